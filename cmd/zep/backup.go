@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/llms"
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/store/postgres"
+)
+
+// backup writes all sessions, messages, summaries and document collections to an archive
+func backup(path string) {
+	cfg, err := config.LoadConfig(cfgFile)
+	if err != nil {
+		log.Fatalf("Error configuring Zep: %s", err)
+	}
+	config.SetLogLevel(cfg)
+
+	appState := &models.AppState{
+		Config: cfg,
+	}
+	db, err := postgres.NewPostgresConn(appState)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v\n", err)
+	}
+	defer db.Close()
+
+	file, err := os.Create(path)
+	if err != nil {
+		log.Fatalf("Failed to create %s: %v", path, err)
+	}
+
+	stats, err := postgres.Backup(context.Background(), db, file)
+	if err != nil {
+		file.Close()
+		log.Fatalf("Failed to back up: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		log.Fatalf("Failed to write %s: %v", path, err)
+	}
+	fmt.Printf("Backed up %s to %s.\n", formatBackupStats(stats), path)
+}
+
+// restore loads an archive written by backup
+func restore(path string) {
+	cfg, err := config.LoadConfig(cfgFile)
+	if err != nil {
+		log.Fatalf("Error configuring Zep: %s", err)
+	}
+	config.SetLogLevel(cfg)
+
+	ctx := context.Background()
+
+	llmClient, err := llms.NewLLMClient(ctx, cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	appState := &models.AppState{
+		LLMClient: llmClient,
+		Config:    cfg,
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("Failed to open %s: %v", path, err)
+	}
+	defer file.Close()
+
+	db, err := postgres.NewPostgresConn(appState)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v\n", err)
+	}
+	defer db.Close()
+
+	stats, err := postgres.Restore(ctx, appState, db, file)
+	if err != nil {
+		log.Fatalf("Failed to restore after %s: %v", formatBackupStats(stats), err)
+	}
+	fmt.Printf("Restored %s.\n", formatBackupStats(stats))
+}
+
+func formatBackupStats(stats *postgres.BackupStats) string {
+	return fmt.Sprintf(
+		"%d users, %d sessions, %d messages, %d summaries, %d embeddings, "+
+			"%d collections and %d documents",
+		stats.Users,
+		stats.Sessions,
+		stats.Messages,
+		stats.Summaries,
+		stats.Embeddings,
+		stats.Collections,
+		stats.Documents,
+	)
+}
@@ -21,6 +21,7 @@ var (
 	showVersion bool
 	dumpConfig  bool
 	generateKey bool
+	projectID   string
 	fixturePath string
 )
 
@@ -69,6 +70,36 @@ var loadFixturesCmd = &cobra.Command{
 	},
 }
 
+var reindexCmd = &cobra.Command{
+	Use:   "reindex",
+	Short: "Re-embeds all messages and writes them to the configured vector store",
+	Run:   func(cmd *cobra.Command, args []string) { reindex() },
+}
+
+var importCmd = &cobra.Command{
+	Use:     "import <file.jsonl>",
+	Short:   "Imports historical conversations from a JSONL file of session_ids and messages",
+	Example: `zep import conversations.jsonl`,
+	Args:    cobra.ExactArgs(1),
+	Run:     func(cmd *cobra.Command, args []string) { importJSONL(args[0]) },
+}
+
+var backupCmd = &cobra.Command{
+	Use:     "backup <file>",
+	Short:   "Backs up all sessions, messages, summaries and document collections to an archive",
+	Example: `zep backup zep-backup.jsonl.gz`,
+	Args:    cobra.ExactArgs(1),
+	Run:     func(cmd *cobra.Command, args []string) { backup(args[0]) },
+}
+
+var restoreCmd = &cobra.Command{
+	Use:     "restore <file>",
+	Short:   "Restores an archive created by zep backup. Existing rows are kept.",
+	Example: `zep restore zep-backup.jsonl.gz`,
+	Args:    cobra.ExactArgs(1),
+	Run:     func(cmd *cobra.Command, args []string) { restore(args[0]) },
+}
+
 var dumpJsonSchemaCmd = &cobra.Command{
 	Use:     "json-schema",
 	Short:   "Generates JSON Schema for Zep's configuration file",
@@ -88,12 +119,18 @@ func init() {
 	testCmd.AddCommand(loadFixturesCmd)
 	cmd.AddCommand(testCmd)
 	cmd.AddCommand(dumpJsonSchemaCmd)
+	cmd.AddCommand(reindexCmd)
+	cmd.AddCommand(importCmd)
+	cmd.AddCommand(backupCmd)
+	cmd.AddCommand(restoreCmd)
 
 	cmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default config.yaml)")
 	cmd.PersistentFlags().BoolVarP(&showVersion, "version", "v", false, "print version number")
 	cmd.PersistentFlags().BoolVarP(&dumpConfig, "dump-config", "d", false, "dump config")
 	cmd.PersistentFlags().
 		BoolVarP(&generateKey, "generate-token", "g", false, "generate a new JWT token")
+	cmd.PersistentFlags().
+		StringVar(&projectID, "project", "", "scope the generated JWT token to a project")
 
 	createFixturesCmd.Flags().Int("count", 100, "Number of fixtures to generate per model")
 	createFixturesCmd.Flags().String("outputDir", "./test_data", "Path to output fixtures")
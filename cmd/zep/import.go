@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/importer"
+	"github.com/getzep/zep/pkg/llms"
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/store/postgres"
+	"github.com/getzep/zep/pkg/tasks"
+)
+
+// importJSONL imports the historical conversations in a JSONL file. The imported messages
+// are queued for enrichment, which is done by the running Zep server.
+func importJSONL(path string) {
+	cfg, err := config.LoadConfig(cfgFile)
+	if err != nil {
+		log.Fatalf("Error configuring Zep: %s", err)
+	}
+	config.SetLogLevel(cfg)
+
+	ctx := context.Background()
+
+	llmClient, err := llms.NewLLMClient(ctx, cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	extractorLLMClients, err := llms.NewExtractorLLMClients(ctx, cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	appState := &models.AppState{
+		LLMClient:           llmClient,
+		ExtractorLLMClients: extractorLLMClients,
+		Config:              cfg,
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("Failed to open %s: %v", path, err)
+	}
+	defer file.Close()
+
+	db, err := postgres.NewPostgresConn(appState)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v\n", err)
+	}
+	defer db.Close()
+
+	appState.MemoryStore, err = postgres.NewPostgresMemoryStore(appState, db)
+	if err != nil {
+		log.Fatalf("unable to create memoryStore %v", err)
+	}
+
+	queueDB, err := postgres.NewPostgresConnForQueue(appState)
+	if err != nil {
+		log.Fatalf("failed to create postgres queue connection %v", err)
+	}
+	defer queueDB.Close()
+
+	publisher := tasks.NewTaskPublisher(queueDB, cfg)
+	defer publisher.Close()
+	appState.TaskPublisher = publisher
+
+	progress, err := importer.Import(ctx, appState, file, func(p *models.ImportProgress) {
+		if p.Current != nil {
+			fmt.Printf(
+				"Line %d: stored %d of %d messages.\n",
+				p.Current.Line,
+				p.Current.Stored,
+				p.Current.Messages,
+			)
+			return
+		}
+		fmt.Printf("Read %d lines: %d imported, %d failed.\n", p.Lines, p.Imported, p.Failed)
+	})
+	for _, lineErr := range progress.Errors {
+		fmt.Printf("Line %d: %s\n", lineErr.Line, lineErr.Error)
+	}
+	if progress.Failed > len(progress.Errors) {
+		fmt.Printf("... and %d more failed lines\n", progress.Failed-len(progress.Errors))
+	}
+	if err != nil {
+		log.Fatalf("Failed to import %s: %v", path, err)
+	}
+	fmt.Printf(
+		"Imported %d messages from %d of %d lines.\n",
+		progress.Messages,
+		progress.Imported,
+		progress.Lines,
+	)
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/llms"
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/store/postgres"
+)
+
+const ErrVectorStoreTypeNotSet = "vector_store.type must be set to reindex messages"
+
+// indexProgressReporter is implemented by vector indexes that build their index in the
+// background, such as Milvus'
+type indexProgressReporter interface {
+	IndexProgress(ctx context.Context) (indexed int64, total int64, err error)
+}
+
+// reindex re-embeds all messages and writes them to the configured vector store
+func reindex() {
+	cfg, err := config.LoadConfig(cfgFile)
+	if err != nil {
+		log.Fatalf("Error configuring Zep: %s", err)
+	}
+	config.SetLogLevel(cfg)
+
+	ctx := context.Background()
+
+	llmClient, err := llms.NewLLMClient(ctx, cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	extractorLLMClients, err := llms.NewExtractorLLMClients(ctx, cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	appState := &models.AppState{
+		LLMClient:           llmClient,
+		ExtractorLLMClients: extractorLLMClients,
+		Config:              cfg,
+	}
+
+	db, err := postgres.NewPostgresConn(appState)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v\n", err)
+	}
+	defer db.Close()
+
+	index := initializeVectorIndex(ctx, appState)
+	if index == nil {
+		log.Fatal(ErrVectorStoreTypeNotSet)
+	}
+
+	count, err := postgres.ReindexMessageEmbeddings(ctx, appState, db, index)
+	if err != nil {
+		log.Fatalf("Failed to reindex messages after %d messages: %v", count, err)
+	}
+	fmt.Printf("Reindexed %d messages.\n", count)
+
+	if reporter, ok := index.(indexProgressReporter); ok {
+		indexed, total, err := reporter.IndexProgress(ctx)
+		if err != nil {
+			log.Warnf("Failed to get vector index progress: %v", err)
+			return
+		}
+		fmt.Printf(
+			"Vector index built for %d of %d rows. The rest are searched by brute force until "+
+				"the index build completes.\n",
+			indexed,
+			total,
+		)
+	}
+}
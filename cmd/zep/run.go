@@ -4,12 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/getzep/zep/pkg/store/milvus"
+	"github.com/getzep/zep/pkg/store/opensearch"
 	"github.com/getzep/zep/pkg/store/postgres"
+	"github.com/getzep/zep/pkg/store/qdrant"
+	"github.com/getzep/zep/pkg/store/s3"
+	"github.com/getzep/zep/pkg/store/weaviate"
 	"github.com/getzep/zep/pkg/tasks"
 
 	"github.com/getzep/zep/pkg/auth"
@@ -19,13 +25,18 @@ import (
 	"github.com/uptrace/bun"
 
 	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/events"
 	"github.com/getzep/zep/pkg/llms"
 	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/pii"
+	"github.com/getzep/zep/pkg/ratelimit"
 	"github.com/getzep/zep/pkg/server"
+	"github.com/getzep/zep/pkg/server/grpcserver"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
@@ -35,8 +46,15 @@ const (
 	ErrPostgresDSNNotSet           = "store.postgres.dsn must be set"
 	ErrOtelEnabledButExporterEmpty = "OpenTelemtry is enabled but OTEL_EXPORTER_OTLP_ENDPOINT is not set"
 	StoreTypePostgres              = "postgres"
+	VectorStoreTypeQdrant          = "qdrant"
+	VectorStoreTypeWeaviate        = "weaviate"
+	VectorStoreTypeMilvus          = "milvus"
+	VectorStoreTypeOpenSearch      = "opensearch"
 )
 
+// outboxDispatchInterval is how often the outbox dispatcher checks for events
+const outboxDispatchInterval = time.Second
+
 // run is the entrypoint for the zep server
 func run() {
 	cfg, err := config.LoadConfig(cfgFile)
@@ -61,6 +79,10 @@ func run() {
 		}()
 	}
 
+	if cfg.Server.GRPCPort > 0 {
+		go serveGRPC(appState)
+	}
+
 	srv := server.Create(appState)
 
 	log.Infof("Listening on: %s", srv.Addr)
@@ -73,6 +95,21 @@ func run() {
 	}
 }
 
+// serveGRPC serves the gRPC API on server.grpc_port
+func serveGRPC(appState *models.AppState) {
+	addr := fmt.Sprintf("%s:%d", appState.Config.Server.Host, appState.Config.Server.GRPCPort)
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Fatalf("Failed to listen for gRPC requests on %s: %v", addr, err)
+	}
+
+	log.Infof("gRPC API listening on: %s", addr)
+	err = grpcserver.Create(appState).Serve(listener)
+	if err != nil {
+		log.Panic(err)
+	}
+}
+
 // NewAppState creates an AppState struct from the config file / ENV, initializes the stores,
 // extractors, and creates the OpenAI client
 func NewAppState(cfg *config.Config) *models.AppState {
@@ -84,18 +121,65 @@ func NewAppState(cfg *config.Config) *models.AppState {
 		log.Fatal(err)
 	}
 
+	extractorLLMClients, err := llms.NewExtractorLLMClients(ctx, cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Fail fast on invalid redaction settings, rather than when messages are stored
+	if cfg.Extractors.Messages.PII.Enabled {
+		if _, err := pii.NewRedactor(&cfg.Extractors.Messages.PII); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if err := tasks.ValidateCustomExtractors(cfg.Extractors.Messages.Custom); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := postgres.ValidateRetentionRules(cfg.DataConfig.Retention.Rules); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := tasks.ValidateWebhooks(cfg.Webhooks); err != nil {
+		log.Fatal(err)
+	}
+
 	appState := &models.AppState{
-		LLMClient: llmClient,
-		Config:    cfg,
+		LLMClient:           llmClient,
+		ExtractorLLMClients: extractorLLMClients,
+		SessionEvents:       events.NewBroker(),
+		HealthChecks:        llms.NewHealthChecks(cfg),
+		Config:              cfg,
+	}
+
+	if len(cfg.Webhooks) > 0 {
+		appState.Webhooks = tasks.NewWebhookNotifier(appState)
+	}
+
+	if len(cfg.Kafka.Brokers) > 0 {
+		log.Info("Publishing events to Kafka")
+		appState.EventProducer = events.NewKafkaProducer(&cfg.Kafka)
+	}
+
+	if cfg.Extractors.Messages.Moderation.Enabled {
+		if _, err := llms.NewModerator(appState); err != nil {
+			log.Fatal(err)
+		}
 	}
 
 	initializeStores(ctx, appState)
+	appState.RateLimiter = initializeRateLimiter(&cfg.Server.RateLimit)
 
 	setupTaskRouter(ctx, appState)
 
 	setupSignalHandler(ctx, appState)
 
 	setupPurgeProcessor(ctx, appState)
+	setupSessionExpiryProcessor(ctx, appState)
+	setupRetentionProcessor(ctx, appState)
+	setupArchivalProcessor(ctx, appState)
+	setupOutboxDispatcher(ctx, appState)
 
 	return appState
 }
@@ -110,7 +194,7 @@ func handleCLIOptions(cfg *config.Config) {
 		fmt.Println(dumpConfigToJSON(cfg))
 		os.Exit(0)
 	case generateKey:
-		fmt.Println(auth.GenerateJWT(cfg))
+		fmt.Println(auth.GenerateJWT(cfg, projectID))
 		os.Exit(0)
 	}
 }
@@ -137,6 +221,21 @@ func initializeStores(ctx context.Context, appState *models.AppState) {
 		if err != nil {
 			log.Fatalf("unable to create memoryStore %v", err)
 		}
+		memoryStore.VectorIndex = initializeVectorIndex(ctx, appState)
+		memoryStore.ArchiveStore = initializeArchiveStore(appState)
+		memoryStore.Webhooks = appState.Webhooks
+		if appState.Config.Store.Postgres.ReadDSN != "" {
+			readDB, err := postgres.NewPostgresReadConn(appState)
+			if err != nil {
+				log.Fatalf("Failed to connect to read replica: %v\n", err)
+			}
+			if appState.Config.Log.Level == "debug" {
+				pgDebugLogging(readDB)
+			}
+			memoryStore.ReadClient = readDB
+			memoryStore.ReadAfterWriteWindow = appState.Config.Store.Postgres.ReadAfterWriteWindow
+			log.Info("Reading memory from the read replica")
+		}
 		log.Debug("memoryStore created")
 
 		documentStore, err := postgres.NewDocumentStore(
@@ -152,9 +251,26 @@ func initializeStores(ctx context.Context, appState *models.AppState) {
 		userStore := postgres.NewUserStoreDAO(db)
 		log.Debug("userStore created")
 
+		appState.DeadLetterStore = postgres.NewDeadLetterStoreDAO(db)
+		appState.APIKeyStore = postgres.NewAPIKeyStoreDAO(db)
+		appState.StatsStore = postgres.NewStatsStoreDAO(db, appState.Config)
+		if appState.Config.DataConfig.AuditLog.Enabled {
+			appState.AuditLogStore = postgres.NewAuditLogStoreDAO(db)
+		}
+		if appState.Config.Extractors.EmbeddingCache.Enabled {
+			appState.EmbeddingCache = postgres.NewEmbeddingCacheDAO(db)
+		}
+		if appState.Config.Extractors.ResultCache.Enabled {
+			appState.ExtractorResultCache = postgres.NewExtractorResultCacheDAO(db)
+		}
+
 		appState.MemoryStore = memoryStore
 		appState.DocumentStore = documentStore
 		appState.UserStore = userStore
+		appState.HealthChecks = append(
+			appState.HealthChecks,
+			postgres.NewHealthChecks(db, &appState.Config.Store.Postgres)...,
+		)
 	default:
 		log.Fatal(
 			fmt.Sprintf(
@@ -167,6 +283,117 @@ func initializeStores(ctx context.Context, appState *models.AppState) {
 	log.Info("Using memory store: ", appState.Config.Store.Type)
 }
 
+// initializeVectorIndex returns the message vector index configured by vector_store.type, or
+// nil if message embeddings are to be stored in the memory store
+func initializeVectorIndex(
+	ctx context.Context,
+	appState *models.AppState,
+) models.MessageVectorIndex {
+	switch appState.Config.VectorStore.Type {
+	case "":
+		return nil
+	case VectorStoreTypeQdrant:
+		model, err := llms.GetEmbeddingModel(appState, "message")
+		if err != nil {
+			log.Fatalf("unable to get message embedding model: %v", err)
+		}
+		index, err := qdrant.NewMessageIndex(
+			ctx,
+			&appState.Config.VectorStore.Qdrant,
+			model.Dimensions,
+		)
+		if err != nil {
+			log.Fatalf("unable to create qdrant vector index: %v", err)
+		}
+		log.Info("Using vector store: ", VectorStoreTypeQdrant)
+		return index
+	case VectorStoreTypeWeaviate:
+		index, err := weaviate.NewMessageIndex(ctx, &appState.Config.VectorStore.Weaviate)
+		if err != nil {
+			log.Fatalf("unable to create weaviate vector index: %v", err)
+		}
+		log.Info("Using vector store: ", VectorStoreTypeWeaviate)
+		return index
+	case VectorStoreTypeMilvus:
+		model, err := llms.GetEmbeddingModel(appState, "message")
+		if err != nil {
+			log.Fatalf("unable to get message embedding model: %v", err)
+		}
+		index, err := milvus.NewMessageIndex(
+			ctx,
+			&appState.Config.VectorStore.Milvus,
+			model.Dimensions,
+		)
+		if err != nil {
+			log.Fatalf("unable to create milvus vector index: %v", err)
+		}
+		log.Info("Using vector store: ", VectorStoreTypeMilvus)
+		return index
+	case VectorStoreTypeOpenSearch:
+		model, err := llms.GetEmbeddingModel(appState, "message")
+		if err != nil {
+			log.Fatalf("unable to get message embedding model: %v", err)
+		}
+		index, err := opensearch.NewMessageIndex(
+			ctx,
+			&appState.Config.VectorStore.OpenSearch,
+			model.Dimensions,
+		)
+		if err != nil {
+			log.Fatalf("unable to create opensearch vector index: %v", err)
+		}
+		log.Info("Using vector store: ", VectorStoreTypeOpenSearch)
+		return index
+	default:
+		log.Fatal(
+			fmt.Sprintf(
+				"vector_store.type (%s) is not supported",
+				appState.Config.VectorStore.Type,
+			),
+		)
+	}
+	return nil
+}
+
+// initializeArchiveStore returns the object store of session archives, or nil if no bucket
+// is configured. The store is needed to rehydrate archived sessions even when archival is
+// disabled.
+func initializeArchiveStore(appState *models.AppState) models.ObjectStore {
+	cfg := &appState.Config.DataConfig.Archival
+	if cfg.S3.Bucket == "" {
+		if cfg.CheckEvery > 0 {
+			log.Fatal("data.archival.s3.bucket must be set to archive sessions")
+		}
+		return nil
+	}
+	if cfg.CheckEvery > 0 && cfg.InactiveDays <= 0 {
+		log.Fatal("data.archival.inactive_days must be positive to archive sessions")
+	}
+	archiveStore, err := s3.NewObjectStore(&cfg.S3)
+	if err != nil {
+		log.Fatalf("unable to create archive store: %v", err)
+	}
+	return archiveStore
+}
+
+// initializeRateLimiter returns the limiter holding the buckets of the rate limits, or nil if
+// no limits are configured
+func initializeRateLimiter(cfg *config.RateLimitConfig) models.RateLimiter {
+	if cfg.PerKey.RequestsPerSecond <= 0 && cfg.PerIP.RequestsPerSecond <= 0 {
+		return nil
+	}
+	if cfg.RedisURL == "" {
+		log.Info("Rate limits enabled, with buckets held in memory")
+		return ratelimit.NewMemoryLimiter()
+	}
+	limiter, err := ratelimit.NewRedisLimiter(cfg.RedisURL)
+	if err != nil {
+		log.Fatalf("unable to create rate limiter: %v", err)
+	}
+	log.Info("Rate limits enabled, with buckets held in Redis")
+	return limiter
+}
+
 func pgDebugLogging(db *bun.DB) {
 	db.AddQueryHook(logrusbun.NewQueryHook(logrusbun.QueryHookOptions{
 		LogSlow:         time.Second,
@@ -194,6 +421,11 @@ func setupSignalHandler(ctx context.Context, appState *models.AppState) {
 		if err := appState.TaskRouter.Close(); err != nil {
 			log.Errorf("Error closing LLMClient connection: %v", err)
 		}
+		if appState.EventProducer != nil {
+			if err := appState.EventProducer.Close(); err != nil {
+				log.Errorf("Error closing EventProducer: %v", err)
+			}
+		}
 		os.Exit(0)
 	}()
 }
@@ -229,12 +461,178 @@ func setupPurgeProcessor(ctx context.Context, appState *models.AppState) {
 				if err != nil {
 					log.Errorf("error purging deleted records: %v", err)
 				}
+				purgeCaches(ctx, appState)
+			}
+			time.Sleep(interval)
+		}
+	}()
+}
+
+// purgeCaches deletes the cached embeddings and extractor results older than the max age of
+// their cache, if the caches are enabled and their entries expire
+func purgeCaches(ctx context.Context, appState *models.AppState) {
+	cfg := &appState.Config.Extractors
+	if appState.EmbeddingCache != nil && cfg.EmbeddingCache.MaxAgeDays > 0 {
+		before := time.Now().AddDate(0, 0, -cfg.EmbeddingCache.MaxAgeDays)
+		purged, err := appState.EmbeddingCache.PurgeEmbeddings(ctx, before)
+		if err != nil {
+			log.Errorf("error purging cached embeddings: %v", err)
+		} else if purged > 0 {
+			log.Infof("purged %d cached embeddings", purged)
+		}
+	}
+	if appState.ExtractorResultCache != nil && cfg.ResultCache.MaxAgeDays > 0 {
+		before := time.Now().AddDate(0, 0, -cfg.ResultCache.MaxAgeDays)
+		purged, err := appState.ExtractorResultCache.PurgeResults(ctx, before)
+		if err != nil {
+			log.Errorf("error purging cached extractor results: %v", err)
+		} else if purged > 0 {
+			log.Infof("purged %d cached extractor results", purged)
+		}
+	}
+}
+
+// setupSessionExpiryProcessor sets up a go routine to expire idle sessions at a regular
+// interval. It's cancellable via the passed context.
+// If Config.DataConfig.SessionExpiry.CheckEvery is 0, this function does nothing.
+func setupSessionExpiryProcessor(ctx context.Context, appState *models.AppState) {
+	cfg := &appState.Config.DataConfig.SessionExpiry
+	interval := time.Duration(cfg.CheckEvery) * time.Minute
+	if interval == 0 {
+		log.Debug("session expiry processor disabled")
+		return
+	}
+
+	log.Infof("Starting session expiry processor. Checking every %v", interval)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				log.Info("Stopping session expiry processor")
+				return
+			default:
+				result, err := appState.MemoryStore.ExpireSessions(ctx, cfg)
+				if err != nil {
+					log.Errorf("error expiring sessions: %v", err)
+					break
+				}
+				if result.DryRun {
+					log.Infof(
+						"session expiry dry run: would expire sessions %v and purge sessions %v",
+						result.Expired,
+						result.Purged,
+					)
+				} else if len(result.Expired) > 0 || len(result.Purged) > 0 {
+					log.Infof(
+						"expired %d sessions and purged %d sessions",
+						len(result.Expired),
+						len(result.Purged),
+					)
+				}
+			}
+			time.Sleep(interval)
+		}
+	}()
+}
+
+// setupRetentionProcessor sets up a go routine to apply the retention rules at a regular
+// interval. It's cancellable via the passed context.
+// If Config.DataConfig.Retention.CheckEvery is 0, this function does nothing.
+func setupRetentionProcessor(ctx context.Context, appState *models.AppState) {
+	cfg := &appState.Config.DataConfig.Retention
+	interval := time.Duration(cfg.CheckEvery) * time.Minute
+	if interval == 0 || len(cfg.Rules) == 0 {
+		log.Debug("retention processor disabled")
+		return
+	}
+
+	log.Infof("Starting retention processor. Applying %d rules every %v", len(cfg.Rules), interval)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				log.Info("Stopping retention processor")
+				return
+			default:
+				audits, err := appState.MemoryStore.ApplyRetentionRules(ctx, cfg.Rules)
+				if err != nil {
+					log.Errorf("error applying retention rules: %v", err)
+				}
+				for _, audit := range audits {
+					log.Infof(
+						"retention rule %s: %s %d %s",
+						audit.Rule,
+						audit.Action,
+						audit.RecordCount,
+						audit.Target,
+					)
+				}
+			}
+			time.Sleep(interval)
+		}
+	}()
+}
+
+func setupArchivalProcessor(ctx context.Context, appState *models.AppState) {
+	cfg := &appState.Config.DataConfig.Archival
+	interval := time.Duration(cfg.CheckEvery) * time.Minute
+	if interval == 0 {
+		log.Debug("archival processor disabled")
+		return
+	}
+
+	log.Infof(
+		"Starting archival processor. Archiving sessions inactive for %d days every %v",
+		cfg.InactiveDays,
+		interval,
+	)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				log.Info("Stopping archival processor")
+				return
+			default:
+				archived, err := appState.MemoryStore.ArchiveSessions(ctx, cfg)
+				if err != nil {
+					log.Errorf("error archiving sessions: %v", err)
+				}
+				if len(archived) > 0 {
+					log.Infof("archived %d inactive sessions", len(archived))
+				}
 			}
 			time.Sleep(interval)
 		}
 	}()
 }
 
+// setupOutboxDispatcher sets up a go routine to dispatch the events written to the outbox.
+// It's cancellable via the passed context.
+// If there's no EventProducer or webhooks, this function does nothing.
+func setupOutboxDispatcher(ctx context.Context, appState *models.AppState) {
+	if appState.EventProducer == nil && appState.Webhooks == nil {
+		log.Debug("outbox dispatcher disabled")
+		return
+	}
+
+	log.Infof("Starting outbox dispatcher. Dispatching every %v", outboxDispatchInterval)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				log.Info("Stopping outbox dispatcher")
+				return
+			default:
+				_, err := appState.MemoryStore.DispatchOutbox(ctx, appState)
+				if err != nil {
+					log.Errorf("error dispatching outbox events: %v", err)
+				}
+			}
+			time.Sleep(outboxDispatchInterval)
+		}
+	}()
+}
+
 func dumpConfigToJSON(cfg *config.Config) string {
 	b, err := json.MarshalIndent(cfg, "", "  ")
 	if err != nil {
@@ -267,6 +665,13 @@ func initTracer() func(context.Context) error {
 		log.Fatal(err)
 	}
 
+	// propagate trace context to and from requests and tasks
+	otel.SetTextMapPropagator(
+		propagation.NewCompositeTextMapPropagator(
+			propagation.TraceContext{},
+			propagation.Baggage{},
+		),
+	)
 	otel.SetTracerProvider(
 		sdktrace.NewTracerProvider(
 			sdktrace.WithSampler(sdktrace.AlwaysSample()),
@@ -16,10 +16,25 @@ var log = logrus.New()
 
 // EnvVars is a set of secrets that should be stored in the environment, not config file
 var EnvVars = map[string]string{
-	"llm.anthropic_api_key": "ZEP_ANTHROPIC_API_KEY",
-	"llm.openai_api_key":    "ZEP_OPENAI_API_KEY",
-	"auth.secret":           "ZEP_AUTH_SECRET",
-	"development":           "ZEP_DEVELOPMENT",
+	"llm.anthropic_api_key":              "ZEP_ANTHROPIC_API_KEY",
+	"llm.openai_api_key":                 "ZEP_OPENAI_API_KEY",
+	"llm.azure_openai.embedding_api_key": "ZEP_AZURE_OPENAI_EMBEDDING_API_KEY",
+	"llm.cohere.api_key":                 "ZEP_COHERE_API_KEY",
+	"auth.secret":                        "ZEP_AUTH_SECRET",
+	"development":                        "ZEP_DEVELOPMENT",
+	"vector_store.qdrant.api_key":        "ZEP_QDRANT_API_KEY",
+	"vector_store.weaviate.api_key":      "ZEP_WEAVIATE_API_KEY",
+	"vector_store.milvus.token":          "ZEP_MILVUS_TOKEN",
+	"vector_store.opensearch.password":   "ZEP_OPENSEARCH_PASSWORD",
+	// Bedrock credentials use the standard AWS environment variables
+	"llm.bedrock.access_key_id":          "AWS_ACCESS_KEY_ID",
+	"llm.bedrock.secret_access_key":      "AWS_SECRET_ACCESS_KEY",
+	"llm.bedrock.session_token":          "AWS_SESSION_TOKEN",
+	"data.archival.s3.access_key_id":     "ZEP_ARCHIVAL_S3_ACCESS_KEY_ID",
+	"data.archival.s3.secret_access_key": "ZEP_ARCHIVAL_S3_SECRET_ACCESS_KEY",
+	"server.rate_limit.redis_url":        "ZEP_RATE_LIMIT_REDIS_URL",
+	// Vertex AI uses the standard Application Default Credentials variable
+	"llm.vertexai.credentials_file": "GOOGLE_APPLICATION_CREDENTIALS",
 }
 
 // LoadConfig loads the config file and ENV variables into a Config struct
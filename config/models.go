@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 // Config holds the configuration of the application
 // Use cmd.NewConfig to create a new instance
 type Config struct {
@@ -8,6 +10,7 @@ type Config struct {
 	Memory        MemoryConfig        `mapstructure:"memory"`
 	Extractors    ExtractorsConfig    `mapstructure:"extractors"`
 	Store         StoreConfig         `mapstructure:"store"`
+	VectorStore   VectorStoreConfig   `mapstructure:"vector_store"`
 	Server        ServerConfig        `mapstructure:"server"`
 	Log           LogConfig           `mapstructure:"log"`
 	Auth          AuthConfig          `mapstructure:"auth"`
@@ -15,6 +18,9 @@ type Config struct {
 	DataConfig    DataConfig          `mapstructure:"data"`
 	Development   bool                `mapstructure:"development"`
 	CustomPrompts CustomPromptsConfig `mapstructure:"custom_prompts"`
+	Rerank        RerankConfig        `mapstructure:"rerank"`
+	Webhooks      []WebhookConfig     `mapstructure:"webhooks"`
+	Kafka         KafkaConfig         `mapstructure:"kafka"`
 }
 
 type StoreConfig struct {
@@ -22,6 +28,57 @@ type StoreConfig struct {
 	Postgres PostgresConfig `mapstructure:"postgres"`
 }
 
+// VectorStoreConfig configures where message embeddings are stored and searched. An empty
+// Type stores embeddings alongside messages in the memory store.
+type VectorStoreConfig struct {
+	Type       string           `mapstructure:"type"`
+	Qdrant     QdrantConfig     `mapstructure:"qdrant"`
+	Weaviate   WeaviateConfig   `mapstructure:"weaviate"`
+	Milvus     MilvusConfig     `mapstructure:"milvus"`
+	OpenSearch OpenSearchConfig `mapstructure:"opensearch"`
+}
+
+type QdrantConfig struct {
+	URL        string `mapstructure:"url"`
+	APIKey     string `mapstructure:"api_key"`
+	Collection string `mapstructure:"collection"`
+}
+
+type WeaviateConfig struct {
+	URL    string `mapstructure:"url"`
+	APIKey string `mapstructure:"api_key"`
+	Class  string `mapstructure:"class"`
+}
+
+type MilvusConfig struct {
+	URL        string            `mapstructure:"url"`
+	Token      string            `mapstructure:"token"`
+	Collection string            `mapstructure:"collection"`
+	Index      MilvusIndexConfig `mapstructure:"index"`
+}
+
+// MilvusIndexConfig configures the collection's vector index. Type is HNSW or IVF_FLAT.
+// M, EFConstruction and EF apply to HNSW, NList and NProbe to IVF_FLAT. Zero values use
+// the defaults.
+type MilvusIndexConfig struct {
+	Type           string `mapstructure:"type"`
+	M              int    `mapstructure:"m"`
+	EFConstruction int    `mapstructure:"ef_construction"`
+	EF             int    `mapstructure:"ef"`
+	NList          int    `mapstructure:"nlist"`
+	NProbe         int    `mapstructure:"nprobe"`
+}
+
+type OpenSearchConfig struct {
+	URL      string `mapstructure:"url"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	Index    string `mapstructure:"index"`
+	// BM25Weight is the weight, between 0 and 1, given to BM25 relevance when combining it
+	// with kNN similarity. kNN similarity is given the remaining weight.
+	BM25Weight float64 `mapstructure:"bm25_weight"`
+}
+
 type LLM struct {
 	Service             string            `mapstructure:"service"`
 	Model               string            `mapstructure:"model"`
@@ -31,15 +88,77 @@ type LLM struct {
 	AzureOpenAIModel    AzureOpenAIConfig `mapstructure:"azure_openai"`
 	OpenAIEndpoint      string            `mapstructure:"openai_endpoint"`
 	OpenAIOrgID         string            `mapstructure:"openai_org_id"`
+	OllamaEndpoint      string            `mapstructure:"ollama_endpoint"`
+	Bedrock             BedrockConfig     `mapstructure:"bedrock"`
+	VertexAI            VertexAIConfig    `mapstructure:"vertexai"`
+	Cohere              CohereConfig      `mapstructure:"cohere"`
+	// Tokenizer overrides the tokenizer used to count tokens: tiktoken, anthropic or
+	// estimate. Defaults to the tokenizer of the llm service.
+	Tokenizer string `mapstructure:"tokenizer"`
+}
+
+// CohereConfig configures Cohere, which is used by embeddings extractors and the reranker
+// with the cohere service
+type CohereConfig struct {
+	APIKey         string `mapstructure:"api_key"`
+	EmbeddingModel string `mapstructure:"embedding_model"`
+	RerankModel    string `mapstructure:"rerank_model"`
+	// Endpoint overrides the Cohere API endpoint, https://api.cohere.ai
+	Endpoint string `mapstructure:"endpoint"`
+}
+
+// BedrockConfig configures AWS Bedrock. The model id is set using llm.model.
+type BedrockConfig struct {
+	Region          string `mapstructure:"region"`
+	AccessKeyID     string `mapstructure:"access_key_id"`
+	SecretAccessKey string `mapstructure:"secret_access_key"`
+	SessionToken    string `mapstructure:"session_token"`
+	// Endpoint overrides the regional bedrock-runtime endpoint, e.g. for a VPC endpoint
+	Endpoint string `mapstructure:"endpoint"`
+}
+
+// VertexAIConfig configures Google Vertex AI. The Gemini model is set using llm.model.
+// Credentials are found using Application Default Credentials if CredentialsFile isn't set.
+type VertexAIConfig struct {
+	// Project defaults to the project of the service account credentials
+	Project  string `mapstructure:"project"`
+	Location string `mapstructure:"location"`
+	// CredentialsFile is the path to a service account or authorized user credentials file
+	CredentialsFile string `mapstructure:"credentials_file"`
+	// EmbeddingModel is used if an embeddings extractor's service is vertexai
+	EmbeddingModel string `mapstructure:"embedding_model"`
+	// Endpoint overrides the regional aiplatform endpoint, e.g. for Private Service Connect
+	Endpoint string `mapstructure:"endpoint"`
 }
 
 type AzureOpenAIConfig struct {
 	LLMDeployment       string `mapstructure:"llm_deployment"`
 	EmbeddingDeployment string `mapstructure:"embedding_deployment"`
+	// APIVersion is the Azure OpenAI REST API version. Defaults to 2023-05-15.
+	APIVersion string `mapstructure:"api_version"`
+	// EmbeddingEndpoint and EmbeddingAPIKey are used if the embedding deployment is on a
+	// different Azure OpenAI resource than the llm deployment. They default to
+	// azure_openai_endpoint and openai_api_key.
+	EmbeddingEndpoint string `mapstructure:"embedding_endpoint"`
+	EmbeddingAPIKey   string `mapstructure:"embedding_api_key"`
 }
 
 type NLP struct {
 	ServerURL string `mapstructure:"server_url"`
+	// EmbeddingsServerURL is the URL of a self-hosted embeddings server, such as
+	// text-embeddings-inference serving a sentence-transformers model. If set, it's used by
+	// the local embeddings service in place of the NLP server.
+	EmbeddingsServerURL string `mapstructure:"embeddings_server_url"`
+}
+
+// RerankConfig configures the cross-encoder used to rerank search results when a search
+// request sets rerank
+type RerankConfig struct {
+	// Service is local or cohere
+	Service string `mapstructure:"service"`
+	// ServerURL is the URL of the local service's rerank server, such as
+	// text-embeddings-inference serving a cross-encoder model
+	ServerURL string `mapstructure:"server_url"`
 }
 
 type MemoryConfig struct {
@@ -47,8 +166,46 @@ type MemoryConfig struct {
 }
 
 type PostgresConfig struct {
-	DSN              string           `mapstructure:"dsn"`
-	AvailableIndexes AvailableIndexes `mapstructure:"available_indexes"`
+	DSN string `mapstructure:"dsn"`
+	// ReadDSN, if set, is the DSN of a read replica. Memory retrieval, message listing and
+	// memory search read from it, and everything else uses DSN.
+	ReadDSN string `mapstructure:"read_dsn"`
+	// ReadAfterWriteWindow is how long a session's reads go to the primary after its
+	// messages or summaries are written, so that they aren't read stale from the replica.
+	// Zero always reads from the replica.
+	ReadAfterWriteWindow time.Duration    `mapstructure:"read_after_write_window"`
+	AvailableIndexes     AvailableIndexes `mapstructure:"available_indexes"`
+	// Connection pool settings. Zero values use the defaults: MaxOpenConnections and
+	// MaxIdleConnections default to 4 * GOMAXPROCS, and connections are reused forever.
+	MaxOpenConnections    int           `mapstructure:"max_open_connections"`
+	MaxIdleConnections    int           `mapstructure:"max_idle_connections"`
+	ConnectionMaxLifetime time.Duration `mapstructure:"connection_max_lifetime"`
+	ConnectionMaxIdleTime time.Duration `mapstructure:"connection_max_idle_time"`
+	// QueueMaxOpenConnections limits the connections of the task queue's pool, which is
+	// separate from the store's. Zero doesn't limit them. ConnectionMaxLifetime and
+	// ConnectionMaxIdleTime also apply to the queue's pool.
+	QueueMaxOpenConnections int `mapstructure:"queue_max_open_connections"`
+	// StatementTimeout aborts statements that run longer, on both pools. Zero doesn't
+	// limit them. Index builds aren't subject to it.
+	StatementTimeout time.Duration `mapstructure:"statement_timeout"`
+	// MessagePartitions is the number of hash partitions, by session ID, of the message and
+	// message_embedding tables. Zero doesn't partition them. It only applies to new databases,
+	// when the tables are created, and can't be changed afterwards. Existing databases aren't
+	// converted: their data may be moved to partitioned tables by restoring a backup into a
+	// new database.
+	MessagePartitions int        `mapstructure:"message_partitions"`
+	HNSW              HNSWConfig `mapstructure:"hnsw"`
+	// CockroachDB enables compatibility with CockroachDB, which uses its built-in VECTOR
+	// type in place of the pgvector extension and does not support vector indexes.
+	CockroachDB bool `mapstructure:"cockroachdb"`
+}
+
+// HNSWConfig configures pgvector HNSW indexes. Zero values use the defaults: M = 16,
+// EFConstruction = 64 and EFSearch = 100.
+type HNSWConfig struct {
+	M              int `mapstructure:"m"`
+	EFConstruction int `mapstructure:"ef_construction"`
+	EFSearch       int `mapstructure:"ef_search"`
 }
 
 type AvailableIndexes struct {
@@ -61,6 +218,45 @@ type ServerConfig struct {
 	Port           int    `mapstructure:"port"`
 	WebEnabled     bool   `mapstructure:"web_enabled"`
 	MaxRequestSize int64  `mapstructure:"max_request_size"`
+	// SessionQuota is the maximum number of active sessions per tenant. 0 disables the quota.
+	SessionQuota int64 `mapstructure:"session_quota"`
+	// GRPCPort is the port of the gRPC API, which is served alongside the HTTP API. 0
+	// disables the gRPC API.
+	GRPCPort int `mapstructure:"grpc_port"`
+	// MetricsEnabled serves Prometheus metrics at /metrics, which isn't authenticated
+	MetricsEnabled bool `mapstructure:"metrics_enabled"`
+	// RateLimit limits the requests to the memory and search endpoints
+	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
+	Replay    ReplayConfig    `mapstructure:"replay"`
+}
+
+// ReplayConfig configures the session replay endpoint
+type ReplayConfig struct {
+	// AllowPrivateNetworks allows sessions to be replayed to target URLs on loopback, private
+	// and link-local addresses. It's disabled so that API clients can't reach services on
+	// Zep's network.
+	AllowPrivateNetworks bool `mapstructure:"allow_private_networks"`
+}
+
+// RateLimitConfig configures token bucket rate limits of the memory and search endpoints.
+// Requests over a limit are rejected with a 429 Too Many Requests.
+type RateLimitConfig struct {
+	// PerKey limits the requests of each API key, and of each tenant of JWTs with a
+	// tenant_id claim
+	PerKey TokenBucketConfig `mapstructure:"per_key"`
+	// PerIP limits the requests of each client IP
+	PerIP TokenBucketConfig `mapstructure:"per_ip"`
+	// RedisURL is the redis:// or rediss:// URL of a Redis server holding the buckets, so
+	// that they're shared by replicas. If empty, each replica holds its buckets in memory.
+	RedisURL string `mapstructure:"redis_url"`
+}
+
+type TokenBucketConfig struct {
+	// RequestsPerSecond is the rate at which a bucket refills. 0 disables the limit.
+	RequestsPerSecond float64 `mapstructure:"requests_per_second"`
+	// Burst is the number of requests a full bucket allows at once. Defaults to
+	// RequestsPerSecond, rounded up.
+	Burst int `mapstructure:"burst"`
 }
 
 type LogConfig struct {
@@ -72,37 +268,180 @@ type OpenTelemetryConfig struct {
 }
 
 type AuthConfig struct {
-	Secret   string `mapstructure:"secret"`
-	Required bool   `mapstructure:"required"`
+	Secret   string     `mapstructure:"secret"`
+	Required bool       `mapstructure:"required"`
+	OIDC     OIDCConfig `mapstructure:"oidc"`
+}
+
+// OIDCConfig configures the verification of JWTs issued by an external OIDC issuer, in place
+// of JWTs signed with the auth secret
+type OIDCConfig struct {
+	// Issuer is the issuer URL, which must match the iss claim of tokens. If empty, JWTs are
+	// verified with the auth secret.
+	Issuer string `mapstructure:"issuer"`
+	// Audience must be in the aud claim of tokens, if set. Usually the client ID of Zep.
+	Audience string `mapstructure:"audience"`
+	// JWKSURL is the URL of the issuer's key set. Defaults to the jwks_uri of the issuer's
+	// OpenID configuration.
+	JWKSURL string `mapstructure:"jwks_url"`
 }
 
 type DataConfig struct {
 	// PurgeEvery is the period between hard deletes, in minutes.
 	// If set to 0, hard deletes will not be performed.
-	PurgeEvery int `mapstructure:"purge_every"`
+	PurgeEvery    int                 `mapstructure:"purge_every"`
+	SessionExpiry SessionExpiryConfig `mapstructure:"session_expiry"`
+	Retention     RetentionConfig     `mapstructure:"retention"`
+	Archival      ArchivalConfig      `mapstructure:"archival"`
+	AuditLog      AuditLogConfig      `mapstructure:"audit_log"`
+}
+
+// AuditLogConfig configures the audit log of the API requests that put, delete, search or
+// export memory, which records who made each request and when
+type AuditLogConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// IncludeReads also records the requests that get memory, messages and sessions
+	IncludeReads bool `mapstructure:"include_reads"`
+}
+
+// ArchivalConfig configures the archival of inactive sessions' messages, summaries and
+// embeddings to S3-compatible object storage. An archived session is rehydrated when it's
+// next read or written.
+type ArchivalConfig struct {
+	// CheckEvery is the period between checks for inactive sessions, in minutes.
+	// If set to 0, sessions aren't archived.
+	CheckEvery int `mapstructure:"check_every"`
+	// InactiveDays is the number of days after which sessions that haven't been updated are
+	// archived
+	InactiveDays int      `mapstructure:"inactive_days"`
+	S3           S3Config `mapstructure:"s3"`
+}
+
+// S3Config configures an S3-compatible object store. Objects are addressed path-style, as
+// endpoint/bucket/key, so that stores other than Amazon S3 may be used.
+type S3Config struct {
+	// Endpoint defaults to the regional Amazon S3 endpoint
+	Endpoint string `mapstructure:"endpoint"`
+	Region   string `mapstructure:"region"`
+	Bucket   string `mapstructure:"bucket"`
+	// Prefix is prepended to the keys of objects
+	Prefix          string `mapstructure:"prefix"`
+	AccessKeyID     string `mapstructure:"access_key_id"`
+	SecretAccessKey string `mapstructure:"secret_access_key"`
+}
+
+// RetentionConfig configures the retention rules applied to sessions' records. Records that
+// no rule applies to are kept forever.
+type RetentionConfig struct {
+	// CheckEvery is the period between applications of the rules, in minutes.
+	// If set to 0, the rules aren't applied.
+	CheckEvery int                   `mapstructure:"check_every"`
+	Rules      []RetentionRuleConfig `mapstructure:"rules"`
+}
+
+// RetentionRuleConfig applies an action to the records of a target created more than
+// OlderThanDays ago
+type RetentionRuleConfig struct {
+	Name string `mapstructure:"name"`
+	// Target is messages or summaries
+	Target string `mapstructure:"target"`
+	// Action is purge, to hard delete the records, or anonymize, to clear their metadata
+	Action        string `mapstructure:"action"`
+	OlderThanDays int    `mapstructure:"older_than_days"`
+}
+
+// SessionExpiryConfig configures the expiry of sessions that haven't been updated within
+// their TTL. Expired sessions are soft deleted, and may be restored, until they've been idle
+// for the TTL and the grace period, when they're purged.
+type SessionExpiryConfig struct {
+	// CheckEvery is the period between checks for expired sessions, in minutes.
+	// If set to 0, sessions don't expire.
+	CheckEvery int `mapstructure:"check_every"`
+	// TTL is the time, in minutes, after which idle sessions expire. A session's session_ttl
+	// metadata overrides it. If set to 0, only sessions with a session_ttl expire.
+	TTL int `mapstructure:"ttl"`
+	// GracePeriod is the time, in minutes, that expired sessions may be restored before
+	// they're purged. If set to 0, expired sessions are purged rather than soft deleted.
+	GracePeriod int `mapstructure:"grace_period"`
+	// DryRun logs the sessions that would be expired or purged, without deleting them.
+	DryRun bool `mapstructure:"dry_run"`
 }
 
 type ExtractorsConfig struct {
 	Messages  MessageExtractorsConfig  `mapstructure:"messages"`
 	Documents DocumentExtractorsConfig `mapstructure:"documents"`
+	// EmbeddingCache caches message, summary and document embeddings, keyed by a hash of
+	// their text and embedding model, so that identical texts are embedded once
+	EmbeddingCache CacheConfig `mapstructure:"embedding_cache"`
+	// ResultCache caches the intents and entities extracted from texts, so that recurring
+	// utterances aren't sent to the llm or NLP server again
+	ResultCache CacheConfig `mapstructure:"result_cache"`
+}
+
+type CacheConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MaxAgeDays is the number of days entries are cached. Expired entries are deleted by the
+	// purge processor. If set to 0, entries don't expire.
+	MaxAgeDays int `mapstructure:"max_age_days"`
 }
 
 // MessageExtractorsConfig holds the configuration for all extractors
 type MessageExtractorsConfig struct {
-	Summarizer SummarizerConfig      `mapstructure:"summarizer"`
-	Embeddings EmbeddingsConfig      `mapstructure:"embeddings"`
-	Entities   EntityExtractorConfig `mapstructure:"entities"`
-	Intent     IntentExtractorConfig `mapstructure:"intent"`
+	Summarizer SummarizerConfig         `mapstructure:"summarizer"`
+	Embeddings EmbeddingsConfig         `mapstructure:"embeddings"`
+	Entities   EntityExtractorConfig    `mapstructure:"entities"`
+	Intent     IntentExtractorConfig    `mapstructure:"intent"`
+	PII        PIIRedactorConfig        `mapstructure:"pii"`
+	Sentiment  SentimentExtractorConfig `mapstructure:"sentiment"`
+	Language   LanguageExtractorConfig  `mapstructure:"language"`
+	Moderation ModerationConfig         `mapstructure:"moderation"`
+	Custom     []CustomExtractorConfig  `mapstructure:"custom"`
+	Facts      FactsExtractorConfig     `mapstructure:"facts"`
+	Pipeline   []PipelineStageConfig    `mapstructure:"pipeline"`
 }
 
 type DocumentExtractorsConfig struct {
-	Embeddings EmbeddingsConfig `mapstructure:"embeddings"`
+	Embeddings   EmbeddingsConfig   `mapstructure:"embeddings"`
+	URLIngestion URLIngestionConfig `mapstructure:"url_ingestion"`
+}
+
+// URLIngestionConfig configures the fetching of URLs ingested into document collections
+type URLIngestionConfig struct {
+	// MaxSize is the size, in bytes, of the largest page that's fetched. Defaults to 10MB.
+	MaxSize int64 `mapstructure:"max_size"`
+	// Timeout is the time allowed to fetch a page, in seconds. Defaults to 30.
+	Timeout int `mapstructure:"timeout"`
+	// UserAgent is sent when fetching pages, and its first word selects the robots.txt
+	// rules that are respected. Defaults to zep.
+	UserAgent string `mapstructure:"user_agent"`
+	// AllowPrivateNetworks allows URLs on loopback, private and link-local addresses to be
+	// fetched. It's disabled so that API clients can't reach services on Zep's network.
+	AllowPrivateNetworks bool `mapstructure:"allow_private_networks"`
 }
 
 type SummarizerConfig struct {
-	Enabled    bool                  `mapstructure:"enabled"`
-	Embeddings EmbeddingsConfig      `mapstructure:"embeddings"`
-	Entities   EntityExtractorConfig `mapstructure:"entities"`
+	Enabled      bool                      `mapstructure:"enabled"`
+	Embeddings   EmbeddingsConfig          `mapstructure:"embeddings"`
+	Entities     EntityExtractorConfig     `mapstructure:"entities"`
+	LLM          ExtractorLLMConfig        `mapstructure:"llm"`
+	Hierarchical HierarchicalSummaryConfig `mapstructure:"hierarchical"`
+}
+
+// HierarchicalSummaryConfig configures hierarchical summarization. Each window of messages is
+// summarized on its own, rather than being added to the previous summary, and window
+// summaries older than the most recent WindowSummaries are summarized into a session abstract.
+type HierarchicalSummaryConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// WindowSummaries is the number of the most recent window summaries returned with the
+	// abstract. Defaults to 3.
+	WindowSummaries int `mapstructure:"window_summaries"`
+}
+
+// ExtractorLLMConfig overrides the llm service and model used by an extractor. Empty values
+// use llm.service and llm.model. The service's other settings are taken from llm.
+type ExtractorLLMConfig struct {
+	Service string `mapstructure:"service"`
+	Model   string `mapstructure:"model"`
 }
 
 type CustomPromptsConfig struct {
@@ -118,14 +457,128 @@ type EmbeddingsConfig struct {
 	Enabled    bool   `mapstructure:"enabled"`
 	Dimensions int    `mapstructure:"dimensions"`
 	Service    string `mapstructure:"service"`
+	// Model is the embedding model of the cohere service. Empty uses llm.cohere.embedding_model.
+	Model string `mapstructure:"model"`
 	// ChunkSize is the number of documents to embed in a single task.
 	ChunkSize int `mapstructure:"chunk_size"`
+	// BatchSize is the maximum number of texts sent in an embedding request. Texts embedded
+	// concurrently, by different tasks, are batched together. Zero doesn't batch them.
+	BatchSize int `mapstructure:"batch_size"`
+	// BatchMaxWait is how long texts wait for a batch to fill before it's sent. Defaults to
+	// 100ms.
+	BatchMaxWait time.Duration `mapstructure:"batch_max_wait"`
+	// MaxConcurrency limits the concurrent embedding requests. Zero doesn't limit them.
+	MaxConcurrency int `mapstructure:"max_concurrency"`
 }
 
 type EntityExtractorConfig struct {
 	Enabled bool `mapstructure:"enabled"`
 }
 
+// PIIRedactorConfig configures the redaction of personally identifiable information from
+// messages. Messages are redacted as they're stored, so before they're sent to any llm.
+type PIIRedactorConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// EntityTypes are the types of PII to redact: email, phone, ssn and credit_card.
+	// Defaults to all of them.
+	EntityTypes []string `mapstructure:"entity_types"`
+	// Strategy is how PII is redacted. redact, the default, replaces it with a placeholder
+	// such as [EMAIL], and mask with asterisks. metadata leaves the content as-is. Whatever
+	// the strategy, the PII found is recorded in the message's system metadata.
+	Strategy string `mapstructure:"strategy"`
+}
+
 type IntentExtractorConfig struct {
+	Enabled bool               `mapstructure:"enabled"`
+	LLM     ExtractorLLMConfig `mapstructure:"llm"`
+}
+
+// FactsExtractorConfig configures the extraction of stable facts about users from their
+// sessions' messages. Sessions without a user are skipped.
+type FactsExtractorConfig struct {
+	Enabled bool               `mapstructure:"enabled"`
+	LLM     ExtractorLLMConfig `mapstructure:"llm"`
+	// MemoryFacts is the number of the user's most recently updated facts returned with
+	// memory. Defaults to 20.
+	MemoryFacts int `mapstructure:"memory_facts"`
+}
+
+// SentimentExtractorConfig configures the annotation of messages with their sentiment
+type SentimentExtractorConfig struct {
 	Enabled bool `mapstructure:"enabled"`
+	// Service is llm, the default, to prompt the llm, or local to use the NLP server
+	Service string `mapstructure:"service"`
+	// LLM is used if Service is llm
+	LLM ExtractorLLMConfig `mapstructure:"llm"`
+}
+
+// LanguageExtractorConfig configures the detection of the language of messages. Detection
+// runs in-process and doesn't call an llm.
+type LanguageExtractorConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// ModerationConfig configures the moderation of messages for harmful content
+type ModerationConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Service is openai, the default, to use the OpenAI moderation API, or local to use the
+	// NLP server
+	Service string `mapstructure:"service"`
+	// Threshold is the category score, between 0 and 1, at which a message is flagged.
+	// Defaults to 0.5.
+	Threshold float64 `mapstructure:"threshold"`
+	// RejectThreshold, if set, is the category score at which messages are rejected. Messages
+	// are then moderated as they're stored, and PutMemory fails if any message is rejected.
+	RejectThreshold float64 `mapstructure:"reject_threshold"`
+}
+
+// CustomExtractorConfig configures an external extractor. New messages are POSTed to URL,
+// and the metadata returned for each message is merged into the message's metadata.
+type CustomExtractorConfig struct {
+	// Name identifies the extractor in requests and logs
+	Name string `mapstructure:"name"`
+	URL  string `mapstructure:"url"`
+	// Secret, if set, is used to sign requests with HMAC-SHA256. Responses must then be
+	// signed with the same secret.
+	Secret string `mapstructure:"secret"`
+	// Timeout is the timeout, in seconds, of each attempt. Defaults to 10.
+	Timeout int `mapstructure:"timeout"`
+	// MaxRetries is the number of times a failed request is retried. Defaults to 3.
+	MaxRetries int `mapstructure:"max_retries"`
+}
+
+// WebhookConfig configures a webhook notified of memory events
+type WebhookConfig struct {
+	URL string `mapstructure:"url"`
+	// Secret, if set, is used to sign requests with HMAC-SHA256
+	Secret string `mapstructure:"secret"`
+	// Events are the types of the events sent to the webhook, such as session.created. If
+	// empty, all events are sent.
+	Events []string `mapstructure:"events"`
+	// Timeout is the timeout, in seconds, of each attempt. Defaults to 10.
+	Timeout int `mapstructure:"timeout"`
+	// MaxRetries is the number of times a failed request is retried. Defaults to 3.
+	MaxRetries int `mapstructure:"max_retries"`
+}
+
+// KafkaConfig configures the publishing of memory events to Kafka. Events are published if
+// Brokers are set, to the topics that are set.
+type KafkaConfig struct {
+	Brokers []string `mapstructure:"brokers"`
+	// MessageTopic receives an event for each message added to a session
+	MessageTopic string `mapstructure:"message_topic"`
+	// SummaryTopic receives an event for each summary created
+	SummaryTopic string `mapstructure:"summary_topic"`
+}
+
+// PipelineStageConfig configures a stage of the message extractor pipeline. The pipeline
+// runs its stages one after another, in the order they're listed. Extractors that aren't
+// listed run independently, in parallel with the pipeline.
+type PipelineStageConfig struct {
+	// Name is the extractor's task topic, such as message_ner
+	Name string `mapstructure:"name"`
+	// Enabled, if false, disables the stage even if its extractor is enabled
+	Enabled *bool `mapstructure:"enabled"`
+	// DependsOn names stages, listed before this one, that must succeed for it to run
+	DependsOn []string `mapstructure:"depends_on"`
 }
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"context"
 	"os"
 	"sync"
 
@@ -33,6 +34,33 @@ func SetLogLevel(level logrus.Level) {
 	logger.SetLevel(level)
 }
 
+// RequestIDField is the log field, and task metadata key, of the ID of the request that
+// started an operation. Tasks published while serving a request carry its ID, so that the
+// logs of a request and of the extractors it triggered can be correlated.
+const RequestIDField = "request_id"
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the ID of the request it serves
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID of ctx, or an empty string if it has none
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// LoggerFromContext returns the logger with the request ID of ctx, if any, as a field
+func LoggerFromContext(ctx context.Context) *logrus.Entry {
+	entry := logrus.NewEntry(GetLogger())
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		entry = entry.WithField(RequestIDField, requestID)
+	}
+	return entry
+}
+
 // LeveledLogger is an interface that wraps the logrus Logger interface
 type LeveledLogger interface {
 	Error(msg string, keysAndValues ...interface{})
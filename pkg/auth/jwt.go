@@ -1,26 +1,34 @@
 package auth
 
 import (
+	"context"
 	"log"
 	"net/http"
 
 	"github.com/go-chi/jwtauth/v5"
+	"github.com/lestrrat-go/jwx/v2/jwt"
 
 	"github.com/getzep/zep/config"
 )
 
 const JwtAlg = "HS256"
 
-// GenerateJWT generates a JWT token using the given config.
+// GenerateJWT generates a JWT token using the given config. If projectID isn't empty, the
+// token is scoped to the project.
 // Requires that ZEP_AUTH_SECRET is set in the environment.
-func GenerateJWT(cfg *config.Config) string {
+func GenerateJWT(cfg *config.Config, projectID string) string {
 	secret := []byte(cfg.Auth.Secret)
 	if len(secret) == 0 {
 		log.Fatal("Auth secret not set. Ensure ZEP_AUTH_SECRET is set in your environment.")
 	}
 
+	var claims map[string]interface{}
+	if projectID != "" {
+		claims = map[string]interface{}{ProjectIDClaim: projectID}
+	}
+
 	tokenAuth := jwtauth.New(JwtAlg, secret, nil)
-	_, tokenString, err := tokenAuth.Encode(nil)
+	_, tokenString, err := tokenAuth.Encode(claims)
 	if err != nil {
 		log.Fatal("Error generating auth token: ", err)
 	}
@@ -28,11 +36,84 @@ func GenerateJWT(cfg *config.Config) string {
 	return tokenString
 }
 
+// JWTVerifier returns a middleware that verifies the JWT of the Authorization header or jwt
+// cookie of requests with the verifier of NewTokenVerifier. The token and any error are added
+// to the request context for jwtauth.Authenticator.
 func JWTVerifier(cfg *config.Config) func(http.Handler) http.Handler {
+	verifier := NewTokenVerifier(cfg)
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			var token jwt.Token
+			err := jwtauth.ErrNoTokenFound
+			tokenString := jwtauth.TokenFromHeader(r)
+			if tokenString == "" {
+				tokenString = jwtauth.TokenFromCookie(r)
+			}
+			if tokenString != "" {
+				token, err = verifier.VerifyToken(r.Context(), tokenString)
+			}
+			next.ServeHTTP(w, r.WithContext(jwtauth.NewContext(r.Context(), token, err)))
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// TokenVerifier verifies the JWTs authenticating requests
+type TokenVerifier interface {
+	VerifyToken(ctx context.Context, tokenString string) (jwt.Token, error)
+}
+
+// NewTokenVerifier returns an OIDCVerifier if an OIDC issuer is configured, and otherwise a
+// verifier of JWTs signed with the auth secret
+func NewTokenVerifier(cfg *config.Config) TokenVerifier {
+	if cfg.Auth.OIDC.Issuer != "" {
+		return NewOIDCVerifier(&cfg.Auth.OIDC)
+	}
+	return &secretVerifier{tokenAuth: NewJWTAuth(cfg)}
+}
+
+type secretVerifier struct {
+	tokenAuth *jwtauth.JWTAuth
+}
+
+func (v *secretVerifier) VerifyToken(_ context.Context, tokenString string) (jwt.Token, error) {
+	return jwtauth.VerifyToken(v.tokenAuth, tokenString)
+}
+
+// NewJWTAuth returns a JWTAuth that verifies tokens signed with the configured auth secret.
+// Requires that ZEP_AUTH_SECRET is set in the environment.
+func NewJWTAuth(cfg *config.Config) *jwtauth.JWTAuth {
 	secret := []byte(cfg.Auth.Secret)
 	if len(secret) == 0 {
 		log.Fatal("Auth secret not set. Ensure ZEP_AUTH_SECRET is set in your environment.")
 	}
-	tokenAuth := jwtauth.New(JwtAlg, secret, nil)
-	return jwtauth.Verifier(tokenAuth)
+	return jwtauth.New(JwtAlg, secret, nil)
+}
+
+// TenantIDClaim is the JWT claim identifying the tenant making a request
+const TenantIDClaim = "tenant_id"
+
+// TenantIDFromContext returns the tenant ID claim of the JWT in ctx. An empty string is
+// returned if there is no token or the token has no tenant ID.
+func TenantIDFromContext(ctx context.Context) string {
+	_, claims, err := jwtauth.FromContext(ctx)
+	if err != nil {
+		return ""
+	}
+	tenantID, _ := claims[TenantIDClaim].(string)
+	return tenantID
+}
+
+// ProjectIDClaim is the JWT claim scoping a request to a project
+const ProjectIDClaim = "project_id"
+
+// ProjectIDFromContext returns the project ID claim of the JWT in ctx. An empty string is
+// returned if there is no token or the token has no project ID.
+func ProjectIDFromContext(ctx context.Context) string {
+	_, claims, err := jwtauth.FromContext(ctx)
+	if err != nil {
+		return ""
+	}
+	projectID, _ := claims[ProjectIDClaim].(string)
+	return projectID
 }
@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -24,7 +25,7 @@ func TestGenerateKey(t *testing.T) {
 		},
 	}
 
-	token := GenerateJWT(cfg)
+	token := GenerateJWT(cfg, "")
 
 	// Validate the generated token
 	claims := jwt.MapClaims{}
@@ -37,6 +38,24 @@ func TestGenerateKey(t *testing.T) {
 	}
 }
 
+func TestGenerateKey_Project(t *testing.T) {
+	cfg := &config.Config{
+		Auth: config.AuthConfig{
+			Secret: "test-secret",
+		},
+	}
+
+	token, err := jwtauth.VerifyToken(
+		jwtauth.New(JwtAlg, []byte(cfg.Auth.Secret), nil),
+		GenerateJWT(cfg, "acme"),
+	)
+	require.NoError(t, err)
+
+	ctx := jwtauth.NewContext(context.Background(), token, nil)
+	assert.Equal(t, "acme", ProjectIDFromContext(ctx))
+	assert.Empty(t, ProjectIDFromContext(context.Background()))
+}
+
 func TestJWTVerifier(t *testing.T) {
 	cfg := &config.Config{
 		Auth: config.AuthConfig{
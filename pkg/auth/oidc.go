@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+
+	"github.com/getzep/zep/config"
+)
+
+const (
+	// oidcKeysTTL is how long the issuer's key set is cached before it's fetched again
+	oidcKeysTTL = time.Hour
+	// oidcKeysMinRefresh is the least time between fetches of the issuer's key set when
+	// tokens fail verification, so that keys rotated by the issuer are picked up without
+	// invalid tokens causing a fetch each
+	oidcKeysMinRefresh = time.Minute
+	// oidcSkew is the clock skew allowed when validating the times of tokens
+	oidcSkew = 30 * time.Second
+)
+
+// OIDCVerifier verifies JWTs issued by an external OIDC issuer, against the keys of the
+// issuer's JWKS. The JWKS URL is discovered from the issuer's OpenID configuration, unless
+// it's configured, when the first token is verified.
+type OIDCVerifier struct {
+	issuer     string
+	audience   string
+	jwksURL    string
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	keys      jwk.Set
+	fetchedAt time.Time
+}
+
+func NewOIDCVerifier(cfg *config.OIDCConfig) *OIDCVerifier {
+	return &OIDCVerifier{
+		issuer:     cfg.Issuer,
+		audience:   cfg.Audience,
+		jwksURL:    cfg.JWKSURL,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// VerifyToken verifies the signature, issuer, audience and times of a token
+func (v *OIDCVerifier) VerifyToken(ctx context.Context, tokenString string) (jwt.Token, error) {
+	keys, err := v.keySet(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	token, err := v.parse(tokenString, keys)
+	if err == nil {
+		return token, nil
+	}
+
+	// the issuer may have rotated its keys
+	refreshed, refreshErr := v.keySet(ctx, true)
+	if refreshErr != nil || refreshed == keys {
+		return nil, err
+	}
+	return v.parse(tokenString, refreshed)
+}
+
+func (v *OIDCVerifier) parse(tokenString string, keys jwk.Set) (jwt.Token, error) {
+	options := []jwt.ParseOption{
+		jwt.WithKeySet(keys, jws.WithInferAlgorithmFromKey(true)),
+		jwt.WithValidate(true),
+		jwt.WithIssuer(v.issuer),
+		jwt.WithAcceptableSkew(oidcSkew),
+	}
+	if v.audience != "" {
+		options = append(options, jwt.WithAudience(v.audience))
+	}
+	return jwt.ParseString(tokenString, options...)
+}
+
+// keySet returns the cached key set of the issuer, fetching it if it's expired. If refresh is
+// true, the key set is fetched unless it was fetched in the last oidcKeysMinRefresh.
+func (v *OIDCVerifier) keySet(ctx context.Context, refresh bool) (jwk.Set, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	age := time.Since(v.fetchedAt)
+	if v.keys != nil && age < oidcKeysTTL && (!refresh || age < oidcKeysMinRefresh) {
+		return v.keys, nil
+	}
+
+	if v.jwksURL == "" {
+		jwksURL, err := v.discoverJWKSURL(ctx)
+		if err != nil {
+			return nil, err
+		}
+		v.jwksURL = jwksURL
+	}
+
+	keys, err := jwk.Fetch(ctx, v.jwksURL, jwk.WithHTTPClient(v.httpClient))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch oidc key set: %w", err)
+	}
+	v.keys = keys
+	v.fetchedAt = time.Now()
+	return keys, nil
+}
+
+// discoverJWKSURL returns the JWKS URL of the issuer's OpenID configuration
+func (v *OIDCVerifier) discoverJWKSURL(ctx context.Context) (string, error) {
+	discoveryURL := strings.TrimSuffix(v.issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create oidc discovery request: %w", err)
+	}
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get oidc configuration: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get oidc configuration: %s", resp.Status)
+	}
+
+	var discovery struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&discovery); err != nil {
+		return "", fmt.Errorf("failed to decode oidc configuration: %w", err)
+	}
+	if discovery.Issuer != v.issuer {
+		return "", fmt.Errorf(
+			"oidc configuration issuer %s doesn't match %s",
+			discovery.Issuer,
+			v.issuer,
+		)
+	}
+	if discovery.JWKSURI == "" {
+		return "", fmt.Errorf("oidc configuration of %s has no jwks_uri", v.issuer)
+	}
+	return discovery.JWKSURI, nil
+}
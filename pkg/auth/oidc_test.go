@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/config"
+)
+
+type testIssuer struct {
+	*httptest.Server
+	key       jwk.Key
+	jwksCalls int
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	issuer := &testIssuer{}
+	issuer.rotateKey(t, "key1")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(
+		"/.well-known/openid-configuration",
+		func(w http.ResponseWriter, _ *http.Request) {
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"issuer":   issuer.URL,
+				"jwks_uri": issuer.URL + "/jwks",
+			})
+		},
+	)
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, _ *http.Request) {
+		issuer.jwksCalls++
+		publicKey, err := issuer.key.PublicKey()
+		require.NoError(t, err)
+		keys := jwk.NewSet()
+		require.NoError(t, keys.AddKey(publicKey))
+		_ = json.NewEncoder(w).Encode(keys)
+	})
+	issuer.Server = httptest.NewServer(mux)
+	t.Cleanup(issuer.Close)
+	return issuer
+}
+
+func (i *testIssuer) rotateKey(t *testing.T, kid string) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	key, err := jwk.FromRaw(rsaKey)
+	require.NoError(t, err)
+	require.NoError(t, key.Set(jwk.KeyIDKey, kid))
+	i.key = key
+}
+
+func (i *testIssuer) token(t *testing.T, issuer, audience string, expiry time.Time) string {
+	token, err := jwt.NewBuilder().
+		Issuer(issuer).
+		Audience([]string{audience}).
+		Expiration(expiry).
+		Claim(ProjectIDClaim, "acme").
+		Build()
+	require.NoError(t, err)
+	signed, err := jwt.Sign(token, jwt.WithKey(jwa.RS256, i.key))
+	require.NoError(t, err)
+	return string(signed)
+}
+
+func TestOIDCVerifier(t *testing.T) {
+	issuer := newTestIssuer(t)
+	verifier := NewTokenVerifier(&config.Config{
+		Auth: config.AuthConfig{
+			OIDC: config.OIDCConfig{Issuer: issuer.URL, Audience: "zep"},
+		},
+	})
+	ctx := context.Background()
+	expiry := time.Now().Add(time.Hour)
+
+	token, err := verifier.VerifyToken(ctx, issuer.token(t, issuer.URL, "zep", expiry))
+	require.NoError(t, err)
+	projectID, _ := token.Get(ProjectIDClaim)
+	assert.Equal(t, "acme", projectID)
+
+	for name, tokenString := range map[string]string{
+		"wrong issuer":   issuer.token(t, "https://other.example.com", "zep", expiry),
+		"wrong audience": issuer.token(t, issuer.URL, "other", expiry),
+		"expired":        issuer.token(t, issuer.URL, "zep", time.Now().Add(-time.Hour)),
+		"not a jwt":      "invalid",
+	} {
+		_, err := verifier.VerifyToken(ctx, tokenString)
+		assert.Error(t, err, name)
+	}
+	// the key set is cached
+	assert.Equal(t, 1, issuer.jwksCalls)
+}
+
+func TestOIDCVerifier_KeyRotation(t *testing.T) {
+	issuer := newTestIssuer(t)
+	verifier := NewOIDCVerifier(&config.OIDCConfig{
+		Issuer:  issuer.URL,
+		JWKSURL: issuer.URL + "/jwks",
+	})
+	ctx := context.Background()
+	expiry := time.Now().Add(time.Hour)
+
+	_, err := verifier.VerifyToken(ctx, issuer.token(t, issuer.URL, "zep", expiry))
+	require.NoError(t, err)
+
+	// tokens of a rotated key verify once the key set is refreshed
+	issuer.rotateKey(t, "key2")
+	verifier.fetchedAt = time.Now().Add(-oidcKeysMinRefresh)
+	_, err = verifier.VerifyToken(ctx, issuer.token(t, issuer.URL, "zep", expiry))
+	require.NoError(t, err)
+	assert.Equal(t, 2, issuer.jwksCalls)
+}
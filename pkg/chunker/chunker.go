@@ -0,0 +1,376 @@
+// Package chunker splits documents into chunks small enough to be embedded and retrieved
+// on their own.
+package chunker
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/getzep/zep/pkg/models"
+)
+
+const (
+	// StrategyToken splits documents into windows of words
+	StrategyToken = "token"
+	// StrategySentence splits documents between sentences
+	StrategySentence = "sentence"
+	// StrategyMarkdown splits documents at their headings, then between paragraphs
+	StrategyMarkdown = "markdown"
+)
+
+// DefaultChunkSize is the chunk size, in tokens, of chunking configurations that don't set one
+const DefaultChunkSize = 512
+
+// charsPerToken estimates the length of a token, as the embedding model's tokenizer isn't
+// known. It's the estimate the LLM clients use for models without a known tokenizer.
+const charsPerToken = 4
+
+var (
+	wordPattern = regexp.MustCompile(`\S+`)
+	// sentenceBoundaryPattern matches the end of a sentence and the whitespace following it,
+	// or a paragraph break
+	sentenceBoundaryPattern = regexp.MustCompile(`[.!?]+["'”’)\]]*\s+|\n[ \t]*\n`)
+	headingPattern          = regexp.MustCompile(`^ {0,3}#{1,6}(\s|$)`)
+)
+
+// Validate returns an error if chunking isn't a valid chunking configuration
+func Validate(chunking *models.DocumentChunking) error {
+	switch chunking.Strategy {
+	case StrategyToken, StrategySentence, StrategyMarkdown:
+	default:
+		return fmt.Errorf("unknown chunking strategy: %s", chunking.Strategy)
+	}
+	if chunking.ChunkSize < 0 {
+		return fmt.Errorf("chunk size must not be negative")
+	}
+	if chunking.ChunkOverlap < 0 || chunking.ChunkOverlap >= chunkSize(chunking) {
+		return fmt.Errorf("chunk overlap must be at least 0 and less than the chunk size")
+	}
+	return nil
+}
+
+// Chunk splits text into chunks of at most the configured chunk size using the configured
+// strategy. Consecutive chunks repeat up to the configured overlap of tokens. Text that fits
+// in a single chunk is returned as is, and text that's only whitespace has no chunks.
+func Chunk(text string, chunking *models.DocumentChunking) ([]string, error) {
+	if err := Validate(chunking); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+	c := &chunker{
+		text:    text,
+		size:    chunkSize(chunking),
+		overlap: chunking.ChunkOverlap,
+	}
+	if c.tokens(utf8.RuneCountInString(text)) <= c.size {
+		return []string{text}, nil
+	}
+
+	switch chunking.Strategy {
+	case StrategyToken:
+		return c.texts(c.pack(c.fit(c.words(0, len(text))))), nil
+	case StrategySentence:
+		return c.texts(c.pack(c.fit(c.sentences(0, len(text)), c.words))), nil
+	default:
+		return c.markdown(), nil
+	}
+}
+
+// ChunkDocuments splits documents into chunks, returning a document for each chunk.
+// Documents that are already chunks are returned as they are. Chunks have no document_id of
+// their own, as document_ids are unique. Instead, they're linked to the document they were
+// split from by its document_id, or by an ID generated for it if it has none, and by their
+// index in it. Chunks copy the metadata of their document.
+func ChunkDocuments(
+	documents []models.Document,
+	chunking *models.DocumentChunking,
+) ([]models.Document, error) {
+	var chunks []models.Document
+	for i := range documents {
+		if documents[i].ParentDocumentID != "" {
+			chunks = append(chunks, documents[i])
+			continue
+		}
+
+		texts, err := Chunk(documents[i].Content, chunking)
+		if err != nil {
+			return nil, err
+		}
+
+		parentID := documents[i].DocumentID
+		if parentID == "" {
+			parentID = uuid.New().String()
+		}
+		for j, text := range texts {
+			chunk := models.Document{}
+			chunk.Content = text
+			chunk.Metadata = documents[i].Metadata
+			chunk.ParentDocumentID = parentID
+			chunk.ChunkIndex = j
+			chunks = append(chunks, chunk)
+		}
+	}
+
+	return chunks, nil
+}
+
+func chunkSize(chunking *models.DocumentChunking) int {
+	if chunking.ChunkSize == 0 {
+		return DefaultChunkSize
+	}
+	return chunking.ChunkSize
+}
+
+// span is a range of bytes of the text being chunked
+type span struct {
+	start, end int
+}
+
+type chunker struct {
+	text    string
+	size    int
+	overlap int
+}
+
+// tokens estimates the number of tokens of a text of the given number of runes
+func (c *chunker) tokens(runes int) int {
+	return (runes + charsPerToken - 1) / charsPerToken
+}
+
+func (c *chunker) runes(start, end int) int {
+	return utf8.RuneCountInString(c.text[start:end])
+}
+
+func (c *chunker) texts(spans []span) []string {
+	texts := make([]string, len(spans))
+	for i, s := range spans {
+		texts[i] = c.text[s.start:s.end]
+	}
+	return texts
+}
+
+// fit returns units, replacing each unit larger than a chunk with the units it's split into
+// by the first of splits. Units that are still too large are split by the remaining splits
+// in turn, and units too large to be split any further are cut into chunk-sized pieces.
+func (c *chunker) fit(units []span, splits ...func(start, end int) []span) []span {
+	var fitted []span
+	for _, u := range units {
+		switch {
+		case c.tokens(c.runes(u.start, u.end)) <= c.size:
+			fitted = append(fitted, u)
+		case len(splits) > 0:
+			fitted = append(fitted, c.fit(splits[0](u.start, u.end), splits[1:]...)...)
+		default:
+			fitted = append(fitted, c.cut(u)...)
+		}
+	}
+	return fitted
+}
+
+// cut cuts a unit into pieces of a chunk's worth of runes
+func (c *chunker) cut(u span) []span {
+	var pieces []span
+	maxRunes := c.size * charsPerToken
+	start, runes := u.start, 0
+	for i := range c.text[u.start:u.end] {
+		if runes == maxRunes {
+			pieces = append(pieces, span{start, u.start + i})
+			start, runes = u.start+i, 0
+		}
+		runes++
+	}
+	return append(pieces, span{start, u.end})
+}
+
+// pack packs consecutive units into chunks of at most the chunk size. Each chunk after the
+// first starts with as many of the previous chunk's last units as fit in the overlap, but
+// always with at least one unit the previous chunk didn't include.
+func (c *chunker) pack(units []span) []span {
+	var chunks []span
+	for i := 0; i < len(units); {
+		runes := c.runes(units[i].start, units[i].end)
+		j := i + 1
+		for ; j < len(units); j++ {
+			r := runes + c.runes(units[j-1].end, units[j].end)
+			if c.tokens(r) > c.size {
+				break
+			}
+			runes = r
+		}
+		chunks = append(chunks, span{units[i].start, units[j-1].end})
+		if j == len(units) {
+			break
+		}
+
+		next, tail := j, 0
+		for k := j - 1; k > i; k-- {
+			if k == j-1 {
+				tail = c.runes(units[k].start, units[k].end)
+			} else {
+				tail += c.runes(units[k].start, units[k+1].start)
+			}
+			if c.tokens(tail) > c.overlap {
+				break
+			}
+			next = k
+		}
+		i = next
+	}
+	return chunks
+}
+
+// words returns the spans of the words between start and end
+func (c *chunker) words(start, end int) []span {
+	matches := wordPattern.FindAllStringIndex(c.text[start:end], -1)
+	spans := make([]span, len(matches))
+	for i, m := range matches {
+		spans[i] = span{start + m[0], start + m[1]}
+	}
+	return spans
+}
+
+// sentences returns the spans of the sentences between start and end. Paragraph breaks also
+// end sentences, so that headings and list items without punctuation aren't run together.
+func (c *chunker) sentences(start, end int) []span {
+	var spans []span
+	pos := start
+	for _, m := range sentenceBoundaryPattern.FindAllStringIndex(c.text[start:end], -1) {
+		spans = c.appendTrimmed(spans, pos, start+m[1])
+		pos = start + m[1]
+	}
+	return c.appendTrimmed(spans, pos, end)
+}
+
+// blocks returns the spans of the blocks of markdown between start and end: the runs of lines
+// between blank lines, keeping fenced code blocks whole
+func (c *chunker) blocks(start, end int) []span {
+	var spans []span
+	blockStart, inFence := start, false
+	for _, l := range c.lines(start, end) {
+		line := c.text[l.start:l.end]
+		if isFence(line) {
+			inFence = !inFence
+		}
+		if !inFence && strings.TrimSpace(line) == "" {
+			spans = c.appendTrimmed(spans, blockStart, l.start)
+			blockStart = l.end
+		}
+	}
+	return c.appendTrimmed(spans, blockStart, end)
+}
+
+// lines returns the spans of the lines between start and end, including their line breaks
+func (c *chunker) lines(start, end int) []span {
+	var spans []span
+	for start < end {
+		i := strings.IndexByte(c.text[start:end], '\n')
+		if i < 0 {
+			return append(spans, span{start, end})
+		}
+		spans = append(spans, span{start, start + i + 1})
+		start += i + 1
+	}
+	return spans
+}
+
+// appendTrimmed appends the span between start and end, less its leading and trailing
+// whitespace, to spans. Spans that are only whitespace aren't appended.
+func (c *chunker) appendTrimmed(spans []span, start, end int) []span {
+	s := c.text[start:end]
+	trimmed := strings.TrimLeftFunc(s, unicode.IsSpace)
+	start += len(s) - len(trimmed)
+	trimmed = strings.TrimRightFunc(trimmed, unicode.IsSpace)
+	if trimmed == "" {
+		return spans
+	}
+	return append(spans, span{start, start + len(trimmed)})
+}
+
+// section is the text from a markdown heading to the next heading of any level
+type section struct {
+	span
+	// headings are the heading lines of the section and of the sections it's nested in
+	headings []string
+}
+
+// markdown splits the text into sections at its headings, and each section into chunks of
+// its blocks, splitting blocks that don't fit in a chunk between sentences. Chunks that
+// don't start with their section's heading are prefixed with it, and every chunk is
+// prefixed with the headings of the sections its section is nested in, so that chunks keep
+// the context of their place in the document.
+func (c *chunker) markdown() []string {
+	var chunks []string
+	for _, sec := range c.sections() {
+		units := c.fit(c.blocks(sec.start, sec.end), c.sentences, c.words)
+		for _, s := range c.pack(units) {
+			headings := sec.headings
+			// the first chunk of a section starts with its heading
+			if len(headings) > 0 && s.start == units[0].start {
+				headings = headings[:len(headings)-1]
+			}
+			text := c.text[s.start:s.end]
+			if len(headings) > 0 {
+				text = strings.Join(headings, "\n") + "\n\n" + text
+			}
+			chunks = append(chunks, text)
+		}
+	}
+	return chunks
+}
+
+// sections splits the text at its headings, ignoring lines starting with # in fenced code
+// blocks. Text before the first heading is a section without headings.
+func (c *chunker) sections() []section {
+	type heading struct {
+		level int
+		line  string
+	}
+
+	var (
+		sections []section
+		stack    []heading
+		inFence  bool
+	)
+	current := section{}
+	for _, l := range c.lines(0, len(c.text)) {
+		line := strings.TrimRight(c.text[l.start:l.end], "\r\n")
+		if isFence(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence || !headingPattern.MatchString(line) {
+			continue
+		}
+
+		current.end = l.start
+		sections = append(sections, current)
+
+		line = strings.TrimSpace(line)
+		level := len(line) - len(strings.TrimLeft(line, "#"))
+		for len(stack) > 0 && stack[len(stack)-1].level >= level {
+			stack = stack[:len(stack)-1]
+		}
+		stack = append(stack, heading{level: level, line: line})
+
+		headings := make([]string, len(stack))
+		for i, h := range stack {
+			headings[i] = h.line
+		}
+		current = section{span: span{start: l.start}, headings: headings}
+	}
+	current.end = len(c.text)
+	return append(sections, current)
+}
+
+func isFence(line string) bool {
+	line = strings.TrimLeft(line, " ")
+	return strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~")
+}
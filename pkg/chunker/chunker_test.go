@@ -0,0 +1,169 @@
+package chunker
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/pkg/models"
+)
+
+func TestChunk(t *testing.T) {
+	markdown := "# Guide\nIntro text.\n\n" +
+		"## Install\nRun the installer.\n\n" +
+		"```sh\n# not a heading\n\nmake install\n```\n\n" +
+		"## Usage\nUse it well. Then use it again and again and again.\n"
+
+	tests := []struct {
+		name     string
+		text     string
+		chunking models.DocumentChunking
+		expected []string
+	}{
+		{
+			name:     "token windows",
+			text:     "one two three four five six seven eight nine ten",
+			chunking: models.DocumentChunking{Strategy: StrategyToken, ChunkSize: 4},
+			expected: []string{"one two three", "four five six", "seven eight nine", "ten"},
+		},
+		{
+			name: "token windows with overlap",
+			text: "one two three four five six seven eight nine ten",
+			chunking: models.DocumentChunking{
+				Strategy:     StrategyToken,
+				ChunkSize:    4,
+				ChunkOverlap: 2,
+			},
+			expected: []string{
+				"one two three",
+				"three four five",
+				"five six seven",
+				"seven eight nine",
+				"nine ten",
+			},
+		},
+		{
+			name:     "words longer than a chunk are cut",
+			text:     "abcdefghijklmnopqrst",
+			chunking: models.DocumentChunking{Strategy: StrategyToken, ChunkSize: 2},
+			expected: []string{"abcdefgh", "ijklmnop", "qrst"},
+		},
+		{
+			name:     "sentences",
+			text:     "The cat sat. The dog ran far away! Birds fly.",
+			chunking: models.DocumentChunking{Strategy: StrategySentence, ChunkSize: 6},
+			expected: []string{"The cat sat.", "The dog ran far away!", "Birds fly."},
+		},
+		{
+			name:     "markdown sections",
+			text:     markdown,
+			chunking: models.DocumentChunking{Strategy: StrategyMarkdown, ChunkSize: 12},
+			expected: []string{
+				"# Guide\nIntro text.",
+				"# Guide\n\n## Install\nRun the installer.",
+				"# Guide\n## Install\n\n```sh\n# not a heading\n\nmake install\n```",
+				"# Guide\n\n## Usage\nUse it well.",
+				"# Guide\n## Usage\n\nThen use it again and again and again.",
+			},
+		},
+		{
+			name:     "text that fits in a chunk",
+			text:     "  Hello there.\n",
+			chunking: models.DocumentChunking{Strategy: StrategySentence},
+			expected: []string{"  Hello there.\n"},
+		},
+		{
+			name:     "whitespace",
+			text:     " \n\t",
+			chunking: models.DocumentChunking{Strategy: StrategyToken},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks, err := Chunk(tt.text, &tt.chunking)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, chunks)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		chunking models.DocumentChunking
+		valid    bool
+	}{
+		{"default size", models.DocumentChunking{Strategy: StrategyMarkdown}, true},
+		{
+			"overlap less than the default size",
+			models.DocumentChunking{Strategy: StrategyToken, ChunkOverlap: 64},
+			true,
+		},
+		{"unknown strategy", models.DocumentChunking{Strategy: "paragraph"}, false},
+		{
+			"overlap as large as the chunk",
+			models.DocumentChunking{Strategy: StrategyToken, ChunkSize: 64, ChunkOverlap: 64},
+			false,
+		},
+		{
+			"negative overlap",
+			models.DocumentChunking{Strategy: StrategyToken, ChunkOverlap: -1},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(&tt.chunking)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestChunkDocuments(t *testing.T) {
+	chunking := &models.DocumentChunking{Strategy: StrategyToken, ChunkSize: 4}
+	documents := []models.Document{
+		{DocumentBase: models.DocumentBase{
+			DocumentID: "doc1",
+			Content:    "one two three four five",
+			Metadata:   map[string]interface{}{"source": "test"},
+		}},
+		{DocumentBase: models.DocumentBase{Content: "six"}},
+		{DocumentBase: models.DocumentBase{
+			Content:          "seven eight nine ten eleven",
+			ParentDocumentID: "doc2",
+			ChunkIndex:       3,
+		}},
+	}
+
+	chunks, err := ChunkDocuments(documents, chunking)
+	require.NoError(t, err)
+	require.Equal(t, 4, len(chunks))
+
+	for i, expected := range []string{"one two three", "four five"} {
+		assert.Equal(t, expected, chunks[i].Content)
+		assert.Empty(t, chunks[i].DocumentID)
+		assert.Equal(t, "doc1", chunks[i].ParentDocumentID)
+		assert.Equal(t, i, chunks[i].ChunkIndex)
+		assert.Equal(t, documents[0].Metadata, chunks[i].Metadata)
+	}
+
+	// documents without a document_id are linked to their chunks by a generated ID
+	assert.Equal(t, "six", chunks[2].Content)
+	_, err = uuid.Parse(chunks[2].ParentDocumentID)
+	assert.NoError(t, err)
+
+	// chunks aren't chunked again
+	assert.Equal(t, documents[2], chunks[3])
+
+	_, err = ChunkDocuments(documents, &models.DocumentChunking{Strategy: "unknown"})
+	assert.Error(t, err)
+}
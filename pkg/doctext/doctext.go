@@ -0,0 +1,118 @@
+// Package doctext extracts the text of uploaded documents so that they can be chunked and
+// embedded.
+package doctext
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	FormatPDF      = "pdf"
+	FormatDOCX     = "docx"
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+	FormatText     = "text"
+)
+
+// ErrUnsupportedFormat is returned for files that aren't PDF, DOCX, Markdown, HTML or plain
+// text
+var ErrUnsupportedFormat = errors.New("unsupported file format")
+
+// extensions are the file extensions of each format
+var extensions = map[string]string{
+	".pdf":      FormatPDF,
+	".docx":     FormatDOCX,
+	".md":       FormatMarkdown,
+	".markdown": FormatMarkdown,
+	".html":     FormatHTML,
+	".htm":      FormatHTML,
+	".txt":      FormatText,
+	".text":     FormatText,
+}
+
+// mediaTypes are the media types of each format
+var mediaTypes = map[string]string{
+	"application/pdf": FormatPDF,
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document": FormatDOCX,
+	"text/markdown":         FormatMarkdown,
+	"text/x-markdown":       FormatMarkdown,
+	"text/html":             FormatHTML,
+	"application/xhtml+xml": FormatHTML,
+	"text/plain":            FormatText,
+}
+
+// FormatFromMediaType returns the format of a file with the given media type, such as the
+// Content-Type of a web page without its parameters
+func FormatFromMediaType(mediaType string) (string, error) {
+	format, ok := mediaTypes[strings.ToLower(mediaType)]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedFormat, mediaType)
+	}
+	return format, nil
+}
+
+// DetectFormat returns the format of a file from its extension, or from its content if the
+// extension isn't known. Files without a known extension are text if they're valid UTF-8.
+func DetectFormat(filename string, data []byte) (string, error) {
+	if format, ok := extensions[strings.ToLower(filepath.Ext(filename))]; ok {
+		return format, nil
+	}
+
+	switch {
+	case bytes.HasPrefix(data, []byte("%PDF-")):
+		return FormatPDF, nil
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")) &&
+		bytes.Contains(data, []byte("word/document.xml")):
+		return FormatDOCX, nil
+	case isHTML(data):
+		return FormatHTML, nil
+	case utf8.Valid(data):
+		return FormatText, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedFormat, filename)
+	}
+}
+
+// Extract returns the text of a file of the given format. The text of DOCX and HTML files is
+// returned as Markdown, with their headings as Markdown headings.
+func Extract(format string, data []byte) (string, error) {
+	var (
+		text string
+		err  error
+	)
+	switch format {
+	case FormatPDF:
+		text, err = extractPDF(data)
+	case FormatDOCX:
+		text, err = extractDOCX(data)
+	case FormatHTML:
+		text, err = extractHTML(data)
+	case FormatMarkdown, FormatText:
+		if !utf8.Valid(data) {
+			return "", errors.New("text is not valid UTF-8")
+		}
+		text = strings.TrimPrefix(string(data), "\ufeff")
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if strings.TrimSpace(text) == "" {
+		return "", errors.New("no text found in file")
+	}
+	return text, nil
+}
+
+// isHTML returns whether data starts with an HTML doctype or html element
+func isHTML(data []byte) bool {
+	start := bytes.ToLower(bytes.TrimLeft(data[:min(len(data), 512)], " \t\r\n\ufeff"))
+	return bytes.HasPrefix(start, []byte("<!doctype html")) ||
+		bytes.HasPrefix(start, []byte("<html"))
+}
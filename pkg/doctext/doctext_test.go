@@ -0,0 +1,188 @@
+package doctext
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPDF returns a PDF file with an uncompressed and a compressed content stream, and an
+// image stream that isn't page content
+func testPDF(t *testing.T) []byte {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	_, err := zw.Write([]byte(
+		"BT /F1 12 Tf 72 700 Td (Second page \\(compressed\\)) Tj T* " +
+			"<FEFF00630061006600E9> Tj ET",
+	))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.4\n")
+	pdf.WriteString("1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+	content := "BT /F1 12 Tf 72 712 Td (Hello, world!) Tj 0 -14 Td " +
+		"[(Sec) 10 (ond) -250 (line)] TJ ET"
+	fmt.Fprintf(&pdf, "4 0 obj\n<< /Length %d >>\nstream\n%s\nendstream\nendobj\n",
+		len(content), content)
+	image := "BT (not text) Tj ET"
+	fmt.Fprintf(&pdf,
+		"5 0 obj\n<< /Type /XObject /Subtype /Image /Length %d >>\nstream\n%s\nendstream\nendobj\n",
+		len(image), image)
+	fmt.Fprintf(&pdf, "6 0 obj\n<< /Length %d /Filter /FlateDecode >>\nstream\n",
+		compressed.Len())
+	pdf.Write(compressed.Bytes())
+	pdf.WriteString("\nendstream\nendobj\ntrailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return pdf.Bytes()
+}
+
+func testDOCX(t *testing.T) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	f, err := zw.Create("word/document.xml")
+	require.NoError(t, err)
+	_, err = f.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Title</w:t></w:r></w:p>
+<w:p><w:r><w:t xml:space="preserve">Hello </w:t></w:r><w:r><w:t>world</w:t><w:tab/><w:t>tabbed</w:t></w:r></w:p>
+<w:p></w:p>
+<w:p><w:pPr><w:pStyle w:val="Heading2"/></w:pPr><w:r><w:t>Section</w:t></w:r></w:p>
+<w:p><w:r><w:t>Body &amp; more</w:t></w:r></w:p>
+</w:body></w:document>`))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		data     []byte
+		format   string
+		expected string
+	}{
+		{
+			name:     "pdf",
+			filename: "report.pdf",
+			data:     testPDF(t),
+			format:   FormatPDF,
+			expected: "Hello, world!\nSecond line\n\nSecond page (compressed)\ncafé",
+		},
+		{
+			name:     "docx",
+			filename: "Report.DOCX",
+			data:     testDOCX(t),
+			format:   FormatDOCX,
+			expected: "# Title\n\nHello world\ttabbed\n\n## Section\n\nBody & more",
+		},
+		{
+			name:     "markdown",
+			filename: "README.md",
+			data:     []byte("# Title\n\nBody"),
+			format:   FormatMarkdown,
+			expected: "# Title\n\nBody",
+		},
+		{
+			name:     "text with a byte order mark",
+			filename: "notes.txt",
+			data:     []byte("\ufeffSome notes"),
+			format:   FormatText,
+			expected: "Some notes",
+		},
+		{
+			name:     "pdf without an extension",
+			filename: "upload",
+			data:     testPDF(t),
+			format:   FormatPDF,
+			expected: "Hello, world!\nSecond line\n\nSecond page (compressed)\ncafé",
+		},
+		{
+			name:     "text without an extension",
+			filename: "upload",
+			data:     []byte("plain"),
+			format:   FormatText,
+			expected: "plain",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, err := DetectFormat(tt.filename, tt.data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.format, format)
+
+			text, err := Extract(format, tt.data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, text)
+		})
+	}
+}
+
+func TestExtract_Errors(t *testing.T) {
+	_, err := DetectFormat("image.png", []byte{0x89, 'P', 'N', 'G', 0xff, 0xfe})
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+
+	_, err = Extract(FormatText, []byte{0xff, 0xfe})
+	assert.Error(t, err)
+
+	_, err = Extract(FormatText, []byte(" \n"))
+	assert.Error(t, err)
+
+	_, err = Extract(FormatPDF, []byte("%PDF-1.4\n%%EOF\n"))
+	assert.Error(t, err)
+
+	_, err = Extract(FormatDOCX, []byte("not a zip file"))
+	assert.Error(t, err)
+}
+
+func TestExtractHTML(t *testing.T) {
+	page := `<!DOCTYPE html>
+<html><head><title> Release
+  notes </title><script>var x = 1;</script><style>p { color: red }</style></head>
+<body class="has-sidebar">
+<header><a href="/">Home</a></header>
+<nav><ul><li>Docs</li><li>Blog</li></ul></nav>
+<div class="cookie-banner">We use cookies.</div>
+<main>
+  <article>
+    <header><h1>Release   notes</h1></header>
+    <p>Version 2 is <em>out</em>.<br>Upgrade now.</p>
+    <h2>Changes</h2>
+    <ul><li>Faster search</li><li>New <b>API</b></li><li></li></ul>
+    <pre>zep --version
+  2.0</pre>
+    <div class="share-buttons">Share this</div>
+    <footer>Posted today</footer>
+  </article>
+</main>
+<aside>Related posts</aside>
+<footer>Copyright</footer>
+</body></html>`
+
+	title, text, err := ExtractHTML([]byte(page))
+	require.NoError(t, err)
+	assert.Equal(t, "Release notes", title)
+	assert.Equal(
+		t,
+		"# Release notes\n\nVersion 2 is out. Upgrade now.\n\n## Changes\n\n"+
+			"- Faster search\n\n- New API\n\n```\nzep --version\n  2.0\n```\n\nPosted today",
+		text,
+	)
+
+	format, err := DetectFormat("index", []byte(page))
+	require.NoError(t, err)
+	assert.Equal(t, FormatHTML, format)
+
+	// pages without a main element are read from their body, without the page's header
+	_, text, err = ExtractHTML(
+		[]byte(`<body><header>Site</header><p>Body text</p><footer>Footer</footer></body>`),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "Body text", text)
+}
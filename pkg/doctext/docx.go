@@ -0,0 +1,111 @@
+package doctext
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// docxBody is the part of a DOCX file holding its body
+const docxBody = "word/document.xml"
+
+// extractDOCX returns the text of a DOCX file's body, a paragraph to a line with a blank line
+// between paragraphs. Paragraphs styled as headings are returned as Markdown headings, so
+// that DOCX files can be chunked at their headings.
+func extractDOCX(data []byte) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("failed to read DOCX file: %w", err)
+	}
+
+	var body *zip.File
+	for _, f := range archive.File {
+		if f.Name == docxBody {
+			body = f
+			break
+		}
+	}
+	if body == nil {
+		return "", errors.New("failed to read DOCX file: missing " + docxBody)
+	}
+
+	rc, err := body.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to read DOCX file: %w", err)
+	}
+	defer rc.Close()
+
+	var (
+		text      strings.Builder
+		paragraph strings.Builder
+		heading   int
+		inText    bool
+	)
+	decoder := xml.NewDecoder(rc)
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read DOCX file: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				paragraph.WriteString("\t")
+			case "br", "cr":
+				paragraph.WriteString("\n")
+			case "pStyle":
+				heading = headingLevel(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				if line := strings.TrimSpace(paragraph.String()); line != "" {
+					if heading > 0 {
+						text.WriteString(strings.Repeat("#", heading) + " ")
+					}
+					text.WriteString(line)
+					text.WriteString("\n\n")
+				}
+				paragraph.Reset()
+				heading = 0
+			}
+		case xml.CharData:
+			if inText {
+				paragraph.Write(t)
+			}
+		}
+	}
+
+	return strings.TrimSpace(text.String()), nil
+}
+
+// headingLevel returns the heading level of a paragraph style, such as 2 for Heading2, or 0
+// if the style isn't a heading
+func headingLevel(style xml.StartElement) int {
+	for _, attr := range style.Attr {
+		if attr.Name.Local != "val" {
+			continue
+		}
+		val := strings.ToLower(attr.Value)
+		if !strings.HasPrefix(val, "heading") || len(val) != len("heading")+1 {
+			return 0
+		}
+		if level := int(val[len(val)-1] - '0'); level >= 1 && level <= 6 {
+			return level
+		}
+	}
+	return 0
+}
@@ -0,0 +1,246 @@
+package doctext
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	// htmlBoilerplateElements are elements whose content isn't part of a page's main text
+	htmlBoilerplateElements = map[atom.Atom]bool{
+		atom.Script:   true,
+		atom.Style:    true,
+		atom.Noscript: true,
+		atom.Template: true,
+		atom.Svg:      true,
+		atom.Iframe:   true,
+		atom.Form:     true,
+		atom.Button:   true,
+		atom.Nav:      true,
+		atom.Aside:    true,
+	}
+	// htmlBoilerplateRoles are the ARIA roles of page navigation and other boilerplate
+	htmlBoilerplateRoles = map[string]bool{
+		"navigation":    true,
+		"banner":        true,
+		"contentinfo":   true,
+		"complementary": true,
+		"search":        true,
+		"dialog":        true,
+	}
+	// htmlBoilerplateClassPattern matches the classes and IDs of elements that commonly hold
+	// navigation, adverts, cookie notices and other boilerplate
+	htmlBoilerplateClassPattern = regexp.MustCompile(
+		`(?i)(^|[\s_-])(nav|navbar|menu|sidebar|breadcrumbs?|cookies?|consent|advert|ads|social|share|related|comments?|newsletter|popup|modal)($|[\s_-])`,
+	)
+	htmlBlockElements = map[atom.Atom]bool{
+		atom.P:          true,
+		atom.Div:        true,
+		atom.Section:    true,
+		atom.Article:    true,
+		atom.Main:       true,
+		atom.Blockquote: true,
+		atom.Ul:         true,
+		atom.Ol:         true,
+		atom.Table:      true,
+		atom.Tr:         true,
+		atom.Dl:         true,
+		atom.Dt:         true,
+		atom.Dd:         true,
+		atom.Figure:     true,
+		atom.Figcaption: true,
+		atom.Hr:         true,
+	}
+	htmlHeadingLevels = map[atom.Atom]int{
+		atom.H1: 1, atom.H2: 2, atom.H3: 3, atom.H4: 4, atom.H5: 5, atom.H6: 6,
+	}
+)
+
+// ExtractHTML returns the title of an HTML page and its main text as Markdown, with its
+// headings, paragraphs, list items and preformatted blocks. Boilerplate such as scripts,
+// navigation, headers, footers, sidebars and cookie notices is dropped, and if the page
+// has a main or article element, only its content is returned.
+func ExtractHTML(data []byte) (title string, text string, err error) {
+	doc, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	if t := findHTMLElement(doc, atom.Title); t != nil {
+		title = strings.Join(strings.Fields(htmlText(t)), " ")
+	}
+
+	root := findHTMLElement(doc, atom.Main)
+	if root == nil {
+		root = findHTMLElement(doc, atom.Article)
+	}
+	if root == nil {
+		root = findHTMLElement(doc, atom.Body)
+	}
+	if root == nil {
+		root = doc
+	}
+
+	// the headers and footers of the main content are its own, unlike those of the page
+	w := &htmlTextWriter{skipHeaders: root.DataAtom != atom.Main && root.DataAtom != atom.Article}
+	w.write(root)
+	w.endBlock()
+
+	return title, strings.TrimSpace(w.text.String()), nil
+}
+
+func extractHTML(data []byte) (string, error) {
+	_, text, err := ExtractHTML(data)
+	return text, err
+}
+
+// findHTMLElement returns the first element of the given type, in document order
+func findHTMLElement(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findHTMLElement(c, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// htmlText returns the text of a node and its descendants
+func htmlText(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(htmlText(c))
+	}
+	return b.String()
+}
+
+// isBoilerplate returns whether an element holds boilerplate rather than the page's text.
+// Elements are judged by their type, role and classes, but elements containing a top-level
+// heading are kept whatever their classes, as they hold the page's main content.
+func (w *htmlTextWriter) isBoilerplate(n *html.Node) bool {
+	if htmlBoilerplateElements[n.DataAtom] {
+		return true
+	}
+	if w.skipHeaders && (n.DataAtom == atom.Header || n.DataAtom == atom.Footer) {
+		return true
+	}
+	for _, attr := range n.Attr {
+		switch attr.Key {
+		case "hidden":
+			return true
+		case "aria-hidden":
+			if attr.Val == "true" {
+				return true
+			}
+		case "role":
+			if htmlBoilerplateRoles[strings.ToLower(attr.Val)] {
+				return true
+			}
+		case "class", "id":
+			if htmlBoilerplateClassPattern.MatchString(attr.Val) &&
+				findHTMLElement(n, atom.H1) == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// htmlTextWriter writes the text of HTML elements as Markdown blocks separated by blank lines
+type htmlTextWriter struct {
+	text  strings.Builder
+	block strings.Builder
+	// skipHeaders drops header and footer elements, which are the page's own outside of its
+	// main content
+	skipHeaders bool
+}
+
+// endBlock writes the current block, collapsing its whitespace
+func (w *htmlTextWriter) endBlock() {
+	block := strings.Join(strings.Fields(w.block.String()), " ")
+	w.block.Reset()
+	// headings and list items without text are dropped
+	if strings.Trim(block, "#- ") == "" {
+		return
+	}
+	if w.text.Len() > 0 {
+		w.text.WriteString("\n\n")
+	}
+	w.text.WriteString(block)
+}
+
+// writeRaw writes a block as it is, for preformatted text
+func (w *htmlTextWriter) writeRaw(block string) {
+	w.endBlock()
+	if strings.TrimSpace(block) == "" {
+		return
+	}
+	if w.text.Len() > 0 {
+		w.text.WriteString("\n\n")
+	}
+	w.text.WriteString("```\n" + strings.Trim(block, "\n") + "\n```")
+}
+
+func (w *htmlTextWriter) write(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		w.block.WriteString(n.Data)
+		return
+	case html.ElementNode:
+		if w.isBoilerplate(n) {
+			return
+		}
+	case html.DocumentNode:
+	default:
+		return
+	}
+
+	switch {
+	case n.DataAtom == atom.Pre:
+		w.writeRaw(htmlText(n))
+		return
+	case n.DataAtom == atom.Br:
+		w.block.WriteString("\n")
+		return
+	case htmlHeadingLevels[n.DataAtom] > 0:
+		w.endBlock()
+		w.block.WriteString(strings.Repeat("#", htmlHeadingLevels[n.DataAtom]) + " ")
+		w.writeChildren(n)
+		w.endBlock()
+		return
+	case n.DataAtom == atom.Li:
+		w.endBlock()
+		w.block.WriteString("- ")
+		w.writeChildren(n)
+		w.endBlock()
+		return
+	case n.DataAtom == atom.Td || n.DataAtom == atom.Th:
+		w.block.WriteString(" ")
+		w.writeChildren(n)
+		w.block.WriteString(" ")
+		return
+	case htmlBlockElements[n.DataAtom]:
+		w.endBlock()
+		w.writeChildren(n)
+		w.endBlock()
+		return
+	}
+
+	w.writeChildren(n)
+}
+
+func (w *htmlTextWriter) writeChildren(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		w.write(c)
+	}
+}
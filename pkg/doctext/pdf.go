@@ -0,0 +1,454 @@
+package doctext
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// maxPDFStreamSize limits the size of a decompressed PDF stream, so that a small upload
+// can't decompress to an unbounded size
+const maxPDFStreamSize = 64 << 20
+
+var (
+	// pdfSkippedStreamPattern matches the dictionaries of streams that don't hold page
+	// content: images, fonts, metadata, and object and cross-reference streams
+	pdfSkippedStreamPattern = regexp.MustCompile(
+		`/Subtype\s*/(Image|Type1C|CIDFontType0C|OpenType)\b|/Type\s*/(ObjStm|XRef|Metadata)\b|/Length[123]\b`,
+	)
+	pdfFilterPattern = regexp.MustCompile(`/Filter\s*(\[[^\]]*\]|/\w+)`)
+	pdfNamePattern   = regexp.MustCompile(`/\w+`)
+)
+
+// extractPDF returns the text drawn by the content streams of a PDF file, in the order the
+// streams appear in the file. Only uncompressed and Flate-compressed streams are read, and
+// strings are decoded as UTF-16 if they start with a byte order mark and as Latin-1
+// otherwise, so the text of fonts with custom encodings may not be extracted. Scanned PDF
+// files have no text to extract.
+func extractPDF(data []byte) (string, error) {
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return "", errors.New("not a PDF file")
+	}
+	if bytes.Contains(data, []byte("/Encrypt")) {
+		return "", errors.New("encrypted PDF files are not supported")
+	}
+
+	w := &pdfTextWriter{}
+	pos := 0
+	for {
+		i := bytes.Index(data[pos:], []byte("stream"))
+		if i < 0 {
+			break
+		}
+		keyword := pos + i
+		pos = keyword + len("stream")
+		// the endstream keyword also contains "stream"
+		if keyword >= 3 && string(data[keyword-3:keyword]) == "end" {
+			continue
+		}
+
+		// the stream's data starts on the line after the stream keyword
+		start := pos
+		if start < len(data) && data[start] == '\r' {
+			start++
+		}
+		if start >= len(data) || data[start] != '\n' {
+			continue
+		}
+		start++
+		end := bytes.Index(data[start:], []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		end += start
+		pos = end + len("endstream")
+
+		header := bytes.LastIndex(data[:keyword], []byte("obj"))
+		if header < 0 {
+			continue
+		}
+		content, ok := decodePDFStream(data[header:keyword], data[start:end])
+		if !ok {
+			continue
+		}
+		w.writeContent(content)
+		w.paragraph()
+	}
+
+	text := strings.TrimSpace(w.String())
+	if text == "" {
+		return "", errors.New("no text found in PDF file. scanned PDF files are not supported")
+	}
+	return text, nil
+}
+
+// decodePDFStream returns the decoded data of a stream with the given dictionary, and false
+// if the stream doesn't hold page content or its filters aren't supported
+func decodePDFStream(dict, data []byte) ([]byte, bool) {
+	if pdfSkippedStreamPattern.Match(dict) {
+		return nil, false
+	}
+
+	match := pdfFilterPattern.FindSubmatch(dict)
+	if match == nil {
+		return data, true
+	}
+	filters := pdfNamePattern.FindAll(match[1], -1)
+	if len(filters) != 1 || string(filters[0]) != "/FlateDecode" {
+		return nil, false
+	}
+
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, false
+	}
+	defer r.Close()
+	// streams are often followed by an end of line that isn't part of the compressed data,
+	// so what could be read before an error is used
+	decoded, _ := io.ReadAll(io.LimitReader(r, maxPDFStreamSize))
+	return decoded, len(decoded) > 0
+}
+
+// pdfOperand is an operand of a content stream operator: a string, number or array
+type pdfOperand struct {
+	str   []byte
+	isStr bool
+	num   float64
+	isNum bool
+	array []pdfOperand
+}
+
+// pdfTextWriter writes the text shown by content streams, starting a new line when the
+// text position moves to another line
+type pdfTextWriter struct {
+	strings.Builder
+}
+
+func (w *pdfTextWriter) last() byte {
+	s := w.String()
+	if s == "" {
+		return '\n'
+	}
+	return s[len(s)-1]
+}
+
+func (w *pdfTextWriter) space() {
+	if last := w.last(); last != ' ' && last != '\n' {
+		w.WriteByte(' ')
+	}
+}
+
+func (w *pdfTextWriter) newline() {
+	if w.last() != '\n' {
+		w.WriteByte('\n')
+	}
+}
+
+func (w *pdfTextWriter) paragraph() {
+	w.newline()
+	if s := w.String(); len(s) > 1 && s[len(s)-2] != '\n' {
+		w.WriteByte('\n')
+	}
+}
+
+func (w *pdfTextWriter) text(s []byte) {
+	w.WriteString(decodePDFString(s))
+}
+
+// writeContent writes the text shown by the text operators of a content stream
+func (w *pdfTextWriter) writeContent(content []byte) {
+	l := &pdfLexer{data: content}
+	var operands []pdfOperand
+	inText := false
+	for {
+		operand, operator, ok := l.next()
+		if !ok {
+			return
+		}
+		if operator == "" {
+			operands = append(operands, operand)
+			continue
+		}
+
+		switch operator {
+		case "BT":
+			inText = true
+		case "ET":
+			inText = false
+			w.newline()
+		case "ID":
+			// inline image data isn't tokenized
+			l.skipInlineImage()
+		}
+		if inText {
+			w.writeOperator(operator, operands)
+		}
+		operands = operands[:0]
+	}
+}
+
+func (w *pdfTextWriter) writeOperator(operator string, operands []pdfOperand) {
+	var last pdfOperand
+	if len(operands) > 0 {
+		last = operands[len(operands)-1]
+	}
+
+	switch operator {
+	case "Tj":
+		if last.isStr {
+			w.text(last.str)
+		}
+	case "'", "\"":
+		w.newline()
+		if last.isStr {
+			w.text(last.str)
+		}
+	case "TJ":
+		for _, item := range last.array {
+			switch {
+			case item.isStr:
+				w.text(item.str)
+			case item.isNum && item.num < -200:
+				// large negative adjustments are spaces between words
+				w.space()
+			}
+		}
+	case "T*":
+		w.newline()
+	case "Td", "TD":
+		if len(operands) == 2 && operands[1].isNum && operands[1].num != 0 {
+			w.newline()
+		} else {
+			w.space()
+		}
+	case "Tm":
+		w.newline()
+	}
+}
+
+// decodePDFString decodes a string as UTF-16 if it starts with a byte order mark, and as
+// Latin-1 otherwise, dropping control characters
+func decodePDFString(s []byte) string {
+	var b strings.Builder
+	if len(s) >= 2 && s[0] == 0xFE && s[1] == 0xFF {
+		units := make([]uint16, 0, len(s)/2)
+		for i := 2; i+1 < len(s); i += 2 {
+			units = append(units, uint16(s[i])<<8|uint16(s[i+1]))
+		}
+		for _, r := range utf16.Decode(units) {
+			if r >= ' ' {
+				b.WriteRune(r)
+			}
+		}
+		return b.String()
+	}
+
+	for _, c := range s {
+		if c >= ' ' {
+			b.WriteRune(rune(c))
+		}
+	}
+	return b.String()
+}
+
+// pdfLexer reads the operands and operators of a content stream
+type pdfLexer struct {
+	data []byte
+	pos  int
+}
+
+func isPDFWhitespace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\f' || c == 0
+}
+
+func isPDFDelimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+// next returns the next operand, or the next operator if one is reached first. ok is false
+// at the end of the stream.
+func (l *pdfLexer) next() (operand pdfOperand, operator string, ok bool) {
+	for {
+		l.skipWhitespace()
+		if l.pos >= len(l.data) {
+			return pdfOperand{}, "", false
+		}
+
+		c := l.data[l.pos]
+		switch {
+		case c == '(':
+			return pdfOperand{str: l.literalString(), isStr: true}, "", true
+		case c == '<' && l.peek(1) == '<', c == '>' && l.peek(1) == '>':
+			// dictionaries of marked content operators have no text
+			l.pos += 2
+		case c == '<':
+			return pdfOperand{str: l.hexString(), isStr: true}, "", true
+		case c == '[':
+			l.pos++
+			return pdfOperand{array: l.array()}, "", true
+		case c == ']' || c == '{' || c == '}' || c == '>' || c == ')':
+			l.pos++
+		case c == '%':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		case c == '/':
+			l.pos++
+			l.regular()
+			return pdfOperand{}, "", true
+		default:
+			word := l.regular()
+			if word == "" {
+				l.pos++
+				continue
+			}
+			if num, err := strconv.ParseFloat(word, 64); err == nil {
+				return pdfOperand{num: num, isNum: true}, "", true
+			}
+			return pdfOperand{}, word, true
+		}
+	}
+}
+
+func (l *pdfLexer) peek(offset int) byte {
+	if l.pos+offset < len(l.data) {
+		return l.data[l.pos+offset]
+	}
+	return 0
+}
+
+func (l *pdfLexer) skipWhitespace() {
+	for l.pos < len(l.data) && isPDFWhitespace(l.data[l.pos]) {
+		l.pos++
+	}
+}
+
+// regular reads a run of regular characters: a number, name or operator
+func (l *pdfLexer) regular() string {
+	start := l.pos
+	for l.pos < len(l.data) && !isPDFWhitespace(l.data[l.pos]) &&
+		!isPDFDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	return string(l.data[start:l.pos])
+}
+
+// array reads the items of an array up to its closing bracket
+func (l *pdfLexer) array() []pdfOperand {
+	var items []pdfOperand
+	for {
+		l.skipWhitespace()
+		if l.pos >= len(l.data) {
+			return items
+		}
+		if l.data[l.pos] == ']' {
+			l.pos++
+			return items
+		}
+		item, operator, ok := l.next()
+		if !ok || operator != "" {
+			return items
+		}
+		items = append(items, item)
+	}
+}
+
+// literalString reads a string in parentheses, which may contain balanced parentheses
+func (l *pdfLexer) literalString() []byte {
+	var s []byte
+	depth := 0
+	for l.pos++; l.pos < len(l.data); l.pos++ {
+		c := l.data[l.pos]
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				l.pos++
+				return s
+			}
+			depth--
+		case '\\':
+			l.pos++
+			if l.pos >= len(l.data) {
+				return s
+			}
+			c = l.data[l.pos]
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				// a backslash at the end of a line continues the string on the next line
+				if l.peek(1) == '\n' {
+					l.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if c >= '0' && c <= '7' {
+					n := 0
+					for i := 0; i < 3 && l.pos < len(l.data) &&
+						l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						n = n*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					l.pos--
+					c = byte(n)
+				}
+			}
+		}
+		s = append(s, c)
+	}
+	return s
+}
+
+// hexString reads a string of hex digits in angle brackets
+func (l *pdfLexer) hexString() []byte {
+	var s []byte
+	var digits []byte
+	for l.pos++; l.pos < len(l.data) && l.data[l.pos] != '>'; l.pos++ {
+		if c := l.data[l.pos]; !isPDFWhitespace(c) {
+			digits = append(digits, c)
+		}
+	}
+	l.pos++
+	// a missing final digit is zero
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	for i := 0; i < len(digits); i += 2 {
+		b, err := strconv.ParseUint(string(digits[i:i+2]), 16, 8)
+		if err != nil {
+			return s
+		}
+		s = append(s, byte(b))
+	}
+	return s
+}
+
+// skipInlineImage skips the data of an inline image, up to its EI operator
+func (l *pdfLexer) skipInlineImage() {
+	for l.pos+2 < len(l.data) {
+		if isPDFWhitespace(l.data[l.pos]) && l.data[l.pos+1] == 'E' && l.data[l.pos+2] == 'I' &&
+			(l.pos+3 == len(l.data) || isPDFWhitespace(l.data[l.pos+3])) {
+			l.pos += 3
+			return
+		}
+		l.pos++
+	}
+	l.pos = len(l.data)
+}
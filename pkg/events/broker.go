@@ -0,0 +1,70 @@
+package events
+
+import (
+	"sync"
+
+	"github.com/getzep/zep/internal"
+	"github.com/getzep/zep/pkg/models"
+)
+
+var log = internal.GetLogger()
+
+var _ models.SessionEventBroker = (*Broker)(nil)
+
+// SubscriberBufferSize is the number of events buffered for each subscriber. Events are
+// dropped for subscribers whose buffer is full.
+const SubscriberBufferSize = 100
+
+// Broker is an in-memory SessionEventBroker. Events are only delivered to subscribers in
+// the process that published them.
+type Broker struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[chan *models.SessionEvent]struct{}
+}
+
+// NewBroker returns a Broker with no subscribers.
+func NewBroker() *Broker {
+	return &Broker{subscribers: make(map[string]map[chan *models.SessionEvent]struct{})}
+}
+
+func (b *Broker) Publish(event *models.SessionEvent) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for events := range b.subscribers[event.SessionID] {
+		select {
+		case events <- event:
+		default:
+			log.Warnf(
+				"dropped %s event for slow subscriber of session %s",
+				event.Type,
+				event.SessionID,
+			)
+		}
+	}
+}
+
+func (b *Broker) Subscribe(sessionID string) (<-chan *models.SessionEvent, func()) {
+	events := make(chan *models.SessionEvent, SubscriberBufferSize)
+
+	b.mu.Lock()
+	if b.subscribers[sessionID] == nil {
+		b.subscribers[sessionID] = make(map[chan *models.SessionEvent]struct{})
+	}
+	b.subscribers[sessionID][events] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			delete(b.subscribers[sessionID], events)
+			if len(b.subscribers[sessionID]) == 0 {
+				delete(b.subscribers, sessionID)
+			}
+			close(events)
+		})
+	}
+	return events, unsubscribe
+}
@@ -0,0 +1,43 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/getzep/zep/pkg/models"
+)
+
+func TestBroker(t *testing.T) {
+	b := NewBroker()
+
+	events, unsubscribe := b.Subscribe("session")
+	other, unsubscribeOther := b.Subscribe("other")
+	defer unsubscribeOther()
+
+	event := &models.SessionEvent{Type: models.SessionEventSummary, SessionID: "session"}
+	b.Publish(event)
+	assert.Equal(t, event, <-events)
+	assert.Empty(t, other)
+
+	unsubscribe()
+	_, ok := <-events
+	assert.False(t, ok, "channel should be closed")
+	assert.NotContains(t, b.subscribers, "session")
+
+	// publishing to a session without subscribers and unsubscribing twice are no-ops
+	b.Publish(event)
+	unsubscribe()
+}
+
+func TestBroker_SlowSubscriber(t *testing.T) {
+	b := NewBroker()
+
+	events, unsubscribe := b.Subscribe("session")
+	defer unsubscribe()
+
+	for i := 0; i < SubscriberBufferSize+1; i++ {
+		b.Publish(&models.SessionEvent{Type: models.SessionEventMessages, SessionID: "session"})
+	}
+	assert.Len(t, events, SubscriberBufferSize)
+}
@@ -0,0 +1,160 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+)
+
+var _ models.EventProducer = (*KafkaProducer)(nil)
+
+// KafkaEventType is the type of an event published to Kafka
+type KafkaEventType string
+
+const (
+	// KafkaEventMessageIngested is published to the message topic for each message added to
+	// a session
+	KafkaEventMessageIngested KafkaEventType = "message.ingested"
+	// KafkaEventSummaryCreated is published to the summary topic for each summary created
+	KafkaEventSummaryCreated KafkaEventType = "summary.created"
+)
+
+// KafkaEvent is the value of the records published to Kafka. Records are keyed by session
+// ID, so that the events of a session are in the same partition, in order.
+type KafkaEvent struct {
+	Type      KafkaEventType  `json:"type"`
+	SessionID string          `json:"session_id"`
+	Timestamp time.Time       `json:"timestamp"`
+	Message   *models.Message `json:"message,omitempty"`
+	Summary   *models.Summary `json:"summary,omitempty"`
+}
+
+// kafkaWriter is the part of kafka.Writer used by KafkaProducer
+type kafkaWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// KafkaProducer publishes message and summary events to Kafka. Records published with Publish
+// are written asynchronously, in batches, while PublishSync waits for the brokers to
+// acknowledge them.
+type KafkaProducer struct {
+	writer       kafkaWriter
+	syncWriter   kafkaWriter
+	messageTopic string
+	summaryTopic string
+}
+
+// NewKafkaProducer returns a KafkaProducer publishing to cfg's brokers and topics
+func NewKafkaProducer(cfg *config.KafkaConfig) *KafkaProducer {
+	writer := &kafka.Writer{
+		Addr:     kafka.TCP(cfg.Brokers...),
+		Balancer: &kafka.Hash{},
+		Async:    true,
+		Completion: func(messages []kafka.Message, err error) {
+			if err != nil {
+				log.Errorf("failed to publish %d events to kafka: %v", len(messages), err)
+			}
+		},
+	}
+	syncWriter := &kafka.Writer{
+		Addr:         kafka.TCP(cfg.Brokers...),
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+	}
+	return &KafkaProducer{
+		writer:       writer,
+		syncWriter:   syncWriter,
+		messageTopic: cfg.MessageTopic,
+		summaryTopic: cfg.SummaryTopic,
+	}
+}
+
+func (p *KafkaProducer) Publish(event *models.SessionEvent) {
+	records, err := p.records(event)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	if len(records) == 0 {
+		return
+	}
+
+	// the writer is asynchronous, so this only fails if the producer is closed
+	if err := p.writer.WriteMessages(context.Background(), records...); err != nil {
+		log.Errorf("failed to publish %d events to kafka: %v", len(records), err)
+	}
+}
+
+func (p *KafkaProducer) PublishSync(ctx context.Context, event *models.SessionEvent) error {
+	records, err := p.records(event)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return nil
+	}
+
+	if err := p.syncWriter.WriteMessages(ctx, records...); err != nil {
+		return fmt.Errorf("failed to publish %d events to kafka: %w", len(records), err)
+	}
+	return nil
+}
+
+// records returns the Kafka records of event. Events whose topic isn't set, and extractor
+// events, have no records.
+func (p *KafkaProducer) records(event *models.SessionEvent) ([]kafka.Message, error) {
+	now := time.Now().UTC()
+
+	var kafkaEvents []KafkaEvent
+	var topic string
+	switch event.Type {
+	case models.SessionEventMessages:
+		topic = p.messageTopic
+		for i := range event.Messages {
+			kafkaEvents = append(kafkaEvents, KafkaEvent{
+				Type:      KafkaEventMessageIngested,
+				SessionID: event.SessionID,
+				Timestamp: now,
+				Message:   &event.Messages[i],
+			})
+		}
+	case models.SessionEventSummary:
+		topic = p.summaryTopic
+		kafkaEvents = append(kafkaEvents, KafkaEvent{
+			Type:      KafkaEventSummaryCreated,
+			SessionID: event.SessionID,
+			Timestamp: now,
+			Summary:   event.Summary,
+		})
+	}
+	if topic == "" || len(kafkaEvents) == 0 {
+		return nil, nil
+	}
+
+	records := make([]kafka.Message, 0, len(kafkaEvents))
+	for _, e := range kafkaEvents {
+		value, err := json.Marshal(e)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal %s kafka event: %w", e.Type, err)
+		}
+		records = append(records, kafka.Message{
+			Topic: topic,
+			Key:   []byte(event.SessionID),
+			Value: value,
+			Time:  now,
+		})
+	}
+	return records, nil
+}
+
+func (p *KafkaProducer) Close() error {
+	return errors.Join(p.writer.Close(), p.syncWriter.Close())
+}
@@ -0,0 +1,107 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/pkg/models"
+)
+
+type recordingWriter struct {
+	records []kafka.Message
+	err     error
+}
+
+func (w *recordingWriter) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	if w.err != nil {
+		return w.err
+	}
+	w.records = append(w.records, msgs...)
+	return nil
+}
+
+func (w *recordingWriter) Close() error {
+	return nil
+}
+
+func TestKafkaProducer(t *testing.T) {
+	writer := &recordingWriter{}
+	p := &KafkaProducer{writer: writer, messageTopic: "messages", summaryTopic: "summaries"}
+
+	messages := []models.Message{
+		{UUID: uuid.New(), Role: "user", Content: "Hello"},
+		{UUID: uuid.New(), Role: "assistant", Content: "Hi there!"},
+	}
+	p.Publish(&models.SessionEvent{
+		Type:      models.SessionEventMessages,
+		SessionID: "session",
+		Messages:  messages,
+	})
+	p.Publish(&models.SessionEvent{
+		Type:      models.SessionEventSummary,
+		SessionID: "session",
+		Summary:   &models.Summary{Content: "a greeting"},
+	})
+	// extractor events aren't published
+	p.Publish(&models.SessionEvent{
+		Type:      models.SessionEventExtractor,
+		SessionID: "session",
+		Messages:  messages,
+	})
+
+	require.Len(t, writer.records, 3)
+	for i, topic := range []string{"messages", "messages", "summaries"} {
+		assert.Equal(t, topic, writer.records[i].Topic)
+		assert.Equal(t, []byte("session"), writer.records[i].Key)
+	}
+
+	var event KafkaEvent
+	require.NoError(t, json.Unmarshal(writer.records[1].Value, &event))
+	assert.Equal(t, KafkaEventMessageIngested, event.Type)
+	assert.Equal(t, "session", event.SessionID)
+	require.NotNil(t, event.Message)
+	assert.Equal(t, messages[1].UUID, event.Message.UUID)
+
+	require.NoError(t, json.Unmarshal(writer.records[2].Value, &event))
+	assert.Equal(t, KafkaEventSummaryCreated, event.Type)
+	require.NotNil(t, event.Summary)
+	assert.Equal(t, "a greeting", event.Summary.Content)
+}
+
+func TestKafkaProducer_TopicNotSet(t *testing.T) {
+	writer := &recordingWriter{}
+	p := &KafkaProducer{writer: writer, summaryTopic: "summaries"}
+
+	p.Publish(&models.SessionEvent{
+		Type:      models.SessionEventMessages,
+		SessionID: "session",
+		Messages:  []models.Message{{UUID: uuid.New(), Content: "Hello"}},
+	})
+	assert.Empty(t, writer.records)
+}
+
+func TestKafkaProducer_PublishSync(t *testing.T) {
+	writer := &recordingWriter{}
+	syncWriter := &recordingWriter{}
+	p := &KafkaProducer{writer: writer, syncWriter: syncWriter, summaryTopic: "summaries"}
+
+	event := &models.SessionEvent{
+		Type:      models.SessionEventSummary,
+		SessionID: "session",
+		Summary:   &models.Summary{Content: "a greeting"},
+	}
+	require.NoError(t, p.PublishSync(context.Background(), event))
+	assert.Empty(t, writer.records)
+	require.Len(t, syncWriter.records, 1)
+	assert.Equal(t, "summaries", syncWriter.records[0].Topic)
+
+	syncWriter.err = errors.New("leader not available")
+	assert.ErrorContains(t, p.PublishSync(context.Background(), event), "leader not available")
+}
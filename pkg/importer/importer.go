@@ -0,0 +1,188 @@
+package importer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+
+	"github.com/getzep/zep/internal"
+	"github.com/getzep/zep/pkg/models"
+)
+
+var log = internal.GetLogger()
+
+var validate = validator.New()
+
+const (
+	// TaskBatchSize is the number of a session's imported messages that are sent for
+	// embedding and enrichment at a time
+	TaskBatchSize = 100
+	// ProgressInterval is the number of lines read between progress reports
+	ProgressInterval = 100
+	// MaxReportedErrors is the number of failed lines whose errors are reported
+	MaxReportedErrors = 100
+	// MaxLineSize is the longest line that can be imported
+	MaxLineSize = 10 << 20 // 10MB
+)
+
+// Import streams JSONL of historical conversations into the MemoryStore. Each line is a
+// SessionMemory. Sessions that don't exist are created. The imported messages are sent for
+// embedding, summarization and other enrichment in batches of TaskBatchSize per session,
+// rather than after every line.
+//
+// Lines that fail to import are reported in the progress and don't stop the import.
+// onProgress, which may be nil, is called every ProgressInterval lines, as the MemoryStore
+// reports storing the messages of large lines, and once the import is complete. An error is returned, along with the progress so far, if the input can't be
+// read, ctx is done, or the imported messages can't be sent for enrichment.
+func Import(
+	ctx context.Context,
+	appState *models.AppState,
+	r io.Reader,
+	onProgress func(progress *models.ImportProgress),
+) (*models.ImportProgress, error) {
+	progress := &models.ImportProgress{}
+	pending := make(map[string][]models.MessageTask)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), MaxLineSize)
+	lineNumber := 0
+	var readErr error
+	for scanner.Scan() {
+		lineNumber++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		if readErr = ctx.Err(); readErr != nil {
+			break
+		}
+		progress.Lines++
+
+		lineCtx := ctx
+		if onProgress != nil {
+			// lines with many messages report progress as their messages are stored
+			line := lineNumber
+			lineCtx = models.WithMessageProgress(ctx, func(stored, total int) {
+				progress.Current = &models.ImportLineProgress{
+					Line:     line,
+					Stored:   stored,
+					Messages: total,
+				}
+				onProgress(progress)
+			})
+		}
+		sessionID, tasks, err := importLine(lineCtx, appState, scanner.Bytes())
+		progress.Current = nil
+		if err != nil {
+			progress.Failed++
+			if len(progress.Errors) < MaxReportedErrors {
+				progress.Errors = append(progress.Errors, models.ImportError{
+					Line:      lineNumber,
+					SessionID: sessionID,
+					Error:     err.Error(),
+				})
+			}
+			log.Debugf("failed to import line %d: %v", lineNumber, err)
+		} else {
+			progress.Imported++
+			progress.Messages += len(tasks)
+
+			pending[sessionID] = append(pending[sessionID], tasks...)
+			if len(pending[sessionID]) >= TaskBatchSize {
+				err := publishMessageTasks(ctx, appState, sessionID, pending[sessionID])
+				if err != nil {
+					return progress, err
+				}
+				delete(pending, sessionID)
+			}
+		}
+
+		if onProgress != nil && progress.Lines%ProgressInterval == 0 {
+			onProgress(progress)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		readErr = fmt.Errorf("failed to read line %d: %w", lineNumber+1, err)
+	}
+
+	// the messages that were stored are enriched even if the import stopped early
+	for sessionID, tasks := range pending {
+		if err := publishMessageTasks(ctx, appState, sessionID, tasks); err != nil {
+			return progress, err
+		}
+	}
+	if readErr != nil {
+		return progress, readErr
+	}
+
+	if onProgress != nil {
+		onProgress(progress)
+	}
+
+	return progress, nil
+}
+
+// importLine stores the messages of a line, returning the line's session ID and the tasks
+// that will enrich its messages.
+func importLine(
+	ctx context.Context,
+	appState *models.AppState,
+	line []byte,
+) (string, []models.MessageTask, error) {
+	var sessionMemory models.SessionMemory
+	if err := json.Unmarshal(line, &sessionMemory); err != nil {
+		return "", nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	sessionID := sessionMemory.SessionID
+	if err := validate.Struct(sessionMemory); err != nil {
+		return sessionID, nil, err
+	}
+	if len(sessionMemory.Messages) == 0 {
+		return sessionID, nil, errors.New("no messages")
+	}
+
+	// Messages are given UUIDs up front so that they can be enriched once stored
+	tasks := make([]models.MessageTask, len(sessionMemory.Messages))
+	for i := range sessionMemory.Messages {
+		if sessionMemory.Messages[i].UUID == uuid.Nil {
+			sessionMemory.Messages[i].UUID = uuid.New()
+		}
+		tasks[i] = models.MessageTask{UUID: sessionMemory.Messages[i].UUID}
+	}
+
+	err := appState.MemoryStore.PutMemory(
+		ctx,
+		appState,
+		sessionID,
+		&sessionMemory.Memory,
+		true,
+	)
+	if err != nil {
+		return sessionID, nil, err
+	}
+
+	return sessionID, tasks, nil
+}
+
+func publishMessageTasks(
+	ctx context.Context,
+	appState *models.AppState,
+	sessionID string,
+	tasks []models.MessageTask,
+) error {
+	err := appState.TaskPublisher.PublishMessage(
+		ctx,
+		map[string]string{"session_id": sessionID},
+		tasks,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to publish imported messages of session %s: %w", sessionID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,194 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/pkg/models"
+)
+
+// fakeMemoryStore implements the MemoryStore methods used by Import. Other methods panic.
+type fakeMemoryStore struct {
+	models.MemoryStore[any]
+	messages map[string][]models.Message
+}
+
+func (s *fakeMemoryStore) PutMemory(
+	ctx context.Context,
+	_ *models.AppState,
+	sessionID string,
+	memory *models.Memory,
+	skipNotify bool,
+) error {
+	if !skipNotify {
+		return errors.New("imports should not notify")
+	}
+	if sessionID == "deleted" {
+		return models.NewNotFoundError("session " + sessionID)
+	}
+	if onProgress := models.MessageProgressFromContext(ctx); onProgress != nil && sessionID == "large" {
+		for stored := 1; stored <= len(memory.Messages); stored++ {
+			onProgress(stored, len(memory.Messages))
+		}
+	}
+	s.messages[sessionID] = append(s.messages[sessionID], memory.Messages...)
+	return nil
+}
+
+type publishedMessages struct {
+	sessionID string
+	tasks     []models.MessageTask
+}
+
+type fakeTaskPublisher struct {
+	models.TaskPublisher
+	published []publishedMessages
+}
+
+func (p *fakeTaskPublisher) PublishMessage(
+	_ context.Context,
+	metadata map[string]string,
+	payload []models.MessageTask,
+) error {
+	p.published = append(p.published, publishedMessages{metadata["session_id"], payload})
+	return nil
+}
+
+func newTestAppState() (*models.AppState, *fakeMemoryStore, *fakeTaskPublisher) {
+	memoryStore := &fakeMemoryStore{messages: make(map[string][]models.Message)}
+	publisher := &fakeTaskPublisher{}
+	return &models.AppState{MemoryStore: memoryStore, TaskPublisher: publisher},
+		memoryStore,
+		publisher
+}
+
+func TestImport(t *testing.T) {
+	appState, memoryStore, publisher := newTestAppState()
+	messageUUID := uuid.New()
+
+	input := strings.Join([]string{
+		`{"session_id": "a", "messages": [{"role": "human", "content": "hi"}]}`,
+		"",
+		fmt.Sprintf(
+			`{"session_id": "b", "messages": [{"uuid": "%s", "role": "ai", "content": "hello"}]}`,
+			messageUUID,
+		),
+		`not json`,
+		`{"messages": [{"role": "human", "content": "no session"}]}`,
+		`{"session_id": "a", "messages": []}`,
+		`{"session_id": "deleted", "messages": [{"role": "human", "content": "hi"}]}`,
+		`{"session_id": "a", "messages": [{"role": "ai", "content": "bye"}]}`,
+	}, "\n")
+
+	var reports int
+	progress, err := Import(
+		context.Background(),
+		appState,
+		strings.NewReader(input),
+		func(*models.ImportProgress) { reports++ },
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, 7, progress.Lines)
+	assert.Equal(t, 3, progress.Imported)
+	assert.Equal(t, 3, progress.Messages)
+	assert.Equal(t, 4, progress.Failed)
+	assert.Equal(t, 1, reports)
+
+	require.Len(t, progress.Errors, 4)
+	assert.Equal(t, 4, progress.Errors[0].Line)
+	assert.Equal(t, 5, progress.Errors[1].Line)
+	assert.Equal(t, models.ImportError{Line: 6, SessionID: "a", Error: "no messages"},
+		progress.Errors[2])
+	assert.Equal(t, 7, progress.Errors[3].Line)
+	assert.Equal(t, "deleted", progress.Errors[3].SessionID)
+
+	require.Len(t, memoryStore.messages["a"], 2)
+	assert.Equal(t, messageUUID, memoryStore.messages["b"][0].UUID)
+
+	// each session's messages are published together at the end of the import
+	require.Len(t, publisher.published, 2)
+	published := make(map[string][]models.MessageTask)
+	for _, p := range publisher.published {
+		published[p.sessionID] = p.tasks
+	}
+	assert.Equal(t, []models.MessageTask{
+		{UUID: memoryStore.messages["a"][0].UUID},
+		{UUID: memoryStore.messages["a"][1].UUID},
+	}, published["a"])
+	assert.Equal(t, []models.MessageTask{{UUID: messageUUID}}, published["b"])
+}
+
+func TestImport_Batches(t *testing.T) {
+	appState, _, publisher := newTestAppState()
+
+	lines := make([]string, TaskBatchSize+1)
+	for i := range lines {
+		lines[i] = `{"session_id": "a", "messages": [{"role": "human", "content": "hi"}]}`
+	}
+
+	var reports []models.ImportProgress
+	progress, err := Import(
+		context.Background(),
+		appState,
+		strings.NewReader(strings.Join(lines, "\n")),
+		func(p *models.ImportProgress) { reports = append(reports, *p) },
+	)
+	require.NoError(t, err)
+	assert.Equal(t, TaskBatchSize+1, progress.Imported)
+
+	require.Len(t, publisher.published, 2)
+	assert.Len(t, publisher.published[0].tasks, TaskBatchSize)
+	assert.Len(t, publisher.published[1].tasks, 1)
+
+	require.Len(t, reports, 2)
+	assert.Equal(t, ProgressInterval, reports[0].Lines)
+	assert.Equal(t, TaskBatchSize+1, reports[1].Lines)
+}
+
+func TestImport_LineProgress(t *testing.T) {
+	appState, _, _ := newTestAppState()
+
+	input := `{"session_id": "a", "messages": [{"role": "human", "content": "hi"}]}` + "\n" +
+		`{"session_id": "large", "messages": [{"role": "human", "content": "hi"}, {"role": "ai", "content": "hello"}]}`
+
+	var reports []models.ImportProgress
+	progress, err := Import(
+		context.Background(),
+		appState,
+		strings.NewReader(input),
+		func(p *models.ImportProgress) {
+			report := *p
+			if p.Current != nil {
+				current := *p.Current
+				report.Current = &current
+			}
+			reports = append(reports, report)
+		},
+	)
+	require.NoError(t, err)
+	assert.Nil(t, progress.Current)
+
+	require.Len(t, reports, 3)
+	assert.Equal(t, &models.ImportLineProgress{Line: 2, Stored: 1, Messages: 2}, reports[0].Current)
+	assert.Equal(t, &models.ImportLineProgress{Line: 2, Stored: 2, Messages: 2}, reports[1].Current)
+	assert.Nil(t, reports[2].Current)
+}
+
+func TestImport_LineTooLong(t *testing.T) {
+	appState, _, publisher := newTestAppState()
+
+	input := `{"session_id": "a", "messages": [{"role": "human", "content": "hi"}]}` + "\n" +
+		strings.Repeat("a", MaxLineSize+1)
+	progress, err := Import(context.Background(), appState, strings.NewReader(input), nil)
+	assert.ErrorContains(t, err, "failed to read line 2")
+	assert.Equal(t, 1, progress.Imported)
+	assert.Len(t, publisher.published, 1, "stored messages should be published")
+}
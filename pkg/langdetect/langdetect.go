@@ -0,0 +1,157 @@
+package langdetect
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Unknown is returned when the language of a text can't be detected
+const Unknown = ""
+
+// minStopwords is the number of stopwords a text in a Latin script must contain for its
+// language to be detected
+const minStopwords = 1
+
+// scripts are the writing systems that identify a language on their own. Texts in Han are
+// Japanese if they contain any kana, and Cyrillic texts are checked for Ukrainian letters.
+var scripts = []struct {
+	table    *unicode.RangeTable
+	language string
+}{
+	{unicode.Hangul, "ko"},
+	{unicode.Hiragana, "ja"},
+	{unicode.Katakana, "ja"},
+	{unicode.Han, "zh"},
+	{unicode.Cyrillic, "ru"},
+	{unicode.Arabic, "ar"},
+	{unicode.Hebrew, "he"},
+	{unicode.Greek, "el"},
+	{unicode.Devanagari, "hi"},
+	{unicode.Thai, "th"},
+}
+
+// stopwords are common words that distinguish languages written in Latin scripts
+var stopwords = map[string][]string{
+	"en": {
+		"the", "and", "is", "are", "was", "of", "to", "in", "that", "it", "you", "for",
+		"with", "this", "have", "what", "not", "be", "on", "my", "do", "can", "i",
+	},
+	"es": {
+		"el", "la", "los", "las", "y", "es", "de", "que", "en", "un", "una", "por", "con",
+		"para", "está", "qué", "cómo", "pero", "muy", "yo", "sí", "del", "se",
+	},
+	"fr": {
+		"le", "la", "les", "et", "est", "de", "des", "que", "un", "une", "pour", "avec",
+		"je", "vous", "nous", "pas", "ce", "qui", "dans", "sur", "mais", "du", "suis",
+	},
+	"de": {
+		"der", "die", "das", "und", "ist", "nicht", "ich", "sie", "ein", "eine", "mit",
+		"zu", "auf", "für", "wie", "was", "auch", "den", "dem", "sind", "wir", "es",
+	},
+	"it": {
+		"il", "lo", "la", "gli", "e", "è", "di", "che", "un", "una", "per", "con", "non",
+		"sono", "come", "questo", "ma", "del", "della", "mi", "ho", "io",
+	},
+	"pt": {
+		"o", "os", "as", "e", "é", "de", "que", "um", "uma", "para", "com", "não", "em",
+		"do", "da", "eu", "você", "mas", "como", "isso", "muito", "está",
+	},
+	"nl": {
+		"de", "het", "een", "en", "is", "van", "dat", "niet", "ik", "je", "met", "voor",
+		"op", "zijn", "wat", "maar", "ook", "er", "hoe", "dit", "wij",
+	},
+}
+
+// stopwordIndex maps each stopword to the languages it's used in
+var stopwordIndex = func() map[string][]string {
+	index := make(map[string][]string)
+	for language, words := range stopwords {
+		for _, word := range words {
+			index[word] = append(index[word], language)
+		}
+	}
+	return index
+}()
+
+// Detect returns the ISO 639-1 code of the language text is written in, or Unknown.
+// Detection is heuristic: texts in a distinctive script are identified by the script, and
+// texts in a Latin script by the stopwords they contain.
+func Detect(text string) string {
+	if language := detectScript(text); language != Unknown {
+		return language
+	}
+	return detectStopwords(text)
+}
+
+func detectScript(text string) string {
+	counts := make(map[string]int)
+	letters := 0
+	hasKana := false
+	hasUkrainian := false
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for _, s := range scripts {
+			if unicode.Is(s.table, r) {
+				counts[s.language]++
+				break
+			}
+		}
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			hasKana = true
+		case strings.ContainsRune("ґєіїҐЄІЇ", r):
+			hasUkrainian = true
+		}
+	}
+
+	best, bestCount := Unknown, 0
+	for language, count := range counts {
+		if count > bestCount || (count == bestCount && language < best) {
+			best, bestCount = language, count
+		}
+	}
+	// a few foreign characters in a Latin text don't make it foreign
+	if bestCount*2 < letters {
+		return Unknown
+	}
+
+	switch {
+	case best == "zh" && hasKana:
+		return "ja"
+	case best == "ru" && hasUkrainian:
+		return "uk"
+	default:
+		return best
+	}
+}
+
+func detectStopwords(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+
+	scores := make(map[string]int)
+	for _, word := range words {
+		for _, language := range stopwordIndex[strings.Trim(word, "'")] {
+			scores[language]++
+		}
+	}
+
+	best, bestScore, tied := Unknown, 0, false
+	for language, score := range scores {
+		switch {
+		case score > bestScore:
+			best, bestScore, tied = language, score, false
+		case score == bestScore:
+			tied = true
+		}
+	}
+	if bestScore < minStopwords || tied {
+		return Unknown
+	}
+
+	return best
+}
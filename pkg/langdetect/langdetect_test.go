@@ -0,0 +1,38 @@
+package langdetect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"english", "What is the weather like in Paris this week?", "en"},
+		{"spanish", "¿Qué tiempo hace en Madrid? Quiero ir con mi familia.", "es"},
+		{"french", "Je voudrais réserver une table pour deux personnes.", "fr"},
+		{"german", "Ich habe das Buch nicht gelesen, aber es ist gut.", "de"},
+		{"italian", "Non ho capito cosa vuoi dire con questo.", "it"},
+		{"portuguese", "Eu não sei se você pode vir amanhã.", "pt"},
+		{"dutch", "Ik weet niet wat het is, maar het is mooi.", "nl"},
+		{"chinese", "今天天气怎么样？", "zh"},
+		{"japanese", "今日の天気はどうですか？", "ja"},
+		{"korean", "오늘 날씨 어때요?", "ko"},
+		{"russian", "Какая сегодня погода?", "ru"},
+		{"ukrainian", "Яка сьогодні погода у Києві?", "uk"},
+		{"arabic", "كيف حالك اليوم؟", "ar"},
+		{"latin text with a foreign name", "I met Zhang (张) yesterday at the office", "en"},
+		{"no words", "42 + 7 = 49", Unknown},
+		{"empty", "", Unknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Detect(tt.text))
+		})
+	}
+}
@@ -3,12 +3,74 @@ package llms
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"github.com/getzep/zep/config"
 
 	"github.com/getzep/zep/pkg/models"
 )
 
+// Search queries and the texts they search are embedded differently by some services
+const (
+	embedInputTypeDocument = "search_document"
+	embedInputTypeQuery    = "search_query"
+)
+
+// OpenAIEmbeddingModel is the embedding model used by the openai service
+const OpenAIEmbeddingModel = "text-embedding-ada-002"
+
+// EmbeddingModelDimensions are the dimensions of known embedding models. They are used to
+// check a collection's embedding width against its embedding model.
+var EmbeddingModelDimensions = map[string]int{
+	OpenAIEmbeddingModel:                   1536,
+	"embed-english-v3.0":                   1024,
+	"embed-multilingual-v3.0":              1024,
+	"embed-english-light-v3.0":             384,
+	"embed-multilingual-light-v3.0":        384,
+	"textembedding-gecko@001":              768,
+	"textembedding-gecko@002":              768,
+	"textembedding-gecko@003":              768,
+	"textembedding-gecko-multilingual@001": 768,
+}
+
+// NewEmbedder returns an Embedder for the embedding model. documentType is the type of
+// text embedded: message, summary or document. Embeddings are checked against the model's
+// dimensions if they are set. If batching or a concurrency limit is configured for the
+// document type, texts are embedded with the model's shared batching embedder. If the
+// embedding cache is enabled, only texts that aren't cached are embedded.
+func NewEmbedder(
+	appState *models.AppState,
+	model *models.EmbeddingModel,
+	documentType string,
+) (models.Embedder, error) {
+	var embedder models.Embedder
+	switch model.Service {
+	case "local":
+		embedder = &localEmbedder{appState: appState, documentType: documentType}
+	case "cohere":
+		embedder = &cohereEmbedder{cfg: appState.Config, model: model.Model}
+	default:
+		client := GetExtractorLLMClient(appState, embeddingsExtractor(documentType))
+		if client == nil {
+			return nil, errors.New(InvalidLLMModelError)
+		}
+		embedder = &llmEmbedder{client: client}
+	}
+
+	cfg, err := embeddingsConfig(appState.Config, documentType)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.BatchSize > 0 || cfg.MaxConcurrency > 0 {
+		embedder = sharedBatchingEmbedder(appState, model, documentType, embedder, &cfg)
+	}
+	if appState.EmbeddingCache != nil {
+		embedder = newCachingEmbedder(embedder, appState.EmbeddingCache, model, documentType)
+	}
+
+	return &validatingEmbedder{embedder: embedder, dimensions: model.Dimensions}, nil
+}
+
 func EmbedTexts(
 	ctx context.Context,
 	appState *models.AppState,
@@ -16,39 +78,276 @@ func EmbedTexts(
 	documentType string,
 	text []string,
 ) ([][]float32, error) {
-	if len(text) == 0 {
-		return nil, errors.New("no text to embed")
-	}
-
-	if appState.LLMClient == nil {
-		return nil, errors.New(InvalidLLMModelError)
+	embedder, err := NewEmbedder(appState, model, documentType)
+	if err != nil {
+		return nil, err
 	}
+	return embedder.EmbedTexts(ctx, text)
+}
 
-	if model.Service == "local" {
-		return embedTextsLocal(ctx, appState, documentType, text)
+// EmbedQuery embeds a search query. Services such as Cohere embed queries differently to
+// the messages and documents being searched, which improves retrieval.
+func EmbedQuery(
+	ctx context.Context,
+	appState *models.AppState,
+	model *models.EmbeddingModel,
+	documentType string,
+	query string,
+) ([]float32, error) {
+	embedder, err := NewEmbedder(appState, model, documentType)
+	if err != nil {
+		return nil, err
 	}
-	return appState.LLMClient.EmbedTexts(ctx, text)
+	return embedder.EmbedQuery(ctx, query)
 }
 
 func GetEmbeddingModel(
 	appState *models.AppState,
 	documentType string,
 ) (*models.EmbeddingModel, error) {
-	var cfg config.EmbeddingsConfig
+	cfg, err := embeddingsConfig(appState.Config, documentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.EmbeddingModel{
+		Service:    cfg.Service,
+		Model:      cfg.Model,
+		Dimensions: cfg.Dimensions,
+	}, nil
+}
+
+// GetCollectionEmbeddingModel returns the embedding model of a document collection. The
+// configured document embedding model is used by collections created without an embedding
+// service. The model's dimensions are the collection's embedding width.
+func GetCollectionEmbeddingModel(
+	appState *models.AppState,
+	collection *models.DocumentCollection,
+) (*models.EmbeddingModel, error) {
+	model, err := GetEmbeddingModel(appState, "document")
+	if err != nil {
+		return nil, err
+	}
+	if collection.EmbeddingService != "" {
+		model.Service = collection.EmbeddingService
+		model.Model = collection.EmbeddingModelName
+	}
+	model.Dimensions = collection.EmbeddingDimensions
+
+	return model, nil
+}
+
+// SetCollectionEmbeddingModel sets the embedding service and model name of an auto-embedded
+// collection, defaulting to the configured document embedding model. If a service or model
+// is requested, an error is returned if it isn't available, or if the model's dimensions
+// don't match the collection's embedding width.
+func SetCollectionEmbeddingModel(
+	appState *models.AppState,
+	collection *models.DocumentCollection,
+) error {
+	configModel, err := GetEmbeddingModel(appState, "document")
+	if err != nil {
+		return err
+	}
+	if collection.EmbeddingService == "" && collection.EmbeddingModelName == "" {
+		// collections using the configured model are checked against the config at startup
+		collection.EmbeddingService = configModel.Service
+		collection.EmbeddingModelName = EmbeddingModelName(appState.Config, configModel)
+		return nil
+	}
+	if collection.EmbeddingService == "" {
+		collection.EmbeddingService = configModel.Service
+	}
+
+	if err := checkEmbeddingServiceAvailable(appState, collection.EmbeddingService); err != nil {
+		return err
+	}
+
+	modelName := EmbeddingModelName(appState.Config, &models.EmbeddingModel{
+		Service: collection.EmbeddingService,
+		Model:   collection.EmbeddingModelName,
+	})
+	if collection.EmbeddingService != "cohere" && collection.EmbeddingModelName != "" &&
+		collection.EmbeddingModelName != modelName {
+		return models.NewBadRequestError(fmt.Sprintf(
+			"the embedding model of the %s service can't be set per collection. "+
+				"embedding_model_name must be empty or %s",
+			collection.EmbeddingService,
+			modelName,
+		))
+	}
+	collection.EmbeddingModelName = modelName
+
+	expectedDimensions, ok := EmbeddingModelDimensions[modelName]
+	if !ok && collection.EmbeddingService == configModel.Service &&
+		modelName == EmbeddingModelName(appState.Config, configModel) {
+		// the configured dimensions are used for models we don't know, such as local models
+		expectedDimensions = configModel.Dimensions
+	}
+	if expectedDimensions > 0 && expectedDimensions != collection.EmbeddingDimensions {
+		return models.NewBadRequestError(fmt.Sprintf(
+			"embedding_dimensions is %d but the %s embedding model has %d dimensions",
+			collection.EmbeddingDimensions,
+			modelName,
+			expectedDimensions,
+		))
+	}
+
+	return nil
+}
 
+// checkEmbeddingServiceAvailable returns a bad request error if documents can't be embedded
+// by the service. openai and vertexai embeddings use an LLM client, which is only
+// available for the llm service, and for vertexai document embeddings.
+func checkEmbeddingServiceAvailable(appState *models.AppState, service string) error {
+	llmService := appState.Config.LLM.Service
+	if llmService == "" {
+		llmService = "openai"
+	}
+
+	switch service {
+	case "local", "cohere":
+		return nil
+	case "vertexai":
+		_, ok := appState.ExtractorLLMClients[embeddingsExtractor("document")]
+		if ok || llmService == service {
+			return nil
+		}
+	case "openai":
+		if llmService == service {
+			return nil
+		}
+	}
+
+	return models.NewBadRequestError(fmt.Sprintf(
+		"the %s embedding service is not available with the %s llm service",
+		service,
+		llmService,
+	))
+}
+
+// EmbeddingModelName returns the name of the model used by the embedding service, or an
+// empty string if it isn't known
+func EmbeddingModelName(cfg *config.Config, model *models.EmbeddingModel) string {
+	switch model.Service {
+	case "openai":
+		return OpenAIEmbeddingModel
+	case "vertexai":
+		if cfg.LLM.VertexAI.EmbeddingModel != "" {
+			return cfg.LLM.VertexAI.EmbeddingModel
+		}
+		return DefaultVertexAIEmbeddingModel
+	case "cohere":
+		return cohereEmbeddingModel(cfg, model.Model)
+	default:
+		return model.Model
+	}
+}
+
+// ValidateEmbeddingModel returns an error if the embedding model is known to have
+// different dimensions to those configured
+func ValidateEmbeddingModel(cfg *config.Config, model *models.EmbeddingModel) error {
+	modelName := EmbeddingModelName(cfg, model)
+	dimensions, ok := EmbeddingModelDimensions[modelName]
+	if ok && dimensions != model.Dimensions {
+		return fmt.Errorf(
+			"embedding dimensions are %d but %s has %d dimensions",
+			model.Dimensions,
+			modelName,
+			dimensions,
+		)
+	}
+	return nil
+}
+
+func embeddingsConfig(cfg *config.Config, documentType string) (config.EmbeddingsConfig, error) {
 	switch documentType {
 	case "message":
-		cfg = appState.Config.Extractors.Messages.Embeddings
+		return cfg.Extractors.Messages.Embeddings, nil
 	case "summary":
-		cfg = appState.Config.Extractors.Messages.Summarizer.Embeddings
+		return cfg.Extractors.Messages.Summarizer.Embeddings, nil
 	case "document":
-		cfg = appState.Config.Extractors.Documents.Embeddings
+		return cfg.Extractors.Documents.Embeddings, nil
 	default:
-		return nil, errors.New("invalid document type")
+		return config.EmbeddingsConfig{}, errors.New("invalid document type")
 	}
+}
 
-	return &models.EmbeddingModel{
-		Service:    cfg.Service,
-		Dimensions: cfg.Dimensions,
-	}, nil
+var _ models.Embedder = &llmEmbedder{}
+
+// llmEmbedder embeds texts using the embedding model of an LLM service
+type llmEmbedder struct {
+	client models.ZepLLM
+}
+
+func (e *llmEmbedder) EmbedTexts(ctx context.Context, texts []string) ([][]float32, error) {
+	return e.client.EmbedTexts(ctx, texts)
+}
+
+func (e *llmEmbedder) EmbedQuery(ctx context.Context, query string) ([]float32, error) {
+	return embedQuery(ctx, e, query)
+}
+
+var _ models.Embedder = &validatingEmbedder{}
+
+// validatingEmbedder checks that texts were provided, and that the embeddings returned have
+// the expected dimensions, so that they can be stored in vector columns of that width
+type validatingEmbedder struct {
+	embedder   models.Embedder
+	dimensions int
+}
+
+func (e *validatingEmbedder) EmbedTexts(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, errors.New("no text to embed")
+	}
+
+	embeddings, err := e.embedder.EmbedTexts(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.checkDimensions(embeddings...); err != nil {
+		return nil, err
+	}
+	return embeddings, nil
+}
+
+func (e *validatingEmbedder) EmbedQuery(ctx context.Context, query string) ([]float32, error) {
+	embedding, err := e.embedder.EmbedQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.checkDimensions(embedding); err != nil {
+		return nil, err
+	}
+	return embedding, nil
+}
+
+func (e *validatingEmbedder) checkDimensions(embeddings ...[]float32) error {
+	if e.dimensions == 0 {
+		return nil
+	}
+	for _, embedding := range embeddings {
+		if len(embedding) != e.dimensions {
+			return fmt.Errorf(
+				"embedding model returned %d dimensions, expected %d",
+				len(embedding),
+				e.dimensions,
+			)
+		}
+	}
+	return nil
+}
+
+// embedQuery embeds a query using the embedder's EmbedTexts, for services that embed
+// queries and documents alike
+func embedQuery(ctx context.Context, embedder models.Embedder, query string) ([]float32, error) {
+	embeddings, err := embedder.EmbedTexts(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+	if len(embeddings) == 0 {
+		return nil, errors.New("no embedding returned for query")
+	}
+	return embeddings[0], nil
 }
@@ -0,0 +1,252 @@
+package llms
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+)
+
+// DefaultEmbeddingBatchMaxWait is how long texts wait for a batch to fill if batching is
+// enabled without a max wait
+const DefaultEmbeddingBatchMaxWait = 100 * time.Millisecond
+
+var _ models.Embedder = &batchingEmbedder{}
+
+// batchingEmbedder batches texts embedded concurrently, such as the messages of sessions
+// being imported, into requests of up to size texts, and limits the concurrent requests to
+// the embedding service. A batch is sent once it's full, or maxWait after its first text.
+type batchingEmbedder struct {
+	embedder models.Embedder
+	size     int
+	maxWait  time.Duration
+	// slots limits the concurrent requests. It's nil if they aren't limited.
+	slots   chan struct{}
+	pending chan *embeddingRequest
+}
+
+type embeddingRequest struct {
+	ctx    context.Context
+	texts  []string
+	result chan embeddingResult
+}
+
+type embeddingResult struct {
+	embeddings [][]float32
+	err        error
+}
+
+// newBatchingEmbedder returns a batchingEmbedder of embedder. Texts aren't batched if
+// cfg.BatchSize is zero, and requests aren't limited if cfg.MaxConcurrency is zero.
+func newBatchingEmbedder(embedder models.Embedder, cfg *config.EmbeddingsConfig) *batchingEmbedder {
+	e := &batchingEmbedder{
+		embedder: embedder,
+		size:     cfg.BatchSize,
+		maxWait:  cfg.BatchMaxWait,
+	}
+	if e.maxWait <= 0 {
+		e.maxWait = DefaultEmbeddingBatchMaxWait
+	}
+	if cfg.MaxConcurrency > 0 {
+		e.slots = make(chan struct{}, cfg.MaxConcurrency)
+	}
+	if e.size > 0 {
+		e.pending = make(chan *embeddingRequest)
+		go e.run()
+	}
+
+	return e
+}
+
+func (e *batchingEmbedder) EmbedTexts(ctx context.Context, texts []string) ([][]float32, error) {
+	if e.size <= 0 {
+		if err := e.acquire(ctx); err != nil {
+			return nil, err
+		}
+		defer e.release()
+		return e.embedder.EmbedTexts(ctx, texts)
+	}
+
+	// texts beyond the batch size are sent in further batches
+	requests := make([]*embeddingRequest, 0, (len(texts)+e.size-1)/e.size)
+	for start := 0; start < len(texts); start += e.size {
+		end := start + e.size
+		if end > len(texts) {
+			end = len(texts)
+		}
+		request := &embeddingRequest{
+			ctx:    ctx,
+			texts:  texts[start:end],
+			result: make(chan embeddingResult, 1),
+		}
+		select {
+		case e.pending <- request:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		requests = append(requests, request)
+	}
+
+	embeddings := make([][]float32, 0, len(texts))
+	for _, request := range requests {
+		select {
+		case result := <-request.result:
+			if result.err != nil {
+				return nil, result.err
+			}
+			embeddings = append(embeddings, result.embeddings...)
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	return embeddings, nil
+}
+
+// EmbedQuery embeds a query without batching it, so that searches aren't delayed
+func (e *batchingEmbedder) EmbedQuery(ctx context.Context, query string) ([]float32, error) {
+	if err := e.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer e.release()
+
+	return e.embedder.EmbedQuery(ctx, query)
+}
+
+// run collects pending requests into batches and sends them
+func (e *batchingEmbedder) run() {
+	var batch []*embeddingRequest
+	var count int
+	var timer *time.Timer
+	var timeout <-chan time.Time
+
+	send := func() {
+		if timer != nil {
+			timer.Stop()
+			timer, timeout = nil, nil
+		}
+		if len(batch) > 0 {
+			go e.send(batch)
+		}
+		batch, count = nil, 0
+	}
+
+	for {
+		select {
+		case request := <-e.pending:
+			if count+len(request.texts) > e.size {
+				send()
+			}
+			batch = append(batch, request)
+			count += len(request.texts)
+			if count >= e.size {
+				send()
+			} else if timer == nil {
+				timer = time.NewTimer(e.maxWait)
+				timeout = timer.C
+			}
+		case <-timeout:
+			timer, timeout = nil, nil
+			send()
+		}
+	}
+}
+
+// send embeds the texts of a batch of requests in a single request to the embedding service,
+// and returns each request its embeddings
+func (e *batchingEmbedder) send(batch []*embeddingRequest) {
+	// the batch is sent even if the request it's sent with is cancelled, as it holds the texts
+	// of other requests
+	ctx := context.WithoutCancel(batch[0].ctx)
+
+	var texts []string
+	for _, request := range batch {
+		texts = append(texts, request.texts...)
+	}
+
+	var embeddings [][]float32
+	err := e.acquire(ctx)
+	if err == nil {
+		embeddings, err = e.embedder.EmbedTexts(ctx, texts)
+		e.release()
+	}
+	if err == nil && len(embeddings) != len(texts) {
+		err = fmt.Errorf("embedding service returned %d embeddings for %d texts", len(embeddings), len(texts))
+	}
+
+	var offset int
+	for _, request := range batch {
+		if err != nil {
+			request.result <- embeddingResult{err: err}
+			continue
+		}
+		request.result <- embeddingResult{
+			embeddings: embeddings[offset : offset+len(request.texts)],
+		}
+		offset += len(request.texts)
+	}
+}
+
+func (e *batchingEmbedder) acquire(ctx context.Context) error {
+	if e.slots == nil {
+		return nil
+	}
+	select {
+	case e.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (e *batchingEmbedder) release() {
+	if e.slots != nil {
+		<-e.slots
+	}
+}
+
+// embeddingBatcherKey identifies the embedding model whose requests are batched together
+type embeddingBatcherKey struct {
+	appState     *models.AppState
+	documentType string
+	service      string
+	model        string
+}
+
+// embeddingBatchers are the batching embedders of each embedding model, shared by the tasks
+// embedding texts with the model
+var embeddingBatchers = struct {
+	sync.Mutex
+	embedders map[embeddingBatcherKey]*batchingEmbedder
+}{embedders: make(map[embeddingBatcherKey]*batchingEmbedder)}
+
+// sharedBatchingEmbedder returns the batching embedder of the embedding model, creating it
+// with embedder if it doesn't exist
+func sharedBatchingEmbedder(
+	appState *models.AppState,
+	model *models.EmbeddingModel,
+	documentType string,
+	embedder models.Embedder,
+	cfg *config.EmbeddingsConfig,
+) *batchingEmbedder {
+	key := embeddingBatcherKey{
+		appState:     appState,
+		documentType: documentType,
+		service:      model.Service,
+		model:        model.Model,
+	}
+
+	embeddingBatchers.Lock()
+	defer embeddingBatchers.Unlock()
+
+	batcher, ok := embeddingBatchers.embedders[key]
+	if !ok {
+		batcher = newBatchingEmbedder(embedder, cfg)
+		embeddingBatchers.embedders[key] = batcher
+	}
+
+	return batcher
+}
@@ -0,0 +1,166 @@
+package llms
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/config"
+)
+
+// recordingEmbedder embeds each text as its index in the text, recording the requests made
+type recordingEmbedder struct {
+	mu          sync.Mutex
+	requests    [][]string
+	inFlight    int
+	maxInFlight int
+	delay       time.Duration
+}
+
+func (e *recordingEmbedder) EmbedTexts(_ context.Context, texts []string) ([][]float32, error) {
+	e.mu.Lock()
+	e.requests = append(e.requests, texts)
+	e.inFlight++
+	if e.inFlight > e.maxInFlight {
+		e.maxInFlight = e.inFlight
+	}
+	e.mu.Unlock()
+
+	time.Sleep(e.delay)
+
+	e.mu.Lock()
+	e.inFlight--
+	e.mu.Unlock()
+
+	embeddings := make([][]float32, len(texts))
+	for i, text := range texts {
+		n, err := strconv.Atoi(text)
+		if err != nil {
+			return nil, err
+		}
+		embeddings[i] = []float32{float32(n)}
+	}
+	return embeddings, nil
+}
+
+func (e *recordingEmbedder) EmbedQuery(ctx context.Context, query string) ([]float32, error) {
+	return embedQuery(ctx, e, query)
+}
+
+func numberedTexts(start, n int) []string {
+	texts := make([]string, n)
+	for i := range texts {
+		texts[i] = strconv.Itoa(start + i)
+	}
+	return texts
+}
+
+func TestBatchingEmbedder(t *testing.T) {
+	t.Run("batches concurrent texts", func(t *testing.T) {
+		recorder := &recordingEmbedder{}
+		embedder := newBatchingEmbedder(recorder, &config.EmbeddingsConfig{
+			BatchSize:    10,
+			BatchMaxWait: time.Second,
+		})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				embeddings, err := embedder.EmbedTexts(context.Background(), numberedTexts(i*2, 2))
+				require.NoError(t, err)
+				assert.Equal(t, [][]float32{{float32(i * 2)}, {float32(i*2 + 1)}}, embeddings)
+			}(i)
+		}
+		wg.Wait()
+
+		assert.Len(t, recorder.requests, 1)
+	})
+
+	t.Run("splits texts beyond the batch size", func(t *testing.T) {
+		recorder := &recordingEmbedder{}
+		embedder := newBatchingEmbedder(recorder, &config.EmbeddingsConfig{
+			BatchSize:    4,
+			BatchMaxWait: 10 * time.Millisecond,
+		})
+
+		embeddings, err := embedder.EmbedTexts(context.Background(), numberedTexts(0, 10))
+		require.NoError(t, err)
+		require.Len(t, embeddings, 10)
+		for i, embedding := range embeddings {
+			assert.Equal(t, []float32{float32(i)}, embedding)
+		}
+
+		require.Len(t, recorder.requests, 3)
+		for _, request := range recorder.requests {
+			assert.LessOrEqual(t, len(request), 4)
+		}
+	})
+
+	t.Run("sends partial batches after max wait", func(t *testing.T) {
+		recorder := &recordingEmbedder{}
+		embedder := newBatchingEmbedder(recorder, &config.EmbeddingsConfig{
+			BatchSize:    100,
+			BatchMaxWait: 10 * time.Millisecond,
+		})
+
+		embeddings, err := embedder.EmbedTexts(context.Background(), numberedTexts(0, 3))
+		require.NoError(t, err)
+		assert.Len(t, embeddings, 3)
+	})
+
+	t.Run("limits concurrent requests", func(t *testing.T) {
+		recorder := &recordingEmbedder{delay: 20 * time.Millisecond}
+		embedder := newBatchingEmbedder(recorder, &config.EmbeddingsConfig{
+			BatchSize:      1,
+			MaxConcurrency: 2,
+		})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 6; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_, err := embedder.EmbedTexts(context.Background(), numberedTexts(i, 1))
+				assert.NoError(t, err)
+			}(i)
+		}
+		wg.Wait()
+
+		assert.Len(t, recorder.requests, 6)
+		assert.Equal(t, 2, recorder.maxInFlight)
+	})
+
+	t.Run("returns errors to each request of the batch", func(t *testing.T) {
+		embedder := newBatchingEmbedder(&recordingEmbedder{}, &config.EmbeddingsConfig{
+			BatchSize:    10,
+			BatchMaxWait: 10 * time.Millisecond,
+		})
+
+		_, err := embedder.EmbedTexts(context.Background(), []string{"not a number"})
+		assert.Error(t, err)
+	})
+}
+
+func TestNewEmbedderSharesBatchingEmbedder(t *testing.T) {
+	appState := newEmbeddingsTestAppState()
+	appState.Config.Extractors.Documents.Embeddings.BatchSize = 8
+	model, err := GetEmbeddingModel(appState, "document")
+	require.NoError(t, err)
+
+	first, err := NewEmbedder(appState, model, "document")
+	require.NoError(t, err)
+	second, err := NewEmbedder(appState, model, "document")
+	require.NoError(t, err)
+
+	firstBatcher, ok := first.(*validatingEmbedder).embedder.(*batchingEmbedder)
+	require.True(t, ok, fmt.Sprintf("%T", first.(*validatingEmbedder).embedder))
+	assert.Same(t, firstBatcher, second.(*validatingEmbedder).embedder)
+}
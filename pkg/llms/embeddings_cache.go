@@ -0,0 +1,111 @@
+package llms
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/getzep/zep/pkg/models"
+)
+
+var _ models.Embedder = &cachingEmbedder{}
+
+// cachingEmbedder embeds only the texts that aren't in the embedding cache, caching the
+// embeddings of those that aren't. Texts are cached by a hash of the text and the embedding
+// model, so that repeated texts such as greetings are embedded once.
+type cachingEmbedder struct {
+	embedder models.Embedder
+	cache    models.EmbeddingCache
+	// modelKey identifies the embedding model, so that texts embedded by different models
+	// are cached separately
+	modelKey string
+}
+
+func newCachingEmbedder(
+	embedder models.Embedder,
+	cache models.EmbeddingCache,
+	model *models.EmbeddingModel,
+	documentType string,
+) *cachingEmbedder {
+	return &cachingEmbedder{
+		embedder: embedder,
+		cache:    cache,
+		modelKey: fmt.Sprintf(
+			"%s:%s:%s:%d",
+			documentType,
+			model.Service,
+			model.Model,
+			model.Dimensions,
+		),
+	}
+}
+
+// EmbedTexts returns the cached embeddings of texts, embedding the others. Cache errors are
+// logged rather than returned, as the texts can still be embedded.
+func (e *cachingEmbedder) EmbedTexts(ctx context.Context, texts []string) ([][]float32, error) {
+	keys := make([]string, len(texts))
+	for i, text := range texts {
+		keys[i] = e.key(text)
+	}
+
+	cached, err := e.cache.GetEmbeddings(ctx, keys)
+	if err != nil {
+		log.Warningf("error getting cached embeddings: %v", err)
+		cached = make(map[string][]float32)
+	}
+
+	// identical texts are embedded once
+	var missingTexts []string
+	missingKeys := make(map[string]bool)
+	for i, key := range keys {
+		if _, ok := cached[key]; ok || missingKeys[key] {
+			continue
+		}
+		missingKeys[key] = true
+		missingTexts = append(missingTexts, texts[i])
+	}
+
+	if len(missingTexts) > 0 {
+		embeddings, err := e.embedder.EmbedTexts(ctx, missingTexts)
+		if err != nil {
+			return nil, err
+		}
+		if len(embeddings) != len(missingTexts) {
+			return nil, fmt.Errorf(
+				"embedding service returned %d embeddings for %d texts",
+				len(embeddings),
+				len(missingTexts),
+			)
+		}
+
+		embedded := make(map[string][]float32, len(missingTexts))
+		for i, text := range missingTexts {
+			embedded[e.key(text)] = embeddings[i]
+		}
+		if err := e.cache.PutEmbeddings(ctx, embedded); err != nil {
+			log.Warningf("error caching embeddings: %v", err)
+		}
+		for key, embedding := range embedded {
+			cached[key] = embedding
+		}
+	}
+
+	embeddings := make([][]float32, len(texts))
+	for i, key := range keys {
+		embeddings[i] = cached[key]
+	}
+
+	return embeddings, nil
+}
+
+// EmbedQuery embeds a query without caching it, as services may embed queries differently
+func (e *cachingEmbedder) EmbedQuery(ctx context.Context, query string) ([]float32, error) {
+	return e.embedder.EmbedQuery(ctx, query)
+}
+
+// key returns the cache key of text: the hex SHA-256 hash of the model key and text
+func (e *cachingEmbedder) key(text string) string {
+	hash := sha256.Sum256([]byte(e.modelKey + "\x00" + text))
+	return hex.EncodeToString(hash[:])
+}
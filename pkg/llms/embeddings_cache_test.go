@@ -0,0 +1,92 @@
+package llms
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/pkg/models"
+)
+
+// mapEmbeddingCache is an in-memory EmbeddingCache
+type mapEmbeddingCache struct {
+	embeddings map[string][]float32
+	err        error
+}
+
+func (c *mapEmbeddingCache) GetEmbeddings(
+	_ context.Context,
+	keys []string,
+) (map[string][]float32, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	embeddings := make(map[string][]float32)
+	for _, key := range keys {
+		if embedding, ok := c.embeddings[key]; ok {
+			embeddings[key] = embedding
+		}
+	}
+	return embeddings, nil
+}
+
+func (c *mapEmbeddingCache) PutEmbeddings(
+	_ context.Context,
+	embeddings map[string][]float32,
+) error {
+	if c.err != nil {
+		return c.err
+	}
+	for key, embedding := range embeddings {
+		c.embeddings[key] = embedding
+	}
+	return nil
+}
+
+func (c *mapEmbeddingCache) PurgeEmbeddings(_ context.Context, _ time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestCachingEmbedder(t *testing.T) {
+	model := &models.EmbeddingModel{Service: "openai", Dimensions: 1}
+
+	t.Run("embeds texts that aren't cached once", func(t *testing.T) {
+		recorder := &recordingEmbedder{}
+		cache := &mapEmbeddingCache{embeddings: make(map[string][]float32)}
+		embedder := newCachingEmbedder(recorder, cache, model, "message")
+
+		embeddings, err := embedder.EmbedTexts(context.Background(), []string{"1", "2", "1"})
+		require.NoError(t, err)
+		assert.Equal(t, [][]float32{{1}, {2}, {1}}, embeddings)
+
+		embeddings, err = embedder.EmbedTexts(context.Background(), []string{"2", "3"})
+		require.NoError(t, err)
+		assert.Equal(t, [][]float32{{2}, {3}}, embeddings)
+
+		assert.Equal(t, [][]string{{"1", "2"}, {"3"}}, recorder.requests)
+		assert.Len(t, cache.embeddings, 3)
+	})
+
+	t.Run("caches models separately", func(t *testing.T) {
+		cache := &mapEmbeddingCache{embeddings: make(map[string][]float32)}
+		other := &models.EmbeddingModel{Service: "cohere", Dimensions: 1}
+
+		first := newCachingEmbedder(&recordingEmbedder{}, cache, model, "message")
+		second := newCachingEmbedder(&recordingEmbedder{}, cache, other, "message")
+		assert.NotEqual(t, first.key("1"), second.key("1"))
+	})
+
+	t.Run("embeds texts if the cache fails", func(t *testing.T) {
+		recorder := &recordingEmbedder{}
+		cache := &mapEmbeddingCache{err: errors.New("cache unavailable")}
+		embedder := newCachingEmbedder(recorder, cache, model, "message")
+
+		embeddings, err := embedder.EmbedTexts(context.Background(), []string{"1"})
+		require.NoError(t, err)
+		assert.Equal(t, [][]float32{{1}}, embeddings)
+	})
+}
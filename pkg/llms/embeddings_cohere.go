@@ -0,0 +1,181 @@
+package llms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+)
+
+const CohereAPITimeout = 30 * time.Second
+const CohereAPIKeyNotSetError = "ZEP_COHERE_API_KEY is not set" //nolint:gosec
+const MaxCohereAPIRequestAttempts = 5
+const DefaultCohereEndpoint = "https://api.cohere.ai"
+const DefaultCohereEmbeddingModel = "embed-english-v3.0"
+
+// CohereEmbeddingBatchSize is the maximum number of texts the Embed API accepts in a single
+// request
+const CohereEmbeddingBatchSize = 96
+
+type cohereEmbedRequest struct {
+	Model     string   `json:"model"`
+	Texts     []string `json:"texts"`
+	InputType string   `json:"input_type"`
+	Truncate  string   `json:"truncate"`
+}
+
+type cohereEmbedResponse struct {
+	Embeddings [][]float32 `json:"embeddings"`
+}
+
+type cohereErrorResponse struct {
+	Message string `json:"message"`
+}
+
+var _ models.Embedder = &cohereEmbedder{}
+
+// cohereEmbedder embeds texts using a Cohere embedding model. An empty model uses
+// llm.cohere.embedding_model.
+type cohereEmbedder struct {
+	cfg   *config.Config
+	model string
+}
+
+func (e *cohereEmbedder) EmbedTexts(ctx context.Context, texts []string) ([][]float32, error) {
+	return embedTextsCohere(ctx, e.cfg, e.model, embedInputTypeDocument, texts)
+}
+
+func (e *cohereEmbedder) EmbedQuery(ctx context.Context, query string) ([]float32, error) {
+	embeddings, err := embedTextsCohere(
+		ctx,
+		e.cfg,
+		e.model,
+		embedInputTypeQuery,
+		[]string{query},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return embeddings[0], nil
+}
+
+// cohereEmbeddingModel returns model, or the configured embedding model if it's empty
+func cohereEmbeddingModel(cfg *config.Config, model string) string {
+	if model != "" {
+		return model
+	}
+	if cfg.LLM.Cohere.EmbeddingModel != "" {
+		return cfg.LLM.Cohere.EmbeddingModel
+	}
+	return DefaultCohereEmbeddingModel
+}
+
+// embedTextsCohere embeds a slice of texts using the Cohere Embed API. inputType is
+// search_document for texts that are searched, and search_query for search queries, as
+// required by the v3 embedding models.
+func embedTextsCohere(
+	ctx context.Context,
+	cfg *config.Config,
+	model string,
+	inputType string,
+	texts []string,
+) ([][]float32, error) {
+	cohereCfg := cfg.LLM.Cohere
+	if cohereCfg.APIKey == "" {
+		return nil, errors.New(CohereAPIKeyNotSetError)
+	}
+	model = cohereEmbeddingModel(cfg, model)
+	endpoint := cohereCfg.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultCohereEndpoint
+	}
+	url := strings.TrimSuffix(endpoint, "/") + "/v1/embed"
+
+	ctx, cancel := context.WithTimeout(ctx, CohereAPITimeout)
+	defer cancel()
+
+	httpClient := NewRetryableHTTPClient(MaxCohereAPIRequestAttempts, CohereAPITimeout)
+
+	embeddings := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += CohereEmbeddingBatchSize {
+		end := start + CohereEmbeddingBatchSize
+		if end > len(texts) {
+			end = len(texts)
+		}
+
+		body, err := json.Marshal(cohereEmbedRequest{
+			Model:     model,
+			Texts:     texts[start:end],
+			InputType: inputType,
+			// truncate texts longer than the model's maximum rather than failing
+			Truncate: "END",
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal cohere request: %w", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create cohere request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+cohereCfg.APIKey)
+
+		batch, err := doCohereEmbedRequest(httpClient, req)
+		if err != nil {
+			return nil, err
+		}
+		if len(batch) != end-start {
+			return nil, fmt.Errorf(
+				"cohere returned %d embeddings for %d texts",
+				len(batch),
+				end-start,
+			)
+		}
+		embeddings = append(embeddings, batch...)
+	}
+
+	return embeddings, nil
+}
+
+func doCohereEmbedRequest(httpClient *http.Client, req *http.Request) ([][]float32, error) {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cohere response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, cohereResponseError(resp.StatusCode, respBody)
+	}
+
+	var embedResp cohereEmbedResponse
+	if err := json.Unmarshal(respBody, &embedResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cohere response: %w", err)
+	}
+
+	return embedResp.Embeddings, nil
+}
+
+// cohereResponseError returns an error for a Cohere API error response, using the response's
+// message if it has one
+func cohereResponseError(statusCode int, respBody []byte) error {
+	var errResp cohereErrorResponse
+	if err := json.Unmarshal(respBody, &errResp); err != nil || errResp.Message == "" {
+		return fmt.Errorf("cohere returned status %d: %s", statusCode, respBody)
+	}
+	return fmt.Errorf("cohere returned status %d: %s", statusCode, errResp.Message)
+}
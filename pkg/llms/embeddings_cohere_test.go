@@ -0,0 +1,107 @@
+package llms
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+)
+
+func newCohereTestAppState(endpoint string) *models.AppState {
+	return &models.AppState{
+		Config: &config.Config{
+			LLM: config.LLM{
+				Cohere: config.CohereConfig{
+					APIKey:   "test-key",
+					Endpoint: endpoint,
+				},
+			},
+		},
+	}
+}
+
+func TestEmbedTextsCohere(t *testing.T) {
+	var requests []cohereEmbedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/embed", r.URL.Path)
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+
+		var request cohereEmbedRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		requests = append(requests, request)
+
+		embeddings := make([][]float32, len(request.Texts))
+		for i := range request.Texts {
+			embeddings[i] = []float32{float32(len(requests)), float32(i)}
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(cohereEmbedResponse{Embeddings: embeddings}))
+	}))
+	defer server.Close()
+
+	appState := newCohereTestAppState(server.URL)
+	model := &models.EmbeddingModel{Service: "cohere", Dimensions: 2}
+
+	texts := make([]string, CohereEmbeddingBatchSize+1)
+	for i := range texts {
+		texts[i] = fmt.Sprintf("text %d", i)
+	}
+	embeddings, err := EmbedTexts(context.Background(), appState, model, "message", texts)
+	require.NoError(t, err)
+
+	require.Len(t, embeddings, len(texts))
+	assert.Equal(t, []float32{1, 0}, embeddings[0])
+	assert.Equal(t, []float32{2, 0}, embeddings[CohereEmbeddingBatchSize])
+	require.Len(t, requests, 2)
+	assert.Equal(t, cohereEmbedRequest{
+		Model:     DefaultCohereEmbeddingModel,
+		Texts:     texts[CohereEmbeddingBatchSize:],
+		InputType: "search_document",
+		Truncate:  "END",
+	}, requests[1])
+
+	// queries are embedded with the search_query input type
+	requests = nil
+	embedding, err := EmbedQuery(context.Background(), appState, model, "message", "query")
+	require.NoError(t, err)
+	assert.Equal(t, []float32{1, 0}, embedding)
+	require.Len(t, requests, 1)
+	assert.Equal(t, "search_query", requests[0].InputType)
+}
+
+func TestEmbedTextsCohere_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"message":"invalid api token"}`))
+	}))
+	defer server.Close()
+
+	model := &models.EmbeddingModel{Service: "cohere"}
+
+	_, err := EmbedTexts(
+		context.Background(),
+		newCohereTestAppState(server.URL),
+		model,
+		"document",
+		[]string{"text"},
+	)
+	assert.ErrorContains(t, err, "cohere returned status 401: invalid api token")
+
+	appState := newCohereTestAppState(server.URL)
+	appState.Config.LLM.Cohere.APIKey = ""
+	_, err = EmbedTexts(
+		context.Background(),
+		appState,
+		model,
+		"document",
+		[]string{"text"},
+	)
+	assert.ErrorContains(t, err, CohereAPIKeyNotSetError)
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/getzep/zep/pkg/models"
@@ -15,6 +16,31 @@ import (
 const MaxLocalEmbedderRetryAttempts = 5
 const LocalEmbedderTimeout = 60 * time.Second
 
+// EmbeddingsServerBatchSize is the number of texts sent to the embeddings server in a
+// single request. It's the default maximum batch size of text-embeddings-inference.
+const EmbeddingsServerBatchSize = 32
+
+type embeddingsServerRequest struct {
+	Inputs   []string `json:"inputs"`
+	Truncate bool     `json:"truncate"`
+}
+
+var _ models.Embedder = &localEmbedder{}
+
+// localEmbedder embeds texts using the local embeddings service
+type localEmbedder struct {
+	appState     *models.AppState
+	documentType string
+}
+
+func (e *localEmbedder) EmbedTexts(ctx context.Context, texts []string) ([][]float32, error) {
+	return embedTextsLocal(ctx, e.appState, e.documentType, texts)
+}
+
+func (e *localEmbedder) EmbedQuery(ctx context.Context, query string) ([]float32, error) {
+	return embedQuery(ctx, e, query)
+}
+
 // embedTextsLocal embeds a slice of texts using the local embeddings service
 func embedTextsLocal(
 	ctx context.Context,
@@ -26,6 +52,10 @@ func embedTextsLocal(
 		return nil, nil
 	}
 
+	if appState.Config.NLP.EmbeddingsServerURL != "" {
+		return embedTextsServer(ctx, appState.Config.NLP.EmbeddingsServerURL, texts)
+	}
+
 	var endpoint string
 	switch documentType {
 	case "message":
@@ -72,6 +102,52 @@ func embedTextsLocal(
 	return m, nil
 }
 
+// embedTextsServer embeds a slice of texts using a self-hosted embeddings server's /embed
+// endpoint, which returns an embedding for each of the inputs
+func embedTextsServer(ctx context.Context, serverURL string, texts []string) ([][]float32, error) {
+	url := strings.TrimSuffix(serverURL, "/") + "/embed"
+
+	embeddings := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += EmbeddingsServerBatchSize {
+		end := start + EmbeddingsServerBatchSize
+		if end > len(texts) {
+			end = len(texts)
+		}
+
+		jsonBody, err := json.Marshal(embeddingsServerRequest{
+			Inputs: texts[start:end],
+			// truncate texts longer than the model's maximum rather than failing
+			Truncate: true,
+		})
+		if err != nil {
+			log.Error("Error marshaling request body:", err)
+			return nil, err
+		}
+
+		bodyBytes, err := makeEmbedRequest(ctx, url, jsonBody)
+		if err != nil {
+			return nil, err
+		}
+
+		var batch [][]float32
+		err = json.Unmarshal(bodyBytes, &batch)
+		if err != nil {
+			log.Errorf("Error unmarshaling response body: %s", err)
+			return nil, err
+		}
+		if len(batch) != end-start {
+			return nil, fmt.Errorf(
+				"embeddings server returned %d embeddings for %d texts",
+				len(batch),
+				end-start,
+			)
+		}
+		embeddings = append(embeddings, batch...)
+	}
+
+	return embeddings, nil
+}
+
 // makeEmbedRequest makes a POST request to the local embeddings service. It
 // returns the response body as a byte slice. A retryablehttp.Client is used to
 // make the request.
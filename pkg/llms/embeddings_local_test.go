@@ -2,13 +2,18 @@ package llms
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/getzep/zep/config"
 	"github.com/getzep/zep/pkg/models"
 	"github.com/getzep/zep/pkg/testutils"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestEmbedLocal(t *testing.T) {
@@ -33,3 +38,42 @@ func TestEmbedLocal(t *testing.T) {
 		})
 	}
 }
+
+func TestEmbedLocal_EmbeddingsServer(t *testing.T) {
+	var requests []embeddingsServerRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/embed", r.URL.Path)
+
+		var request embeddingsServerRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		requests = append(requests, request)
+
+		embeddings := make([][]float32, len(request.Inputs))
+		for i := range request.Inputs {
+			embeddings[i] = []float32{float32(len(requests)), float32(i)}
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(embeddings))
+	}))
+	defer server.Close()
+
+	appState := &models.AppState{
+		Config: &config.Config{NLP: config.NLP{EmbeddingsServerURL: server.URL + "/"}},
+	}
+	model := &models.EmbeddingModel{Service: "local", Dimensions: 2}
+
+	texts := make([]string, EmbeddingsServerBatchSize+1)
+	for i := range texts {
+		texts[i] = fmt.Sprintf("text %d", i)
+	}
+	embeddings, err := EmbedTexts(context.Background(), appState, model, "message", texts)
+	require.NoError(t, err)
+
+	require.Len(t, embeddings, len(texts))
+	assert.Equal(t, []float32{1, 0}, embeddings[0])
+	assert.Equal(t, []float32{2, 0}, embeddings[EmbeddingsServerBatchSize])
+	require.Len(t, requests, 2)
+	assert.Equal(t, embeddingsServerRequest{
+		Inputs:   texts[EmbeddingsServerBatchSize:],
+		Truncate: true,
+	}, requests[1])
+}
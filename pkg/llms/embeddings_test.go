@@ -0,0 +1,188 @@
+package llms
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+)
+
+func newEmbeddingsTestAppState() *models.AppState {
+	return &models.AppState{
+		Config: &config.Config{
+			LLM: config.LLM{Service: "openai"},
+			Extractors: config.ExtractorsConfig{
+				Documents: config.DocumentExtractorsConfig{
+					Embeddings: config.EmbeddingsConfig{
+						Enabled:    true,
+						Dimensions: 384,
+						Service:    "local",
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestSetCollectionEmbeddingModel(t *testing.T) {
+	testCases := []struct {
+		name              string
+		collection        models.DocumentCollection
+		expectedService   string
+		expectedModelName string
+		expectedError     string
+	}{
+		{
+			name:            "configured model",
+			collection:      models.DocumentCollection{EmbeddingDimensions: 128},
+			expectedService: "local",
+		},
+		{
+			name: "cohere model",
+			collection: models.DocumentCollection{
+				EmbeddingService:    "cohere",
+				EmbeddingModelName:  "embed-english-light-v3.0",
+				EmbeddingDimensions: 384,
+			},
+			expectedService:   "cohere",
+			expectedModelName: "embed-english-light-v3.0",
+		},
+		{
+			name: "cohere default model",
+			collection: models.DocumentCollection{
+				EmbeddingService:    "cohere",
+				EmbeddingDimensions: 1024,
+			},
+			expectedService:   "cohere",
+			expectedModelName: DefaultCohereEmbeddingModel,
+		},
+		{
+			name: "openai model",
+			collection: models.DocumentCollection{
+				EmbeddingService:    "openai",
+				EmbeddingDimensions: 1536,
+			},
+			expectedService:   "openai",
+			expectedModelName: OpenAIEmbeddingModel,
+		},
+		{
+			name: "dimensions mismatch",
+			collection: models.DocumentCollection{
+				EmbeddingService:    "cohere",
+				EmbeddingDimensions: 384,
+			},
+			expectedError: "embedding_dimensions is 384 but the embed-english-v3.0 " +
+				"embedding model has 1024 dimensions",
+		},
+		{
+			name: "model can't be set",
+			collection: models.DocumentCollection{
+				EmbeddingService:    "openai",
+				EmbeddingModelName:  "text-embedding-3-small",
+				EmbeddingDimensions: 1536,
+			},
+			expectedError: "the embedding model of the openai service can't be set per collection",
+		},
+		{
+			name: "service not available",
+			collection: models.DocumentCollection{
+				EmbeddingService:    "vertexai",
+				EmbeddingDimensions: 768,
+			},
+			expectedError: "the vertexai embedding service is not available with the " +
+				"openai llm service",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			collection := tc.collection
+			err := SetCollectionEmbeddingModel(newEmbeddingsTestAppState(), &collection)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				assert.ErrorIs(t, err, models.ErrBadRequest)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedService, collection.EmbeddingService)
+			assert.Equal(t, tc.expectedModelName, collection.EmbeddingModelName)
+		})
+	}
+}
+
+func TestGetCollectionEmbeddingModel(t *testing.T) {
+	appState := newEmbeddingsTestAppState()
+
+	// collections created without an embedding service use the configured service
+	model, err := GetCollectionEmbeddingModel(appState, &models.DocumentCollection{
+		EmbeddingModelName:  "all-MiniLM-L6-v2",
+		EmbeddingDimensions: 128,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &models.EmbeddingModel{Service: "local", Dimensions: 128}, model)
+
+	model, err = GetCollectionEmbeddingModel(appState, &models.DocumentCollection{
+		EmbeddingService:    "cohere",
+		EmbeddingModelName:  "embed-multilingual-v3.0",
+		EmbeddingDimensions: 1024,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &models.EmbeddingModel{
+		Service:    "cohere",
+		Model:      "embed-multilingual-v3.0",
+		Dimensions: 1024,
+	}, model)
+}
+
+type fakeEmbedder struct {
+	embeddings [][]float32
+}
+
+func (e *fakeEmbedder) EmbedTexts(_ context.Context, _ []string) ([][]float32, error) {
+	return e.embeddings, nil
+}
+
+func (e *fakeEmbedder) EmbedQuery(ctx context.Context, query string) ([]float32, error) {
+	return embedQuery(ctx, e, query)
+}
+
+func TestValidatingEmbedder(t *testing.T) {
+	ctx := context.Background()
+	embedder := &validatingEmbedder{
+		embedder:   &fakeEmbedder{embeddings: [][]float32{{1, 2}, {3, 4}}},
+		dimensions: 2,
+	}
+
+	embeddings, err := embedder.EmbedTexts(ctx, []string{"text 1", "text 2"})
+	require.NoError(t, err)
+	assert.Len(t, embeddings, 2)
+
+	embedding, err := embedder.EmbedQuery(ctx, "query")
+	require.NoError(t, err)
+	assert.Equal(t, []float32{1, 2}, embedding)
+
+	_, err = embedder.EmbedTexts(ctx, nil)
+	assert.ErrorContains(t, err, "no text to embed")
+
+	embedder.dimensions = 3
+	_, err = embedder.EmbedTexts(ctx, []string{"text 1", "text 2"})
+	assert.ErrorContains(t, err, "embedding model returned 2 dimensions, expected 3")
+}
+
+func TestValidateEmbeddingModel(t *testing.T) {
+	cfg := &config.Config{}
+
+	err := ValidateEmbeddingModel(cfg, &models.EmbeddingModel{Service: "openai", Dimensions: 1536})
+	assert.NoError(t, err)
+
+	err = ValidateEmbeddingModel(cfg, &models.EmbeddingModel{Service: "cohere", Dimensions: 768})
+	assert.ErrorContains(t, err, "embedding dimensions are 768 but embed-english-v3.0 has 1024")
+
+	// the dimensions of local models aren't known
+	err = ValidateEmbeddingModel(cfg, &models.EmbeddingModel{Service: "local", Dimensions: 768})
+	assert.NoError(t, err)
+}
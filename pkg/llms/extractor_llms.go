@@ -0,0 +1,122 @@
+package llms
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+)
+
+// Extractors that may be configured to use a different llm service or model to llm
+const (
+	SummarizerExtractor = "summarizer"
+	IntentExtractor     = "intent"
+	SentimentExtractor  = "sentiment"
+	FactsExtractor      = "facts"
+)
+
+// NewExtractorLLMClients creates the LLM clients of enabled extractors that are configured
+// with their own llm service or model, keyed by extractor. These are used in place of the
+// AppState's LLMClient by GetExtractorLLMClient.
+func NewExtractorLLMClients(
+	ctx context.Context,
+	cfg *config.Config,
+) (map[string]models.ZepLLM, error) {
+	clients := make(map[string]models.ZepLLM)
+
+	for _, extractor := range []string{
+		SummarizerExtractor,
+		IntentExtractor,
+		SentimentExtractor,
+		FactsExtractor,
+	} {
+		enabled, _ := extractorLLMConfig(cfg, extractor)
+		extractorCfg := ExtractorConfig(cfg, extractor)
+		if !enabled || extractorCfg == cfg {
+			continue
+		}
+		client, err := NewLLMClient(ctx, extractorCfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s llm client: %w", extractor, err)
+		}
+		clients[extractor] = client
+	}
+
+	// Vertex AI embeddings may be used with any llm service. If llm.service is vertexai,
+	// the LLMClient embeds texts.
+	if cfg.LLM.Service != "vertexai" {
+		var vertexClient models.ZepLLM
+		for _, documentType := range []string{"message", "summary", "document"} {
+			embeddingsCfg, err := embeddingsConfig(cfg, documentType)
+			if err != nil {
+				return nil, err
+			}
+			if !embeddingsCfg.Enabled || embeddingsCfg.Service != "vertexai" {
+				continue
+			}
+			if vertexClient == nil {
+				vertexClient, err = NewVertexAILLM(ctx, cfg)
+				if err != nil {
+					return nil, fmt.Errorf("failed to create vertexai embeddings client: %w", err)
+				}
+			}
+			clients[embeddingsExtractor(documentType)] = vertexClient
+		}
+	}
+
+	return clients, nil
+}
+
+// GetExtractorLLMClient returns the LLM client used by the extractor, which is the
+// AppState's LLMClient unless the extractor is configured with its own llm
+func GetExtractorLLMClient(appState *models.AppState, extractor string) models.ZepLLM {
+	if client, ok := appState.ExtractorLLMClients[extractor]; ok {
+		return client
+	}
+	return appState.LLMClient
+}
+
+// ExtractorConfig returns the config with llm.service and llm.model replaced by the
+// extractor's llm settings. cfg is returned unchanged if the extractor has none.
+func ExtractorConfig(cfg *config.Config, extractor string) *config.Config {
+	_, llmCfg := extractorLLMConfig(cfg, extractor)
+	if llmCfg.Service == "" && llmCfg.Model == "" {
+		return cfg
+	}
+
+	extractorCfg := *cfg
+	if llmCfg.Service != "" {
+		extractorCfg.LLM.Service = llmCfg.Service
+	}
+	if llmCfg.Model != "" {
+		extractorCfg.LLM.Model = llmCfg.Model
+	}
+	return &extractorCfg
+}
+
+// extractorLLMConfig returns whether the extractor is enabled, and its llm settings
+func extractorLLMConfig(
+	cfg *config.Config,
+	extractor string,
+) (bool, config.ExtractorLLMConfig) {
+	switch extractor {
+	case SummarizerExtractor:
+		return cfg.Extractors.Messages.Summarizer.Enabled, cfg.Extractors.Messages.Summarizer.LLM
+	case IntentExtractor:
+		return cfg.Extractors.Messages.Intent.Enabled, cfg.Extractors.Messages.Intent.LLM
+	case SentimentExtractor:
+		sentiment := cfg.Extractors.Messages.Sentiment
+		// the local service doesn't use an llm
+		return sentiment.Enabled && sentiment.Service != "local", sentiment.LLM
+	case FactsExtractor:
+		return cfg.Extractors.Messages.Facts.Enabled, cfg.Extractors.Messages.Facts.LLM
+	default:
+		return false, config.ExtractorLLMConfig{}
+	}
+}
+
+// embeddingsExtractor returns the extractor key of the embeddings of a document type
+func embeddingsExtractor(documentType string) string {
+	return documentType + "_embeddings"
+}
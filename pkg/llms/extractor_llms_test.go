@@ -0,0 +1,95 @@
+package llms
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+)
+
+func TestNewExtractorLLMClients(t *testing.T) {
+	server, credentialsFile, _ := newVertexAITestServer(t, nil)
+
+	cfg := &config.Config{
+		LLM: config.LLM{
+			Service: "ollama",
+			Model:   "llama2",
+			VertexAI: config.VertexAIConfig{
+				CredentialsFile: credentialsFile,
+				Endpoint:        server.URL,
+			},
+		},
+		Extractors: config.ExtractorsConfig{
+			Messages: config.MessageExtractorsConfig{
+				Summarizer: config.SummarizerConfig{
+					Enabled: true,
+					LLM:     config.ExtractorLLMConfig{Service: "vertexai", Model: "gemini-pro"},
+				},
+				// disabled extractors don't have a client
+				Intent: config.IntentExtractorConfig{
+					LLM: config.ExtractorLLMConfig{Model: "mistral"},
+				},
+				Embeddings: config.EmbeddingsConfig{Enabled: true, Service: "vertexai"},
+			},
+			Documents: config.DocumentExtractorsConfig{
+				Embeddings: config.EmbeddingsConfig{Enabled: true, Service: "local"},
+			},
+		},
+	}
+
+	clients, err := NewExtractorLLMClients(context.Background(), cfg)
+	require.NoError(t, err)
+	assert.Len(t, clients, 2)
+
+	summarizerClient, ok := clients[SummarizerExtractor].(*ZepLLM)
+	require.True(t, ok, "Expected ZepLLM")
+	summarizerLLM, ok := summarizerClient.llm.(*ZepVertexAILLM)
+	require.True(t, ok, "Expected ZepVertexAILLM")
+	assert.Equal(t, "gemini-pro", summarizerLLM.model)
+
+	embeddingsClient, ok := clients[embeddingsExtractor("message")].(*ZepLLM)
+	require.True(t, ok, "Expected ZepLLM")
+	_, ok = embeddingsClient.llm.(*ZepVertexAILLM)
+	assert.True(t, ok, "Expected ZepVertexAILLM")
+
+	// the llm settings of the config aren't changed
+	assert.Equal(t, "ollama", cfg.LLM.Service)
+	assert.Equal(t, "llama2", cfg.LLM.Model)
+}
+
+func TestGetExtractorLLMClient(t *testing.T) {
+	defaultClient := &ZepLLM{llm: &ZepOllamaLLM{}}
+	summarizerClient := &ZepLLM{llm: &ZepVertexAILLM{}}
+	appState := &models.AppState{
+		LLMClient: defaultClient,
+		ExtractorLLMClients: map[string]models.ZepLLM{
+			SummarizerExtractor: summarizerClient,
+		},
+	}
+
+	assert.Same(t, summarizerClient, GetExtractorLLMClient(appState, SummarizerExtractor))
+	assert.Same(t, defaultClient, GetExtractorLLMClient(appState, IntentExtractor))
+}
+
+func TestExtractorConfig(t *testing.T) {
+	cfg := &config.Config{
+		LLM: config.LLM{Service: "openai", Model: "gpt-3.5-turbo"},
+		Extractors: config.ExtractorsConfig{
+			Messages: config.MessageExtractorsConfig{
+				Intent: config.IntentExtractorConfig{
+					LLM: config.ExtractorLLMConfig{Model: "gpt-4"},
+				},
+			},
+		},
+	}
+
+	assert.Same(t, cfg, ExtractorConfig(cfg, SummarizerExtractor))
+
+	intentCfg := ExtractorConfig(cfg, IntentExtractor)
+	assert.Equal(t, "openai", intentCfg.LLM.Service)
+	assert.Equal(t, "gpt-4", intentCfg.LLM.Model)
+}
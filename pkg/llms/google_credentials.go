@@ -0,0 +1,246 @@
+package llms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const googleCloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+const googleTokenURL = "https://oauth2.googleapis.com/token" //nolint:gosec
+const googleMetadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/" +
+	"instance/service-accounts/default/token" //nolint:gosec
+
+// googleTokenExpiryDelta is how long before expiry a cached access token is refreshed
+const googleTokenExpiryDelta = time.Minute
+
+// googleCredentialsFile is a service account key or the authorized user credentials
+// written by gcloud auth application-default login
+type googleCredentialsFile struct {
+	Type string `json:"type"`
+	// service_account
+	ProjectID    string `json:"project_id"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	ClientEmail  string `json:"client_email"`
+	TokenURI     string `json:"token_uri"`
+	// authorized_user
+	ClientID       string `json:"client_id"`
+	ClientSecret   string `json:"client_secret"`
+	RefreshToken   string `json:"refresh_token"`
+	QuotaProjectID string `json:"quota_project_id"`
+}
+
+type googleTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// googleTokenSource fetches OAuth2 access tokens for Google APIs, caching each token
+// until shortly before it expires
+type googleTokenSource struct {
+	fetch      func(ctx context.Context) (*googleTokenResponse, error)
+	httpClient *http.Client
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// Token returns a cached access token, or a new one if the cached token is about to expire
+func (ts *googleTokenSource) Token(ctx context.Context) (string, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.token != "" && time.Now().Add(googleTokenExpiryDelta).Before(ts.expiry) {
+		return ts.token, nil
+	}
+
+	resp, err := ts.fetch(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get google access token: %w", err)
+	}
+	ts.token = resp.AccessToken
+	ts.expiry = time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second)
+
+	return ts.token, nil
+}
+
+// findGoogleCredentials returns a token source using Application Default Credentials,
+// with the project id of the credentials, if known. Credentials are read from
+// credentialsFile, or if it's empty, the gcloud application default credentials file. If
+// that doesn't exist, tokens are fetched from the metadata server of the Google Cloud
+// environment Zep is running in.
+func findGoogleCredentials(
+	credentialsFile string,
+	httpClient *http.Client,
+) (*googleTokenSource, string, error) {
+	if credentialsFile == "" {
+		wellKnownFile := gcloudCredentialsFile()
+		if _, err := os.Stat(wellKnownFile); err != nil {
+			return newGoogleMetadataTokenSource(httpClient), "", nil
+		}
+		credentialsFile = wellKnownFile
+	}
+
+	data, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read google credentials file: %w", err)
+	}
+
+	var creds googleCredentialsFile
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, "", fmt.Errorf("failed to parse google credentials file: %w", err)
+	}
+
+	switch creds.Type {
+	case "service_account":
+		ts, err := newGoogleServiceAccountTokenSource(&creds, httpClient)
+		return ts, creds.ProjectID, err
+	case "authorized_user":
+		return newGoogleAuthorizedUserTokenSource(&creds, httpClient), creds.QuotaProjectID, nil
+	default:
+		return nil, "", fmt.Errorf("unsupported google credentials type \"%s\"", creds.Type)
+	}
+}
+
+// gcloudCredentialsFile returns the path of the credentials file written by
+// gcloud auth application-default login
+func gcloudCredentialsFile() string {
+	const name = "application_default_credentials.json"
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("APPDATA"), "gcloud", name)
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", "gcloud", name)
+}
+
+// newGoogleServiceAccountTokenSource exchanges JWTs signed with the service account's
+// private key for access tokens
+func newGoogleServiceAccountTokenSource(
+	creds *googleCredentialsFile,
+	httpClient *http.Client,
+) (*googleTokenSource, error) {
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(creds.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid service account private key: %w", err)
+	}
+	tokenURL := creds.TokenURI
+	if tokenURL == "" {
+		tokenURL = googleTokenURL
+	}
+
+	ts := &googleTokenSource{httpClient: httpClient}
+	ts.fetch = func(ctx context.Context) (*googleTokenResponse, error) {
+		now := time.Now()
+		assertion := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"iss":   creds.ClientEmail,
+			"scope": googleCloudPlatformScope,
+			"aud":   tokenURL,
+			"iat":   now.Unix(),
+			"exp":   now.Add(time.Hour).Unix(),
+		})
+		assertion.Header["kid"] = creds.PrivateKeyID
+		signed, err := assertion.SignedString(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign service account assertion: %w", err)
+		}
+
+		return ts.postTokenRequest(ctx, tokenURL, url.Values{
+			"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+			"assertion":  {signed},
+		})
+	}
+
+	return ts, nil
+}
+
+// newGoogleAuthorizedUserTokenSource exchanges the user's refresh token for access tokens
+func newGoogleAuthorizedUserTokenSource(
+	creds *googleCredentialsFile,
+	httpClient *http.Client,
+) *googleTokenSource {
+	ts := &googleTokenSource{httpClient: httpClient}
+	ts.fetch = func(ctx context.Context) (*googleTokenResponse, error) {
+		return ts.postTokenRequest(ctx, googleTokenURL, url.Values{
+			"grant_type":    {"refresh_token"},
+			"client_id":     {creds.ClientID},
+			"client_secret": {creds.ClientSecret},
+			"refresh_token": {creds.RefreshToken},
+		})
+	}
+
+	return ts
+}
+
+// newGoogleMetadataTokenSource fetches the access tokens of the service account attached
+// to the Compute Engine instance, GKE workload or Cloud Run service
+func newGoogleMetadataTokenSource(httpClient *http.Client) *googleTokenSource {
+	ts := &googleTokenSource{httpClient: httpClient}
+	ts.fetch = func(ctx context.Context) (*googleTokenResponse, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, googleMetadataTokenURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Metadata-Flavor", "Google")
+		return ts.doTokenRequest(req)
+	}
+
+	return ts
+}
+
+func (ts *googleTokenSource) postTokenRequest(
+	ctx context.Context,
+	tokenURL string,
+	form url.Values,
+) (*googleTokenResponse, error) {
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		tokenURL,
+		strings.NewReader(form.Encode()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return ts.doTokenRequest(req)
+}
+
+func (ts *googleTokenSource) doTokenRequest(req *http.Request) (*googleTokenResponse, error) {
+	resp, err := ts.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token endpoint returned status %d: %s", resp.StatusCode, body)
+	}
+
+	var token googleTokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return nil, errors.New("token endpoint returned no access token")
+	}
+
+	return &token, nil
+}
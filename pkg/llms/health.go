@@ -0,0 +1,104 @@
+package llms
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+)
+
+// OpenAIAPIURL is the base URL of the OpenAI API, used if no custom endpoint is configured
+const OpenAIAPIURL = "https://api.openai.com/v1"
+
+// AnthropicModelsURL is the Anthropic Models endpoint, used to verify the API key
+const AnthropicModelsURL = "https://api.anthropic.com/v1/models"
+
+const healthCheckTimeout = 5 * time.Second
+
+// healthCheckClient doesn't retry, so that a readiness probe fails fast
+var healthCheckClient = &http.Client{Timeout: healthCheckTimeout}
+
+// NewHealthChecks returns the readiness checks of the NLP server and the LLM service. The
+// LLM check verifies the configured credentials by listing the service's models, which
+// doesn't consume tokens. Services without a models endpoint are skipped.
+func NewHealthChecks(cfg *config.Config) []models.HealthCheck {
+	return []models.HealthCheck{
+		{
+			Name: "nlp",
+			Check: func(ctx context.Context) error {
+				if cfg.NLP.ServerURL == "" {
+					return models.ErrHealthCheckSkipped
+				}
+				return checkEndpoint(ctx, strings.TrimSuffix(cfg.NLP.ServerURL, "/")+"/healthz", nil)
+			},
+		},
+		{
+			Name: "llm",
+			Check: func(ctx context.Context) error {
+				return checkLLM(ctx, cfg)
+			},
+		},
+	}
+}
+
+func checkLLM(ctx context.Context, cfg *config.Config) error {
+	switch cfg.LLM.Service {
+	case "openai", "":
+		// Azure OpenAI keys are scoped to deployments, which have no models endpoint
+		if cfg.LLM.AzureOpenAIEndpoint != "" {
+			return models.ErrHealthCheckSkipped
+		}
+		baseURL := OpenAIAPIURL
+		if cfg.LLM.OpenAIEndpoint != "" {
+			baseURL = strings.TrimSuffix(cfg.LLM.OpenAIEndpoint, "/")
+		}
+		return checkEndpoint(ctx, baseURL+"/models", map[string]string{
+			"Authorization": "Bearer " + cfg.LLM.OpenAIAPIKey,
+		})
+	case "anthropic":
+		return checkEndpoint(ctx, AnthropicModelsURL, map[string]string{
+			"x-api-key":         cfg.LLM.AnthropicAPIKey,
+			"anthropic-version": AnthropicAPIVersion,
+		})
+	case "ollama":
+		baseURL := cfg.LLM.OllamaEndpoint
+		if baseURL == "" {
+			baseURL = DefaultOllamaEndpoint
+		}
+		return checkEndpoint(ctx, strings.TrimSuffix(baseURL, "/")+"/api/tags", nil)
+	default:
+		return models.ErrHealthCheckSkipped
+	}
+}
+
+// checkEndpoint returns an error if a GET of url with headers fails or doesn't return 200
+func checkEndpoint(ctx context.Context, url string, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := healthCheckClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("credentials were rejected with status %d", resp.StatusCode)
+	default:
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+}
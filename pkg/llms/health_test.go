@@ -0,0 +1,69 @@
+package llms
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+)
+
+func TestCheckLLM(t *testing.T) {
+	var path, authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		authorization = r.Header.Get("Authorization")
+		if authorization != "Bearer valid" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		LLM: config.LLM{
+			Service:        "openai",
+			OpenAIAPIKey:   "valid",
+			OpenAIEndpoint: server.URL + "/v1/",
+		},
+	}
+	assert.NoError(t, checkLLM(context.Background(), cfg))
+	assert.Equal(t, "/v1/models", path)
+	assert.Equal(t, "Bearer valid", authorization)
+
+	cfg.LLM.OpenAIAPIKey = "invalid"
+	assert.ErrorContains(t, checkLLM(context.Background(), cfg), "credentials were rejected")
+
+	cfg.LLM.Service = "ollama"
+	cfg.LLM.OllamaEndpoint = server.URL
+	assert.Error(t, checkLLM(context.Background(), cfg))
+	assert.Equal(t, "/api/tags", path)
+
+	cfg.LLM.Service = "bedrock"
+	assert.ErrorIs(t, checkLLM(context.Background(), cfg), models.ErrHealthCheckSkipped)
+}
+
+func TestNewHealthChecks_NLP(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{NLP: config.NLP{ServerURL: server.URL}}
+	nlp := NewHealthChecks(cfg)[0]
+	assert.Equal(t, "nlp", nlp.Name)
+	assert.NoError(t, nlp.Check(context.Background()))
+	assert.Equal(t, "/healthz", path)
+
+	server.Close()
+	assert.Error(t, nlp.Check(context.Background()))
+
+	cfg.NLP.ServerURL = ""
+	assert.ErrorIs(t, nlp.Check(context.Background()), models.ErrHealthCheckSkipped)
+}
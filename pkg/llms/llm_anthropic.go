@@ -1,20 +1,36 @@
 package llms
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"net/http"
 	"time"
 
-	"github.com/tmc/langchaingo/llms/anthropic"
-
 	"github.com/tmc/langchaingo/llms"
 
 	"github.com/getzep/zep/config"
 	"github.com/getzep/zep/pkg/models"
 )
 
+const AnthropicCallTimeout = 90 * time.Second
 const AnthropicAPITimeout = 30 * time.Second
 const AnthropicAPIKeyNotSetError = "ZEP_ANTHROPIC_API_KEY is not set" //nolint:gosec
+const MaxAnthropicAPIRequestAttempts = 5
+
+// AnthropicAPIURL is the Anthropic Text Completions endpoint
+const AnthropicAPIURL = "https://api.anthropic.com/v1/complete"
+
+// AnthropicCountTokensURL is the Anthropic Token Counting endpoint
+const AnthropicCountTokensURL = "https://api.anthropic.com/v1/messages/count_tokens"
+const AnthropicAPIVersion = "2023-06-01"
+
+// AnthropicDefaultMaxTokens is used if the caller doesn't set a max tokens call option, as
+// the Anthropic API requires one.
+const AnthropicDefaultMaxTokens = 1024
 
 var _ models.ZepLLM = &ZepAnthropicLLM{}
 
@@ -32,22 +48,16 @@ func NewAnthropicLLM(ctx context.Context, cfg *config.Config) (models.ZepLLM, er
 }
 
 type ZepAnthropicLLM struct {
-	client *anthropic.LLM
+	client *anthropicClient
 	cfg    *config.Config
 }
 
 func (zllm *ZepAnthropicLLM) Init(_ context.Context, cfg *config.Config) error {
-	options, err := zllm.configureClient(cfg)
+	client, err := zllm.configureClient(cfg)
 	if err != nil {
 		return err
 	}
-
-	// Create a new client instance with options
-	llm, err := anthropic.New(options...)
-	if err != nil {
-		return err
-	}
-	zllm.client = llm
+	zllm.client = client
 
 	return nil
 }
@@ -65,12 +75,29 @@ func (zllm *ZepAnthropicLLM) Call(ctx context.Context,
 		options = append(options, llms.WithTemperature(DefaultTemperature))
 	}
 
-	thisCtx, cancel := context.WithTimeout(ctx, AnthropicAPITimeout)
+	callOptions := llms.CallOptions{}
+	for _, opt := range options {
+		opt(&callOptions)
+	}
+
+	thisCtx, cancel := context.WithTimeout(ctx, AnthropicCallTimeout)
 	defer cancel()
 
-	prompt = "Human: " + prompt + "\nAssistant:"
+	request := &anthropicCompletionRequest{
+		Model:       zllm.client.model,
+		Prompt:      "\n\nHuman: " + prompt + "\n\nAssistant:",
+		MaxTokens:   callOptions.MaxTokens,
+		Temperature: callOptions.Temperature,
+		StopWords:   callOptions.StopWords,
+	}
+	if callOptions.Model != "" {
+		request.Model = callOptions.Model
+	}
+	if request.MaxTokens == 0 {
+		request.MaxTokens = AnthropicDefaultMaxTokens
+	}
 
-	completion, err := zllm.client.Call(thisCtx, prompt, options...)
+	completion, err := zllm.client.complete(thisCtx, request)
 	if err != nil {
 		return "", err
 	}
@@ -82,25 +109,157 @@ func (zllm *ZepAnthropicLLM) EmbedTexts(_ context.Context, _ []string) ([][]floa
 	return nil, errors.New("not implemented. use a local embedding model")
 }
 
-// GetTokenCount returns the number of tokens in the text.
-// Return 0 for now, since we don't have a token count function
-func (zllm *ZepAnthropicLLM) GetTokenCount(_ string) (int, error) {
-	return 0, nil
+// GetTokenCount returns the number of tokens in the text, counted by the Anthropic API
+func (zllm *ZepAnthropicLLM) GetTokenCount(text string) (int, error) {
+	// If the LLM is not initialized, return an error
+	if zllm.client == nil {
+		return 0, NewLLMError(InvalidLLMModelError, nil)
+	}
+
+	return (&anthropicTokenizer{client: zllm.client}).CountTokens(text)
 }
 
-func (zllm *ZepAnthropicLLM) configureClient(cfg *config.Config) ([]anthropic.Option, error) {
+func (zllm *ZepAnthropicLLM) configureClient(cfg *config.Config) (*anthropicClient, error) {
 	apiKey := cfg.LLM.AnthropicAPIKey
 	// If the key is not set, log a fatal error and exit
 	if apiKey == "" {
 		log.Fatal(AnthropicAPIKeyNotSetError)
 	}
 
-	options := make([]anthropic.Option, 0)
-	options = append(
-		options,
-		anthropic.WithModel(cfg.LLM.Model),
-		anthropic.WithToken(apiKey),
-	)
+	return newAnthropicClient(cfg), nil
+}
+
+func newAnthropicClient(cfg *config.Config) *anthropicClient {
+	// Set up the HTTP client with the same retry and backoff policy as the OpenAI client
+	httpClient := NewRetryableHTTPClient(MaxAnthropicAPIRequestAttempts, AnthropicAPITimeout)
+
+	return &anthropicClient{
+		apiKey:         cfg.LLM.AnthropicAPIKey,
+		model:          cfg.LLM.Model,
+		url:            AnthropicAPIURL,
+		countTokensURL: AnthropicCountTokensURL,
+		httpClient:     httpClient,
+	}
+}
+
+// anthropicClient is a client for the Anthropic Text Completions API. langchaingo's
+// Anthropic client doesn't accept a custom HTTP client, which we need for retries.
+type anthropicClient struct {
+	apiKey         string
+	model          string
+	url            string
+	countTokensURL string
+	httpClient     *http.Client
+}
+
+type anthropicCompletionRequest struct {
+	Model       string   `json:"model"`
+	Prompt      string   `json:"prompt"`
+	MaxTokens   int      `json:"max_tokens_to_sample"`
+	Temperature float64  `json:"temperature"`
+	StopWords   []string `json:"stop_sequences,omitempty"`
+}
+
+type anthropicCompletionResponse struct {
+	Completion string `json:"completion"`
+	StopReason string `json:"stop_reason"`
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicCountTokensRequest struct {
+	Model    string             `json:"model"`
+	Messages []anthropicMessage `json:"messages"`
+}
+
+type anthropicCountTokensResponse struct {
+	InputTokens int `json:"input_tokens"`
+}
+
+type anthropicErrorResponse struct {
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (c *anthropicClient) complete(
+	ctx context.Context,
+	request *anthropicCompletionRequest,
+) (string, error) {
+	var completion anthropicCompletionResponse
+	if err := c.post(ctx, c.url, request, &completion); err != nil {
+		return "", err
+	}
+
+	return completion.Completion, nil
+}
 
-	return options, nil
+// countTokens returns the number of tokens in the text as a user message to the client's model
+func (c *anthropicClient) countTokens(ctx context.Context, text string) (int, error) {
+	request := &anthropicCountTokensRequest{
+		Model:    c.model,
+		Messages: []anthropicMessage{{Role: "user", Content: text}},
+	}
+
+	var count anthropicCountTokensResponse
+	if err := c.post(ctx, c.countTokensURL, request, &count); err != nil {
+		return 0, err
+	}
+
+	return count.InputTokens, nil
+}
+
+// post sends the request to the url, and unmarshals the response into response
+func (c *anthropicClient) post(
+	ctx context.Context,
+	url string,
+	request interface{},
+	response interface{},
+) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal anthropic request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create anthropic request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", c.apiKey)
+	req.Header.Set("anthropic-version", AnthropicAPIVersion)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read anthropic response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp anthropicErrorResponse
+		if err := json.Unmarshal(respBody, &errResp); err != nil || errResp.Error.Message == "" {
+			return fmt.Errorf("anthropic returned status %d: %s", resp.StatusCode, respBody)
+		}
+		return fmt.Errorf(
+			"anthropic returned status %d: %s: %s",
+			resp.StatusCode,
+			errResp.Error.Type,
+			errResp.Error.Message,
+		)
+	}
+
+	if err := json.Unmarshal(respBody, response); err != nil {
+		return fmt.Errorf("failed to unmarshal anthropic response: %w", err)
+	}
+
+	return nil
 }
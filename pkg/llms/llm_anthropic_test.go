@@ -2,9 +2,13 @@ package llms
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/getzep/zep/pkg/testutils"
+	"github.com/tmc/langchaingo/llms"
 
 	"github.com/stretchr/testify/assert"
 
@@ -31,6 +35,63 @@ func TestZepAnthropicLLM_Init(t *testing.T) {
 	assert.NotNil(t, a.client, "Expected client to be initialized")
 }
 
+func TestZepAnthropicLLM_CallRetries(t *testing.T) {
+	var attempts int
+	var request map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		assert.Equal(t, "test-key", r.Header.Get("x-api-key"))
+		assert.Equal(t, AnthropicAPIVersion, r.Header.Get("anthropic-version"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+		if attempts == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error":{"type":"rate_limit_error","message":"slow down"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"completion":" Hello!","stop_reason":"stop_sequence"}`))
+	}))
+	defer server.Close()
+
+	zllm := &ZepAnthropicLLM{}
+	err := zllm.Init(context.Background(), &config.Config{
+		LLM: config.LLM{Model: "claude-2.1", AnthropicAPIKey: "test-key"},
+	})
+	assert.NoError(t, err)
+	zllm.client.url = server.URL
+
+	result, err := zllm.Call(context.Background(), "Hi", llms.WithMaxTokens(50))
+	assert.NoError(t, err)
+	assert.Equal(t, " Hello!", result)
+	assert.Equal(t, 2, attempts)
+
+	assert.Equal(t, "claude-2.1", request["model"])
+	assert.Equal(t, "\n\nHuman: Hi\n\nAssistant:", request["prompt"])
+	assert.Equal(t, float64(50), request["max_tokens_to_sample"])
+	assert.Equal(t, float64(0), request["temperature"])
+}
+
+func TestZepAnthropicLLM_CallError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write(
+			[]byte(`{"error":{"type":"invalid_request_error","message":"prompt is too long"}}`),
+		)
+	}))
+	defer server.Close()
+
+	zllm := &ZepAnthropicLLM{}
+	err := zllm.Init(context.Background(), &config.Config{
+		LLM: config.LLM{Model: "claude-2", AnthropicAPIKey: "test-key"},
+	})
+	assert.NoError(t, err)
+	zllm.client.url = server.URL
+
+	_, err = zllm.Call(context.Background(), "Hi")
+	assert.ErrorContains(t, err, "invalid_request_error: prompt is too long")
+}
+
 func TestZepAnthropicLLM_Call(t *testing.T) {
 	cfg := testutils.NewTestConfig()
 	cfg.LLM.Model = "claude-2"
@@ -57,14 +118,31 @@ func TestZepAnthropicLLM_EmbedTexts(t *testing.T) {
 }
 
 func TestZepAnthropicLLM_GetTokenCount(t *testing.T) {
-	cfg := testutils.NewTestConfig()
-
-	zllm, err := NewAnthropicLLM(context.Background(), cfg)
-	assert.NoError(t, err, "Expected no error from NewOpenAILLM")
+	var request anthropicCountTokensRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-key", r.Header.Get("x-api-key"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		_, _ = w.Write([]byte(`{"input_tokens":12}`))
+	}))
+	defer server.Close()
+
+	zllm := &ZepAnthropicLLM{}
+	err := zllm.Init(context.Background(), &config.Config{
+		LLM: config.LLM{Model: "claude-2.1", AnthropicAPIKey: "test-key"},
+	})
+	assert.NoError(t, err)
+	zllm.client.countTokensURL = server.URL
 
 	count, err := zllm.GetTokenCount("Hello, world!")
 	assert.NoError(t, err, "Expected no error from GetTokenCount")
-
-	// Should return 0
-	assert.Equal(t, 0, count, "Unexpected token count")
+	assert.Equal(t, 12, count, "Unexpected token count")
+	assert.Equal(t, anthropicCountTokensRequest{
+		Model:    "claude-2.1",
+		Messages: []anthropicMessage{{Role: "user", Content: "Hello, world!"}},
+	}, request)
+
+	// empty texts aren't sent to the API
+	count, err = zllm.GetTokenCount("")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
 }
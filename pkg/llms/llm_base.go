@@ -11,6 +11,7 @@ import (
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel"
 
+	"github.com/getzep/zep/pkg/metrics"
 	"github.com/getzep/zep/pkg/models"
 	"github.com/tmc/langchaingo/llms"
 	"go.opentelemetry.io/otel/trace"
@@ -28,6 +29,26 @@ const OtelLLMTracerName = "llm"
 
 var log = internal.GetLogger()
 
+var (
+	llmRequests = metrics.NewCounterVec(
+		"zep_llm_requests_total",
+		"LLM requests by service, operation and status, ok or error",
+		"service", "operation", "status",
+	)
+	llmRequestDuration = metrics.NewHistogramVec(
+		"zep_llm_request_duration_seconds",
+		"Latency of LLM requests by service and operation",
+		metrics.DefaultBuckets,
+		"service", "operation",
+	)
+	llmTokens = metrics.NewCounterVec(
+		"zep_llm_tokens_total",
+		"Tokens of LLM calls by service and type, prompt or completion, as counted by the "+
+			"llm's tokenizer",
+		"service", "type",
+	)
+)
+
 func NewLLMClient(ctx context.Context, cfg *config.Config) (models.ZepLLM, error) {
 	switch cfg.LLM.Service {
 	case "openai":
@@ -80,6 +101,21 @@ func NewLLMClient(ctx context.Context, cfg *config.Config) (models.ZepLLM, error
 			)
 		}
 		return NewAnthropicLLM(ctx, cfg)
+	case "ollama":
+		// Ollama serves arbitrary local models, so the model name isn't validated
+		return NewOllamaLLM(ctx, cfg)
+	case "bedrock":
+		// Bedrock model ids are validated by provider when the client is initialized
+		return NewBedrockLLM(ctx, cfg)
+	case "vertexai":
+		if _, ok := ValidVertexAILLMs[cfg.LLM.Model]; !ok {
+			return nil, fmt.Errorf(
+				"invalid llm model \"%s\" for %s",
+				cfg.LLM.Model,
+				cfg.LLM.Service,
+			)
+		}
+		return NewVertexAILLM(ctx, cfg)
 	case "":
 		// for backward compatibility
 		return NewOpenAILLM(ctx, cfg)
@@ -91,10 +127,14 @@ func NewLLMClient(ctx context.Context, cfg *config.Config) (models.ZepLLM, error
 var _ models.ZepLLM = &ZepLLM{}
 
 // ZepLLM is a wrapper around the Zep LLM implementations that implements the
-// ZepLLM interface and adds OpenTelemetry tracing
+// ZepLLM interface and adds OpenTelemetry tracing and metrics
 type ZepLLM struct {
 	llm    models.ZepLLM
 	tracer trace.Tracer
+	// service labels the llm's metrics
+	service string
+	// tokenizer, if set, overrides the llm's tokenizer
+	tokenizer Tokenizer
 }
 
 func (zllm *ZepLLM) Call(ctx context.Context,
@@ -104,12 +144,21 @@ func (zllm *ZepLLM) Call(ctx context.Context,
 	ctx, span := zllm.tracer.Start(ctx, "llm.Call")
 	defer span.End()
 
+	start := time.Now()
 	result, err := zllm.llm.Call(ctx, prompt, options...)
+	zllm.recordRequest("call", start, err)
 	if err != nil {
 		span.RecordError(err)
 		return "", err
 	}
 
+	if promptTokens, err := zllm.GetTokenCount(prompt); err == nil {
+		llmTokens.Add(float64(promptTokens), zllm.service, "prompt")
+	}
+	if completionTokens, err := zllm.GetTokenCount(result); err == nil {
+		llmTokens.Add(float64(completionTokens), zllm.service, "completion")
+	}
+
 	return result, err
 }
 
@@ -117,7 +166,9 @@ func (zllm *ZepLLM) EmbedTexts(ctx context.Context, texts []string) ([][]float32
 	ctx, span := zllm.tracer.Start(ctx, "llm.EmbedTexts")
 	defer span.End()
 
+	start := time.Now()
 	result, err := zllm.llm.EmbedTexts(ctx, texts)
+	zllm.recordRequest("embed", start, err)
 	if err != nil {
 		span.RecordError(err)
 		return nil, err
@@ -126,7 +177,19 @@ func (zllm *ZepLLM) EmbedTexts(ctx context.Context, texts []string) ([][]float32
 	return result, err
 }
 
+func (zllm *ZepLLM) recordRequest(operation string, start time.Time, err error) {
+	llmRequestDuration.Observe(time.Since(start).Seconds(), zllm.service, operation)
+	status := "ok"
+	if err != nil {
+		status = "error"
+	}
+	llmRequests.Inc(zllm.service, operation, status)
+}
+
 func (zllm *ZepLLM) GetTokenCount(text string) (int, error) {
+	if zllm.tokenizer != nil {
+		return zllm.tokenizer.CountTokens(text)
+	}
 	return zllm.llm.GetTokenCount(text)
 }
 
@@ -135,6 +198,19 @@ func (zllm *ZepLLM) Init(ctx context.Context, cfg *config.Config) error {
 	tracer := otel.Tracer(OtelLLMTracerName)
 	zllm.tracer = tracer
 
+	zllm.service = cfg.LLM.Service
+	if zllm.service == "" {
+		zllm.service = "openai"
+	}
+
+	if cfg.LLM.Tokenizer != "" {
+		tokenizer, err := NewTokenizer(cfg)
+		if err != nil {
+			return err
+		}
+		zllm.tokenizer = tokenizer
+	}
+
 	return zllm.llm.Init(ctx, cfg)
 }
 
@@ -161,11 +237,21 @@ var ValidOpenAILLMs = map[string]bool{
 }
 
 var ValidAnthropicLLMs = map[string]bool{
-	"claude-instant-1": true,
-	"claude-2":         true,
+	"claude-instant-1":   true,
+	"claude-instant-1.2": true,
+	"claude-2":           true,
+	"claude-2.0":         true,
+	"claude-2.1":         true,
+}
+
+var ValidVertexAILLMs = map[string]bool{
+	"gemini-pro":       true,
+	"gemini-1.0-pro":   true,
+	"gemini-1.5-pro":   true,
+	"gemini-1.5-flash": true,
 }
 
-var ValidLLMMap = internal.MergeMaps(ValidOpenAILLMs, ValidAnthropicLLMs)
+var ValidLLMMap = internal.MergeMaps(ValidOpenAILLMs, ValidAnthropicLLMs, ValidVertexAILLMs)
 
 var MaxLLMTokensMap = map[string]int{
 	"gpt-3.5-turbo":      4096,
@@ -175,13 +261,22 @@ var MaxLLMTokensMap = map[string]int{
 	"gpt-4-32k":          32_768,
 	"gpt-4-1106-preview": 128_000,
 	"claude-instant-1":   100_000,
+	"claude-instant-1.2": 100_000,
 	"claude-2":           100_000,
+	"claude-2.0":         100_000,
+	"claude-2.1":         200_000,
+	"gemini-pro":         32_760,
+	"gemini-1.0-pro":     32_760,
+	"gemini-1.5-pro":     1_048_576,
+	"gemini-1.5-flash":   1_048_576,
 }
 
 func GetLLMModelName(cfg *config.Config) (string, error) {
 	llmModel := cfg.LLM.Model
-	// Don't validate if custom OpenAI endpoint or Azure OpenAI endpoint is set
-	if cfg.LLM.OpenAIEndpoint != "" || cfg.LLM.AzureOpenAIEndpoint != "" {
+	// Don't validate if custom OpenAI endpoint or Azure OpenAI endpoint is set, or if
+	// the model is served by Ollama or Bedrock
+	if cfg.LLM.OpenAIEndpoint != "" || cfg.LLM.AzureOpenAIEndpoint != "" ||
+		cfg.LLM.Service == "ollama" || cfg.LLM.Service == "bedrock" {
 		return llmModel, nil
 	}
 	if llmModel == "" || !ValidLLMMap[llmModel] {
@@ -190,6 +285,15 @@ func GetLLMModelName(cfg *config.Config) (string, error) {
 	return llmModel, nil
 }
 
+// charsPerToken is used to estimate token counts for models without a known tokenizer
+const charsPerToken = 4
+
+// estimateTokenCount estimates the number of tokens in the text, for models whose
+// tokenizer is unknown
+func estimateTokenCount(text string) int {
+	return (len([]rune(text)) + charsPerToken - 1) / charsPerToken
+}
+
 func Float64ToFloat32Matrix(in [][]float64) [][]float32 {
 	out := make([][]float32, len(in))
 	for i := range in {
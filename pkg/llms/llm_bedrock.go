@@ -0,0 +1,271 @@
+package llms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+)
+
+const BedrockCallTimeout = 90 * time.Second
+const BedrockAPITimeout = 30 * time.Second
+const MaxBedrockAPIRequestAttempts = 5
+const BedrockCredentialsNotSetError = "AWS credentials for Bedrock are not set" //nolint:gosec
+const bedrockService = "bedrock"
+
+// BedrockDefaultMaxTokens is used if the caller doesn't set a max tokens call option
+const BedrockDefaultMaxTokens = 1024
+
+// Bedrock model families, identified by the provider prefix of the model id
+const (
+	BedrockProviderAnthropic = "anthropic"
+	BedrockProviderAmazon    = "amazon"
+	BedrockProviderMeta      = "meta"
+)
+
+var _ models.ZepLLM = &ZepBedrockLLM{}
+
+func NewBedrockLLM(ctx context.Context, cfg *config.Config) (models.ZepLLM, error) {
+	zllm := &ZepLLM{
+		llm: &ZepBedrockLLM{
+			cfg: cfg,
+		},
+	}
+	err := zllm.Init(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return zllm, nil
+}
+
+// ZepBedrockLLM calls Claude, Titan and Llama 2 text models using the Bedrock InvokeModel API
+type ZepBedrockLLM struct {
+	endpoint   string
+	region     string
+	modelID    string
+	provider   string
+	creds      AWSCredentials
+	httpClient *http.Client
+	cfg        *config.Config
+}
+
+func (zllm *ZepBedrockLLM) Init(_ context.Context, cfg *config.Config) error {
+	bedrockCfg := cfg.LLM.Bedrock
+	if bedrockCfg.AccessKeyID == "" || bedrockCfg.SecretAccessKey == "" {
+		log.Fatal(BedrockCredentialsNotSetError)
+	}
+	if bedrockCfg.Region == "" {
+		return errors.New("llm.bedrock.region must be set")
+	}
+
+	provider, err := BedrockModelProvider(cfg.LLM.Model)
+	if err != nil {
+		return err
+	}
+
+	endpoint := bedrockCfg.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com", bedrockCfg.Region)
+	}
+
+	zllm.endpoint = strings.TrimSuffix(endpoint, "/")
+	zllm.region = bedrockCfg.Region
+	zllm.modelID = cfg.LLM.Model
+	zllm.provider = provider
+	zllm.creds = AWSCredentials{
+		AccessKeyID:     bedrockCfg.AccessKeyID,
+		SecretAccessKey: bedrockCfg.SecretAccessKey,
+		SessionToken:    bedrockCfg.SessionToken,
+	}
+	zllm.httpClient = NewRetryableHTTPClient(MaxBedrockAPIRequestAttempts, BedrockAPITimeout)
+
+	return nil
+}
+
+// BedrockModelProvider returns the provider of a Bedrock model id, e.g. anthropic for
+// anthropic.claude-v2. Returns an error if the provider's models aren't supported.
+func BedrockModelProvider(modelID string) (string, error) {
+	provider, _, found := strings.Cut(modelID, ".")
+	if !found {
+		return "", fmt.Errorf("invalid bedrock model id \"%s\"", modelID)
+	}
+
+	switch provider {
+	case BedrockProviderAnthropic, BedrockProviderAmazon, BedrockProviderMeta:
+		return provider, nil
+	default:
+		return "", fmt.Errorf("unsupported bedrock model \"%s\"", modelID)
+	}
+}
+
+func (zllm *ZepBedrockLLM) Call(ctx context.Context,
+	prompt string,
+	options ...llms.CallOption,
+) (string, error) {
+	// If the LLM is not initialized, return an error
+	if zllm.httpClient == nil {
+		return "", NewLLMError(InvalidLLMModelError, nil)
+	}
+
+	if len(options) == 0 {
+		options = append(options, llms.WithTemperature(DefaultTemperature))
+	}
+
+	callOptions := llms.CallOptions{}
+	for _, opt := range options {
+		opt(&callOptions)
+	}
+	if callOptions.MaxTokens == 0 {
+		callOptions.MaxTokens = BedrockDefaultMaxTokens
+	}
+
+	body, err := json.Marshal(zllm.requestBody(prompt, &callOptions))
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal bedrock request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, BedrockCallTimeout)
+	defer cancel()
+
+	respBody, err := zllm.invoke(ctx, body)
+	if err != nil {
+		return "", err
+	}
+
+	return zllm.parseResponse(respBody)
+}
+
+// requestBody returns the InvokeModel request body for the model's provider
+func (zllm *ZepBedrockLLM) requestBody(
+	prompt string,
+	callOptions *llms.CallOptions,
+) map[string]interface{} {
+	switch zllm.provider {
+	case BedrockProviderAnthropic:
+		body := map[string]interface{}{
+			"prompt":               "\n\nHuman: " + prompt + "\n\nAssistant:",
+			"max_tokens_to_sample": callOptions.MaxTokens,
+			"temperature":          callOptions.Temperature,
+		}
+		if len(callOptions.StopWords) > 0 {
+			body["stop_sequences"] = callOptions.StopWords
+		}
+		return body
+	case BedrockProviderAmazon:
+		textConfig := map[string]interface{}{
+			"maxTokenCount": callOptions.MaxTokens,
+			"temperature":   callOptions.Temperature,
+		}
+		if len(callOptions.StopWords) > 0 {
+			textConfig["stopSequences"] = callOptions.StopWords
+		}
+		return map[string]interface{}{
+			"inputText":            prompt,
+			"textGenerationConfig": textConfig,
+		}
+	default:
+		// Llama 2 doesn't support stop sequences
+		return map[string]interface{}{
+			"prompt":      prompt,
+			"max_gen_len": callOptions.MaxTokens,
+			"temperature": callOptions.Temperature,
+		}
+	}
+}
+
+// parseResponse returns the completion from the provider's InvokeModel response body
+func (zllm *ZepBedrockLLM) parseResponse(respBody []byte) (string, error) {
+	var resp struct {
+		// Anthropic
+		Completion string `json:"completion"`
+		// Amazon Titan
+		Results []struct {
+			OutputText string `json:"outputText"`
+		} `json:"results"`
+		// Meta Llama 2
+		Generation string `json:"generation"`
+	}
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return "", fmt.Errorf("failed to unmarshal bedrock response: %w", err)
+	}
+
+	switch zllm.provider {
+	case BedrockProviderAnthropic:
+		return resp.Completion, nil
+	case BedrockProviderAmazon:
+		if len(resp.Results) == 0 {
+			return "", errors.New("bedrock returned no results")
+		}
+		return resp.Results[0].OutputText, nil
+	default:
+		return resp.Generation, nil
+	}
+}
+
+// invoke sends a signed InvokeModel request for the model and returns the response body
+func (zllm *ZepBedrockLLM) invoke(ctx context.Context, body []byte) ([]byte, error) {
+	invokeURL, err := url.Parse(zllm.endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bedrock endpoint: %w", err)
+	}
+	// model ids may contain a ':' version suffix, so the id is escaped in the path
+	invokeURL.RawPath = "/model/" + AWSURIEncode(zllm.modelID) + "/invoke"
+	invokeURL.Path = "/model/" + zllm.modelID + "/invoke"
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		invokeURL.String(),
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bedrock request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	SignAWSRequestV4(req, body, zllm.creds, zllm.region, bedrockService, time.Now())
+
+	resp, err := zllm.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bedrock response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp struct {
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(respBody, &errResp); err != nil || errResp.Message == "" {
+			return nil, fmt.Errorf("bedrock returned status %d: %s", resp.StatusCode, respBody)
+		}
+		return nil, fmt.Errorf("bedrock returned status %d: %s", resp.StatusCode, errResp.Message)
+	}
+
+	return respBody, nil
+}
+
+func (zllm *ZepBedrockLLM) EmbedTexts(_ context.Context, _ []string) ([][]float32, error) {
+	return nil, errors.New("not implemented. use a local embedding model")
+}
+
+// GetTokenCount returns an estimate of the number of tokens in the text, as the tokenizers
+// of Bedrock models aren't available
+func (zllm *ZepBedrockLLM) GetTokenCount(text string) (int, error) {
+	return estimateTokenCount(text), nil
+}
@@ -0,0 +1,170 @@
+package llms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+)
+
+// OllamaCallTimeout is generous as local models may be slow, particularly on CPU
+const OllamaCallTimeout = 5 * time.Minute
+const OllamaAPITimeout = 2 * time.Minute
+const MaxOllamaAPIRequestAttempts = 3
+const DefaultOllamaEndpoint = "http://localhost:11434"
+
+var _ models.ZepLLM = &ZepOllamaLLM{}
+
+func NewOllamaLLM(ctx context.Context, cfg *config.Config) (models.ZepLLM, error) {
+	zllm := &ZepLLM{
+		llm: &ZepOllamaLLM{
+			cfg: cfg,
+		},
+	}
+	err := zllm.Init(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return zllm, nil
+}
+
+// ZepOllamaLLM calls models served by Ollama's generate API
+type ZepOllamaLLM struct {
+	baseURL    string
+	model      string
+	httpClient *http.Client
+	cfg        *config.Config
+}
+
+func (zllm *ZepOllamaLLM) Init(_ context.Context, cfg *config.Config) error {
+	if cfg.LLM.Model == "" {
+		return errors.New("llm.model must be set to an Ollama model name")
+	}
+
+	baseURL := cfg.LLM.OllamaEndpoint
+	if baseURL == "" {
+		baseURL = DefaultOllamaEndpoint
+	}
+	zllm.baseURL = strings.TrimSuffix(baseURL, "/")
+	zllm.model = cfg.LLM.Model
+	zllm.httpClient = NewRetryableHTTPClient(MaxOllamaAPIRequestAttempts, OllamaAPITimeout)
+
+	return nil
+}
+
+type ollamaGenerateRequest struct {
+	Model   string                 `json:"model"`
+	Prompt  string                 `json:"prompt"`
+	Stream  bool                   `json:"stream"`
+	Options map[string]interface{} `json:"options,omitempty"`
+}
+
+type ollamaGenerateResponse struct {
+	Response string `json:"response"`
+}
+
+type ollamaErrorResponse struct {
+	Error string `json:"error"`
+}
+
+func (zllm *ZepOllamaLLM) Call(ctx context.Context,
+	prompt string,
+	options ...llms.CallOption,
+) (string, error) {
+	// If the LLM is not initialized, return an error
+	if zllm.httpClient == nil {
+		return "", NewLLMError(InvalidLLMModelError, nil)
+	}
+
+	if len(options) == 0 {
+		options = append(options, llms.WithTemperature(DefaultTemperature))
+	}
+
+	callOptions := llms.CallOptions{}
+	for _, opt := range options {
+		opt(&callOptions)
+	}
+
+	request := ollamaGenerateRequest{
+		Model:  zllm.model,
+		Prompt: prompt,
+		Options: map[string]interface{}{
+			"temperature": callOptions.Temperature,
+		},
+	}
+	if callOptions.Model != "" {
+		request.Model = callOptions.Model
+	}
+	if callOptions.MaxTokens > 0 {
+		request.Options["num_predict"] = callOptions.MaxTokens
+	}
+	if len(callOptions.StopWords) > 0 {
+		request.Options["stop"] = callOptions.StopWords
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, OllamaCallTimeout)
+	defer cancel()
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal ollama request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		zllm.baseURL+"/api/generate",
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to create ollama request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := zllm.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read ollama response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp ollamaErrorResponse
+		if err := json.Unmarshal(respBody, &errResp); err != nil || errResp.Error == "" {
+			return "", fmt.Errorf("ollama returned status %d: %s", resp.StatusCode, respBody)
+		}
+		return "", fmt.Errorf("ollama returned status %d: %s", resp.StatusCode, errResp.Error)
+	}
+
+	var completion ollamaGenerateResponse
+	if err := json.Unmarshal(respBody, &completion); err != nil {
+		return "", fmt.Errorf("failed to unmarshal ollama response: %w", err)
+	}
+
+	return completion.Response, nil
+}
+
+func (zllm *ZepOllamaLLM) EmbedTexts(_ context.Context, _ []string) ([][]float32, error) {
+	return nil, errors.New("not implemented. use a local embedding model")
+}
+
+// GetTokenCount returns an estimate of the number of tokens in the text, as the model's
+// tokenizer is unknown. tiktoken isn't used as it downloads its encodings on first use,
+// which isn't possible when running offline.
+func (zllm *ZepOllamaLLM) GetTokenCount(text string) (int, error) {
+	return estimateTokenCount(text), nil
+}
@@ -0,0 +1,112 @@
+package llms
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/getzep/zep/config"
+)
+
+func TestNewLLMClient_Ollama(t *testing.T) {
+	cfg := &config.Config{
+		LLM: config.LLM{
+			Service: "ollama",
+			Model:   "llama2",
+		},
+	}
+
+	zllm, err := NewLLMClient(context.Background(), cfg)
+	require.NoError(t, err)
+
+	z, ok := zllm.(*ZepLLM)
+	assert.True(t, ok, "Expected ZepLLM")
+	o, ok := z.llm.(*ZepOllamaLLM)
+	assert.True(t, ok, "Expected ZepOllamaLLM")
+	assert.Equal(t, DefaultOllamaEndpoint, o.baseURL)
+
+	modelName, err := GetLLMModelName(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, "llama2", modelName)
+
+	_, err = NewOllamaLLM(context.Background(), &config.Config{
+		LLM: config.LLM{Service: "ollama"},
+	})
+	assert.Error(t, err, "Expected error when model is not set")
+}
+
+func TestZepOllamaLLM_Call(t *testing.T) {
+	var path string
+	var request map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		_, _ = w.Write([]byte(`{"model":"llama2","response":"Hello!","done":true}`))
+	}))
+	defer server.Close()
+
+	zllm, err := NewOllamaLLM(context.Background(), &config.Config{
+		LLM: config.LLM{
+			Service:        "ollama",
+			Model:          "llama2",
+			OllamaEndpoint: server.URL + "/",
+		},
+	})
+	require.NoError(t, err)
+
+	result, err := zllm.Call(context.Background(), "Hi", llms.WithMaxTokens(50))
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello!", result)
+
+	assert.Equal(t, "/api/generate", path)
+	assert.Equal(t, "llama2", request["model"])
+	assert.Equal(t, "Hi", request["prompt"])
+	assert.Equal(t, false, request["stream"])
+	assert.Equal(t, map[string]interface{}{
+		"temperature": float64(0),
+		"num_predict": float64(50),
+	}, request["options"])
+}
+
+func TestZepOllamaLLM_CallError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":"model 'llama2' not found, try pulling it first"}`))
+	}))
+	defer server.Close()
+
+	zllm, err := NewOllamaLLM(context.Background(), &config.Config{
+		LLM: config.LLM{Service: "ollama", Model: "llama2", OllamaEndpoint: server.URL},
+	})
+	require.NoError(t, err)
+
+	_, err = zllm.Call(context.Background(), "Hi")
+	assert.ErrorContains(t, err, "model 'llama2' not found")
+}
+
+func TestZepOllamaLLM_GetTokenCount(t *testing.T) {
+	zllm := &ZepOllamaLLM{}
+
+	tests := []struct {
+		text     string
+		expected int
+	}{
+		{"", 0},
+		{"Hi", 1},
+		{"Hello, world!", 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			count, err := zllm.GetTokenCount(tt.text)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, count)
+		})
+	}
+}
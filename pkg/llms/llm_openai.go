@@ -10,7 +10,6 @@ import (
 
 	"github.com/getzep/zep/config"
 	"github.com/getzep/zep/pkg/models"
-	"github.com/pkoukk/tiktoken-go"
 	"github.com/tmc/langchaingo/llms/openai"
 )
 
@@ -36,18 +35,20 @@ func NewOpenAILLM(ctx context.Context, cfg *config.Config) (models.ZepLLM, error
 
 type ZepOpenAILLM struct {
 	client *openai.Chat
-	cfg    *config.Config
-	tkm    *tiktoken.Tiktoken
+	// embeddingClient is client unless the Azure OpenAI embedding deployment is on a
+	// different endpoint
+	embeddingClient *openai.Chat
+	cfg             *config.Config
+	tokenizer       *tiktokenTokenizer
 }
 
 func (zllm *ZepOpenAILLM) Init(_ context.Context, cfg *config.Config) error {
 	// Initialize the Tiktoken client
-	encoding := "cl100k_base"
-	tkm, err := tiktoken.GetEncoding(encoding)
+	tokenizer, err := newTiktokenTokenizer(DefaultTiktokenEncoding)
 	if err != nil {
 		return err
 	}
-	zllm.tkm = tkm
+	zllm.tokenizer = tokenizer
 
 	options, err := zllm.configureClient(cfg)
 	if err != nil {
@@ -60,6 +61,15 @@ func (zllm *ZepOpenAILLM) Init(_ context.Context, cfg *config.Config) error {
 		return err
 	}
 	zllm.client = llm
+	zllm.embeddingClient = llm
+
+	if embeddingOptions := zllm.configureEmbeddingClient(cfg, options); embeddingOptions != nil {
+		embeddingLLM, err := openai.NewChat(embeddingOptions...)
+		if err != nil {
+			return err
+		}
+		zllm.embeddingClient = embeddingLLM
+	}
 
 	return nil
 }
@@ -92,14 +102,14 @@ func (zllm *ZepOpenAILLM) Call(ctx context.Context,
 
 func (zllm *ZepOpenAILLM) EmbedTexts(ctx context.Context, texts []string) ([][]float32, error) {
 	// If the LLM is not initialized, return an error
-	if zllm.client == nil {
+	if zllm.embeddingClient == nil {
 		return nil, NewLLMError(InvalidLLMModelError, nil)
 	}
 
 	ctx, cancel := context.WithTimeout(ctx, OpenAICallTimeout)
 	defer cancel()
 
-	embeddings, err := zllm.client.CreateEmbedding(ctx, texts)
+	embeddings, err := zllm.embeddingClient.CreateEmbedding(ctx, texts)
 	if err != nil {
 		return nil, NewLLMError("error while creating embedding", err)
 	}
@@ -109,7 +119,7 @@ func (zllm *ZepOpenAILLM) EmbedTexts(ctx context.Context, texts []string) ([][]f
 
 // GetTokenCount returns the number of tokens in the text
 func (zllm *ZepOpenAILLM) GetTokenCount(text string) (int, error) {
-	return len(zllm.tkm.Encode(text, nil, nil)), nil
+	return zllm.tokenizer.CountTokens(text)
 }
 
 func (zllm *ZepOpenAILLM) configureClient(cfg *config.Config) ([]openai.Option, error) {
@@ -138,10 +148,15 @@ func (zllm *ZepOpenAILLM) configureClient(cfg *config.Config) ([]openai.Option,
 	case cfg.LLM.AzureOpenAIEndpoint != "":
 		// Check configuration for AzureOpenAIEndpoint; if it's set, use the DefaultAzureConfig
 		// and provided endpoint Path
+		apiVersion := cfg.LLM.AzureOpenAIModel.APIVersion
+		if apiVersion == "" {
+			apiVersion = openai.DefaultAPIVersion
+		}
 		options = append(
 			options,
 			openai.WithAPIType(openai.APITypeAzure),
 			openai.WithBaseURL(cfg.LLM.AzureOpenAIEndpoint),
+			openai.WithAPIVersion(apiVersion),
 		)
 		if cfg.LLM.AzureOpenAIModel.EmbeddingDeployment != "" {
 			options = append(
@@ -161,3 +176,25 @@ func (zllm *ZepOpenAILLM) configureClient(cfg *config.Config) ([]openai.Option,
 
 	return options, nil
 }
+
+// configureEmbeddingClient returns the options for a separate embedding client if the Azure
+// OpenAI embedding deployment is on a different endpoint than the llm deployment. Otherwise,
+// it returns nil and embeddings are created using the llm client.
+func (zllm *ZepOpenAILLM) configureEmbeddingClient(
+	cfg *config.Config,
+	options []openai.Option,
+) []openai.Option {
+	azureCfg := cfg.LLM.AzureOpenAIModel
+	if cfg.LLM.AzureOpenAIEndpoint == "" || azureCfg.EmbeddingEndpoint == "" {
+		return nil
+	}
+
+	// later options override earlier ones
+	embeddingOptions := append([]openai.Option{}, options...)
+	embeddingOptions = append(embeddingOptions, openai.WithBaseURL(azureCfg.EmbeddingEndpoint))
+	if azureCfg.EmbeddingAPIKey != "" {
+		embeddingOptions = append(embeddingOptions, openai.WithToken(azureCfg.EmbeddingAPIKey))
+	}
+
+	return embeddingOptions
+}
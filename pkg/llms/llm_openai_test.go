@@ -2,11 +2,14 @@ package llms
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/getzep/zep/pkg/testutils"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/tmc/langchaingo/llms/openai"
 
 	"github.com/getzep/zep/config"
 )
@@ -33,7 +36,7 @@ func TestZepOpenAILLM_Init(t *testing.T) {
 	o, ok := z.llm.(*ZepOpenAILLM)
 	assert.True(t, ok, "Expected ZepOpenAILLM")
 	assert.NotNil(t, o.client, "Expected tkm to be initialized")
-	assert.NotNil(t, o.tkm, "Expected tkm to be initialized")
+	assert.NotNil(t, o.tokenizer, "Expected tokenizer to be initialized")
 }
 
 func TestZepOpenAILLM_TestConfigureClient(t *testing.T) {
@@ -69,8 +72,8 @@ func TestZepOpenAILLM_TestConfigureClient(t *testing.T) {
 			t.Errorf("Unexpected error: %v", err)
 		}
 
-		if len(options) != 5 {
-			t.Errorf("Expected 4 options, got %d", len(options))
+		if len(options) != 6 {
+			t.Errorf("Expected 6 options, got %d", len(options))
 		}
 	})
 
@@ -92,8 +95,8 @@ func TestZepOpenAILLM_TestConfigureClient(t *testing.T) {
 			t.Errorf("Unexpected error: %v", err)
 		}
 
-		if len(options) != 6 {
-			t.Errorf("Expected 6 options, got %d", len(options))
+		if len(options) != 7 {
+			t.Errorf("Expected 7 options, got %d", len(options))
 		}
 	})
 
@@ -135,6 +138,56 @@ func TestZepOpenAILLM_TestConfigureClient(t *testing.T) {
 	})
 }
 
+func TestZepOpenAILLM_AzureEmbeddingEndpoint(t *testing.T) {
+	var llmRequests int
+	llmServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		llmRequests++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer llmServer.Close()
+
+	var path, apiVersion, apiKey string
+	embeddingServer := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+			apiVersion = r.URL.Query().Get("api-version")
+			apiKey = r.Header.Get("api-key")
+			_, _ = w.Write([]byte(`{"data":[{"embedding":[0.1,0.2]}]}`))
+		}),
+	)
+	defer embeddingServer.Close()
+
+	cfg := &config.Config{
+		LLM: config.LLM{
+			OpenAIAPIKey:        "test-key",
+			AzureOpenAIEndpoint: llmServer.URL,
+			Model:               "test-llm-deployment",
+			AzureOpenAIModel: config.AzureOpenAIConfig{
+				EmbeddingDeployment: "test-embedding-deployment",
+				APIVersion:          "2023-12-01-preview",
+				EmbeddingEndpoint:   embeddingServer.URL,
+				EmbeddingAPIKey:     "test-embedding-key",
+			},
+		},
+	}
+
+	// Init downloads the tiktoken encoding, so create the embedding client directly
+	zllm := &ZepOpenAILLM{}
+	options, err := zllm.configureClient(cfg)
+	assert.NoError(t, err)
+	zllm.embeddingClient, err = openai.NewChat(zllm.configureEmbeddingClient(cfg, options)...)
+	assert.NoError(t, err)
+
+	embeddings, err := zllm.EmbedTexts(context.Background(), []string{"Hello, world!"})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]float32{{0.1, 0.2}}, embeddings)
+
+	assert.Equal(t, 0, llmRequests)
+	assert.Equal(t, "/openai/deployments/test-embedding-deployment/embeddings", path)
+	assert.Equal(t, "2023-12-01-preview", apiVersion)
+	assert.Equal(t, "test-embedding-key", apiKey)
+}
+
 func TestZepOpenAILLM_Call(t *testing.T) {
 	cfg := testutils.NewTestConfig()
 	cfg.LLM.Model = "gpt-3.5-turbo"
@@ -0,0 +1,329 @@
+package llms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+)
+
+const VertexAICallTimeout = 90 * time.Second
+const VertexAIAPITimeout = 30 * time.Second
+const MaxVertexAIAPIRequestAttempts = 5
+const DefaultVertexAILocation = "us-central1"
+const DefaultVertexAIEmbeddingModel = "textembedding-gecko@003"
+
+// VertexAIEmbeddingBatchSize is the maximum number of texts the embedding models accept
+// in a single request
+const VertexAIEmbeddingBatchSize = 5
+
+var _ models.ZepLLM = &ZepVertexAILLM{}
+
+func NewVertexAILLM(ctx context.Context, cfg *config.Config) (models.ZepLLM, error) {
+	zllm := &ZepLLM{
+		llm: &ZepVertexAILLM{
+			cfg: cfg,
+		},
+	}
+	err := zllm.Init(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return zllm, nil
+}
+
+// ZepVertexAILLM calls Gemini models and text embedding models hosted on Vertex AI
+type ZepVertexAILLM struct {
+	modelsURL      string
+	model          string
+	embeddingModel string
+	tokenSource    *googleTokenSource
+	httpClient     *http.Client
+	cfg            *config.Config
+}
+
+func (zllm *ZepVertexAILLM) Init(_ context.Context, cfg *config.Config) error {
+	vertexCfg := cfg.LLM.VertexAI
+	httpClient := NewRetryableHTTPClient(MaxVertexAIAPIRequestAttempts, VertexAIAPITimeout)
+
+	tokenSource, credentialsProject, err := findGoogleCredentials(
+		vertexCfg.CredentialsFile,
+		httpClient,
+	)
+	if err != nil {
+		return err
+	}
+
+	project := vertexCfg.Project
+	if project == "" {
+		project = credentialsProject
+	}
+	if project == "" {
+		return errors.New("llm.vertexai.project must be set")
+	}
+
+	location := vertexCfg.Location
+	if location == "" {
+		location = DefaultVertexAILocation
+	}
+	endpoint := vertexCfg.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s-aiplatform.googleapis.com", location)
+	}
+
+	zllm.modelsURL = fmt.Sprintf(
+		"%s/v1/projects/%s/locations/%s/publishers/google/models",
+		strings.TrimSuffix(endpoint, "/"),
+		project,
+		location,
+	)
+	zllm.model = cfg.LLM.Model
+	zllm.embeddingModel = vertexCfg.EmbeddingModel
+	if zllm.embeddingModel == "" {
+		zllm.embeddingModel = DefaultVertexAIEmbeddingModel
+	}
+	zllm.tokenSource = tokenSource
+	zllm.httpClient = httpClient
+
+	return nil
+}
+
+type vertexAIPart struct {
+	Text string `json:"text"`
+}
+
+type vertexAIContent struct {
+	Role  string         `json:"role,omitempty"`
+	Parts []vertexAIPart `json:"parts"`
+}
+
+type vertexAIGenerationConfig struct {
+	Temperature     float64  `json:"temperature"`
+	MaxOutputTokens int      `json:"maxOutputTokens,omitempty"`
+	StopSequences   []string `json:"stopSequences,omitempty"`
+}
+
+type vertexAIGenerateRequest struct {
+	Contents         []vertexAIContent        `json:"contents"`
+	GenerationConfig vertexAIGenerationConfig `json:"generationConfig"`
+}
+
+type vertexAIGenerateResponse struct {
+	Candidates []struct {
+		Content      vertexAIContent `json:"content"`
+		FinishReason string          `json:"finishReason"`
+	} `json:"candidates"`
+	PromptFeedback struct {
+		BlockReason string `json:"blockReason"`
+	} `json:"promptFeedback"`
+}
+
+type vertexAIEmbedRequest struct {
+	Instances []vertexAIEmbedInstance `json:"instances"`
+}
+
+type vertexAIEmbedInstance struct {
+	Content string `json:"content"`
+}
+
+type vertexAIEmbedResponse struct {
+	Predictions []struct {
+		Embeddings struct {
+			Values []float32 `json:"values"`
+		} `json:"embeddings"`
+	} `json:"predictions"`
+}
+
+type vertexAIErrorResponse struct {
+	Error struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (zllm *ZepVertexAILLM) Call(ctx context.Context,
+	prompt string,
+	options ...llms.CallOption,
+) (string, error) {
+	// If the LLM is not initialized, return an error
+	if zllm.httpClient == nil || zllm.model == "" {
+		return "", NewLLMError(InvalidLLMModelError, nil)
+	}
+
+	if len(options) == 0 {
+		options = append(options, llms.WithTemperature(DefaultTemperature))
+	}
+
+	callOptions := llms.CallOptions{}
+	for _, opt := range options {
+		opt(&callOptions)
+	}
+
+	model := zllm.model
+	if callOptions.Model != "" {
+		model = callOptions.Model
+	}
+
+	request := vertexAIGenerateRequest{
+		Contents: []vertexAIContent{
+			{Role: "user", Parts: []vertexAIPart{{Text: prompt}}},
+		},
+		GenerationConfig: vertexAIGenerationConfig{
+			Temperature:     callOptions.Temperature,
+			MaxOutputTokens: callOptions.MaxTokens,
+			StopSequences:   callOptions.StopWords,
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, VertexAICallTimeout)
+	defer cancel()
+
+	var response vertexAIGenerateResponse
+	err := zllm.post(ctx, model+":generateContent", request, &response)
+	if err != nil {
+		return "", err
+	}
+
+	if response.PromptFeedback.BlockReason != "" {
+		return "", fmt.Errorf(
+			"vertex ai blocked the prompt: %s",
+			response.PromptFeedback.BlockReason,
+		)
+	}
+	if len(response.Candidates) == 0 {
+		return "", errors.New("vertex ai returned no candidates")
+	}
+	candidate := response.Candidates[0]
+	if candidate.FinishReason == "SAFETY" {
+		return "", errors.New("vertex ai blocked the response for safety reasons")
+	}
+
+	var completion strings.Builder
+	for _, part := range candidate.Content.Parts {
+		completion.WriteString(part.Text)
+	}
+
+	return completion.String(), nil
+}
+
+func (zllm *ZepVertexAILLM) EmbedTexts(ctx context.Context, texts []string) ([][]float32, error) {
+	// If the LLM is not initialized, return an error
+	if zllm.httpClient == nil {
+		return nil, NewLLMError(InvalidLLMModelError, nil)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, VertexAICallTimeout)
+	defer cancel()
+
+	embeddings := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += VertexAIEmbeddingBatchSize {
+		end := start + VertexAIEmbeddingBatchSize
+		if end > len(texts) {
+			end = len(texts)
+		}
+
+		request := vertexAIEmbedRequest{
+			Instances: make([]vertexAIEmbedInstance, 0, end-start),
+		}
+		for _, text := range texts[start:end] {
+			request.Instances = append(request.Instances, vertexAIEmbedInstance{Content: text})
+		}
+
+		var response vertexAIEmbedResponse
+		err := zllm.post(ctx, zllm.embeddingModel+":predict", request, &response)
+		if err != nil {
+			return nil, err
+		}
+		if len(response.Predictions) != len(request.Instances) {
+			return nil, fmt.Errorf(
+				"vertex ai returned %d embeddings for %d texts",
+				len(response.Predictions),
+				len(request.Instances),
+			)
+		}
+
+		for _, prediction := range response.Predictions {
+			embeddings = append(embeddings, prediction.Embeddings.Values)
+		}
+	}
+
+	return embeddings, nil
+}
+
+// GetTokenCount returns an estimate of the number of tokens in the text. Gemini's
+// tokenizer is only available using the countTokens API, which is too slow to call for
+// every message.
+func (zllm *ZepVertexAILLM) GetTokenCount(text string) (int, error) {
+	return estimateTokenCount(text), nil
+}
+
+// post sends an authenticated request to a model method, e.g. gemini-pro:generateContent,
+// and unmarshals the response into response
+func (zllm *ZepVertexAILLM) post(
+	ctx context.Context,
+	modelMethod string,
+	request interface{},
+	response interface{},
+) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal vertex ai request: %w", err)
+	}
+
+	token, err := zllm.tokenSource.Token(ctx)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		zllm.modelsURL+"/"+modelMethod,
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create vertex ai request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := zllm.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read vertex ai response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp vertexAIErrorResponse
+		if err := json.Unmarshal(respBody, &errResp); err != nil || errResp.Error.Message == "" {
+			return fmt.Errorf("vertex ai returned status %d: %s", resp.StatusCode, respBody)
+		}
+		return fmt.Errorf(
+			"vertex ai returned status %d: %s: %s",
+			resp.StatusCode,
+			errResp.Error.Status,
+			errResp.Error.Message,
+		)
+	}
+
+	if err := json.Unmarshal(respBody, response); err != nil {
+		return fmt.Errorf("failed to unmarshal vertex ai response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,237 @@
+package llms
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/getzep/zep/config"
+)
+
+const testVertexAIModelsPath = "/v1/projects/test-project/locations/us-central1/" +
+	"publishers/google/models/"
+
+// newVertexAITestServer returns a server that issues access tokens for a service account
+// and handles Vertex AI model requests with handler. The service account credentials
+// file is written to a temporary directory.
+func newVertexAITestServer(
+	t *testing.T,
+	handler http.HandlerFunc,
+) (server *httptest.Server, credentialsFile string, tokenRequests *int) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	tokenRequests = new(int)
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/token" {
+			assert.Equal(t, "Bearer test-access-token", r.Header.Get("Authorization"))
+			handler(w, r)
+			return
+		}
+
+		*tokenRequests++
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "urn:ietf:params:oauth:grant-type:jwt-bearer", r.Form.Get("grant_type"))
+		claims := jwt.MapClaims{}
+		_, err := jwt.ParseWithClaims(
+			r.Form.Get("assertion"),
+			claims,
+			func(token *jwt.Token) (interface{}, error) {
+				assert.Equal(t, "test-key-id", token.Header["kid"])
+				return &key.PublicKey, nil
+			},
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, "zep@test-project.iam.gserviceaccount.com", claims["iss"])
+		assert.Equal(t, googleCloudPlatformScope, claims["scope"])
+
+		_, _ = w.Write([]byte(`{"access_token":"test-access-token","expires_in":3599}`))
+	}))
+	t.Cleanup(server.Close)
+
+	credentials, err := json.Marshal(googleCredentialsFile{
+		Type:         "service_account",
+		ProjectID:    "test-project",
+		PrivateKeyID: "test-key-id",
+		PrivateKey: string(pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(key),
+		})),
+		ClientEmail: "zep@test-project.iam.gserviceaccount.com",
+		TokenURI:    server.URL + "/token",
+	})
+	require.NoError(t, err)
+	credentialsFile = filepath.Join(t.TempDir(), "credentials.json")
+	require.NoError(t, os.WriteFile(credentialsFile, credentials, 0o600))
+
+	return server, credentialsFile, tokenRequests
+}
+
+func newTestVertexAIConfig(serverURL, credentialsFile string) *config.Config {
+	return &config.Config{
+		LLM: config.LLM{
+			Service: "vertexai",
+			Model:   "gemini-pro",
+			VertexAI: config.VertexAIConfig{
+				CredentialsFile: credentialsFile,
+				Endpoint:        serverURL,
+			},
+		},
+	}
+}
+
+func TestZepVertexAILLM_Call(t *testing.T) {
+	var path string
+	var request map[string]interface{}
+	server, credentialsFile, tokenRequests := newVertexAITestServer(
+		t,
+		func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			_, _ = w.Write([]byte(`{"candidates":[{"content":{"role":"model",` +
+				`"parts":[{"text":"Hello"},{"text":"!"}]},"finishReason":"STOP"}]}`))
+		},
+	)
+
+	zllm, err := NewLLMClient(
+		context.Background(),
+		newTestVertexAIConfig(server.URL, credentialsFile),
+	)
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		result, err := zllm.Call(context.Background(), "Hi", llms.WithMaxTokens(50))
+		assert.NoError(t, err)
+		assert.Equal(t, "Hello!", result)
+	}
+
+	// the access token is cached
+	assert.Equal(t, 1, *tokenRequests)
+	assert.Equal(t, testVertexAIModelsPath+"gemini-pro:generateContent", path)
+	assert.Equal(t, map[string]interface{}{
+		"contents": []interface{}{
+			map[string]interface{}{
+				"role":  "user",
+				"parts": []interface{}{map[string]interface{}{"text": "Hi"}},
+			},
+		},
+		"generationConfig": map[string]interface{}{
+			"temperature":     float64(0),
+			"maxOutputTokens": float64(50),
+		},
+	}, request)
+}
+
+func TestZepVertexAILLM_CallBlocked(t *testing.T) {
+	server, credentialsFile, _ := newVertexAITestServer(
+		t,
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"promptFeedback":{"blockReason":"SAFETY"}}`))
+		},
+	)
+
+	zllm, err := NewVertexAILLM(
+		context.Background(),
+		newTestVertexAIConfig(server.URL, credentialsFile),
+	)
+	require.NoError(t, err)
+
+	_, err = zllm.Call(context.Background(), "Hi")
+	assert.ErrorContains(t, err, "blocked the prompt: SAFETY")
+}
+
+func TestZepVertexAILLM_CallError(t *testing.T) {
+	server, credentialsFile, _ := newVertexAITestServer(
+		t,
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":404,"status":"NOT_FOUND",` +
+				`"message":"Publisher Model gemini-pro was not found."}}`))
+		},
+	)
+
+	zllm, err := NewVertexAILLM(
+		context.Background(),
+		newTestVertexAIConfig(server.URL, credentialsFile),
+	)
+	require.NoError(t, err)
+
+	_, err = zllm.Call(context.Background(), "Hi")
+	assert.ErrorContains(t, err, "NOT_FOUND: Publisher Model gemini-pro was not found.")
+}
+
+func TestZepVertexAILLM_EmbedTexts(t *testing.T) {
+	var batchSizes []int
+	server, credentialsFile, _ := newVertexAITestServer(
+		t,
+		func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(
+				t,
+				testVertexAIModelsPath+DefaultVertexAIEmbeddingModel+":predict",
+				r.URL.Path,
+			)
+			var request vertexAIEmbedRequest
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			batchSizes = append(batchSizes, len(request.Instances))
+
+			predictions := make([]string, len(request.Instances))
+			for i, instance := range request.Instances {
+				predictions[i] = `{"embeddings":{"values":[` +
+					strings.TrimPrefix(instance.Content, "text ") + `,0.5]}}`
+			}
+			_, _ = w.Write(
+				[]byte(`{"predictions":[` + strings.Join(predictions, ",") + `]}`),
+			)
+		},
+	)
+
+	zllm, err := NewVertexAILLM(
+		context.Background(),
+		newTestVertexAIConfig(server.URL, credentialsFile),
+	)
+	require.NoError(t, err)
+
+	texts := []string{"text 1", "text 2", "text 3", "text 4", "text 5", "text 6", "text 7"}
+	embeddings, err := zllm.EmbedTexts(context.Background(), texts)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []int{5, 2}, batchSizes)
+	require.Len(t, embeddings, len(texts))
+	for i, embedding := range embeddings {
+		assert.Equal(t, []float32{float32(i + 1), 0.5}, embedding)
+	}
+}
+
+func TestZepVertexAILLM_InitProject(t *testing.T) {
+	server, credentialsFile, _ := newVertexAITestServer(t, nil)
+
+	cfg := newTestVertexAIConfig(server.URL, credentialsFile)
+	cfg.LLM.VertexAI.Project = "other-project"
+	cfg.LLM.VertexAI.Location = "europe-west4"
+
+	zllm := &ZepVertexAILLM{}
+	require.NoError(t, zllm.Init(context.Background(), cfg))
+	assert.Equal(
+		t,
+		server.URL+"/v1/projects/other-project/locations/europe-west4/publishers/google/models",
+		zllm.modelsURL,
+	)
+
+	cfg.LLM.Model = "gemini-ultra"
+	_, err := NewLLMClient(context.Background(), cfg)
+	assert.ErrorContains(t, err, "invalid llm model \"gemini-ultra\" for vertexai")
+}
@@ -0,0 +1,71 @@
+package llms
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/getzep/zep/pkg/models"
+)
+
+// DefaultModerationThreshold is the category score at which messages are flagged if
+// extractors.messages.moderation.threshold isn't set
+const DefaultModerationThreshold = 0.5
+
+// NewModerator returns the Moderator of the configured moderation service
+func NewModerator(appState *models.AppState) (models.Moderator, error) {
+	cfg := appState.Config
+	switch cfg.Extractors.Messages.Moderation.Service {
+	case "openai", "":
+		return &openAIModerator{cfg: cfg}, nil
+	case "local":
+		return &localModerator{serverURL: cfg.NLP.ServerURL}, nil
+	default:
+		return nil, fmt.Errorf(
+			"invalid moderation service: %s",
+			cfg.Extractors.Messages.Moderation.Service,
+		)
+	}
+}
+
+// ModerateTexts moderates texts with the configured moderation service, flagging those
+// scoring at least the configured threshold in any category
+func ModerateTexts(
+	ctx context.Context,
+	appState *models.AppState,
+	texts []string,
+) ([]models.Moderation, error) {
+	moderator, err := NewModerator(appState)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := moderator.Moderate(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) != len(texts) {
+		return nil, fmt.Errorf(
+			"moderation service returned %d results for %d texts",
+			len(results),
+			len(texts),
+		)
+	}
+
+	threshold := appState.Config.Extractors.Messages.Moderation.Threshold
+	if threshold <= 0 {
+		threshold = DefaultModerationThreshold
+	}
+
+	moderations := make([]models.Moderation, len(results))
+	for i, r := range results {
+		category, score := r.MaxScore()
+		moderations[i] = models.Moderation{
+			Flagged:        score >= threshold,
+			Category:       category,
+			Score:          score,
+			CategoryScores: r.CategoryScores,
+		}
+	}
+
+	return moderations, nil
+}
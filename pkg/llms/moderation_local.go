@@ -0,0 +1,52 @@
+package llms
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/getzep/zep/pkg/models"
+)
+
+type localModerationRequest struct {
+	Texts []string `json:"texts"`
+}
+
+type localModerationResponse struct {
+	Results []models.ModerationResult `json:"results"`
+}
+
+var _ models.Moderator = &localModerator{}
+
+// localModerator moderates texts using the NLP server's /moderation endpoint
+type localModerator struct {
+	serverURL string
+}
+
+func (m *localModerator) Moderate(
+	ctx context.Context,
+	texts []string,
+) ([]models.ModerationResult, error) {
+	if len(texts) == 0 {
+		return []models.ModerationResult{}, nil
+	}
+
+	jsonBody, err := json.Marshal(localModerationRequest{Texts: texts})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal moderation request: %w", err)
+	}
+
+	url := strings.TrimSuffix(m.serverURL, "/") + "/moderation"
+	bodyBytes, err := makeEmbedRequest(ctx, url, jsonBody)
+	if err != nil {
+		return nil, err
+	}
+
+	var response localModerationResponse
+	if err := json.Unmarshal(bodyBytes, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal moderation response: %w", err)
+	}
+
+	return response.Results, nil
+}
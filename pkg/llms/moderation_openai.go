@@ -0,0 +1,101 @@
+package llms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+)
+
+const DefaultOpenAIEndpoint = "https://api.openai.com/v1"
+const OpenAIModerationTimeout = 30 * time.Second
+const MaxOpenAIModerationRequestAttempts = 5
+
+type openAIModerationRequest struct {
+	Input []string `json:"input"`
+}
+
+type openAIModerationResponse struct {
+	Results []models.ModerationResult `json:"results"`
+}
+
+var _ models.Moderator = &openAIModerator{}
+
+// openAIModerator moderates texts using the OpenAI moderation API. llm.openai_endpoint
+// overrides the API endpoint.
+type openAIModerator struct {
+	cfg *config.Config
+}
+
+func (m *openAIModerator) Moderate(
+	ctx context.Context,
+	texts []string,
+) ([]models.ModerationResult, error) {
+	if len(texts) == 0 {
+		return []models.ModerationResult{}, nil
+	}
+
+	if m.cfg.LLM.OpenAIAPIKey == "" {
+		return nil, errors.New(OpenAIAPIKeyNotSetError)
+	}
+	endpoint := m.cfg.LLM.OpenAIEndpoint
+	if endpoint == "" {
+		endpoint = DefaultOpenAIEndpoint
+	}
+	url := strings.TrimSuffix(endpoint, "/") + "/moderations"
+
+	body, err := json.Marshal(openAIModerationRequest{Input: texts})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal moderation request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, OpenAIModerationTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create moderation request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+m.cfg.LLM.OpenAIAPIKey)
+	if m.cfg.LLM.OpenAIOrgID != "" {
+		req.Header.Set("OpenAI-Organization", m.cfg.LLM.OpenAIOrgID)
+	}
+
+	httpClient := NewRetryableHTTPClient(
+		MaxOpenAIModerationRequestAttempts,
+		OpenAIModerationTimeout,
+	)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read moderation response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(
+			"openai moderation request failed with status %d: %s",
+			resp.StatusCode,
+			respBody,
+		)
+	}
+
+	var moderationResp openAIModerationResponse
+	if err := json.Unmarshal(respBody, &moderationResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal moderation response: %w", err)
+	}
+
+	return moderationResp.Results, nil
+}
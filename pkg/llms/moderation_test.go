@@ -0,0 +1,105 @@
+package llms
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+)
+
+func newModerationTestAppState(moderation config.ModerationConfig, url string) *models.AppState {
+	return &models.AppState{
+		Config: &config.Config{
+			LLM: config.LLM{OpenAIAPIKey: "test-key", OpenAIEndpoint: url},
+			NLP: config.NLP{ServerURL: url},
+			Extractors: config.ExtractorsConfig{
+				Messages: config.MessageExtractorsConfig{Moderation: moderation},
+			},
+		},
+	}
+}
+
+func TestOpenAIModerator(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/moderations", r.URL.Path)
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+
+		var request openAIModerationRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, []string{"text 1", "text 2"}, request.Input)
+
+		_, _ = w.Write([]byte(`{"results":[` +
+			`{"flagged":false,"category_scores":{"hate":0.1,"violence":0.2}},` +
+			`{"flagged":true,"category_scores":{"hate":0.9,"violence":0.3}}]}`))
+	}))
+	defer server.Close()
+
+	appState := newModerationTestAppState(config.ModerationConfig{Service: "openai"}, server.URL)
+	moderations, err := ModerateTexts(context.Background(), appState, []string{"text 1", "text 2"})
+	require.NoError(t, err)
+	assert.Equal(t, []models.Moderation{
+		{
+			Category:       "violence",
+			Score:          0.2,
+			CategoryScores: map[string]float64{"hate": 0.1, "violence": 0.2},
+		},
+		{
+			Flagged:        true,
+			Category:       "hate",
+			Score:          0.9,
+			CategoryScores: map[string]float64{"hate": 0.9, "violence": 0.3},
+		},
+	}, moderations)
+}
+
+func TestLocalModerator(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/moderation", r.URL.Path)
+
+		var request localModerationRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, []string{"text"}, request.Texts)
+
+		assert.NoError(t, json.NewEncoder(w).Encode(localModerationResponse{
+			Results: []models.ModerationResult{
+				{CategoryScores: map[string]float64{"toxicity": 0.4}},
+			},
+		}))
+	}))
+	defer server.Close()
+
+	// the threshold is configurable
+	appState := newModerationTestAppState(
+		config.ModerationConfig{Service: "local", Threshold: 0.3},
+		server.URL,
+	)
+	moderations, err := ModerateTexts(context.Background(), appState, []string{"text"})
+	require.NoError(t, err)
+	require.Len(t, moderations, 1)
+	assert.True(t, moderations[0].Flagged)
+	assert.Equal(t, "toxicity", moderations[0].Category)
+}
+
+func TestModerateTexts_ResultCountMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"results":[]}`))
+	}))
+	defer server.Close()
+
+	appState := newModerationTestAppState(config.ModerationConfig{Service: "local"}, server.URL)
+	_, err := ModerateTexts(context.Background(), appState, []string{"text"})
+	assert.ErrorContains(t, err, "returned 0 results for 1 texts")
+}
+
+func TestNewModerator_InvalidService(t *testing.T) {
+	appState := newModerationTestAppState(config.ModerationConfig{Service: "acme"}, "")
+	_, err := NewModerator(appState)
+	assert.ErrorContains(t, err, "invalid moderation service")
+}
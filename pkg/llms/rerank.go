@@ -0,0 +1,26 @@
+package llms
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/getzep/zep/pkg/models"
+)
+
+// NewReranker returns the Reranker of the configured rerank service
+func NewReranker(appState *models.AppState) (models.Reranker, error) {
+	cfg := appState.Config
+	switch cfg.Rerank.Service {
+	case "local":
+		if cfg.Rerank.ServerURL == "" {
+			return nil, errors.New("rerank.server_url must be set for the local rerank service")
+		}
+		return &localReranker{serverURL: cfg.Rerank.ServerURL}, nil
+	case "cohere":
+		return &cohereReranker{cfg: cfg}, nil
+	case "":
+		return nil, models.NewBadRequestError("rerank.service is not configured")
+	default:
+		return nil, fmt.Errorf("invalid rerank service: %s", cfg.Rerank.Service)
+	}
+}
@@ -0,0 +1,111 @@
+package llms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+)
+
+const DefaultCohereRerankModel = "rerank-english-v2.0"
+
+type cohereRerankRequest struct {
+	Model     string   `json:"model"`
+	Query     string   `json:"query"`
+	Documents []string `json:"documents"`
+}
+
+type cohereRerankResponse struct {
+	Results []struct {
+		Index          int     `json:"index"`
+		RelevanceScore float64 `json:"relevance_score"`
+	} `json:"results"`
+}
+
+var _ models.Reranker = &cohereReranker{}
+
+// cohereReranker reranks texts using the Cohere Rerank API. The model is set by
+// llm.cohere.rerank_model.
+type cohereReranker struct {
+	cfg *config.Config
+}
+
+func (r *cohereReranker) Rerank(
+	ctx context.Context,
+	query string,
+	texts []string,
+) ([]models.RerankResult, error) {
+	if len(texts) == 0 {
+		return []models.RerankResult{}, nil
+	}
+
+	cohereCfg := r.cfg.LLM.Cohere
+	if cohereCfg.APIKey == "" {
+		return nil, errors.New(CohereAPIKeyNotSetError)
+	}
+	model := cohereCfg.RerankModel
+	if model == "" {
+		model = DefaultCohereRerankModel
+	}
+	endpoint := cohereCfg.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultCohereEndpoint
+	}
+	url := strings.TrimSuffix(endpoint, "/") + "/v1/rerank"
+
+	body, err := json.Marshal(cohereRerankRequest{
+		Model:     model,
+		Query:     query,
+		Documents: texts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal cohere request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, CohereAPITimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cohere request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+cohereCfg.APIKey)
+
+	httpClient := NewRetryableHTTPClient(MaxCohereAPIRequestAttempts, CohereAPITimeout)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cohere response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, cohereResponseError(resp.StatusCode, respBody)
+	}
+
+	var rerankResp cohereRerankResponse
+	if err := json.Unmarshal(respBody, &rerankResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cohere response: %w", err)
+	}
+
+	results := make([]models.RerankResult, 0, len(rerankResp.Results))
+	for _, cr := range rerankResp.Results {
+		if cr.Index < 0 || cr.Index >= len(texts) {
+			return nil, fmt.Errorf("cohere returned invalid index %d", cr.Index)
+		}
+		results = append(results, models.RerankResult{Index: cr.Index, Score: cr.RelevanceScore})
+	}
+
+	return results, nil
+}
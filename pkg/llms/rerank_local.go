@@ -0,0 +1,74 @@
+package llms
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/getzep/zep/pkg/models"
+)
+
+type localRerankRequest struct {
+	Query    string   `json:"query"`
+	Texts    []string `json:"texts"`
+	Truncate bool     `json:"truncate"`
+}
+
+type localRerankResult struct {
+	Index int     `json:"index"`
+	Score float64 `json:"score"`
+}
+
+var _ models.Reranker = &localReranker{}
+
+// localReranker reranks texts using a self-hosted rerank server's /rerank endpoint, such as
+// text-embeddings-inference serving a cross-encoder model
+type localReranker struct {
+	serverURL string
+}
+
+func (r *localReranker) Rerank(
+	ctx context.Context,
+	query string,
+	texts []string,
+) ([]models.RerankResult, error) {
+	if len(texts) == 0 {
+		return []models.RerankResult{}, nil
+	}
+
+	jsonBody, err := json.Marshal(localRerankRequest{
+		Query: query,
+		Texts: texts,
+		// truncate texts longer than the model's maximum rather than failing
+		Truncate: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal rerank request: %w", err)
+	}
+
+	url := strings.TrimSuffix(r.serverURL, "/") + "/rerank"
+	bodyBytes, err := makeEmbedRequest(ctx, url, jsonBody)
+	if err != nil {
+		return nil, err
+	}
+
+	var localResults []localRerankResult
+	if err := json.Unmarshal(bodyBytes, &localResults); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal rerank response: %w", err)
+	}
+
+	results := make([]models.RerankResult, 0, len(localResults))
+	for _, lr := range localResults {
+		if lr.Index < 0 || lr.Index >= len(texts) {
+			return nil, fmt.Errorf("rerank server returned invalid index %d", lr.Index)
+		}
+		results = append(results, models.RerankResult{Index: lr.Index, Score: lr.Score})
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	return results, nil
+}
@@ -0,0 +1,82 @@
+package llms
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+)
+
+func TestLocalReranker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/rerank", r.URL.Path)
+
+		var request localRerankRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "query", request.Query)
+		assert.Equal(t, []string{"text 1", "text 2"}, request.Texts)
+
+		assert.NoError(t, json.NewEncoder(w).Encode([]localRerankResult{
+			{Index: 0, Score: 0.1},
+			{Index: 1, Score: 0.9},
+		}))
+	}))
+	defer server.Close()
+
+	appState := &models.AppState{
+		Config: &config.Config{
+			Rerank: config.RerankConfig{Service: "local", ServerURL: server.URL},
+		},
+	}
+	reranker, err := NewReranker(appState)
+	require.NoError(t, err)
+
+	results, err := reranker.Rerank(context.Background(), "query", []string{"text 1", "text 2"})
+	require.NoError(t, err)
+	assert.Equal(t, []models.RerankResult{{Index: 1, Score: 0.9}, {Index: 0, Score: 0.1}}, results)
+}
+
+func TestCohereReranker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/rerank", r.URL.Path)
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+
+		var request cohereRerankRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, cohereRerankRequest{
+			Model:     DefaultCohereRerankModel,
+			Query:     "query",
+			Documents: []string{"text 1", "text 2"},
+		}, request)
+
+		_, _ = w.Write([]byte(`{"results":[` +
+			`{"index":1,"relevance_score":0.8},{"index":0,"relevance_score":0.2}]}`))
+	}))
+	defer server.Close()
+
+	appState := newCohereTestAppState(server.URL)
+	appState.Config.Rerank.Service = "cohere"
+	reranker, err := NewReranker(appState)
+	require.NoError(t, err)
+
+	results, err := reranker.Rerank(context.Background(), "query", []string{"text 1", "text 2"})
+	require.NoError(t, err)
+	assert.Equal(t, []models.RerankResult{{Index: 1, Score: 0.8}, {Index: 0, Score: 0.2}}, results)
+}
+
+func TestNewReranker_NotConfigured(t *testing.T) {
+	_, err := NewReranker(&models.AppState{Config: &config.Config{}})
+	assert.ErrorIs(t, err, models.ErrBadRequest)
+
+	_, err = NewReranker(&models.AppState{
+		Config: &config.Config{Rerank: config.RerankConfig{Service: "local"}},
+	})
+	assert.ErrorContains(t, err, "rerank.server_url must be set")
+}
@@ -0,0 +1,87 @@
+package llms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/pkoukk/tiktoken-go"
+
+	"github.com/getzep/zep/config"
+)
+
+// DefaultTiktokenEncoding is the tiktoken encoding of the OpenAI chat models
+const DefaultTiktokenEncoding = "cl100k_base"
+
+// Tokenizer counts the tokens in texts the way a model does
+type Tokenizer interface {
+	// CountTokens returns the number of tokens in the text
+	CountTokens(text string) (int, error)
+}
+
+// NewTokenizer returns the tokenizer configured by llm.tokenizer, one of tiktoken, anthropic
+// or estimate
+func NewTokenizer(cfg *config.Config) (Tokenizer, error) {
+	switch cfg.LLM.Tokenizer {
+	case "tiktoken":
+		return newTiktokenTokenizer(DefaultTiktokenEncoding)
+	case "anthropic":
+		if cfg.LLM.AnthropicAPIKey == "" {
+			return nil, errors.New(AnthropicAPIKeyNotSetError)
+		}
+		return &anthropicTokenizer{client: newAnthropicClient(cfg)}, nil
+	case "estimate":
+		return estimateTokenizer{}, nil
+	default:
+		return nil, fmt.Errorf("invalid tokenizer: %s", cfg.LLM.Tokenizer)
+	}
+}
+
+var _ Tokenizer = &tiktokenTokenizer{}
+
+// tiktokenTokenizer counts tokens using a tiktoken encoding, as OpenAI models do
+type tiktokenTokenizer struct {
+	tkm *tiktoken.Tiktoken
+}
+
+func newTiktokenTokenizer(encoding string) (*tiktokenTokenizer, error) {
+	tkm, err := tiktoken.GetEncoding(encoding)
+	if err != nil {
+		return nil, err
+	}
+	return &tiktokenTokenizer{tkm: tkm}, nil
+}
+
+func (t *tiktokenTokenizer) CountTokens(text string) (int, error) {
+	return len(t.tkm.Encode(text, nil, nil)), nil
+}
+
+var _ Tokenizer = &anthropicTokenizer{}
+
+// anthropicTokenizer counts tokens using the Anthropic Token Counting API, as Claude's
+// tokenizer isn't available offline. Counts include the few tokens framing a user message.
+type anthropicTokenizer struct {
+	client *anthropicClient
+}
+
+func (t *anthropicTokenizer) CountTokens(text string) (int, error) {
+	// the API rejects empty messages
+	if text == "" {
+		return 0, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), AnthropicAPITimeout)
+	defer cancel()
+
+	return t.client.countTokens(ctx, text)
+}
+
+var _ Tokenizer = estimateTokenizer{}
+
+// estimateTokenizer estimates token counts from the length of texts, for models whose
+// tokenizer is unknown
+type estimateTokenizer struct{}
+
+func (estimateTokenizer) CountTokens(text string) (int, error) {
+	return estimateTokenCount(text), nil
+}
@@ -0,0 +1,41 @@
+package llms
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/config"
+)
+
+func TestNewTokenizer(t *testing.T) {
+	tokenizer, err := NewTokenizer(&config.Config{LLM: config.LLM{Tokenizer: "estimate"}})
+	require.NoError(t, err)
+	count, err := tokenizer.CountTokens("Hello, world!")
+	assert.NoError(t, err)
+	assert.Equal(t, 4, count)
+
+	_, err = NewTokenizer(&config.Config{LLM: config.LLM{Tokenizer: "anthropic"}})
+	assert.ErrorContains(t, err, AnthropicAPIKeyNotSetError)
+
+	_, err = NewTokenizer(&config.Config{LLM: config.LLM{Tokenizer: "sentencepiece"}})
+	assert.ErrorContains(t, err, "invalid tokenizer: sentencepiece")
+}
+
+func TestZepLLM_TokenizerOverride(t *testing.T) {
+	cfg := &config.Config{
+		LLM: config.LLM{
+			Service:   "ollama",
+			Model:     "llama2",
+			Tokenizer: "estimate",
+		},
+	}
+	zllm, err := NewOllamaLLM(context.Background(), cfg)
+	require.NoError(t, err)
+
+	z, ok := zllm.(*ZepLLM)
+	require.True(t, ok, "Expected ZepLLM")
+	assert.Equal(t, estimateTokenizer{}, z.tokenizer)
+}
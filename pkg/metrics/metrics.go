@@ -0,0 +1,283 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are the upper bounds, in seconds, of the buckets of latency histograms
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// collector is a metric family written in the Prometheus text format
+type collector interface {
+	name() string
+	write(w io.Writer)
+}
+
+// Registry holds metrics and writes them in the Prometheus text exposition format
+type Registry struct {
+	mu         sync.Mutex
+	collectors []collector
+}
+
+// DefaultRegistry holds the metrics created with the New functions, and is served by Handler
+var DefaultRegistry = &Registry{}
+
+// register adds a collector, panicking if a metric of the same name is registered, as
+// metrics are registered once when their package is initialized
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.collectors {
+		if existing.name() == c.name() {
+			panic(fmt.Sprintf("metric %s is already registered", c.name()))
+		}
+	}
+	r.collectors = append(r.collectors, c)
+}
+
+// Write writes the metrics of the registry, sorted by name
+func (r *Registry) Write(w io.Writer) {
+	r.mu.Lock()
+	collectors := make([]collector, len(r.collectors))
+	copy(collectors, r.collectors)
+	r.mu.Unlock()
+
+	sort.Slice(collectors, func(i, j int) bool {
+		return collectors[i].name() < collectors[j].name()
+	})
+	for _, c := range collectors {
+		c.write(w)
+	}
+}
+
+// Handler serves the metrics of the DefaultRegistry to Prometheus scrapes
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		DefaultRegistry.Write(w)
+	})
+}
+
+// family holds the series of a metric, keyed by their label values
+type family[T any] struct {
+	metricName string
+	help       string
+	labels     []string
+
+	mu     sync.Mutex
+	series map[string]*T
+	values map[string][]string
+}
+
+func newFamily[T any](name, help string, labels []string) family[T] {
+	return family[T]{
+		metricName: name,
+		help:       help,
+		labels:     labels,
+		series:     make(map[string]*T),
+		values:     make(map[string][]string),
+	}
+}
+
+func (f *family[T]) name() string {
+	return f.metricName
+}
+
+// get returns the series of the label values, creating it with create. f.mu must be held.
+func (f *family[T]) get(labelValues []string, create func() *T) *T {
+	if len(labelValues) != len(f.labels) {
+		panic(fmt.Sprintf(
+			"metric %s has %d labels, got %d values",
+			f.metricName,
+			len(f.labels),
+			len(labelValues),
+		))
+	}
+	key := strings.Join(labelValues, "\xff")
+	s, ok := f.series[key]
+	if !ok {
+		s = create()
+		f.series[key] = s
+		f.values[key] = append([]string(nil), labelValues...)
+	}
+	return s
+}
+
+// sortedKeys returns the keys of the series, sorted. f.mu must be held.
+func (f *family[T]) sortedKeys() []string {
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (f *family[T]) writeHeader(w io.Writer, metricType string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", f.metricName, escapeHelp(f.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.metricName, metricType)
+}
+
+// CounterVec is a counter partitioned by labels
+type CounterVec struct {
+	family[float64]
+}
+
+// NewCounterVec returns a counter registered with the DefaultRegistry
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{family: newFamily[float64](name, help, labels)}
+	DefaultRegistry.register(c)
+	return c
+}
+
+// Inc adds 1 to the counter of the label values
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds value, which must not be negative, to the counter of the label values
+func (c *CounterVec) Add(value float64, labelValues ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	*c.get(labelValues, func() *float64 { return new(float64) }) += value
+}
+
+func (c *CounterVec) write(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.writeHeader(w, "counter")
+	for _, key := range c.sortedKeys() {
+		writeSample(w, c.metricName, c.labels, c.values[key], *c.series[key])
+	}
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// HistogramVec is a histogram partitioned by labels
+type HistogramVec struct {
+	family[histogram]
+	buckets []float64
+}
+
+// NewHistogramVec returns a histogram with the bucket upper bounds, registered with the
+// DefaultRegistry
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{family: newFamily[histogram](name, help, labels), buckets: buckets}
+	DefaultRegistry.register(h)
+	return h
+}
+
+// Observe adds a value to the histogram of the label values
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s := h.get(labelValues, func() *histogram {
+		return &histogram{counts: make([]uint64, len(h.buckets))}
+	})
+	for i, bound := range h.buckets {
+		if value <= bound {
+			s.counts[i]++
+		}
+	}
+	s.sum += value
+	s.count++
+}
+
+func (h *HistogramVec) write(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.writeHeader(w, "histogram")
+	labels := append(append([]string(nil), h.labels...), "le")
+	for _, key := range h.sortedKeys() {
+		s, values := h.series[key], h.values[key]
+		for i, bound := range h.buckets {
+			bucketValues := append(append([]string(nil), values...), formatValue(bound))
+			writeSample(w, h.metricName+"_bucket", labels, bucketValues, float64(s.counts[i]))
+		}
+		infValues := append(append([]string(nil), values...), "+Inf")
+		writeSample(w, h.metricName+"_bucket", labels, infValues, float64(s.count))
+		writeSample(w, h.metricName+"_sum", h.labels, values, s.sum)
+		writeSample(w, h.metricName+"_count", h.labels, values, float64(s.count))
+	}
+}
+
+// GaugeSample is a value of a gauge collected by a GaugeFunc
+type GaugeSample struct {
+	LabelValues []string
+	Value       float64
+}
+
+// GaugeFunc is a gauge whose values are collected when the metrics are written
+type GaugeFunc struct {
+	family[struct{}]
+	collect func() []GaugeSample
+}
+
+// NewGaugeFunc returns a gauge whose values are collected by collect, registered with the
+// DefaultRegistry
+func NewGaugeFunc(name, help string, labels []string, collect func() []GaugeSample) *GaugeFunc {
+	g := &GaugeFunc{family: newFamily[struct{}](name, help, labels), collect: collect}
+	DefaultRegistry.register(g)
+	return g
+}
+
+func (g *GaugeFunc) write(w io.Writer) {
+	samples := g.collect()
+	sort.Slice(samples, func(i, j int) bool {
+		return strings.Join(samples[i].LabelValues, "\xff") <
+			strings.Join(samples[j].LabelValues, "\xff")
+	})
+	g.writeHeader(w, "gauge")
+	for _, sample := range samples {
+		writeSample(w, g.metricName, g.labels, sample.LabelValues, sample.Value)
+	}
+}
+
+func writeSample(w io.Writer, name string, labels, values []string, value float64) {
+	var b strings.Builder
+	b.WriteString(name)
+	if len(labels) > 0 {
+		b.WriteByte('{')
+		for i, label := range labels {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			fmt.Fprintf(&b, `%s="%s"`, label, labelValueReplacer.Replace(values[i]))
+		}
+		b.WriteByte('}')
+	}
+	b.WriteByte(' ')
+	b.WriteString(formatValue(value))
+	b.WriteByte('\n')
+	_, _ = io.WriteString(w, b.String())
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+var (
+	helpReplacer       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+)
+
+func escapeHelp(help string) string {
+	return helpReplacer.Replace(help)
+}
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	counter := NewCounterVec("test_requests_total", "Test requests", "route", "status")
+	counter.Inc("/a", "200")
+	counter.Add(2, "/a", "200")
+	counter.Inc(`/b"\`, "500")
+
+	histogram := NewHistogramVec("test_duration_seconds", "Test\nlatency", []float64{0.1, 1}, "route")
+	histogram.Observe(0.05, "/a")
+	histogram.Observe(0.5, "/a")
+
+	NewGaugeFunc("test_depth", "Test depth", []string{"topic"}, func() []GaugeSample {
+		return []GaugeSample{
+			{LabelValues: []string{"b"}, Value: 2},
+			{LabelValues: []string{"a"}, Value: 1},
+		}
+	})
+
+	rr := httptest.NewRecorder()
+	Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.True(t, strings.HasPrefix(rr.Header().Get("Content-Type"), "text/plain; version=0.0.4"))
+
+	assert.Equal(t, `# HELP test_depth Test depth
+# TYPE test_depth gauge
+test_depth{topic="a"} 1
+test_depth{topic="b"} 2
+# HELP test_duration_seconds Test\nlatency
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{route="/a",le="0.1"} 1
+test_duration_seconds_bucket{route="/a",le="1"} 2
+test_duration_seconds_bucket{route="/a",le="+Inf"} 2
+test_duration_seconds_sum{route="/a"} 0.55
+test_duration_seconds_count{route="/a"} 2
+# HELP test_requests_total Test requests
+# TYPE test_requests_total counter
+test_requests_total{route="/a",status="200"} 3
+test_requests_total{route="/b\"\\",status="500"} 1
+`, rr.Body.String())
+
+	assert.Panics(t, func() { NewCounterVec("test_requests_total", "Duplicate") })
+	assert.Panics(t, func() { counter.Inc("/a") })
+}
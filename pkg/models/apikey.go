@@ -0,0 +1,92 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKeyPrefix starts every API key, telling API keys apart from JWTs in Authorization headers
+const APIKeyPrefix = "zep_"
+
+// APIKeyScope is the access granted by an API key. Each scope grants the access of the scopes
+// before it.
+type APIKeyScope string
+
+const (
+	// APIKeyScopeRead grants reading and searching a project's records
+	APIKeyScopeRead APIKeyScope = "read"
+	// APIKeyScopeWrite grants writing a project's records
+	APIKeyScopeWrite APIKeyScope = "write"
+	// APIKeyScopeAdmin grants managing a project's API keys, and the admin routes of the
+	// default project
+	APIKeyScopeAdmin APIKeyScope = "admin"
+)
+
+var apiKeyScopeLevels = map[APIKeyScope]int{
+	APIKeyScopeRead:  1,
+	APIKeyScopeWrite: 2,
+	APIKeyScopeAdmin: 3,
+}
+
+// IsValid reports whether s is a known scope
+func (s APIKeyScope) IsValid() bool {
+	_, ok := apiKeyScopeLevels[s]
+	return ok
+}
+
+// Allows reports whether s grants the access of scope
+func (s APIKeyScope) Allows(scope APIKeyScope) bool {
+	return s.IsValid() && apiKeyScopeLevels[s] >= apiKeyScopeLevels[scope]
+}
+
+// APIKey is a managed API key of a project. The key itself is only returned when it's
+// created, and only its hash is stored.
+type APIKey struct {
+	UUID      uuid.UUID   `json:"uuid"`
+	CreatedAt time.Time   `json:"created_at"`
+	ProjectID string      `json:"project_id"`
+	Name      string      `json:"name"`
+	Scope     APIKeyScope `json:"scope"`
+	// Prefix is the start of the key, so that it can be recognized
+	Prefix     string     `json:"prefix"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name  string      `json:"name"`
+	Scope APIKeyScope `json:"scope"`
+}
+
+type CreateAPIKeyResponse struct {
+	APIKey
+	// Key is the API key, which can't be retrieved again
+	Key string `json:"key"`
+}
+
+// APIKeyStore stores the API keys of projects. Keys are created in, and listed and revoked
+// from, the project of the context.
+type APIKeyStore interface {
+	CreateAPIKey(ctx context.Context, request *CreateAPIKeyRequest) (*CreateAPIKeyResponse, error)
+	ListAPIKeys(ctx context.Context) ([]APIKey, error)
+	RevokeAPIKey(ctx context.Context, keyUUID uuid.UUID) error
+	// VerifyAPIKey returns the API key of key and records its use. A not found error is
+	// returned if there's no such key or it's revoked.
+	VerifyAPIKey(ctx context.Context, key string) (*APIKey, error)
+}
+
+type apiKeyKey struct{}
+
+// WithAPIKey adds the API key authenticating a request to ctx
+func WithAPIKey(ctx context.Context, apiKey *APIKey) context.Context {
+	return context.WithValue(ctx, apiKeyKey{}, apiKey)
+}
+
+// APIKeyFromContext returns the API key authenticating the request of ctx, or nil if the
+// request isn't authenticated by an API key
+func APIKeyFromContext(ctx context.Context) *APIKey {
+	apiKey, _ := ctx.Value(apiKeyKey{}).(*APIKey)
+	return apiKey
+}
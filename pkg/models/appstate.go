@@ -1,17 +1,72 @@
 package models
 
 import (
+	"context"
+
 	"github.com/getzep/zep/config"
 )
 
 // AppState is a struct that holds the state of the application
 // Use cmd.NewAppState to create a new instance
 type AppState struct {
-	LLMClient     ZepLLM
-	MemoryStore   MemoryStore[any]
-	DocumentStore DocumentStore[any]
-	UserStore     UserStore
-	TaskRouter    TaskRouter
-	TaskPublisher TaskPublisher
-	Config        *config.Config
+	LLMClient ZepLLM
+	// ExtractorLLMClients are the LLM clients of extractors configured with their own llm,
+	// keyed by extractor
+	ExtractorLLMClients map[string]ZepLLM
+	MemoryStore         MemoryStore[any]
+	DocumentStore       DocumentStore[any]
+	UserStore           UserStore
+	TaskRouter          TaskRouter
+	TaskPublisher       TaskPublisher
+	// DeadLetterStore stores task messages that failed after retries. If nil, they're
+	// published to the poison queue topic instead.
+	DeadLetterStore DeadLetterStore
+	// APIKeyStore stores the managed API keys of projects. If nil, only JWTs authenticate
+	// requests.
+	APIKeyStore APIKeyStore
+	// AuditLogStore records the API requests that access memory. If nil, requests aren't
+	// audited.
+	AuditLogStore AuditLogStore
+	// StatsStore computes the deployment statistics of the admin API. If nil, they're not
+	// available.
+	StatsStore StatsStore
+	// RateLimiter holds the token buckets of the rate limits of the memory and search
+	// endpoints. May be nil, in which case no rate limits are enforced.
+	RateLimiter RateLimiter
+	// SessionEvents delivers session events to stream subscribers. May be nil, in which case
+	// no events are published.
+	SessionEvents SessionEventBroker
+	// EventProducer publishes session events to an external event stream. May be nil, in
+	// which case they're only sent to stream subscribers.
+	EventProducer EventProducer
+	// Webhooks sends memory events to the configured webhooks. May be nil, in which case no
+	// webhooks are configured.
+	Webhooks WebhookNotifier
+	// EmbeddingCache caches the embeddings of texts. May be nil, in which case every text is
+	// embedded.
+	EmbeddingCache EmbeddingCache
+	// ExtractorResultCache caches the results of the intent and entity extractors. May be
+	// nil, in which case every message is extracted.
+	ExtractorResultCache ExtractorResultCache
+	// HealthChecks are the readiness checks of the dependencies Zep needs to serve requests
+	HealthChecks []HealthCheck
+	Config       *config.Config
+}
+
+// PublishSessionEvent publishes an event with the SessionEvents broker and the EventProducer,
+// if there are any
+func (a *AppState) PublishSessionEvent(event *SessionEvent) {
+	if a.SessionEvents != nil {
+		a.SessionEvents.Publish(event)
+	}
+	if a.EventProducer != nil {
+		a.EventProducer.Publish(event)
+	}
+}
+
+// NotifyWebhooks sends an event to the webhooks subscribed to it, if there are any
+func (a *AppState) NotifyWebhooks(ctx context.Context, event *WebhookEvent) {
+	if a.Webhooks != nil {
+		a.Webhooks.Notify(ctx, event)
+	}
 }
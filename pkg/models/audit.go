@@ -0,0 +1,82 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditAction is the kind of access an audit event records
+type AuditAction string
+
+const (
+	AuditActionRead   AuditAction = "read"
+	AuditActionPut    AuditAction = "put"
+	AuditActionDelete AuditAction = "delete"
+	AuditActionSearch AuditAction = "search"
+	AuditActionExport AuditAction = "export"
+)
+
+// AuditActorType is the kind of credential that authenticated an audited request
+type AuditActorType string
+
+const (
+	// AuditActorAPIKey is a managed API key, whose UUID is the actor
+	AuditActorAPIKey AuditActorType = "api_key"
+	// AuditActorUser is a JWT, whose subject, or tenant if it has no subject, is the actor
+	AuditActorUser AuditActorType = "user"
+	// AuditActorAnonymous is a request of a deployment that doesn't require authentication
+	AuditActorAnonymous AuditActorType = "anonymous"
+)
+
+// AuditEvent records an API request that accessed memory: who made it, what it did, and when
+type AuditEvent struct {
+	UUID uuid.UUID `json:"uuid"`
+	// ID is used as a cursor for pagination
+	ID        int64          `json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	ProjectID string         `json:"project_id"`
+	ActorType AuditActorType `json:"actor_type"`
+	Actor     string         `json:"actor"`
+	Action    AuditAction    `json:"action"`
+	Method    string         `json:"method"`
+	// Route is the route pattern of the request, such as /api/v1/sessions/{sessionId}/memory
+	Route string `json:"route"`
+	// SessionID, UserID and CollectionName are the resources named by the request's path
+	SessionID      string `json:"session_id,omitempty"`
+	UserID         string `json:"user_id,omitempty"`
+	CollectionName string `json:"collection_name,omitempty"`
+	StatusCode     int    `json:"status_code"`
+	RequestID      string `json:"request_id,omitempty"`
+}
+
+// AuditEventListRequest filters the audit events listed. Empty filters match all events.
+type AuditEventListRequest struct {
+	ProjectID string
+	Actor     string
+	Action    AuditAction
+	SessionID string
+	// Since and Until bound the creation time of the events, if they aren't zero
+	Since  time.Time
+	Until  time.Time
+	Cursor int64
+	Limit  int
+}
+
+type AuditEventListResponse struct {
+	Events []AuditEvent `json:"events"`
+	// NextCursor is the cursor of the next page, or 0 if this is the last page
+	NextCursor int64 `json:"next_cursor"`
+}
+
+// AuditLogStore is an append-only store of audit events. Events can't be updated or deleted.
+type AuditLogStore interface {
+	PutAuditEvent(ctx context.Context, event *AuditEvent) error
+	// ListAuditEvents returns up to request.Limit events matching request with an ID greater
+	// than request.Cursor, oldest first
+	ListAuditEvents(
+		ctx context.Context,
+		request *AuditEventListRequest,
+	) (*AuditEventListResponse, error)
+}
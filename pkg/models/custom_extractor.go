@@ -0,0 +1,21 @@
+package models
+
+import "github.com/google/uuid"
+
+// CustomExtractorRequest is POSTed to a custom extractor with a session's new messages
+type CustomExtractorRequest struct {
+	Extractor string    `json:"extractor"`
+	SessionID string    `json:"session_id"`
+	Messages  []Message `json:"messages"`
+}
+
+// CustomExtractorResponse is a custom extractor's response. The metadata of each message
+// is merged into the stored message's metadata.
+type CustomExtractorResponse struct {
+	Messages []CustomExtractorResult `json:"messages"`
+}
+
+type CustomExtractorResult struct {
+	UUID     uuid.UUID              `json:"uuid"`
+	Metadata map[string]interface{} `json:"metadata"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeadLetter is a task message whose handler still failed after the task router's retries
+type DeadLetter struct {
+	UUID uuid.UUID `json:"uuid"`
+	// ID is used as a cursor for pagination
+	ID          int64             `json:"id"`
+	CreatedAt   time.Time         `json:"created_at"`
+	MessageUUID string            `json:"message_uuid"`
+	Topic       TaskTopic         `json:"topic"`
+	Handler     string            `json:"handler"`
+	Metadata    map[string]string `json:"metadata"`
+	Payload     json.RawMessage   `json:"payload"`
+	Error       string            `json:"error"`
+}
+
+type DeadLetterListResponse struct {
+	DeadLetters []DeadLetter `json:"dead_letters"`
+	// NextCursor is the cursor of the next page, or 0 if this is the last page
+	NextCursor int64 `json:"next_cursor"`
+}
+
+// DeadLetterStore stores the task messages that failed after retries, so they aren't lost
+// and can be inspected and retried
+type DeadLetterStore interface {
+	PutDeadLetter(ctx context.Context, deadLetter *DeadLetter) error
+	// ListDeadLetters returns up to limit dead letters with an ID greater than cursor,
+	// oldest first. If topic isn't empty, only the dead letters of topic are returned.
+	ListDeadLetters(
+		ctx context.Context,
+		topic TaskTopic,
+		cursor int64,
+		limit int,
+	) (*DeadLetterListResponse, error)
+	GetDeadLetter(ctx context.Context, deadLetterUUID uuid.UUID) (*DeadLetter, error)
+	DeleteDeadLetter(ctx context.Context, deadLetterUUID uuid.UUID) error
+}
@@ -20,6 +20,7 @@ type DocumentCollection struct {
 	Metadata                  map[string]interface{} `bun:"type:jsonb,nullzero,json_use_number"                         yaml:"metadata"`
 	TableName                 string                 `bun:",notnull"                                                    yaml:"table_name"`
 	EmbeddingModelName        string                 `bun:",notnull"                                                    yaml:"embedding_model_name"`
+	EmbeddingService          string                 `bun:",notnull,default:''"                                         yaml:"embedding_service"` // Embedding service used to embed an auto-embedded collection
 	EmbeddingDimensions       int                    `bun:",notnull"                                                    yaml:"embedding_dimensions"`
 	IsAutoEmbedded            bool                   `bun:",notnull"                                                    yaml:"is_auto_embedded"`  // Is the collection automatically embedded by Zep?
 	DistanceFunction          DistanceFunction       `bun:",notnull"                                                    yaml:"distance_function"` // Distance function to use for index
@@ -28,6 +29,7 @@ type DocumentCollection struct {
 	IndexType                 IndexType              `bun:",notnull"                                                    yaml:"index_type"`        // Type of index to use
 	ListCount                 int                    `bun:",notnull"                                                    yaml:"list_count"`        // Number of lists in the collection index
 	ProbeCount                int                    `bun:",notnull"                                                    yaml:"probe_count"`       // Number of probes to use when searching the index
+	Chunking                  *DocumentChunking      `bun:"type:jsonb,nullzero"                                         yaml:"chunking"`          // Chunking of documents added to an auto-embedded collection
 	*DocumentCollectionCounts ` yaml:"document_collection_counts,inline"`
 }
 
@@ -36,13 +38,31 @@ type DocumentCollectionCounts struct {
 	DocumentEmbeddedCount int `bun:"document_embedded_count" json:"document_embedded_count" yaml:"document_embedded_count,omitempty"` // Number of documents with embeddings
 }
 
+// DocumentChunking configures how the documents added to an auto-embedded collection are
+// split into chunks, each of which is stored and embedded as a document of its own
+type DocumentChunking struct {
+	// Strategy is one of token, sentence or markdown
+	Strategy string `json:"strategy"                validate:"required,oneof=token sentence markdown" yaml:"strategy"`
+	// ChunkSize is the maximum size of a chunk, in tokens. It defaults to 512.
+	ChunkSize int `json:"chunk_size,omitempty"    validate:"omitempty,min=16,max=8192"             yaml:"chunk_size,omitempty"`
+	// ChunkOverlap is the number of tokens repeated from the end of a chunk at the start of
+	// the next, and must be less than the chunk size
+	ChunkOverlap int `json:"chunk_overlap,omitempty" validate:"omitempty,min=0"                       yaml:"chunk_overlap,omitempty"`
+}
+
 type CreateDocumentCollectionRequest struct {
 	Name                string                 `json:"name"                 validate:"required,alphanum,min=3,max=40"`
 	Description         string                 `json:"description"          validate:"omitempty,max=1000"`
 	Metadata            map[string]interface{} `json:"metadata,omitempty"`
 	EmbeddingDimensions int                    `json:"embedding_dimensions" validate:"required,numeric,min=8,max=2000"`
+	// EmbeddingService and EmbeddingModelName select the embedding model of an auto-embedded
+	// collection. They default to the configured document embeddings service and model.
+	EmbeddingService   string `json:"embedding_service,omitempty"    validate:"omitempty,oneof=local openai cohere vertexai"`
+	EmbeddingModelName string `json:"embedding_model_name,omitempty" validate:"omitempty,printascii,max=100"`
 	// these needs to be pointers so that we can distinguish between false and unset when validating
 	IsAutoEmbedded *bool `json:"is_auto_embedded"     validate:"required,boolean"`
+	// Chunking splits the documents added to an auto-embedded collection into chunks
+	Chunking *DocumentChunking `json:"chunking,omitempty"`
 }
 
 type UpdateDocumentCollectionRequest struct {
@@ -57,14 +77,46 @@ type DocumentCollectionResponse struct {
 	Name                string                 `json:"name"`
 	Description         string                 `json:"description"`
 	Metadata            map[string]interface{} `json:"metadata,omitempty"`
+	EmbeddingService    string                 `json:"embedding_service,omitempty"`
 	EmbeddingModelName  string                 `json:"embedding_model_name,omitempty"`
 	EmbeddingDimensions int                    `json:"embedding_dimensions"`
 	IsAutoEmbedded      bool                   `json:"is_auto_embedded"`
 	IsNormalized        bool                   `json:"is_normalized"`
 	IsIndexed           bool                   `json:"is_indexed"`
+	Chunking            *DocumentChunking      `json:"chunking,omitempty"`
+	*DocumentCollectionCounts
+}
+
+// CollectionIndexStatus describes the vector index of a DocumentCollection
+type CollectionIndexStatus struct {
+	CollectionName string    `json:"collection_name"`
+	IndexType      IndexType `json:"index_type"`
+	IsIndexed      bool      `json:"is_indexed"`
+	// IsBuilding is true while the index is being created or rebuilt
+	IsBuilding bool `json:"is_building"`
+	// IndexExists is true if the index is present in the database. An index is invalid
+	// if its build failed or was interrupted.
+	IndexExists bool `json:"index_exists"`
+	IndexValid  bool `json:"index_valid"`
+	// MinDocumentCount is the number of embedded documents at which an ivfflat index is
+	// created automatically
+	MinDocumentCount int `json:"min_document_count,omitempty"`
+	ListCount        int `json:"list_count,omitempty"`
+	ProbeCount       int `json:"probe_count,omitempty"`
 	*DocumentCollectionCounts
 }
 
+// ReindexCollectionRequest rebuilds the index of a DocumentCollection. The documents of an
+// auto-embedded collection may be re-embedded first, optionally by another embedding model
+// with the same dimensions.
+type ReindexCollectionRequest struct {
+	Reembed bool `json:"reembed"`
+	// EmbeddingService and EmbeddingModelName replace the collection's embedding model,
+	// and imply Reembed
+	EmbeddingService   string `json:"embedding_service,omitempty"    validate:"omitempty,oneof=local openai cohere vertexai"`
+	EmbeddingModelName string `json:"embedding_model_name,omitempty" validate:"omitempty,printascii,max=100"`
+}
+
 /* Document Models */
 
 type DocumentBase struct {
@@ -76,6 +128,10 @@ type DocumentBase struct {
 	Content    string                 `bun:",nullzero"`
 	Metadata   map[string]interface{} `bun:"type:jsonb,nullzero,json_use_number"`
 	IsEmbedded bool                   `bun:",nullzero"`
+	// ParentDocumentID is the document_id of the document a chunk was split from, or the ID
+	// generated for it if it had none. ChunkIndex is the chunk's position in that document.
+	ParentDocumentID string `bun:",nullzero"`
+	ChunkIndex       int    `bun:",notnull,default:0"`
 }
 
 type Document struct {
@@ -124,6 +180,38 @@ type DocumentResponse struct {
 	Metadata   map[string]interface{} `json:"metadata,omitempty"`
 	Embedding  []float32              `json:"embedding"`
 	IsEmbedded bool                   `json:"is_embedded"`
+	// ParentDocumentID and ChunkIndex are set on the chunks of a chunked document
+	ParentDocumentID string `json:"parent_document_id,omitempty"`
+	ChunkIndex       int    `json:"chunk_index,omitempty"`
+}
+
+// DocumentUploadResponse is a file uploaded to a collection, and the documents its text
+// was chunked into
+type DocumentUploadResponse struct {
+	Filename string `json:"filename"`
+	// ParentDocumentID links the file's chunks
+	ParentDocumentID string      `json:"parent_document_id"`
+	ChunkCount       int         `json:"chunk_count"`
+	UUIDs            []uuid.UUID `json:"uuids"`
+}
+
+// IngestURLRequest asks for the page at a URL to be fetched and stored in a collection
+type IngestURLRequest struct {
+	URL string `json:"url"                   validate:"required,url,max=2048"`
+	// DocumentID links the page's chunks, as their parent_document_id
+	DocumentID string                 `json:"document_id,omitempty" validate:"omitempty,printascii,max=100"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// IngestURLResponse is a page stored in a collection, and the documents its text was
+// chunked into
+type IngestURLResponse struct {
+	// URL is the URL of the page, after any redirects
+	URL              string      `json:"url"`
+	Title            string      `json:"title,omitempty"`
+	ParentDocumentID string      `json:"parent_document_id"`
+	ChunkCount       int         `json:"chunk_count"`
+	UUIDs            []uuid.UUID `json:"uuids"`
 }
 
 type DocEmbeddingTask struct {
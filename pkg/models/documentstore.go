@@ -73,10 +73,22 @@ type DocumentStore[T any] interface {
 		pageNumber int,
 		pageSize int,
 	) (*DocumentSearchResultPage, error)
+	// SearchCollections searches several collections, merging their results by score. Scores are normalized
+	// per collection, as collections may be embedded by different models. limit applies to the merged results.
+	SearchCollections(
+		ctx context.Context,
+		query *CollectionsSearchPayload,
+		limit int,
+	) (*CollectionsSearchResultPage, error)
 	// CreateCollectionIndex creates an index on the collection. Manually calling this function will drop and
 	// recreate the index, if it exists.
 	// force: If true, the index will be created even if there are too few documents in the collection.
 	CreateCollectionIndex(ctx context.Context, collectionName string, force bool) error
+	// GetCollectionIndexStatus returns the state of the collection's index and its document counts.
+	GetCollectionIndexStatus(ctx context.Context, collectionName string) (*CollectionIndexStatus, error)
+	// ReindexCollection rebuilds the collection's index, whatever its type and document count. If documents
+	// are re-embedded, the index is rebuilt as their new embeddings are stored.
+	ReindexCollection(ctx context.Context, collectionName string, request *ReindexCollectionRequest) error
 	// OnStart is called when the application starts. This is a good place to initialize any resources or configs that
 	// are required by the MemoryStore implementation.
 	OnStart(ctx context.Context) error
@@ -1,18 +1,47 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type EmbeddingModel struct {
-	Service      string `json:"service"`
+	Service string `json:"service"`
+	// Model is the name of the service's embedding model. Empty uses the service's default.
+	Model        string `json:"model,omitempty"`
 	Dimensions   int    `json:"dimensions"`
 	IsNormalized bool   `json:"normalized"`
 }
 
+// Embedder embeds texts using an embedding model
+type Embedder interface {
+	// EmbedTexts embeds the messages or documents that are stored and searched
+	EmbedTexts(ctx context.Context, texts []string) ([][]float32, error)
+	// EmbedQuery embeds a search query
+	EmbedQuery(ctx context.Context, query string) ([]float32, error)
+}
+
+// EmbeddingCache stores embeddings keyed by a hash of their text and embedding model, so that
+// identical texts aren't embedded again
+type EmbeddingCache interface {
+	// GetEmbeddings returns the cached embeddings of keys. Keys that aren't cached are absent
+	// from the returned map.
+	GetEmbeddings(ctx context.Context, keys []string) (map[string][]float32, error)
+	PutEmbeddings(ctx context.Context, embeddings map[string][]float32) error
+	// PurgeEmbeddings deletes the embeddings cached before before, returning the number deleted
+	PurgeEmbeddings(ctx context.Context, before time.Time) (int64, error)
+}
+
 type TextData struct {
 	TextUUID  uuid.UUID `json:"uuid,omitempty"` // MemoryStore's unique ID associated with this text.
 	Text      string    `json:"text"`
 	Embedding []float32 `json:"embedding,omitempty"`
 	Language  string    `json:"language"`
+	// Metadata is the metadata of the text's source, made available to vector indexes that
+	// support filtering.
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
 }
 
 type TextEmbeddingCollection struct {
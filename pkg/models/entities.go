@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type EntityMatch struct {
 	Start int    `json:"start"`
 	End   int    `json:"end"`
@@ -30,3 +32,30 @@ type EntityRequest struct {
 type EntityResponse struct {
 	Texts []EntityResponseRecord `json:"texts"`
 }
+
+// UserEntity is an entity mentioned in the messages of a user's sessions. Entities are
+// identified by their name, compared without case, and label.
+type UserEntity struct {
+	Name            string    `json:"name"`
+	Label           string    `json:"label"`
+	MentionCount    int       `json:"mention_count"`
+	LastMentionedAt time.Time `json:"last_mentioned_at"`
+}
+
+type UserEntityListResponse struct {
+	Entities []UserEntity `json:"entities"`
+	RowCount int          `json:"row_count"`
+}
+
+// EntityMention is a message that mentions an entity, and its session
+type EntityMention struct {
+	SessionID string  `json:"session_id"`
+	Message   Message `json:"message"`
+}
+
+type EntityMentionListResponse struct {
+	Mentions []EntityMention `json:"mentions"`
+	RowCount int             `json:"row_count"`
+	// NextCursor is the cursor of the next page of mentions. It is omitted on the last page.
+	NextCursor int64 `json:"next_cursor,omitempty"`
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
 /* NotFoundError */
@@ -44,3 +45,29 @@ func (e *BadRequestError) Unwrap() error {
 func NewBadRequestError(message string) error {
 	return &BadRequestError{Message: message}
 }
+
+/* SessionQuotaExceededError */
+
+var ErrSessionQuotaExceeded = errors.New("session quota exceeded")
+
+type SessionQuotaExceededError struct {
+	TenantID   string
+	Quota      int64
+	RetryAfter time.Duration
+}
+
+func (e *SessionQuotaExceededError) Error() string {
+	return fmt.Sprintf("session quota of %d exceeded for tenant %s", e.Quota, e.TenantID)
+}
+
+func (e *SessionQuotaExceededError) Unwrap() error {
+	return ErrSessionQuotaExceeded
+}
+
+func NewSessionQuotaExceededError(quota SessionQuota) error {
+	return &SessionQuotaExceededError{
+		TenantID:   quota.TenantID,
+		Quota:      quota.Quota,
+		RetryAfter: quota.RetryAfter,
+	}
+}
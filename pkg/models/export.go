@@ -0,0 +1,35 @@
+package models
+
+type ExportFormat string
+
+const (
+	// ExportFormatJSON exports a session as a single SessionExport
+	ExportFormatJSON ExportFormat = "json"
+	// ExportFormatJSONL exports a session as one ExportRecord per line
+	ExportFormatJSONL ExportFormat = "jsonl"
+)
+
+// SessionExport is a session with all of its messages and summaries, oldest first.
+type SessionExport struct {
+	Session   *Session  `json:"session"`
+	Messages  []Message `json:"messages"`
+	Summaries []Summary `json:"summaries"`
+	// Embeddings are only exported if requested
+	Embeddings []TextData `json:"embeddings,omitempty"`
+}
+
+type ExportRecordType string
+
+const (
+	ExportRecordSession   ExportRecordType = "session"
+	ExportRecordMessage   ExportRecordType = "message"
+	ExportRecordSummary   ExportRecordType = "summary"
+	ExportRecordEmbedding ExportRecordType = "embedding"
+)
+
+// ExportRecord is a line of a JSONL export. Data is a Session, Message, Summary or
+// TextData, depending on Type. The session is the first line.
+type ExportRecord struct {
+	Type ExportRecordType `json:"type"`
+	Data interface{}      `json:"data"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultMemoryFacts is the number of a user's most recently updated facts returned with
+// memory if the facts extractor doesn't configure it
+const DefaultMemoryFacts = 20
+
+// Fact is a stable fact about a user, such as that they live in Berlin, extracted from their
+// sessions as a subject, predicate and object. A user has one fact for a subject and
+// predicate, whose object is updated as the user's circumstances change.
+type Fact struct {
+	UUID      uuid.UUID `json:"uuid"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Subject   string    `json:"subject"`
+	Predicate string    `json:"predicate"`
+	Object    string    `json:"object"`
+	// SessionID and MessageUUID identify the message the fact was last extracted from
+	SessionID   string     `json:"session_id,omitempty"`
+	MessageUUID *uuid.UUID `json:"message_uuid,omitempty"`
+}
+
+type FactListResponse struct {
+	Facts    []Fact `json:"facts"`
+	RowCount int    `json:"row_count"`
+}
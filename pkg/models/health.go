@@ -0,0 +1,42 @@
+package models
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrHealthCheckSkipped is returned by a health check that doesn't apply to the configured
+// deployment, such as the vector extension check of a CockroachDB store
+var ErrHealthCheckSkipped = errors.New("health check skipped")
+
+// HealthStatus is the status of a dependency, or of the readiness of Zep overall
+type HealthStatus string
+
+const (
+	HealthStatusOK      HealthStatus = "ok"
+	HealthStatusError   HealthStatus = "error"
+	HealthStatusSkipped HealthStatus = "skipped"
+)
+
+// HealthCheck verifies that a dependency Zep needs to serve requests is available
+type HealthCheck struct {
+	Name string
+	// Check returns nil if the dependency is available, or ErrHealthCheckSkipped if the
+	// check doesn't apply
+	Check func(ctx context.Context) error
+}
+
+// DependencyHealth is the result of a HealthCheck
+type DependencyHealth struct {
+	Name   string       `json:"name"`
+	Status HealthStatus `json:"status"`
+	Error  string       `json:"error,omitempty"`
+	// LatencyMs is how long the check took, in milliseconds
+	LatencyMs int64 `json:"latency_ms"`
+}
+
+// ReadinessResponse is ok if no dependency's check failed
+type ReadinessResponse struct {
+	Status       HealthStatus       `json:"status"`
+	Dependencies []DependencyHealth `json:"dependencies"`
+}
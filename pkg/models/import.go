@@ -0,0 +1,50 @@
+package models
+
+import "context"
+
+// ImportProgress reports the progress of a JSONL import. Each line of an import is a
+// SessionMemory.
+type ImportProgress struct {
+	// Lines is the number of non-empty lines read
+	Lines int `json:"lines"`
+	// Imported is the number of lines whose messages were stored
+	Imported int `json:"imported"`
+	Messages int `json:"messages"`
+	Failed   int `json:"failed"`
+	// Errors are the first lines that failed. Failed counts all of them.
+	Errors []ImportError `json:"errors,omitempty"`
+	// Error is set if the import stopped before the end of its input
+	Error string `json:"error,omitempty"`
+	// Current is the progress of the line being stored, reported while lines with many
+	// messages are stored
+	Current *ImportLineProgress `json:"current,omitempty"`
+}
+
+// ImportLineProgress reports the progress of storing the messages of a line
+type ImportLineProgress struct {
+	Line int `json:"line"`
+	// Stored is the number of the line's messages stored so far, of Messages
+	Stored   int `json:"stored"`
+	Messages int `json:"messages"`
+}
+
+type ImportError struct {
+	Line      int    `json:"line"`
+	SessionID string `json:"session_id,omitempty"`
+	Error     string `json:"error"`
+}
+
+type messageProgressKey struct{}
+
+// WithMessageProgress adds onProgress to ctx. Stores that write large batches of messages in
+// several statements call it after each, with the number of messages stored so far and the
+// number being stored. The messages aren't committed until they've all been stored.
+func WithMessageProgress(ctx context.Context, onProgress func(stored, total int)) context.Context {
+	return context.WithValue(ctx, messageProgressKey{}, onProgress)
+}
+
+// MessageProgressFromContext returns the message progress func of ctx, or nil if it has none
+func MessageProgressFromContext(ctx context.Context) func(stored, total int) {
+	onProgress, _ := ctx.Value(messageProgressKey{}).(func(stored, total int))
+	return onProgress
+}
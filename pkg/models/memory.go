@@ -14,12 +14,50 @@ type Message struct {
 	Content    string                 `json:"content"`
 	Metadata   map[string]interface{} `json:"metadata,omitempty"`
 	TokenCount int                    `json:"token_count"`
+	// IsSystem is true if the message was injected by the system, e.g. injected context or
+	// tool results, rather than being a turn by a participant. This is independent of Role.
+	IsSystem bool `json:"is_system"`
+}
+
+// PositionedMessage is a Message along with its position in the session's full message
+// stream. AbsolutePosition is 1-based. RelativePosition ranges from 0.0 for the first message
+// to 1.0 for the last.
+type PositionedMessage struct {
+	Message
+	AbsolutePosition int     `json:"absolute_position"`
+	RelativePosition float64 `json:"relative_position"`
+}
+
+// GetMessagesRequest holds options for retrieving a session's recent messages.
+type GetMessagesRequest struct {
+	// ExcludeSystemMessages excludes messages with IsSystem set.
+	ExcludeSystemMessages bool `json:"exclude_system_messages"`
+	// MaxTokens, if set, returns the most recent messages whose total TokenCount fits within
+	// MaxTokens, rather than a number of messages. Messages whose tokens haven't been counted
+	// yet count as 0 tokens.
+	MaxTokens int `json:"max_tokens,omitempty"`
+}
+
+// UpdateMessageRequest holds the new content of a message.
+type UpdateMessageRequest struct {
+	Content string `json:"content"`
+}
+
+// MessageListOptions holds options for listing a session's messages.
+type MessageListOptions struct {
+	// Role only lists messages with this role, e.g. assistant, if set.
+	Role string
+	// Desc lists the newest messages first.
+	Desc bool
 }
 
 type MessageListResponse struct {
 	Messages   []Message `json:"messages"`
 	TotalCount int       `json:"total_count"`
 	RowCount   int       `json:"row_count"`
+	// NextCursor is the cursor of the next page of messages when listing by cursor. It is
+	// omitted on the last page.
+	NextCursor int64 `json:"next_cursor,omitempty"`
 }
 
 type SummaryListResponse struct {
@@ -37,8 +75,65 @@ type Summary struct {
 	TokenCount       int                    `json:"token_count"`
 }
 
+// DefaultWindowSummaries is the number of the most recent window summaries returned with the
+// abstract if hierarchical summarization doesn't configure it
+const DefaultWindowSummaries = 3
+
+// SummaryAbstract is a compact summary of a session's older summaries, created by
+// hierarchical summarization. It covers the session's summaries up to and including the
+// summary with LastSummaryUUID.
+type SummaryAbstract struct {
+	UUID            uuid.UUID `json:"uuid"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	Content         string    `json:"content"`
+	LastSummaryUUID uuid.UUID `json:"last_summary_uuid"`
+	TokenCount      int       `json:"token_count"`
+}
+
+// SummaryWindow is a summary and the messages it covers, i.e. the messages after the
+// previous summary's SummaryPoint up to and including its own SummaryPoint.
+type SummaryWindow struct {
+	Summary  Summary   `json:"summary"`
+	Messages []Message `json:"messages"`
+}
+
 type Memory struct {
 	Messages []Message              `json:"messages"`
 	Summary  *Summary               `json:"summary,omitempty"`
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	// Abstract and WindowSummaries are returned if hierarchical summarization is enabled.
+	// Abstract summarizes the session's summaries older than WindowSummaries, the most recent
+	// window summaries, oldest first.
+	Abstract        *SummaryAbstract `json:"abstract,omitempty"`
+	WindowSummaries []Summary        `json:"window_summaries,omitempty"`
+	// Facts are the session user's most recently updated facts, returned if the facts
+	// extractor is enabled.
+	Facts []Fact `json:"facts,omitempty"`
+}
+
+// BatchMemoryRequest adds memory to many sessions in one request. Each session's memory is
+// stored in its own transaction, so a failure in one session doesn't affect the others.
+type BatchMemoryRequest struct {
+	Sessions []SessionMemory `json:"sessions" validate:"required,min=1,max=100,dive"`
+}
+
+// SessionMemory is the memory of one session in a BatchMemoryRequest.
+type SessionMemory struct {
+	SessionID string `json:"session_id" validate:"required"`
+	Memory
+}
+
+type BatchMemoryResponse struct {
+	// Results are in the order of the request's sessions
+	Results     []BatchMemoryResult `json:"results"`
+	FailedCount int                 `json:"failed_count"`
+}
+
+// BatchMemoryResult reports whether a session's memory was stored. Status is the HTTP status
+// that adding the memory to the session alone would have returned.
+type BatchMemoryResult struct {
+	SessionID string `json:"session_id"`
+	Status    int    `json:"status"`
+	Error     string `json:"error,omitempty"`
 }
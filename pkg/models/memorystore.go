@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+
+	"github.com/getzep/zep/config"
 )
 
 // MemoryStore interface
@@ -12,8 +14,39 @@ type MemoryStore[T any] interface {
 	MessageStorer
 	SessionStorer
 	SummaryStorer
+	FactStorer
+	EntityStorer
 	// PurgeDeleted hard deletes all deleted data in the MemoryStore.
 	PurgeDeleted(ctx context.Context) error
+	// ApplyRetentionRules applies retention rules to the records of all sessions, returning
+	// the audit records of their application.
+	ApplyRetentionRules(
+		ctx context.Context,
+		rules []config.RetentionRuleConfig,
+	) ([]RetentionAudit, error)
+	// ListRetentionAudits returns up to limit retention audit records with an ID greater than
+	// cursor, oldest first. If rule isn't empty, only the records of rule are returned.
+	ListRetentionAudits(
+		ctx context.Context,
+		rule string,
+		cursor int64,
+		limit int,
+	) (*RetentionAuditListResponse, error)
+	// EraseUserData securely hard deletes a user, and all of the records of their sessions,
+	// returning a report of the records erased.
+	EraseUserData(ctx context.Context, userID string) (*UserDataErasureReport, error)
+	// ArchiveSessions moves the records of sessions that haven't been updated within
+	// cfg.InactiveDays to object storage, returning the IDs of the sessions archived.
+	ArchiveSessions(ctx context.Context, cfg *config.ArchivalConfig) ([]string, error)
+	// ExpireSessions deletes sessions that haven't been updated within their TTL, and purges
+	// them once the grace period has also passed.
+	ExpireSessions(
+		ctx context.Context,
+		cfg *config.SessionExpiryConfig,
+	) (*SessionExpiryResult, error)
+	// DispatchOutbox publishes the events written to the outbox and deletes them, returning
+	// the number of events dispatched.
+	DispatchOutbox(ctx context.Context, appState *AppState) (int, error)
 	// Close is called when the application is shutting down. This is a good place to clean up any resources used by
 	// the MemoryStore implementation.
 	Close() error
@@ -39,8 +72,27 @@ type SessionStorer interface {
 		session *UpdateSessionRequest,
 	) (*Session, error)
 	// DeleteSession deletes all records for a given sessionID. This is a soft delete. Related Messages
-	// and MessageEmbeddings are also soft deleted.
+	// and MessageEmbeddings are also soft deleted, and may be restored with RestoreSession.
 	DeleteSession(ctx context.Context, sessionID string) error
+	// RestoreSession undeletes a soft-deleted Session, along with the records deleted with it.
+	RestoreSession(ctx context.Context, appState *AppState, sessionID string) (*Session, error)
+	// PurgeSession hard deletes a Session and all of its records, including soft-deleted ones.
+	PurgeSession(ctx context.Context, sessionID string) error
+	// MergeSessions moves the messages of the source session to the target session, whose
+	// summary is recomputed, and merges their metadata. The source session is deleted.
+	MergeSessions(
+		ctx context.Context,
+		appState *AppState,
+		targetSessionID string,
+		request *MergeSessionsRequest,
+	) (*Session, error)
+	// ForkSession creates a session with copies of the session's messages up to a message,
+	// along with their embeddings and the summaries of those messages.
+	ForkSession(
+		ctx context.Context,
+		sessionID string,
+		request *ForkSessionRequest,
+	) (*Session, error)
 	// ListSessions returns a list of all Sessions, paginated by cursor and limit.
 	ListSessions(
 		ctx context.Context,
@@ -59,6 +111,9 @@ type SessionStorer interface {
 		orderedBy string,
 		asc bool,
 	) (*SessionListResponse, error)
+	// GetActiveSessionCount returns the number of sessions that have not been deleted for a
+	// tenant. A tenant's sessions are those of the project with the tenant's ID.
+	GetActiveSessionCount(ctx context.Context, tenantID string) (int64, error)
 }
 
 type MessageStorer interface {
@@ -76,6 +131,16 @@ type MessageStorer interface {
 		pageNumber int,
 		pageSize int,
 	) (*MessageListResponse, error)
+	// ListMessages retrieves up to limit messages for a given sessionID after cursor, oldest
+	// first unless opts.Desc is set. opts may be nil. The response's NextCursor is used to
+	// fetch the next page.
+	ListMessages(ctx context.Context,
+		appState *AppState,
+		sessionID string,
+		cursor int64,
+		limit int,
+		opts *MessageListOptions,
+	) (*MessageListResponse, error)
 	// PutMessageMetadata creates, updates, or deletes metadata for a given message, and does not
 	// update the message itself.
 	// isPrivileged indicates whether the caller is privileged to add or update system metadata.
@@ -84,6 +149,17 @@ type MessageStorer interface {
 		sessionID string,
 		messages []Message,
 		isPrivileged bool) error
+	// UpdateMessageContent updates the content of a session's message. The message's embedding
+	// is marked stale and summaries that include the message are deleted. The message is then
+	// published to the message extractors, which re-embed it and summarize the session again.
+	UpdateMessageContent(ctx context.Context,
+		appState *AppState,
+		sessionID string,
+		messageUUID uuid.UUID,
+		content string) (*Message, error)
+	// DeleteMessage deletes a session's message and its embedding. A summary whose summary
+	// point is the message is moved to the preceding message, or deleted if it can't be.
+	DeleteMessage(ctx context.Context, sessionID string, messageUUID uuid.UUID) error
 	// PutMessageEmbeddings stores a collection of TextData for a given sessionID.
 	PutMessageEmbeddings(ctx context.Context,
 		appState *AppState,
@@ -93,6 +169,13 @@ type MessageStorer interface {
 	GetMessageEmbeddings(ctx context.Context,
 		appState *AppState,
 		sessionID string) ([]TextData, error)
+	// SearchMessages performs a full-text search over a session's messages using the session's
+	// language, returning at most limit messages ordered by rank.
+	SearchMessages(ctx context.Context,
+		appState *AppState,
+		sessionID string,
+		query string,
+		limit int) ([]Message, error)
 }
 
 type MemoryStorer interface {
@@ -103,10 +186,12 @@ type MemoryStorer interface {
 	//   - all messages since the last SummaryPoint, if lastNMessages == 0
 	//   - if no Summary (and no SummaryPoint) exists and lastNMessages == 0, returns
 	//     all undeleted messages
+	// request holds further options for the messages returned, and may be nil.
 	GetMemory(ctx context.Context,
 		appState *AppState,
 		sessionID string,
-		lastNMessages int) (*Memory, error)
+		lastNMessages int,
+		request *GetMessagesRequest) (*Memory, error)
 	// PutMemory stores a Memory for a given sessionID. If the SessionID doesn't exist, a new one is created.
 	PutMemory(ctx context.Context,
 		appState *AppState,
@@ -121,6 +206,14 @@ type MemoryStorer interface {
 		sessionID string,
 		query *MemorySearchPayload,
 		limit int) ([]MemorySearchResult, error)
+	// SearchUserMemory searches all of a user's sessions, returning at most limit results
+	// grouped by session.
+	SearchUserMemory(
+		ctx context.Context,
+		appState *AppState,
+		userID string,
+		query *MemorySearchPayload,
+		limit int) ([]UserMemorySearchResult, error)
 }
 
 type SummaryStorer interface {
@@ -145,6 +238,23 @@ type SummaryStorer interface {
 		appState *AppState,
 		sessionID string,
 		summary *Summary) error
+	// ReplaceSummaries stores a new Summary for a given sessionID and deletes the session's
+	// existing summaries and abstract, which it replaces.
+	ReplaceSummaries(ctx context.Context,
+		appState *AppState,
+		sessionID string,
+		summary *Summary) error
+	// GetSummaryAbstract retrieves the abstract of a given sessionID's summaries. nil is
+	// returned if the session has no abstract.
+	GetSummaryAbstract(ctx context.Context,
+		appState *AppState,
+		sessionID string) (*SummaryAbstract, error)
+	// PutSummaryAbstract stores the abstract of a given sessionID's summaries, replacing any
+	// existing abstract.
+	PutSummaryAbstract(ctx context.Context,
+		appState *AppState,
+		sessionID string,
+		abstract *SummaryAbstract) error
 	// UpdateSummaryMetadata updates the metadata for a given Summary. The Summary UUID must be set.
 	UpdateSummaryMetadata(ctx context.Context,
 		appState *AppState,
@@ -155,3 +265,41 @@ type SummaryStorer interface {
 		sessionID string,
 		embedding *TextData) error
 }
+
+type FactStorer interface {
+	// PutFacts stores facts about a user. A fact with the same subject and predicate as one of
+	// the user's existing facts, ignoring case, replaces the existing fact's object.
+	PutFacts(ctx context.Context, userID string, facts []Fact) error
+	// GetFacts returns up to limit of a user's facts, most recently updated first. If limit is
+	// 0, all of the user's facts are returned.
+	GetFacts(ctx context.Context, userID string, limit int) ([]Fact, error)
+}
+
+type EntityStorer interface {
+	// PutEntityMentions stores the entities mentioned by a session's messages, keyed by message
+	// UUID, replacing the entities previously stored for those messages.
+	PutEntityMentions(
+		ctx context.Context,
+		sessionID string,
+		entities map[uuid.UUID][]Entity,
+	) error
+	// GetUserEntities returns up to limit of the entities mentioned in a user's sessions, most
+	// mentioned first. If label isn't empty, only entities with the label are returned.
+	GetUserEntities(
+		ctx context.Context,
+		userID string,
+		label string,
+		limit int,
+	) (*UserEntityListResponse, error)
+	// ListEntityMentions returns up to limit of the messages of a user's sessions that mention
+	// an entity, newest first, before cursor if it's set. The entity's name is matched without
+	// case. If label isn't empty, only mentions of entities with the label are returned.
+	ListEntityMentions(
+		ctx context.Context,
+		userID string,
+		name string,
+		label string,
+		cursor int64,
+		limit int,
+	) (*EntityMentionListResponse, error)
+}
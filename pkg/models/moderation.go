@@ -0,0 +1,36 @@
+package models
+
+import "context"
+
+// Moderator scores texts for harmful content, such as harassment, hate or violence
+type Moderator interface {
+	// Moderate returns the category scores of each text, in the order of texts
+	Moderate(ctx context.Context, texts []string) ([]ModerationResult, error)
+}
+
+// ModerationResult maps moderation categories to scores between 0 and 1
+type ModerationResult struct {
+	CategoryScores map[string]float64 `json:"category_scores"`
+}
+
+// MaxScore returns the category with the highest score, and its score
+func (r ModerationResult) MaxScore() (string, float64) {
+	var category string
+	var score float64
+	for c, s := range r.CategoryScores {
+		if s > score || (s == score && c < category) {
+			category, score = c, s
+		}
+	}
+	return category, score
+}
+
+// Moderation is the moderation of a message, recorded in its system metadata. Category is
+// the message's highest scoring category, and Flagged whether Score reached the configured
+// threshold.
+type Moderation struct {
+	Flagged        bool               `json:"flagged"`
+	Category       string             `json:"category,omitempty"`
+	Score          float64            `json:"score"`
+	CategoryScores map[string]float64 `json:"category_scores"`
+}
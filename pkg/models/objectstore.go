@@ -0,0 +1,13 @@
+package models
+
+import "context"
+
+// ObjectStore stores opaque objects by key, such as the archives of inactive sessions
+type ObjectStore interface {
+	// PutObject creates or replaces the object at key
+	PutObject(ctx context.Context, key string, data []byte) error
+	// GetObject returns the object at key, or a NotFoundError if there isn't one
+	GetObject(ctx context.Context, key string) ([]byte, error)
+	// DeleteObject deletes the object at key. Deleting a missing object isn't an error.
+	DeleteObject(ctx context.Context, key string) error
+}
@@ -0,0 +1,28 @@
+package models
+
+import "context"
+
+// DefaultProjectID is the project of API requests that don't name one, and of the sessions,
+// users and collections created before projects were introduced
+const DefaultProjectID = "default"
+
+type projectIDKey struct{}
+
+// WithProjectID scopes ctx to a project. The stores only read and write the records of the
+// project of a scoped context.
+func WithProjectID(ctx context.Context, projectID string) context.Context {
+	return context.WithValue(ctx, projectIDKey{}, projectID)
+}
+
+// WithoutProject lifts the project scope of ctx, for requests acting on the whole deployment
+func WithoutProject(ctx context.Context) context.Context {
+	return context.WithValue(ctx, projectIDKey{}, nil)
+}
+
+// ProjectIDFromContext returns the project ctx is scoped to, and whether it's scoped. Contexts
+// of API requests are always scoped, while those of internal tasks aren't, so that tasks may
+// process the records of all projects.
+func ProjectIDFromContext(ctx context.Context) (string, bool) {
+	projectID, ok := ctx.Value(projectIDKey{}).(string)
+	return projectID, ok
+}
@@ -0,0 +1,35 @@
+package models
+
+import "github.com/google/uuid"
+
+// QARequest asks a question that is answered from a session's memory and, optionally, from
+// document collections
+type QARequest struct {
+	Question string `json:"question"                   validate:"required,max=4000"`
+	// MemoryLimit is the number of messages retrieved from the session. Unset uses 10.
+	MemoryLimit int `json:"memory_limit,omitempty"     validate:"omitempty,min=1,max=50"`
+	// CollectionNames and Tag select document collections that are searched as well, as
+	// they do for a search across collections
+	CollectionNames []string `json:"collection_names,omitempty" validate:"omitempty,max=20,dive,required"`
+	Tag             string   `json:"tag,omitempty"`
+	// DocumentLimit is the number of documents retrieved from the collections. Unset uses 5.
+	DocumentLimit int `json:"document_limit,omitempty"   validate:"omitempty,min=1,max=50"`
+}
+
+// QACitation is a message or document that an answer cites
+type QACitation struct {
+	// Source is the number the answer cites the source by, such as 1 for [1]
+	Source      int        `json:"source"`
+	MessageUUID *uuid.UUID `json:"message_uuid,omitempty"`
+	// DocumentUUID and CollectionName are set for documents
+	DocumentUUID   *uuid.UUID `json:"document_uuid,omitempty"`
+	CollectionName string     `json:"collection_name,omitempty"`
+	Content        string     `json:"content"`
+	Score          float64    `json:"score"`
+}
+
+type QAResponse struct {
+	// Answer cites its sources by number in square brackets, such as [1]
+	Answer    string       `json:"answer"`
+	Citations []QACitation `json:"citations"`
+}
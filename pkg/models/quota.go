@@ -0,0 +1,36 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// QuotaStore provides per-tenant quotas. It may be backed by static configuration, Postgres,
+// Redis, etc.
+type QuotaStore interface {
+	// GetSessionQuota returns the maximum number of active sessions for a tenant. A quota of
+	// 0 means the tenant is unlimited.
+	GetSessionQuota(tenantID string) (int64, error)
+}
+
+// SessionQuota is a tenant's session quota. RetryAfter is how long a client refused a session
+// is asked to wait before retrying.
+type SessionQuota struct {
+	TenantID   string
+	Quota      int64
+	RetryAfter time.Duration
+}
+
+type sessionQuotaKey struct{}
+
+// WithSessionQuota returns a copy of ctx carrying a tenant's session quota. The stores refuse
+// to create sessions with ctx once the tenant has quota.Quota active sessions.
+func WithSessionQuota(ctx context.Context, quota SessionQuota) context.Context {
+	return context.WithValue(ctx, sessionQuotaKey{}, quota)
+}
+
+// SessionQuotaFromContext returns the session quota ctx carries, and whether it carries one.
+func SessionQuotaFromContext(ctx context.Context) (SessionQuota, bool) {
+	q, ok := ctx.Value(sessionQuotaKey{}).(SessionQuota)
+	return q, ok
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// TokenBucket is the size and refill rate of a rate limit's token buckets
+type TokenBucket struct {
+	// Rate is the number of tokens added to a bucket per second
+	Rate float64
+	// Burst is the number of tokens a bucket holds, and that a new bucket starts with
+	Burst int
+}
+
+// RateLimiter takes tokens from token buckets identified by key. A request is allowed if a
+// token was taken from each of its buckets.
+type RateLimiter interface {
+	// Take takes a token from the bucket of key, creating it if it doesn't exist. If the
+	// bucket is empty, false is returned with the time until it next has a token.
+	Take(ctx context.Context, key string, bucket TokenBucket) (bool, time.Duration, error)
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RetentionTarget string
+
+const (
+	RetentionTargetMessages  RetentionTarget = "messages"
+	RetentionTargetSummaries RetentionTarget = "summaries"
+)
+
+type RetentionAction string
+
+const (
+	RetentionActionPurge     RetentionAction = "purge"
+	RetentionActionAnonymize RetentionAction = "anonymize"
+)
+
+// RetentionAudit records an application of a retention rule, and the records it purged or
+// anonymized
+type RetentionAudit struct {
+	UUID uuid.UUID `json:"uuid"`
+	// ID is used as a cursor for pagination
+	ID        int64           `json:"id"`
+	CreatedAt time.Time       `json:"created_at"`
+	Rule      string          `json:"rule"`
+	Target    RetentionTarget `json:"target"`
+	Action    RetentionAction `json:"action"`
+	// Cutoff is the creation time before which the rule applied to records
+	Cutoff      time.Time   `json:"cutoff"`
+	RecordCount int         `json:"record_count"`
+	RecordUUIDs []uuid.UUID `json:"record_uuids"`
+}
+
+type RetentionAuditListResponse struct {
+	Audits []RetentionAudit `json:"audits"`
+	// NextCursor is the cursor of the next page, or 0 if this is the last page
+	NextCursor int64 `json:"next_cursor"`
+}
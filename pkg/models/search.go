@@ -1,10 +1,17 @@
 package models
 
+import (
+	"context"
+	"time"
+)
+
 type SearchType string
 
 const (
 	SearchTypeSimilarity SearchType = "similarity"
 	SearchTypeMMR        SearchType = "mmr"
+	// SearchTypeHybrid combines full-text relevance with vector similarity
+	SearchTypeHybrid SearchType = "hybrid"
 )
 
 type SearchScope string
@@ -12,6 +19,8 @@ type SearchScope string
 const (
 	SearchScopeMessages SearchScope = "messages"
 	SearchScopeSummary  SearchScope = "summary"
+	// SearchScopeAll searches messages and summaries, and fuses the two sets of results
+	SearchScopeAll SearchScope = "all"
 )
 
 type MemorySearchResult struct {
@@ -22,12 +31,33 @@ type MemorySearchResult struct {
 	Embedding []float32              `json:"embedding"`
 }
 
+// UserMemorySearchResult is a session's results of a search across a user's sessions
+type UserMemorySearchResult struct {
+	SessionID string               `json:"session_id"`
+	Results   []MemorySearchResult `json:"results"`
+}
+
 type MemorySearchPayload struct {
 	Text        string                 `json:"text"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
 	SearchScope SearchScope            `json:"search_scope,omitempty"`
 	SearchType  SearchType             `json:"search_type,omitempty"`
-	MMRLambda   float32                `json:"mmr_lambda,omitempty"`
+	// MMRLambda trades the relevance of mmr results against their diversity. Values closer
+	// to 1 favour relevance, and values closer to 0 diversity. Unset uses 0.5.
+	MMRLambda float32 `json:"mmr_lambda,omitempty"`
+	// VectorFilter is passed as-is to vector indexes that support native filtering. It is
+	// applied in addition to Metadata.
+	VectorFilter map[string]interface{} `json:"vector_filter,omitempty"`
+	// StartDate and EndDate limit the search to messages or summaries created between them
+	StartDate *time.Time `json:"start_date,omitempty"`
+	EndDate   *time.Time `json:"end_date,omitempty"`
+	// Rerank reorders the results using the configured reranker. The Dist of each result is
+	// its relevance score.
+	Rerank bool `json:"rerank,omitempty"`
+	// RecencyHalfLifeDays, if set, weights the Dist of each result by its age, halving it for
+	// every RecencyHalfLifeDays days since the message or summary was created, so that older
+	// results rank below recent ones of similar relevance.
+	RecencyHalfLifeDays float64 `json:"recency_half_life_days,omitempty"`
 }
 
 type DocumentSearchPayload struct {
@@ -51,3 +81,52 @@ type DocumentSearchResultPage struct {
 	TotalPages  int                    `json:"total_pages"`
 	CurrentPage int                    `json:"current_page"`
 }
+
+type ScoreNormalization string
+
+const (
+	// ScoreNormalizationMinMax rescales the scores of each collection's results to between 0
+	// and 1, so that collections embedded by different models rank comparably
+	ScoreNormalizationMinMax ScoreNormalization = "minmax"
+	ScoreNormalizationNone   ScoreNormalization = "none"
+)
+
+// CollectionsSearchPayload searches several document collections at once: those named by
+// CollectionNames, and those whose metadata has a tags array containing Tag
+type CollectionsSearchPayload struct {
+	CollectionNames []string               `json:"collection_names,omitempty"    validate:"omitempty,max=100,dive,required"`
+	Tag             string                 `json:"tag,omitempty"`
+	Text            string                 `json:"text,omitempty"`
+	Embedding       []float32              `json:"embedding,omitempty"`
+	Metadata        map[string]interface{} `json:"metadata,omitempty"`
+	SearchType      SearchType             `json:"search_type"`
+	MMRLambda       float32                `json:"mmr_lambda,omitempty"`
+	// ScoreNormalization is applied to each collection's results before they're merged.
+	// Unset uses minmax.
+	ScoreNormalization ScoreNormalization `json:"score_normalization,omitempty" validate:"omitempty,oneof=minmax none"`
+}
+
+// CollectionsSearchResult is a document found by a search across collections
+type CollectionsSearchResult struct {
+	DocumentSearchResult
+	// CollectionName is the collection the document was found in
+	CollectionName string `json:"collection_name"`
+	// RawScore is the document's score in its collection, before it was normalized
+	RawScore float64 `json:"raw_score"`
+}
+
+type CollectionsSearchResultPage struct {
+	Results     []CollectionsSearchResult `json:"results"`
+	ResultCount int                       `json:"result_count"`
+}
+
+// Reranker orders texts by their relevance to a query, typically using a cross-encoder
+type Reranker interface {
+	// Rerank returns the texts' indexes and relevance scores, most relevant first
+	Rerank(ctx context.Context, query string, texts []string) ([]RerankResult, error)
+}
+
+type RerankResult struct {
+	Index int
+	Score float64
+}
@@ -0,0 +1,36 @@
+package models
+
+type SentimentLabel string
+
+const (
+	SentimentPositive SentimentLabel = "positive"
+	SentimentNegative SentimentLabel = "negative"
+	SentimentNeutral  SentimentLabel = "neutral"
+)
+
+// Sentiment is the sentiment of a message. Polarity ranges from -1, most negative, to 1,
+// most positive.
+type Sentiment struct {
+	Label    SentimentLabel `json:"label"`
+	Polarity float64        `json:"polarity"`
+}
+
+type SentimentRequestRecord struct {
+	UUID     string `json:"uuid"`
+	Text     string `json:"text"`
+	Language string `json:"language"`
+}
+
+type SentimentResponseRecord struct {
+	UUID     string         `json:"uuid"`
+	Label    SentimentLabel `json:"label"`
+	Polarity float64        `json:"polarity"`
+}
+
+type SentimentRequest struct {
+	Texts []SentimentRequestRecord `json:"texts"`
+}
+
+type SentimentResponse struct {
+	Texts []SentimentResponseRecord `json:"texts"`
+}
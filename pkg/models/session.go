@@ -2,6 +2,9 @@ package models
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"math"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,9 +19,48 @@ type Session struct {
 	SessionID string                 `json:"session_id"`
 	Metadata  map[string]interface{} `json:"metadata"`
 	// Must be a pointer to allow for null values
-	UserID *string `json:"user_id"`
+	UserID   *string `json:"user_id"`
+	Language string  `json:"language"`
 }
 
+// MessageWindowMetadataKey is the session metadata key of a message window that overrides the
+// configured memory.message_window for the session
+const MessageWindowMetadataKey = "message_window"
+
+// SessionMessageWindow returns the message window set in the session's metadata, or
+// defaultWindow if there isn't one. A bad request error is returned if the value isn't a
+// positive whole number.
+func SessionMessageWindow(session *Session, defaultWindow int) (int, error) {
+	window, ok := session.Metadata[MessageWindowMetadataKey]
+	if !ok || window == nil {
+		return defaultWindow, nil
+	}
+
+	// metadata read from the store decodes numbers as json.Number
+	var n float64
+	switch w := window.(type) {
+	case json.Number:
+		n, _ = w.Float64()
+	case float64:
+		n = w
+	case int:
+		n = float64(w)
+	}
+	if n < 1 || n != math.Trunc(n) || n > math.MaxInt32 {
+		return 0, NewBadRequestError(fmt.Sprintf(
+			"session %s metadata %s must be a positive whole number",
+			session.SessionID,
+			MessageWindowMetadataKey,
+		))
+	}
+
+	return int(n), nil
+}
+
+// SessionTTLMetadataKey is the session metadata key of a TTL, in minutes, that overrides the
+// configured data.session_expiry.ttl for the session. Sessions with a TTL of 0 don't expire.
+const SessionTTLMetadataKey = "session_ttl"
+
 type SessionListResponse struct {
 	Sessions   []*Session `json:"sessions"`
 	TotalCount int        `json:"total_count"`
@@ -30,6 +72,9 @@ type CreateSessionRequest struct {
 	// Must be a pointer to allow for null values
 	UserID   *string                `json:"user_id"`
 	Metadata map[string]interface{} `json:"metadata"`
+	// Language is the Postgres text search configuration used for the session's messages,
+	// e.g. "english" or "french". Defaults to "english".
+	Language string `json:"language,omitempty"`
 }
 
 type UpdateSessionRequest struct {
@@ -37,10 +82,60 @@ type UpdateSessionRequest struct {
 	Metadata  map[string]interface{} `json:"metadata"`
 }
 
+// MetadataConflict selects the session whose metadata is kept where both sessions being
+// merged set a key
+type MetadataConflict string
+
+const (
+	MetadataConflictTarget MetadataConflict = "target"
+	MetadataConflictSource MetadataConflict = "source"
+)
+
+// MergeSessionsRequest merges the source session into the session it's made for
+type MergeSessionsRequest struct {
+	SourceSessionID string `json:"source_session_id"`
+	// MetadataConflict defaults to target
+	MetadataConflict MetadataConflict `json:"metadata_conflict,omitempty"`
+}
+
+// ForkSessionRequest creates a session from the messages of the session it's made for, up to
+// and including the message MessageUUID
+type ForkSessionRequest struct {
+	SessionID   string    `json:"session_id"`
+	MessageUUID uuid.UUID `json:"message_uuid"`
+	// Metadata defaults to the forked session's metadata
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// SessionExpiryResult lists the sessions expired by a check for expired sessions. Expired
+// sessions were soft deleted, and purged sessions hard deleted, unless the check was a dry run.
+type SessionExpiryResult struct {
+	Expired []string `json:"expired"`
+	Purged  []string `json:"purged"`
+	DryRun  bool     `json:"dry_run"`
+}
+
 type SessionManager interface {
 	Create(ctx context.Context, session *CreateSessionRequest) (*Session, error)
 	Get(ctx context.Context, sessionID string) (*Session, error)
 	Update(ctx context.Context, session *UpdateSessionRequest, isPrivileged bool) (*Session, error)
-	Delete(ctx context.Context, sessionID string) error
+	Delete(ctx context.Context, sessionID string, hardDelete bool) error
+	Restore(ctx context.Context, sessionID string) (*Session, error)
 	ListAll(ctx context.Context, cursor int64, limit int) ([]*Session, error)
 }
+
+// SessionReplayRequest describes a replay of a session's messages to an external endpoint.
+type SessionReplayRequest struct {
+	SessionID string `json:"session_id"`
+	TargetURL string `json:"target_url"`
+	// DelayMS is the delay between messages in milliseconds
+	DelayMS int `json:"delay_ms"`
+}
+
+// SessionReplayEvent reports the progress of a session replay.
+type SessionReplayEvent struct {
+	CloneSessionID string `json:"clone_session_id"`
+	Replayed       int    `json:"replayed"`
+	Total          int    `json:"total"`
+	Error          string `json:"error,omitempty"`
+}
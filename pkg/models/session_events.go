@@ -0,0 +1,49 @@
+package models
+
+import "context"
+
+type SessionEventType string
+
+const (
+	// SessionEventMessages is sent when messages are added to a session
+	SessionEventMessages SessionEventType = "messages"
+	// SessionEventSummary is sent when a session is summarized
+	SessionEventSummary SessionEventType = "summary"
+	// SessionEventExtractor is sent when an extractor enriches a session's messages or
+	// summary, e.g. with entities or an intent
+	SessionEventExtractor SessionEventType = "extractor"
+)
+
+// SessionEvent is an event in a session, as sent to clients of the session's stream.
+type SessionEvent struct {
+	Type      SessionEventType `json:"type"`
+	SessionID string           `json:"session_id"`
+	// Extractor is the task topic of the extractor for extractor events, e.g. message_ner
+	Extractor TaskTopic `json:"extractor,omitempty"`
+	// Messages are the new messages, or for extractor events, the UUIDs of the enriched
+	// messages and the fields the extractor updated
+	Messages []Message `json:"messages,omitempty"`
+	Summary  *Summary  `json:"summary,omitempty"`
+}
+
+// SessionEventBroker delivers session events to subscribers in the same process.
+type SessionEventBroker interface {
+	// Publish sends an event to the subscribers of its session. It doesn't block: events are
+	// dropped for subscribers that aren't keeping up.
+	Publish(event *SessionEvent)
+	// Subscribe returns a channel of a session's events, and a function that ends the
+	// subscription and closes the channel.
+	Subscribe(sessionID string) (<-chan *SessionEvent, func())
+}
+
+// EventProducer publishes session events to an external event stream, such as Kafka
+type EventProducer interface {
+	// Publish sends an event to the stream. It doesn't block on delivery: failures are
+	// logged.
+	Publish(event *SessionEvent)
+	// PublishSync sends an event to the stream and waits for the stream to acknowledge it.
+	// Failures are returned.
+	PublishSync(ctx context.Context, event *SessionEvent) error
+	// Close delivers the events that are buffered and closes the producer
+	Close() error
+}
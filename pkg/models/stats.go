@@ -0,0 +1,49 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// RateBucket is a count of records created within a time bucket starting at BucketStart.
+type RateBucket struct {
+	BucketStart time.Time `json:"bucket_start"`
+	Count       int64     `json:"count"`
+}
+
+// MessageRateBucket is a count of messages created within a time bucket starting at
+// BucketStart, and the sum of their token counts
+type MessageRateBucket struct {
+	BucketStart time.Time `json:"bucket_start"`
+	Count       int64     `json:"count"`
+	Tokens      int64     `json:"tokens"`
+}
+
+// DeploymentStats are the totals of a deployment, across all projects
+type DeploymentStats struct {
+	SessionCount int64 `json:"session_count"`
+	MessageCount int64 `json:"message_count"`
+	// TotalTokens is the sum of the token counts of the messages
+	TotalTokens int64 `json:"total_tokens"`
+	// EmbeddingsPending is the number of messages not yet embedded. It's nil if message
+	// embeddings are disabled or stored in an external vector index.
+	EmbeddingsPending *int64 `json:"embeddings_pending,omitempty"`
+	// SummariesStale is the number of sessions with more messages since their last summary
+	// than the configured message window. It's nil if the summarizer is disabled.
+	SummariesStale *int64 `json:"summaries_stale,omitempty"`
+}
+
+// IngestionRate is the number of sessions and messages created per time bucket, most recent
+// bucket first
+type IngestionRate struct {
+	Sessions []RateBucket        `json:"sessions"`
+	Messages []MessageRateBucket `json:"messages"`
+}
+
+// StatsStore reports the statistics of a deployment for operators. Deleted sessions and
+// messages are excluded from totals, but included in ingestion rates.
+type StatsStore interface {
+	GetDeploymentStats(ctx context.Context) (*DeploymentStats, error)
+	// GetIngestionRate returns the last buckets of granularity "minute", "hour" or "day"
+	GetIngestionRate(ctx context.Context, granularity string, last int) (*IngestionRate, error)
+}
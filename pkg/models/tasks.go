@@ -2,6 +2,8 @@ package models
 
 import (
 	"context"
+	"encoding/json"
+	"time"
 
 	"github.com/ThreeDotsLabs/watermill/message"
 	"github.com/google/uuid"
@@ -18,6 +20,13 @@ const (
 	DocumentEmbedderTopic       TaskTopic = "document_embedder"
 	MessageSummaryEmbedderTopic TaskTopic = "message_summary_embedder"
 	MessageSummaryNERTopic      TaskTopic = "message_summary_ner"
+	MessageSentimentTopic       TaskTopic = "message_sentiment"
+	MessageLanguageTopic        TaskTopic = "message_language"
+	MessageModerationTopic      TaskTopic = "message_moderation"
+	MessageCustomTopic          TaskTopic = "message_custom"
+	MessageFactsTopic           TaskTopic = "message_facts"
+	MessagePipelineTopic        TaskTopic = "message_pipeline"
+	WebhookTopic                TaskTopic = "webhook"
 )
 
 type Task interface {
@@ -34,11 +43,24 @@ type TaskRouter interface {
 }
 
 type TaskPublisher interface {
-	Publish(taskType TaskTopic, metadata map[string]string, payload any) error
-	PublishMessage(metadata map[string]string, payload []MessageTask) error
+	Publish(ctx context.Context, taskType TaskTopic, metadata map[string]string, payload any) error
+	PublishMessage(ctx context.Context, metadata map[string]string, payload []MessageTask) error
+	// PublishSummarize publishes a task summarizing a session
+	PublishSummarize(ctx context.Context, sessionID string) error
 	Close() error
 }
 
+// ExtractorResultCache stores extractor results keyed by a hash of the extractor and its
+// input, so that recurring inputs aren't extracted again
+type ExtractorResultCache interface {
+	// GetResults returns the cached results of keys. Keys that aren't cached are absent from
+	// the returned map.
+	GetResults(ctx context.Context, keys []string) (map[string]json.RawMessage, error)
+	PutResults(ctx context.Context, extractor string, results map[string]json.RawMessage) error
+	// PurgeResults deletes the results cached before before, returning the number deleted
+	PurgeResults(ctx context.Context, before time.Time) (int64, error)
+}
+
 type MessageTask struct {
 	UUID uuid.UUID `json:"uuid"`
 }
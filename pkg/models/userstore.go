@@ -57,3 +57,28 @@ type UserStore interface {
 		asc bool,
 	) (*UserListResponse, error)
 }
+
+// UserDataErasureReport records the data erased for a user, as evidence of the erasure
+type UserDataErasureReport struct {
+	UserID   string    `json:"user_id"`
+	ErasedAt time.Time `json:"erased_at"`
+	// Sessions lists the user's sessions erased, including deleted sessions
+	Sessions []SessionErasureReport `json:"sessions"`
+	// DeadLetters is the number of dead letters of the user's sessions' tasks erased
+	DeadLetters int `json:"dead_letters"`
+	// Facts is the number of facts about the user erased
+	Facts int `json:"facts"`
+	// Entities is the number of entities erased as they were no longer mentioned in any
+	// session
+	Entities int `json:"entities"`
+}
+
+// SessionErasureReport counts the records of a session that were erased
+type SessionErasureReport struct {
+	SessionID         string `json:"session_id"`
+	Messages          int    `json:"messages"`
+	MessageEmbeddings int    `json:"message_embeddings"`
+	Summaries         int    `json:"summaries"`
+	SummaryEmbeddings int    `json:"summary_embeddings"`
+	SummaryAbstracts  int    `json:"summary_abstracts"`
+}
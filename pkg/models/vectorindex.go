@@ -0,0 +1,67 @@
+package models
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// MessageVectorIndex stores and searches message embeddings outside of the MemoryStore.
+// Messages and sessions remain in the MemoryStore; the index holds only the embeddings,
+// keyed by message UUID and scoped by session.
+type MessageVectorIndex interface {
+	// PutMessageEmbeddings upserts the embeddings for a session's messages.
+	PutMessageEmbeddings(ctx context.Context, sessionID string, embeddings []TextData) error
+	// GetMessageEmbeddings returns a session's embeddings. The Text field is not populated.
+	GetMessageEmbeddings(ctx context.Context, sessionID string) ([]TextData, error)
+	// SearchMessageEmbeddings returns at most limit of the session's messages nearest to
+	// embedding, ordered by descending score.
+	SearchMessageEmbeddings(
+		ctx context.Context,
+		sessionID string,
+		embedding []float32,
+		limit int,
+	) ([]VectorIndexResult, error)
+	// DeleteSessionEmbeddings removes all of a session's embeddings from the index.
+	DeleteSessionEmbeddings(ctx context.Context, sessionID string) error
+	// DeleteMessageEmbedding removes a session's message embedding from the index. Deleting
+	// an embedding that isn't in the index is not an error.
+	DeleteMessageEmbedding(ctx context.Context, sessionID string, messageUUID uuid.UUID) error
+}
+
+// FilteredMessageVectorIndex is a MessageVectorIndex that can apply a filter in its own
+// native filter syntax while searching.
+type FilteredMessageVectorIndex interface {
+	MessageVectorIndex
+	// SearchMessageEmbeddingsFiltered is SearchMessageEmbeddings, restricted to embeddings
+	// matching filter.
+	SearchMessageEmbeddingsFiltered(
+		ctx context.Context,
+		sessionID string,
+		embedding []float32,
+		limit int,
+		filter map[string]interface{},
+	) ([]VectorIndexResult, error)
+}
+
+// HybridMessageVectorIndex is a MessageVectorIndex that can combine lexical relevance over
+// message content with vector similarity.
+type HybridMessageVectorIndex interface {
+	MessageVectorIndex
+	// SearchMessagesHybrid returns at most limit of the session's messages, ranked by a
+	// combination of their relevance to text and the similarity of their embeddings to
+	// embedding.
+	SearchMessagesHybrid(
+		ctx context.Context,
+		sessionID string,
+		text string,
+		embedding []float32,
+		limit int,
+	) ([]VectorIndexResult, error)
+}
+
+type VectorIndexResult struct {
+	UUID      uuid.UUID
+	Score     float64
+	Embedding []float32
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebhookEventType is the type of a memory event sent to webhooks
+type WebhookEventType string
+
+const (
+	// WebhookEventSessionCreated is sent when a session is created, explicitly or by adding
+	// messages to a session that doesn't exist
+	WebhookEventSessionCreated WebhookEventType = "session.created"
+	// WebhookEventSessionDeleted is sent when a session is deleted or purged
+	WebhookEventSessionDeleted WebhookEventType = "session.deleted"
+	// WebhookEventSummaryCreated is sent when a session is summarized
+	WebhookEventSummaryCreated WebhookEventType = "summary.created"
+	// WebhookEventEntitiesExtracted is sent when entities are extracted from a session's
+	// messages or summary
+	WebhookEventEntitiesExtracted WebhookEventType = "entities.extracted"
+)
+
+// WebhookEventTypes are the types of events that webhooks may subscribe to
+var WebhookEventTypes = []WebhookEventType{
+	WebhookEventSessionCreated,
+	WebhookEventSessionDeleted,
+	WebhookEventSummaryCreated,
+	WebhookEventEntitiesExtracted,
+}
+
+// WebhookEvent is a memory event, as sent to webhooks
+type WebhookEvent struct {
+	// UUID identifies the event. It's the same across retries of its delivery, so that
+	// receivers can ignore duplicates.
+	UUID      uuid.UUID        `json:"uuid"`
+	Type      WebhookEventType `json:"type"`
+	CreatedAt time.Time        `json:"created_at"`
+	SessionID string           `json:"session_id"`
+	// Summary is the new summary, or for entities.extracted, the UUID and metadata of the
+	// summary whose entities were extracted
+	Summary *Summary `json:"summary,omitempty"`
+	// Messages are, for entities.extracted, the UUIDs and metadata of the messages whose
+	// entities were extracted
+	Messages []Message `json:"messages,omitempty"`
+}
+
+// WebhookNotifier sends memory events to the webhooks subscribed to them
+type WebhookNotifier interface {
+	// Notify queues the event's delivery to each webhook subscribed to its type. It doesn't
+	// wait for delivery, and failures to queue it are logged rather than returned, so that
+	// the operation raising the event doesn't fail.
+	Notify(ctx context.Context, event *WebhookEvent)
+	// NotifySync queues the event's delivery as Notify does, returning an error if it
+	// couldn't be queued for each webhook subscribed to its type. A webhook may be queued
+	// the event more than once if NotifySync is retried after a failure.
+	NotifySync(ctx context.Context, event *WebhookEvent) error
+}
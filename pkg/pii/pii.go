@@ -0,0 +1,219 @@
+package pii
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+)
+
+type EntityType string
+
+const (
+	Email      EntityType = "email"
+	Phone      EntityType = "phone"
+	SSN        EntityType = "ssn"
+	CreditCard EntityType = "credit_card"
+)
+
+// EntityTypes are the types of PII that can be redacted, in the order they're detected.
+// Text matched by one type isn't matched by later types.
+var EntityTypes = []EntityType{Email, CreditCard, SSN, Phone}
+
+type Strategy string
+
+const (
+	// StrategyRedact replaces PII with a placeholder naming its type, e.g. [EMAIL]
+	StrategyRedact Strategy = "redact"
+	// StrategyMask replaces each character of PII with an asterisk
+	StrategyMask Strategy = "mask"
+	// StrategyMetadata leaves PII in place, only reporting where it was found
+	StrategyMetadata Strategy = "metadata"
+)
+
+var patterns = map[EntityType]*regexp.Regexp{
+	Email:      regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	CreditCard: regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+	SSN:        regexp.MustCompile(`\b(\d{3})-(\d{2})-(\d{4})\b`),
+	Phone: regexp.MustCompile(
+		`(?:\+\d{1,3}[\s.-]?)?(?:\(\d{2,4}\)[\s.-]?|\b\d{2,4}[\s.-])\d{3,4}[\s.-]?\d{3,4}\b`,
+	),
+}
+
+// validators reject matches that have the shape of a type of PII but can't be one
+var validators = map[EntityType]func(text string, start, end int) bool{
+	CreditCard: func(text string, start, end int) bool { return luhnValid(text[start:end]) },
+	SSN:        func(text string, start, end int) bool { return ssnValid(text[start:end]) },
+	Phone:      isolated,
+}
+
+// Entity is PII found in a text. Start and End are the byte offsets of the entity in the
+// redacted text.
+type Entity struct {
+	Type  EntityType `json:"type"`
+	Start int        `json:"start"`
+	End   int        `json:"end"`
+}
+
+// Redactor finds and redacts PII of the configured types.
+type Redactor struct {
+	types    []EntityType
+	strategy Strategy
+}
+
+// NewRedactor returns a Redactor configured by cfg. An error is returned if cfg names an
+// unknown entity type or strategy.
+func NewRedactor(cfg *config.PIIRedactorConfig) (*Redactor, error) {
+	strategy := Strategy(cfg.Strategy)
+	switch strategy {
+	case "":
+		strategy = StrategyRedact
+	case StrategyRedact, StrategyMask, StrategyMetadata:
+	default:
+		return nil, fmt.Errorf("unknown pii redaction strategy %q", cfg.Strategy)
+	}
+
+	types := EntityTypes
+	if len(cfg.EntityTypes) > 0 {
+		enabled := make(map[EntityType]bool, len(cfg.EntityTypes))
+		for _, t := range cfg.EntityTypes {
+			if _, ok := patterns[EntityType(t)]; !ok {
+				return nil, fmt.Errorf("unknown pii entity type %q", t)
+			}
+			enabled[EntityType(t)] = true
+		}
+		types = nil
+		for _, t := range EntityTypes {
+			if enabled[t] {
+				types = append(types, t)
+			}
+		}
+	}
+
+	return &Redactor{types: types, strategy: strategy}, nil
+}
+
+type match struct {
+	entityType EntityType
+	start, end int
+}
+
+// Redact returns text with its PII redacted according to the Redactor's strategy, and the
+// PII that was found.
+func (r *Redactor) Redact(text string) (string, []Entity) {
+	var matches []match
+	for _, t := range r.types {
+		for _, loc := range patterns[t].FindAllStringIndex(text, -1) {
+			if validate, ok := validators[t]; ok && !validate(text, loc[0], loc[1]) {
+				continue
+			}
+			if overlaps(matches, loc[0], loc[1]) {
+				continue
+			}
+			matches = append(matches, match{entityType: t, start: loc[0], end: loc[1]})
+		}
+	}
+	if len(matches) == 0 {
+		return text, nil
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].start < matches[j].start })
+
+	var b strings.Builder
+	entities := make([]Entity, len(matches))
+	last := 0
+	for i, m := range matches {
+		b.WriteString(text[last:m.start])
+		start := b.Len()
+		b.WriteString(r.replacement(m.entityType, text[m.start:m.end]))
+		entities[i] = Entity{Type: m.entityType, Start: start, End: b.Len()}
+		last = m.end
+	}
+	b.WriteString(text[last:])
+
+	return b.String(), entities
+}
+
+func (r *Redactor) replacement(entityType EntityType, value string) string {
+	switch r.strategy {
+	case StrategyMask:
+		return strings.Repeat("*", utf8.RuneCountInString(value))
+	case StrategyMetadata:
+		return value
+	default:
+		return "[" + strings.ToUpper(string(entityType)) + "]"
+	}
+}
+
+func overlaps(matches []match, start, end int) bool {
+	for _, m := range matches {
+		if start < m.end && m.start < end {
+			return true
+		}
+	}
+	return false
+}
+
+// isolated reports whether a match isn't part of a longer run of digits, such as a phone
+// number's worth of digits within an account number
+func isolated(text string, start, end int) bool {
+	isDigit := func(i int) bool {
+		return i >= 0 && i < len(text) && '0' <= text[i] && text[i] <= '9'
+	}
+	isSeparator := func(i int) bool {
+		return i >= 0 && i < len(text) && strings.IndexByte(" .-", text[i]) >= 0
+	}
+
+	if isDigit(start-1) || (isSeparator(start-1) && isDigit(start-2)) {
+		return false
+	}
+	return !isDigit(end) && !(isSeparator(end) && isDigit(end+1))
+}
+
+// luhnValid reports whether the digits of a card number pass the Luhn checksum
+func luhnValid(number string) bool {
+	sum, n := 0, 0
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && sum%10 == 0
+}
+
+// ssnValid reports whether an SSN could have been issued. Area numbers 000, 666 and 900-999,
+// group 00 and serial 0000 are never issued.
+func ssnValid(ssn string) bool {
+	parts := patterns[SSN].FindStringSubmatch(ssn)
+	area, group, serial := parts[1], parts[2], parts[3]
+	return area != "000" && area != "666" && area[0] != '9' && group != "00" && serial != "0000"
+}
+
+// RedactMessages redacts the PII in the content of messages, as configured by cfg. The
+// PII found in each message is returned in the order of messages.
+func RedactMessages(cfg *config.PIIRedactorConfig, messages []models.Message) ([][]Entity, error) {
+	redactor, err := NewRedactor(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	entities := make([][]Entity, len(messages))
+	for i := range messages {
+		messages[i].Content, entities[i] = redactor.Redact(messages[i].Content)
+	}
+
+	return entities, nil
+}
@@ -0,0 +1,81 @@
+package pii
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/config"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"email", "Mail jane.doe+zep@example.co.uk today", "Mail [EMAIL] today"},
+		{"us phone", "Call (555) 123-4567 or 555.123.4567", "Call [PHONE] or [PHONE]"},
+		{"international phone", "Call +44 20 7946 0958", "Call [PHONE]"},
+		{"ssn", "My SSN is 123-45-6789.", "My SSN is [SSN]."},
+		{"unissued ssn", "Not an SSN: 666-45-6789", "Not an SSN: 666-45-6789"},
+		{"credit card", "Card 4111 1111 1111 1111 expires", "Card [CREDIT_CARD] expires"},
+		{"invalid card number", "Order 4111 1111 1111 1112", "Order 4111 1111 1111 1112"},
+		{"no pii", "Hello there, how are you?", "Hello there, how are you?"},
+		{"year and count", "In 2023 we sold 150 units", "In 2023 we sold 150 units"},
+	}
+
+	redactor, err := NewRedactor(&config.PIIRedactorConfig{})
+	require.NoError(t, err)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redacted, _ := redactor.Redact(tt.text)
+			assert.Equal(t, tt.expected, redacted)
+		})
+	}
+}
+
+func TestRedact_Entities(t *testing.T) {
+	redactor, err := NewRedactor(&config.PIIRedactorConfig{})
+	require.NoError(t, err)
+
+	redacted, entities := redactor.Redact("Email a@b.com, SSN 123-45-6789")
+	assert.Equal(t, "Email [EMAIL], SSN [SSN]", redacted)
+	assert.Equal(t, []Entity{
+		{Type: Email, Start: 6, End: 13},
+		{Type: SSN, Start: 19, End: 24},
+	}, entities)
+	assert.Equal(t, "[EMAIL]", redacted[entities[0].Start:entities[0].End])
+}
+
+func TestRedact_Strategies(t *testing.T) {
+	text := "Email a@b.com"
+
+	redactor, err := NewRedactor(&config.PIIRedactorConfig{Strategy: "mask"})
+	require.NoError(t, err)
+	redacted, _ := redactor.Redact(text)
+	assert.Equal(t, "Email *******", redacted)
+
+	redactor, err = NewRedactor(&config.PIIRedactorConfig{Strategy: "metadata"})
+	require.NoError(t, err)
+	redacted, entities := redactor.Redact(text)
+	assert.Equal(t, text, redacted)
+	assert.Equal(t, []Entity{{Type: Email, Start: 6, End: 13}}, entities)
+}
+
+func TestRedact_EntityTypes(t *testing.T) {
+	redactor, err := NewRedactor(&config.PIIRedactorConfig{EntityTypes: []string{"ssn"}})
+	require.NoError(t, err)
+
+	redacted, _ := redactor.Redact("a@b.com 123-45-6789")
+	assert.Equal(t, "a@b.com [SSN]", redacted)
+}
+
+func TestNewRedactor_Invalid(t *testing.T) {
+	_, err := NewRedactor(&config.PIIRedactorConfig{Strategy: "shred"})
+	assert.ErrorContains(t, err, "unknown pii redaction strategy")
+
+	_, err = NewRedactor(&config.PIIRedactorConfig{EntityTypes: []string{"address"}})
+	assert.ErrorContains(t, err, "unknown pii entity type")
+}
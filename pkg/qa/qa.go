@@ -0,0 +1,242 @@
+// Package qa answers questions from a session's memory and from document collections, citing
+// the messages and documents the answers are drawn from.
+package qa
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	llms2 "github.com/tmc/langchaingo/llms"
+
+	"github.com/getzep/zep/internal"
+	"github.com/getzep/zep/pkg/models"
+)
+
+const (
+	DefaultMemoryLimit   = 10
+	DefaultDocumentLimit = 5
+	answerMaxTokens      = 1024
+)
+
+const answerPromptTemplate = `
+Answer the question below using only the numbered sources, which are messages from a
+conversation and documents. After each statement in your answer, cite the sources that
+support it by their numbers in square brackets, such as [1] or [2, 3].
+If the sources don't contain the answer, say that you don't know.
+{{if .Summary}}
+Summary of the conversation, for context only:
+{{.Summary}}
+{{end}}
+Sources:
+{{range .Sources}}[{{.Number}}] {{.Text}}
+{{end}}
+Question: {{.Question}}
+
+Provide an answer immediately without preamble.
+`
+
+type answerPromptTemplateData struct {
+	Summary  string
+	Sources  []promptSource
+	Question string
+}
+
+type promptSource struct {
+	Number int
+	Text   string
+}
+
+// citationRegex matches citations such as [1] and [2, 3]
+var citationRegex = regexp.MustCompile(`\[(\d+(?:\s*,\s*\d+)*)\]`)
+
+// source is a message or document retrieved to answer a question
+type source struct {
+	citation models.QACitation
+	// text is the source as it's shown to the LLM
+	text string
+}
+
+// Answer answers a question from the session's most relevant messages, its summary, and the
+// most relevant documents of the requested collections, using the configured LLM.
+func Answer(
+	ctx context.Context,
+	appState *models.AppState,
+	sessionID string,
+	request *models.QARequest,
+) (*models.QAResponse, error) {
+	if _, err := appState.MemoryStore.GetSession(ctx, appState, sessionID); err != nil {
+		return nil, err
+	}
+
+	sources, err := memorySources(ctx, appState, sessionID, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(request.CollectionNames) > 0 || request.Tag != "" {
+		documents, err := documentSources(ctx, appState, request)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, documents...)
+	}
+
+	summary, err := appState.MemoryStore.GetSummary(ctx, appState, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get summary: %w", err)
+	}
+	var summaryContent string
+	if summary != nil {
+		summaryContent = summary.Content
+	}
+
+	return answer(ctx, appState.LLMClient, request.Question, summaryContent, sources)
+}
+
+// memorySources returns the session's messages most relevant to the question
+func memorySources(
+	ctx context.Context,
+	appState *models.AppState,
+	sessionID string,
+	request *models.QARequest,
+) ([]source, error) {
+	limit := request.MemoryLimit
+	if limit == 0 {
+		limit = DefaultMemoryLimit
+	}
+
+	results, err := appState.MemoryStore.SearchMemory(
+		ctx,
+		appState,
+		sessionID,
+		&models.MemorySearchPayload{
+			Text:        request.Question,
+			SearchScope: models.SearchScopeMessages,
+		},
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search memory: %w", err)
+	}
+
+	sources := make([]source, 0, len(results))
+	for _, r := range results {
+		if r.Message == nil {
+			continue
+		}
+		messageUUID := r.Message.UUID
+		sources = append(sources, source{
+			citation: models.QACitation{
+				MessageUUID: &messageUUID,
+				Content:     r.Message.Content,
+				Score:       r.Dist,
+			},
+			text: r.Message.Role + ": " + r.Message.Content,
+		})
+	}
+
+	return sources, nil
+}
+
+// documentSources returns the documents of the requested collections most relevant to the
+// question
+func documentSources(
+	ctx context.Context,
+	appState *models.AppState,
+	request *models.QARequest,
+) ([]source, error) {
+	limit := request.DocumentLimit
+	if limit == 0 {
+		limit = DefaultDocumentLimit
+	}
+
+	page, err := appState.DocumentStore.SearchCollections(
+		ctx,
+		&models.CollectionsSearchPayload{
+			CollectionNames: request.CollectionNames,
+			Tag:             request.Tag,
+			Text:            request.Question,
+			SearchType:      models.SearchTypeSimilarity,
+		},
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search documents: %w", err)
+	}
+
+	sources := make([]source, 0, len(page.Results))
+	for _, r := range page.Results {
+		documentUUID := r.UUID
+		sources = append(sources, source{
+			citation: models.QACitation{
+				DocumentUUID:   &documentUUID,
+				CollectionName: r.CollectionName,
+				Content:        r.Content,
+				Score:          r.Score,
+			},
+			text: r.Content,
+		})
+	}
+
+	return sources, nil
+}
+
+// answer prompts the LLM to answer the question from the numbered sources, and returns the
+// answer with the sources it cites, in the order they're first cited
+func answer(
+	ctx context.Context,
+	llm models.ZepLLM,
+	question string,
+	summary string,
+	sources []source,
+) (*models.QAResponse, error) {
+	data := answerPromptTemplateData{
+		Summary:  summary,
+		Sources:  make([]promptSource, len(sources)),
+		Question: question,
+	}
+	for i := range sources {
+		data.Sources[i] = promptSource{Number: i + 1, Text: sources[i].text}
+	}
+
+	prompt, err := internal.ParsePrompt(answerPromptTemplate, data)
+	if err != nil {
+		return nil, err
+	}
+
+	answerContent, err := llm.Call(ctx, prompt, llms2.WithMaxTokens(answerMaxTokens))
+	if err != nil {
+		return nil, fmt.Errorf("failed to answer question: %w", err)
+	}
+	answerContent = strings.TrimSpace(answerContent)
+
+	citations := make([]models.QACitation, 0)
+	for _, number := range parseCitations(answerContent, len(sources)) {
+		citation := sources[number-1].citation
+		citation.Source = number
+		citations = append(citations, citation)
+	}
+
+	return &models.QAResponse{Answer: answerContent, Citations: citations}, nil
+}
+
+// parseCitations returns the source numbers cited by an answer, in the order they're first
+// cited. Numbers that aren't between 1 and sourceCount are ignored.
+func parseCitations(answer string, sourceCount int) []int {
+	var numbers []int
+	seen := make(map[int]bool)
+	for _, match := range citationRegex.FindAllStringSubmatch(answer, -1) {
+		for _, field := range strings.Split(match[1], ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil || n < 1 || n > sourceCount || seen[n] {
+				continue
+			}
+			seen[n] = true
+			numbers = append(numbers, n)
+		}
+	}
+	return numbers
+}
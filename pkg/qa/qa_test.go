@@ -0,0 +1,95 @@
+package qa
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+)
+
+type fakeLLM struct {
+	response string
+	prompt   string
+}
+
+func (f *fakeLLM) Call(_ context.Context, prompt string, _ ...llms.CallOption) (string, error) {
+	f.prompt = prompt
+	return f.response, nil
+}
+
+func (f *fakeLLM) EmbedTexts(context.Context, []string) ([][]float32, error) {
+	return nil, nil
+}
+
+func (f *fakeLLM) GetTokenCount(string) (int, error) {
+	return 0, nil
+}
+
+func (f *fakeLLM) Init(context.Context, *config.Config) error {
+	return nil
+}
+
+func TestAnswer(t *testing.T) {
+	messageUUID, documentUUID := uuid.New(), uuid.New()
+	sources := []source{
+		{
+			citation: models.QACitation{MessageUUID: &messageUUID, Content: "I live in Lisbon"},
+			text:     "user: I live in Lisbon",
+		},
+		{
+			citation: models.QACitation{
+				DocumentUUID:   &documentUUID,
+				CollectionName: "cities",
+				Content:        "Lisbon is the capital of Portugal.",
+			},
+			text: "Lisbon is the capital of Portugal.",
+		},
+	}
+	llm := &fakeLLM{response: " The user lives in Lisbon [1], the capital of Portugal [2, 1]. [7]\n"}
+
+	response, err := answer(
+		context.Background(),
+		llm,
+		"Which country does the user live in?",
+		"The user is planning a trip.",
+		sources,
+	)
+	require.NoError(t, err)
+
+	assert.Contains(t, llm.prompt, "[1] user: I live in Lisbon\n[2] Lisbon is the capital of Portugal.")
+	assert.Contains(t, llm.prompt, "The user is planning a trip.")
+	assert.Contains(t, llm.prompt, "Question: Which country does the user live in?")
+
+	assert.Equal(t, "The user lives in Lisbon [1], the capital of Portugal [2, 1]. [7]", response.Answer)
+	require.Len(t, response.Citations, 2)
+	assert.Equal(t, 1, response.Citations[0].Source)
+	assert.Equal(t, &messageUUID, response.Citations[0].MessageUUID)
+	assert.Equal(t, 2, response.Citations[1].Source)
+	assert.Equal(t, &documentUUID, response.Citations[1].DocumentUUID)
+	assert.Equal(t, "cities", response.Citations[1].CollectionName)
+}
+
+func TestParseCitations(t *testing.T) {
+	tests := []struct {
+		name     string
+		answer   string
+		expected []int
+	}{
+		{"single citations", "A [2]. B [1].", []int{2, 1}},
+		{"grouped citations", "A [1, 3] and B [3,2].", []int{1, 3, 2}},
+		{"out of range citations", "A [0] B [4] C [2]", []int{2}},
+		{"no citations", "I don't know.", nil},
+		{"other brackets", "A [see above] B [1a]", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseCitations(tt.answer, 3))
+		})
+	}
+}
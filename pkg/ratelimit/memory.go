@@ -0,0 +1,86 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/getzep/zep/pkg/models"
+)
+
+var _ models.RateLimiter = (*MemoryLimiter)(nil)
+
+// sweepInterval is the least time between sweeps of the full buckets of a MemoryLimiter
+const sweepInterval = time.Minute
+
+type memoryBucket struct {
+	tokens  float64
+	updated time.Time
+	bucket  models.TokenBucket
+}
+
+// MemoryLimiter is a RateLimiter holding its buckets in memory. Buckets are only shared by
+// the requests of one process. Full buckets, which are the same as new buckets, are swept
+// periodically.
+type MemoryLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*memoryBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryLimiter returns a MemoryLimiter with no buckets.
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		buckets:   make(map[string]*memoryBucket),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+func (l *MemoryLimiter) Take(
+	_ context.Context,
+	key string,
+	bucket models.TokenBucket,
+) (bool, time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) >= sweepInterval {
+		l.sweep(now)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &memoryBucket{tokens: float64(bucket.Burst), updated: now}
+		l.buckets[key] = b
+	}
+	b.bucket = bucket
+	b.tokens = refill(b, now)
+	b.updated = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0, nil
+	}
+	wait := time.Duration(math.Ceil((1 - b.tokens) / bucket.Rate * float64(time.Second)))
+	return false, wait, nil
+}
+
+// sweep deletes the buckets that are full
+func (l *MemoryLimiter) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		if refill(b, now) >= float64(b.bucket.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// refill returns the tokens of b at now
+func refill(b *memoryBucket, now time.Time) float64 {
+	tokens := b.tokens + now.Sub(b.updated).Seconds()*b.bucket.Rate
+	return math.Min(tokens, float64(b.bucket.Burst))
+}
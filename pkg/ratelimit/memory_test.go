@@ -0,0 +1,51 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/pkg/models"
+)
+
+func TestMemoryLimiter(t *testing.T) {
+	now := time.Now()
+	l := NewMemoryLimiter()
+	l.now = func() time.Time { return now }
+	ctx := context.Background()
+	bucket := models.TokenBucket{Rate: 2, Burst: 3}
+
+	// a new bucket allows a burst
+	for i := 0; i < 3; i++ {
+		taken, _, err := l.Take(ctx, "key", bucket)
+		require.NoError(t, err)
+		assert.True(t, taken)
+	}
+	taken, wait, err := l.Take(ctx, "key", bucket)
+	require.NoError(t, err)
+	assert.False(t, taken)
+	assert.Equal(t, 500*time.Millisecond, wait)
+
+	// buckets are separate
+	taken, _, err = l.Take(ctx, "other", bucket)
+	require.NoError(t, err)
+	assert.True(t, taken)
+
+	// the bucket refills at the rate
+	now = now.Add(500 * time.Millisecond)
+	taken, _, err = l.Take(ctx, "key", bucket)
+	require.NoError(t, err)
+	assert.True(t, taken)
+	taken, _, err = l.Take(ctx, "key", bucket)
+	require.NoError(t, err)
+	assert.False(t, taken)
+
+	// full buckets are swept
+	now = now.Add(sweepInterval)
+	_, _, err = l.Take(ctx, "key", bucket)
+	require.NoError(t, err)
+	assert.Len(t, l.buckets, 1)
+}
@@ -0,0 +1,288 @@
+package ratelimit
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1" //nolint:gosec // Redis identifies scripts by their SHA1
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getzep/zep/pkg/models"
+)
+
+var _ models.RateLimiter = (*RedisLimiter)(nil)
+
+const (
+	// redisKeyPrefix namespaces the keys of the buckets
+	redisKeyPrefix = "zep:ratelimit:"
+	// redisTimeout bounds each command, so that a slow Redis server doesn't hold up requests
+	redisTimeout = time.Second
+	// redisMaxIdleConns is the number of connections kept open between commands
+	redisMaxIdleConns = 8
+)
+
+// takeScript takes a token from the bucket hash of KEYS[1], refilling it at ARGV[1] tokens
+// per second up to ARGV[2] tokens. It returns whether a token was taken and, if not, the
+// milliseconds until the bucket has one. The server's clock is used, so that replicas agree on
+// the time. Buckets expire once they'd be full.
+const takeScript = `
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'updated')
+local tokens = tonumber(bucket[1])
+local updated = tonumber(bucket[2])
+if tokens == nil or updated == nil then
+	tokens = burst
+	updated = now
+end
+tokens = math.min(burst, tokens + math.max(0, now - updated) * rate / 1000)
+local taken = 0
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	taken = 1
+else
+	wait = math.ceil((1 - tokens) * 1000 / rate)
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst * 1000 / rate) + 1000)
+return {taken, wait}
+`
+
+var takeScriptSHA = func() string {
+	sum := sha1.Sum([]byte(takeScript)) //nolint:gosec // see import
+	return hex.EncodeToString(sum[:])
+}()
+
+// RedisLimiter is a RateLimiter holding its buckets in Redis, so that they're shared by all
+// replicas. Tokens are taken by a Lua script, which requires Redis 5 or later.
+type RedisLimiter struct {
+	addr     string
+	username string
+	password string
+	db       int
+	tls      *tls.Config
+	idle     chan *redisConn
+}
+
+// NewRedisLimiter returns a RedisLimiter for the Redis server of a redis:// or rediss:// URL,
+// such as redis://:password@localhost:6379/0. Connections are opened when they're needed.
+func NewRedisLimiter(redisURL string) (*RedisLimiter, error) {
+	u, err := url.Parse(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis url: %w", err)
+	}
+
+	l := &RedisLimiter{idle: make(chan *redisConn, redisMaxIdleConns)}
+	switch u.Scheme {
+	case "redis":
+	case "rediss":
+		l.tls = &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12}
+	default:
+		return nil, fmt.Errorf("invalid redis url scheme: %s", u.Scheme)
+	}
+
+	l.addr = u.Host
+	if u.Port() == "" {
+		l.addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		l.username = u.User.Username()
+		l.password, _ = u.User.Password()
+	}
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		if l.db, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("invalid redis database: %s", db)
+		}
+	}
+	return l, nil
+}
+
+func (l *RedisLimiter) Take(
+	ctx context.Context,
+	key string,
+	bucket models.TokenBucket,
+) (bool, time.Duration, error) {
+	args := []string{
+		"1",
+		redisKeyPrefix + key,
+		strconv.FormatFloat(bucket.Rate, 'f', -1, 64),
+		strconv.Itoa(bucket.Burst),
+	}
+	reply, err := l.do(ctx, append([]string{"EVALSHA", takeScriptSHA}, args...)...)
+	var redisErr redisError
+	if errors.As(err, &redisErr) && strings.HasPrefix(string(redisErr), "NOSCRIPT") {
+		// EVAL caches the script for later EVALSHAs
+		reply, err = l.do(ctx, append([]string{"EVAL", takeScript}, args...)...)
+	}
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to take rate limit token: %w", err)
+	}
+
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != 2 {
+		return false, 0, fmt.Errorf("unexpected rate limit script reply: %v", reply)
+	}
+	taken, _ := values[0].(int64)
+	wait, _ := values[1].(int64)
+	return taken == 1, time.Duration(wait) * time.Millisecond, nil
+}
+
+// do sends a command on an idle or new connection, and returns its reply. Connections are
+// closed if the command fails other than with an error reply.
+func (l *RedisLimiter) do(ctx context.Context, args ...string) (interface{}, error) {
+	var conn *redisConn
+	select {
+	case conn = <-l.idle:
+	default:
+		var err error
+		if conn, err = l.dial(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	reply, err := conn.do(ctx, args...)
+	var redisErr redisError
+	if err != nil && !errors.As(err, &redisErr) {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	select {
+	case l.idle <- conn:
+	default:
+		_ = conn.Close()
+	}
+	return reply, err
+}
+
+func (l *RedisLimiter) dial(ctx context.Context) (*redisConn, error) {
+	ctx, cancel := context.WithTimeout(ctx, redisTimeout)
+	defer cancel()
+
+	var c net.Conn
+	var err error
+	if l.tls != nil {
+		dialer := &tls.Dialer{Config: l.tls}
+		c, err = dialer.DialContext(ctx, "tcp", l.addr)
+	} else {
+		var dialer net.Dialer
+		c, err = dialer.DialContext(ctx, "tcp", l.addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	conn := &redisConn{Conn: c, r: bufio.NewReader(c)}
+
+	var setup [][]string
+	if l.password != "" {
+		if l.username != "" {
+			setup = append(setup, []string{"AUTH", l.username, l.password})
+		} else {
+			setup = append(setup, []string{"AUTH", l.password})
+		}
+	}
+	if l.db != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(l.db)})
+	}
+	for _, args := range setup {
+		if _, err := conn.do(ctx, args...); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("failed to set up redis connection: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// redisError is an error reply of the Redis server
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// redisConn is a connection speaking the RESP2 protocol of Redis
+type redisConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *redisConn) do(ctx context.Context, args ...string) (interface{}, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok || time.Until(deadline) > redisTimeout {
+		deadline = time.Now().Add(redisTimeout)
+	}
+	if err := c.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	var command strings.Builder
+	fmt.Fprintf(&command, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&command, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(c, command.String()); err != nil {
+		return nil, fmt.Errorf("failed to send redis command: %w", err)
+	}
+	return c.readReply()
+}
+
+// readReply reads a reply: a string, an int64, a redisError, nil, or a slice of replies
+func (c *redisConn) readReply() (interface{}, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("failed to read redis reply: %w", err)
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("empty redis reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, data); err != nil {
+			return nil, fmt.Errorf("failed to read redis reply: %w", err)
+		}
+		return string(data[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		values := make([]interface{}, n)
+		for i := range values {
+			// error replies of array elements are returned as values
+			values[i], err = c.readReply()
+			var redisErr redisError
+			if errors.As(err, &redisErr) {
+				values[i], err = redisErr, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("invalid redis reply: %q", line)
+}
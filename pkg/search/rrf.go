@@ -0,0 +1,40 @@
+package search
+
+import "sort"
+
+// DefaultRRFConstant is the k constant of Reciprocal Rank Fusion. It limits the influence of
+// the highest ranked results, and 60 is the value used in the original paper.
+const DefaultRRFConstant = 60
+
+// FusedResult is an item ranked by ReciprocalRankFusion and its fused score
+type FusedResult[T comparable] struct {
+	Item  T
+	Score float64
+}
+
+// ReciprocalRankFusion combines several rankings of items into a single ranking. Each item
+// scores the sum of 1 / (k + rank) over the rankings it appears in, where rank starts at 1.
+// Results are returned in descending order of score, with ties in the order the items first
+// appear. See https://plg.uwaterloo.ca/~gvcormac/cormacksigir09-rrf.pdf
+func ReciprocalRankFusion[T comparable](k int, rankings ...[]T) []FusedResult[T] {
+	scores := make(map[T]float64)
+	var items []T
+	for _, ranking := range rankings {
+		for i, item := range ranking {
+			if _, ok := scores[item]; !ok {
+				items = append(items, item)
+			}
+			scores[item] += 1 / float64(k+i+1)
+		}
+	}
+
+	results := make([]FusedResult[T], len(items))
+	for i, item := range items {
+		results[i] = FusedResult[T]{Item: item, Score: scores[item]}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	return results
+}
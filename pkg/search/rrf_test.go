@@ -0,0 +1,35 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReciprocalRankFusion(t *testing.T) {
+	t.Run("Ranking", func(t *testing.T) {
+		vectorRanking := []string{"a", "b", "c"}
+		textRanking := []string{"c", "d", "b"}
+
+		results := ReciprocalRankFusion(60, vectorRanking, textRanking)
+
+		items := make([]string, len(results))
+		for i, r := range results {
+			items[i] = r.Item
+		}
+		// b and c appear in both rankings, and c's ranks sum lower than b's
+		assert.Equal(t, []string{"c", "b", "a", "d"}, items)
+		assert.InDelta(t, 1.0/61+1.0/63, results[0].Score, 1e-9)
+		assert.InDelta(t, 1.0/62, results[3].Score, 1e-9)
+	})
+
+	t.Run("Ties", func(t *testing.T) {
+		results := ReciprocalRankFusion(60, []string{"a"}, []string{"b"})
+		assert.Equal(t, "a", results[0].Item)
+		assert.Equal(t, "b", results[1].Item)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		assert.Empty(t, ReciprocalRankFusion[string](60))
+	})
+}
@@ -0,0 +1,135 @@
+package apihandlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/server/handlertools"
+)
+
+// CreateAPIKeyHandler godoc
+//
+//	@Summary		Create an API key
+//	@Description	create an API key of the request's project with the given scope, one of read,
+//	@Description	write or admin. The key is only returned by this request.
+//	@Tags			apikey
+//	@Accept			json
+//	@Produce		json
+//	@Param			apiKey	body		models.CreateAPIKeyRequest	true	"API key"
+//	@Success		201		{object}	models.CreateAPIKeyResponse
+//	@Failure		400		{object}	APIError	"Bad Request"
+//	@Failure		500		{object}	APIError	"Internal Server Error"
+//	@Security		Bearer
+//	@Router			/api/v1/api_keys [post]
+func CreateAPIKeyHandler(appState *models.AppState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !apiKeysEnabled(w, appState) {
+			return
+		}
+
+		var request models.CreateAPIKeyRequest
+		if err := handlertools.DecodeJSON(r, &request); err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		apiKey, err := appState.APIKeyStore.CreateAPIKey(r.Context(), &request)
+		if err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		if err := handlertools.EncodeJSON(w, apiKey); err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// ListAPIKeysHandler godoc
+//
+//	@Summary		List API keys
+//	@Description	list the API keys of the request's project, including revoked keys, oldest
+//	@Description	first
+//	@Tags			apikey
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{array}		models.APIKey
+//	@Failure		500	{object}	APIError	"Internal Server Error"
+//	@Security		Bearer
+//	@Router			/api/v1/api_keys [get]
+func ListAPIKeysHandler(appState *models.AppState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !apiKeysEnabled(w, appState) {
+			return
+		}
+
+		apiKeys, err := appState.APIKeyStore.ListAPIKeys(r.Context())
+		if err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		if err := handlertools.EncodeJSON(w, apiKeys); err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// RevokeAPIKeyHandler godoc
+//
+//	@Summary		Revoke an API key
+//	@Description	revoke an API key of the request's project by its uuid. Requests
+//	@Description	authenticated by the key are rejected once it's revoked.
+//	@Tags			apikey
+//	@Accept			json
+//	@Produce		json
+//	@Param			apiKeyUUID	path		string		true	"API key UUID"
+//	@Success		200			{string}	string		"OK"
+//	@Failure		400			{object}	APIError	"Bad Request"
+//	@Failure		404			{object}	APIError	"Not Found"
+//	@Failure		500			{object}	APIError	"Internal Server Error"
+//	@Security		Bearer
+//	@Router			/api/v1/api_keys/{apiKeyUUID} [delete]
+func RevokeAPIKeyHandler(appState *models.AppState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !apiKeysEnabled(w, appState) {
+			return
+		}
+
+		apiKeyUUID := handlertools.UUIDFromURL(r, w, "apiKeyUUID")
+		if apiKeyUUID == uuid.Nil {
+			return
+		}
+
+		if err := appState.APIKeyStore.RevokeAPIKey(r.Context(), apiKeyUUID); err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				handlertools.RenderError(w, fmt.Errorf("not found"), http.StatusNotFound)
+				return
+			}
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		_, _ = w.Write([]byte(OKResponse))
+	}
+}
+
+// apiKeysEnabled renders an error and returns false if there's no API key store
+func apiKeysEnabled(w http.ResponseWriter, appState *models.AppState) bool {
+	if appState.APIKeyStore == nil {
+		handlertools.RenderError(
+			w,
+			errors.New("api keys are not enabled"),
+			http.StatusInternalServerError,
+		)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,80 @@
+package apihandlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/server/handlertools"
+)
+
+// ListAuditEventsHandler godoc
+//
+//	@Summary		List audit events
+//	@Description	list the recorded requests that put, deleted, searched or exported memory,
+//	@Description	oldest first, with cursor pagination
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			project_id	query		string	false	"Only return the events of this project"
+//	@Param			actor		query		string	false	"Only return the events of this API key UUID or JWT subject"
+//	@Param			action		query		string	false	"Only return the events of this action: read, put, delete, search or export"
+//	@Param			session_id	query		string	false	"Only return the events of this session"
+//	@Param			since		query		string	false	"Only return the events at or after this RFC 3339 time"
+//	@Param			until		query		string	false	"Only return the events before this RFC 3339 time"
+//	@Param			limit		query		integer	false	"Limit the number of results returned"
+//	@Param			cursor		query		int64	false	"Cursor for pagination. Use the next_cursor of the previous page"
+//	@Success		200			{object}	models.AuditEventListResponse
+//	@Failure		400			{object}	APIError	"Bad Request"
+//	@Failure		500			{object}	APIError	"Internal Server Error"
+//	@Security		Bearer
+//	@Router			/api/v1/admin/audit_log [get]
+func ListAuditEventsHandler(appState *models.AppState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if appState.AuditLogStore == nil {
+			handlertools.RenderError(
+				w,
+				errors.New("audit log is not enabled"),
+				http.StatusInternalServerError,
+			)
+			return
+		}
+
+		query := r.URL.Query()
+		request := models.AuditEventListRequest{
+			ProjectID: query.Get("project_id"),
+			Actor:     query.Get("actor"),
+			Action:    models.AuditAction(query.Get("action")),
+			SessionID: query.Get("session_id"),
+		}
+
+		var err error
+		if request.Since, err = handlertools.TimeFromQuery(r, "since"); err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+		if request.Until, err = handlertools.TimeFromQuery(r, "until"); err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+		if request.Limit, err = handlertools.IntFromQuery[int](r, "limit"); err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+		if request.Cursor, err = handlertools.IntFromQuery[int64](r, "cursor"); err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		events, err := appState.AuditLogStore.ListAuditEvents(r.Context(), &request)
+		if err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		if err := handlertools.EncodeJSON(w, events); err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package apihandlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/server/handlertools"
+	"github.com/getzep/zep/pkg/tasks"
+)
+
+// ListDeadLettersHandler godoc
+//
+//	@Summary		List dead letters
+//	@Description	list the task messages that failed after retries, oldest first, with cursor
+//	@Description	pagination
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			topic	query		string	false	"Only return dead letters of this task topic"
+//	@Param			limit	query		integer	false	"Limit the number of results returned"
+//	@Param			cursor	query		int64	false	"Cursor for pagination. Use the next_cursor of the previous page"
+//	@Success		200		{object}	models.DeadLetterListResponse
+//	@Failure		400		{object}	APIError	"Bad Request"
+//	@Failure		500		{object}	APIError	"Internal Server Error"
+//	@Security		Bearer
+//	@Router			/api/v1/admin/dead_letters [get]
+func ListDeadLettersHandler(appState *models.AppState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit, err := handlertools.IntFromQuery[int](r, "limit")
+		if err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		cursor, err := handlertools.IntFromQuery[int64](r, "cursor")
+		if err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		deadLetters, err := appState.DeadLetterStore.ListDeadLetters(
+			r.Context(),
+			models.TaskTopic(r.URL.Query().Get("topic")),
+			cursor,
+			limit,
+		)
+		if err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		if err := handlertools.EncodeJSON(w, deadLetters); err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// GetDeadLetterHandler godoc
+//
+//	@Summary		Get a dead letter
+//	@Description	get a task message that failed after retries by its uuid
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			deadLetterUUID	path		string	true	"Dead letter UUID"
+//	@Success		200				{object}	models.DeadLetter
+//	@Failure		400				{object}	APIError	"Bad Request"
+//	@Failure		404				{object}	APIError	"Not Found"
+//	@Failure		500				{object}	APIError	"Internal Server Error"
+//	@Security		Bearer
+//	@Router			/api/v1/admin/dead_letters/{deadLetterUUID} [get]
+func GetDeadLetterHandler(appState *models.AppState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		deadLetter, ok := getDeadLetter(w, r, appState)
+		if !ok {
+			return
+		}
+
+		if err := handlertools.EncodeJSON(w, deadLetter); err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// RetryDeadLetterHandler godoc
+//
+//	@Summary		Retry a dead letter
+//	@Description	republish a task message that failed after retries to its topic, and delete
+//	@Description	the dead letter
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			deadLetterUUID	path		string		true	"Dead letter UUID"
+//	@Success		200				{string}	string		"OK"
+//	@Failure		400				{object}	APIError	"Bad Request"
+//	@Failure		404				{object}	APIError	"Not Found"
+//	@Failure		500				{object}	APIError	"Internal Server Error"
+//	@Security		Bearer
+//	@Router			/api/v1/admin/dead_letters/{deadLetterUUID}/retry [post]
+func RetryDeadLetterHandler(appState *models.AppState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		deadLetter, ok := getDeadLetter(w, r, appState)
+		if !ok {
+			return
+		}
+
+		if err := tasks.RetryDeadLetter(r.Context(), appState, deadLetter); err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		_, _ = w.Write([]byte(OKResponse))
+	}
+}
+
+// DeleteDeadLetterHandler godoc
+//
+//	@Summary		Delete a dead letter
+//	@Description	delete a task message that failed after retries without retrying it
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			deadLetterUUID	path		string		true	"Dead letter UUID"
+//	@Success		200				{string}	string		"OK"
+//	@Failure		400				{object}	APIError	"Bad Request"
+//	@Failure		404				{object}	APIError	"Not Found"
+//	@Failure		500				{object}	APIError	"Internal Server Error"
+//	@Security		Bearer
+//	@Router			/api/v1/admin/dead_letters/{deadLetterUUID} [delete]
+func DeleteDeadLetterHandler(appState *models.AppState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		deadLetterUUID := handlertools.UUIDFromURL(r, w, "deadLetterUUID")
+		if deadLetterUUID == uuid.Nil {
+			return
+		}
+
+		err := appState.DeadLetterStore.DeleteDeadLetter(r.Context(), deadLetterUUID)
+		if err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				handlertools.RenderError(w, fmt.Errorf("not found"), http.StatusNotFound)
+				return
+			}
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		_, _ = w.Write([]byte(OKResponse))
+	}
+}
+
+// getDeadLetter gets the dead letter of the request's deadLetterUUID. If it can't, an error
+// is rendered and false is returned.
+func getDeadLetter(
+	w http.ResponseWriter,
+	r *http.Request,
+	appState *models.AppState,
+) (*models.DeadLetter, bool) {
+	deadLetterUUID := handlertools.UUIDFromURL(r, w, "deadLetterUUID")
+	if deadLetterUUID == uuid.Nil {
+		return nil, false
+	}
+
+	deadLetter, err := appState.DeadLetterStore.GetDeadLetter(r.Context(), deadLetterUUID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			handlertools.RenderError(w, fmt.Errorf("not found"), http.StatusNotFound)
+			return nil, false
+		}
+		handlertools.RenderError(w, err, http.StatusInternalServerError)
+		return nil, false
+	}
+
+	return deadLetter, true
+}
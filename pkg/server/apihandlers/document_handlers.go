@@ -3,6 +3,7 @@ package apihandlers
 import (
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
@@ -758,10 +759,135 @@ func CreateCollectionIndexHandler(appState *models.AppState) http.HandlerFunc {
 		}
 
 		err := store.CreateCollectionIndex(r.Context(), collectionName, force)
+		if err != nil {
+			if errors.Is(err, models.ErrBadRequest) {
+				handlertools.RenderError(w, err, http.StatusBadRequest)
+				return
+			}
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		_, err = w.Write([]byte("OK"))
 		if err != nil {
 			handlertools.RenderError(w, err, http.StatusInternalServerError)
 			return
 		}
+	}
+}
+
+// GetCollectionIndexStatusHandler godoc
+//
+//	@Summary		Gets the index status of a DocumentCollection
+//	@Description	Returns the state of the specified DocumentCollection's index, including whether it is being
+//	@Description	built, and the collection's document counts. ivfflat indexes are created automatically once
+//	@Description	min_document_count documents have been embedded.
+//
+//	@Tags			collection
+//
+//	@Accept			json
+//	@Produce		json
+//	@Param			collectionName	path		string	true	"Name of the Document Collection"
+//
+//	@Success		200				{object}	models.CollectionIndexStatus
+//	@Failure		400				{object}	APIError	"Bad Request"
+//	@Failure		401				{object}	APIError	"Unauthorized"
+//	@Failure		404				{object}	APIError	"Not Found"
+//	@Failure		500				{object}	APIError	"Internal Server Error"
+//
+//	@Security		Bearer
+//
+//	@Router			/api/v1/collection/{collectionName}/index [get]
+func GetCollectionIndexStatusHandler(appState *models.AppState) http.HandlerFunc {
+	store := appState.DocumentStore
+	return func(w http.ResponseWriter, r *http.Request) {
+		collectionName := strings.ToLower(chi.URLParam(r, "collectionName"))
+		if collectionName == "" {
+			handlertools.RenderError(
+				w,
+				errors.New("collectionName is required"),
+				http.StatusBadRequest,
+			)
+			return
+		}
+
+		status, err := store.GetCollectionIndexStatus(r.Context(), collectionName)
+		if err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				handlertools.RenderError(w, err, http.StatusNotFound)
+				return
+			}
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		if err := handlertools.EncodeJSON(w, status); err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// ReindexCollectionHandler godoc
+//
+//	@Summary		Rebuilds the index of a DocumentCollection
+//	@Description	Rebuilds the specified DocumentCollection's index in the background, whatever its document
+//	@Description	count, such as after bulk changes to its documents. The documents of an auto-embedded
+//	@Description	collection may be re-embedded, such as after its embedding model is swapped, in which case
+//	@Description	its index is rebuilt as they are embedded.
+//
+//	@Tags			collection
+//
+//	@Accept			json
+//	@Produce		json
+//	@Param			collectionName	path		string							true	"Name of the Document Collection"
+//	@Param			request			body		models.ReindexCollectionRequest	false	"Re-embedding options"
+//
+//	@Success		200				{object}	string							"OK"
+//	@Failure		400				{object}	APIError						"Bad Request"
+//	@Failure		401				{object}	APIError						"Unauthorized"
+//	@Failure		404				{object}	APIError						"Not Found"
+//	@Failure		500				{object}	APIError						"Internal Server Error"
+//
+//	@Security		Bearer
+//
+//	@Router			/api/v1/collection/{collectionName}/index/reindex [post]
+func ReindexCollectionHandler(appState *models.AppState) http.HandlerFunc {
+	store := appState.DocumentStore
+	return func(w http.ResponseWriter, r *http.Request) {
+		collectionName := strings.ToLower(chi.URLParam(r, "collectionName"))
+		if collectionName == "" {
+			handlertools.RenderError(
+				w,
+				errors.New("collectionName is required"),
+				http.StatusBadRequest,
+			)
+			return
+		}
+
+		var request models.ReindexCollectionRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+		if err := validate.Struct(request); err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		err := store.ReindexCollection(r.Context(), collectionName, &request)
+		if err != nil {
+			switch {
+			case errors.Is(err, models.ErrNotFound):
+				handlertools.RenderError(w, err, http.StatusNotFound)
+			case errors.Is(err, models.ErrBadRequest):
+				handlertools.RenderError(w, err, http.StatusBadRequest)
+			default:
+				handlertools.RenderError(w, err, http.StatusInternalServerError)
+			}
+			return
+		}
 
 		w.WriteHeader(http.StatusOK)
 		_, err = w.Write([]byte("OK"))
@@ -836,6 +962,71 @@ func SearchDocumentsHandler(appState *models.AppState) http.HandlerFunc {
 	}
 }
 
+// SearchCollectionsHandler godoc
+//
+//	@Summary		Searches Documents across DocumentCollections
+//	@Description	Searches the named DocumentCollections, and those whose metadata has a tags array containing
+//	@Description	the tag, and merges their results. Unless score_normalization is none, the scores of each
+//	@Description	collection's results are rescaled to between 0 and 1 before they're merged, as collections
+//	@Description	may be embedded by different models. Each result is attributed to its collection.
+//
+//	@Tags			document
+//
+//	@Accept			json
+//	@Produce		json
+//	@Param			limit			query		int								false	"Limit the number of returned documents"
+//	@Param			searchPayload	body		models.CollectionsSearchPayload	true	"Search criteria"
+//	@Success		200				{object}	models.CollectionsSearchResultPage	"OK"
+//	@Failure		400				{object}	APIError							"Bad Request"
+//	@Failure		401				{object}	APIError							"Unauthorized"
+//	@Failure		404				{object}	APIError							"Not Found"
+//	@Failure		500				{object}	APIError							"Internal Server Error"
+//
+//	@Security		Bearer
+//
+//	@Router			/api/v1/collections/search [post]
+func SearchCollectionsHandler(appState *models.AppState) http.HandlerFunc {
+	store := appState.DocumentStore
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit, err := handlertools.IntFromQuery[int](r, "limit")
+		if err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		var searchPayload models.CollectionsSearchPayload
+		if err := json.NewDecoder(r.Body).Decode(&searchPayload); err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+		if err := validate.Struct(searchPayload); err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+		for i := range searchPayload.CollectionNames {
+			searchPayload.CollectionNames[i] = strings.ToLower(searchPayload.CollectionNames[i])
+		}
+
+		results, err := store.SearchCollections(r.Context(), &searchPayload, limit)
+		if err != nil {
+			switch {
+			case errors.Is(err, models.ErrNotFound):
+				handlertools.RenderError(w, err, http.StatusNotFound)
+			case errors.Is(err, models.ErrBadRequest):
+				handlertools.RenderError(w, err, http.StatusBadRequest)
+			default:
+				handlertools.RenderError(w, err, http.StatusInternalServerError)
+			}
+			return
+		}
+
+		if err := handlertools.EncodeJSON(w, results); err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
 // documentCollectionFromCreateRequest converts a CreateDocumentCollectionRequest to a DocumentCollection.
 func documentCollectionFromCreateRequest(
 	collectionRequest models.CreateDocumentCollectionRequest,
@@ -845,7 +1036,10 @@ func documentCollectionFromCreateRequest(
 		Description:         collectionRequest.Description,
 		Metadata:            collectionRequest.Metadata,
 		EmbeddingDimensions: collectionRequest.EmbeddingDimensions,
+		EmbeddingService:    collectionRequest.EmbeddingService,
+		EmbeddingModelName:  collectionRequest.EmbeddingModelName,
 		IsAutoEmbedded:      *collectionRequest.IsAutoEmbedded,
+		Chunking:            collectionRequest.Chunking,
 	}
 }
 
@@ -879,11 +1073,13 @@ func collectionToCollectionResponse(
 		Name:                     collection.Name,
 		Description:              collection.Description,
 		Metadata:                 collection.Metadata,
+		EmbeddingService:         collection.EmbeddingService,
 		EmbeddingModelName:       collection.EmbeddingModelName,
 		EmbeddingDimensions:      collection.EmbeddingDimensions,
 		IsAutoEmbedded:           collection.IsAutoEmbedded,
 		IsNormalized:             collection.IsNormalized,
 		IsIndexed:                collection.IsIndexed,
+		Chunking:                 collection.Chunking,
 		DocumentCollectionCounts: counts,
 	}
 }
@@ -960,14 +1156,16 @@ func documentListFromDocumentBatchUpdateRequest(
 // documentResponseFromDocument converts a models.Document to a models.DocumentResponse
 func documentResponseFromDocument(document models.Document) models.DocumentResponse {
 	return models.DocumentResponse{
-		UUID:       document.UUID,
-		CreatedAt:  document.CreatedAt,
-		UpdatedAt:  document.UpdatedAt,
-		DocumentID: document.DocumentID,
-		Content:    document.Content,
-		Metadata:   document.Metadata,
-		Embedding:  document.Embedding,
-		IsEmbedded: document.IsEmbedded,
+		UUID:             document.UUID,
+		CreatedAt:        document.CreatedAt,
+		UpdatedAt:        document.UpdatedAt,
+		DocumentID:       document.DocumentID,
+		Content:          document.Content,
+		Metadata:         document.Metadata,
+		Embedding:        document.Embedding,
+		IsEmbedded:       document.IsEmbedded,
+		ParentDocumentID: document.ParentDocumentID,
+		ChunkIndex:       document.ChunkIndex,
 	}
 }
 
@@ -0,0 +1,221 @@
+package apihandlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/getzep/zep/pkg/chunker"
+	"github.com/getzep/zep/pkg/doctext"
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/server/handlertools"
+)
+
+const (
+	uploadFileField     = "file"
+	uploadMetadataField = "metadata"
+	// uploadMaxMemory is the size of an upload's files that's held in memory. The rest is
+	// written to temporary files. Uploads are limited to server.max_request_size.
+	uploadMaxMemory = 32 << 20
+)
+
+// UploadDocumentsHandler godoc
+//
+//	@Summary		Uploads files to a DocumentCollection
+//	@Description	Extracts the text of PDF, DOCX, Markdown, HTML and plain text files, and chunks it into documents
+//	@Description	that are embedded and indexed in an auto-embedded collection. Files are chunked using the
+//	@Description	collection's chunking, or else Markdown, DOCX and HTML files at their headings and other files
+//	@Description	between sentences. Uploads are limited to server.max_request_size.
+//	@Tags			document
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			collectionName	path		string	true	"Name of the Document Collection"
+//	@Param			file			formData	file	true	"Files to upload"
+//	@Param			metadata		formData	string	false	"JSON object of metadata added to each document"
+//	@Success		200				{array}		models.DocumentUploadResponse
+//	@Failure		400				{object}	APIError	"Bad Request"
+//	@Failure		401				{object}	APIError	"Unauthorized"
+//	@Failure		404				{object}	APIError	"Not Found"
+//	@Failure		500				{object}	APIError	"Internal Server Error"
+//	@Security		Bearer
+//	@Router			/api/v1/collection/{collectionName}/document/upload [post]
+func UploadDocumentsHandler(appState *models.AppState) http.HandlerFunc {
+	store := appState.DocumentStore
+	return func(w http.ResponseWriter, r *http.Request) {
+		collectionName := strings.ToLower(chi.URLParam(r, "collectionName"))
+		if collectionName == "" {
+			handlertools.RenderError(
+				w,
+				errors.New("collectionName is required"),
+				http.StatusBadRequest,
+			)
+			return
+		}
+
+		collection, err := store.GetCollection(r.Context(), collectionName)
+		if err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				handlertools.RenderError(w, err, http.StatusNotFound)
+				return
+			}
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+		if !collection.IsAutoEmbedded {
+			handlertools.RenderError(
+				w,
+				errors.New("files can only be uploaded to auto-embedded collections"),
+				http.StatusBadRequest,
+			)
+			return
+		}
+
+		if err := r.ParseMultipartForm(uploadMaxMemory); err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+		defer r.MultipartForm.RemoveAll() //nolint:errcheck
+
+		files := r.MultipartForm.File[uploadFileField]
+		if len(files) == 0 {
+			handlertools.RenderError(
+				w,
+				fmt.Errorf("missing %s field", uploadFileField),
+				http.StatusBadRequest,
+			)
+			return
+		}
+
+		var metadata map[string]interface{}
+		if value := r.FormValue(uploadMetadataField); value != "" {
+			if err := json.Unmarshal([]byte(value), &metadata); err != nil {
+				handlertools.RenderError(
+					w,
+					fmt.Errorf("invalid %s field: %w", uploadMetadataField, err),
+					http.StatusBadRequest,
+				)
+				return
+			}
+		}
+
+		var documents []models.Document
+		uploads := make([]models.DocumentUploadResponse, len(files))
+		for i, file := range files {
+			chunks, err := chunkUploadedFile(file, metadata, collection.Chunking)
+			if err != nil {
+				handlertools.RenderError(
+					w,
+					fmt.Errorf("failed to read %s: %w", file.Filename, err),
+					http.StatusBadRequest,
+				)
+				return
+			}
+			uploads[i] = models.DocumentUploadResponse{
+				Filename:         file.Filename,
+				ParentDocumentID: chunks[0].ParentDocumentID,
+				ChunkCount:       len(chunks),
+			}
+			documents = append(documents, chunks...)
+		}
+
+		uuids, err := store.CreateDocuments(r.Context(), collectionName, documents)
+		if err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		// documents are created in the order of the files they were chunked from
+		for i := range uploads {
+			uploads[i].UUIDs, uuids = uuids[:uploads[i].ChunkCount], uuids[uploads[i].ChunkCount:]
+		}
+
+		if err := handlertools.EncodeJSON(w, uploads); err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// chunkUploadedFile extracts the text of an uploaded file and chunks it into documents with
+// the given metadata and the file's name
+func chunkUploadedFile(
+	file *multipart.FileHeader,
+	metadata map[string]interface{},
+	chunking *models.DocumentChunking,
+) ([]models.Document, error) {
+	f, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+
+	format, err := doctext.DetectFormat(file.Filename, data)
+	if err != nil {
+		return nil, err
+	}
+	text, err := doctext.Extract(format, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return chunkExtractedText(
+		text,
+		format,
+		"",
+		withMetadata(metadata, map[string]interface{}{"filename": file.Filename}),
+		chunking,
+	)
+}
+
+// chunkExtractedText chunks text extracted from a file of the given format into documents
+// with the given metadata, linked by documentID if it's set. Text is chunked using the
+// collection's chunking if it has one. Otherwise the text of Markdown, DOCX and HTML files,
+// which is extracted as Markdown, is chunked at its headings, and other text between
+// sentences.
+func chunkExtractedText(
+	text string,
+	format string,
+	documentID string,
+	metadata map[string]interface{},
+	chunking *models.DocumentChunking,
+) ([]models.Document, error) {
+	if chunking == nil {
+		chunking = &models.DocumentChunking{Strategy: chunker.StrategySentence}
+		switch format {
+		case doctext.FormatMarkdown, doctext.FormatDOCX, doctext.FormatHTML:
+			chunking.Strategy = chunker.StrategyMarkdown
+		}
+	}
+
+	document := models.Document{}
+	document.DocumentID = documentID
+	document.Content = text
+	document.Metadata = metadata
+
+	return chunker.ChunkDocuments([]models.Document{document}, chunking)
+}
+
+// withMetadata returns a copy of metadata with the given fields added
+func withMetadata(
+	metadata map[string]interface{},
+	fields map[string]interface{},
+) map[string]interface{} {
+	merged := make(map[string]interface{}, len(metadata)+len(fields))
+	for k, v := range metadata {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return merged
+}
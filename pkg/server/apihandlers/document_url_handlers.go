@@ -0,0 +1,168 @@
+package apihandlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/getzep/zep/pkg/doctext"
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/server/handlertools"
+	"github.com/getzep/zep/pkg/webfetch"
+)
+
+// IngestURLHandler godoc
+//
+//	@Summary		Ingests a web page into a DocumentCollection
+//	@Description	Fetches the page at a URL if the site's robots.txt allows it, strips boilerplate such as
+//	@Description	navigation and footers, and chunks its text into documents that are embedded and indexed in
+//	@Description	an auto-embedded collection. HTML, PDF, DOCX, Markdown and plain text pages are supported.
+//	@Description	Pages larger than extractors.documents.url_ingestion.max_size are rejected.
+//	@Tags			document
+//	@Accept			json
+//	@Produce		json
+//	@Param			collectionName	path		string					true	"Name of the Document Collection"
+//	@Param			request			body		models.IngestURLRequest	true	"URL to ingest"
+//	@Success		200				{object}	models.IngestURLResponse
+//	@Failure		400				{object}	APIError	"Bad Request"
+//	@Failure		401				{object}	APIError	"Unauthorized"
+//	@Failure		404				{object}	APIError	"Not Found"
+//	@Failure		500				{object}	APIError	"Internal Server Error"
+//	@Failure		502				{object}	APIError	"Bad Gateway"
+//	@Security		Bearer
+//	@Router			/api/v1/collection/{collectionName}/document/url [post]
+func IngestURLHandler(appState *models.AppState) http.HandlerFunc {
+	store := appState.DocumentStore
+	fetcher := webfetch.NewFetcher(&appState.Config.Extractors.Documents.URLIngestion)
+	return func(w http.ResponseWriter, r *http.Request) {
+		collectionName := strings.ToLower(chi.URLParam(r, "collectionName"))
+		if collectionName == "" {
+			handlertools.RenderError(
+				w,
+				errors.New("collectionName is required"),
+				http.StatusBadRequest,
+			)
+			return
+		}
+
+		var request models.IngestURLRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+		if err := validate.Struct(request); err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		collection, err := store.GetCollection(r.Context(), collectionName)
+		if err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				handlertools.RenderError(w, err, http.StatusNotFound)
+				return
+			}
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+		if !collection.IsAutoEmbedded {
+			handlertools.RenderError(
+				w,
+				errors.New("URLs can only be ingested into auto-embedded collections"),
+				http.StatusBadRequest,
+			)
+			return
+		}
+
+		page, err := fetcher.Fetch(r.Context(), request.URL)
+		if err != nil {
+			status := http.StatusBadGateway
+			for _, target := range []error{
+				webfetch.ErrInvalidURL,
+				webfetch.ErrDisallowed,
+				webfetch.ErrPrivateAddress,
+				webfetch.ErrTooLarge,
+			} {
+				if errors.Is(err, target) {
+					status = http.StatusBadRequest
+				}
+			}
+			handlertools.RenderError(w, err, status)
+			return
+		}
+
+		title, chunks, err := chunkPage(page, request, collection.Chunking)
+		if err != nil {
+			handlertools.RenderError(
+				w,
+				fmt.Errorf("failed to read %s: %w", page.URL, err),
+				http.StatusBadRequest,
+			)
+			return
+		}
+
+		uuids, err := store.CreateDocuments(r.Context(), collectionName, chunks)
+		if err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		response := models.IngestURLResponse{
+			URL:              page.URL,
+			Title:            title,
+			ParentDocumentID: chunks[0].ParentDocumentID,
+			ChunkCount:       len(chunks),
+			UUIDs:            uuids,
+		}
+		if err := handlertools.EncodeJSON(w, response); err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// chunkPage extracts the text of a fetched page and chunks it into documents with the
+// requested metadata and the page's URL and title. The page's title is returned.
+func chunkPage(
+	page *webfetch.Page,
+	request models.IngestURLRequest,
+	chunking *models.DocumentChunking,
+) (string, []models.Document, error) {
+	format, err := doctext.FormatFromMediaType(page.ContentType)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var title, text string
+	if format == doctext.FormatHTML {
+		title, text, err = doctext.ExtractHTML(page.Body)
+		if err == nil && strings.TrimSpace(text) == "" {
+			err = errors.New("no text found in page")
+		}
+	} else {
+		text, err = doctext.Extract(format, page.Body)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	fields := map[string]interface{}{"url": page.URL}
+	if title != "" {
+		fields["title"] = title
+	}
+	chunks, err := chunkExtractedText(
+		text,
+		format,
+		request.DocumentID,
+		withMetadata(request.Metadata, fields),
+		chunking,
+	)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return title, chunks, nil
+}
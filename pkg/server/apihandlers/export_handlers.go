@@ -0,0 +1,198 @@
+package apihandlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/server/handlertools"
+)
+
+const exportSummaryPageSize = 100
+
+// ExportSessionHandler godoc
+//
+//	@Summary		Exports a session with all of its messages and summaries
+//	@Description	Exports as a single JSON object, or as JSONL with one record per line, session first.
+//	@Description	Message embeddings are included if requested.
+//	@Tags			session
+//	@Produce		json
+//	@Produce		application/x-ndjson
+//	@Param			sessionId	path		string	true	"Session ID"
+//	@Param			format		query		string	false	"json (default) or jsonl"
+//	@Param			embeddings	query		boolean	false	"Include message embeddings"
+//	@Success		200			{object}	models.SessionExport
+//	@Failure		400			{object}	APIError	"Bad Request"
+//	@Failure		404			{object}	APIError	"Not Found"
+//	@Failure		500			{object}	APIError	"Internal Server Error"
+//	@Security		Bearer
+//	@Router			/api/v1/sessions/{sessionId}/export [get]
+func ExportSessionHandler(appState *models.AppState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sessionID := chi.URLParam(r, "sessionId")
+		format := models.ExportFormat(r.URL.Query().Get("format"))
+		if format == "" {
+			format = models.ExportFormatJSON
+		}
+		if format != models.ExportFormatJSON && format != models.ExportFormatJSONL {
+			err := fmt.Errorf(
+				"format must be %s or %s",
+				models.ExportFormatJSON,
+				models.ExportFormatJSONL,
+			)
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+		withEmbeddings, err := handlertools.BoolFromQuery(r, "embeddings")
+		if err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		export, err := exportSession(r.Context(), appState, sessionID, withEmbeddings)
+		if err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				handlertools.RenderError(w, fmt.Errorf("not found"), http.StatusNotFound)
+				return
+			}
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		contentType := "application/json"
+		if format == models.ExportFormatJSONL {
+			contentType = "application/x-ndjson"
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", mime.FormatMediaType(
+			"attachment",
+			map[string]string{"filename": sessionID + "." + string(format)},
+		))
+
+		if format == models.ExportFormatJSON {
+			if err := handlertools.EncodeJSON(w, export); err != nil {
+				handlertools.RenderError(w, err, http.StatusInternalServerError)
+			}
+			return
+		}
+
+		if err := writeExportRecords(w, export); err != nil {
+			// the response has already started, so the error can't be rendered
+			log.Errorf("failed to write export of session %s: %v", sessionID, err)
+		}
+	}
+}
+
+// exportSession gets a session and all of its messages and summaries, and optionally its
+// message embeddings.
+func exportSession(
+	ctx context.Context,
+	appState *models.AppState,
+	sessionID string,
+	withEmbeddings bool,
+) (*models.SessionExport, error) {
+	session, err := appState.MemoryStore.GetSession(ctx, appState, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := getAllSessionMessages(ctx, appState, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if messages == nil {
+		messages = []models.Message{}
+	}
+
+	summaries, err := getAllSessionSummaries(ctx, appState, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &models.SessionExport{
+		Session:   session,
+		Messages:  messages,
+		Summaries: summaries,
+	}
+	if withEmbeddings {
+		export.Embeddings, err = appState.MemoryStore.GetMessageEmbeddings(ctx, appState, sessionID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return export, nil
+}
+
+// getAllSessionSummaries pages through all summaries for a session, oldest first.
+func getAllSessionSummaries(
+	ctx context.Context,
+	appState *models.AppState,
+	sessionID string,
+) ([]models.Summary, error) {
+	summaries := []models.Summary{}
+	for page := 1; ; page++ {
+		summaryList, err := appState.MemoryStore.GetSummaryList(
+			ctx,
+			appState,
+			sessionID,
+			page,
+			exportSummaryPageSize,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if summaryList == nil {
+			break
+		}
+		summaries = append(summaries, summaryList.Summaries...)
+		if summaryList.RowCount < exportSummaryPageSize {
+			break
+		}
+	}
+
+	return summaries, nil
+}
+
+// writeExportRecords writes an export as JSONL, one ExportRecord per line
+func writeExportRecords(w http.ResponseWriter, export *models.SessionExport) error {
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(models.ExportRecord{
+		Type: models.ExportRecordSession,
+		Data: export.Session,
+	}); err != nil {
+		return err
+	}
+	for i := range export.Messages {
+		if err := encoder.Encode(models.ExportRecord{
+			Type: models.ExportRecordMessage,
+			Data: &export.Messages[i],
+		}); err != nil {
+			return err
+		}
+	}
+	for i := range export.Summaries {
+		if err := encoder.Encode(models.ExportRecord{
+			Type: models.ExportRecordSummary,
+			Data: &export.Summaries[i],
+		}); err != nil {
+			return err
+		}
+	}
+	for i := range export.Embeddings {
+		if err := encoder.Encode(models.ExportRecord{
+			Type: models.ExportRecordEmbedding,
+			Data: &export.Embeddings[i],
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
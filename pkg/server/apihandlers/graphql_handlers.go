@@ -0,0 +1,43 @@
+package apihandlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/server/graphql"
+	"github.com/getzep/zep/pkg/server/handlertools"
+)
+
+// GraphQLHandler godoc
+//
+//	@Summary		Executes a GraphQL query
+//	@Description	fetch a session with its messages, summaries and memory in a single request.
+//	@Description	Errors in the query and errors resolving fields are returned in errors.
+//	@Tags			graphql
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		graphql.Request		true	"GraphQL request"
+//	@Success		200		{object}	graphql.Response
+//	@Failure		400		{object}	APIError	"Bad Request"
+//	@Security		Bearer
+//	@Router			/api/v1/graphql [post]
+func GraphQLHandler(appState *models.AppState) http.HandlerFunc {
+	schema := graphql.NewSchema(appState)
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request graphql.Request
+		if err := handlertools.DecodeJSON(r, &request); err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+		if request.Query == "" {
+			handlertools.RenderError(w, errors.New("query is required"), http.StatusBadRequest)
+			return
+		}
+
+		if err := handlertools.EncodeJSON(w, schema.Execute(r.Context(), &request)); err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+}
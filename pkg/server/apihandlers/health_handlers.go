@@ -0,0 +1,84 @@
+package apihandlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/server/handlertools"
+)
+
+// ReadinessCheckTimeout bounds each dependency's readiness check
+const ReadinessCheckTimeout = 5 * time.Second
+
+// ReadinessHandler godoc
+//
+//	@Summary		Check readiness
+//	@Description	check the database, its migrations and vector extension, the NLP server and the
+//	@Description	LLM credentials. Returns 503 if any check fails.
+//	@Tags			health
+//	@Produce		json
+//	@Success		200	{object}	models.ReadinessResponse
+//	@Failure		503	{object}	models.ReadinessResponse
+//	@Router			/healthz/ready [get]
+func ReadinessHandler(appState *models.AppState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response := checkReadiness(r.Context(), appState.HealthChecks)
+
+		w.Header().Set("Content-Type", "application/json")
+		if response.Status != models.HealthStatusOK {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		if err := handlertools.EncodeJSON(w, response); err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// checkReadiness runs the checks concurrently. Readiness is ok unless a check fails; skipped
+// checks don't affect it.
+func checkReadiness(ctx context.Context, checks []models.HealthCheck) *models.ReadinessResponse {
+	response := &models.ReadinessResponse{
+		Status:       models.HealthStatusOK,
+		Dependencies: make([]models.DependencyHealth, len(checks)),
+	}
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check models.HealthCheck) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, ReadinessCheckTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := check.Check(ctx)
+			dependency := models.DependencyHealth{
+				Name:      check.Name,
+				Status:    models.HealthStatusOK,
+				LatencyMs: time.Since(start).Milliseconds(),
+			}
+			switch {
+			case errors.Is(err, models.ErrHealthCheckSkipped):
+				dependency.Status = models.HealthStatusSkipped
+			case err != nil:
+				dependency.Status = models.HealthStatusError
+				dependency.Error = err.Error()
+			}
+			response.Dependencies[i] = dependency
+		}(i, check)
+	}
+	wg.Wait()
+
+	for _, dependency := range response.Dependencies {
+		if dependency.Status == models.HealthStatusError {
+			response.Status = models.HealthStatusError
+		}
+	}
+
+	return response
+}
@@ -0,0 +1,99 @@
+package apihandlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/getzep/zep/pkg/importer"
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/server/handlertools"
+)
+
+const importFormField = "file"
+
+// ImportHandler godoc
+//
+//	@Summary		Imports historical conversations from JSONL
+//	@Description	Each line of the uploaded file is a session_id and its messages, as in a batch memory request.
+//	@Description	The file is streamed into the message store and its messages are enriched in batches.
+//	@Description	Progress is reported as server-sent events. Uploads are limited to server.max_request_size.
+//	@Tags			session
+//	@Accept			multipart/form-data
+//	@Produce		text/event-stream
+//	@Param			file	formData	file	true	"JSONL file"
+//	@Success		200		{object}	models.ImportProgress
+//	@Failure		400		{object}	APIError	"Bad Request"
+//	@Failure		500		{object}	APIError	"Internal Server Error"
+//	@Security		Bearer
+//	@Router			/api/v1/import [post]
+func ImportHandler(appState *models.AppState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// The multipart body is read as a stream rather than parsed up front
+		reader, err := r.MultipartReader()
+		if err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+		file, err := nextFilePart(reader)
+		if err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			handlertools.RenderError(
+				w,
+				errors.New("streaming is not supported"),
+				http.StatusInternalServerError,
+			)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+
+		progress, err := importer.Import(
+			r.Context(),
+			appState,
+			file,
+			func(progress *models.ImportProgress) {
+				writeSSEEvent(w, flusher, "progress", progress)
+			},
+		)
+		if err != nil {
+			// the client has gone away. there's no-one to report to
+			if r.Context().Err() != nil {
+				return
+			}
+			progress.Error = err.Error()
+			writeSSEEvent(w, flusher, "error", progress)
+			return
+		}
+
+		writeSSEEvent(w, flusher, "done", progress)
+	}
+}
+
+// nextFilePart returns the part of the multipart body holding the file to import
+func nextFilePart(reader *multipart.Reader) (*multipart.Part, error) {
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("missing %s field", importFormField)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read multipart body: %w", err)
+		}
+		if part.FormName() == importFormField {
+			return part, nil
+		}
+		part.Close()
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/getzep/zep/pkg/models"
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 )
 
 const OKResponse = "OK"
@@ -22,7 +23,9 @@ const OKResponse = "OK"
 //	@Produce		json
 //	@Param			sessionId	path		string	true	"Session ID"
 //	@Param			lastn		query		integer	false	"Last N messages. Overrides memory_window configuration"
+//	@Param			max_tokens	query		integer	false	"Return the most recent messages that fit within this many tokens"
 //	@Success		200			{object}	[]models.Memory
+//	@Failure		400			{object}	APIError	"Bad Request"
 //	@Failure		404			{object}	APIError	"Not Found"
 //	@Failure		500			{object}	APIError	"Internal Server Error"
 //	@Security		Bearer
@@ -35,9 +38,22 @@ func GetMemoryHandler(appState *models.AppState) http.HandlerFunc {
 			handlertools.RenderError(w, err, http.StatusBadRequest)
 			return
 		}
+		maxTokens, err := handlertools.IntFromQuery[int](r, "max_tokens")
+		if err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+		if maxTokens < 0 {
+			handlertools.RenderError(
+				w,
+				models.NewBadRequestError("max_tokens cannot be negative"),
+				http.StatusBadRequest,
+			)
+			return
+		}
 
 		sessionMemory, err := appState.MemoryStore.GetMemory(r.Context(), appState,
-			sessionID, lastN)
+			sessionID, lastN, &models.GetMessagesRequest{MaxTokens: maxTokens})
 		if err != nil {
 			handlertools.RenderError(w, err, http.StatusInternalServerError)
 			return
@@ -54,6 +70,62 @@ func GetMemoryHandler(appState *models.AppState) http.HandlerFunc {
 	}
 }
 
+// GetMessagesHandler godoc
+//
+//	@Summary		Returns messages for a given session
+//	@Description	get a session's messages, optionally filtered by role, with limit and cursor for pagination
+//	@Tags			memory
+//	@Accept			json
+//	@Produce		json
+//	@Param			sessionId	path		string	true	"Session ID"
+//	@Param			limit		query		integer	false	"Limit the number of results returned"
+//	@Param			cursor		query		int64	false	"Cursor for pagination. Use the next_cursor of the previous page"
+//	@Param			role		query		string	false	"Only return messages with this role"
+//	@Param			desc		query		boolean	false	"Return the newest messages first"
+//	@Success		200			{object}	models.MessageListResponse
+//	@Failure		400			{object}	APIError	"Bad Request"
+//	@Failure		500			{object}	APIError	"Internal Server Error"
+//	@Security		Bearer
+//	@Router			/api/v1/sessions/{sessionId}/messages [get]
+func GetMessagesHandler(appState *models.AppState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sessionID := chi.URLParam(r, "sessionId")
+		limit, err := handlertools.IntFromQuery[int](r, "limit")
+		if err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+		cursor, err := handlertools.IntFromQuery[int64](r, "cursor")
+		if err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+		desc, err := handlertools.BoolFromQuery(r, "desc")
+		if err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		messages, err := appState.MemoryStore.ListMessages(
+			r.Context(),
+			appState,
+			sessionID,
+			cursor,
+			limit,
+			&models.MessageListOptions{Role: r.URL.Query().Get("role"), Desc: desc},
+		)
+		if err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		if err := handlertools.EncodeJSON(w, messages); err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
 // GetSessionHandler godoc
 //
 //	@Summary		Returns a session by ID
@@ -215,6 +287,8 @@ func GetSessionListHandler(appState *models.AppState) http.HandlerFunc {
 //	@Param			sessionId		path		string			true	"Session ID"
 //	@Param			memoryMessages	body		models.Memory	true	"Memory messages"
 //	@Success		200				{string}	string			"OK"
+//	@Failure		400				{object}	APIError		"Bad Request"
+//	@Failure		404				{object}	APIError		"Not Found"
 //	@Failure		500				{object}	APIError		"Internal Server Error"
 //	@Security		Bearer
 //	@Router			/api/v1/sessions/{sessionId}/memory [post]
@@ -234,6 +308,10 @@ func PostMemoryHandler(appState *models.AppState) http.HandlerFunc {
 			&memoryMessages,
 			false,
 		); err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				handlertools.RenderError(w, fmt.Errorf("not found"), http.StatusNotFound)
+				return
+			}
 			handlertools.RenderError(w, err, http.StatusInternalServerError)
 			return
 		}
@@ -241,6 +319,72 @@ func PostMemoryHandler(appState *models.AppState) http.HandlerFunc {
 	}
 }
 
+// BatchPostMemoryHandler godoc
+//
+//	@Summary		Add memory messages to many sessions
+//	@Description	add memory messages to up to 100 sessions. Each session is stored in its own
+//	@Description	transaction. Failures are reported per session, and don't fail the request.
+//	@Tags			memory
+//	@Accept			json
+//	@Produce		json
+//	@Param			memory	body		models.BatchMemoryRequest	true	"Sessions' memory"
+//	@Success		200		{object}	models.BatchMemoryResponse
+//	@Failure		400		{object}	APIError	"Bad Request"
+//	@Security		Bearer
+//	@Router			/api/v1/sessions/memory [post]
+func BatchPostMemoryHandler(appState *models.AppState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request models.BatchMemoryRequest
+		if err := handlertools.DecodeJSON(r, &request); err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+		if err := validate.Struct(request); err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		response := models.BatchMemoryResponse{
+			Results: make([]models.BatchMemoryResult, len(request.Sessions)),
+		}
+		for i := range request.Sessions {
+			session := &request.Sessions[i]
+			result := models.BatchMemoryResult{SessionID: session.SessionID, Status: http.StatusOK}
+			err := appState.MemoryStore.PutMemory(
+				r.Context(),
+				appState,
+				session.SessionID,
+				&session.Memory,
+				false,
+			)
+			if err != nil {
+				result.Status, result.Error = batchErrorStatus(err), err.Error()
+				response.FailedCount++
+			}
+			response.Results[i] = result
+		}
+
+		if err := handlertools.EncodeJSON(w, response); err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// batchErrorStatus returns the HTTP status for an error storing one session of a batch
+func batchErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, models.ErrBadRequest):
+		return http.StatusBadRequest
+	case errors.Is(err, models.ErrSessionQuotaExceeded):
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 // DeleteMemoryHandler godoc
 //
 //	@Summary		Delete memory messages for a given session
@@ -270,6 +414,261 @@ func DeleteMemoryHandler(appState *models.AppState) http.HandlerFunc {
 	}
 }
 
+// RestoreSessionHandler godoc
+//
+//	@Summary		Restores a deleted session
+//	@Description	restore a deleted session by id, along with the messages and summaries
+//	@Description	deleted with it
+//	@Tags			session
+//	@Accept			json
+//	@Produce		json
+//	@Param			sessionId	path		string	true	"Session ID"
+//	@Success		200			{object}	models.Session
+//	@Failure		400			{object}	APIError	"Bad Request"
+//	@Failure		404			{object}	APIError	"Not Found"
+//	@Failure		500			{object}	APIError	"Internal Server Error"
+//	@Security		Bearer
+//	@Router			/api/v1/sessions/{sessionId}/restore [post]
+func RestoreSessionHandler(appState *models.AppState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sessionID := chi.URLParam(r, "sessionId")
+
+		session, err := appState.MemoryStore.RestoreSession(r.Context(), appState, sessionID)
+		if err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				handlertools.RenderError(w, fmt.Errorf("not found"), http.StatusNotFound)
+				return
+			}
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		if err := handlertools.EncodeJSON(w, session); err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// MergeSessionsHandler godoc
+//
+//	@Summary		Merges a session into another
+//	@Description	move the messages of the source session to the session, merging their metadata
+//	@Description	and recomputing the session's summary. The source session is deleted.
+//	@Tags			session
+//	@Accept			json
+//	@Produce		json
+//	@Param			sessionId	path		string						true	"Session ID"
+//	@Param			request		body		models.MergeSessionsRequest	true	"Session to merge"
+//	@Success		200			{object}	models.Session
+//	@Failure		400			{object}	APIError	"Bad Request"
+//	@Failure		404			{object}	APIError	"Not Found"
+//	@Failure		500			{object}	APIError	"Internal Server Error"
+//	@Security		Bearer
+//	@Router			/api/v1/sessions/{sessionId}/merge [post]
+func MergeSessionsHandler(appState *models.AppState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sessionID := chi.URLParam(r, "sessionId")
+
+		var request models.MergeSessionsRequest
+		if err := handlertools.DecodeJSON(r, &request); err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		session, err := appState.MemoryStore.MergeSessions(
+			r.Context(),
+			appState,
+			sessionID,
+			&request,
+		)
+		if err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				handlertools.RenderError(w, fmt.Errorf("not found"), http.StatusNotFound)
+				return
+			}
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		if err := handlertools.EncodeJSON(w, session); err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// ForkSessionHandler godoc
+//
+//	@Summary		Forks a session
+//	@Description	create a session with copies of the session's messages up to and including a
+//	@Description	message, along with their embeddings and summaries
+//	@Tags			session
+//	@Accept			json
+//	@Produce		json
+//	@Param			sessionId	path		string						true	"Session ID"
+//	@Param			request		body		models.ForkSessionRequest	true	"Session to create"
+//	@Success		201			{object}	models.Session
+//	@Failure		400			{object}	APIError	"Bad Request"
+//	@Failure		404			{object}	APIError	"Not Found"
+//	@Failure		500			{object}	APIError	"Internal Server Error"
+//	@Security		Bearer
+//	@Router			/api/v1/sessions/{sessionId}/fork [post]
+func ForkSessionHandler(appState *models.AppState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sessionID := chi.URLParam(r, "sessionId")
+
+		var request models.ForkSessionRequest
+		if err := handlertools.DecodeJSON(r, &request); err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		session, err := appState.MemoryStore.ForkSession(r.Context(), sessionID, &request)
+		if err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				handlertools.RenderError(w, fmt.Errorf("not found"), http.StatusNotFound)
+				return
+			}
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		if err := handlertools.EncodeJSON(w, session); err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// PurgeSessionHandler godoc
+//
+//	@Summary		Permanently deletes a session
+//	@Description	hard delete a session by id, along with all of its records, whether or not
+//	@Description	the session has been deleted. Purged sessions can't be restored.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			sessionId	path		string		true	"Session ID"
+//	@Success		200			{string}	string		"OK"
+//	@Failure		404			{object}	APIError	"Not Found"
+//	@Failure		500			{object}	APIError	"Internal Server Error"
+//	@Security		Bearer
+//	@Router			/api/v1/admin/sessions/{sessionId} [delete]
+func PurgeSessionHandler(appState *models.AppState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sessionID := chi.URLParam(r, "sessionId")
+
+		if err := appState.MemoryStore.PurgeSession(r.Context(), sessionID); err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				handlertools.RenderError(w, fmt.Errorf("not found"), http.StatusNotFound)
+				return
+			}
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(OKResponse))
+	}
+}
+
+// UpdateMessageHandler godoc
+//
+//	@Summary		Updates the content of a message
+//	@Description	update a message's content by session id and message uuid. The message is
+//	@Description	embedded again, and summaries including it are regenerated.
+//	@Tags			memory
+//	@Accept			json
+//	@Produce		json
+//	@Param			sessionId	path		string						true	"Session ID"
+//	@Param			messageId	path		string						true	"Message UUID"
+//	@Param			message		body		models.UpdateMessageRequest	true	"Message content"
+//	@Success		200			{object}	models.Message
+//	@Failure		400			{object}	APIError	"Bad Request"
+//	@Failure		404			{object}	APIError	"Not Found"
+//	@Failure		500			{object}	APIError	"Internal Server Error"
+//	@Security		Bearer
+//	@Router			/api/v1/sessions/{sessionId}/messages/{messageId} [patch]
+func UpdateMessageHandler(appState *models.AppState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sessionID := chi.URLParam(r, "sessionId")
+		messageUUID := handlertools.UUIDFromURL(r, w, "messageId")
+		if messageUUID == uuid.Nil {
+			return
+		}
+
+		var request models.UpdateMessageRequest
+		if err := handlertools.DecodeJSON(r, &request); err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+		if request.Content == "" {
+			handlertools.RenderError(
+				w,
+				errors.New("content is required"),
+				http.StatusBadRequest,
+			)
+			return
+		}
+
+		message, err := appState.MemoryStore.UpdateMessageContent(
+			r.Context(),
+			appState,
+			sessionID,
+			messageUUID,
+			request.Content,
+		)
+		if err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				handlertools.RenderError(w, fmt.Errorf("not found"), http.StatusNotFound)
+				return
+			}
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+		if err := handlertools.EncodeJSON(w, message); err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// DeleteMessageHandler godoc
+//
+//	@Summary		Deletes a message
+//	@Description	delete a message and its embedding by session id and message uuid
+//	@Tags			memory
+//	@Accept			json
+//	@Produce		json
+//	@Param			sessionId	path		string		true	"Session ID"
+//	@Param			messageId	path		string		true	"Message UUID"
+//	@Success		200			{string}	string		"OK"
+//	@Failure		400			{object}	APIError	"Bad Request"
+//	@Failure		404			{object}	APIError	"Not Found"
+//	@Failure		500			{object}	APIError	"Internal Server Error"
+//	@Security		Bearer
+//	@Router			/api/v1/sessions/{sessionId}/messages/{messageId} [delete]
+func DeleteMessageHandler(appState *models.AppState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sessionID := chi.URLParam(r, "sessionId")
+		messageUUID := handlertools.UUIDFromURL(r, w, "messageId")
+		if messageUUID == uuid.Nil {
+			return
+		}
+
+		err := appState.MemoryStore.DeleteMessage(r.Context(), sessionID, messageUUID)
+		if err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				handlertools.RenderError(w, fmt.Errorf("not found"), http.StatusNotFound)
+				return
+			}
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(OKResponse))
+	}
+}
+
 // SearchMemoryHandler godoc
 //
 //	@Summary		Search memory messages for a given session
@@ -0,0 +1,64 @@
+package apihandlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/qa"
+	"github.com/getzep/zep/pkg/server/handlertools"
+)
+
+// SessionQAHandler godoc
+//
+//	@Summary		Answers a question from a session's memory
+//	@Description	Retrieves the session's messages most relevant to the question and, if collection_names
+//	@Description	or tag are set, the most relevant documents of those collections. The configured LLM answers
+//	@Description	the question from them, citing them by number, and the cited messages and documents are
+//	@Description	returned with the answer.
+//	@Tags			memory
+//	@Accept			json
+//	@Produce		json
+//	@Param			sessionId	path		string				true	"Session ID"
+//	@Param			request		body		models.QARequest	true	"Question"
+//	@Success		200			{object}	models.QAResponse
+//	@Failure		400			{object}	APIError	"Bad Request"
+//	@Failure		404			{object}	APIError	"Not Found"
+//	@Failure		500			{object}	APIError	"Internal Server Error"
+//	@Security		Bearer
+//	@Router			/api/v1/sessions/{sessionId}/qa [post]
+func SessionQAHandler(appState *models.AppState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sessionID := chi.URLParam(r, "sessionId")
+
+		var request models.QARequest
+		if err := handlertools.DecodeJSON(r, &request); err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+		if err := validate.Struct(request); err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		response, err := qa.Answer(r.Context(), appState, sessionID, &request)
+		if err != nil {
+			switch {
+			case errors.Is(err, models.ErrNotFound):
+				handlertools.RenderError(w, err, http.StatusNotFound)
+			case errors.Is(err, models.ErrBadRequest):
+				handlertools.RenderError(w, err, http.StatusBadRequest)
+			default:
+				handlertools.RenderError(w, err, http.StatusInternalServerError)
+			}
+			return
+		}
+
+		if err := handlertools.EncodeJSON(w, response); err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+}
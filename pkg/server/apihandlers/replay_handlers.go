@@ -0,0 +1,306 @@
+package apihandlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/getzep/zep/internal"
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/server/handlertools"
+	"github.com/getzep/zep/pkg/webfetch"
+	"github.com/google/uuid"
+)
+
+var log = internal.GetLogger()
+
+const (
+	replayPageSize        = 100
+	replayRequestTimeout  = 30 * time.Second
+	replayMaxResponseSize = 1 << 20 // 1MB
+	replayDefaultRole     = "assistant"
+)
+
+// NewSessionReplayHandler godoc
+//
+//	@Summary		Replays a session's messages to an external endpoint
+//	@Description	Each message in the session is POSTed to target_url, waiting delay_ms between messages.
+//	@Description	The messages and the endpoint's responses are stored in a clone of the session.
+//	@Description	Progress is reported as server-sent events.
+//	@Description	Target URLs on private networks are refused unless server.replay.allow_private_networks is set.
+//	@Tags			session
+//	@Accept			json
+//	@Produce		text/event-stream
+//	@Param			replay	body		models.SessionReplayRequest	true	"Replay"
+//	@Success		200		{object}	models.SessionReplayEvent
+//	@Failure		400		{object}	APIError	"Bad Request"
+//	@Failure		404		{object}	APIError	"Not Found"
+//	@Failure		500		{object}	APIError	"Internal Server Error"
+//	@Security		Bearer
+//	@Router			/api/v1/sessions/replay [post]
+func NewSessionReplayHandler(appState *models.AppState) http.Handler {
+	dialer := &net.Dialer{Timeout: replayRequestTimeout}
+	if !appState.Config.Server.Replay.AllowPrivateNetworks {
+		dialer.Control = webfetch.DenyPrivateNetworks
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	client := &http.Client{Transport: transport, Timeout: replayRequestTimeout}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var replay models.SessionReplayRequest
+		if err := handlertools.DecodeJSON(r, &replay); err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+		if err := validateReplayRequest(&replay); err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			handlertools.RenderError(
+				w,
+				errors.New("streaming is not supported"),
+				http.StatusInternalServerError,
+			)
+			return
+		}
+
+		ctx := r.Context()
+		session, err := appState.MemoryStore.GetSession(ctx, appState, replay.SessionID)
+		if err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				handlertools.RenderError(w, err, http.StatusNotFound)
+				return
+			}
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		messages, err := getAllSessionMessages(ctx, appState, replay.SessionID)
+		if err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		clone, err := appState.MemoryStore.CreateSession(ctx, appState, &models.CreateSessionRequest{
+			SessionID: fmt.Sprintf("%s-replay-%d", replay.SessionID, time.Now().UnixNano()),
+			UserID:    session.UserID,
+			Language:  session.Language,
+			Metadata: map[string]interface{}{
+				"replay_of":         replay.SessionID,
+				"replay_target_url": replay.TargetURL,
+			},
+		})
+		if err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+
+		event := models.SessionReplayEvent{
+			CloneSessionID: clone.SessionID,
+			Total:          len(messages),
+		}
+		delay := time.Duration(replay.DelayMS) * time.Millisecond
+		for i := range messages {
+			if i > 0 && delay > 0 {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(delay):
+				}
+			}
+
+			err := replayMessage(
+				ctx,
+				appState,
+				client,
+				replay.TargetURL,
+				clone.SessionID,
+				&messages[i],
+			)
+			if err != nil {
+				// the client has gone away. there's no-one to report to
+				if ctx.Err() != nil {
+					return
+				}
+				event.Error = err.Error()
+				writeSSEEvent(w, flusher, "error", &event)
+				return
+			}
+
+			event.Replayed = i + 1
+			writeSSEEvent(w, flusher, "progress", &event)
+		}
+
+		writeSSEEvent(w, flusher, "done", &event)
+	})
+}
+
+func validateReplayRequest(replay *models.SessionReplayRequest) error {
+	if replay.SessionID == "" {
+		return errors.New("session_id is required")
+	}
+	if replay.DelayMS < 0 {
+		return errors.New("delay_ms must not be negative")
+	}
+	target, err := url.Parse(replay.TargetURL)
+	if err != nil {
+		return fmt.Errorf("invalid target_url: %w", err)
+	}
+	if (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return errors.New("target_url must be an absolute http or https URL")
+	}
+
+	return nil
+}
+
+// getAllSessionMessages pages through all messages for a session, oldest first.
+func getAllSessionMessages(
+	ctx context.Context,
+	appState *models.AppState,
+	sessionID string,
+) ([]models.Message, error) {
+	var messages []models.Message
+	var cursor int64
+	for {
+		messageList, err := appState.MemoryStore.ListMessages(
+			ctx,
+			appState,
+			sessionID,
+			cursor,
+			replayPageSize,
+			nil,
+		)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, messageList.Messages...)
+		if messageList.NextCursor == 0 {
+			break
+		}
+		cursor = messageList.NextCursor
+	}
+
+	return messages, nil
+}
+
+// replayMessage POSTs a message to the target URL and stores the message and the
+// target's response in the clone session.
+func replayMessage(
+	ctx context.Context,
+	appState *models.AppState,
+	client *http.Client,
+	targetURL string,
+	cloneSessionID string,
+	message *models.Message,
+) error {
+	// system metadata belongs to the original message's enrichment and is regenerated
+	// for the clone
+	metadata := make(map[string]interface{}, len(message.Metadata))
+	for k, v := range message.Metadata {
+		if k != "system" {
+			metadata[k] = v
+		}
+	}
+	request := models.Message{
+		Role:     message.Role,
+		Content:  message.Content,
+		Metadata: metadata,
+	}
+
+	reply, err := postReplayMessage(ctx, client, targetURL, &request)
+	if err != nil {
+		return err
+	}
+
+	memory := models.Memory{Messages: []models.Message{request, *reply}}
+	err = appState.MemoryStore.PutMemory(ctx, appState, cloneSessionID, &memory, false)
+	if err != nil {
+		return fmt.Errorf("failed to store replayed messages: %w", err)
+	}
+
+	return nil
+}
+
+// postReplayMessage POSTs a message to the target URL. A JSON response in the shape of a
+// Message is used as-is. Any other response body is used as the content of the reply.
+func postReplayMessage(
+	ctx context.Context,
+	client *http.Client,
+	targetURL string,
+	message *models.Message,
+) (*models.Message, error) {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		targetURL,
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to post message to target: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, replayMaxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read target response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("target returned status %d: %s", resp.StatusCode, respBody)
+	}
+
+	reply := models.Message{}
+	if err := json.Unmarshal(respBody, &reply); err != nil || reply.Content == "" {
+		reply = models.Message{Content: string(respBody)}
+	}
+	reply.UUID = uuid.Nil
+	if reply.Role == "" {
+		reply.Role = replayDefaultRole
+	}
+
+	return &reply, nil
+}
+
+func writeSSEEvent(
+	w io.Writer,
+	flusher http.Flusher,
+	name string,
+	event interface{},
+) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Errorf("failed to marshal %s event: %v", name, err)
+		return
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, data); err != nil {
+		log.Errorf("failed to write %s event: %v", name, err)
+		return
+	}
+	flusher.Flush()
+}
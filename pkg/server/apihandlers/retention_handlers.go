@@ -0,0 +1,56 @@
+package apihandlers
+
+import (
+	"net/http"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/server/handlertools"
+)
+
+// ListRetentionAuditsHandler godoc
+//
+//	@Summary		List retention audit records
+//	@Description	list the records of the retention rules' applications, and the records they
+//	@Description	purged or anonymized, oldest first, with cursor pagination
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			rule	query		string	false	"Only return the records of this retention rule"
+//	@Param			limit	query		integer	false	"Limit the number of results returned"
+//	@Param			cursor	query		int64	false	"Cursor for pagination. Use the next_cursor of the previous page"
+//	@Success		200		{object}	models.RetentionAuditListResponse
+//	@Failure		400		{object}	APIError	"Bad Request"
+//	@Failure		500		{object}	APIError	"Internal Server Error"
+//	@Security		Bearer
+//	@Router			/api/v1/admin/retention/audits [get]
+func ListRetentionAuditsHandler(appState *models.AppState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit, err := handlertools.IntFromQuery[int](r, "limit")
+		if err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		cursor, err := handlertools.IntFromQuery[int64](r, "cursor")
+		if err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		audits, err := appState.MemoryStore.ListRetentionAudits(
+			r.Context(),
+			r.URL.Query().Get("rule"),
+			cursor,
+			limit,
+		)
+		if err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		if err := handlertools.EncodeJSON(w, audits); err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+}
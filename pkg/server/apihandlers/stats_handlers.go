@@ -0,0 +1,108 @@
+package apihandlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/server/handlertools"
+)
+
+const (
+	// DefaultIngestionRateGranularity is the granularity of the ingestion rate if none is given
+	DefaultIngestionRateGranularity = "day"
+	// DefaultIngestionRateBuckets is the number of ingestion rate buckets if none is given
+	DefaultIngestionRateBuckets = 30
+)
+
+// GetStatsHandler godoc
+//
+//	@Summary		Get deployment statistics
+//	@Description	get the number of sessions and messages, the tokens of the messages, and the
+//	@Description	backlog of embeddings and summaries of the deployment
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	models.DeploymentStats
+//	@Failure		500	{object}	APIError	"Internal Server Error"
+//	@Security		Bearer
+//	@Router			/api/v1/admin/stats [get]
+func GetStatsHandler(appState *models.AppState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if appState.StatsStore == nil {
+			handlertools.RenderError(
+				w,
+				errors.New("stats are not available"),
+				http.StatusInternalServerError,
+			)
+			return
+		}
+
+		stats, err := appState.StatsStore.GetDeploymentStats(r.Context())
+		if err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		if err := handlertools.EncodeJSON(w, stats); err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// GetIngestionRateHandler godoc
+//
+//	@Summary		Get ingestion rates
+//	@Description	get the number of sessions and messages created per minute, hour or day, most
+//	@Description	recent first. Deleted sessions and messages are included.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			granularity	query		string	false	"minute, hour or day. Defaults to day"
+//	@Param			last		query		integer	false	"Number of buckets to return. Defaults to 30"
+//	@Success		200			{object}	models.IngestionRate
+//	@Failure		400			{object}	APIError	"Bad Request"
+//	@Failure		500			{object}	APIError	"Internal Server Error"
+//	@Security		Bearer
+//	@Router			/api/v1/admin/stats/ingestion [get]
+func GetIngestionRateHandler(appState *models.AppState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if appState.StatsStore == nil {
+			handlertools.RenderError(
+				w,
+				errors.New("stats are not available"),
+				http.StatusInternalServerError,
+			)
+			return
+		}
+
+		granularity := r.URL.Query().Get("granularity")
+		if granularity == "" {
+			granularity = DefaultIngestionRateGranularity
+		}
+		last, err := handlertools.IntFromQuery[int](r, "last")
+		if err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+		if last == 0 {
+			last = DefaultIngestionRateBuckets
+		}
+
+		rate, err := appState.StatsStore.GetIngestionRate(r.Context(), granularity, last)
+		if err != nil {
+			if errors.Is(err, models.ErrBadRequest) {
+				handlertools.RenderError(w, err, http.StatusBadRequest)
+				return
+			}
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		if err := handlertools.EncodeJSON(w, rate); err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package apihandlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/net/websocket"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/server/handlertools"
+)
+
+// SessionStreamHandler godoc
+//
+//	@Summary		Streams a session's events over a WebSocket
+//	@Description	new messages, summaries and extractor results are sent as JSON when stored.
+//	@Description	Browsers can't set headers on WebSockets, so may authenticate with a jwt cookie.
+//	@Tags			session
+//	@Produce		json
+//	@Param			sessionId	path		string	true	"Session ID"
+//	@Success		101			{object}	models.SessionEvent
+//	@Failure		404			{object}	APIError	"Not Found"
+//	@Failure		500			{object}	APIError	"Internal Server Error"
+//	@Security		Bearer
+//	@Router			/api/v1/sessions/{sessionId}/stream [get]
+func SessionStreamHandler(appState *models.AppState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sessionID := chi.URLParam(r, "sessionId")
+		if appState.SessionEvents == nil {
+			handlertools.RenderError(
+				w,
+				errors.New("session streams are not enabled"),
+				http.StatusInternalServerError,
+			)
+			return
+		}
+
+		if _, err := appState.MemoryStore.GetSession(r.Context(), appState, sessionID); err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				handlertools.RenderError(w, fmt.Errorf("not found"), http.StatusNotFound)
+				return
+			}
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		server := websocket.Server{
+			// Unlike the default handshake, allow clients that don't send an Origin header,
+			// as only browsers do
+			Handshake: func(config *websocket.Config, r *http.Request) (err error) {
+				config.Origin, err = websocket.Origin(config, r)
+				return err
+			},
+			Handler: func(ws *websocket.Conn) {
+				streamSessionEvents(ws, appState, sessionID)
+			},
+		}
+		server.ServeHTTP(w, r)
+	}
+}
+
+// streamSessionEvents sends the session's events to ws until the client disconnects
+func streamSessionEvents(ws *websocket.Conn, appState *models.AppState, sessionID string) {
+	events, unsubscribe := appState.SessionEvents.Subscribe(sessionID)
+	defer unsubscribe()
+
+	// Clients aren't expected to send anything. Reading detects when they disconnect.
+	disconnected := make(chan struct{})
+	go func() {
+		defer close(disconnected)
+		var discard []byte
+		for {
+			if err := websocket.Message.Receive(ws, &discard); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-disconnected:
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := websocket.JSON.Send(ws, event); err != nil {
+				log.Debugf("failed to send event to stream of session %s: %v", sessionID, err)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package apihandlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/server/handlertools"
+	"github.com/getzep/zep/pkg/tasks"
+)
+
+// RegenerateSummaryHandler godoc
+//
+//	@Summary		Regenerates the summary of a given session
+//	@Description	recompute a session's summary over all of its messages, replacing its existing
+//	@Description	summaries. useful after changing the summarizer prompt or model
+//	@Tags			memory
+//	@Accept			json
+//	@Produce		json
+//	@Param			sessionId	path		string	true	"Session ID"
+//	@Success		200			{object}	models.Summary
+//	@Failure		400			{object}	APIError	"Bad Request"
+//	@Failure		404			{object}	APIError	"Not Found"
+//	@Failure		500			{object}	APIError	"Internal Server Error"
+//	@Security		Bearer
+//	@Router			/api/v1/sessions/{sessionId}/summary/regenerate [post]
+func RegenerateSummaryHandler(appState *models.AppState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sessionID := chi.URLParam(r, "sessionId")
+
+		_, err := appState.MemoryStore.GetSession(r.Context(), appState, sessionID)
+		if err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				handlertools.RenderError(w, fmt.Errorf("not found"), http.StatusNotFound)
+				return
+			}
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		messages, err := getAllSessionMessages(r.Context(), appState, sessionID)
+		if err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		err = tasks.RegenerateSummary(r.Context(), appState, sessionID, messages)
+		if err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		summary, err := appState.MemoryStore.GetSummary(r.Context(), appState, sessionID)
+		if err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		if err := handlertools.EncodeJSON(w, summary); err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+const (
+	DefaultSummaryListPage  = 1
+	DefaultSummaryListLimit = 100
+)
+
+// GetSummaryListHandler godoc
+//
+//	@Summary		Returns the summaries of a given session
+//	@Description	get all of a session's summaries, oldest first, with page and limit for
+//	@Description	pagination
+//	@Tags			memory
+//	@Accept			json
+//	@Produce		json
+//	@Param			sessionId	path		string	true	"Session ID"
+//	@Param			page		query		integer	false	"Page number, starting from 1"
+//	@Param			limit		query		integer	false	"Limit the number of results returned"
+//	@Success		200			{object}	models.SummaryListResponse
+//	@Failure		400			{object}	APIError	"Bad Request"
+//	@Failure		404			{object}	APIError	"Not Found"
+//	@Failure		500			{object}	APIError	"Internal Server Error"
+//	@Security		Bearer
+//	@Router			/api/v1/sessions/{sessionId}/summaries [get]
+func GetSummaryListHandler(appState *models.AppState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sessionID := chi.URLParam(r, "sessionId")
+		page, err := handlertools.IntFromQuery[int](r, "page")
+		if err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+		limit, err := handlertools.IntFromQuery[int](r, "limit")
+		if err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+		if page == 0 {
+			page = DefaultSummaryListPage
+		}
+		if limit == 0 {
+			limit = DefaultSummaryListLimit
+		}
+		if page < 0 || limit < 0 {
+			handlertools.RenderError(
+				w,
+				models.NewBadRequestError("page and limit must be positive"),
+				http.StatusBadRequest,
+			)
+			return
+		}
+
+		_, err = appState.MemoryStore.GetSession(r.Context(), appState, sessionID)
+		if err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				handlertools.RenderError(w, fmt.Errorf("not found"), http.StatusNotFound)
+				return
+			}
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		summaries, err := appState.MemoryStore.GetSummaryList(
+			r.Context(),
+			appState,
+			sessionID,
+			page,
+			limit,
+		)
+		if err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		if err := handlertools.EncodeJSON(w, summaries); err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+}
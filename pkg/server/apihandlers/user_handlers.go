@@ -24,6 +24,7 @@ import (
 //	@Failure		500		{object}	APIError	"Internal Server Error"
 //	@Security		Bearer
 //	@Router			/api/v1/user [post]
+//	@Router			/api/v1/users [post]
 func CreateUserHandler(appState *models.AppState) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var user models.CreateUserRequest
@@ -59,6 +60,7 @@ func CreateUserHandler(appState *models.AppState) http.HandlerFunc {
 //	@Failure		500		{object}	APIError	"Internal Server Error"
 //	@Security		Bearer
 //	@Router			/api/v1/user/{userId} [get]
+//	@Router			/api/v1/users/{userId} [get]
 func GetUserHandler(appState *models.AppState) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		userId := chi.URLParam(r, "userId")
@@ -95,6 +97,7 @@ func GetUserHandler(appState *models.AppState) http.HandlerFunc {
 //	@Failure		500		{object}	APIError	"Internal Server Error"
 //	@Security		Bearer
 //	@Router			/api/v1/user/{userId} [patch]
+//	@Router			/api/v1/users/{userId} [patch]
 func UpdateUserHandler(appState *models.AppState) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		userID := chi.URLParam(r, "userId")
@@ -136,6 +139,7 @@ func UpdateUserHandler(appState *models.AppState) http.HandlerFunc {
 //	@Failure		500		{object}	APIError	"Internal Server Error"
 //	@Security		Bearer
 //	@Router			/api/v1/user/{userId} [delete]
+//	@Router			/api/v1/users/{userId} [delete]
 func DeleteUserHandler(appState *models.AppState) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		userID := chi.URLParam(r, "userId")
@@ -153,6 +157,42 @@ func DeleteUserHandler(appState *models.AppState) http.HandlerFunc {
 	}
 }
 
+// EraseUserDataHandler godoc
+//
+//	@Summary		Erase a user's data
+//	@Description	securely hard delete a user and all of their sessions' messages, embeddings,
+//	@Description	summaries and extracted metadata, returning a report of the records erased
+//	@Tags			user
+//	@Accept			json
+//	@Produce		json
+//	@Param			userId	path		string							true	"User ID"
+//	@Success		200		{object}	models.UserDataErasureReport	"Erasure report"
+//	@Failure		404		{object}	APIError						"Not Found"
+//	@Failure		500		{object}	APIError						"Internal Server Error"
+//	@Security		Bearer
+//	@Router			/api/v1/user/{userId}/data [delete]
+//	@Router			/api/v1/users/{userId}/data [delete]
+func EraseUserDataHandler(appState *models.AppState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := chi.URLParam(r, "userId")
+
+		report, err := appState.MemoryStore.EraseUserData(r.Context(), userID)
+		if err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				handlertools.RenderError(w, fmt.Errorf("not found"), http.StatusNotFound)
+				return
+			}
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		if err := handlertools.EncodeJSON(w, report); err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
 // ListAllUsersHandler godoc
 //
 //	@Summary		List all users
@@ -167,6 +207,7 @@ func DeleteUserHandler(appState *models.AppState) http.HandlerFunc {
 //	@Failure		500		{object}	APIError		"Internal Server Error"
 //	@Security		Bearer
 //	@Router			/api/v1/user [get]
+//	@Router			/api/v1/users [get]
 func ListAllUsersHandler(appState *models.AppState) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		limit, err := handlertools.IntFromQuery[int](r, "limit")
@@ -206,6 +247,7 @@ func ListAllUsersHandler(appState *models.AppState) http.HandlerFunc {
 //	@Failure		500		{object}	APIError	"Internal Server Error"
 //	@Security		Bearer
 //	@Router			/api/v1/user/{userId}/sessions [get]
+//	@Router			/api/v1/users/{userId}/sessions [get]
 func ListUserSessionsHandler(appState *models.AppState) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		userID := chi.URLParam(r, "userId")
@@ -222,3 +264,210 @@ func ListUserSessionsHandler(appState *models.AppState) http.HandlerFunc {
 		}
 	}
 }
+
+// SearchUserMemoryHandler godoc
+//
+//	@Summary		Search memory across all sessions for a user
+//	@Description	search memory messages across all of a user's sessions, grouped by session
+//	@Tags			user
+//	@Accept			json
+//	@Produce		json
+//	@Param			userId			path		string						true	"User ID"
+//	@Param			limit			query		integer						false	"Limit the number of results returned"
+//	@Param			searchPayload	body		models.MemorySearchPayload	true	"Search query"
+//	@Success		200				{object}	[]models.UserMemorySearchResult
+//	@Failure		404				{object}	APIError	"Not Found"
+//	@Failure		500				{object}	APIError	"Internal Server Error"
+//	@Security		Bearer
+//	@Router			/api/v1/user/{userId}/search [post]
+//	@Router			/api/v1/users/{userId}/search [post]
+func SearchUserMemoryHandler(appState *models.AppState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := chi.URLParam(r, "userId")
+		var payload models.MemorySearchPayload
+		if err := handlertools.DecodeJSON(r, &payload); err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+		limit, err := handlertools.IntFromQuery[int](r, "limit")
+		if err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		searchResult, err := appState.MemoryStore.SearchUserMemory(
+			r.Context(),
+			appState,
+			userID,
+			&payload,
+			limit,
+		)
+		if err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				handlertools.RenderError(w, fmt.Errorf("not found"), http.StatusNotFound)
+				return
+			}
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+		if err := handlertools.EncodeJSON(w, searchResult); err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// GetUserFactsHandler godoc
+//
+//	@Summary		List the facts about a user
+//	@Description	list the facts extracted from a user's sessions, most recently updated first
+//	@Tags			user
+//	@Accept			json
+//	@Produce		json
+//	@Param			userId	path		string	true	"User ID"
+//	@Param			limit	query		integer	false	"Limit the number of facts returned"
+//	@Success		200		{object}	models.FactListResponse
+//	@Failure		400		{object}	APIError	"Bad Request"
+//	@Failure		404		{object}	APIError	"Not Found"
+//	@Failure		500		{object}	APIError	"Internal Server Error"
+//	@Security		Bearer
+//	@Router			/api/v1/user/{userId}/facts [get]
+//	@Router			/api/v1/users/{userId}/facts [get]
+func GetUserFactsHandler(appState *models.AppState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := chi.URLParam(r, "userId")
+		limit, err := handlertools.IntFromQuery[int](r, "limit")
+		if err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+		if limit < 0 {
+			handlertools.RenderError(w, fmt.Errorf("limit cannot be negative"), http.StatusBadRequest)
+			return
+		}
+
+		facts, err := appState.MemoryStore.GetFacts(r.Context(), userID, limit)
+		if err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				handlertools.RenderError(w, fmt.Errorf("not found"), http.StatusNotFound)
+				return
+			}
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		response := models.FactListResponse{Facts: facts, RowCount: len(facts)}
+		if err := handlertools.EncodeJSON(w, response); err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// GetUserEntitiesHandler godoc
+//
+//	@Summary		List the entities mentioned in a user's sessions
+//	@Description	list the entities mentioned in the messages of a user's sessions, most mentioned first
+//	@Tags			user
+//	@Accept			json
+//	@Produce		json
+//	@Param			userId	path		string	true	"User ID"
+//	@Param			label	query		string	false	"Only list entities with this label, e.g. PERSON"
+//	@Param			limit	query		integer	false	"Limit the number of entities returned"
+//	@Success		200		{object}	models.UserEntityListResponse
+//	@Failure		400		{object}	APIError	"Bad Request"
+//	@Failure		404		{object}	APIError	"Not Found"
+//	@Failure		500		{object}	APIError	"Internal Server Error"
+//	@Security		Bearer
+//	@Router			/api/v1/user/{userId}/entities [get]
+//	@Router			/api/v1/users/{userId}/entities [get]
+func GetUserEntitiesHandler(appState *models.AppState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := chi.URLParam(r, "userId")
+		limit, err := handlertools.IntFromQuery[int](r, "limit")
+		if err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		entities, err := appState.MemoryStore.GetUserEntities(
+			r.Context(),
+			userID,
+			r.URL.Query().Get("label"),
+			limit,
+		)
+		if err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				handlertools.RenderError(w, fmt.Errorf("not found"), http.StatusNotFound)
+				return
+			}
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		if err := handlertools.EncodeJSON(w, entities); err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// ListEntityMentionsHandler godoc
+//
+//	@Summary		List the messages mentioning an entity across a user's sessions
+//	@Description	list the messages of a user's sessions that mention an entity, newest first. The entity's name is matched without case.
+//	@Tags			user
+//	@Accept			json
+//	@Produce		json
+//	@Param			userId	path		string	true	"User ID"
+//	@Param			name	query		string	true	"Entity name"
+//	@Param			label	query		string	false	"Only match entities with this label, e.g. PERSON"
+//	@Param			cursor	query		integer	false	"next_cursor of the previous page"
+//	@Param			limit	query		integer	false	"Limit the number of messages returned"
+//	@Success		200		{object}	models.EntityMentionListResponse
+//	@Failure		400		{object}	APIError	"Bad Request"
+//	@Failure		404		{object}	APIError	"Not Found"
+//	@Failure		500		{object}	APIError	"Internal Server Error"
+//	@Security		Bearer
+//	@Router			/api/v1/user/{userId}/entities/mentions [get]
+//	@Router			/api/v1/users/{userId}/entities/mentions [get]
+func ListEntityMentionsHandler(appState *models.AppState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := chi.URLParam(r, "userId")
+		cursor, err := handlertools.IntFromQuery[int64](r, "cursor")
+		if err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+		limit, err := handlertools.IntFromQuery[int](r, "limit")
+		if err != nil {
+			handlertools.RenderError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		mentions, err := appState.MemoryStore.ListEntityMentions(
+			r.Context(),
+			userID,
+			r.URL.Query().Get("name"),
+			r.URL.Query().Get("label"),
+			cursor,
+			limit,
+		)
+		if err != nil {
+			switch {
+			case errors.Is(err, models.ErrNotFound):
+				handlertools.RenderError(w, fmt.Errorf("not found"), http.StatusNotFound)
+			case errors.Is(err, models.ErrBadRequest):
+				handlertools.RenderError(w, err, http.StatusBadRequest)
+			default:
+				handlertools.RenderError(w, err, http.StatusInternalServerError)
+			}
+			return
+		}
+
+		if err := handlertools.EncodeJSON(w, mentions); err != nil {
+			handlertools.RenderError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+}
@@ -3,6 +3,7 @@ package server
 import (
 	"bytes"
 	"encoding/json"
+	"mime/multipart"
 	"net/http"
 	"strings"
 	"testing"
@@ -306,6 +307,146 @@ func TestCreateDocumentsHandler_MaxRequestBodySize(t *testing.T) {
 	assert.Equal(t, "413 Request Entity Too Large", resp.Status)
 }
 
+func TestUploadDocumentsHandler(t *testing.T) {
+	collectionName := testutils.GenerateRandomString(10)
+	cr := models.DocumentCollection{
+		Name:                collectionName,
+		Description:         "Test collection",
+		EmbeddingDimensions: 128,
+		IsAutoEmbedded:      true,
+		Chunking:            &models.DocumentChunking{Strategy: "markdown", ChunkSize: 16},
+	}
+
+	err := appState.DocumentStore.CreateCollection(testCtx, cr)
+	assert.NoError(t, err)
+
+	files := map[string]string{
+		"guide.md": "# Guide\n\n" + strings.Repeat("Read the guide. ", 8) +
+			"\n\n## Usage\n\nUse it well.",
+		"notes.txt": "Short notes.",
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for _, filename := range []string{"guide.md", "notes.txt"} {
+		part, err := writer.CreateFormFile("file", filename)
+		assert.NoError(t, err)
+		_, err = part.Write([]byte(files[filename]))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, writer.WriteField("metadata", `{"key": "value"}`))
+	assert.NoError(t, writer.Close())
+
+	req, err := http.NewRequest(
+		"POST",
+		testServer.URL+"/api/v1/collection/"+collectionName+"/document/upload",
+		&body,
+	)
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var uploads []models.DocumentUploadResponse
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&uploads))
+	assert.Equal(t, 2, len(uploads))
+	assert.Equal(t, "guide.md", uploads[0].Filename)
+	assert.Greater(t, uploads[0].ChunkCount, 1)
+	assert.Equal(t, "notes.txt", uploads[1].Filename)
+	assert.Equal(t, 1, uploads[1].ChunkCount)
+
+	for _, upload := range uploads {
+		assert.Equal(t, upload.ChunkCount, len(upload.UUIDs))
+		documents, err := appState.DocumentStore.GetDocuments(
+			testCtx,
+			collectionName,
+			upload.UUIDs,
+			nil,
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, upload.ChunkCount, len(documents))
+		for _, document := range documents {
+			assert.Equal(t, upload.ParentDocumentID, document.ParentDocumentID)
+			assert.Equal(t, upload.Filename, document.Metadata["filename"])
+			assert.Equal(t, "value", document.Metadata["key"])
+		}
+	}
+}
+
+func TestUploadDocumentsHandler_NotAutoEmbedded(t *testing.T) {
+	collectionName := testutils.GenerateRandomString(10)
+	cr := models.DocumentCollection{
+		Name:                collectionName,
+		EmbeddingDimensions: 128,
+		IsAutoEmbedded:      false,
+	}
+
+	err := appState.DocumentStore.CreateCollection(testCtx, cr)
+	assert.NoError(t, err)
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", "notes.txt")
+	assert.NoError(t, err)
+	_, err = part.Write([]byte("Short notes."))
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+
+	req, err := http.NewRequest(
+		"POST",
+		testServer.URL+"/api/v1/collection/"+collectionName+"/document/upload",
+		&body,
+	)
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestCollectionIndexHandlers(t *testing.T) {
+	collectionName := testutils.GenerateRandomString(10)
+	cr := models.DocumentCollection{
+		Name:                collectionName,
+		EmbeddingDimensions: 128,
+		IsAutoEmbedded:      false,
+	}
+
+	err := appState.DocumentStore.CreateCollection(testCtx, cr)
+	assert.NoError(t, err)
+
+	resp, err := http.Get(testServer.URL + "/api/v1/collection/" + collectionName + "/index")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var status models.CollectionIndexStatus
+	err = json.NewDecoder(resp.Body).Decode(&status)
+	assert.NoError(t, err)
+	assert.Equal(t, collectionName, status.CollectionName)
+	assert.Equal(t, 0, status.DocumentCount)
+
+	// only the documents of auto-embedded collections can be re-embedded
+	resp, err = http.Post(
+		testServer.URL+"/api/v1/collection/"+collectionName+"/index/reindex",
+		"application/json",
+		bytes.NewBufferString(`{"reembed": true}`),
+	)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = http.Get(testServer.URL + "/api/v1/collection/notacollection/index")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
 // Test SearchDocumentsHandler
 func TestSearchDocumentsHandler(t *testing.T) {
 	collectionName := testutils.GenerateRandomString(10)
@@ -401,3 +542,58 @@ func TestSearchDocumentsHandler(t *testing.T) {
 		assert.Equal(t, 2, len(searchResults.Results))
 	}
 }
+
+func TestSearchCollectionsHandler(t *testing.T) {
+	tag := testutils.GenerateRandomString(10)
+	embedding := []float32{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, -0.1, -0.2, -0.3, -0.4}
+
+	var collectionNames []string
+	for i := 0; i < 2; i++ {
+		collectionName := testutils.GenerateRandomString(10)
+		collectionNames = append(collectionNames, collectionName)
+		cr := models.DocumentCollection{
+			Name:                collectionName,
+			Metadata:            map[string]interface{}{"tags": []string{tag}},
+			EmbeddingDimensions: 10,
+			IsAutoEmbedded:      false,
+		}
+		err := appState.DocumentStore.CreateCollection(testCtx, cr)
+		assert.NoError(t, err)
+
+		docs := []models.Document{
+			{
+				DocumentBase: models.DocumentBase{Content: "This is a test document"},
+				Embedding:    embedding,
+			},
+		}
+		_, err = appState.DocumentStore.CreateDocuments(testCtx, collectionName, docs)
+		assert.NoError(t, err)
+	}
+
+	q := models.CollectionsSearchPayload{
+		Tag:        tag,
+		Embedding:  embedding,
+		SearchType: models.SearchTypeSimilarity,
+	}
+	p, err := json.Marshal(q)
+	assert.NoError(t, err)
+
+	resp, err := http.Post(
+		testServer.URL+"/api/v1/collections/search?limit=5",
+		"application/json",
+		bytes.NewBuffer(p),
+	)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var searchResults models.CollectionsSearchResultPage
+	err = json.NewDecoder(resp.Body).Decode(&searchResults)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2, len(searchResults.Results))
+	for _, result := range searchResults.Results {
+		assert.Contains(t, collectionNames, result.CollectionName)
+		assert.Equal(t, 1.0, result.Score)
+	}
+}
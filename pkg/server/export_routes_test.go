@@ -0,0 +1,95 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/testutils"
+)
+
+func TestExportSessionRoute(t *testing.T) {
+	sessionID := testutils.GenerateRandomString(10)
+	messages := []models.Message{
+		{Role: "user", Content: "Hello"},
+		{Role: "assistant", Content: "Hi there"},
+	}
+	err := appState.MemoryStore.PutMemory(
+		testCtx,
+		appState,
+		sessionID,
+		&models.Memory{Messages: messages},
+		true,
+	)
+	require.NoError(t, err)
+	err = appState.MemoryStore.PutSummary(
+		testCtx,
+		appState,
+		sessionID,
+		&models.Summary{Content: "A greeting", SummaryPointUUID: messages[1].UUID},
+	)
+	require.NoError(t, err)
+
+	exportURL := testServer.URL + "/api/v1/sessions/" + sessionID + "/export"
+
+	t.Run("json", func(t *testing.T) {
+		resp, err := http.Get(exportURL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+		var export models.SessionExport
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&export))
+		assert.Equal(t, sessionID, export.Session.SessionID)
+		require.Len(t, export.Messages, 2)
+		assert.Equal(t, "Hello", export.Messages[0].Content)
+		assert.Equal(t, "Hi there", export.Messages[1].Content)
+		require.Len(t, export.Summaries, 1)
+		assert.Equal(t, "A greeting", export.Summaries[0].Content)
+		assert.Empty(t, export.Embeddings)
+	})
+
+	t.Run("jsonl", func(t *testing.T) {
+		resp, err := http.Get(exportURL + "?format=jsonl&embeddings=true")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+
+		var types []models.ExportRecordType
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			var record models.ExportRecord
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+			types = append(types, record.Type)
+		}
+		require.NoError(t, scanner.Err())
+		// the messages were stored without notifying the embedder, so have no embeddings
+		assert.Equal(t, []models.ExportRecordType{
+			models.ExportRecordSession,
+			models.ExportRecordMessage,
+			models.ExportRecordMessage,
+			models.ExportRecordSummary,
+		}, types)
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		resp, err := http.Get(exportURL + "?format=csv")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("missing session", func(t *testing.T) {
+		resp, err := http.Get(testServer.URL + "/api/v1/sessions/missing-session/export")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
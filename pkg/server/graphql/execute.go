@@ -0,0 +1,611 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+// Type is the type of a field's value: a Scalar, an *Object or a List of either
+type Type interface {
+	String() string
+}
+
+// Scalar is a leaf type. Scalar values are serialized as JSON as-is.
+type Scalar string
+
+const (
+	String  Scalar = "String"
+	Int     Scalar = "Int"
+	Float   Scalar = "Float"
+	Boolean Scalar = "Boolean"
+	// JSON is an arbitrary JSON value, such as metadata
+	JSON Scalar = "JSON"
+	// Time is an RFC 3339 timestamp
+	Time Scalar = "Time"
+)
+
+func (s Scalar) String() string {
+	return string(s)
+}
+
+// List is a list of values of type Of. Resolvers of list fields return a slice.
+type List struct {
+	Of Type
+}
+
+func (l List) String() string {
+	return "[" + l.Of.String() + "]"
+}
+
+// Object is an object type with a set of fields. The value returned by a resolver of an
+// object field is the source of its own fields' resolvers.
+type Object struct {
+	Name   string
+	Fields map[string]*Field
+}
+
+func (o *Object) String() string {
+	return o.Name
+}
+
+// ResolveFunc returns the value of a field, given the value of its parent object and the
+// field's coerced arguments. Arguments that aren't provided and have no default are absent.
+type ResolveFunc func(
+	ctx context.Context,
+	source interface{},
+	args map[string]interface{},
+) (interface{}, error)
+
+type Field struct {
+	Type    Type
+	Args    map[string]*Argument
+	Resolve ResolveFunc
+}
+
+type Argument struct {
+	Type     Scalar
+	Default  interface{}
+	Required bool
+}
+
+// Schema is a GraphQL schema. Only queries are supported.
+type Schema struct {
+	Query *Object
+}
+
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+type Response struct {
+	Data   interface{} `json:"data,omitempty"`
+	Errors []*Error    `json:"errors,omitempty"`
+}
+
+// Error is a GraphQL error. Path is set for errors raised while resolving a field.
+type Error struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Execute executes a query against the schema. Errors in the query are returned without
+// data. Errors raised by resolvers are returned alongside the data, with the failed field
+// set to null.
+func (s *Schema) Execute(ctx context.Context, request *Request) *Response {
+	doc, err := parse(request.Query)
+	if err != nil {
+		return errorResponse(err)
+	}
+	op, err := selectOperation(doc, request.OperationName)
+	if err != nil {
+		return errorResponse(err)
+	}
+	e := &executor{fragments: doc.fragments}
+	if errs := e.validate(s.Query, op.selections, nil); len(errs) > 0 {
+		return &Response{Errors: errs}
+	}
+	if e.variables, err = coerceVariables(op, request.Variables); err != nil {
+		return errorResponse(err)
+	}
+
+	data := e.executeSelections(ctx, s.Query, nil, op.selections, nil)
+	return &Response{Data: data, Errors: e.errors}
+}
+
+func errorResponse(err error) *Response {
+	return &Response{Errors: []*Error{{Message: err.Error()}}}
+}
+
+func selectOperation(doc *document, operationName string) (*operation, error) {
+	var op *operation
+	switch {
+	case operationName != "":
+		for _, o := range doc.operations {
+			if o.name == operationName {
+				op = o
+				break
+			}
+		}
+		if op == nil {
+			return nil, fmt.Errorf("unknown operation %q", operationName)
+		}
+	case len(doc.operations) > 1:
+		return nil, fmt.Errorf(
+			"operationName is required when the document contains multiple operations",
+		)
+	default:
+		op = doc.operations[0]
+	}
+	if op.kind != "query" {
+		return nil, fmt.Errorf("%s operations are not supported", op.kind)
+	}
+	return op, nil
+}
+
+func coerceVariables(
+	op *operation,
+	values map[string]interface{},
+) (map[string]interface{}, error) {
+	variables := make(map[string]interface{}, len(op.variables))
+	for _, v := range op.variables {
+		value, ok := values[v.name]
+		if !ok && v.hasDefault {
+			value, ok = v.defaultValue, true
+		}
+		if v.typ.nonNull && value == nil {
+			return nil, fmt.Errorf(
+				"variable $%s of required type %s was not provided",
+				v.name, v.typ,
+			)
+		}
+		if ok {
+			variables[v.name] = value
+		}
+	}
+	return variables, nil
+}
+
+type executor struct {
+	fragments map[string]*fragment
+	variables map[string]interface{}
+	errors    []*Error
+}
+
+// validate checks that the selections only query fields and arguments that exist.
+// spreading holds the fragments being spread, to detect cycles.
+func (e *executor) validate(obj *Object, selections []selection, spreading []string) []*Error {
+	var errs []*Error
+	fail := func(format string, a ...interface{}) {
+		errs = append(errs, &Error{Message: fmt.Sprintf(format, a...)})
+	}
+	for _, s := range selections {
+		switch s := s.(type) {
+		case *field:
+			errs = append(errs, e.validateDirectives(s.directives)...)
+			if s.name == "__typename" {
+				if s.selections != nil {
+					fail("field \"__typename\" must not have a selection")
+				}
+				continue
+			}
+			def, ok := obj.Fields[s.name]
+			if !ok {
+				fail("cannot query field %q on type %q", s.name, obj.Name)
+				continue
+			}
+			for _, arg := range s.arguments {
+				if _, ok := def.Args[arg.name]; !ok {
+					fail("unknown argument %q on field %q", arg.name, s.name)
+				}
+			}
+			switch t := namedType(def.Type).(type) {
+			case *Object:
+				if s.selections == nil {
+					fail("field %q of type %q must have a selection", s.name, def.Type)
+					continue
+				}
+				errs = append(errs, e.validate(t, s.selections, spreading)...)
+			default:
+				if s.selections != nil {
+					fail("field %q of type %q must not have a selection", s.name, def.Type)
+				}
+			}
+		case *fragmentSpread:
+			errs = append(errs, e.validateDirectives(s.directives)...)
+			f, ok := e.fragments[s.name]
+			if !ok {
+				fail("unknown fragment %q", s.name)
+				continue
+			}
+			if contains(spreading, s.name) {
+				fail("fragment %q cannot spread itself", s.name)
+				continue
+			}
+			if f.typeCondition != obj.Name {
+				fail("fragment %q cannot be spread on type %q", s.name, obj.Name)
+				continue
+			}
+			errs = append(errs, e.validate(obj, f.selections, append(spreading, s.name))...)
+		case *inlineFragment:
+			errs = append(errs, e.validateDirectives(s.directives)...)
+			if s.typeCondition != "" && s.typeCondition != obj.Name {
+				fail("inline fragment on %q cannot be spread on type %q", s.typeCondition, obj.Name)
+				continue
+			}
+			errs = append(errs, e.validate(obj, s.selections, spreading)...)
+		}
+	}
+	return errs
+}
+
+func (e *executor) validateDirectives(directives []*directive) []*Error {
+	var errs []*Error
+	for _, d := range directives {
+		if d.name != "skip" && d.name != "include" {
+			errs = append(errs, &Error{Message: fmt.Sprintf("unknown directive \"@%s\"", d.name)})
+		}
+	}
+	return errs
+}
+
+// namedType returns the type with any List wrappers removed
+func namedType(t Type) Type {
+	for {
+		l, ok := t.(List)
+		if !ok {
+			return t
+		}
+		t = l.Of
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// collectFields groups the fields of a selection set by response key, expanding fragments
+// and applying @skip and @include.
+func (e *executor) collectFields(
+	obj *Object,
+	selections []selection,
+	result *orderedFields,
+) error {
+	for _, s := range selections {
+		switch s := s.(type) {
+		case *field:
+			include, err := e.shouldInclude(s.directives)
+			if err != nil {
+				return err
+			}
+			if include {
+				result.add(s)
+			}
+		case *fragmentSpread:
+			include, err := e.shouldInclude(s.directives)
+			if err != nil {
+				return err
+			}
+			if include {
+				if err := e.collectFields(obj, e.fragments[s.name].selections, result); err != nil {
+					return err
+				}
+			}
+		case *inlineFragment:
+			include, err := e.shouldInclude(s.directives)
+			if err != nil {
+				return err
+			}
+			if include {
+				if err := e.collectFields(obj, s.selections, result); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (e *executor) shouldInclude(directives []*directive) (bool, error) {
+	for _, d := range directives {
+		if len(d.arguments) != 1 || d.arguments[0].name != "if" {
+			return false, fmt.Errorf("directive \"@%s\" requires a single argument \"if\"", d.name)
+		}
+		value, err := e.resolveValue(d.arguments[0].value)
+		if err != nil {
+			return false, err
+		}
+		condition, ok := value.(bool)
+		if !ok {
+			return false, fmt.Errorf(
+				"argument \"if\" of directive \"@%s\" must be a Boolean",
+				d.name,
+			)
+		}
+		if (d.name == "skip") == condition {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+type orderedFields struct {
+	keys   []string
+	fields map[string][]*field
+}
+
+func (o *orderedFields) add(f *field) {
+	key := f.responseKey()
+	if _, ok := o.fields[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.fields[key] = append(o.fields[key], f)
+}
+
+func (e *executor) executeSelections(
+	ctx context.Context,
+	obj *Object,
+	source interface{},
+	selections []selection,
+	path []interface{},
+) *orderedMap {
+	collected := &orderedFields{fields: make(map[string][]*field)}
+	if err := e.collectFields(obj, selections, collected); err != nil {
+		e.addError(err, path)
+		return nil
+	}
+
+	result := &orderedMap{values: make(map[string]interface{}, len(collected.keys))}
+	for _, key := range collected.keys {
+		fields := collected.fields[key]
+		fieldPath := appendPath(path, key)
+		result.set(key, e.executeField(ctx, obj, source, fields, fieldPath))
+	}
+	return result
+}
+
+func (e *executor) executeField(
+	ctx context.Context,
+	obj *Object,
+	source interface{},
+	fields []*field,
+	path []interface{},
+) interface{} {
+	f := fields[0]
+	if f.name == "__typename" {
+		return obj.Name
+	}
+	def := obj.Fields[f.name]
+	args, err := e.coerceArguments(f, def)
+	if err != nil {
+		e.addError(err, path)
+		return nil
+	}
+	value, err := def.Resolve(ctx, source, args)
+	if err != nil {
+		e.addError(err, path)
+		return nil
+	}
+	return e.completeValue(ctx, def.Type, fields, value, path)
+}
+
+func (e *executor) completeValue(
+	ctx context.Context,
+	typ Type,
+	fields []*field,
+	value interface{},
+	path []interface{},
+) interface{} {
+	if isNil(value) {
+		return nil
+	}
+	switch t := typ.(type) {
+	case *Object:
+		var selections []selection
+		for _, f := range fields {
+			selections = append(selections, f.selections...)
+		}
+		return e.executeSelections(ctx, t, value, selections, path)
+	case List:
+		v := reflect.ValueOf(value)
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			e.addError(fmt.Errorf("expected a list, got %T", value), path)
+			return nil
+		}
+		list := make([]interface{}, v.Len())
+		for i := range list {
+			elem := v.Index(i).Interface()
+			list[i] = e.completeValue(ctx, t.Of, fields, elem, appendPath(path, i))
+		}
+		return list
+	default:
+		return value
+	}
+}
+
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	switch v := reflect.ValueOf(value); v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+// appendPath returns a copy of path with elem appended, as paths are shared by siblings
+func appendPath(path []interface{}, elem interface{}) []interface{} {
+	p := make([]interface{}, len(path), len(path)+1)
+	copy(p, path)
+	return append(p, elem)
+}
+
+func (e *executor) addError(err error, path []interface{}) {
+	e.errors = append(e.errors, &Error{Message: err.Error(), Path: path})
+}
+
+func (e *executor) coerceArguments(f *field, def *Field) (map[string]interface{}, error) {
+	args := make(map[string]interface{}, len(def.Args))
+	for name, arg := range def.Args {
+		if arg.Default != nil {
+			args[name] = arg.Default
+		}
+	}
+	for _, a := range f.arguments {
+		// arguments set to variables that aren't provided keep their default
+		if v, ok := a.value.(variable); ok {
+			if _, ok := e.variables[string(v)]; !ok {
+				continue
+			}
+		}
+		value, err := e.resolveValue(a.value)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			delete(args, a.name)
+			continue
+		}
+		if args[a.name], err = coerceArgument(a.name, def.Args[a.name].Type, value); err != nil {
+			return nil, err
+		}
+	}
+	for name, arg := range def.Args {
+		if _, ok := args[name]; arg.Required && !ok {
+			return nil, fmt.Errorf("argument %q of type \"%s!\" is required", name, arg.Type)
+		}
+	}
+	return args, nil
+}
+
+// resolveValue replaces the variables in a value with their values
+func (e *executor) resolveValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case variable:
+		return e.variables[string(v)], nil
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i := range v {
+			var err error
+			if list[i], err = e.resolveValue(v[i]); err != nil {
+				return nil, err
+			}
+		}
+		return list, nil
+	case map[string]interface{}:
+		object := make(map[string]interface{}, len(v))
+		for key := range v {
+			var err error
+			if object[key], err = e.resolveValue(v[key]); err != nil {
+				return nil, err
+			}
+		}
+		return object, nil
+	}
+	return value, nil
+}
+
+// coerceArgument converts an argument value, which is either a literal or a JSON-decoded
+// variable, to the Go type of its Scalar: int, float64, string, bool or time.Time.
+func coerceArgument(name string, typ Scalar, value interface{}) (interface{}, error) {
+	invalid := fmt.Errorf("argument %q must be of type %q", name, typ)
+	if n, ok := value.(json.Number); ok {
+		f, err := n.Float64()
+		if err != nil {
+			return nil, invalid
+		}
+		value = f
+	}
+	switch typ {
+	case Int:
+		switch v := value.(type) {
+		case int64:
+			if v >= math.MinInt32 && v <= math.MaxInt32 {
+				return int(v), nil
+			}
+		case float64:
+			if v == math.Trunc(v) && v >= math.MinInt32 && v <= math.MaxInt32 {
+				return int(v), nil
+			}
+		}
+	case Float:
+		switch v := value.(type) {
+		case int64:
+			return float64(v), nil
+		case float64:
+			return v, nil
+		}
+	case String:
+		if v, ok := value.(string); ok {
+			return v, nil
+		}
+	case Boolean:
+		if v, ok := value.(bool); ok {
+			return v, nil
+		}
+	case Time:
+		if v, ok := value.(string); ok {
+			if t, err := time.Parse(time.RFC3339, v); err == nil {
+				return t, nil
+			}
+		}
+	case JSON:
+		if _, ok := value.(enumValue); !ok {
+			return value, nil
+		}
+	}
+	return nil, invalid
+}
+
+// orderedMap is a JSON object that preserves the order of its keys, as GraphQL responses
+// are ordered as the fields are in the query
+type orderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func (m *orderedMap) set(key string, value interface{}) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,315 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/pkg/models"
+)
+
+// fakeMemoryStore implements the MemoryStore methods used by the schema. Other methods panic.
+type fakeMemoryStore struct {
+	models.MemoryStore[any]
+	session      *models.Session
+	messages     []models.Message
+	summaries    []models.Summary
+	listOptions  *models.MessageListOptions
+	summaryLimit int
+}
+
+func (s *fakeMemoryStore) GetSession(
+	_ context.Context,
+	_ *models.AppState,
+	sessionID string,
+) (*models.Session, error) {
+	if sessionID != s.session.SessionID {
+		return nil, models.NewNotFoundError("session " + sessionID)
+	}
+	return s.session, nil
+}
+
+func (s *fakeMemoryStore) ListMessages(
+	_ context.Context,
+	_ *models.AppState,
+	_ string,
+	_ int64,
+	limit int,
+	opts *models.MessageListOptions,
+) (*models.MessageListResponse, error) {
+	s.listOptions = opts
+	messages := s.messages
+	if limit > 0 && limit < len(messages) {
+		messages = messages[:limit]
+	}
+	return &models.MessageListResponse{
+		Messages:   messages,
+		TotalCount: len(s.messages),
+		RowCount:   len(messages),
+	}, nil
+}
+
+func (s *fakeMemoryStore) GetSummaryList(
+	_ context.Context,
+	_ *models.AppState,
+	_ string,
+	_ int,
+	pageSize int,
+) (*models.SummaryListResponse, error) {
+	s.summaryLimit = pageSize
+	return &models.SummaryListResponse{
+		Summaries:  s.summaries,
+		TotalCount: len(s.summaries),
+		RowCount:   len(s.summaries),
+	}, nil
+}
+
+func newTestSchema() (*Schema, *fakeMemoryStore) {
+	userID := "user"
+	store := &fakeMemoryStore{
+		session: &models.Session{
+			UUID:      uuid.MustParse("00000000-0000-0000-0000-000000000001"),
+			SessionID: "session",
+			UserID:    &userID,
+			Metadata:  map[string]interface{}{"key": "value"},
+			CreatedAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		messages: []models.Message{
+			{Role: "user", Content: "hello"},
+			{Role: "assistant", Content: "hi"},
+		},
+		summaries: []models.Summary{{Content: "greetings"}},
+	}
+	return NewSchema(&models.AppState{MemoryStore: store}), store
+}
+
+func execute(t *testing.T, schema *Schema, request *Request) string {
+	b, err := json.Marshal(schema.Execute(context.Background(), request))
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestExecute(t *testing.T) {
+	schema, store := newTestSchema()
+
+	query := `
+		query Session($id: String!, $limit: Int = 1) {
+			session(session_id: $id) {
+				__typename
+				session_id
+				user_id
+				metadata
+				created_at
+				messages(limit: $limit, role: "user", desc: true) {
+					total_count
+					messages { role content }
+				}
+				...summaries
+			}
+		}
+
+		fragment summaries on Session {
+			summaries(limit: 5) {
+				summaries { text: content }
+			}
+		}`
+	resp := execute(t, schema, &Request{
+		Query:     query,
+		Variables: map[string]interface{}{"id": "session"},
+	})
+
+	assert.JSONEq(t, `{"data": {"session": {
+		"__typename": "Session",
+		"session_id": "session",
+		"user_id": "user",
+		"metadata": {"key": "value"},
+		"created_at": "2023-01-01T00:00:00Z",
+		"messages": {"total_count": 2, "messages": [{"role": "user", "content": "hello"}]},
+		"summaries": {"summaries": [{"text": "greetings"}]}
+	}}}`, resp)
+	assert.Equal(t, &models.MessageListOptions{Role: "user", Desc: true}, store.listOptions)
+	assert.Equal(t, 5, store.summaryLimit)
+}
+
+func TestExecute_FieldOrder(t *testing.T) {
+	schema, _ := newTestSchema()
+
+	resp := execute(t, schema, &Request{
+		Query: `{ session(session_id: "session") { user_id session_id uuid } }`,
+	})
+	assert.Equal(
+		t,
+		`{"data":{"session":{"user_id":"user","session_id":"session",`+
+			`"uuid":"00000000-0000-0000-0000-000000000001"}}}`,
+		resp,
+	)
+}
+
+func TestExecute_Directives(t *testing.T) {
+	schema, _ := newTestSchema()
+
+	resp := execute(t, schema, &Request{
+		Query: `query ($skip: Boolean!) {
+			session(session_id: "session") {
+				session_id @skip(if: $skip)
+				user_id @include(if: false)
+				... @include(if: true) { language }
+			}
+		}`,
+		Variables: map[string]interface{}{"skip": true},
+	})
+	assert.JSONEq(t, `{"data": {"session": {"language": ""}}}`, resp)
+}
+
+func TestExecute_FieldError(t *testing.T) {
+	schema, _ := newTestSchema()
+
+	resp := execute(t, schema, &Request{
+		Query: `{
+			found: session(session_id: "session") { session_id }
+			missing: session(session_id: "missing") { session_id }
+		}`,
+	})
+	assert.JSONEq(t, `{
+		"data": {"found": {"session_id": "session"}, "missing": null},
+		"errors": [{"message": "session missing not found", "path": ["missing"]}]
+	}`, resp)
+}
+
+func TestExecute_Errors(t *testing.T) {
+	schema, _ := newTestSchema()
+
+	tests := []struct {
+		name    string
+		request *Request
+		error   string
+	}{
+		{
+			name:    "syntax error",
+			request: &Request{Query: `{ session(session_id: "session") { session_id }`},
+			error:   "syntax error at 47: unexpected end of query",
+		},
+		{
+			name:    "unknown field",
+			request: &Request{Query: `{ session(session_id: "session") { name } }`},
+			error:   `cannot query field "name" on type "Session"`,
+		},
+		{
+			name:    "missing selection",
+			request: &Request{Query: `{ session(session_id: "session") }`},
+			error:   `field "session" of type "Session" must have a selection`,
+		},
+		{
+			name:    "unknown argument",
+			request: &Request{Query: `{ session(id: "session") { session_id } }`},
+			error:   `unknown argument "id" on field "session"`,
+		},
+		{
+			name:    "missing variable",
+			request: &Request{Query: `query ($id: String!) { session(session_id: $id) { uuid } }`},
+			error:   `variable $id of required type String! was not provided`,
+		},
+		{
+			name:    "mutation",
+			request: &Request{Query: `mutation { session(session_id: "session") { uuid } }`},
+			error:   "mutation operations are not supported",
+		},
+		{
+			name: "fragment cycle",
+			request: &Request{
+				Query: `{ session(session_id: "session") { ...a } }
+					fragment a on Session { ...b }
+					fragment b on Session { ...a }`,
+			},
+			error: `fragment "a" cannot spread itself`,
+		},
+		{
+			name: "multiple operations",
+			request: &Request{
+				Query: `query A { session(session_id: "a") { uuid } }
+					query B { session(session_id: "b") { uuid } }`,
+			},
+			error: "operationName is required when the document contains multiple operations",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := schema.Execute(context.Background(), tt.request)
+			assert.Nil(t, resp.Data)
+			require.Len(t, resp.Errors, 1)
+			assert.Equal(t, tt.error, resp.Errors[0].Message)
+		})
+	}
+}
+
+func TestExecute_ArgumentCoercion(t *testing.T) {
+	schema, store := newTestSchema()
+
+	// variables decoded from JSON hold numbers as float64
+	resp := execute(t, schema, &Request{
+		Query: `query ($limit: Int) {
+			session(session_id: "session") { summaries(limit: $limit) { row_count } }
+		}`,
+		Variables: map[string]interface{}{"limit": float64(3)},
+	})
+	assert.JSONEq(t, `{"data": {"session": {"summaries": {"row_count": 1}}}}`, resp)
+	assert.Equal(t, 3, store.summaryLimit)
+
+	// arguments set to variables that aren't provided keep their default
+	execute(t, schema, &Request{
+		Query: `query ($limit: Int) {
+			session(session_id: "session") { summaries(limit: $limit) { row_count } }
+		}`,
+	})
+	assert.Equal(t, 100, store.summaryLimit)
+
+	resp = execute(t, schema, &Request{
+		Query: `{ session(session_id: "session") { summaries(limit: "3") { row_count } } }`,
+	})
+	assert.JSONEq(t, `{
+		"data": {"session": {"summaries": null}},
+		"errors": [{"message": "argument \"limit\" must be of type \"Int\"",
+			"path": ["session", "summaries"]}]
+	}`, resp)
+}
+
+func TestParse(t *testing.T) {
+	doc, err := parse(`
+		# comment
+		query Q($a: [Int!]! = [1, 2], $b: String) {
+			alias: field(x: $a, y: {z: "sé\n"}, e: ENUM, n: null, f: -1.5e3) {
+				... on T @skip(if: true) { inner }
+				...frag
+			}
+		}
+		fragment frag on T { other }`)
+	require.NoError(t, err)
+	require.Len(t, doc.operations, 1)
+
+	op := doc.operations[0]
+	assert.Equal(t, "Q", op.name)
+	require.Len(t, op.variables, 2)
+	assert.Equal(t, "[Int!]!", op.variables[0].typ.String())
+	assert.Equal(t, []interface{}{int64(1), int64(2)}, op.variables[0].defaultValue)
+	assert.False(t, op.variables[1].hasDefault)
+
+	f := op.selections[0].(*field)
+	assert.Equal(t, "alias", f.responseKey())
+	assert.Equal(t, "field", f.name)
+	assert.Equal(t, []*argument{
+		{name: "x", value: variable("a")},
+		{name: "y", value: map[string]interface{}{"z": "sé\n"}},
+		{name: "e", value: enumValue("ENUM")},
+		{name: "n", value: nil},
+		{name: "f", value: -1500.0},
+	}, f.arguments)
+	require.Len(t, f.selections, 2)
+	assert.Equal(t, "T", f.selections[0].(*inlineFragment).typeCondition)
+	assert.Equal(t, "frag", f.selections[1].(*fragmentSpread).name)
+	assert.Equal(t, "T", doc.fragments["frag"].typeCondition)
+}
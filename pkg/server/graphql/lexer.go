@@ -0,0 +1,174 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunct
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of query"
+	}
+	return strconv.Quote(t.value)
+}
+
+// lex splits a GraphQL document into tokens. Whitespace, commas and comments are ignored,
+// as they are insignificant.
+func lex(src string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case c == '#':
+			for i < len(src) && src[i] != '\n' && src[i] != '\r' {
+				i++
+			}
+		case strings.ContainsRune("!$&()=:@[]{}|", rune(c)):
+			tokens = append(tokens, token{kind: tokenPunct, value: string(c), pos: i})
+			i++
+		case strings.HasPrefix(src[i:], "..."):
+			tokens = append(tokens, token{kind: tokenPunct, value: "...", pos: i})
+			i += 3
+		case c == '_' || isLetter(c):
+			start := i
+			for i < len(src) && (src[i] == '_' || isLetter(src[i]) || isDigit(src[i])) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenName, value: src[start:i], pos: start})
+		case c == '-' || isDigit(c):
+			t, end, err := lexNumber(src, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, t)
+			i = end
+		case c == '"':
+			if strings.HasPrefix(src[i:], `"""`) {
+				return nil, fmt.Errorf("syntax error at %d: block strings are not supported", i)
+			}
+			t, end, err := lexString(src, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, t)
+			i = end
+		default:
+			r, _ := utf8.DecodeRuneInString(src[i:])
+			return nil, fmt.Errorf("syntax error at %d: unexpected character %q", i, r)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(src)}), nil
+}
+
+func lexNumber(src string, start int) (token, int, error) {
+	i := start
+	if src[i] == '-' {
+		i++
+	}
+	digits := func() int {
+		n := 0
+		for i < len(src) && isDigit(src[i]) {
+			i++
+			n++
+		}
+		return n
+	}
+	if digits() == 0 {
+		return token{}, 0, fmt.Errorf("syntax error at %d: invalid number", start)
+	}
+	kind := tokenInt
+	if i < len(src) && src[i] == '.' {
+		i++
+		kind = tokenFloat
+		if digits() == 0 {
+			return token{}, 0, fmt.Errorf("syntax error at %d: invalid number", start)
+		}
+	}
+	if i < len(src) && (src[i] == 'e' || src[i] == 'E') {
+		i++
+		kind = tokenFloat
+		if i < len(src) && (src[i] == '+' || src[i] == '-') {
+			i++
+		}
+		if digits() == 0 {
+			return token{}, 0, fmt.Errorf("syntax error at %d: invalid number", start)
+		}
+	}
+	return token{kind: kind, value: src[start:i], pos: start}, i, nil
+}
+
+func lexString(src string, start int) (token, int, error) {
+	var b strings.Builder
+	for i := start + 1; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case c == '"':
+			return token{kind: tokenString, value: b.String(), pos: start}, i + 1, nil
+		case c == '\n' || c == '\r':
+			return token{}, 0, fmt.Errorf("syntax error at %d: unterminated string", start)
+		case c == '\\':
+			i++
+			if i >= len(src) {
+				break
+			}
+			switch src[i] {
+			case '"', '\\', '/':
+				b.WriteByte(src[i])
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if i+4 >= len(src) {
+					return token{}, 0, fmt.Errorf("syntax error at %d: invalid escape", i)
+				}
+				r, err := strconv.ParseUint(src[i+1:i+5], 16, 32)
+				if err != nil {
+					return token{}, 0, fmt.Errorf("syntax error at %d: invalid escape", i)
+				}
+				b.WriteRune(rune(r))
+				i += 4
+			default:
+				return token{}, 0, fmt.Errorf("syntax error at %d: invalid escape", i)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return token{}, 0, fmt.Errorf("syntax error at %d: unterminated string", start)
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,473 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type document struct {
+	operations []*operation
+	fragments  map[string]*fragment
+}
+
+type operation struct {
+	kind       string
+	name       string
+	variables  []*variableDefinition
+	selections []selection
+}
+
+type variableDefinition struct {
+	name         string
+	typ          *typeRef
+	defaultValue interface{}
+	hasDefault   bool
+}
+
+// typeRef is a type in a variable definition, e.g. [String]!
+type typeRef struct {
+	name    string
+	elem    *typeRef
+	nonNull bool
+}
+
+func (t *typeRef) String() string {
+	s := t.name
+	if t.elem != nil {
+		s = "[" + t.elem.String() + "]"
+	}
+	if t.nonNull {
+		s += "!"
+	}
+	return s
+}
+
+type fragment struct {
+	name          string
+	typeCondition string
+	selections    []selection
+}
+
+// selection is a *field, *fragmentSpread or *inlineFragment
+type selection interface{}
+
+type field struct {
+	alias      string
+	name       string
+	arguments  []*argument
+	directives []*directive
+	selections []selection
+}
+
+// responseKey returns the key of the field in the response
+func (f *field) responseKey() string {
+	if f.alias != "" {
+		return f.alias
+	}
+	return f.name
+}
+
+type fragmentSpread struct {
+	name       string
+	directives []*directive
+}
+
+type inlineFragment struct {
+	typeCondition string
+	directives    []*directive
+	selections    []selection
+}
+
+type directive struct {
+	name      string
+	arguments []*argument
+}
+
+type argument struct {
+	name  string
+	value interface{}
+}
+
+// Values in a document are parsed to int64, float64, string, bool, nil, []interface{},
+// map[string]interface{}, variable or enumValue.
+type (
+	variable  string
+	enumValue string
+)
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// parse parses an executable GraphQL document
+func parse(query string) (*document, error) {
+	tokens, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	doc := &document{fragments: make(map[string]*fragment)}
+	for p.peek().kind != tokenEOF {
+		if p.peekPunct("{") {
+			selections, err := p.parseSelectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(
+				doc.operations,
+				&operation{kind: "query", selections: selections},
+			)
+			continue
+		}
+		t := p.peek()
+		if t.kind != tokenName {
+			return nil, p.unexpected()
+		}
+		switch t.value {
+		case "query", "mutation", "subscription":
+			op, err := p.parseOperation()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, op)
+		case "fragment":
+			f, err := p.parseFragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := doc.fragments[f.name]; ok {
+				return nil, fmt.Errorf("fragment %q is defined more than once", f.name)
+			}
+			doc.fragments[f.name] = f
+		default:
+			return nil, p.unexpected()
+		}
+	}
+	if len(doc.operations) == 0 {
+		return nil, fmt.Errorf("document does not contain an operation")
+	}
+	return doc, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) peekPunct(value string) bool {
+	t := p.peek()
+	return t.kind == tokenPunct && t.value == value
+}
+
+// skipPunct consumes the next token if it is the punctuator value
+func (p *parser) skipPunct(value string) bool {
+	if p.peekPunct(value) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectPunct(value string) error {
+	if !p.skipPunct(value) {
+		return p.unexpected()
+	}
+	return nil
+}
+
+func (p *parser) expectName() (string, error) {
+	t := p.peek()
+	if t.kind != tokenName {
+		return "", p.unexpected()
+	}
+	p.pos++
+	return t.value, nil
+}
+
+func (p *parser) unexpected() error {
+	t := p.peek()
+	return fmt.Errorf("syntax error at %d: unexpected %s", t.pos, t)
+}
+
+func (p *parser) parseOperation() (*operation, error) {
+	op := &operation{kind: p.next().value}
+	if p.peek().kind == tokenName {
+		op.name = p.next().value
+	}
+	if p.skipPunct("(") {
+		for !p.skipPunct(")") {
+			v, err := p.parseVariableDefinition()
+			if err != nil {
+				return nil, err
+			}
+			op.variables = append(op.variables, v)
+		}
+	}
+	if _, err := p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	selections, err := p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+	op.selections = selections
+	return op, nil
+}
+
+func (p *parser) parseVariableDefinition() (*variableDefinition, error) {
+	if err := p.expectPunct("$"); err != nil {
+		return nil, err
+	}
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectPunct(":"); err != nil {
+		return nil, err
+	}
+	typ, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+	v := &variableDefinition{name: name, typ: typ}
+	if p.skipPunct("=") {
+		if v.defaultValue, err = p.parseValue(true); err != nil {
+			return nil, err
+		}
+		v.hasDefault = true
+	}
+	return v, nil
+}
+
+func (p *parser) parseType() (*typeRef, error) {
+	t := &typeRef{}
+	if p.skipPunct("[") {
+		elem, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectPunct("]"); err != nil {
+			return nil, err
+		}
+		t.elem = elem
+	} else {
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		t.name = name
+	}
+	t.nonNull = p.skipPunct("!")
+	return t, nil
+}
+
+func (p *parser) parseFragment() (*fragment, error) {
+	p.next()
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+	if name == "on" {
+		return nil, fmt.Errorf("syntax error: fragment cannot be named \"on\"")
+	}
+	if on, err := p.expectName(); err != nil || on != "on" {
+		return nil, fmt.Errorf("syntax error: fragment %q is missing a type condition", name)
+	}
+	typeCondition, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	selections, err := p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+	return &fragment{name: name, typeCondition: typeCondition, selections: selections}, nil
+}
+
+func (p *parser) parseSelectionSet() ([]selection, error) {
+	if err := p.expectPunct("{"); err != nil {
+		return nil, err
+	}
+	var selections []selection
+	for !p.skipPunct("}") {
+		s, err := p.parseSelection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, s)
+	}
+	if len(selections) == 0 {
+		return nil, fmt.Errorf("syntax error: selection set cannot be empty")
+	}
+	return selections, nil
+}
+
+func (p *parser) parseSelection() (selection, error) {
+	if !p.skipPunct("...") {
+		return p.parseField()
+	}
+	if t := p.peek(); t.kind == tokenName && t.value != "on" {
+		p.pos++
+		directives, err := p.parseDirectives()
+		if err != nil {
+			return nil, err
+		}
+		return &fragmentSpread{name: t.value, directives: directives}, nil
+	}
+	f := &inlineFragment{}
+	if t := p.peek(); t.kind == tokenName && t.value == "on" {
+		p.pos++
+		typeCondition, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		f.typeCondition = typeCondition
+	}
+	var err error
+	if f.directives, err = p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	if f.selections, err = p.parseSelectionSet(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (p *parser) parseField() (*field, error) {
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+	f := &field{name: name}
+	if p.skipPunct(":") {
+		f.alias = name
+		if f.name, err = p.expectName(); err != nil {
+			return nil, err
+		}
+	}
+	if f.arguments, err = p.parseArguments(); err != nil {
+		return nil, err
+	}
+	if f.directives, err = p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	if p.peekPunct("{") {
+		if f.selections, err = p.parseSelectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+func (p *parser) parseArguments() ([]*argument, error) {
+	if !p.skipPunct("(") {
+		return nil, nil
+	}
+	var arguments []*argument
+	for !p.skipPunct(")") {
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectPunct(":"); err != nil {
+			return nil, err
+		}
+		value, err := p.parseValue(false)
+		if err != nil {
+			return nil, err
+		}
+		arguments = append(arguments, &argument{name: name, value: value})
+	}
+	if len(arguments) == 0 {
+		return nil, fmt.Errorf("syntax error: argument list cannot be empty")
+	}
+	return arguments, nil
+}
+
+func (p *parser) parseDirectives() ([]*directive, error) {
+	var directives []*directive
+	for p.skipPunct("@") {
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		arguments, err := p.parseArguments()
+		if err != nil {
+			return nil, err
+		}
+		directives = append(directives, &directive{name: name, arguments: arguments})
+	}
+	return directives, nil
+}
+
+// parseValue parses a value. Variables aren't allowed in constant values, such as defaults.
+func (p *parser) parseValue(constant bool) (interface{}, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenInt:
+		return strconv.ParseInt(t.value, 10, 64)
+	case tokenFloat:
+		return strconv.ParseFloat(t.value, 64)
+	case tokenString:
+		return t.value, nil
+	case tokenName:
+		switch t.value {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		return enumValue(t.value), nil
+	case tokenPunct:
+		switch t.value {
+		case "$":
+			if constant {
+				break
+			}
+			name, err := p.expectName()
+			if err != nil {
+				return nil, err
+			}
+			return variable(name), nil
+		case "[":
+			list := []interface{}{}
+			for !p.skipPunct("]") {
+				v, err := p.parseValue(constant)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, v)
+			}
+			return list, nil
+		case "{":
+			object := map[string]interface{}{}
+			for !p.skipPunct("}") {
+				name, err := p.expectName()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expectPunct(":"); err != nil {
+					return nil, err
+				}
+				if object[name], err = p.parseValue(constant); err != nil {
+					return nil, err
+				}
+			}
+			return object, nil
+		}
+	}
+	return nil, fmt.Errorf("syntax error at %d: unexpected %s", t.pos, t)
+}
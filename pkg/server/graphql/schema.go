@@ -0,0 +1,246 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/getzep/zep/pkg/models"
+)
+
+// NewSchema returns the schema of Zep's GraphQL API, which allows a session to be fetched
+// along with its messages, summaries and memory in a single request. Field names match the
+// JSON of the REST API.
+func NewSchema(appState *models.AppState) *Schema {
+	message := &Object{
+		Name: "Message",
+		Fields: map[string]*Field{
+			"uuid": fieldOf(String, func(m *models.Message) interface{} { return m.UUID }),
+			"created_at": fieldOf(Time, func(m *models.Message) interface{} {
+				return m.CreatedAt
+			}),
+			"updated_at": fieldOf(Time, func(m *models.Message) interface{} {
+				return m.UpdatedAt
+			}),
+			"role": fieldOf(String, func(m *models.Message) interface{} { return m.Role }),
+			"content": fieldOf(String, func(m *models.Message) interface{} {
+				return m.Content
+			}),
+			"metadata": fieldOf(JSON, func(m *models.Message) interface{} { return m.Metadata }),
+			"token_count": fieldOf(Int, func(m *models.Message) interface{} {
+				return m.TokenCount
+			}),
+			"is_system": fieldOf(Boolean, func(m *models.Message) interface{} {
+				return m.IsSystem
+			}),
+		},
+	}
+
+	summary := &Object{
+		Name: "Summary",
+		Fields: map[string]*Field{
+			"uuid":       fieldOf(String, func(s *models.Summary) interface{} { return s.UUID }),
+			"created_at": fieldOf(Time, func(s *models.Summary) interface{} { return s.CreatedAt }),
+			"content":    fieldOf(String, func(s *models.Summary) interface{} { return s.Content }),
+			"recent_message_uuid": fieldOf(String, func(s *models.Summary) interface{} {
+				return s.SummaryPointUUID
+			}),
+			"metadata": fieldOf(JSON, func(s *models.Summary) interface{} { return s.Metadata }),
+			"token_count": fieldOf(Int, func(s *models.Summary) interface{} {
+				return s.TokenCount
+			}),
+		},
+	}
+
+	messageList := &Object{
+		Name: "MessageList",
+		Fields: map[string]*Field{
+			"messages": fieldOf(List{message}, func(l *models.MessageListResponse) interface{} {
+				return pointers(l.Messages)
+			}),
+			"total_count": fieldOf(Int, func(l *models.MessageListResponse) interface{} {
+				return l.TotalCount
+			}),
+			"row_count": fieldOf(Int, func(l *models.MessageListResponse) interface{} {
+				return l.RowCount
+			}),
+			"next_cursor": fieldOf(Int, func(l *models.MessageListResponse) interface{} {
+				if l.NextCursor == 0 {
+					return nil
+				}
+				return l.NextCursor
+			}),
+		},
+	}
+
+	summaryList := &Object{
+		Name: "SummaryList",
+		Fields: map[string]*Field{
+			"summaries": fieldOf(List{summary}, func(l *models.SummaryListResponse) interface{} {
+				return pointers(l.Summaries)
+			}),
+			"total_count": fieldOf(Int, func(l *models.SummaryListResponse) interface{} {
+				return l.TotalCount
+			}),
+			"row_count": fieldOf(Int, func(l *models.SummaryListResponse) interface{} {
+				return l.RowCount
+			}),
+		},
+	}
+
+	memory := &Object{
+		Name: "Memory",
+		Fields: map[string]*Field{
+			"messages": fieldOf(List{message}, func(m *models.Memory) interface{} {
+				return pointers(m.Messages)
+			}),
+			"summary":  fieldOf(summary, func(m *models.Memory) interface{} { return m.Summary }),
+			"metadata": fieldOf(JSON, func(m *models.Memory) interface{} { return m.Metadata }),
+		},
+	}
+
+	session := &Object{
+		Name: "Session",
+		Fields: map[string]*Field{
+			"uuid": fieldOf(String, func(s *models.Session) interface{} { return s.UUID }),
+			"session_id": fieldOf(String, func(s *models.Session) interface{} {
+				return s.SessionID
+			}),
+			"user_id":  fieldOf(String, func(s *models.Session) interface{} { return s.UserID }),
+			"metadata": fieldOf(JSON, func(s *models.Session) interface{} { return s.Metadata }),
+			"language": fieldOf(String, func(s *models.Session) interface{} {
+				return s.Language
+			}),
+			"created_at": fieldOf(Time, func(s *models.Session) interface{} { return s.CreatedAt }),
+			"updated_at": fieldOf(Time, func(s *models.Session) interface{} { return s.UpdatedAt }),
+			"messages": {
+				Type: messageList,
+				Args: map[string]*Argument{
+					"limit":  {Type: Int},
+					"cursor": {Type: Int},
+					"role":   {Type: String},
+					"desc":   {Type: Boolean},
+				},
+				Resolve: func(
+					ctx context.Context,
+					source interface{},
+					args map[string]interface{},
+				) (interface{}, error) {
+					limit, _ := args["limit"].(int)
+					cursor, _ := args["cursor"].(int)
+					role, _ := args["role"].(string)
+					desc, _ := args["desc"].(bool)
+					return appState.MemoryStore.ListMessages(
+						ctx,
+						appState,
+						source.(*models.Session).SessionID,
+						int64(cursor),
+						limit,
+						&models.MessageListOptions{Role: role, Desc: desc},
+					)
+				},
+			},
+			"summaries": {
+				Type: summaryList,
+				Args: map[string]*Argument{
+					"page":  {Type: Int, Default: 1},
+					"limit": {Type: Int, Default: 100},
+				},
+				Resolve: func(
+					ctx context.Context,
+					source interface{},
+					args map[string]interface{},
+				) (interface{}, error) {
+					page, limit := args["page"].(int), args["limit"].(int)
+					if page < 1 || limit < 1 {
+						return nil, models.NewBadRequestError("page and limit must be positive")
+					}
+					return appState.MemoryStore.GetSummaryList(
+						ctx,
+						appState,
+						source.(*models.Session).SessionID,
+						page,
+						limit,
+					)
+				},
+			},
+			"memory": {
+				Type: memory,
+				Args: map[string]*Argument{
+					"lastn":      {Type: Int},
+					"max_tokens": {Type: Int},
+				},
+				Resolve: func(
+					ctx context.Context,
+					source interface{},
+					args map[string]interface{},
+				) (interface{}, error) {
+					lastN, _ := args["lastn"].(int)
+					if lastN < 0 {
+						return nil, models.NewBadRequestError("lastn cannot be negative")
+					}
+					maxTokens, _ := args["max_tokens"].(int)
+					if maxTokens < 0 {
+						return nil, models.NewBadRequestError("max_tokens cannot be negative")
+					}
+					return appState.MemoryStore.GetMemory(
+						ctx,
+						appState,
+						source.(*models.Session).SessionID,
+						lastN,
+						&models.GetMessagesRequest{MaxTokens: maxTokens},
+					)
+				},
+			},
+		},
+	}
+
+	return &Schema{
+		Query: &Object{
+			Name: "Query",
+			Fields: map[string]*Field{
+				"session": {
+					Type: session,
+					Args: map[string]*Argument{
+						"session_id": {Type: String, Required: true},
+					},
+					Resolve: func(
+						ctx context.Context,
+						_ interface{},
+						args map[string]interface{},
+					) (interface{}, error) {
+						return appState.MemoryStore.GetSession(
+							ctx,
+							appState,
+							args["session_id"].(string),
+						)
+					},
+				},
+			},
+		},
+	}
+}
+
+// fieldOf returns a field that is resolved by calling get with the field's source
+func fieldOf[T any](typ Type, get func(source *T) interface{}) *Field {
+	return &Field{
+		Type: typ,
+		Resolve: func(
+			_ context.Context,
+			source interface{},
+			_ map[string]interface{},
+		) (interface{}, error) {
+			return get(source.(*T)), nil
+		},
+	}
+}
+
+// pointers returns pointers to the elements of s, as fieldOf resolves fields of pointers
+func pointers[T any](s []T) []*T {
+	if s == nil {
+		return nil
+	}
+	p := make([]*T, len(s))
+	for i := range s {
+		p[i] = &s[i]
+	}
+	return p
+}
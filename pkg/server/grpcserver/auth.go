@@ -0,0 +1,152 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/go-chi/jwtauth/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/auth"
+	"github.com/getzep/zep/pkg/models"
+)
+
+// authenticator verifies the JWT or API key in the authorization metadata of requests, in the
+// same "Bearer <token>" form as the HTTP API's Authorization header. The verified token or
+// API key is added to the request context, so that auth.TenantIDFromContext can be used by
+// services.
+type authenticator struct {
+	verifier auth.TokenVerifier
+	// apiKeyStore verifies API keys. If nil, only JWTs are accepted.
+	apiKeyStore models.APIKeyStore
+}
+
+func newAuthenticator(cfg *config.Config, apiKeyStore models.APIKeyStore) *authenticator {
+	return &authenticator{verifier: auth.NewTokenVerifier(cfg), apiKeyStore: apiKeyStore}
+}
+
+func (a *authenticator) authenticate(
+	ctx context.Context,
+	fullMethod string,
+) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "missing authorization token")
+	}
+	bearer := values[0]
+	if len(bearer) <= 7 || !strings.EqualFold(bearer[:7], "bearer ") {
+		return nil, status.Error(codes.Unauthenticated, "authorization must be a bearer token")
+	}
+
+	if a.apiKeyStore != nil && strings.HasPrefix(bearer[7:], models.APIKeyPrefix) {
+		return a.authenticateAPIKey(ctx, bearer[7:], fullMethod)
+	}
+
+	token, err := a.verifier.VerifyToken(ctx, bearer[7:])
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, jwtauth.ErrorReason(err).Error())
+	}
+
+	return jwtauth.NewContext(ctx, token, nil), nil
+}
+
+// authenticateAPIKey verifies an API key, and that it grants the scope of the method: read for
+// the methods that get or search records, and write for others
+func (a *authenticator) authenticateAPIKey(
+	ctx context.Context,
+	key string,
+	fullMethod string,
+) (context.Context, error) {
+	apiKey, err := a.apiKeyStore.VerifyAPIKey(ctx, key)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, status.Error(codes.Unauthenticated, "invalid api key")
+		}
+		return nil, statusError(err)
+	}
+
+	scope := models.APIKeyScopeWrite
+	method := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+	if strings.HasPrefix(method, "Get") || strings.HasPrefix(method, "Search") {
+		scope = models.APIKeyScopeRead
+	}
+	if !apiKey.Scope.Allows(scope) {
+		return nil, status.Errorf(codes.PermissionDenied, "api key lacks the %s scope", scope)
+	}
+
+	return models.WithAPIKey(ctx, apiKey), nil
+}
+
+func (a *authenticator) unary(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	ctx, err := a.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a *authenticator) stream(
+	srv interface{},
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+}
+
+// projectContext scopes ctx to the project of its API key or of its token's project_id claim,
+// or to the default project, as the HTTP API's ProjectMiddleware does
+func projectContext(ctx context.Context) context.Context {
+	projectID := auth.ProjectIDFromContext(ctx)
+	if apiKey := models.APIKeyFromContext(ctx); apiKey != nil {
+		projectID = apiKey.ProjectID
+	}
+	if projectID == "" {
+		projectID = models.DefaultProjectID
+	}
+	return models.WithProjectID(ctx, projectID)
+}
+
+func scopeProjectUnary(
+	ctx context.Context,
+	req interface{},
+	_ *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	return handler(projectContext(ctx), req)
+}
+
+func scopeProjectStream(
+	srv interface{},
+	ss grpc.ServerStream,
+	_ *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	return handler(srv, &contextStream{ServerStream: ss, ctx: projectContext(ss.Context())})
+}
+
+// contextStream is a ServerStream with a context derived from the stream's own, such as the
+// context of its verified token
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
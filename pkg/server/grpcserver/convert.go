@@ -0,0 +1,293 @@
+package grpcserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/server/grpcserver/zepv1"
+)
+
+// metadataToStruct converts metadata to a Struct. Metadata is converted via JSON, as
+// metadata read from the database holds numbers as json.Number, which structpb doesn't
+// support.
+func metadataToStruct(metadata map[string]interface{}) (*structpb.Struct, error) {
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	s := &structpb.Struct{}
+	if err := s.UnmarshalJSON(b); err != nil {
+		return nil, fmt.Errorf("failed to convert metadata: %w", err)
+	}
+	return s, nil
+}
+
+func structToMetadata(s *structpb.Struct) map[string]interface{} {
+	if s == nil {
+		return nil
+	}
+	return s.AsMap()
+}
+
+func timeToTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func timestampToTime(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
+func uuidToString(id uuid.UUID) string {
+	if id == uuid.Nil {
+		return ""
+	}
+	return id.String()
+}
+
+// parseUUID parses an optional UUID, returning uuid.Nil if it's empty
+func parseUUID(field string, s string) (uuid.UUID, error) {
+	if s == "" {
+		return uuid.Nil, nil
+	}
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return uuid.Nil, models.NewBadRequestError(fmt.Sprintf("%s is not a valid uuid", field))
+	}
+	return id, nil
+}
+
+func sessionToProto(session *models.Session) (*zepv1.Session, error) {
+	metadata, err := metadataToStruct(session.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	s := &zepv1.Session{
+		Uuid:      uuidToString(session.UUID),
+		SessionId: session.SessionID,
+		Metadata:  metadata,
+		Language:  session.Language,
+		CreatedAt: timeToTimestamp(session.CreatedAt),
+		UpdatedAt: timeToTimestamp(session.UpdatedAt),
+	}
+	if session.UserID != nil {
+		s.UserId = *session.UserID
+	}
+	return s, nil
+}
+
+func messageToProto(message *models.Message) (*zepv1.Message, error) {
+	metadata, err := metadataToStruct(message.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	return &zepv1.Message{
+		Uuid:       uuidToString(message.UUID),
+		CreatedAt:  timeToTimestamp(message.CreatedAt),
+		Role:       message.Role,
+		Content:    message.Content,
+		Metadata:   metadata,
+		TokenCount: int32(message.TokenCount),
+		IsSystem:   message.IsSystem,
+	}, nil
+}
+
+func messageFromProto(message *zepv1.Message) (models.Message, error) {
+	messageUUID, err := parseUUID("message uuid", message.GetUuid())
+	if err != nil {
+		return models.Message{}, err
+	}
+	m := models.Message{
+		UUID:       messageUUID,
+		Role:       message.GetRole(),
+		Content:    message.GetContent(),
+		Metadata:   structToMetadata(message.GetMetadata()),
+		TokenCount: int(message.GetTokenCount()),
+		IsSystem:   message.GetIsSystem(),
+	}
+	if createdAt := timestampToTime(message.GetCreatedAt()); createdAt != nil {
+		m.CreatedAt = *createdAt
+	}
+	return m, nil
+}
+
+func summaryToProto(summary *models.Summary) (*zepv1.Summary, error) {
+	metadata, err := metadataToStruct(summary.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	return &zepv1.Summary{
+		Uuid:              uuidToString(summary.UUID),
+		CreatedAt:         timeToTimestamp(summary.CreatedAt),
+		Content:           summary.Content,
+		RecentMessageUuid: uuidToString(summary.SummaryPointUUID),
+		Metadata:          metadata,
+		TokenCount:        int32(summary.TokenCount),
+	}, nil
+}
+
+func summaryFromProto(summary *zepv1.Summary) (*models.Summary, error) {
+	summaryUUID, err := parseUUID("summary uuid", summary.GetUuid())
+	if err != nil {
+		return nil, err
+	}
+	summaryPointUUID, err := parseUUID("recent_message_uuid", summary.GetRecentMessageUuid())
+	if err != nil {
+		return nil, err
+	}
+	s := &models.Summary{
+		UUID:             summaryUUID,
+		Content:          summary.GetContent(),
+		SummaryPointUUID: summaryPointUUID,
+		Metadata:         structToMetadata(summary.GetMetadata()),
+		TokenCount:       int(summary.GetTokenCount()),
+	}
+	if createdAt := timestampToTime(summary.GetCreatedAt()); createdAt != nil {
+		s.CreatedAt = *createdAt
+	}
+	return s, nil
+}
+
+func memoryToProto(memory *models.Memory) (*zepv1.Memory, error) {
+	metadata, err := metadataToStruct(memory.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	m := &zepv1.Memory{
+		Messages: make([]*zepv1.Message, len(memory.Messages)),
+		Metadata: metadata,
+	}
+	for i := range memory.Messages {
+		if m.Messages[i], err = messageToProto(&memory.Messages[i]); err != nil {
+			return nil, err
+		}
+	}
+	if memory.Summary != nil {
+		if m.Summary, err = summaryToProto(memory.Summary); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func memoryFromProto(memory *zepv1.Memory) (*models.Memory, error) {
+	m := &models.Memory{
+		Messages: make([]models.Message, len(memory.GetMessages())),
+		Metadata: structToMetadata(memory.GetMetadata()),
+	}
+	var err error
+	for i, message := range memory.GetMessages() {
+		if m.Messages[i], err = messageFromProto(message); err != nil {
+			return nil, err
+		}
+	}
+	if memory.GetSummary() != nil {
+		if m.Summary, err = summaryFromProto(memory.GetSummary()); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func memorySearchResultToProto(
+	result *models.MemorySearchResult,
+) (*zepv1.MemorySearchResult, error) {
+	metadata, err := metadataToStruct(result.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	r := &zepv1.MemorySearchResult{
+		Metadata: metadata,
+		Dist:     result.Dist,
+	}
+	if result.Message != nil {
+		if r.Message, err = messageToProto(result.Message); err != nil {
+			return nil, err
+		}
+	}
+	if result.Summary != nil {
+		if r.Summary, err = summaryToProto(result.Summary); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func collectionToProto(collection *models.DocumentCollection) (*zepv1.DocumentCollection, error) {
+	metadata, err := metadataToStruct(collection.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	c := &zepv1.DocumentCollection{
+		Uuid:                uuidToString(collection.UUID),
+		CreatedAt:           timeToTimestamp(collection.CreatedAt),
+		UpdatedAt:           timeToTimestamp(collection.UpdatedAt),
+		Name:                collection.Name,
+		Description:         collection.Description,
+		Metadata:            metadata,
+		EmbeddingService:    collection.EmbeddingService,
+		EmbeddingModelName:  collection.EmbeddingModelName,
+		EmbeddingDimensions: int32(collection.EmbeddingDimensions),
+		IsAutoEmbedded:      collection.IsAutoEmbedded,
+		IsNormalized:        collection.IsNormalized,
+		IsIndexed:           collection.IsIndexed,
+	}
+	if collection.DocumentCollectionCounts != nil {
+		c.DocumentCount = int32(collection.DocumentCount)
+		c.DocumentEmbeddedCount = int32(collection.DocumentEmbeddedCount)
+	}
+	return c, nil
+}
+
+func documentToProto(document *models.DocumentResponse) (*zepv1.Document, error) {
+	metadata, err := metadataToStruct(document.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	return &zepv1.Document{
+		Uuid:       uuidToString(document.UUID),
+		CreatedAt:  timeToTimestamp(document.CreatedAt),
+		UpdatedAt:  timeToTimestamp(document.UpdatedAt),
+		DocumentId: document.DocumentID,
+		Content:    document.Content,
+		Metadata:   metadata,
+		Embedding:  document.Embedding,
+		IsEmbedded: document.IsEmbedded,
+	}, nil
+}
+
+// documentFromProto validates a NewDocument and converts it to a Document
+func documentFromProto(document *zepv1.NewDocument) (models.Document, error) {
+	request := models.CreateDocumentRequest{
+		DocumentID: document.GetDocumentId(),
+		Content:    document.GetContent(),
+		Metadata:   structToMetadata(document.GetMetadata()),
+		Embedding:  document.GetEmbedding(),
+	}
+	if err := validate.Struct(request); err != nil {
+		return models.Document{}, models.NewBadRequestError(err.Error())
+	}
+	return models.Document{
+		DocumentBase: models.DocumentBase{
+			DocumentID: request.DocumentID,
+			Content:    request.Content,
+			Metadata:   request.Metadata,
+		},
+		Embedding: request.Embedding,
+	}, nil
+}
@@ -0,0 +1,154 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/server/grpcserver/zepv1"
+)
+
+var _ zepv1.DocumentServiceServer = &documentService{}
+
+type documentService struct {
+	zepv1.UnimplementedDocumentServiceServer
+	appState *models.AppState
+}
+
+func (s *documentService) GetCollection(
+	ctx context.Context,
+	req *zepv1.GetCollectionRequest,
+) (*zepv1.DocumentCollection, error) {
+	collectionName, err := collectionNameFromRequest(req.GetCollectionName())
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	collection, err := s.appState.DocumentStore.GetCollection(ctx, collectionName)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	resp, err := collectionToProto(&collection)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return resp, nil
+}
+
+func (s *documentService) CreateDocuments(
+	ctx context.Context,
+	req *zepv1.CreateDocumentsRequest,
+) (*zepv1.CreateDocumentsResponse, error) {
+	uuids, err := s.createDocuments(ctx, req)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &zepv1.CreateDocumentsResponse{Uuids: uuids}, nil
+}
+
+func (s *documentService) CreateDocumentsStream(
+	stream zepv1.DocumentService_CreateDocumentsStreamServer,
+) error {
+	var uuids []string
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(&zepv1.CreateDocumentsResponse{Uuids: uuids})
+		}
+		if err != nil {
+			return err
+		}
+
+		created, err := s.createDocuments(stream.Context(), req)
+		if err != nil {
+			return statusError(err)
+		}
+		uuids = append(uuids, created...)
+	}
+}
+
+// createDocuments validates and creates the documents of a request, returning their UUIDs
+func (s *documentService) createDocuments(
+	ctx context.Context,
+	req *zepv1.CreateDocumentsRequest,
+) ([]string, error) {
+	collectionName, err := collectionNameFromRequest(req.GetCollectionName())
+	if err != nil {
+		return nil, err
+	}
+
+	documents := make([]models.Document, len(req.GetDocuments()))
+	for i, document := range req.GetDocuments() {
+		if documents[i], err = documentFromProto(document); err != nil {
+			return nil, err
+		}
+	}
+
+	created, err := s.appState.DocumentStore.CreateDocuments(ctx, collectionName, documents)
+	if err != nil {
+		return nil, err
+	}
+
+	uuids := make([]string, len(created))
+	for i, id := range created {
+		uuids[i] = id.String()
+	}
+	return uuids, nil
+}
+
+func (s *documentService) SearchCollection(
+	ctx context.Context,
+	req *zepv1.SearchCollectionRequest,
+) (*zepv1.SearchCollectionResponse, error) {
+	collectionName, err := collectionNameFromRequest(req.GetCollectionName())
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	payload := &models.DocumentSearchPayload{
+		CollectionName: collectionName,
+		Text:           req.GetText(),
+		Embedding:      req.GetEmbedding(),
+		Metadata:       structToMetadata(req.GetMetadata()),
+		SearchType:     models.SearchType(req.GetSearchType()),
+		MMRLambda:      req.GetMmrLambda(),
+	}
+
+	page, err := s.appState.DocumentStore.SearchCollection(
+		ctx,
+		payload,
+		int(req.GetLimit()),
+		0,
+		0,
+	)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	resp := &zepv1.SearchCollectionResponse{
+		Results:     make([]*zepv1.DocumentSearchResult, len(page.Results)),
+		QueryVector: page.QueryVector,
+	}
+	for i, result := range page.Results {
+		r := &zepv1.DocumentSearchResult{Score: result.Score}
+		if result.DocumentResponse != nil {
+			if r.Document, err = documentToProto(result.DocumentResponse); err != nil {
+				return nil, statusError(err)
+			}
+		}
+		resp.Results[i] = r
+	}
+	return resp, nil
+}
+
+// collectionNameFromRequest returns the lowercased collection name, as collection names
+// are case-insensitive in the HTTP API
+func collectionNameFromRequest(collectionName string) (string, error) {
+	if collectionName == "" {
+		return "", models.NewBadRequestError("collection_name is required")
+	}
+	return strings.ToLower(collectionName), nil
+}
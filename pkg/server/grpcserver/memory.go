@@ -0,0 +1,133 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/server/grpcserver/zepv1"
+)
+
+var _ zepv1.MemoryServiceServer = &memoryService{}
+
+type memoryService struct {
+	zepv1.UnimplementedMemoryServiceServer
+	appState *models.AppState
+}
+
+func (s *memoryService) GetMemory(
+	ctx context.Context,
+	req *zepv1.GetMemoryRequest,
+) (*zepv1.Memory, error) {
+	memory, err := s.appState.MemoryStore.GetMemory(
+		ctx,
+		s.appState,
+		req.GetSessionId(),
+		int(req.GetLastN()),
+		nil,
+	)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	if memory == nil || memory.Messages == nil {
+		return nil, statusError(models.NewNotFoundError("memory for session " + req.GetSessionId()))
+	}
+
+	resp, err := memoryToProto(memory)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return resp, nil
+}
+
+func (s *memoryService) PutMemory(
+	ctx context.Context,
+	req *zepv1.PutMemoryRequest,
+) (*zepv1.PutMemoryResponse, error) {
+	count, err := s.putMemory(ctx, req)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &zepv1.PutMemoryResponse{MessageCount: int32(count)}, nil
+}
+
+func (s *memoryService) PutMemoryStream(stream zepv1.MemoryService_PutMemoryStreamServer) error {
+	var total int
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(&zepv1.PutMemoryResponse{MessageCount: int32(total)})
+		}
+		if err != nil {
+			return err
+		}
+
+		count, err := s.putMemory(stream.Context(), req)
+		if err != nil {
+			return statusError(err)
+		}
+		total += count
+	}
+}
+
+// putMemory stores the memory of a request, returning the number of messages stored
+func (s *memoryService) putMemory(ctx context.Context, req *zepv1.PutMemoryRequest) (int, error) {
+	memory, err := memoryFromProto(req.GetMemory())
+	if err != nil {
+		return 0, err
+	}
+	err = s.appState.MemoryStore.PutMemory(ctx, s.appState, req.GetSessionId(), memory, false)
+	if err != nil {
+		return 0, err
+	}
+	return len(memory.Messages), nil
+}
+
+func (s *memoryService) DeleteMemory(
+	ctx context.Context,
+	req *zepv1.DeleteMemoryRequest,
+) (*zepv1.DeleteMemoryResponse, error) {
+	if err := s.appState.MemoryStore.DeleteSession(ctx, req.GetSessionId()); err != nil {
+		return nil, statusError(err)
+	}
+	return &zepv1.DeleteMemoryResponse{}, nil
+}
+
+func (s *memoryService) SearchMemory(
+	ctx context.Context,
+	req *zepv1.SearchMemoryRequest,
+) (*zepv1.SearchMemoryResponse, error) {
+	payload := &models.MemorySearchPayload{
+		Text:         req.GetText(),
+		Metadata:     structToMetadata(req.GetMetadata()),
+		SearchScope:  models.SearchScope(req.GetSearchScope()),
+		SearchType:   models.SearchType(req.GetSearchType()),
+		MMRLambda:    req.GetMmrLambda(),
+		VectorFilter: structToMetadata(req.GetVectorFilter()),
+		StartDate:    timestampToTime(req.GetStartDate()),
+		EndDate:      timestampToTime(req.GetEndDate()),
+		Rerank:       req.GetRerank(),
+	}
+
+	results, err := s.appState.MemoryStore.SearchMemory(
+		ctx,
+		s.appState,
+		req.GetSessionId(),
+		payload,
+		int(req.GetLimit()),
+	)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	resp := &zepv1.SearchMemoryResponse{
+		Results: make([]*zepv1.MemorySearchResult, len(results)),
+	}
+	for i := range results {
+		if resp.Results[i], err = memorySearchResultToProto(&results[i]); err != nil {
+			return nil, statusError(err)
+		}
+	}
+	return resp, nil
+}
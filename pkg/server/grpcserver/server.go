@@ -0,0 +1,107 @@
+// Package grpcserver serves the session, memory and document APIs over gRPC, alongside the
+// HTTP API. The services are defined in proto/zep/v1/zep.proto.
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/getzep/zep/internal"
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/server/grpcserver/zepv1"
+)
+
+// DefaultMaxRequestSize is the maximum size of a request message if
+// server.max_request_size isn't set. It matches the HTTP API's default.
+const DefaultMaxRequestSize = 5 << 20 // 5MB
+
+var log = internal.GetLogger()
+
+var validate = validator.New()
+
+// Create creates a new gRPC server with the given app state. Requests must have a valid JWT
+// if auth.required is set, and are scoped to the project of the JWT or the default project.
+func Create(appState *models.AppState) *grpc.Server {
+	maxRequestSize := appState.Config.Server.MaxRequestSize
+	if maxRequestSize == 0 {
+		maxRequestSize = DefaultMaxRequestSize
+	}
+
+	unaryInterceptors := []grpc.UnaryServerInterceptor{recoverUnary}
+	streamInterceptors := []grpc.StreamServerInterceptor{recoverStream}
+	if appState.Config.Auth.Required {
+		log.Info("JWT authentication required for the gRPC API")
+		authenticator := newAuthenticator(appState.Config, appState.APIKeyStore)
+		unaryInterceptors = append(unaryInterceptors, authenticator.unary)
+		streamInterceptors = append(streamInterceptors, authenticator.stream)
+	}
+	unaryInterceptors = append(unaryInterceptors, scopeProjectUnary)
+	streamInterceptors = append(streamInterceptors, scopeProjectStream)
+
+	srv := grpc.NewServer(
+		grpc.MaxRecvMsgSize(int(maxRequestSize)),
+		grpc.ChainUnaryInterceptor(unaryInterceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
+	)
+	zepv1.RegisterSessionServiceServer(srv, &sessionService{appState: appState})
+	zepv1.RegisterMemoryServiceServer(srv, &memoryService{appState: appState})
+	zepv1.RegisterDocumentServiceServer(srv, &documentService{appState: appState})
+
+	return srv
+}
+
+// statusError converts an error returned by a store to a gRPC status error, using the
+// same classification as the HTTP API's handlertools.RenderError
+func statusError(err error) error {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, models.ErrBadRequest), strings.Contains(err.Error(), "is deleted"):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		log.Error(err)
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// recoverUnary and recoverStream return an Internal error if a handler panics, rather than
+// crashing the server
+func recoverUnary(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (resp interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recovered(info.FullMethod, r)
+		}
+	}()
+	return handler(ctx, req)
+}
+
+func recoverStream(
+	srv interface{},
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recovered(info.FullMethod, r)
+		}
+	}()
+	return handler(srv, ss)
+}
+
+func recovered(method string, r interface{}) error {
+	log.Errorf("panic in gRPC method %s: %v", method, r)
+	return status.Error(codes.Internal, fmt.Sprintf("internal error in %s", method))
+}
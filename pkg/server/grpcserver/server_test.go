@@ -0,0 +1,306 @@
+package grpcserver
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/auth"
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/server/grpcserver/zepv1"
+)
+
+// fakeMemoryStore implements the MemoryStore methods used by the tests. Other methods panic.
+type fakeMemoryStore struct {
+	models.MemoryStore[any]
+	sessions    map[string]*models.Session
+	memories    map[string][]models.Message
+	activeCount int64
+	// projectIDs holds the project of each created session's request
+	projectIDs map[string]string
+}
+
+func (s *fakeMemoryStore) GetSession(
+	_ context.Context,
+	_ *models.AppState,
+	sessionID string,
+) (*models.Session, error) {
+	session, ok := s.sessions[sessionID]
+	if !ok {
+		return nil, models.NewNotFoundError("session " + sessionID)
+	}
+	return session, nil
+}
+
+func (s *fakeMemoryStore) CreateSession(
+	ctx context.Context,
+	_ *models.AppState,
+	request *models.CreateSessionRequest,
+) (*models.Session, error) {
+	session := &models.Session{
+		UUID:      uuid.New(),
+		SessionID: request.SessionID,
+		UserID:    request.UserID,
+		Metadata:  request.Metadata,
+	}
+	s.sessions[request.SessionID] = session
+	if s.projectIDs != nil {
+		s.projectIDs[request.SessionID], _ = models.ProjectIDFromContext(ctx)
+	}
+	return session, nil
+}
+
+func (s *fakeMemoryStore) GetActiveSessionCount(_ context.Context, _ string) (int64, error) {
+	return s.activeCount, nil
+}
+
+func (s *fakeMemoryStore) PutMemory(
+	_ context.Context,
+	_ *models.AppState,
+	sessionID string,
+	memory *models.Memory,
+	_ bool,
+) error {
+	s.memories[sessionID] = append(s.memories[sessionID], memory.Messages...)
+	return nil
+}
+
+func newTestClient(
+	t *testing.T,
+	appState *models.AppState,
+) (zepv1.SessionServiceClient, zepv1.MemoryServiceClient) {
+	listener := bufconn.Listen(1 << 20)
+	srv := Create(appState)
+	go func() {
+		_ = srv.Serve(listener)
+	}()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.DialContext(
+		context.Background(),
+		"bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return zepv1.NewSessionServiceClient(conn), zepv1.NewMemoryServiceClient(conn)
+}
+
+func newTestAppState(store *fakeMemoryStore) *models.AppState {
+	return &models.AppState{
+		MemoryStore: store,
+		Config: &config.Config{
+			Auth: config.AuthConfig{Secret: "test-secret", Required: true},
+		},
+	}
+}
+
+func withToken(t *testing.T, cfg *config.Config, claims map[string]interface{}) context.Context {
+	_, token, err := auth.NewJWTAuth(cfg).Encode(claims)
+	require.NoError(t, err)
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func TestSessionService(t *testing.T) {
+	store := &fakeMemoryStore{
+		sessions: map[string]*models.Session{
+			"session": {
+				SessionID: "session",
+				// metadata read from the database holds numbers as json.Number
+				Metadata: map[string]interface{}{"count": json.Number("3")},
+			},
+		},
+	}
+	appState := newTestAppState(store)
+	sessions, _ := newTestClient(t, appState)
+	ctx := withToken(t, appState.Config, nil)
+
+	session, err := sessions.GetSession(ctx, &zepv1.GetSessionRequest{SessionId: "session"})
+	require.NoError(t, err)
+	assert.Equal(t, "session", session.SessionId)
+	assert.Equal(t, map[string]interface{}{"count": float64(3)}, session.Metadata.AsMap())
+
+	_, err = sessions.GetSession(ctx, &zepv1.GetSessionRequest{SessionId: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = sessions.GetSession(
+		context.Background(),
+		&zepv1.GetSessionRequest{SessionId: "session"},
+	)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	created, err := sessions.CreateSession(ctx, &zepv1.CreateSessionRequest{
+		SessionId: "new",
+		UserId:    "user",
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, created.Uuid)
+	assert.Equal(t, "user", created.UserId)
+	assert.Equal(t, "user", *store.sessions["new"].UserID)
+}
+
+func TestSessionService_Quota(t *testing.T) {
+	store := &fakeMemoryStore{sessions: map[string]*models.Session{}, activeCount: 2}
+	appState := newTestAppState(store)
+	appState.Config.Server.SessionQuota = 2
+	sessions, _ := newTestClient(t, appState)
+
+	ctx := withToken(t, appState.Config, map[string]interface{}{auth.TenantIDClaim: "tenant"})
+	_, err := sessions.CreateSession(ctx, &zepv1.CreateSessionRequest{SessionId: "new"})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// requests without a tenant aren't subject to the quota
+	ctx = withToken(t, appState.Config, nil)
+	_, err = sessions.CreateSession(ctx, &zepv1.CreateSessionRequest{SessionId: "new"})
+	assert.NoError(t, err)
+}
+
+func TestSessionService_Project(t *testing.T) {
+	store := &fakeMemoryStore{
+		sessions:   map[string]*models.Session{},
+		projectIDs: map[string]string{},
+	}
+	appState := newTestAppState(store)
+	sessions, _ := newTestClient(t, appState)
+
+	ctx := withToken(t, appState.Config, map[string]interface{}{auth.ProjectIDClaim: "project"})
+	_, err := sessions.CreateSession(ctx, &zepv1.CreateSessionRequest{SessionId: "scoped"})
+	require.NoError(t, err)
+	assert.Equal(t, "project", store.projectIDs["scoped"])
+
+	// tokens without a project are scoped to the default project
+	ctx = withToken(t, appState.Config, nil)
+	_, err = sessions.CreateSession(ctx, &zepv1.CreateSessionRequest{SessionId: "default"})
+	require.NoError(t, err)
+	assert.Equal(t, models.DefaultProjectID, store.projectIDs["default"])
+}
+
+func TestMemoryService_PutMemoryStream(t *testing.T) {
+	store := &fakeMemoryStore{memories: map[string][]models.Message{}}
+	appState := newTestAppState(store)
+	_, memory := newTestClient(t, appState)
+	ctx := withToken(t, appState.Config, nil)
+
+	stream, err := memory.PutMemoryStream(ctx)
+	require.NoError(t, err)
+	metadata, err := structpb.NewStruct(map[string]interface{}{"key": "value"})
+	require.NoError(t, err)
+	for _, sessionID := range []string{"session1", "session2", "session1"} {
+		err := stream.Send(&zepv1.PutMemoryRequest{
+			SessionId: sessionID,
+			Memory: &zepv1.Memory{
+				Messages: []*zepv1.Message{
+					{Role: "user", Content: "hello", Metadata: metadata},
+					{Role: "assistant", Content: "hi"},
+				},
+			},
+		})
+		require.NoError(t, err)
+	}
+	resp, err := stream.CloseAndRecv()
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(6), resp.MessageCount)
+	assert.Len(t, store.memories["session1"], 4)
+	assert.Len(t, store.memories["session2"], 2)
+	assert.Equal(t, map[string]interface{}{"key": "value"}, store.memories["session1"][0].Metadata)
+
+	// invalid requests fail the stream
+	stream, err = memory.PutMemoryStream(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&zepv1.PutMemoryRequest{
+		SessionId: "session1",
+		Memory:    &zepv1.Memory{Messages: []*zepv1.Message{{Uuid: "invalid"}}},
+	}))
+	_, err = stream.CloseAndRecv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestAuthenticator(t *testing.T) {
+	cfg := &config.Config{Auth: config.AuthConfig{Secret: "test-secret"}}
+	a := newAuthenticator(cfg, nil)
+
+	_, token, err := auth.NewJWTAuth(cfg).Encode(
+		map[string]interface{}{auth.TenantIDClaim: "tenant"},
+	)
+	require.NoError(t, err)
+
+	ctx, err := a.authenticate(
+		metadata.NewIncomingContext(
+			context.Background(),
+			metadata.Pairs("authorization", "Bearer "+token),
+		),
+		"/zep.v1.SessionService/CreateSession",
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "tenant", auth.TenantIDFromContext(ctx))
+	_, _, err = jwtauth.FromContext(ctx)
+	assert.NoError(t, err)
+
+	for _, value := range []string{token, "Bearer invalid"} {
+		_, err = a.authenticate(
+			metadata.NewIncomingContext(
+				context.Background(),
+				metadata.Pairs("authorization", value),
+			),
+			"/zep.v1.SessionService/CreateSession",
+		)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err), value)
+	}
+}
+
+type fakeAPIKeyStore struct {
+	models.APIKeyStore
+	apiKeys map[string]*models.APIKey
+}
+
+func (s *fakeAPIKeyStore) VerifyAPIKey(_ context.Context, key string) (*models.APIKey, error) {
+	apiKey, ok := s.apiKeys[key]
+	if !ok {
+		return nil, models.NewNotFoundError("api key")
+	}
+	return apiKey, nil
+}
+
+func TestAuthenticator_APIKey(t *testing.T) {
+	cfg := &config.Config{Auth: config.AuthConfig{Secret: "test-secret"}}
+	a := newAuthenticator(cfg, &fakeAPIKeyStore{apiKeys: map[string]*models.APIKey{
+		"zep_read": {ProjectID: "project1", Scope: models.APIKeyScopeRead},
+	}})
+
+	keyContext := func(key string) context.Context {
+		return metadata.NewIncomingContext(
+			context.Background(),
+			metadata.Pairs("authorization", "Bearer "+key),
+		)
+	}
+
+	ctx, err := a.authenticate(keyContext("zep_read"), "/zep.v1.SessionService/GetSession")
+	require.NoError(t, err)
+	projectID, _ := models.ProjectIDFromContext(projectContext(ctx))
+	assert.Equal(t, "project1", projectID)
+
+	_, err = a.authenticate(keyContext("zep_read"), "/zep.v1.SessionService/CreateSession")
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = a.authenticate(keyContext("zep_unknown"), "/zep.v1.SessionService/GetSession")
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
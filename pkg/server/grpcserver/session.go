@@ -0,0 +1,104 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/getzep/zep/pkg/auth"
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/server/grpcserver/zepv1"
+)
+
+var _ zepv1.SessionServiceServer = &sessionService{}
+
+type sessionService struct {
+	zepv1.UnimplementedSessionServiceServer
+	appState *models.AppState
+}
+
+func (s *sessionService) GetSession(
+	ctx context.Context,
+	req *zepv1.GetSessionRequest,
+) (*zepv1.Session, error) {
+	session, err := s.appState.MemoryStore.GetSession(ctx, s.appState, req.GetSessionId())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return s.sessionResponse(session)
+}
+
+func (s *sessionService) CreateSession(
+	ctx context.Context,
+	req *zepv1.CreateSessionRequest,
+) (*zepv1.Session, error) {
+	if err := s.checkSessionQuota(ctx); err != nil {
+		return nil, err
+	}
+
+	request := &models.CreateSessionRequest{
+		SessionID: req.GetSessionId(),
+		Metadata:  structToMetadata(req.GetMetadata()),
+		Language:  req.GetLanguage(),
+	}
+	if req.GetUserId() != "" {
+		userID := req.GetUserId()
+		request.UserID = &userID
+	}
+
+	session, err := s.appState.MemoryStore.CreateSession(ctx, s.appState, request)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return s.sessionResponse(session)
+}
+
+func (s *sessionService) UpdateSession(
+	ctx context.Context,
+	req *zepv1.UpdateSessionRequest,
+) (*zepv1.Session, error) {
+	session, err := s.appState.MemoryStore.UpdateSession(
+		ctx,
+		s.appState,
+		&models.UpdateSessionRequest{
+			SessionID: req.GetSessionId(),
+			Metadata:  structToMetadata(req.GetMetadata()),
+		},
+	)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return s.sessionResponse(session)
+}
+
+func (s *sessionService) sessionResponse(session *models.Session) (*zepv1.Session, error) {
+	resp, err := sessionToProto(session)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return resp, nil
+}
+
+// checkSessionQuota returns a ResourceExhausted error if the requesting tenant has reached
+// server.session_quota
+func (s *sessionService) checkSessionQuota(ctx context.Context) error {
+	quota := s.appState.Config.Server.SessionQuota
+	tenantID := auth.TenantIDFromContext(ctx)
+	if quota <= 0 || tenantID == "" {
+		return nil
+	}
+
+	count, err := s.appState.MemoryStore.GetActiveSessionCount(ctx, tenantID)
+	if err != nil {
+		return statusError(err)
+	}
+	if count >= quota {
+		return status.Error(
+			codes.ResourceExhausted,
+			fmt.Sprintf("session quota of %d exceeded for tenant %s", quota, tenantID),
+		)
+	}
+	return nil
+}
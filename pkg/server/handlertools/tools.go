@@ -4,9 +4,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/getzep/zep/internal"
 	"github.com/getzep/zep/pkg/models"
@@ -56,6 +58,20 @@ func BoolFromQuery(r *http.Request, param string) (bool, error) {
 	return false, nil
 }
 
+// TimeFromQuery extracts a query string value and parses it as an RFC 3339 time. If the
+// value is empty, it returns the zero time.
+func TimeFromQuery(r *http.Request, param string) (time.Time, error) {
+	p := r.URL.Query().Get(param)
+	if p == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, p)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s, must be an RFC 3339 time: %w", param, err)
+	}
+	return t, nil
+}
+
 // EncodeJSON encodes data into JSON and writes it to the response writer.
 func EncodeJSON(w http.ResponseWriter, data interface{}) error {
 	return json.NewEncoder(w).Encode(data)
@@ -66,7 +82,8 @@ func DecodeJSON(r *http.Request, data interface{}) error {
 	return json.NewDecoder(r.Body).Decode(&data)
 }
 
-// RenderError renders an error response.
+// RenderError renders an error response. Session quota errors are rendered as a 429 Too Many
+// Requests, with Retry-After set to the seconds the client is asked to wait.
 func RenderError(w http.ResponseWriter, err error, status int) {
 	if err.Error() == "http: request body too large" {
 		status = http.StatusRequestEntityTooLarge
@@ -83,6 +100,14 @@ func RenderError(w http.ResponseWriter, err error, status int) {
 	if strings.Contains(err.Error(), "is deleted") || errors.Is(err, models.ErrBadRequest) {
 		status = http.StatusBadRequest
 	}
+	var quotaErr *models.SessionQuotaExceededError
+	if errors.As(err, &quotaErr) {
+		status = http.StatusTooManyRequests
+		if quotaErr.RetryAfter > 0 {
+			retryAfter := int(math.Ceil(quotaErr.RetryAfter.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		}
+	}
 
 	http.Error(w, err.Error(), status)
 }
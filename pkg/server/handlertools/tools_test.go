@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -18,6 +19,21 @@ func TestExtractQueryStringValueToInt(t *testing.T) {
 	assert.Equal(t, 123, got, "extractQueryStringValueToInt() = %v, want %v", got, 123)
 }
 
+func TestTimeFromQuery(t *testing.T) {
+	req := httptest.NewRequest("GET", "/?since=2024-02-01T10:00:00Z", nil)
+	got, err := TimeFromQuery(req, "since")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC), got)
+
+	got, err = TimeFromQuery(req, "until")
+	assert.NoError(t, err)
+	assert.True(t, got.IsZero())
+
+	req = httptest.NewRequest("GET", "/?since=yesterday", nil)
+	_, err = TimeFromQuery(req, "since")
+	assert.Error(t, err)
+}
+
 func TestParseUUIDFromURL(t *testing.T) {
 	r := chi.NewRouter()
 	r.Get("/{uuid}", func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/server/apihandlers"
+)
+
+func TestReadinessHandler(t *testing.T) {
+	ok := models.HealthCheck{
+		Name:  "ok",
+		Check: func(context.Context) error { return nil },
+	}
+	skipped := models.HealthCheck{
+		Name:  "skipped",
+		Check: func(context.Context) error { return models.ErrHealthCheckSkipped },
+	}
+	failed := models.HealthCheck{
+		Name:  "failed",
+		Check: func(context.Context) error { return errors.New("unreachable") },
+	}
+
+	tests := []struct {
+		name           string
+		checks         []models.HealthCheck
+		expectedStatus int
+		expected       []models.DependencyHealth
+	}{
+		{
+			name:           "ready",
+			checks:         []models.HealthCheck{ok, skipped},
+			expectedStatus: http.StatusOK,
+			expected: []models.DependencyHealth{
+				{Name: "ok", Status: models.HealthStatusOK},
+				{Name: "skipped", Status: models.HealthStatusSkipped},
+			},
+		},
+		{
+			name:           "not ready",
+			checks:         []models.HealthCheck{ok, failed},
+			expectedStatus: http.StatusServiceUnavailable,
+			expected: []models.DependencyHealth{
+				{Name: "ok", Status: models.HealthStatusOK},
+				{Name: "failed", Status: models.HealthStatusError, Error: "unreachable"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appState := &models.AppState{HealthChecks: tt.checks}
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/healthz/ready", nil)
+			apihandlers.ReadinessHandler(appState).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			var response models.ReadinessResponse
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+			for i := range response.Dependencies {
+				response.Dependencies[i].LatencyMs = 0
+			}
+			assert.Equal(t, tt.expected, response.Dependencies)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/testutils"
+)
+
+func TestImportRoute(t *testing.T) {
+	sessionID := testutils.GenerateRandomString(10)
+	lines := []string{
+		fmt.Sprintf(
+			`{"session_id": "%s", "messages": [{"role": "user", "content": "Hello"}]}`,
+			sessionID,
+		),
+		`not json`,
+		fmt.Sprintf(
+			`{"session_id": "%s", "messages": [{"role": "assistant", "content": "Hi"}]}`,
+			sessionID,
+		),
+	}
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", "conversations.jsonl")
+	require.NoError(t, err)
+	_, err = part.Write([]byte(strings.Join(lines, "\n")))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	resp, err := http.Post(
+		testServer.URL+"/api/v1/import",
+		writer.FormDataContentType(),
+		body,
+	)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	var name string
+	var progress models.ImportProgress
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &progress)
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, scanner.Err())
+
+	assert.Equal(t, "done", name)
+	assert.Equal(t, 3, progress.Lines)
+	assert.Equal(t, 2, progress.Imported)
+	assert.Equal(t, 2, progress.Messages)
+	assert.Equal(t, 1, progress.Failed)
+	require.Len(t, progress.Errors, 1)
+	assert.Equal(t, 2, progress.Errors[0].Line)
+
+	messageList, err := appState.MemoryStore.ListMessages(
+		testCtx,
+		appState,
+		sessionID,
+		0,
+		0,
+		nil,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, 2, messageList.TotalCount)
+}
+
+func TestImportRoute_MissingFile(t *testing.T) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	require.NoError(t, writer.WriteField("other", "value"))
+	require.NoError(t, writer.Close())
+
+	resp, err := http.Post(
+		testServer.URL+"/api/v1/import",
+		writer.FormDataContentType(),
+		body,
+	)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -192,3 +192,74 @@ func TestGetSessionListRoute(t *testing.T) {
 	// Check the number of sessions returned
 	assert.Equal(t, numSessions, len(sessions))
 }
+
+func TestBatchPostMemoryRoute(t *testing.T) {
+	sessionStore := postgres.NewSessionDAO(testDB)
+
+	// Messages can't be added to a deleted session
+	deletedSessionID := testutils.GenerateRandomString(10)
+	_, err := sessionStore.Create(
+		testCtx,
+		&models.CreateSessionRequest{SessionID: deletedSessionID},
+	)
+	assert.NoError(t, err)
+	assert.NoError(t, sessionStore.Delete(testCtx, deletedSessionID, false))
+
+	newSessionID := testutils.GenerateRandomString(10)
+	messages := []models.Message{
+		{Role: "user", Content: "Hello"},
+		{Role: "assistant", Content: "Hi there"},
+	}
+	body, err := json.Marshal(models.BatchMemoryRequest{
+		Sessions: []models.SessionMemory{
+			{SessionID: newSessionID, Memory: models.Memory{Messages: messages}},
+			{SessionID: deletedSessionID, Memory: models.Memory{Messages: messages}},
+		},
+	})
+	assert.NoError(t, err)
+
+	resp, err := http.Post(
+		testServer.URL+"/api/v1/sessions/memory",
+		"application/json",
+		bytes.NewBuffer(body),
+	)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var batchResponse models.BatchMemoryResponse
+	err = json.NewDecoder(resp.Body).Decode(&batchResponse)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, batchResponse.FailedCount)
+	assert.Equal(t, []models.BatchMemoryResult{
+		{SessionID: newSessionID, Status: http.StatusOK},
+		{
+			SessionID: deletedSessionID,
+			Status:    http.StatusNotFound,
+			Error:     batchResponse.Results[1].Error,
+		},
+	}, batchResponse.Results)
+	assert.NotEmpty(t, batchResponse.Results[1].Error)
+
+	// The successful session's messages were stored
+	messageList, err := appState.MemoryStore.ListMessages(
+		testCtx,
+		appState,
+		newSessionID,
+		0,
+		0,
+		nil,
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, messageList.TotalCount)
+
+	// An empty batch is rejected
+	resp, err = http.Post(
+		testServer.URL+"/api/v1/sessions/memory",
+		"application/json",
+		bytes.NewBufferString(`{"sessions": []}`),
+	)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
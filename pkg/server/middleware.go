@@ -1,9 +1,27 @@
 package server
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
 
 	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/internal"
+	"github.com/getzep/zep/pkg/auth"
+	"github.com/getzep/zep/pkg/metrics"
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/server/handlertools"
 )
 
 const versionHeader = "X-Zep-Version"
@@ -22,3 +40,441 @@ func SendVersion(next http.Handler) http.Handler {
 	}
 	return http.HandlerFunc(fn)
 }
+
+// CorrelationIDMiddleware is a middleware that adds the ID assigned to a request by
+// middleware.RequestID, which is the client's X-Request-Id if it sent one, to the request's
+// context and to the response's X-Request-Id header. The ID is logged by the stores and
+// propagated to the tasks the request publishes.
+func CorrelationIDMiddleware(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		requestID := middleware.GetReqID(r.Context())
+		if requestID == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set(middleware.RequestIDHeader, requestID)
+		next.ServeHTTP(w, r.WithContext(internal.WithRequestID(r.Context(), requestID)))
+	}
+	return http.HandlerFunc(fn)
+}
+
+// sessionQuotaCacheTTL is how long tenant session quotas are cached
+const sessionQuotaCacheTTL = 30 * time.Second
+
+type cachedQuota struct {
+	quota   int64
+	expires time.Time
+}
+
+// TenantSessionQuotaMiddleware is a middleware that scopes requests to their tenant's session
+// quota. The stores refuse to create sessions once the tenant has reached its quota, whichever
+// route creates them. Requests to create a session with POST /sessions are refused before
+// they're handled, using store's count of the tenant's sessions. Refusals are a 429 Too Many
+// Requests, with Retry-After set to when the quota is next refreshed. The tenant is
+// identified by the JWT's tenant_id claim. Requests without a tenant are not subject to a
+// quota. Quotas are cached for 30 seconds.
+func TenantSessionQuotaMiddleware(
+	store models.SessionStorer,
+	quotaStore models.QuotaStore,
+) func(http.Handler) http.Handler {
+	var mu sync.Mutex
+	cache := make(map[string]cachedQuota)
+	var lastEviction time.Time
+
+	getQuota := func(tenantID string) (int64, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		now := time.Now()
+		if cached, ok := cache[tenantID]; ok && now.Before(cached.expires) {
+			return cached.quota, nil
+		}
+		// evict expired quotas, so that the cache doesn't grow with every tenant seen
+		if now.Sub(lastEviction) >= sessionQuotaCacheTTL {
+			for id, cached := range cache {
+				if !now.Before(cached.expires) {
+					delete(cache, id)
+				}
+			}
+			lastEviction = now
+		}
+
+		quota, err := quotaStore.GetSessionQuota(tenantID)
+		if err != nil {
+			return 0, err
+		}
+		cache[tenantID] = cachedQuota{quota: quota, expires: now.Add(sessionQuotaCacheTTL)}
+		return quota, nil
+	}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			tenantID := auth.TenantIDFromContext(r.Context())
+			if tenantID == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			quota, err := getQuota(tenantID)
+			if err != nil {
+				handlertools.RenderError(
+					w,
+					fmt.Errorf("failed to get session quota: %w", err),
+					http.StatusInternalServerError,
+				)
+				return
+			}
+
+			sessionQuota := models.SessionQuota{
+				TenantID:   tenantID,
+				Quota:      quota,
+				RetryAfter: sessionQuotaCacheTTL,
+			}
+			if quota > 0 && r.Method == http.MethodPost &&
+				strings.HasSuffix(strings.TrimSuffix(r.URL.Path, "/"), "/sessions") {
+				count, err := store.GetActiveSessionCount(r.Context(), tenantID)
+				if err != nil {
+					handlertools.RenderError(w, err, http.StatusInternalServerError)
+					return
+				}
+				if count >= quota {
+					handlertools.RenderError(
+						w,
+						models.NewSessionQuotaExceededError(sessionQuota),
+						http.StatusTooManyRequests,
+					)
+					return
+				}
+			}
+
+			ctx := models.WithSessionQuota(r.Context(), sessionQuota)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// ProjectMiddleware is a middleware that scopes requests to the project of their API key or
+// of the JWT's project_id claim, or to the default project if there's no token or the token
+// has no project. The stores only read and write the records of a request's project.
+func ProjectMiddleware(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		projectID := auth.ProjectIDFromContext(r.Context())
+		if apiKey := models.APIKeyFromContext(r.Context()); apiKey != nil {
+			projectID = apiKey.ProjectID
+		}
+		if projectID == "" {
+			projectID = models.DefaultProjectID
+		}
+		next.ServeHTTP(w, r.WithContext(models.WithProjectID(r.Context(), projectID)))
+	}
+	return http.HandlerFunc(fn)
+}
+
+// AdminProjectMiddleware is a middleware that rejects requests of projects other than the
+// default project with a 403 Forbidden, and lifts the project scope of the requests it
+// accepts. It guards the admin routes, which act on the whole deployment.
+func AdminProjectMiddleware(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		projectID, _ := models.ProjectIDFromContext(r.Context())
+		if projectID != models.DefaultProjectID {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(models.WithoutProject(r.Context())))
+	}
+	return http.HandlerFunc(fn)
+}
+
+// AuthMiddleware is a middleware that authenticates requests by the bearer token of their
+// Authorization header, rejecting requests without a valid token with a 401 Unauthorized.
+// Tokens starting with models.APIKeyPrefix are verified by apiKeyStore, if it isn't nil, and
+// their API key is added to the request context. Other tokens are verified as JWTs.
+func AuthMiddleware(
+	cfg *config.Config,
+	apiKeyStore models.APIKeyStore,
+) func(http.Handler) http.Handler {
+	verifyJWT := auth.JWTVerifier(cfg)
+	return func(next http.Handler) http.Handler {
+		jwtNext := verifyJWT(jwtauth.Authenticator(next))
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			token := jwtauth.TokenFromHeader(r)
+			if apiKeyStore == nil || !strings.HasPrefix(token, models.APIKeyPrefix) {
+				jwtNext.ServeHTTP(w, r)
+				return
+			}
+
+			apiKey, err := apiKeyStore.VerifyAPIKey(r.Context(), token)
+			if err != nil {
+				if errors.Is(err, models.ErrNotFound) {
+					http.Error(
+						w,
+						http.StatusText(http.StatusUnauthorized),
+						http.StatusUnauthorized,
+					)
+					return
+				}
+				handlertools.RenderError(w, err, http.StatusInternalServerError)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(models.WithAPIKey(r.Context(), apiKey)))
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// readOnlyPOSTSuffixes are the path suffixes of the POST routes that only read, such as
+// searches and questions answered from a session's memory
+var readOnlyPOSTSuffixes = []string{
+	"/search",
+	"/search/",
+	"/qa",
+	"/graphql",
+	"/document/list/get",
+}
+
+// APIKeyScopeMiddleware is a middleware that rejects requests whose API key lacks the scope
+// they require with a 403 Forbidden. GET requests and the POST requests that only read require
+// the read scope, and other requests the write scope. Requests without an API key aren't
+// restricted.
+func APIKeyScopeMiddleware(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		scope := models.APIKeyScopeWrite
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			scope = models.APIKeyScopeRead
+		case http.MethodPost:
+			for _, suffix := range readOnlyPOSTSuffixes {
+				if strings.HasSuffix(r.URL.Path, suffix) {
+					scope = models.APIKeyScopeRead
+					break
+				}
+			}
+		}
+		if !apiKeyAllows(r, scope) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}
+	return http.HandlerFunc(fn)
+}
+
+// AuditMiddleware is a middleware that records the requests that put, delete, search or
+// export memory in auditLog, along with the API key or JWT subject that made them, once
+// they've been served. Requests that only read are recorded if includeReads is set. It must
+// follow the authentication and project middlewares.
+func AuditMiddleware(
+	auditLog models.AuditLogStore,
+	includeReads bool,
+) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			action := auditAction(r)
+			if action == models.AuditActionRead && !includeReads {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			event := &models.AuditEvent{
+				Action:     action,
+				Method:     r.Method,
+				StatusCode: ww.Status(),
+				RequestID:  internal.RequestIDFromContext(r.Context()),
+			}
+			if event.StatusCode == 0 {
+				event.StatusCode = http.StatusOK
+			}
+			event.ProjectID, _ = models.ProjectIDFromContext(r.Context())
+			event.ActorType, event.Actor = auditActor(r.Context())
+			if rctx := chi.RouteContext(r.Context()); rctx != nil {
+				event.Route = rctx.RoutePattern()
+				event.SessionID = rctx.URLParam("sessionId")
+				event.UserID = rctx.URLParam("userId")
+				event.CollectionName = rctx.URLParam("collectionName")
+			}
+
+			// the event is recorded even if the client went away before the response
+			err := auditLog.PutAuditEvent(context.WithoutCancel(r.Context()), event)
+			if err != nil {
+				log.Errorf("failed to record audit event of %s %s: %v", r.Method, r.URL.Path, err)
+			}
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// auditAction returns the action of a request. Exports and searches are told apart from
+// other reads by their path, as are the POST requests that only read.
+func auditAction(r *http.Request) models.AuditAction {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		if strings.HasSuffix(strings.TrimSuffix(r.URL.Path, "/"), "/export") {
+			return models.AuditActionExport
+		}
+		return models.AuditActionRead
+	case http.MethodDelete:
+		return models.AuditActionDelete
+	case http.MethodPost:
+		for _, suffix := range readOnlyPOSTSuffixes {
+			if strings.HasSuffix(r.URL.Path, suffix) {
+				if strings.Contains(suffix, "/search") {
+					return models.AuditActionSearch
+				}
+				return models.AuditActionRead
+			}
+		}
+	}
+	return models.AuditActionPut
+}
+
+// auditActor returns who made the request of ctx: its API key's UUID, or its JWT's subject,
+// or tenant if the JWT has no subject
+func auditActor(ctx context.Context) (models.AuditActorType, string) {
+	if apiKey := models.APIKeyFromContext(ctx); apiKey != nil {
+		return models.AuditActorAPIKey, apiKey.UUID.String()
+	}
+	if token, _, _ := jwtauth.FromContext(ctx); token != nil {
+		if token.Subject() != "" {
+			return models.AuditActorUser, token.Subject()
+		}
+		return models.AuditActorUser, auth.TenantIDFromContext(ctx)
+	}
+	return models.AuditActorAnonymous, ""
+}
+
+// AdminScopeMiddleware is a middleware that rejects requests whose API key lacks the admin
+// scope with a 403 Forbidden
+func AdminScopeMiddleware(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		if !apiKeyAllows(r, models.APIKeyScopeAdmin) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}
+	return http.HandlerFunc(fn)
+}
+
+// apiKeyAllows reports whether the request's API key, if it has one, grants scope
+func apiKeyAllows(r *http.Request, scope models.APIKeyScope) bool {
+	apiKey := models.APIKeyFromContext(r.Context())
+	return apiKey == nil || apiKey.Scope.Allows(scope)
+}
+
+type rateLimit struct {
+	key    string
+	bucket models.TokenBucket
+}
+
+// RateLimitMiddleware is a middleware that rejects requests with a 429 Too Many Requests if a
+// token can't be taken from the bucket of their API key or tenant, or of their client IP.
+// Retry-After is set to the seconds until the bucket has a token. Requests are allowed if the
+// limiter fails, so that an unavailable Redis server doesn't take down the API.
+func RateLimitMiddleware(
+	limiter models.RateLimiter,
+	cfg *config.RateLimitConfig,
+) func(http.Handler) http.Handler {
+	perKey := tokenBucket(&cfg.PerKey)
+	perIP := tokenBucket(&cfg.PerIP)
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			var limits []rateLimit
+			if key := rateLimitKey(r); key != "" && perKey.Rate > 0 {
+				limits = append(limits, rateLimit{key: key, bucket: perKey})
+			}
+			if perIP.Rate > 0 {
+				limits = append(limits, rateLimit{key: "ip:" + clientIP(r), bucket: perIP})
+			}
+
+			for _, limit := range limits {
+				taken, wait, err := limiter.Take(r.Context(), limit.key, limit.bucket)
+				if err != nil {
+					log.Errorf("rate limit of %s not enforced: %v", limit.key, err)
+					continue
+				}
+				if !taken {
+					retryAfter := int(math.Max(1, math.Ceil(wait.Seconds())))
+					w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+					http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// tokenBucket returns the bucket of a rate limit's config, defaulting the burst to the rate
+func tokenBucket(cfg *config.TokenBucketConfig) models.TokenBucket {
+	burst := cfg.Burst
+	if burst <= 0 {
+		burst = int(math.Max(1, math.Ceil(cfg.RequestsPerSecond)))
+	}
+	return models.TokenBucket{Rate: cfg.RequestsPerSecond, Burst: burst}
+}
+
+// rateLimitKey identifies the API key or tenant of a request, or returns an empty string if
+// the request has neither
+func rateLimitKey(r *http.Request) string {
+	if apiKey := models.APIKeyFromContext(r.Context()); apiKey != nil {
+		return "key:" + apiKey.UUID.String()
+	}
+	if tenantID := auth.TenantIDFromContext(r.Context()); tenantID != "" {
+		return "tenant:" + tenantID
+	}
+	return ""
+}
+
+// clientIP returns the IP of the request's RemoteAddr, which the RealIP middleware sets from
+// the X-Forwarded-For and X-Real-IP headers of proxied requests
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+var (
+	httpRequests = metrics.NewCounterVec(
+		"zep_http_requests_total",
+		"HTTP requests by method, route and status code",
+		"method", "route", "status",
+	)
+	httpRequestDuration = metrics.NewHistogramVec(
+		"zep_http_request_duration_seconds",
+		"Latency of HTTP requests by method and route",
+		metrics.DefaultBuckets,
+		"method", "route",
+	)
+)
+
+// MetricsMiddleware is a middleware that records the count and latency of requests. Requests
+// are labeled by their route pattern, so that path parameters don't partition the metrics, or
+// as unmatched if they don't match a route.
+func MetricsMiddleware(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		httpRequests.Inc(r.Method, route, strconv.Itoa(status))
+		httpRequestDuration.Observe(time.Since(start).Seconds(), r.Method, route)
+	}
+	return http.HandlerFunc(fn)
+}
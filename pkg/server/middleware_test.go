@@ -0,0 +1,341 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/internal"
+	"github.com/getzep/zep/pkg/auth"
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/ratelimit"
+	"github.com/getzep/zep/pkg/server/handlertools"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingQuotaStore struct {
+	quota int64
+	calls int
+}
+
+func (c *countingQuotaStore) GetSessionQuota(_ string) (int64, error) {
+	c.calls++
+	return c.quota, nil
+}
+
+// sessionCountStore is a session store that only counts sessions
+type sessionCountStore struct {
+	models.SessionStorer
+	count int64
+}
+
+func (s *sessionCountStore) GetActiveSessionCount(context.Context, string) (int64, error) {
+	return s.count, nil
+}
+
+func TestTenantSessionQuotaMiddleware(t *testing.T) {
+	tokenAuth := jwtauth.New(auth.JwtAlg, []byte("secret"), nil)
+	token, _, err := tokenAuth.Encode(map[string]interface{}{auth.TenantIDClaim: "tenant"})
+	require.NoError(t, err)
+
+	sessionStore := &sessionCountStore{count: 1}
+	quotaStore := &countingQuotaStore{quota: 2}
+	var quota models.SessionQuota
+	var hasQuota bool
+	var handled bool
+	handler := TenantSessionQuotaMiddleware(sessionStore, quotaStore)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handled = true
+			quota, hasQuota = models.SessionQuotaFromContext(r.Context())
+			handlertools.RenderError(
+				w,
+				models.NewSessionQuotaExceededError(quota),
+				http.StatusInternalServerError,
+			)
+		}),
+	)
+
+	doRequest := func(method string, path string, withTenant bool) *httptest.ResponseRecorder {
+		handled = false
+		req := httptest.NewRequest(method, path, nil)
+		if withTenant {
+			req = req.WithContext(jwtauth.NewContext(req.Context(), token, nil))
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	doRequest(http.MethodPost, "/api/v1/sessions", true)
+	assert.True(t, handled)
+	assert.True(t, hasQuota)
+	assert.Equal(t, "tenant", quota.TenantID)
+	assert.Equal(t, int64(2), quota.Quota)
+
+	// the quota is cached
+	doRequest(http.MethodPost, "/api/v1/sessions", true)
+	assert.Equal(t, 1, quotaStore.calls)
+
+	// requests without a tenant are not subject to a quota
+	doRequest(http.MethodPost, "/api/v1/sessions", false)
+	assert.False(t, hasQuota)
+
+	t.Run("quota errors set Retry-After", func(t *testing.T) {
+		rr := doRequest(http.MethodPost, "/api/v1/sessions/s/memory", true)
+		assert.True(t, handled)
+		assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+		assert.Equal(t, "30", rr.Header().Get("Retry-After"))
+	})
+
+	t.Run("session creation is refused once the quota is reached", func(t *testing.T) {
+		sessionStore.count = 2
+		defer func() { sessionStore.count = 1 }()
+
+		rr := doRequest(http.MethodPost, "/api/v1/sessions", true)
+		assert.False(t, handled)
+		assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+		assert.Equal(t, "30", rr.Header().Get("Retry-After"))
+
+		// other requests are handled, and refused by the stores if they create a session
+		doRequest(http.MethodGet, "/api/v1/sessions", true)
+		assert.True(t, handled)
+	})
+}
+
+func TestProjectMiddleware(t *testing.T) {
+	tokenAuth := jwtauth.New(auth.JwtAlg, []byte("secret"), nil)
+	token, _, err := tokenAuth.Encode(map[string]interface{}{auth.ProjectIDClaim: "project"})
+	require.NoError(t, err)
+
+	var projectID string
+	handler := ProjectMiddleware(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			projectID, _ = models.ProjectIDFromContext(r.Context())
+		}),
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/sessions", nil)
+	req = req.WithContext(jwtauth.NewContext(req.Context(), token, nil))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "project", projectID)
+
+	// requests without a project are scoped to the default project
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/sessions", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, models.DefaultProjectID, projectID)
+}
+
+func TestCorrelationIDMiddleware(t *testing.T) {
+	var requestID string
+	handler := middleware.RequestID(CorrelationIDMiddleware(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID = internal.RequestIDFromContext(r.Context())
+		}),
+	))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/sessions", nil)
+	req.Header.Set(middleware.RequestIDHeader, "client-request")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, "client-request", requestID)
+	assert.Equal(t, "client-request", rr.Header().Get(middleware.RequestIDHeader))
+
+	// requests without an ID are assigned one
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/sessions", nil))
+	assert.NotEmpty(t, requestID)
+	assert.Equal(t, requestID, rr.Header().Get(middleware.RequestIDHeader))
+}
+
+func TestAdminProjectMiddleware(t *testing.T) {
+	var scoped bool
+	handler := AdminProjectMiddleware(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, scoped = models.ProjectIDFromContext(r.Context())
+		}),
+	)
+
+	doRequest := func(projectID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/dead_letters", nil)
+		req = req.WithContext(models.WithProjectID(req.Context(), projectID))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	assert.Equal(t, http.StatusForbidden, doRequest("project").Code)
+
+	// the admin routes act on the whole deployment
+	assert.Equal(t, http.StatusOK, doRequest(models.DefaultProjectID).Code)
+	assert.False(t, scoped)
+}
+
+type fakeAPIKeyStore struct {
+	models.APIKeyStore
+	apiKeys map[string]*models.APIKey
+}
+
+func (s *fakeAPIKeyStore) VerifyAPIKey(_ context.Context, key string) (*models.APIKey, error) {
+	apiKey, ok := s.apiKeys[key]
+	if !ok {
+		return nil, models.NewNotFoundError("api key")
+	}
+	return apiKey, nil
+}
+
+func TestAuthMiddleware_APIKey(t *testing.T) {
+	cfg := &config.Config{Auth: config.AuthConfig{Secret: "secret"}}
+	token := auth.GenerateJWT(cfg, "")
+
+	var apiKey *models.APIKey
+	handler := AuthMiddleware(cfg, &fakeAPIKeyStore{apiKeys: map[string]*models.APIKey{
+		"zep_key": {ProjectID: "project", Scope: models.APIKeyScopeRead},
+	}})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			apiKey = models.APIKeyFromContext(r.Context())
+		}),
+	)
+
+	doRequest := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/sessions", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	assert.Equal(t, http.StatusOK, doRequest("zep_key").Code)
+	require.NotNil(t, apiKey)
+	assert.Equal(t, "project", apiKey.ProjectID)
+
+	apiKey = nil
+	assert.Equal(t, http.StatusOK, doRequest(token).Code)
+	assert.Nil(t, apiKey)
+
+	assert.Equal(t, http.StatusUnauthorized, doRequest("zep_unknown").Code)
+	assert.Equal(t, http.StatusUnauthorized, doRequest("invalid").Code)
+}
+
+func TestAPIKeyScopeMiddleware(t *testing.T) {
+	handler := APIKeyScopeMiddleware(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+	)
+
+	doRequest := func(scope models.APIKeyScope, method, path string) int {
+		req := httptest.NewRequest(method, path, nil)
+		if scope != "" {
+			req = req.WithContext(models.WithAPIKey(req.Context(), &models.APIKey{Scope: scope}))
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	read := models.APIKeyScopeRead
+	assert.Equal(t, http.StatusOK, doRequest(read, http.MethodGet, "/api/v1/sessions"))
+	assert.Equal(t, http.StatusOK, doRequest(read, http.MethodPost, "/api/v1/sessions/s/search"))
+	assert.Equal(t, http.StatusOK, doRequest(read, http.MethodPost, "/api/v1/sessions/s/qa"))
+	assert.Equal(t, http.StatusForbidden, doRequest(read, http.MethodPost, "/api/v1/sessions"))
+	assert.Equal(
+		t,
+		http.StatusOK,
+		doRequest(models.APIKeyScopeWrite, http.MethodDelete, "/api/v1/sessions/s"),
+	)
+
+	// requests without an API key aren't restricted
+	assert.Equal(t, http.StatusOK, doRequest("", http.MethodPost, "/api/v1/sessions"))
+}
+
+type recordingAuditLog struct {
+	models.AuditLogStore
+	events []*models.AuditEvent
+}
+
+func (a *recordingAuditLog) PutAuditEvent(_ context.Context, event *models.AuditEvent) error {
+	a.events = append(a.events, event)
+	return nil
+}
+
+func TestAuditMiddleware(t *testing.T) {
+	auditLog := &recordingAuditLog{}
+	router := chi.NewRouter()
+	router.Use(AuditMiddleware(auditLog, false))
+	router.HandleFunc("/api/v1/sessions/{sessionId}/*", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	apiKey := &models.APIKey{UUID: uuid.New()}
+	doRequest := func(method, path string) {
+		req := httptest.NewRequest(method, path, nil)
+		ctx := models.WithProjectID(req.Context(), "project")
+		ctx = models.WithAPIKey(ctx, apiKey)
+		router.ServeHTTP(httptest.NewRecorder(), req.WithContext(ctx))
+	}
+
+	doRequest(http.MethodPost, "/api/v1/sessions/s1/memory")
+	doRequest(http.MethodPost, "/api/v1/sessions/s1/search")
+	doRequest(http.MethodGet, "/api/v1/sessions/s1/export")
+	doRequest(http.MethodDelete, "/api/v1/sessions/s1/memory")
+	// reads aren't recorded unless they're included
+	doRequest(http.MethodGet, "/api/v1/sessions/s1/memory")
+
+	require.Len(t, auditLog.events, 4)
+	actions := make([]models.AuditAction, len(auditLog.events))
+	for i, event := range auditLog.events {
+		actions[i] = event.Action
+	}
+	assert.Equal(t, []models.AuditAction{
+		models.AuditActionPut,
+		models.AuditActionSearch,
+		models.AuditActionExport,
+		models.AuditActionDelete,
+	}, actions)
+
+	event := auditLog.events[0]
+	assert.Equal(t, "project", event.ProjectID)
+	assert.Equal(t, models.AuditActorAPIKey, event.ActorType)
+	assert.Equal(t, apiKey.UUID.String(), event.Actor)
+	assert.Equal(t, "s1", event.SessionID)
+	assert.Equal(t, "/api/v1/sessions/{sessionId}/*", event.Route)
+	assert.Equal(t, http.StatusCreated, event.StatusCode)
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	handler := RateLimitMiddleware(ratelimit.NewMemoryLimiter(), &config.RateLimitConfig{
+		PerKey: config.TokenBucketConfig{RequestsPerSecond: 0.5, Burst: 2},
+		PerIP:  config.TokenBucketConfig{RequestsPerSecond: 0.1},
+	})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+	)
+
+	doRequest := func(apiKey *models.APIKey, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/sessions/s/memory", nil)
+		req.RemoteAddr = remoteAddr
+		if apiKey != nil {
+			req = req.WithContext(models.WithAPIKey(req.Context(), apiKey))
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// the per-IP burst defaults to the rate, rounded up
+	assert.Equal(t, http.StatusOK, doRequest(nil, "10.0.0.1:1234").Code)
+	rr := doRequest(nil, "10.0.0.1:5678")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "10", rr.Header().Get("Retry-After"))
+
+	apiKey := &models.APIKey{UUID: uuid.New()}
+	assert.Equal(t, http.StatusOK, doRequest(apiKey, "10.0.0.2:1234").Code)
+	assert.Equal(t, http.StatusOK, doRequest(apiKey, "10.0.0.3:1234").Code)
+	rr = doRequest(apiKey, "10.0.0.4:1234")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "2", rr.Header().Get("Retry-After"))
+}
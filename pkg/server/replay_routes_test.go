@@ -0,0 +1,158 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/server/apihandlers"
+	"github.com/getzep/zep/pkg/testutils"
+	"github.com/getzep/zep/pkg/webfetch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionReplayRoute(t *testing.T) {
+	sessionID := testutils.GenerateRandomString(10)
+	messages := []models.Message{
+		{Role: "user", Content: "Hello"},
+		{Role: "user", Content: "How are you?"},
+	}
+	err := appState.MemoryStore.PutMemory(
+		testCtx,
+		appState,
+		sessionID,
+		&models.Memory{Messages: messages},
+		true,
+	)
+	require.NoError(t, err)
+
+	var received int32
+	target := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&received, 1)
+			var msg models.Message
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+			_ = json.NewEncoder(w).Encode(models.Message{
+				Role:    "assistant",
+				Content: "reply to " + msg.Content,
+			})
+		}),
+	)
+	defer target.Close()
+
+	body, err := json.Marshal(models.SessionReplayRequest{
+		SessionID: sessionID,
+		TargetURL: target.URL,
+		DelayMS:   1,
+	})
+	require.NoError(t, err)
+
+	resp, err := http.Post(
+		testServer.URL+"/api/v1/sessions/replay",
+		"application/json",
+		bytes.NewBuffer(body),
+	)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	var events []string
+	var last models.SessionReplayEvent
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if name, ok := strings.CutPrefix(line, "event: "); ok {
+			events = append(events, name)
+		}
+		if data, ok := strings.CutPrefix(line, "data: "); ok {
+			require.NoError(t, json.Unmarshal([]byte(data), &last))
+		}
+	}
+	require.NoError(t, scanner.Err())
+
+	assert.Equal(t, []string{"progress", "progress", "done"}, events)
+	assert.Equal(t, 2, last.Replayed)
+	assert.Equal(t, 2, last.Total)
+	assert.Empty(t, last.Error)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&received))
+
+	clone, err := appState.MemoryStore.GetMessageList(
+		testCtx,
+		appState,
+		last.CloneSessionID,
+		1,
+		10,
+	)
+	require.NoError(t, err)
+	require.Len(t, clone.Messages, 4)
+	assert.Equal(t, "Hello", clone.Messages[0].Content)
+	assert.Equal(t, "reply to Hello", clone.Messages[1].Content)
+	assert.Equal(t, "assistant", clone.Messages[1].Role)
+}
+
+func TestSessionReplayRouteInvalidRequest(t *testing.T) {
+	body, err := json.Marshal(models.SessionReplayRequest{
+		SessionID: "some-session",
+		TargetURL: "file:///etc/passwd",
+	})
+	require.NoError(t, err)
+
+	resp, err := http.Post(
+		testServer.URL+"/api/v1/sessions/replay",
+		"application/json",
+		bytes.NewBuffer(body),
+	)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestSessionReplayRoutePrivateTarget(t *testing.T) {
+	sessionID := testutils.GenerateRandomString(10)
+	err := appState.MemoryStore.PutMemory(
+		testCtx,
+		appState,
+		sessionID,
+		&models.Memory{Messages: []models.Message{{Role: "user", Content: "Hello"}}},
+		true,
+	)
+	require.NoError(t, err)
+
+	var received int32
+	target := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&received, 1)
+		}),
+	)
+	defer target.Close()
+
+	// the test server opts in to private networks, so the handler is created without it
+	cfg := *appState.Config
+	cfg.Server.Replay.AllowPrivateNetworks = false
+	state := *appState
+	state.Config = &cfg
+	handler := apihandlers.NewSessionReplayHandler(&state)
+
+	body, err := json.Marshal(models.SessionReplayRequest{
+		SessionID: sessionID,
+		TargetURL: target.URL,
+	})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sessions/replay", bytes.NewBuffer(body))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "event: error")
+	assert.Contains(t, rec.Body.String(), webfetch.ErrPrivateAddress.Error())
+	assert.Equal(t, int32(0), atomic.LoadInt32(&received))
+}
@@ -10,10 +10,10 @@ import (
 
 	"github.com/getzep/zep/internal"
 
-	"github.com/getzep/zep/pkg/auth"
+	"github.com/getzep/zep/pkg/metrics"
 	"github.com/getzep/zep/pkg/server/apihandlers"
 	"github.com/getzep/zep/pkg/server/webhandlers"
-	"github.com/go-chi/jwtauth/v5"
+	"github.com/getzep/zep/pkg/store"
 
 	httpLogger "github.com/chi-middleware/logrus-logger"
 	"github.com/getzep/zep/pkg/models"
@@ -58,6 +58,9 @@ func setupRouter(appState *models.AppState) *chi.Mux {
 
 	router := chi.NewRouter()
 	router.Use(
+		// the request ID is assigned first so that it's logged with the request
+		middleware.RequestID,
+		CorrelationIDMiddleware,
 		httpLogger.Logger(RouterName, log),
 		otelchi.Middleware(
 			RouterName,
@@ -65,14 +68,17 @@ func setupRouter(appState *models.AppState) *chi.Mux {
 			otelchi.WithRequestMethodInSpanName(true),
 		),
 		middleware.RequestSize(maxRequestSize),
+		MetricsMiddleware,
 		middleware.Recoverer,
-		middleware.RequestID,
 		middleware.RealIP,
 		middleware.CleanPath,
 		SendVersion,
 		middleware.Heartbeat("/healthz"),
 	)
 
+	// /healthz only reports that the server is up. /healthz/ready also checks its dependencies.
+	router.Get("/healthz/ready", apihandlers.ReadinessHandler(appState))
+
 	// Only setup web routes if enabled
 	if appState.Config.Server.WebEnabled {
 		log.Info("Web interface enabled")
@@ -81,6 +87,11 @@ func setupRouter(appState *models.AppState) *chi.Mux {
 		log.Info("Web interface disabled")
 	}
 
+	if appState.Config.Server.MetricsEnabled {
+		log.Info("Metrics enabled at /metrics")
+		router.Handle("/metrics", metrics.Handler())
+	}
+
 	setupAPIRoutes(router, appState)
 
 	return router
@@ -152,49 +163,140 @@ func setupAPIRoutes(router chi.Router, appState *models.AppState) {
 	router.Route("/api/v1", func(r chi.Router) {
 		// JWT authentication on all API routes
 		if appState.Config.Auth.Required {
-			log.Info("JWT authentication required")
-			r.Use(auth.JWTVerifier(appState.Config))
-			r.Use(jwtauth.Authenticator)
+			log.Info("Authentication required")
+			r.Use(AuthMiddleware(appState.Config, appState.APIKeyStore))
 		}
+		r.Use(ProjectMiddleware)
+		r.Use(APIKeyScopeMiddleware)
+		if appState.Config.Server.SessionQuota > 0 {
+			r.Use(TenantSessionQuotaMiddleware(
+				appState.MemoryStore,
+				store.NewStaticQuotaStore(appState.Config.Server.SessionQuota),
+			))
+		}
+		if appState.AuditLogStore != nil {
+			log.Info("Audit log enabled")
+			r.Use(AuditMiddleware(
+				appState.AuditLogStore,
+				appState.Config.DataConfig.AuditLog.IncludeReads,
+			))
+		}
+
+		rateLimit := rateLimitMiddleware(appState)
+		setupSessionRoutes(r, appState, rateLimit)
+		setupUserRoutes(r, appState, rateLimit)
+		setupCollectionRoutes(r, appState, rateLimit)
+		r.Post("/graphql", apihandlers.GraphQLHandler(appState))
+		r.Post("/import", apihandlers.ImportHandler(appState))
+		setupAPIKeyRoutes(r, appState)
+		setupAdminRoutes(r, appState)
+	})
+}
 
-		setupSessionRoutes(r, appState)
-		setupUserRoutes(r, appState)
-		setupCollectionRoutes(r, appState)
+// rateLimitMiddleware returns the middleware of the rate limits of the memory and search
+// endpoints, which passes requests through if there's no rate limiter
+func rateLimitMiddleware(appState *models.AppState) func(http.Handler) http.Handler {
+	if appState.RateLimiter == nil {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	return RateLimitMiddleware(appState.RateLimiter, &appState.Config.Server.RateLimit)
+}
+
+func setupAPIKeyRoutes(router chi.Router, appState *models.AppState) {
+	router.Route("/api_keys", func(r chi.Router) {
+		r.Use(AdminScopeMiddleware)
+		r.Get("/", apihandlers.ListAPIKeysHandler(appState))
+		r.Post("/", apihandlers.CreateAPIKeyHandler(appState))
+		r.Delete("/{apiKeyUUID}", apihandlers.RevokeAPIKeyHandler(appState))
+	})
+}
+
+func setupAdminRoutes(router chi.Router, appState *models.AppState) {
+	router = router.With(AdminScopeMiddleware, AdminProjectMiddleware)
+	router.Route("/admin/dead_letters", func(r chi.Router) {
+		r.Get("/", apihandlers.ListDeadLettersHandler(appState))
+		r.Route("/{deadLetterUUID}", func(r chi.Router) {
+			r.Get("/", apihandlers.GetDeadLetterHandler(appState))
+			r.Delete("/", apihandlers.DeleteDeadLetterHandler(appState))
+			r.Post("/retry", apihandlers.RetryDeadLetterHandler(appState))
+		})
 	})
+	router.Delete("/admin/sessions/{sessionId}", apihandlers.PurgeSessionHandler(appState))
+	router.Get("/admin/retention/audits", apihandlers.ListRetentionAuditsHandler(appState))
+	router.Get("/admin/audit_log", apihandlers.ListAuditEventsHandler(appState))
+	router.Get("/admin/stats", apihandlers.GetStatsHandler(appState))
+	router.Get("/admin/stats/ingestion", apihandlers.GetIngestionRateHandler(appState))
 }
 
-func setupSessionRoutes(router chi.Router, appState *models.AppState) {
+func setupSessionRoutes(
+	router chi.Router,
+	appState *models.AppState,
+	rateLimit func(http.Handler) http.Handler,
+) {
 	router.Get("/sessions", apihandlers.GetSessionListHandler(appState))
 	router.Post("/sessions", apihandlers.CreateSessionHandler(appState))
+	router.With(rateLimit).
+		Method(http.MethodPost, "/sessions/replay", apihandlers.NewSessionReplayHandler(appState))
+	router.With(rateLimit).Post("/sessions/memory", apihandlers.BatchPostMemoryHandler(appState))
 	router.Route("/sessions/{sessionId}", func(r chi.Router) {
 		r.Get("/", apihandlers.GetSessionHandler(appState))
 		r.Patch("/", apihandlers.UpdateSessionHandler(appState))
+		r.Post("/restore", apihandlers.RestoreSessionHandler(appState))
+		r.Post("/merge", apihandlers.MergeSessionsHandler(appState))
+		r.Post("/fork", apihandlers.ForkSessionHandler(appState))
 		// Memory-related routes
 		r.Route("/memory", func(r chi.Router) {
+			r.Use(rateLimit)
 			r.Get("/", apihandlers.GetMemoryHandler(appState))
 			r.Post("/", apihandlers.PostMemoryHandler(appState))
 			r.Delete("/", apihandlers.DeleteMemoryHandler(appState))
 		})
+		r.Get("/messages", apihandlers.GetMessagesHandler(appState))
+		r.Patch("/messages/{messageId}", apihandlers.UpdateMessageHandler(appState))
+		r.Delete("/messages/{messageId}", apihandlers.DeleteMessageHandler(appState))
+		r.Get("/summaries", apihandlers.GetSummaryListHandler(appState))
+		r.Post("/summary/regenerate", apihandlers.RegenerateSummaryHandler(appState))
+		r.Get("/stream", apihandlers.SessionStreamHandler(appState))
+		r.Get("/export", apihandlers.ExportSessionHandler(appState))
 		// Memory search-related routes
 		r.Route("/search", func(r chi.Router) {
+			r.Use(rateLimit)
 			r.Post("/", apihandlers.SearchMemoryHandler(appState))
 		})
+		r.With(rateLimit).Post("/qa", apihandlers.SessionQAHandler(appState))
 	})
 }
 
-func setupUserRoutes(router chi.Router, appState *models.AppState) {
-	router.Post("/user", apihandlers.CreateUserHandler(appState))
-	router.Get("/user", apihandlers.ListAllUsersHandler(appState))
-	router.Route("/user/{userId}", func(r chi.Router) {
-		r.Get("/", apihandlers.GetUserHandler(appState))
-		r.Patch("/", apihandlers.UpdateUserHandler(appState))
-		r.Delete("/", apihandlers.DeleteUserHandler(appState))
-		r.Get("/sessions", apihandlers.ListUserSessionsHandler(appState))
-	})
+func setupUserRoutes(
+	router chi.Router,
+	appState *models.AppState,
+	rateLimit func(http.Handler) http.Handler,
+) {
+	// /users is the plural form of the /user routes, which remain for existing clients
+	for _, prefix := range []string{"/user", "/users"} {
+		router.Post(prefix, apihandlers.CreateUserHandler(appState))
+		router.Get(prefix, apihandlers.ListAllUsersHandler(appState))
+		router.Route(prefix+"/{userId}", func(r chi.Router) {
+			r.Get("/", apihandlers.GetUserHandler(appState))
+			r.Patch("/", apihandlers.UpdateUserHandler(appState))
+			r.Delete("/", apihandlers.DeleteUserHandler(appState))
+			r.Delete("/data", apihandlers.EraseUserDataHandler(appState))
+			r.Get("/sessions", apihandlers.ListUserSessionsHandler(appState))
+			r.Get("/facts", apihandlers.GetUserFactsHandler(appState))
+			r.Get("/entities", apihandlers.GetUserEntitiesHandler(appState))
+			r.Get("/entities/mentions", apihandlers.ListEntityMentionsHandler(appState))
+			r.With(rateLimit).Post("/search", apihandlers.SearchUserMemoryHandler(appState))
+		})
+	}
 }
 
-func setupCollectionRoutes(router chi.Router, appState *models.AppState) {
+func setupCollectionRoutes(
+	router chi.Router,
+	appState *models.AppState,
+	rateLimit func(http.Handler) http.Handler,
+) {
 	router.Get("/collection", apihandlers.GetCollectionListHandler(appState))
+	router.With(rateLimit).Post("/collections/search", apihandlers.SearchCollectionsHandler(appState))
 	router.Route("/collection/{collectionName}", func(r chi.Router) {
 		r.Post("/", apihandlers.CreateCollectionHandler(appState))
 		r.Get("/", apihandlers.GetCollectionHandler(appState))
@@ -202,14 +304,18 @@ func setupCollectionRoutes(router chi.Router, appState *models.AppState) {
 		r.Patch("/", apihandlers.UpdateCollectionHandler(appState))
 
 		// Document collection search-related routes
-		r.Post("/search", apihandlers.SearchDocumentsHandler(appState))
+		r.With(rateLimit).Post("/search", apihandlers.SearchDocumentsHandler(appState))
 
 		// Document collection index-related routes
+		r.Get("/index", apihandlers.GetCollectionIndexStatusHandler(appState))
 		r.Post("/index/create", apihandlers.CreateCollectionIndexHandler(appState))
+		r.Post("/index/reindex", apihandlers.ReindexCollectionHandler(appState))
 
 		// Document-related routes
 		r.Route("/document", func(r chi.Router) {
 			r.Post("/", apihandlers.CreateDocumentsHandler(appState))
+			r.Post("/upload", apihandlers.UploadDocumentsHandler(appState))
+			r.With(rateLimit).Post("/url", apihandlers.IngestURLHandler(appState))
 			// Single document routes (by UUID)
 			r.Route("/uuid/{documentUUID}", func(r chi.Router) {
 				r.Get("/", apihandlers.GetDocumentHandler(appState))
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/getzep/zep/internal"
+	"github.com/getzep/zep/pkg/events"
 	"github.com/getzep/zep/pkg/llms"
 	"github.com/getzep/zep/pkg/store/postgres"
 	"github.com/getzep/zep/pkg/tasks"
@@ -40,6 +41,8 @@ func setup() {
 
 	appState = &models.AppState{}
 	cfg := testutils.NewTestConfig()
+	// sessions are replayed to test servers on loopback addresses
+	cfg.Server.Replay.AllowPrivateNetworks = true
 
 	llmClient, err := llms.NewLLMClient(context.Background(), cfg)
 	if err != nil {
@@ -48,6 +51,7 @@ func setup() {
 
 	appState.LLMClient = llmClient
 	appState.Config = cfg
+	appState.SessionEvents = events.NewBroker()
 
 	// Initialize the database connection
 	testDB, err = postgres.NewPostgresConn(appState)
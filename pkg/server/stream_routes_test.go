@@ -0,0 +1,57 @@
+package server
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/store/postgres"
+	"github.com/getzep/zep/pkg/testutils"
+)
+
+func TestSessionStreamRoute(t *testing.T) {
+	sessionID := testutils.GenerateRandomString(10)
+	_, err := postgres.NewSessionDAO(testDB).Create(
+		testCtx,
+		&models.CreateSessionRequest{SessionID: sessionID},
+	)
+	require.NoError(t, err)
+
+	wsURL := "ws" + strings.TrimPrefix(testServer.URL, "http") + "/api/v1/sessions/"
+	ws, err := websocket.Dial(wsURL+sessionID+"/stream", "", testServer.URL)
+	require.NoError(t, err)
+	defer ws.Close()
+
+	// the subscription is created after the handshake, so wait before adding messages
+	time.Sleep(100 * time.Millisecond)
+
+	messages := []models.Message{{Role: "user", Content: "Hello"}}
+	err = appState.MemoryStore.PutMemory(
+		testCtx,
+		appState,
+		sessionID,
+		&models.Memory{Messages: messages},
+		false,
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, ws.SetReadDeadline(time.Now().Add(5*time.Second)))
+	var event models.SessionEvent
+	require.NoError(t, websocket.JSON.Receive(ws, &event))
+	assert.Equal(t, models.SessionEventMessages, event.Type)
+	assert.Equal(t, sessionID, event.SessionID)
+	require.Len(t, event.Messages, 1)
+	assert.Equal(t, "Hello", event.Messages[0].Content)
+	assert.NotEmpty(t, event.Messages[0].UUID)
+
+	// unknown sessions are rejected before the handshake
+	_, err = websocket.Dial(wsURL+"unknown/stream", "", testServer.URL)
+	var dialErr *websocket.DialError
+	require.ErrorAs(t, err, &dialErr)
+	assert.Equal(t, websocket.ErrBadStatus, dialErr.Err)
+}
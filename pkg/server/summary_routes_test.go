@@ -0,0 +1,116 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/testutils"
+)
+
+func TestGetSummaryListRoute(t *testing.T) {
+	sessionID := testutils.GenerateRandomString(10)
+	messages := []models.Message{
+		{Role: "user", Content: "Hello"},
+		{Role: "assistant", Content: "Hi there"},
+	}
+	err := appState.MemoryStore.PutMemory(
+		testCtx,
+		appState,
+		sessionID,
+		&models.Memory{Messages: messages},
+		true,
+	)
+	require.NoError(t, err)
+	for i, content := range []string{"A greeting", "A friendly greeting"} {
+		err = appState.MemoryStore.PutSummary(
+			testCtx,
+			appState,
+			sessionID,
+			&models.Summary{Content: content, SummaryPointUUID: messages[i].UUID},
+		)
+		require.NoError(t, err)
+	}
+
+	summariesURL := testServer.URL + "/api/v1/sessions/" + sessionID + "/summaries"
+
+	t.Run("all summaries", func(t *testing.T) {
+		resp, err := http.Get(summariesURL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var summaries models.SummaryListResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&summaries))
+		assert.Equal(t, 2, summaries.TotalCount)
+		require.Len(t, summaries.Summaries, 2)
+		assert.Equal(t, "A greeting", summaries.Summaries[0].Content)
+		assert.Equal(t, "A friendly greeting", summaries.Summaries[1].Content)
+	})
+
+	t.Run("paginated", func(t *testing.T) {
+		resp, err := http.Get(summariesURL + "?page=2&limit=1")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var summaries models.SummaryListResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&summaries))
+		assert.Equal(t, 2, summaries.TotalCount)
+		assert.Equal(t, 1, summaries.RowCount)
+		require.Len(t, summaries.Summaries, 1)
+		assert.Equal(t, "A friendly greeting", summaries.Summaries[0].Content)
+	})
+
+	t.Run("invalid page", func(t *testing.T) {
+		resp, err := http.Get(summariesURL + "?page=-1")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("session not found", func(t *testing.T) {
+		resp, err := http.Get(testServer.URL + "/api/v1/sessions/notfound/summaries")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestRegenerateSummaryRoute(t *testing.T) {
+	t.Run("too few messages", func(t *testing.T) {
+		sessionID := testutils.GenerateRandomString(10)
+		err := appState.MemoryStore.PutMemory(
+			testCtx,
+			appState,
+			sessionID,
+			&models.Memory{Messages: []models.Message{{Role: "user", Content: "Hello"}}},
+			true,
+		)
+		require.NoError(t, err)
+
+		resp, err := http.Post(
+			testServer.URL+"/api/v1/sessions/"+sessionID+"/summary/regenerate",
+			"application/json",
+			nil,
+		)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("session not found", func(t *testing.T) {
+		resp, err := http.Post(
+			testServer.URL+"/api/v1/sessions/notfound/summary/regenerate",
+			"application/json",
+			nil,
+		)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
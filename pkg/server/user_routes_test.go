@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/google/uuid"
+
 	"github.com/getzep/zep/pkg/store/postgres"
 	"github.com/getzep/zep/pkg/testutils"
 
@@ -271,3 +273,118 @@ func TestListUserSessionsRoute(t *testing.T) {
 		assert.Equal(t, createdUser.UserID, *session.UserID)
 	}
 }
+
+func TestUsersRoutes(t *testing.T) {
+	userID := testutils.GenerateRandomString(10)
+
+	// Create a user using the plural route
+	userJSON, err := json.Marshal(&models.CreateUserRequest{UserID: userID})
+	assert.NoError(t, err)
+	req, err := http.NewRequest("POST", testServer.URL+"/api/v1/users", bytes.NewBuffer(userJSON))
+	assert.NoError(t, err)
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	// The user is returned by both routes
+	for _, prefix := range []string{"/api/v1/users/", "/api/v1/user/"} {
+		req, err := http.NewRequest("GET", testServer.URL+prefix+userID, nil)
+		assert.NoError(t, err)
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		user := new(models.User)
+		err = json.NewDecoder(resp.Body).Decode(user)
+		assert.NoError(t, err)
+		assert.Equal(t, userID, user.UserID)
+	}
+}
+
+func TestGetUserFactsRoute(t *testing.T) {
+	userID := testutils.GenerateRandomString(10)
+	_, err := postgres.NewUserStoreDAO(testDB).Create(testCtx, &models.CreateUserRequest{
+		UserID: userID,
+	})
+	assert.NoError(t, err)
+
+	err = appState.MemoryStore.PutFacts(testCtx, userID, []models.Fact{
+		{Subject: "user", Predicate: "lives in", Object: "Berlin"},
+		{Subject: "user", Predicate: "has children", Object: "two"},
+	})
+	assert.NoError(t, err)
+
+	resp, err := http.Get(testServer.URL + "/api/v1/users/" + userID + "/facts?limit=1")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var facts models.FactListResponse
+	err = json.NewDecoder(resp.Body).Decode(&facts)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, facts.RowCount)
+	assert.Len(t, facts.Facts, 1)
+
+	resp, err = http.Get(testServer.URL + "/api/v1/users/nonexistent/facts")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestUserEntitiesRoutes(t *testing.T) {
+	userID := testutils.GenerateRandomString(10)
+	_, err := postgres.NewUserStoreDAO(testDB).Create(testCtx, &models.CreateUserRequest{
+		UserID: userID,
+	})
+	assert.NoError(t, err)
+
+	sessionID := testutils.GenerateRandomString(10)
+	_, err = postgres.NewSessionDAO(testDB).Create(testCtx, &models.CreateSessionRequest{
+		SessionID: sessionID,
+		UserID:    &userID,
+	})
+	assert.NoError(t, err)
+
+	err = appState.MemoryStore.PutMemory(testCtx, appState, sessionID, &models.Memory{
+		Messages: []models.Message{{Role: "user", Content: "Ada lives in Berlin"}},
+	}, true)
+	assert.NoError(t, err)
+	messages, err := appState.MemoryStore.GetMessageList(testCtx, appState, sessionID, 1, 10)
+	assert.NoError(t, err)
+
+	err = appState.MemoryStore.PutEntityMentions(testCtx, sessionID, map[uuid.UUID][]models.Entity{
+		messages.Messages[0].UUID: {{Name: "Ada", Label: "PERSON"}, {Name: "Berlin", Label: "GPE"}},
+	})
+	assert.NoError(t, err)
+
+	resp, err := http.Get(testServer.URL + "/api/v1/users/" + userID + "/entities?label=PERSON")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var entities models.UserEntityListResponse
+	err = json.NewDecoder(resp.Body).Decode(&entities)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, entities.RowCount)
+
+	resp, err = http.Get(
+		testServer.URL + "/api/v1/users/" + userID + "/entities/mentions?name=berlin",
+	)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var mentions models.EntityMentionListResponse
+	err = json.NewDecoder(resp.Body).Decode(&mentions)
+	assert.NoError(t, err)
+	if assert.Len(t, mentions.Mentions, 1) {
+		assert.Equal(t, sessionID, mentions.Mentions[0].SessionID)
+		assert.Equal(t, "Ada lives in Berlin", mentions.Mentions[0].Message.Content)
+	}
+
+	resp, err = http.Get(testServer.URL + "/api/v1/users/" + userID + "/entities/mentions")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -31,6 +31,12 @@ func redactHTMLEncodeConfig(cfg *config.Config) (*config.Config, error) {
 		"$1:**redacted**",
 	)
 
+	reRedis := regexp.MustCompile(`(?i:rediss?://[^:@]*:)([^@]+)`)
+	redactedConfig.Server.RateLimit.RedisURL = reRedis.ReplaceAllString(
+		redactedConfig.Server.RateLimit.RedisURL,
+		"$1**redacted**",
+	)
+
 	escapedConfig := web.HTMLEscapeStruct(redactedConfig)
 
 	if redactedConfig, ok := escapedConfig.(config.Config); ok {
@@ -14,6 +14,10 @@ func (e *StorageError) Error() string {
 	return fmt.Sprintf("storage error: %s (original error: %v)", e.Message, e.OriginalError)
 }
 
+func (e *StorageError) Unwrap() error {
+	return e.OriginalError
+}
+
 func NewStorageError(message string, originalError error) *StorageError {
 	return &StorageError{Message: message, OriginalError: originalError}
 }
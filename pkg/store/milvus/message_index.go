@@ -0,0 +1,458 @@
+package milvus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/llms"
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/store"
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultCollection = "zep_message_embeddings"
+	IndexTypeHNSW     = "HNSW"
+	IndexTypeIVFFlat  = "IVF_FLAT"
+
+	defaultHNSWM              = 16
+	defaultHNSWEFConstruction = 64
+	defaultHNSWEF             = 64
+	defaultIVFNList           = 1024
+	defaultIVFNProbe          = 16
+
+	requestTimeout   = 30 * time.Second
+	maxRetryAttempts = 3
+	// insertBatchSize keeps bulk imports within Milvus' request size limits
+	insertBatchSize = 1000
+	queryPageSize   = 1000
+	maxSessionIDLen = 255
+	vectorField     = "vector"
+	indexName       = "vector_index"
+)
+
+var _ models.MessageVectorIndex = (*MessageIndex)(nil)
+
+// MessageIndex is a MessageVectorIndex backed by a Milvus collection, using Milvus' RESTful
+// v2 API. Entities are keyed by message UUID and carry the session ID as a scalar field.
+//
+// Milvus builds indexes asynchronously. Entities inserted by large imports are searchable
+// immediately, but are searched by brute force until Milvus has indexed their segments.
+// IndexProgress reports how much of the collection has been indexed, and is reported by the
+// reindex command.
+type MessageIndex struct {
+	baseURL      string
+	token        string
+	collection   string
+	index        config.MilvusIndexConfig
+	searchParams map[string]interface{}
+	client       *http.Client
+}
+
+// NewMessageIndex returns a MessageIndex for the configured collection. If the collection
+// doesn't exist it is created and loaded, and a build of its vector index is started. The
+// index build is not waited for.
+func NewMessageIndex(
+	ctx context.Context,
+	cfg *config.MilvusConfig,
+	dimensions int,
+) (*MessageIndex, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("vector_store.milvus.url must be set")
+	}
+	if _, err := url.ParseRequestURI(cfg.URL); err != nil {
+		return nil, fmt.Errorf("invalid vector_store.milvus.url: %w", err)
+	}
+
+	collection := cfg.Collection
+	if collection == "" {
+		collection = DefaultCollection
+	}
+
+	index, searchParams, err := indexParams(cfg.Index)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := &MessageIndex{
+		baseURL:      strings.TrimSuffix(cfg.URL, "/"),
+		token:        cfg.Token,
+		collection:   collection,
+		index:        index,
+		searchParams: searchParams,
+		client:       llms.NewRetryableHTTPClient(maxRetryAttempts, requestTimeout),
+	}
+
+	if err := idx.ensureCollection(ctx, dimensions); err != nil {
+		return nil, err
+	}
+
+	return idx, nil
+}
+
+// indexParams applies defaults to the index config, returning the config and the search
+// params for the index type.
+func indexParams(
+	cfg config.MilvusIndexConfig,
+) (config.MilvusIndexConfig, map[string]interface{}, error) {
+	switch cfg.Type {
+	case "", IndexTypeHNSW:
+		cfg.Type = IndexTypeHNSW
+		if cfg.M == 0 {
+			cfg.M = defaultHNSWM
+		}
+		if cfg.EFConstruction == 0 {
+			cfg.EFConstruction = defaultHNSWEFConstruction
+		}
+		if cfg.EF == 0 {
+			cfg.EF = defaultHNSWEF
+		}
+		return cfg, map[string]interface{}{"ef": cfg.EF}, nil
+	case IndexTypeIVFFlat:
+		if cfg.NList == 0 {
+			cfg.NList = defaultIVFNList
+		}
+		if cfg.NProbe == 0 {
+			cfg.NProbe = defaultIVFNProbe
+		}
+		return cfg, map[string]interface{}{"nprobe": cfg.NProbe}, nil
+	default:
+		return cfg, nil, fmt.Errorf("unsupported vector_store.milvus.index.type: %s", cfg.Type)
+	}
+}
+
+func (idx *MessageIndex) PutMessageEmbeddings(
+	ctx context.Context,
+	sessionID string,
+	embeddings []models.TextData,
+) error {
+	if len(embeddings) == 0 {
+		return store.NewStorageError("no embeddings received", nil)
+	}
+	if len(sessionID) > maxSessionIDLen {
+		return store.NewStorageError("session id is too long for milvus", nil)
+	}
+
+	for start := 0; start < len(embeddings); start += insertBatchSize {
+		end := start + insertBatchSize
+		if end > len(embeddings) {
+			end = len(embeddings)
+		}
+
+		data := make([]map[string]interface{}, end-start)
+		for i, e := range embeddings[start:end] {
+			data[i] = map[string]interface{}{
+				"id":         e.TextUUID.String(),
+				"session_id": sessionID,
+				vectorField:  e.Embedding,
+			}
+		}
+
+		err := idx.do(ctx, "/v2/vectordb/entities/upsert", map[string]interface{}{
+			"collectionName": idx.collection,
+			"data":           data,
+		}, nil)
+		if err != nil {
+			return store.NewStorageError("failed to upsert milvus entities", err)
+		}
+	}
+
+	return nil
+}
+
+type entity struct {
+	ID       string    `json:"id"`
+	Distance float64   `json:"distance"`
+	Vector   []float32 `json:"vector"`
+}
+
+func (e *entity) uuid() (uuid.UUID, error) {
+	id, err := uuid.Parse(e.ID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid milvus entity id %s: %w", e.ID, err)
+	}
+	return id, nil
+}
+
+func (idx *MessageIndex) GetMessageEmbeddings(
+	ctx context.Context,
+	sessionID string,
+) ([]models.TextData, error) {
+	var embeddings []models.TextData
+	for offset := 0; ; offset += queryPageSize {
+		var entities []entity
+		err := idx.do(ctx, "/v2/vectordb/entities/query", map[string]interface{}{
+			"collectionName": idx.collection,
+			"filter":         sessionFilter(sessionID),
+			"outputFields":   []string{"id", vectorField},
+			"limit":          queryPageSize,
+			"offset":         offset,
+		}, &entities)
+		if err != nil {
+			return nil, store.NewStorageError("failed to query milvus entities", err)
+		}
+
+		for i := range entities {
+			id, err := entities[i].uuid()
+			if err != nil {
+				return nil, store.NewStorageError("failed to query milvus entities", err)
+			}
+			embeddings = append(embeddings, models.TextData{
+				TextUUID:  id,
+				Embedding: entities[i].Vector,
+			})
+		}
+
+		if len(entities) < queryPageSize {
+			break
+		}
+	}
+
+	return embeddings, nil
+}
+
+func (idx *MessageIndex) SearchMessageEmbeddings(
+	ctx context.Context,
+	sessionID string,
+	embedding []float32,
+	limit int,
+) ([]models.VectorIndexResult, error) {
+	var entities []entity
+	err := idx.do(ctx, "/v2/vectordb/entities/search", map[string]interface{}{
+		"collectionName": idx.collection,
+		"data":           [][]float32{embedding},
+		"annsField":      vectorField,
+		"filter":         sessionFilter(sessionID),
+		"limit":          limit,
+		"outputFields":   []string{"id", vectorField},
+		"searchParams": map[string]interface{}{
+			"metricType": "IP",
+			"params":     idx.searchParams,
+		},
+	}, &entities)
+	if err != nil {
+		return nil, store.NewStorageError("failed to search milvus entities", err)
+	}
+
+	results := make([]models.VectorIndexResult, len(entities))
+	for i := range entities {
+		id, err := entities[i].uuid()
+		if err != nil {
+			return nil, store.NewStorageError("failed to search milvus entities", err)
+		}
+		// with the IP metric, distance is the inner product
+		results[i] = models.VectorIndexResult{
+			UUID:      id,
+			Score:     entities[i].Distance,
+			Embedding: entities[i].Vector,
+		}
+	}
+
+	return results, nil
+}
+
+func (idx *MessageIndex) DeleteSessionEmbeddings(ctx context.Context, sessionID string) error {
+	err := idx.do(ctx, "/v2/vectordb/entities/delete", map[string]interface{}{
+		"collectionName": idx.collection,
+		"filter":         sessionFilter(sessionID),
+	}, nil)
+	if err != nil {
+		return store.NewStorageError("failed to delete milvus entities", err)
+	}
+
+	return nil
+}
+
+func (idx *MessageIndex) DeleteMessageEmbedding(
+	ctx context.Context,
+	sessionID string,
+	messageUUID uuid.UUID,
+) error {
+	err := idx.do(ctx, "/v2/vectordb/entities/delete", map[string]interface{}{
+		"collectionName": idx.collection,
+		"filter":         sessionFilter(sessionID) + ` && id == "` + messageUUID.String() + `"`,
+	}, nil)
+	if err != nil {
+		return store.NewStorageError("failed to delete milvus entity", err)
+	}
+
+	return nil
+}
+
+// IndexProgress returns the number of rows in the collection that have been indexed, and
+// the total number of rows.
+func (idx *MessageIndex) IndexProgress(ctx context.Context) (int64, int64, error) {
+	var indexes []struct {
+		IndexName   string `json:"indexName"`
+		IndexedRows int64  `json:"indexedRows"`
+		TotalRows   int64  `json:"totalRows"`
+	}
+	err := idx.do(ctx, "/v2/vectordb/indexes/describe", map[string]interface{}{
+		"collectionName": idx.collection,
+		"indexName":      indexName,
+	}, &indexes)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to describe milvus index: %w", err)
+	}
+	if len(indexes) == 0 {
+		return 0, 0, models.NewNotFoundError("milvus index " + indexName)
+	}
+
+	return indexes[0].IndexedRows, indexes[0].TotalRows, nil
+}
+
+// ensureCollection creates, indexes and loads the collection if it doesn't exist. Milvus
+// builds the index in the background. The IP metric matches pgvector's inner product search.
+func (idx *MessageIndex) ensureCollection(ctx context.Context, dimensions int) error {
+	var has struct {
+		Has bool `json:"has"`
+	}
+	err := idx.do(ctx, "/v2/vectordb/collections/has", map[string]interface{}{
+		"collectionName": idx.collection,
+	}, &has)
+	if err != nil {
+		return fmt.Errorf("failed to check milvus collection: %w", err)
+	}
+	if has.Has {
+		return nil
+	}
+
+	err = idx.do(ctx, "/v2/vectordb/collections/create", map[string]interface{}{
+		"collectionName": idx.collection,
+		"schema": map[string]interface{}{
+			"autoId": false,
+			"fields": []map[string]interface{}{
+				{
+					"fieldName":         "id",
+					"dataType":          "VarChar",
+					"isPrimary":         true,
+					"elementTypeParams": map[string]interface{}{"max_length": 36},
+				},
+				{
+					"fieldName": "session_id",
+					"dataType":  "VarChar",
+					"elementTypeParams": map[string]interface{}{
+						"max_length": maxSessionIDLen,
+					},
+				},
+				{
+					"fieldName":         vectorField,
+					"dataType":          "FloatVector",
+					"elementTypeParams": map[string]interface{}{"dim": dimensions},
+				},
+			},
+		},
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create milvus collection: %w", err)
+	}
+
+	params := map[string]interface{}{"index_type": idx.index.Type}
+	switch idx.index.Type {
+	case IndexTypeHNSW:
+		params["M"] = idx.index.M
+		params["efConstruction"] = idx.index.EFConstruction
+	case IndexTypeIVFFlat:
+		params["nlist"] = idx.index.NList
+	}
+	err = idx.do(ctx, "/v2/vectordb/indexes/create", map[string]interface{}{
+		"collectionName": idx.collection,
+		"indexParams": []map[string]interface{}{
+			{
+				"fieldName":  vectorField,
+				"indexName":  indexName,
+				"metricType": "IP",
+				"params":     params,
+			},
+		},
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create milvus index: %w", err)
+	}
+
+	err = idx.do(ctx, "/v2/vectordb/collections/load", map[string]interface{}{
+		"collectionName": idx.collection,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to load milvus collection: %w", err)
+	}
+
+	return nil
+}
+
+// sessionFilter returns a Milvus boolean expression matching a session's entities.
+func sessionFilter(sessionID string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(sessionID)
+	return `session_id == "` + escaped + `"`
+}
+
+// do POSTs a request to the Milvus endpoint at path, decoding the response's data into
+// result if it is not nil. Milvus reports errors with a non-zero code in the response body.
+func (idx *MessageIndex) do(
+	ctx context.Context,
+	path string,
+	body interface{},
+	result interface{},
+) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		idx.baseURL+path,
+		bytes.NewReader(b),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if idx.token != "" {
+		req.Header.Set("Authorization", "Bearer "+idx.token)
+	}
+
+	resp, err := idx.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("milvus returned status %d: %s", resp.StatusCode, respBody)
+	}
+
+	var envelope struct {
+		Code    int             `json:"code"`
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(respBody, &envelope); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if envelope.Code != 0 {
+		return fmt.Errorf("milvus returned code %d: %s", envelope.Code, envelope.Message)
+	}
+
+	if result != nil && len(envelope.Data) > 0 {
+		if err := json.Unmarshal(envelope.Data, result); err != nil {
+			return fmt.Errorf("failed to unmarshal response data: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package milvus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeMilvus struct {
+	hasCollection bool
+	requests      []string
+	bodies        map[string][]map[string]interface{}
+	searchData    interface{}
+}
+
+func newFakeMilvus(t *testing.T, f *fakeMilvus) *httptest.Server {
+	f.bodies = make(map[string][]map[string]interface{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.requests = append(f.requests, r.URL.Path)
+
+		var body map[string]interface{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		f.bodies[r.URL.Path] = append(f.bodies[r.URL.Path], body)
+
+		var data interface{}
+		switch r.URL.Path {
+		case "/v2/vectordb/collections/has":
+			data = map[string]interface{}{"has": f.hasCollection}
+		case "/v2/vectordb/entities/search":
+			data = f.searchData
+		case "/v2/vectordb/indexes/describe":
+			data = []map[string]interface{}{
+				{"indexName": indexName, "indexedRows": 10, "totalRows": 25},
+			}
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(map[string]interface{}{
+			"code": 0,
+			"data": data,
+		}))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNewMessageIndex(t *testing.T) {
+	t.Run("creates, indexes and loads missing collection", func(t *testing.T) {
+		f := &fakeMilvus{}
+		server := newFakeMilvus(t, f)
+
+		_, err := NewMessageIndex(context.Background(), &config.MilvusConfig{
+			URL:   server.URL,
+			Index: config.MilvusIndexConfig{Type: IndexTypeIVFFlat, NList: 128},
+		}, 3)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{
+			"/v2/vectordb/collections/has",
+			"/v2/vectordb/collections/create",
+			"/v2/vectordb/indexes/create",
+			"/v2/vectordb/collections/load",
+		}, f.requests)
+		indexParams := f.bodies["/v2/vectordb/indexes/create"][0]["indexParams"].([]interface{})
+		params := indexParams[0].(map[string]interface{})["params"].(map[string]interface{})
+		assert.Equal(t, IndexTypeIVFFlat, params["index_type"])
+		assert.Equal(t, float64(128), params["nlist"])
+	})
+
+	t.Run("uses existing collection", func(t *testing.T) {
+		f := &fakeMilvus{hasCollection: true}
+		server := newFakeMilvus(t, f)
+
+		_, err := NewMessageIndex(
+			context.Background(),
+			&config.MilvusConfig{URL: server.URL},
+			3,
+		)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"/v2/vectordb/collections/has"}, f.requests)
+	})
+
+	t.Run("rejects unknown index type", func(t *testing.T) {
+		_, err := NewMessageIndex(context.Background(), &config.MilvusConfig{
+			URL:   "http://localhost:19530",
+			Index: config.MilvusIndexConfig{Type: "DISKANN"},
+		}, 3)
+		assert.Error(t, err)
+	})
+}
+
+func TestIndexParams(t *testing.T) {
+	cfg, searchParams, err := indexParams(config.MilvusIndexConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, config.MilvusIndexConfig{
+		Type:           IndexTypeHNSW,
+		M:              defaultHNSWM,
+		EFConstruction: defaultHNSWEFConstruction,
+		EF:             defaultHNSWEF,
+	}, cfg)
+	assert.Equal(t, map[string]interface{}{"ef": defaultHNSWEF}, searchParams)
+
+	_, searchParams, err = indexParams(config.MilvusIndexConfig{Type: IndexTypeIVFFlat, NProbe: 8})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"nprobe": 8}, searchParams)
+}
+
+func TestMessageIndexPutAndSearch(t *testing.T) {
+	messageUUID := uuid.New()
+	f := &fakeMilvus{
+		hasCollection: true,
+		searchData: []map[string]interface{}{
+			{"id": messageUUID.String(), "distance": 0.5, "vector": []float32{1, 0, 0}},
+		},
+	}
+	server := newFakeMilvus(t, f)
+
+	idx, err := NewMessageIndex(context.Background(), &config.MilvusConfig{URL: server.URL}, 3)
+	require.NoError(t, err)
+
+	t.Run("upserts in batches", func(t *testing.T) {
+		embeddings := make([]models.TextData, insertBatchSize+1)
+		for i := range embeddings {
+			embeddings[i] = models.TextData{TextUUID: uuid.New(), Embedding: []float32{1, 0, 0}}
+		}
+		err := idx.PutMessageEmbeddings(context.Background(), "session", embeddings)
+		require.NoError(t, err)
+
+		batches := f.bodies["/v2/vectordb/entities/upsert"]
+		require.Len(t, batches, 2)
+		assert.Len(t, batches[0]["data"], insertBatchSize)
+		assert.Len(t, batches[1]["data"], 1)
+	})
+
+	t.Run("searches within session", func(t *testing.T) {
+		results, err := idx.SearchMessageEmbeddings(
+			context.Background(),
+			"session",
+			[]float32{1, 0, 0},
+			5,
+		)
+		require.NoError(t, err)
+		assert.Equal(t, []models.VectorIndexResult{
+			{UUID: messageUUID, Score: 0.5, Embedding: []float32{1, 0, 0}},
+		}, results)
+		body := f.bodies["/v2/vectordb/entities/search"][0]
+		assert.Equal(t, `session_id == "session"`, body["filter"])
+	})
+
+	t.Run("reports index progress", func(t *testing.T) {
+		indexed, total, err := idx.IndexProgress(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int64(10), indexed)
+		assert.Equal(t, int64(25), total)
+	})
+}
+
+func TestSessionFilter(t *testing.T) {
+	assert.Equal(t, `session_id == "a\"b\\c"`, sessionFilter(`a"b\c`))
+}
+
+func TestMessageIndexDeleteMessageEmbedding(t *testing.T) {
+	f := &fakeMilvus{hasCollection: true}
+	server := newFakeMilvus(t, f)
+
+	idx, err := NewMessageIndex(context.Background(), &config.MilvusConfig{URL: server.URL}, 3)
+	require.NoError(t, err)
+
+	messageUUID := uuid.New()
+	err = idx.DeleteMessageEmbedding(context.Background(), "session", messageUUID)
+	require.NoError(t, err)
+
+	deletes := f.bodies["/v2/vectordb/entities/delete"]
+	require.Len(t, deletes, 1)
+	assert.Equal(
+		t,
+		`session_id == "session" && id == "`+messageUUID.String()+`"`,
+		deletes[0]["filter"],
+	)
+}
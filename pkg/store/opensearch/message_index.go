@@ -0,0 +1,514 @@
+package opensearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/llms"
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/store"
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultIndex         = "zep_messages"
+	DefaultBM25Weight    = 0.3
+	hybridPipelineSuffix = "_hybrid"
+	requestTimeout       = 30 * time.Second
+	maxRetryAttempts     = 3
+	embeddingsPageSize   = 500
+	sessionIDField       = "session_id"
+	messageUUIDField     = "message_uuid"
+	contentField         = "content"
+	embeddingField       = "embedding"
+)
+
+var _ models.HybridMessageVectorIndex = (*MessageIndex)(nil)
+
+// MessageIndex is a MessageVectorIndex backed by an OpenSearch index. Documents are keyed by
+// message UUID and hold the session ID, the message content and its embedding, so that
+// searches may combine BM25 relevance over the content with kNN similarity over the
+// embeddings.
+type MessageIndex struct {
+	baseURL    string
+	username   string
+	password   string
+	index      string
+	pipeline   string
+	bm25Weight float64
+	client     *http.Client
+}
+
+// NewMessageIndex returns a MessageIndex for the configured index, creating the index and
+// the search pipeline used to normalize and combine hybrid query scores if they don't
+// exist. Hybrid queries require OpenSearch 2.10+.
+func NewMessageIndex(
+	ctx context.Context,
+	cfg *config.OpenSearchConfig,
+	dimensions int,
+) (*MessageIndex, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("vector_store.opensearch.url must be set")
+	}
+	if _, err := url.ParseRequestURI(cfg.URL); err != nil {
+		return nil, fmt.Errorf("invalid vector_store.opensearch.url: %w", err)
+	}
+
+	index := cfg.Index
+	if index == "" {
+		index = DefaultIndex
+	}
+
+	bm25Weight := cfg.BM25Weight
+	if bm25Weight == 0 {
+		bm25Weight = DefaultBM25Weight
+	}
+	if bm25Weight < 0 || bm25Weight > 1 {
+		return nil, errors.New("vector_store.opensearch.bm25_weight must be between 0 and 1")
+	}
+
+	idx := &MessageIndex{
+		baseURL:    strings.TrimSuffix(cfg.URL, "/"),
+		username:   cfg.Username,
+		password:   cfg.Password,
+		index:      index,
+		pipeline:   index + hybridPipelineSuffix,
+		bm25Weight: bm25Weight,
+		client:     llms.NewRetryableHTTPClient(maxRetryAttempts, requestTimeout),
+	}
+
+	if err := idx.ensureIndex(ctx, dimensions); err != nil {
+		return nil, err
+	}
+	if err := idx.ensurePipeline(ctx); err != nil {
+		return nil, err
+	}
+
+	return idx, nil
+}
+
+type document struct {
+	SessionID   string    `json:"session_id"`
+	MessageUUID uuid.UUID `json:"message_uuid"`
+	Content     string    `json:"content"`
+	Embedding   []float32 `json:"embedding"`
+}
+
+// PutMessageEmbeddings indexes the embeddings and the content of the messages they embed
+// using a single bulk request.
+func (idx *MessageIndex) PutMessageEmbeddings(
+	ctx context.Context,
+	sessionID string,
+	embeddings []models.TextData,
+) error {
+	if len(embeddings) == 0 {
+		return store.NewStorageError("no embeddings received", nil)
+	}
+
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, e := range embeddings {
+		action := map[string]interface{}{
+			"index": map[string]interface{}{"_index": idx.index, "_id": e.TextUUID},
+		}
+		if err := encoder.Encode(action); err != nil {
+			return store.NewStorageError("failed to encode bulk request", err)
+		}
+		err := encoder.Encode(document{
+			SessionID:   sessionID,
+			MessageUUID: e.TextUUID,
+			Content:     e.Text,
+			Embedding:   e.Embedding,
+		})
+		if err != nil {
+			return store.NewStorageError("failed to encode bulk request", err)
+		}
+	}
+
+	var resp struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			ID    string `json:"_id"`
+			Error *struct {
+				Reason string `json:"reason"`
+			} `json:"error"`
+		} `json:"items"`
+	}
+	err := idx.do(
+		ctx,
+		http.MethodPost,
+		"/_bulk?refresh=wait_for",
+		"application/x-ndjson",
+		&body,
+		&resp,
+	)
+	if err != nil {
+		return store.NewStorageError("failed to bulk index messages", err)
+	}
+	if resp.Errors {
+		for _, item := range resp.Items {
+			for _, result := range item {
+				if result.Error != nil {
+					return store.NewStorageError(
+						fmt.Sprintf(
+							"failed to index message %s: %s",
+							result.ID,
+							result.Error.Reason,
+						),
+						nil,
+					)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+type searchResponse struct {
+	Hits struct {
+		Hits []struct {
+			Score  float64       `json:"_score"`
+			Source document      `json:"_source"`
+			Sort   []interface{} `json:"sort"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+func (idx *MessageIndex) GetMessageEmbeddings(
+	ctx context.Context,
+	sessionID string,
+) ([]models.TextData, error) {
+	var embeddings []models.TextData
+	var searchAfter []interface{}
+	for {
+		query := map[string]interface{}{
+			"size":    embeddingsPageSize,
+			"query":   sessionFilter(sessionID),
+			"sort":    []interface{}{map[string]interface{}{messageUUIDField: "asc"}},
+			"_source": []string{messageUUIDField, embeddingField},
+		}
+		if searchAfter != nil {
+			query["search_after"] = searchAfter
+		}
+
+		var resp searchResponse
+		if err := idx.search(ctx, query, false, &resp); err != nil {
+			return nil, store.NewStorageError("failed to get message embeddings", err)
+		}
+
+		for _, hit := range resp.Hits.Hits {
+			embeddings = append(embeddings, models.TextData{
+				TextUUID:  hit.Source.MessageUUID,
+				Embedding: hit.Source.Embedding,
+			})
+		}
+
+		if len(resp.Hits.Hits) < embeddingsPageSize {
+			break
+		}
+		searchAfter = resp.Hits.Hits[len(resp.Hits.Hits)-1].Sort
+	}
+
+	return embeddings, nil
+}
+
+// SearchMessageEmbeddings performs a kNN search over the session's embeddings.
+func (idx *MessageIndex) SearchMessageEmbeddings(
+	ctx context.Context,
+	sessionID string,
+	embedding []float32,
+	limit int,
+) ([]models.VectorIndexResult, error) {
+	query := map[string]interface{}{
+		"size":    limit,
+		"query":   knnQuery(sessionID, embedding, limit),
+		"_source": []string{messageUUIDField, embeddingField},
+	}
+
+	var resp searchResponse
+	if err := idx.search(ctx, query, false, &resp); err != nil {
+		return nil, store.NewStorageError("failed to search messages", err)
+	}
+
+	return searchResults(&resp), nil
+}
+
+// SearchMessagesHybrid combines a BM25 match over the session's message content with a kNN
+// search over the session's embeddings. Scores are min-max normalized and combined using
+// the configured BM25 weight.
+func (idx *MessageIndex) SearchMessagesHybrid(
+	ctx context.Context,
+	sessionID string,
+	text string,
+	embedding []float32,
+	limit int,
+) ([]models.VectorIndexResult, error) {
+	query := map[string]interface{}{
+		"size": limit,
+		"query": map[string]interface{}{
+			"hybrid": map[string]interface{}{
+				"queries": []interface{}{
+					map[string]interface{}{
+						"bool": map[string]interface{}{
+							"filter": []interface{}{sessionTerm(sessionID)},
+							"must": []interface{}{
+								map[string]interface{}{
+									"match": map[string]interface{}{contentField: text},
+								},
+							},
+						},
+					},
+					knnQuery(sessionID, embedding, limit),
+				},
+			},
+		},
+		"_source": []string{messageUUIDField, embeddingField},
+	}
+
+	var resp searchResponse
+	if err := idx.search(ctx, query, true, &resp); err != nil {
+		return nil, store.NewStorageError("failed to search messages", err)
+	}
+
+	return searchResults(&resp), nil
+}
+
+func (idx *MessageIndex) DeleteSessionEmbeddings(ctx context.Context, sessionID string) error {
+	body, err := json.Marshal(map[string]interface{}{"query": sessionFilter(sessionID)})
+	if err != nil {
+		return store.NewStorageError("failed to marshal request", err)
+	}
+
+	err = idx.do(
+		ctx,
+		http.MethodPost,
+		"/"+url.PathEscape(idx.index)+"/_delete_by_query?refresh=true",
+		"application/json",
+		bytes.NewReader(body),
+		nil,
+	)
+	if err != nil {
+		return store.NewStorageError("failed to delete messages", err)
+	}
+
+	return nil
+}
+
+// DeleteMessageEmbedding deletes a message's document. Documents are keyed by message UUID.
+func (idx *MessageIndex) DeleteMessageEmbedding(
+	ctx context.Context,
+	_ string,
+	messageUUID uuid.UUID,
+) error {
+	err := idx.do(
+		ctx,
+		http.MethodDelete,
+		"/"+url.PathEscape(idx.index)+"/_doc/"+messageUUID.String()+"?refresh=true",
+		"",
+		nil,
+		nil,
+	)
+	if err != nil && !errors.Is(err, models.ErrNotFound) {
+		return store.NewStorageError("failed to delete message", err)
+	}
+
+	return nil
+}
+
+func sessionTerm(sessionID string) map[string]interface{} {
+	return map[string]interface{}{
+		"term": map[string]interface{}{sessionIDField: sessionID},
+	}
+}
+
+func sessionFilter(sessionID string) map[string]interface{} {
+	return map[string]interface{}{
+		"bool": map[string]interface{}{
+			"filter": []interface{}{sessionTerm(sessionID)},
+		},
+	}
+}
+
+func knnQuery(sessionID string, embedding []float32, k int) map[string]interface{} {
+	return map[string]interface{}{
+		"knn": map[string]interface{}{
+			embeddingField: map[string]interface{}{
+				"vector": embedding,
+				"k":      k,
+				"filter": sessionTerm(sessionID),
+			},
+		},
+	}
+}
+
+func searchResults(resp *searchResponse) []models.VectorIndexResult {
+	results := make([]models.VectorIndexResult, len(resp.Hits.Hits))
+	for i, hit := range resp.Hits.Hits {
+		results[i] = models.VectorIndexResult{
+			UUID:      hit.Source.MessageUUID,
+			Score:     hit.Score,
+			Embedding: hit.Source.Embedding,
+		}
+	}
+	return results
+}
+
+func (idx *MessageIndex) search(
+	ctx context.Context,
+	query map[string]interface{},
+	hybrid bool,
+	result interface{},
+) error {
+	body, err := json.Marshal(query)
+	if err != nil {
+		return fmt.Errorf("failed to marshal query: %w", err)
+	}
+
+	path := "/" + url.PathEscape(idx.index) + "/_search"
+	if hybrid {
+		path += "?search_pipeline=" + url.QueryEscape(idx.pipeline)
+	}
+
+	return idx.do(ctx, http.MethodPost, path, "application/json", bytes.NewReader(body), result)
+}
+
+// ensureIndex creates the index if it doesn't exist. Inner product similarity matches
+// pgvector's inner product search.
+func (idx *MessageIndex) ensureIndex(ctx context.Context, dimensions int) error {
+	path := "/" + url.PathEscape(idx.index)
+	err := idx.do(ctx, http.MethodHead, path, "", nil, nil)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, models.ErrNotFound) {
+		return fmt.Errorf("failed to get opensearch index: %w", err)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"settings": map[string]interface{}{
+			"index": map[string]interface{}{"knn": true},
+		},
+		"mappings": map[string]interface{}{
+			"properties": map[string]interface{}{
+				sessionIDField:   map[string]interface{}{"type": "keyword"},
+				messageUUIDField: map[string]interface{}{"type": "keyword"},
+				contentField:     map[string]interface{}{"type": "text"},
+				embeddingField: map[string]interface{}{
+					"type":      "knn_vector",
+					"dimension": dimensions,
+					"method": map[string]interface{}{
+						"name":       "hnsw",
+						"space_type": "innerproduct",
+						"engine":     "faiss",
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal index: %w", err)
+	}
+
+	err = idx.do(ctx, http.MethodPut, path, "application/json", bytes.NewReader(body), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create opensearch index: %w", err)
+	}
+
+	return nil
+}
+
+// ensurePipeline creates or updates the search pipeline used by hybrid queries, so that
+// changes to the BM25 weight take effect on startup.
+func (idx *MessageIndex) ensurePipeline(ctx context.Context) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"description": "Zep hybrid message search",
+		"phase_results_processors": []interface{}{
+			map[string]interface{}{
+				"normalization-processor": map[string]interface{}{
+					"normalization": map[string]interface{}{"technique": "min_max"},
+					"combination": map[string]interface{}{
+						"technique": "arithmetic_mean",
+						"parameters": map[string]interface{}{
+							// weights are in the order of the hybrid query's queries
+							"weights": []float64{idx.bm25Weight, 1 - idx.bm25Weight},
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal search pipeline: %w", err)
+	}
+
+	err = idx.do(
+		ctx,
+		http.MethodPut,
+		"/_search/pipeline/"+url.PathEscape(idx.pipeline),
+		"application/json",
+		bytes.NewReader(body),
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create opensearch search pipeline: %w", err)
+	}
+
+	return nil
+}
+
+// do sends a request to the OpenSearch endpoint at path, decoding the response into result
+// if it is not nil. A 404 response returns a models.ErrNotFound.
+func (idx *MessageIndex) do(
+	ctx context.Context,
+	method string,
+	path string,
+	contentType string,
+	body io.Reader,
+	result interface{},
+) error {
+	req, err := http.NewRequestWithContext(ctx, method, idx.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if idx.username != "" {
+		req.SetBasicAuth(idx.username, idx.password)
+	}
+
+	resp, err := idx.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return models.NewNotFoundError("opensearch " + path)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("opensearch returned status %d: %s", resp.StatusCode, respBody)
+	}
+
+	if result != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, result); err != nil {
+			return fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,183 @@
+package opensearch
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeOpenSearch struct {
+	indexExists  bool
+	requests     []string
+	queries      []string
+	bodies       map[string]map[string]interface{}
+	bulkLines    []map[string]interface{}
+	searchResult interface{}
+}
+
+func newFakeOpenSearch(t *testing.T, f *fakeOpenSearch) *httptest.Server {
+	f.bodies = make(map[string]map[string]interface{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Method + " " + r.URL.Path
+		f.requests = append(f.requests, key)
+		f.queries = append(f.queries, r.URL.RawQuery)
+
+		switch key {
+		case "POST /_bulk":
+			scanner := bufio.NewScanner(r.Body)
+			scanner.Buffer(make([]byte, 1<<20), 1<<20)
+			for scanner.Scan() {
+				var line map[string]interface{}
+				assert.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+				f.bulkLines = append(f.bulkLines, line)
+			}
+			_, _ = w.Write([]byte(`{"errors":false,"items":[]}`))
+			return
+		case "HEAD /test":
+			if !f.indexExists {
+				w.WriteHeader(http.StatusNotFound)
+			}
+			return
+		}
+
+		if r.ContentLength > 0 {
+			var body map[string]interface{}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			f.bodies[key] = body
+		}
+
+		if key == "POST /test/_search" {
+			assert.NoError(t, json.NewEncoder(w).Encode(f.searchResult))
+			return
+		}
+		_, _ = w.Write([]byte(`{"acknowledged":true}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestIndex(t *testing.T, f *fakeOpenSearch) *MessageIndex {
+	server := newFakeOpenSearch(t, f)
+	idx, err := NewMessageIndex(context.Background(), &config.OpenSearchConfig{
+		URL:   server.URL,
+		Index: "test",
+	}, 3)
+	require.NoError(t, err)
+	return idx
+}
+
+func TestNewMessageIndex(t *testing.T) {
+	t.Run("creates missing index and pipeline", func(t *testing.T) {
+		f := &fakeOpenSearch{}
+		newTestIndex(t, f)
+
+		assert.Equal(t, []string{
+			"HEAD /test",
+			"PUT /test",
+			"PUT /_search/pipeline/test_hybrid",
+		}, f.requests)
+		mappings := f.bodies["PUT /test"]["mappings"].(map[string]interface{})
+		properties := mappings["properties"].(map[string]interface{})
+		embedding := properties[embeddingField].(map[string]interface{})
+		assert.Equal(t, "knn_vector", embedding["type"])
+		assert.Equal(t, float64(3), embedding["dimension"])
+
+		processors := f.bodies["PUT /_search/pipeline/test_hybrid"]["phase_results_processors"]
+		processor := processors.([]interface{})[0].(map[string]interface{})
+		normalization := processor["normalization-processor"].(map[string]interface{})
+		combination := normalization["combination"].(map[string]interface{})
+		weights := combination["parameters"].(map[string]interface{})["weights"]
+		assert.Equal(t, []interface{}{DefaultBM25Weight, 1 - DefaultBM25Weight}, weights)
+	})
+
+	t.Run("updates pipeline for existing index", func(t *testing.T) {
+		f := &fakeOpenSearch{indexExists: true}
+		newTestIndex(t, f)
+		assert.Equal(t, []string{"HEAD /test", "PUT /_search/pipeline/test_hybrid"}, f.requests)
+	})
+
+	t.Run("rejects invalid bm25 weight", func(t *testing.T) {
+		_, err := NewMessageIndex(context.Background(), &config.OpenSearchConfig{
+			URL:        "http://localhost:9200",
+			BM25Weight: 1.5,
+		}, 3)
+		assert.Error(t, err)
+	})
+}
+
+func TestMessageIndexPutMessageEmbeddings(t *testing.T) {
+	f := &fakeOpenSearch{indexExists: true}
+	idx := newTestIndex(t, f)
+
+	messageUUID := uuid.New()
+	err := idx.PutMessageEmbeddings(context.Background(), "session", []models.TextData{
+		{TextUUID: messageUUID, Text: "content", Embedding: []float32{1, 0, 0}},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, f.bulkLines, 2)
+	action := f.bulkLines[0]["index"].(map[string]interface{})
+	assert.Equal(t, "test", action["_index"])
+	assert.Equal(t, messageUUID.String(), action["_id"])
+	assert.Equal(t, "session", f.bulkLines[1][sessionIDField])
+	assert.Equal(t, "content", f.bulkLines[1][contentField])
+}
+
+func TestMessageIndexSearchMessagesHybrid(t *testing.T) {
+	messageUUID := uuid.New()
+	f := &fakeOpenSearch{
+		indexExists: true,
+		searchResult: map[string]interface{}{
+			"hits": map[string]interface{}{
+				"hits": []map[string]interface{}{
+					{
+						"_score": 0.8,
+						"_source": map[string]interface{}{
+							messageUUIDField: messageUUID,
+							embeddingField:   []float32{1, 0, 0},
+						},
+					},
+				},
+			},
+		},
+	}
+	idx := newTestIndex(t, f)
+
+	results, err := idx.SearchMessagesHybrid(
+		context.Background(),
+		"session",
+		"who was Octavia Butler",
+		[]float32{1, 0, 0},
+		5,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []models.VectorIndexResult{
+		{UUID: messageUUID, Score: 0.8, Embedding: []float32{1, 0, 0}},
+	}, results)
+
+	assert.Equal(t, "search_pipeline=test_hybrid", f.queries[len(f.queries)-1])
+	query := f.bodies["POST /test/_search"]["query"].(map[string]interface{})
+	queries := query["hybrid"].(map[string]interface{})["queries"].([]interface{})
+	require.Len(t, queries, 2)
+	assert.Contains(t, queries[0].(map[string]interface{}), "bool")
+	assert.Contains(t, queries[1].(map[string]interface{}), "knn")
+}
+
+func TestMessageIndexDeleteMessageEmbedding(t *testing.T) {
+	f := &fakeOpenSearch{indexExists: true}
+	idx := newTestIndex(t, f)
+
+	messageUUID := uuid.New()
+	err := idx.DeleteMessageEmbedding(context.Background(), "session", messageUUID)
+	require.NoError(t, err)
+	assert.Contains(t, f.requests, "DELETE /test/_doc/"+messageUUID.String())
+}
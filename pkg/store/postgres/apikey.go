@@ -0,0 +1,204 @@
+package postgres
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/store"
+)
+
+const (
+	// apiKeyBytes is the number of random bytes of an API key
+	apiKeyBytes = 32
+	// apiKeyPrefixLength is the length of the start of a key kept to recognize it
+	apiKeyPrefixLength = len(models.APIKeyPrefix) + 8
+	// apiKeyLastUsedResolution is the precision of an API key's last_used_at, so that keys
+	// aren't written by every request they authenticate
+	apiKeyLastUsedResolution = time.Minute
+)
+
+type APIKeySchema struct {
+	bun.BaseModel `bun:"table:api_key,alias:ak" yaml:"-"`
+
+	UUID       uuid.UUID `bun:",pk,type:uuid,default:gen_random_uuid()"`
+	ID         int64     `bun:",autoincrement"`
+	CreatedAt  time.Time `bun:"type:timestamptz,notnull,default:current_timestamp"`
+	ProjectID  string    `bun:",notnull,default:'default'"`
+	Name       string    `bun:",notnull"`
+	Scope      string    `bun:",notnull"`
+	Prefix     string    `bun:",notnull"`
+	KeyHash    string    `bun:",unique,notnull"`
+	LastUsedAt time.Time `bun:"type:timestamptz,nullzero"`
+	RevokedAt  time.Time `bun:"type:timestamptz,nullzero"`
+}
+
+func (*APIKeySchema) AfterCreateTable(
+	ctx context.Context,
+	query *bun.CreateTableQuery,
+) error {
+	_, err := query.DB().NewCreateIndex().
+		Model((*APIKeySchema)(nil)).
+		Index("api_key_project_id_idx").
+		Column("project_id").
+		IfNotExists().
+		Exec(ctx)
+	return err
+}
+
+var _ models.APIKeyStore = &APIKeyStoreDAO{}
+
+type APIKeyStoreDAO struct {
+	db *bun.DB
+}
+
+func NewAPIKeyStoreDAO(db *bun.DB) *APIKeyStoreDAO {
+	return &APIKeyStoreDAO{
+		db: db,
+	}
+}
+
+// CreateAPIKey creates an API key in the project ctx is scoped to
+func (dao *APIKeyStoreDAO) CreateAPIKey(
+	ctx context.Context,
+	request *models.CreateAPIKeyRequest,
+) (*models.CreateAPIKeyResponse, error) {
+	if !request.Scope.IsValid() {
+		return nil, models.NewBadRequestError(fmt.Sprintf("invalid scope: %s", request.Scope))
+	}
+
+	secret := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, store.NewStorageError("failed to generate api key", err)
+	}
+	key := models.APIKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	projectID, _ := models.ProjectIDFromContext(ctx)
+	apiKeyDB := &APIKeySchema{
+		ProjectID: projectID,
+		Name:      request.Name,
+		Scope:     string(request.Scope),
+		Prefix:    key[:apiKeyPrefixLength],
+		KeyHash:   hashAPIKey(key),
+	}
+	_, err := dao.db.NewInsert().Model(apiKeyDB).Returning("*").Exec(ctx)
+	if err != nil {
+		return nil, store.NewStorageError("failed to create api key", err)
+	}
+
+	return &models.CreateAPIKeyResponse{
+		APIKey: *apiKeySchemaToAPIKey(apiKeyDB),
+		Key:    key,
+	}, nil
+}
+
+// ListAPIKeys returns the API keys of ctx's project, including revoked keys, oldest first
+func (dao *APIKeyStoreDAO) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	var apiKeysDB []APIKeySchema
+	err := dao.db.NewSelect().
+		Model(&apiKeysDB).
+		ApplyQueryBuilder(whereProject(ctx)).
+		Order("id ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, store.NewStorageError("failed to list api keys", err)
+	}
+
+	apiKeys := make([]models.APIKey, len(apiKeysDB))
+	for i := range apiKeysDB {
+		apiKeys[i] = *apiKeySchemaToAPIKey(&apiKeysDB[i])
+	}
+	return apiKeys, nil
+}
+
+// RevokeAPIKey revokes an API key of ctx's project. Revoked keys are kept, so that they're
+// still listed. A not found error is returned if the key is already revoked.
+func (dao *APIKeyStoreDAO) RevokeAPIKey(ctx context.Context, keyUUID uuid.UUID) error {
+	r, err := dao.db.NewUpdate().
+		Model((*APIKeySchema)(nil)).
+		Set("revoked_at = current_timestamp").
+		Where("uuid = ?", keyUUID).
+		Where("revoked_at IS NULL").
+		ApplyQueryBuilder(whereProject(ctx)).
+		Exec(ctx)
+	if err != nil {
+		return store.NewStorageError("failed to revoke api key", err)
+	}
+	rowsAffected, err := r.RowsAffected()
+	if err != nil {
+		return store.NewStorageError("failed to get rows affected", err)
+	}
+	if rowsAffected == 0 {
+		return models.NewNotFoundError("api key " + keyUUID.String())
+	}
+	return nil
+}
+
+// VerifyAPIKey returns the live API key of key, of any project. Its last_used_at is updated
+// if it's older than apiKeyLastUsedResolution.
+func (dao *APIKeyStoreDAO) VerifyAPIKey(ctx context.Context, key string) (*models.APIKey, error) {
+	apiKeyDB := &APIKeySchema{}
+	err := dao.db.NewSelect().
+		Model(apiKeyDB).
+		Where("key_hash = ?", hashAPIKey(key)).
+		Where("revoked_at IS NULL").
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.NewNotFoundError("api key")
+		}
+		return nil, store.NewStorageError("failed to get api key", err)
+	}
+
+	now := time.Now()
+	if now.Sub(apiKeyDB.LastUsedAt) >= apiKeyLastUsedResolution {
+		_, err := dao.db.NewUpdate().
+			Model((*APIKeySchema)(nil)).
+			Set("last_used_at = ?", now).
+			Where("uuid = ?", apiKeyDB.UUID).
+			Exec(ctx)
+		if err != nil {
+			return nil, store.NewStorageError("failed to update api key last used", err)
+		}
+		apiKeyDB.LastUsedAt = now
+	}
+
+	return apiKeySchemaToAPIKey(apiKeyDB), nil
+}
+
+// hashAPIKey returns the hash of key stored in place of the key. API keys are random, so
+// they don't need a slow password hash.
+func hashAPIKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+func apiKeySchemaToAPIKey(apiKey *APIKeySchema) *models.APIKey {
+	var lastUsedAt, revokedAt *time.Time
+	if !apiKey.LastUsedAt.IsZero() {
+		lastUsedAt = &apiKey.LastUsedAt
+	}
+	if !apiKey.RevokedAt.IsZero() {
+		revokedAt = &apiKey.RevokedAt
+	}
+	return &models.APIKey{
+		UUID:       apiKey.UUID,
+		CreatedAt:  apiKey.CreatedAt,
+		ProjectID:  apiKey.ProjectID,
+		Name:       apiKey.Name,
+		Scope:      models.APIKeyScope(apiKey.Scope),
+		Prefix:     apiKey.Prefix,
+		LastUsedAt: lastUsedAt,
+		RevokedAt:  revokedAt,
+	}
+}
@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/testutils"
+)
+
+func TestAPIKeyStore(t *testing.T) {
+	ctx := models.WithProjectID(testCtx, testutils.GenerateRandomString(8))
+	otherCtx := models.WithProjectID(testCtx, testutils.GenerateRandomString(8))
+	apiKeyStore := NewAPIKeyStoreDAO(testDB)
+
+	created, err := apiKeyStore.CreateAPIKey(ctx, &models.CreateAPIKeyRequest{
+		Name:  "test",
+		Scope: models.APIKeyScopeWrite,
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.Key, models.APIKeyPrefix))
+	assert.True(t, strings.HasPrefix(created.Key, created.Prefix))
+	assert.Nil(t, created.LastUsedAt)
+
+	t.Run("verify", func(t *testing.T) {
+		apiKey, err := apiKeyStore.VerifyAPIKey(testCtx, created.Key)
+		require.NoError(t, err)
+		assert.Equal(t, created.UUID, apiKey.UUID)
+		assert.Equal(t, models.APIKeyScopeWrite, apiKey.Scope)
+		assert.NotNil(t, apiKey.LastUsedAt)
+
+		_, err = apiKeyStore.VerifyAPIKey(testCtx, created.Key+"x")
+		assert.ErrorIs(t, err, models.ErrNotFound)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		apiKeys, err := apiKeyStore.ListAPIKeys(ctx)
+		require.NoError(t, err)
+		require.Len(t, apiKeys, 1)
+		assert.Equal(t, created.UUID, apiKeys[0].UUID)
+
+		apiKeys, err = apiKeyStore.ListAPIKeys(otherCtx)
+		require.NoError(t, err)
+		assert.Empty(t, apiKeys)
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		err := apiKeyStore.RevokeAPIKey(otherCtx, created.UUID)
+		assert.ErrorIs(t, err, models.ErrNotFound)
+
+		require.NoError(t, apiKeyStore.RevokeAPIKey(ctx, created.UUID))
+		_, err = apiKeyStore.VerifyAPIKey(testCtx, created.Key)
+		assert.ErrorIs(t, err, models.ErrNotFound)
+
+		err = apiKeyStore.RevokeAPIKey(ctx, created.UUID)
+		assert.ErrorIs(t, err, models.ErrNotFound)
+	})
+
+	t.Run("invalid scope", func(t *testing.T) {
+		_, err := apiKeyStore.CreateAPIKey(ctx, &models.CreateAPIKeyRequest{Scope: "root"})
+		assert.ErrorIs(t, err, models.ErrBadRequest)
+	})
+}
@@ -0,0 +1,304 @@
+package postgres
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/uptrace/bun"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/store"
+)
+
+const (
+	// SessionArchiveFormat identifies the archives of inactive sessions
+	SessionArchiveFormat = "zep-session-archive"
+	// SessionArchiveVersion is the version of the session archive format
+	SessionArchiveVersion = 1
+)
+
+// A session archive is a backup archive of a session's messages and summaries, and their
+// embeddings, with its own format identifier. The session itself stays in Postgres.
+var sessionArchiveRecordTypes = map[backupRecordType]bool{
+	backupMessage:          true,
+	backupMessageEmbedding: true,
+	backupSummary:          true,
+	backupSummaryEmbedding: true,
+}
+
+// sessionArchiveKey is the object key of a session's archive. Each archive of a session has
+// its own key, so that deleting a rehydrated archive never deletes a later one.
+func sessionArchiveKey(sessionID string, archivedAt time.Time) string {
+	return fmt.Sprintf("%s/%d.jsonl.gz", sessionID, archivedAt.UnixMicro())
+}
+
+// ArchiveSessions moves the messages and summaries, and their embeddings, of the sessions that
+// haven't been updated within cfg.InactiveDays to the ArchiveStore. Deleted records and
+// summary abstracts stay in Postgres, as do message embeddings held by a VectorIndex.
+func (pms *PostgresMemoryStore) ArchiveSessions(
+	ctx context.Context,
+	cfg *config.ArchivalConfig,
+) ([]string, error) {
+	if pms.ArchiveStore == nil {
+		return nil, store.NewStorageError("no archive store is configured", nil)
+	}
+	if cfg.InactiveDays <= 0 {
+		return nil, models.NewBadRequestError("inactive_days must be positive")
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -cfg.InactiveDays)
+	var sessionIDs []string
+	err := pms.Client.NewSelect().
+		Model((*SessionSchema)(nil)).
+		Column("session_id").
+		Where("archived_at IS NULL").
+		Where("updated_at < ?", cutoff).
+		Order("id ASC").
+		Scan(ctx, &sessionIDs)
+	if err != nil {
+		return nil, store.NewStorageError("failed to get inactive sessions", err)
+	}
+
+	var archived []string
+	for _, sessionID := range sessionIDs {
+		ok, err := archiveSession(ctx, pms.Client, pms.ArchiveStore, sessionID, cutoff)
+		if err != nil {
+			return archived, store.NewStorageError("failed to archive session "+sessionID, err)
+		}
+		if ok {
+			archived = append(archived, sessionID)
+		}
+	}
+
+	return archived, nil
+}
+
+// archiveSession archives a session if it's still inactive, returning whether it was. The
+// session is locked while its archive is written, as messages can't be added to a session
+// without updating it.
+func archiveSession(
+	ctx context.Context,
+	db *bun.DB,
+	archiveStore models.ObjectStore,
+	sessionID string,
+	cutoff time.Time,
+) (bool, error) {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer rollbackOnError(tx)
+
+	exists, err := tx.NewSelect().
+		Model((*SessionSchema)(nil)).
+		Where("session_id = ?", sessionID).
+		Where("archived_at IS NULL").
+		Where("updated_at < ?", cutoff).
+		For("UPDATE").
+		Exists(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to lock session: %w", err)
+	}
+	if !exists {
+		// the session was updated, deleted or archived since it was selected
+		return false, nil
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	b := &backupWriter{db: tx, encoder: json.NewEncoder(gz), stats: &BackupStats{}}
+	err = b.encoder.Encode(backupHeader{
+		Format:    SessionArchiveFormat,
+		Version:   SessionArchiveVersion,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to write archive header: %w", err)
+	}
+	if err := b.writeMessages(ctx, sessionID); err != nil {
+		return false, err
+	}
+	if err := b.writeSummaries(ctx, sessionID); err != nil {
+		return false, err
+	}
+	if err := gz.Close(); err != nil {
+		return false, fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	// stored at microsecond precision, as is the key
+	archivedAt := time.Now().Truncate(time.Microsecond)
+	err = archiveStore.PutObject(ctx, sessionArchiveKey(sessionID, archivedAt), buf.Bytes())
+	if err != nil {
+		return false, err
+	}
+
+	// Only the live rows written to the archive are deleted, as ForceDelete doesn't exclude
+	// deleted rows. Summaries are deleted before the messages they reference.
+	for _, schema := range []interface{}{
+		(*SummaryVectorStoreSchema)(nil),
+		(*SummaryStoreSchema)(nil),
+		(*MessageVectorStoreSchema)(nil),
+		(*MessageStoreSchema)(nil),
+	} {
+		_, err := tx.NewDelete().
+			Model(schema).
+			Where("session_id = ?", sessionID).
+			Where("deleted_at IS NULL").
+			ForceDelete().
+			Exec(ctx)
+		if err != nil {
+			return false, fmt.Errorf("failed to delete archived rows from %T: %w", schema, err)
+		}
+	}
+
+	_, err = tx.NewUpdate().
+		Model((*SessionSchema)(nil)).
+		Set("archived_at = ?", archivedAt).
+		Where("session_id = ?", sessionID).
+		Exec(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark session archived: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return true, nil
+}
+
+// rehydrateSession restores an archived session's records, if it's archived. Restoring is
+// idempotent, so an interrupted rehydration is completed by the next. Rehydrating a session
+// updates it, so that it isn't archived again until it's been inactive once more.
+func (pms *PostgresMemoryStore) rehydrateSession(ctx context.Context, sessionID string) error {
+	if pms.ArchiveStore == nil {
+		return nil
+	}
+
+	session := SessionSchema{}
+	err := pms.Client.NewSelect().
+		Model(&session).
+		Column("archived_at").
+		Where("session_id = ?", sessionID).
+		Scan(ctx)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && session.ArchivedAt.IsZero()) {
+		return nil
+	}
+	if err != nil {
+		return store.NewStorageError("failed to get session", err)
+	}
+
+	key := sessionArchiveKey(sessionID, session.ArchivedAt)
+	data, err := pms.ArchiveStore.GetObject(ctx, key)
+	if err != nil {
+		return store.NewStorageError("failed to get archive of session "+sessionID, err)
+	}
+	if err := restoreSessionArchive(ctx, pms.Client, bytes.NewReader(data)); err != nil {
+		return store.NewStorageError("failed to restore archive of session "+sessionID, err)
+	}
+
+	// the session may have been rehydrated concurrently
+	_, err = pms.Client.NewUpdate().
+		Model((*SessionSchema)(nil)).
+		Set("archived_at = NULL").
+		Set("updated_at = current_timestamp").
+		Where("session_id = ?", sessionID).
+		Where("archived_at = ?", session.ArchivedAt).
+		Exec(ctx)
+	if err != nil {
+		return store.NewStorageError("failed to mark session rehydrated", err)
+	}
+
+	if err := pms.ArchiveStore.DeleteObject(ctx, key); err != nil {
+		log.Warningf("failed to delete archive of rehydrated session %s: %s", sessionID, err)
+	}
+
+	return nil
+}
+
+// restoreSessionArchive loads a session archive, skipping rows that already exist
+func restoreSessionArchive(ctx context.Context, db *bun.DB, r io.Reader) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("failed to read archive: %w", err)
+	}
+	defer gz.Close()
+
+	decoder := json.NewDecoder(gz)
+	var header backupHeader
+	if err := decoder.Decode(&header); err != nil {
+		return fmt.Errorf("failed to read archive header: %w", err)
+	}
+	if header.Format != SessionArchiveFormat {
+		return errors.New("not a zep session archive")
+	}
+	if header.Version < 1 || header.Version > SessionArchiveVersion {
+		return fmt.Errorf("unsupported session archive version %d", header.Version)
+	}
+
+	restorer := &backupRestorer{db: db, stats: &BackupStats{}}
+	for {
+		var record backupRecord
+		err := decoder.Decode(&record)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive record: %w", err)
+		}
+		if !sessionArchiveRecordTypes[record.Type] {
+			return fmt.Errorf("unexpected session archive record type %q", record.Type)
+		}
+		if err := restorer.add(ctx, &record); err != nil {
+			return err
+		}
+	}
+
+	return restorer.flush(ctx)
+}
+
+// deleteSessionArchives deletes the archives of hard deleted sessions, whose keys were got
+// using sessionArchiveKeys before the sessions were deleted. Failures are logged, as the
+// sessions are already gone.
+func (pms *PostgresMemoryStore) deleteSessionArchives(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := pms.ArchiveStore.DeleteObject(ctx, key); err != nil {
+			log.Warningf("failed to delete session archive %s: %s", key, err)
+		}
+	}
+}
+
+// sessionArchiveKeys returns the archive keys of the archived sessions, including deleted
+// sessions, matching the where condition
+func sessionArchiveKeys(
+	ctx context.Context,
+	db bun.IDB,
+	where string,
+	args ...interface{},
+) ([]string, error) {
+	var sessions []SessionSchema
+	err := db.NewSelect().
+		Model(&sessions).
+		Column("session_id", "archived_at").
+		WhereAllWithDeleted().
+		Where("archived_at IS NOT NULL").
+		Where(where, args...).
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get archived sessions: %w", err)
+	}
+
+	keys := make([]string, len(sessions))
+	for i, s := range sessions {
+		keys[i] = sessionArchiveKey(s.SessionID, s.ArchivedAt)
+	}
+	return keys, nil
+}
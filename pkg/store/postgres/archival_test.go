@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/store"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+type memoryObjectStore struct {
+	objects map[string][]byte
+}
+
+func (m *memoryObjectStore) PutObject(_ context.Context, key string, data []byte) error {
+	m.objects[key] = data
+	return nil
+}
+
+func (m *memoryObjectStore) GetObject(_ context.Context, key string) ([]byte, error) {
+	data, ok := m.objects[key]
+	if !ok {
+		return nil, models.NewNotFoundError("object " + key)
+	}
+	return data, nil
+}
+
+func (m *memoryObjectStore) DeleteObject(_ context.Context, key string) error {
+	delete(m.objects, key)
+	return nil
+}
+
+func TestArchiveSessions(t *testing.T) {
+	archiveStore := &memoryObjectStore{objects: make(map[string][]byte)}
+	pms := &PostgresMemoryStore{
+		BaseMemoryStore: store.BaseMemoryStore[*bun.DB]{Client: testDB},
+		SessionStore:    NewSessionDAO(testDB),
+		ArchiveStore:    archiveStore,
+	}
+
+	sessionID := createSession(t)
+	msgs, err := putMessages(testCtx, testDB, sessionID, []models.Message{
+		{Role: "user", Content: "Hello"},
+		{Role: "assistant", Content: "Hi there!"},
+	})
+	require.NoError(t, err)
+	_, err = putSummary(testCtx, testDB, sessionID, &models.Summary{
+		Content:          "a greeting",
+		SummaryPointUUID: msgs[1].UUID,
+	})
+	require.NoError(t, err)
+
+	_, err = testDB.NewUpdate().
+		Model((*SessionSchema)(nil)).
+		Set("updated_at = ?", time.Now().AddDate(0, 0, -31)).
+		Where("session_id = ?", sessionID).
+		Exec(testCtx)
+	require.NoError(t, err)
+
+	archived, err := pms.ArchiveSessions(testCtx, &config.ArchivalConfig{InactiveDays: 30})
+	require.NoError(t, err)
+	assert.Contains(t, archived, sessionID)
+	assert.Len(t, archiveStore.objects, 1)
+
+	count, err := testDB.NewSelect().
+		Model((*MessageStoreSchema)(nil)).
+		Where("session_id = ?", sessionID).
+		Count(testCtx)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	// reading the session rehydrates it
+	messages, err := pms.GetMessagesByUUID(
+		testCtx,
+		appState,
+		sessionID,
+		[]uuid.UUID{msgs[0].UUID, msgs[1].UUID},
+	)
+	require.NoError(t, err)
+	assert.Len(t, messages, 2)
+	summary, err := pms.GetSummary(testCtx, appState, sessionID)
+	require.NoError(t, err)
+	assert.Equal(t, "a greeting", summary.Content)
+	assert.Empty(t, archiveStore.objects)
+
+	// a rehydrated session is active again
+	archived, err = pms.ArchiveSessions(testCtx, &config.ArchivalConfig{InactiveDays: 30})
+	require.NoError(t, err)
+	assert.NotContains(t, archived, sessionID)
+}
@@ -0,0 +1,170 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/store"
+)
+
+// DefaultAuditEventListLimit is the number of audit events listed if no limit is given
+const DefaultAuditEventListLimit = 100
+
+type AuditEventSchema struct {
+	bun.BaseModel `bun:"table:audit_log,alias:al" yaml:"-"`
+
+	UUID           uuid.UUID `bun:",pk,type:uuid,default:gen_random_uuid()"`
+	ID             int64     `bun:",autoincrement"`
+	CreatedAt      time.Time `bun:"type:timestamptz,notnull,default:current_timestamp"`
+	ProjectID      string    `bun:",notnull,default:'default'"`
+	ActorType      string    `bun:",notnull"`
+	Actor          string    `bun:",notnull"`
+	Action         string    `bun:",notnull"`
+	Method         string    `bun:",notnull"`
+	Route          string    `bun:",notnull"`
+	SessionID      string    `bun:",nullzero"`
+	UserID         string    `bun:",nullzero"`
+	CollectionName string    `bun:",nullzero"`
+	StatusCode     int       `bun:",notnull"`
+	RequestID      string    `bun:",nullzero"`
+}
+
+func (*AuditEventSchema) AfterCreateTable(
+	ctx context.Context,
+	query *bun.CreateTableQuery,
+) error {
+	indexes := []struct {
+		name   string
+		column string
+	}{
+		{"audit_log_actor_idx", "actor"},
+		{"audit_log_session_id_idx", "session_id"},
+		{"audit_log_created_at_idx", "created_at"},
+	}
+	for _, index := range indexes {
+		_, err := query.DB().NewCreateIndex().
+			Model((*AuditEventSchema)(nil)).
+			Index(index.name).
+			Column(index.column).
+			IfNotExists().
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var _ models.AuditLogStore = &AuditLogStoreDAO{}
+
+// AuditLogStoreDAO appends audit events to the audit_log table. It never updates or deletes
+// them, and they're exempt from retention rules and purges.
+type AuditLogStoreDAO struct {
+	db *bun.DB
+}
+
+func NewAuditLogStoreDAO(db *bun.DB) *AuditLogStoreDAO {
+	return &AuditLogStoreDAO{
+		db: db,
+	}
+}
+
+func (dao *AuditLogStoreDAO) PutAuditEvent(ctx context.Context, event *models.AuditEvent) error {
+	eventDB := &AuditEventSchema{
+		ProjectID:      event.ProjectID,
+		ActorType:      string(event.ActorType),
+		Actor:          event.Actor,
+		Action:         string(event.Action),
+		Method:         event.Method,
+		Route:          event.Route,
+		SessionID:      event.SessionID,
+		UserID:         event.UserID,
+		CollectionName: event.CollectionName,
+		StatusCode:     event.StatusCode,
+		RequestID:      event.RequestID,
+	}
+	if eventDB.ProjectID == "" {
+		eventDB.ProjectID = models.DefaultProjectID
+	}
+
+	_, err := dao.db.NewInsert().Model(eventDB).Returning("*").Exec(ctx)
+	if err != nil {
+		return store.NewStorageError("failed to put audit event", err)
+	}
+	*event = *auditEventSchemaToAuditEvent(eventDB)
+
+	return nil
+}
+
+func (dao *AuditLogStoreDAO) ListAuditEvents(
+	ctx context.Context,
+	request *models.AuditEventListRequest,
+) (*models.AuditEventListResponse, error) {
+	limit := request.Limit
+	if limit <= 0 {
+		limit = DefaultAuditEventListLimit
+	}
+
+	var eventsDB []AuditEventSchema
+	query := dao.db.NewSelect().
+		Model(&eventsDB).
+		Where("id > ?", request.Cursor).
+		OrderExpr("id ASC").
+		// select one more than the limit to determine whether there's a next page
+		Limit(limit + 1)
+	if request.ProjectID != "" {
+		query = query.Where("project_id = ?", request.ProjectID)
+	}
+	if request.Actor != "" {
+		query = query.Where("actor = ?", request.Actor)
+	}
+	if request.Action != "" {
+		query = query.Where("action = ?", request.Action)
+	}
+	if request.SessionID != "" {
+		query = query.Where("session_id = ?", request.SessionID)
+	}
+	if !request.Since.IsZero() {
+		query = query.Where("created_at >= ?", request.Since)
+	}
+	if !request.Until.IsZero() {
+		query = query.Where("created_at < ?", request.Until)
+	}
+	if err := query.Scan(ctx); err != nil {
+		return nil, store.NewStorageError("failed to list audit events", err)
+	}
+
+	response := &models.AuditEventListResponse{Events: []models.AuditEvent{}}
+	if len(eventsDB) > limit {
+		eventsDB = eventsDB[:limit]
+		response.NextCursor = eventsDB[limit-1].ID
+	}
+	for i := range eventsDB {
+		response.Events = append(response.Events, *auditEventSchemaToAuditEvent(&eventsDB[i]))
+	}
+
+	return response, nil
+}
+
+func auditEventSchemaToAuditEvent(eventDB *AuditEventSchema) *models.AuditEvent {
+	return &models.AuditEvent{
+		UUID:           eventDB.UUID,
+		ID:             eventDB.ID,
+		CreatedAt:      eventDB.CreatedAt,
+		ProjectID:      eventDB.ProjectID,
+		ActorType:      models.AuditActorType(eventDB.ActorType),
+		Actor:          eventDB.Actor,
+		Action:         models.AuditAction(eventDB.Action),
+		Method:         eventDB.Method,
+		Route:          eventDB.Route,
+		SessionID:      eventDB.SessionID,
+		UserID:         eventDB.UserID,
+		CollectionName: eventDB.CollectionName,
+		StatusCode:     eventDB.StatusCode,
+		RequestID:      eventDB.RequestID,
+	}
+}
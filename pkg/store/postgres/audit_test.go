@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/testutils"
+)
+
+func TestAuditLogStore(t *testing.T) {
+	auditLog := NewAuditLogStoreDAO(testDB)
+	projectID := testutils.GenerateRandomString(8)
+	sessionID := testutils.GenerateRandomString(10)
+	start := time.Now().Add(-time.Minute)
+
+	events := []*models.AuditEvent{
+		{
+			ProjectID: projectID,
+			ActorType: models.AuditActorAPIKey,
+			Actor:     "key",
+			Action:    models.AuditActionPut,
+			Method:    "POST",
+			Route:     "/api/v1/sessions/{sessionId}/memory",
+			SessionID: sessionID,
+		},
+		{
+			ProjectID: projectID,
+			ActorType: models.AuditActorUser,
+			Actor:     "user",
+			Action:    models.AuditActionSearch,
+			Method:    "POST",
+			Route:     "/api/v1/sessions/{sessionId}/search",
+			SessionID: sessionID,
+		},
+		{
+			ProjectID: projectID,
+			ActorType: models.AuditActorAPIKey,
+			Actor:     "key",
+			Action:    models.AuditActionDelete,
+			Method:    "DELETE",
+			Route:     "/api/v1/sessions/{sessionId}/memory",
+			SessionID: sessionID,
+		},
+	}
+	for _, event := range events {
+		event.StatusCode = 200
+		require.NoError(t, auditLog.PutAuditEvent(testCtx, event))
+		assert.NotZero(t, event.ID)
+		assert.False(t, event.CreatedAt.IsZero())
+	}
+
+	t.Run("paginate", func(t *testing.T) {
+		request := &models.AuditEventListRequest{ProjectID: projectID, Limit: 2}
+		page, err := auditLog.ListAuditEvents(testCtx, request)
+		require.NoError(t, err)
+		require.Len(t, page.Events, 2)
+		assert.Equal(t, events[0].UUID, page.Events[0].UUID)
+		assert.Equal(t, events[1].ID, page.NextCursor)
+
+		request.Cursor = page.NextCursor
+		page, err = auditLog.ListAuditEvents(testCtx, request)
+		require.NoError(t, err)
+		require.Len(t, page.Events, 1)
+		assert.Equal(t, events[2].UUID, page.Events[0].UUID)
+		assert.Zero(t, page.NextCursor)
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		page, err := auditLog.ListAuditEvents(testCtx, &models.AuditEventListRequest{
+			SessionID: sessionID,
+			Actor:     "key",
+			Action:    models.AuditActionDelete,
+			Since:     start,
+		})
+		require.NoError(t, err)
+		require.Len(t, page.Events, 1)
+		assert.Equal(t, events[2].UUID, page.Events[0].UUID)
+
+		page, err = auditLog.ListAuditEvents(testCtx, &models.AuditEventListRequest{
+			SessionID: sessionID,
+			Until:     start,
+		})
+		require.NoError(t, err)
+		assert.Empty(t, page.Events)
+	})
+}
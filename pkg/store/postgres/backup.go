@@ -0,0 +1,870 @@
+package postgres
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pgvector/pgvector-go"
+	"github.com/uptrace/bun"
+
+	"github.com/getzep/zep/pkg/models"
+)
+
+const (
+	// BackupFormat identifies Zep backup archives
+	BackupFormat = "zep-backup"
+	// BackupVersion is the version of the archive format written by Backup. Restore reads
+	// archives of this version and earlier.
+	BackupVersion = 1
+
+	backupPageSize = 100
+	// maxBackupLineSize is the longest record that Restore reads
+	maxBackupLineSize = 64 << 20 // 64MB
+)
+
+type backupRecordType string
+
+// Records are written in this order, so that rows are restored after the rows they
+// reference. A session's messages are followed by their embeddings, then the session's
+// summaries and their embeddings. A collection is followed by its documents.
+const (
+	backupUser             backupRecordType = "user"
+	backupSession          backupRecordType = "session"
+	backupMessage          backupRecordType = "message"
+	backupMessageEmbedding backupRecordType = "message_embedding"
+	backupSummary          backupRecordType = "summary"
+	backupSummaryEmbedding backupRecordType = "summary_embedding"
+	backupCollection       backupRecordType = "collection"
+	backupDocument         backupRecordType = "document"
+)
+
+// A backup archive is gzip-compressed JSONL. The first line is a backupHeader and every
+// other line a backupRecord.
+type backupHeader struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type backupRecord struct {
+	Type backupRecordType `json:"type"`
+	Data json.RawMessage  `json:"data"`
+}
+
+// backupUserRecord is a user with its project. Users of archives written before projects were
+// introduced have no project, and are restored to the default project.
+type backupUserRecord struct {
+	models.User
+	ProjectID string `json:"project_id,omitempty"`
+}
+
+// backupSessionRecord is a session with its project, as backupUserRecord is a user
+type backupSessionRecord struct {
+	models.Session
+	ProjectID string `json:"project_id,omitempty"`
+}
+
+type backupMessageRecord struct {
+	SessionID string `json:"session_id"`
+	models.Message
+	Language string `json:"language"`
+}
+
+type backupSummaryRecord struct {
+	SessionID string `json:"session_id"`
+	models.Summary
+}
+
+// backupEmbeddingRecord is the embedding of a message or summary
+type backupEmbeddingRecord struct {
+	SessionID string    `json:"session_id"`
+	UUID      uuid.UUID `json:"uuid"`
+	Embedding []float32 `json:"embedding"`
+}
+
+type backupCollectionRecord struct {
+	UUID                uuid.UUID               `json:"uuid"`
+	CreatedAt           time.Time               `json:"created_at"`
+	UpdatedAt           time.Time               `json:"updated_at"`
+	Name                string                  `json:"name"`
+	Description         string                  `json:"description"`
+	Metadata            map[string]interface{}  `json:"metadata,omitempty"`
+	TableName           string                  `json:"table_name"`
+	EmbeddingModelName  string                  `json:"embedding_model_name"`
+	EmbeddingService    string                  `json:"embedding_service"`
+	EmbeddingDimensions int                     `json:"embedding_dimensions"`
+	IsAutoEmbedded      bool                    `json:"is_auto_embedded"`
+	DistanceFunction    models.DistanceFunction `json:"distance_function"`
+	IsNormalized        bool                    `json:"is_normalized"`
+	ListCount           int                     `json:"list_count"`
+	ProbeCount          int                     `json:"probe_count"`
+	ProjectID           string                  `json:"project_id,omitempty"`
+}
+
+type backupDocumentRecord struct {
+	Collection string                 `json:"collection"`
+	UUID       uuid.UUID              `json:"uuid"`
+	CreatedAt  time.Time              `json:"created_at"`
+	UpdatedAt  time.Time              `json:"updated_at"`
+	DocumentID string                 `json:"document_id,omitempty"`
+	Content    string                 `json:"content,omitempty"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+	IsEmbedded bool                   `json:"is_embedded"`
+	Embedding  []float32              `json:"embedding,omitempty"`
+}
+
+// BackupStats counts the rows written by Backup or restored by Restore.
+type BackupStats struct {
+	Users       int `json:"users"`
+	Sessions    int `json:"sessions"`
+	Messages    int `json:"messages"`
+	Summaries   int `json:"summaries"`
+	Embeddings  int `json:"embeddings"`
+	Collections int `json:"collections"`
+	Documents   int `json:"documents"`
+}
+
+func (s *BackupStats) add(recordType backupRecordType, n int) {
+	switch recordType {
+	case backupUser:
+		s.Users += n
+	case backupSession:
+		s.Sessions += n
+	case backupMessage:
+		s.Messages += n
+	case backupSummary:
+		s.Summaries += n
+	case backupMessageEmbedding, backupSummaryEmbedding:
+		s.Embeddings += n
+	case backupCollection:
+		s.Collections += n
+	case backupDocument:
+		s.Documents += n
+	}
+}
+
+// Backup writes all users, sessions, messages, summaries and document collections, along
+// with their embeddings, to w as a versioned archive. Deleted rows aren't backed up. Message
+// embeddings held in an external vector index aren't backed up, and may be recreated after
+// a restore by reindexing.
+func Backup(ctx context.Context, db *bun.DB, w io.Writer) (*BackupStats, error) {
+	gz := gzip.NewWriter(w)
+	b := &backupWriter{db: db, encoder: json.NewEncoder(gz), stats: &BackupStats{}}
+
+	err := b.encoder.Encode(backupHeader{
+		Format:    BackupFormat,
+		Version:   BackupVersion,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return b.stats, fmt.Errorf("failed to write backup header: %w", err)
+	}
+
+	if err := b.writeUsers(ctx); err != nil {
+		return b.stats, err
+	}
+	if err := b.writeSessions(ctx); err != nil {
+		return b.stats, err
+	}
+	if err := b.writeCollections(ctx); err != nil {
+		return b.stats, err
+	}
+
+	if err := gz.Close(); err != nil {
+		return b.stats, fmt.Errorf("failed to write backup: %w", err)
+	}
+
+	return b.stats, nil
+}
+
+type backupWriter struct {
+	db      bun.IDB
+	encoder *json.Encoder
+	stats   *BackupStats
+}
+
+func (b *backupWriter) write(recordType backupRecordType, data interface{}) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", recordType, err)
+	}
+	if err := b.encoder.Encode(backupRecord{Type: recordType, Data: raw}); err != nil {
+		return fmt.Errorf("failed to write %s: %w", recordType, err)
+	}
+	b.stats.add(recordType, 1)
+
+	return nil
+}
+
+func (b *backupWriter) writeUsers(ctx context.Context) error {
+	var cursor int64
+	for {
+		var users []UserSchema
+		err := b.db.NewSelect().
+			Model(&users).
+			Where("id > ?", cursor).
+			Order("id ASC").
+			Limit(backupPageSize).
+			Scan(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get users: %w", err)
+		}
+		for _, u := range users {
+			err := b.write(backupUser, &backupUserRecord{
+				User: models.User{
+					UUID:      u.UUID,
+					CreatedAt: u.CreatedAt,
+					UpdatedAt: u.UpdatedAt,
+					UserID:    u.UserID,
+					Email:     u.Email,
+					FirstName: u.FirstName,
+					LastName:  u.LastName,
+					Metadata:  u.Metadata,
+				},
+				ProjectID: u.ProjectID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if len(users) < backupPageSize {
+			return nil
+		}
+		cursor = users[len(users)-1].ID
+	}
+}
+
+func (b *backupWriter) writeSessions(ctx context.Context) error {
+	var cursor int64
+	for {
+		var sessions []SessionSchema
+		err := b.db.NewSelect().
+			Model(&sessions).
+			Where("id > ?", cursor).
+			Order("id ASC").
+			Limit(backupPageSize).
+			Scan(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get sessions: %w", err)
+		}
+		for _, s := range sessions {
+			err := b.write(backupSession, &backupSessionRecord{
+				Session: models.Session{
+					UUID:      s.UUID,
+					CreatedAt: s.CreatedAt,
+					UpdatedAt: s.UpdatedAt,
+					SessionID: s.SessionID,
+					Metadata:  s.Metadata,
+					UserID:    s.UserID,
+					Language:  s.Language,
+				},
+				ProjectID: s.ProjectID,
+			})
+			if err != nil {
+				return err
+			}
+			if err := b.writeMessages(ctx, s.SessionID); err != nil {
+				return err
+			}
+			if err := b.writeSummaries(ctx, s.SessionID); err != nil {
+				return err
+			}
+		}
+		if len(sessions) < backupPageSize {
+			return nil
+		}
+		cursor = sessions[len(sessions)-1].ID
+	}
+}
+
+func (b *backupWriter) writeMessages(ctx context.Context, sessionID string) error {
+	var cursor int64
+	for {
+		var messages []MessageStoreSchema
+		err := b.db.NewSelect().
+			Model(&messages).
+			Where("session_id = ?", sessionID).
+			Where("id > ?", cursor).
+			Order("id ASC").
+			Limit(backupPageSize).
+			Scan(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get messages of session %s: %w", sessionID, err)
+		}
+		if len(messages) == 0 {
+			return nil
+		}
+
+		uuids := make([]uuid.UUID, len(messages))
+		for i, m := range messages {
+			uuids[i] = m.UUID
+			err := b.write(backupMessage, &backupMessageRecord{
+				SessionID: sessionID,
+				Message: models.Message{
+					UUID:       m.UUID,
+					CreatedAt:  m.CreatedAt,
+					UpdatedAt:  m.UpdatedAt,
+					Role:       m.Role,
+					Content:    m.Content,
+					Metadata:   m.Metadata,
+					TokenCount: m.TokenCount,
+					IsSystem:   m.IsSystem,
+				},
+				Language: m.Language,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		var embeddings []MessageVectorStoreSchema
+		err = b.db.NewSelect().
+			Model(&embeddings).
+			Where("message_uuid IN (?)", bun.In(uuids)).
+			Where("is_embedded").
+			Scan(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get message embeddings of session %s: %w", sessionID, err)
+		}
+		for _, e := range embeddings {
+			err := b.write(backupMessageEmbedding, &backupEmbeddingRecord{
+				SessionID: sessionID,
+				UUID:      e.MessageUUID,
+				Embedding: e.Embedding.Slice(),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(messages) < backupPageSize {
+			return nil
+		}
+		cursor = messages[len(messages)-1].ID
+	}
+}
+
+func (b *backupWriter) writeSummaries(ctx context.Context, sessionID string) error {
+	var summaries []SummaryStoreSchema
+	err := b.db.NewSelect().
+		Model(&summaries).
+		Where("session_id = ?", sessionID).
+		Order("created_at ASC").
+		Scan(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get summaries of session %s: %w", sessionID, err)
+	}
+	if len(summaries) == 0 {
+		return nil
+	}
+
+	uuids := make([]uuid.UUID, len(summaries))
+	for i, s := range summaries {
+		uuids[i] = s.UUID
+		err := b.write(backupSummary, &backupSummaryRecord{
+			SessionID: sessionID,
+			Summary: models.Summary{
+				UUID:             s.UUID,
+				CreatedAt:        s.CreatedAt,
+				Content:          s.Content,
+				SummaryPointUUID: s.SummaryPointUUID,
+				Metadata:         s.Metadata,
+				TokenCount:       s.TokenCount,
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	var embeddings []SummaryVectorStoreSchema
+	err = b.db.NewSelect().
+		Model(&embeddings).
+		Where("summary_uuid IN (?)", bun.In(uuids)).
+		Where("is_embedded").
+		Scan(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get summary embeddings of session %s: %w", sessionID, err)
+	}
+	for _, e := range embeddings {
+		err := b.write(backupSummaryEmbedding, &backupEmbeddingRecord{
+			SessionID: sessionID,
+			UUID:      e.SummaryUUID,
+			Embedding: e.Embedding.Slice(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *backupWriter) writeCollections(ctx context.Context) error {
+	var collections []DocumentCollectionSchema
+	err := b.db.NewSelect().
+		Model(&collections).
+		Order("name ASC").
+		Scan(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get collections: %w", err)
+	}
+
+	for _, c := range collections {
+		err := b.write(backupCollection, &backupCollectionRecord{
+			UUID:                c.UUID,
+			CreatedAt:           c.CreatedAt,
+			UpdatedAt:           c.UpdatedAt,
+			Name:                c.Name,
+			Description:         c.Description,
+			Metadata:            c.Metadata,
+			TableName:           c.TableName,
+			EmbeddingModelName:  c.EmbeddingModelName,
+			EmbeddingService:    c.EmbeddingService,
+			EmbeddingDimensions: c.EmbeddingDimensions,
+			IsAutoEmbedded:      c.IsAutoEmbedded,
+			DistanceFunction:    c.DistanceFunction,
+			IsNormalized:        c.IsNormalized,
+			ListCount:           c.ListCount,
+			ProbeCount:          c.ProbeCount,
+			ProjectID:           c.ProjectID,
+		})
+		if err != nil {
+			return err
+		}
+		if err := b.writeDocuments(ctx, c.Name, c.TableName); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *backupWriter) writeDocuments(ctx context.Context, collection, tableName string) error {
+	cursor := uuid.Nil
+	for {
+		var documents []models.Document
+		err := b.db.NewSelect().
+			Model(&documents).
+			ModelTableExpr("? AS document", bun.Ident(tableName)).
+			Column(
+				"uuid",
+				"created_at",
+				"updated_at",
+				"content",
+				"metadata",
+				"document_id",
+				"embedding",
+				"is_embedded",
+			).
+			Where("uuid > ?", cursor).
+			Order("uuid ASC").
+			Limit(backupPageSize).
+			Scan(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get documents of collection %s: %w", collection, err)
+		}
+		for _, d := range documents {
+			err := b.write(backupDocument, &backupDocumentRecord{
+				Collection: collection,
+				UUID:       d.UUID,
+				CreatedAt:  d.CreatedAt,
+				UpdatedAt:  d.UpdatedAt,
+				DocumentID: d.DocumentID,
+				Content:    d.Content,
+				Metadata:   d.Metadata,
+				IsEmbedded: d.IsEmbedded,
+				Embedding:  d.Embedding,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if len(documents) < backupPageSize {
+			return nil
+		}
+		cursor = documents[len(documents)-1].UUID
+	}
+}
+
+// Restore loads an archive written by Backup, creating the schema and collection tables if
+// they don't exist. Rows that already exist are skipped, so a database may be restored into
+// more than once, and an interrupted restore re-run. The returned stats count the rows that
+// were restored.
+func Restore(
+	ctx context.Context,
+	appState *models.AppState,
+	db *bun.DB,
+	r io.Reader,
+) (*BackupStats, error) {
+	stats := &BackupStats{}
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return stats, fmt.Errorf("failed to read backup: %w", err)
+	}
+	defer gz.Close()
+
+	decoder := json.NewDecoder(gz)
+	var header backupHeader
+	if err := decoder.Decode(&header); err != nil {
+		return stats, fmt.Errorf("failed to read backup header: %w", err)
+	}
+	if header.Format != BackupFormat {
+		return stats, errors.New("not a zep backup")
+	}
+	if header.Version < 1 || header.Version > BackupVersion {
+		return stats, fmt.Errorf(
+			"unsupported backup version %d. versions up to %d are supported",
+			header.Version,
+			BackupVersion,
+		)
+	}
+
+	if err := CreateSchema(ctx, appState, db); err != nil {
+		return stats, fmt.Errorf("failed to create schema: %w", err)
+	}
+
+	restorer := &backupRestorer{
+		appState:    appState,
+		db:          db,
+		stats:       stats,
+		collections: make(map[string]string),
+	}
+	for {
+		var record backupRecord
+		err := decoder.Decode(&record)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return stats, fmt.Errorf("failed to read backup record: %w", err)
+		}
+		if err := restorer.add(ctx, &record); err != nil {
+			return stats, err
+		}
+	}
+	if err := restorer.flush(ctx); err != nil {
+		return stats, err
+	}
+
+	return stats, nil
+}
+
+// backupRestorer inserts records in batches. A batch holds records of one type and, for
+// documents, one collection.
+type backupRestorer struct {
+	appState *models.AppState
+	db       *bun.DB
+	stats    *BackupStats
+	// collections maps the names of restored collections to their tables
+	collections map[string]string
+
+	pendingType       backupRecordType
+	pendingCollection string
+	pending           []json.RawMessage
+}
+
+func (b *backupRestorer) add(ctx context.Context, record *backupRecord) error {
+	collection := ""
+	switch record.Type {
+	case backupCollection:
+		// collections are restored immediately, as their documents depend on their table
+		if err := b.flush(ctx); err != nil {
+			return err
+		}
+		return b.restoreCollection(ctx, record.Data)
+	case backupDocument:
+		var document struct {
+			Collection string `json:"collection"`
+		}
+		if err := json.Unmarshal(record.Data, &document); err != nil {
+			return fmt.Errorf("invalid document: %w", err)
+		}
+		collection = document.Collection
+	case backupUser, backupSession, backupMessage, backupMessageEmbedding, backupSummary,
+		backupSummaryEmbedding:
+	default:
+		return fmt.Errorf("unknown backup record type %q", record.Type)
+	}
+
+	if record.Type != b.pendingType || collection != b.pendingCollection ||
+		len(b.pending) >= backupPageSize {
+		if err := b.flush(ctx); err != nil {
+			return err
+		}
+	}
+	b.pendingType = record.Type
+	b.pendingCollection = collection
+	b.pending = append(b.pending, record.Data)
+
+	return nil
+}
+
+func (b *backupRestorer) flush(ctx context.Context) error {
+	if len(b.pending) == 0 {
+		return nil
+	}
+
+	var err error
+	var n int
+	switch b.pendingType {
+	case backupUser:
+		n, err = restoreRows(ctx, b.db, b.pending, func(u *backupUserRecord) UserSchema {
+			return UserSchema{
+				UUID:      u.UUID,
+				CreatedAt: u.CreatedAt,
+				UpdatedAt: u.UpdatedAt,
+				UserID:    u.UserID,
+				Email:     u.Email,
+				FirstName: u.FirstName,
+				LastName:  u.LastName,
+				Metadata:  u.Metadata,
+				ProjectID: u.ProjectID,
+			}
+		})
+	case backupSession:
+		n, err = restoreRows(ctx, b.db, b.pending, func(s *backupSessionRecord) SessionSchema {
+			return SessionSchema{
+				UUID:      s.UUID,
+				SessionID: s.SessionID,
+				CreatedAt: s.CreatedAt,
+				UpdatedAt: s.UpdatedAt,
+				Metadata:  s.Metadata,
+				UserID:    s.UserID,
+				Language:  s.Language,
+				ProjectID: s.ProjectID,
+			}
+		})
+	case backupMessage:
+		n, err = restoreRows(
+			ctx,
+			b.db,
+			b.pending,
+			func(m *backupMessageRecord) MessageStoreSchema {
+				return MessageStoreSchema{
+					UUID:       m.UUID,
+					CreatedAt:  m.CreatedAt,
+					UpdatedAt:  m.UpdatedAt,
+					SessionID:  m.SessionID,
+					Role:       m.Role,
+					Content:    m.Content,
+					TokenCount: m.TokenCount,
+					Metadata:   m.Metadata,
+					Language:   m.Language,
+					IsSystem:   m.IsSystem,
+				}
+			},
+		)
+	case backupMessageEmbedding:
+		n, err = restoreRows(
+			ctx,
+			b.db,
+			b.pending,
+			func(e *backupEmbeddingRecord) MessageVectorStoreSchema {
+				return MessageVectorStoreSchema{
+					SessionID:   e.SessionID,
+					MessageUUID: e.UUID,
+					Embedding:   pgvector.NewVector(e.Embedding),
+					IsEmbedded:  true,
+				}
+			},
+		)
+	case backupSummary:
+		n, err = restoreRows(
+			ctx,
+			b.db,
+			b.pending,
+			func(s *backupSummaryRecord) SummaryStoreSchema {
+				return SummaryStoreSchema{
+					UUID:             s.UUID,
+					CreatedAt:        s.CreatedAt,
+					SessionID:        s.SessionID,
+					Content:          s.Content,
+					Metadata:         s.Metadata,
+					TokenCount:       s.TokenCount,
+					SummaryPointUUID: s.SummaryPointUUID,
+				}
+			},
+		)
+	case backupSummaryEmbedding:
+		n, err = restoreRows(
+			ctx,
+			b.db,
+			b.pending,
+			func(e *backupEmbeddingRecord) SummaryVectorStoreSchema {
+				return SummaryVectorStoreSchema{
+					SessionID:   e.SessionID,
+					SummaryUUID: e.UUID,
+					Embedding:   pgvector.NewVector(e.Embedding),
+					IsEmbedded:  true,
+				}
+			},
+		)
+	case backupDocument:
+		n, err = b.restoreDocuments(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to restore %s: %w", b.pendingType, err)
+	}
+	b.stats.add(b.pendingType, n)
+	b.pending = b.pending[:0]
+
+	return nil
+}
+
+// restoreRows inserts the rows converted from records, skipping rows that already exist,
+// and returns the number of rows inserted.
+func restoreRows[R any, S any](
+	ctx context.Context,
+	db bun.IDB,
+	records []json.RawMessage,
+	toRow func(record *R) S,
+) (int, error) {
+	rows := make([]S, len(records))
+	for i, raw := range records {
+		var record R
+		if err := json.Unmarshal(raw, &record); err != nil {
+			return 0, fmt.Errorf("invalid record: %w", err)
+		}
+		rows[i] = toRow(&record)
+	}
+
+	result, err := db.NewInsert().
+		Model(&rows).
+		On("CONFLICT DO NOTHING").
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(n), nil
+}
+
+// restoreCollection creates a collection, if one with the same name doesn't exist, and its
+// document table.
+func (b *backupRestorer) restoreCollection(ctx context.Context, data json.RawMessage) error {
+	var c backupCollectionRecord
+	if err := json.Unmarshal(data, &c); err != nil {
+		return fmt.Errorf("invalid collection: %w", err)
+	}
+
+	// The collection's index is recreated as it would be for a new collection
+	indexType, isIndexed := models.IndexType("ivfflat"), false
+	if b.appState.Config.Store.Postgres.AvailableIndexes.HSNW {
+		indexType, isIndexed = "hnsw", true
+	}
+
+	collection := DocumentCollectionSchema{
+		DocumentCollection: models.DocumentCollection{
+			UUID:                c.UUID,
+			CreatedAt:           c.CreatedAt,
+			UpdatedAt:           c.UpdatedAt,
+			Name:                c.Name,
+			Description:         c.Description,
+			Metadata:            c.Metadata,
+			TableName:           c.TableName,
+			EmbeddingModelName:  c.EmbeddingModelName,
+			EmbeddingService:    c.EmbeddingService,
+			EmbeddingDimensions: c.EmbeddingDimensions,
+			IsAutoEmbedded:      c.IsAutoEmbedded,
+			DistanceFunction:    c.DistanceFunction,
+			IsNormalized:        c.IsNormalized,
+			IsIndexed:           isIndexed,
+			IndexType:           indexType,
+			ListCount:           c.ListCount,
+			ProbeCount:          c.ProbeCount,
+		},
+		ProjectID: c.ProjectID,
+	}
+	result, err := b.db.NewInsert().
+		Model(&collection).
+		On("CONFLICT DO NOTHING").
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to restore collection %s: %w", c.Name, err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to restore collection %s: %w", c.Name, err)
+	}
+
+	if n == 0 {
+		// the collection exists. its documents are restored to its existing table
+		err := b.db.NewSelect().
+			Model(&collection).
+			Column("table_name", "embedding_dimensions").
+			Where("name = ?", c.Name).
+			Scan(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get collection %s: %w", c.Name, err)
+		}
+	}
+	err = createDocumentTable(
+		ctx,
+		b.appState,
+		b.db,
+		collection.TableName,
+		collection.EmbeddingDimensions,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create table of collection %s: %w", c.Name, err)
+	}
+
+	b.collections[c.Name] = collection.TableName
+	b.stats.add(backupCollection, int(n))
+
+	return nil
+}
+
+func (b *backupRestorer) restoreDocuments(ctx context.Context) (int, error) {
+	tableName, ok := b.collections[b.pendingCollection]
+	if !ok {
+		return 0, fmt.Errorf("collection %s precedes its documents", b.pendingCollection)
+	}
+
+	documents := make([]models.Document, len(b.pending))
+	for i, raw := range b.pending {
+		var d backupDocumentRecord
+		if err := json.Unmarshal(raw, &d); err != nil {
+			return 0, fmt.Errorf("invalid record: %w", err)
+		}
+		documents[i] = models.Document{
+			DocumentBase: models.DocumentBase{
+				UUID:       d.UUID,
+				CreatedAt:  d.CreatedAt,
+				UpdatedAt:  d.UpdatedAt,
+				DocumentID: d.DocumentID,
+				Content:    d.Content,
+				Metadata:   d.Metadata,
+				IsEmbedded: d.IsEmbedded,
+			},
+			Embedding: d.Embedding,
+		}
+	}
+
+	result, err := b.db.NewInsert().
+		Model(&documents).
+		ModelTableExpr("?", bun.Ident(tableName)).
+		On("CONFLICT DO NOTHING").
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(n), nil
+}
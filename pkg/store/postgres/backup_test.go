@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/testutils"
+)
+
+func TestBackupRestore(t *testing.T) {
+	sessionID := testutils.GenerateRandomString(16)
+	messages, err := putMessages(testCtx, testDB, sessionID, []models.Message{
+		{Role: "user", Content: "Hello", Metadata: map[string]interface{}{"foo": "bar"}},
+		{Role: "assistant", Content: "Hi there"},
+	})
+	require.NoError(t, err)
+	_, err = putSummary(testCtx, testDB, sessionID, &models.Summary{
+		Content:          "A greeting",
+		SummaryPointUUID: messages[1].UUID,
+	})
+	require.NoError(t, err)
+
+	var archive bytes.Buffer
+	stats, err := Backup(testCtx, testDB, &archive)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, stats.Sessions, 1)
+	assert.GreaterOrEqual(t, stats.Messages, 2)
+	assert.GreaterOrEqual(t, stats.Summaries, 1)
+
+	// The archive is gzipped JSONL, starting with a versioned header
+	gz, err := gzip.NewReader(bytes.NewReader(archive.Bytes()))
+	require.NoError(t, err)
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(nil, maxBackupLineSize)
+	require.True(t, scanner.Scan())
+	var header backupHeader
+	require.NoError(t, json.Unmarshal(scanner.Bytes(), &header))
+	assert.Equal(t, BackupFormat, header.Format)
+	assert.Equal(t, BackupVersion, header.Version)
+
+	var sessionTypes []backupRecordType
+	for scanner.Scan() {
+		var record backupRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		var owner struct {
+			SessionID string `json:"session_id"`
+		}
+		require.NoError(t, json.Unmarshal(record.Data, &owner))
+		if owner.SessionID == sessionID {
+			sessionTypes = append(sessionTypes, record.Type)
+		}
+	}
+	require.NoError(t, scanner.Err())
+	assert.Equal(
+		t,
+		[]backupRecordType{backupSession, backupMessage, backupMessage, backupSummary},
+		sessionTypes,
+	)
+
+	// Restoring into a database with all of the rows restores nothing
+	stats, err = Restore(testCtx, appState, testDB, bytes.NewReader(archive.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, BackupStats{}, *stats)
+
+	// Restoring after the session is purged restores the session
+	require.NoError(t, NewSessionDAO(testDB).Delete(testCtx, sessionID, false))
+	require.NoError(t, purgeDeleted(testCtx, testDB))
+
+	stats, err = Restore(testCtx, appState, testDB, bytes.NewReader(archive.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, BackupStats{Sessions: 1, Messages: 2, Summaries: 1}, *stats)
+
+	messageList, err := getMessageListByCursor(testCtx, testDB, sessionID, 0, 10, nil)
+	require.NoError(t, err)
+	restored := messageList.Messages
+	require.Len(t, restored, 2)
+	assert.Equal(t, messages[0].UUID, restored[0].UUID)
+	assert.Equal(t, "Hello", restored[0].Content)
+	assert.Equal(t, "bar", restored[0].Metadata["foo"])
+	assert.Equal(t, "Hi there", restored[1].Content)
+
+	summary, err := getSummary(testCtx, testDB, sessionID)
+	require.NoError(t, err)
+	assert.Equal(t, "A greeting", summary.Content)
+}
+
+func TestRestore_UnsupportedVersion(t *testing.T) {
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	require.NoError(t, json.NewEncoder(gz).Encode(backupHeader{
+		Format:  BackupFormat,
+		Version: BackupVersion + 1,
+	}))
+	require.NoError(t, gz.Close())
+
+	_, err := Restore(testCtx, appState, testDB, &archive)
+	assert.ErrorContains(t, err, "unsupported backup version")
+
+	_, err = Restore(testCtx, appState, testDB, bytes.NewReader([]byte("not a backup")))
+	assert.Error(t, err)
+}
@@ -0,0 +1,307 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+
+	"github.com/getzep/zep/pkg/llms"
+	"github.com/getzep/zep/pkg/models"
+)
+
+// GetCollectionIndexStatus returns the state of a collection's index, including whether the
+// index is being built and whether it is present and valid in the database.
+func (ds *DocumentStore) GetCollectionIndexStatus(
+	ctx context.Context,
+	collectionName string,
+) (*models.CollectionIndexStatus, error) {
+	collection := NewDocumentCollectionDAO(
+		ds.appState,
+		ds.Client,
+		models.DocumentCollection{Name: collectionName},
+	)
+
+	err := collection.GetByName(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection: %w", err)
+	}
+
+	status := &models.CollectionIndexStatus{
+		CollectionName:           collection.Name,
+		IndexType:                collection.IndexType,
+		IsIndexed:                collection.IsIndexed,
+		IsBuilding:               isIndexBuilding(collection.Name),
+		DocumentCollectionCounts: collection.DocumentCollectionCounts,
+	}
+
+	indexName := hnswIndexName(collection.TableName)
+	if collection.IndexType == "ivfflat" {
+		indexName = ivfflatIndexName(collection.TableName)
+		status.MinDocumentCount = MinRowsForIndex
+		status.ListCount = collection.ListCount
+		status.ProbeCount = collection.ProbeCount
+	}
+
+	status.IndexExists, status.IndexValid, err = getIndexState(ctx, ds.Client, indexName)
+	if err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
+// ReindexCollection rebuilds a collection's index in the background. ivfflat indexes are
+// rebuilt with list and probe counts for the collection's current size, and hnsw indexes
+// with the configured parameters.
+//
+// If the collection's documents are re-embedded, their embeddings are reset and sent to the
+// document embedder, and the collection's ivfflat index is dropped. It is recreated once
+// enough documents have been embedded again, as stale centroids would degrade search.
+func (ds *DocumentStore) ReindexCollection(
+	ctx context.Context,
+	collectionName string,
+	request *models.ReindexCollectionRequest,
+) error {
+	collection := NewDocumentCollectionDAO(
+		ds.appState,
+		ds.Client,
+		models.DocumentCollection{Name: collectionName},
+	)
+
+	err := collection.GetByName(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get collection: %w", err)
+	}
+
+	if request.Reembed || request.EmbeddingService != "" || request.EmbeddingModelName != "" {
+		return ds.reembedCollection(ctx, &collection.DocumentCollection, request)
+	}
+
+	switch collection.IndexType {
+	case "ivfflat":
+		return ds.createIVFFLATIndex(ctx, &collection.DocumentCollection, true)
+	case "hnsw":
+		return ds.rebuildCollectionHNSWIndex(&collection.DocumentCollection)
+	default:
+		return fmt.Errorf("unknown index type %s", collection.IndexType)
+	}
+}
+
+// createIVFFLATIndex creates a collection's ivfflat index. If force is false, the index is
+// only created if the collection has enough embedded documents.
+func (ds *DocumentStore) createIVFFLATIndex(
+	ctx context.Context,
+	collection *models.DocumentCollection,
+	force bool,
+) error {
+	if !ds.appState.Config.Store.Postgres.AvailableIndexes.IVFFLAT {
+		return models.NewBadRequestError("ivfflat indexes are not available in this database")
+	}
+
+	if collection.DocumentCollectionCounts != nil && collection.DocumentEmbeddedCount == 0 {
+		return models.NewBadRequestError(
+			"collection " + collection.Name + " has no embedded documents to index",
+		)
+	}
+
+	vci, err := NewVectorColIndex(ctx, ds.appState, *collection)
+	if err != nil {
+		return fmt.Errorf("failed to create vector column index: %w", err)
+	}
+
+	err = vci.CreateIndex(ctx, force)
+	if err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+
+	return nil
+}
+
+// rebuildCollectionHNSWIndex rebuilds a collection's hnsw index in the background. A valid
+// index remains in use until its replacement is built.
+func (ds *DocumentStore) rebuildCollectionHNSWIndex(collection *models.DocumentCollection) error {
+	if !ds.appState.Config.Store.Postgres.AvailableIndexes.HSNW {
+		return models.NewBadRequestError("hnsw indexes are not available in this database")
+	}
+
+	mu, err := lockIndex(collection.Name)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		defer mu.Unlock()
+		ctx, cancel := context.WithTimeout(context.Background(), IndexTimeout)
+		defer cancel()
+
+		err := ds.rebuildHNSWIndex(ctx, collection.TableName)
+		if err != nil {
+			log.Errorf("error rebuilding hnsw index on %s: %s", collection.Name, err)
+			return
+		}
+
+		log.Infof("Index rebuild on %s completed successfully", collection.Name)
+	}()
+
+	return nil
+}
+
+func (ds *DocumentStore) rebuildHNSWIndex(ctx context.Context, tableName string) error {
+	m, efConstruction := hnswIndexParams(&ds.appState.Config.Store.Postgres.HNSW)
+	idx := hnswIndexName(tableName)
+
+	concurrently, err := indexConcurrently(ctx, ds.Client, tableName)
+	if err != nil {
+		return err
+	}
+
+	exists, valid, err := getIndexState(ctx, ds.Client, idx)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return execCreateHNSWIndex(
+			ctx,
+			ds.Client,
+			idx,
+			tableName,
+			EmbeddingColName,
+			m,
+			efConstruction,
+			concurrently,
+		)
+	}
+
+	return rebuildHNSWIndex(
+		ctx,
+		ds.Client,
+		idx,
+		tableName,
+		EmbeddingColName,
+		m,
+		efConstruction,
+		valid,
+		concurrently,
+	)
+}
+
+// reembedCollection resets the embeddings of an auto-embedded collection's documents and
+// sends them to the document embedder, after replacing the collection's embedding model if
+// one is requested.
+func (ds *DocumentStore) reembedCollection(
+	ctx context.Context,
+	collection *models.DocumentCollection,
+	request *models.ReindexCollectionRequest,
+) error {
+	if !collection.IsAutoEmbedded {
+		return models.NewBadRequestError(
+			"only the documents of auto-embedded collections can be re-embedded",
+		)
+	}
+
+	if request.EmbeddingService != "" || request.EmbeddingModelName != "" {
+		collection.EmbeddingService = request.EmbeddingService
+		collection.EmbeddingModelName = request.EmbeddingModelName
+		if err := llms.SetCollectionEmbeddingModel(ds.appState, collection); err != nil {
+			return err
+		}
+	}
+
+	// the index can't be dropped while it's being built
+	mu, err := lockIndex(collection.Name)
+	if err != nil {
+		return err
+	}
+	defer mu.Unlock()
+
+	var uuids []uuid.UUID
+	err = ds.Client.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewUpdate().
+			TableExpr("document_collection").
+			Set("embedding_service = ?", collection.EmbeddingService).
+			Set("embedding_model_name = ?", collection.EmbeddingModelName).
+			Set("is_indexed = ?", collection.IndexType != "ivfflat").
+			Set("updated_at = ?", time.Now()).
+			Where("table_name = ?", collection.TableName).
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to update collection: %w", err)
+		}
+
+		// existing embeddings are kept, so that the collection remains searchable until
+		// its documents are re-embedded
+		_, err = tx.NewUpdate().
+			TableExpr("?", bun.Ident(collection.TableName)).
+			Set("is_embedded = ?", false).
+			Where("deleted_at IS NULL").
+			Returning("uuid").
+			Exec(ctx, &uuids)
+		if err != nil {
+			return fmt.Errorf("failed to reset document embeddings: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if collection.IndexType == "ivfflat" {
+		_, err = ds.Client.ExecContext(
+			ctx,
+			"DROP INDEX CONCURRENTLY IF EXISTS ?",
+			bun.Ident(ivfflatIndexName(collection.TableName)),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to drop index: %w", err)
+		}
+	}
+
+	documents := make([]models.Document, len(uuids))
+	for i := range uuids {
+		documents[i].UUID = uuids[i]
+	}
+	ds.documentEmbeddingTasker(ctx, collection.Name, documents)
+
+	log.Infof("re-embedding %d documents in collection %s", len(documents), collection.Name)
+
+	return nil
+}
+
+// createCollectionIndexIfReady creates a collection's ivfflat index once enough of its
+// documents have been embedded. Errors are logged, as documents are stored regardless.
+func (ds *DocumentStore) createCollectionIndexIfReady(ctx context.Context, collectionName string) {
+	if !ds.appState.Config.Store.Postgres.AvailableIndexes.IVFFLAT ||
+		isIndexBuilding(collectionName) {
+		return
+	}
+
+	collection := NewDocumentCollectionDAO(
+		ds.appState,
+		ds.Client,
+		models.DocumentCollection{Name: collectionName},
+	)
+	err := collection.GetByName(ctx)
+	if err != nil {
+		log.Errorf("failed to get collection %s: %s", collectionName, err)
+		return
+	}
+
+	if collection.IndexType != "ivfflat" || collection.IsIndexed ||
+		collection.DocumentEmbeddedCount < MinRowsForIndex {
+		return
+	}
+
+	log.Infof(
+		"collection %s has %d embedded documents, creating index",
+		collectionName,
+		collection.DocumentEmbeddedCount,
+	)
+	err = ds.createIVFFLATIndex(ctx, &collection.DocumentCollection, false)
+	if err != nil {
+		log.Errorf("failed to create index on collection %s: %s", collectionName, err)
+	}
+}
@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/testutils"
+)
+
+func TestLockIndex(t *testing.T) {
+	collectionName := testutils.GenerateRandomString(16)
+	assert.False(t, isIndexBuilding(collectionName))
+
+	mu, err := lockIndex(collectionName)
+	require.NoError(t, err)
+	assert.True(t, isIndexBuilding(collectionName))
+
+	_, err = lockIndex(collectionName)
+	assert.ErrorIs(t, err, models.ErrBadRequest)
+
+	mu.Unlock()
+	assert.False(t, isIndexBuilding(collectionName))
+}
+
+func TestCollectionIndexLifecycle(t *testing.T) {
+	ctx, done := context.WithCancel(testCtx)
+	defer done()
+
+	collectionName := testutils.GenerateRandomString(16)
+	_, err := newDocumentCollectionWithDocs(ctx, collectionName, 100, false, true, 384)
+	require.NoError(t, err)
+
+	documentStore, err := NewDocumentStore(ctx, appState, testDB)
+	require.NoError(t, err)
+	appState.DocumentStore = documentStore
+
+	status, err := documentStore.GetCollectionIndexStatus(ctx, collectionName)
+	require.NoError(t, err)
+	assert.Equal(t, collectionName, status.CollectionName)
+	assert.Equal(t, 100, status.DocumentCount)
+	assert.Equal(t, 100, status.DocumentEmbeddedCount)
+	assert.False(t, status.IsBuilding)
+
+	err = documentStore.ReindexCollection(ctx, collectionName, &models.ReindexCollectionRequest{})
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		status, err = documentStore.GetCollectionIndexStatus(ctx, collectionName)
+		return err == nil && !status.IsBuilding
+	}, 5*time.Minute, 500*time.Millisecond)
+	assert.True(t, status.IsIndexed)
+	assert.True(t, status.IndexExists)
+	assert.True(t, status.IndexValid)
+
+	// only auto-embedded collections can be re-embedded
+	err = documentStore.ReindexCollection(
+		ctx,
+		collectionName,
+		&models.ReindexCollectionRequest{Reembed: true},
+	)
+	assert.ErrorIs(t, err, models.ErrBadRequest)
+}
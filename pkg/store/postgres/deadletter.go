@@ -0,0 +1,181 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/store"
+)
+
+// DefaultDeadLetterListLimit is the number of dead letters listed if no limit is given
+const DefaultDeadLetterListLimit = 100
+
+type DeadLetterSchema struct {
+	bun.BaseModel `bun:"table:dead_letter,alias:dl" yaml:"-"`
+
+	UUID        uuid.UUID         `bun:",pk,type:uuid,default:gen_random_uuid()"`
+	ID          int64             `bun:",autoincrement"`
+	CreatedAt   time.Time         `bun:"type:timestamptz,notnull,default:current_timestamp"`
+	MessageUUID string            `bun:",notnull"`
+	Topic       string            `bun:",notnull"`
+	Handler     string            `bun:",notnull"`
+	Metadata    map[string]string `bun:"type:jsonb,nullzero"`
+	Payload     json.RawMessage   `bun:"type:jsonb,nullzero"`
+	Error       string            `bun:",notnull"`
+}
+
+func (*DeadLetterSchema) AfterCreateTable(
+	ctx context.Context,
+	query *bun.CreateTableQuery,
+) error {
+	_, err := query.DB().NewCreateIndex().
+		Model((*DeadLetterSchema)(nil)).
+		Index("dead_letter_topic_idx").
+		Column("topic").
+		IfNotExists().
+		Exec(ctx)
+	return err
+}
+
+var _ models.DeadLetterStore = &DeadLetterStoreDAO{}
+
+type DeadLetterStoreDAO struct {
+	db *bun.DB
+}
+
+func NewDeadLetterStoreDAO(db *bun.DB) *DeadLetterStoreDAO {
+	return &DeadLetterStoreDAO{
+		db: db,
+	}
+}
+
+func (dao *DeadLetterStoreDAO) PutDeadLetter(
+	ctx context.Context,
+	deadLetter *models.DeadLetter,
+) error {
+	deadLetterDB := &DeadLetterSchema{
+		MessageUUID: deadLetter.MessageUUID,
+		Topic:       string(deadLetter.Topic),
+		Handler:     deadLetter.Handler,
+		Metadata:    deadLetter.Metadata,
+		Payload:     deadLetter.Payload,
+		Error:       deadLetter.Error,
+	}
+	// payloads that aren't JSON are stored as JSON strings
+	if len(deadLetterDB.Payload) > 0 && !json.Valid(deadLetterDB.Payload) {
+		payload, err := json.Marshal(string(deadLetterDB.Payload))
+		if err != nil {
+			return err
+		}
+		deadLetterDB.Payload = payload
+	}
+
+	_, err := dao.db.NewInsert().Model(deadLetterDB).Returning("*").Exec(ctx)
+	if err != nil {
+		return store.NewStorageError("failed to put dead letter", err)
+	}
+	*deadLetter = *deadLetterSchemaToDeadLetter(deadLetterDB)
+
+	return nil
+}
+
+func (dao *DeadLetterStoreDAO) ListDeadLetters(
+	ctx context.Context,
+	topic models.TaskTopic,
+	cursor int64,
+	limit int,
+) (*models.DeadLetterListResponse, error) {
+	if limit <= 0 {
+		limit = DefaultDeadLetterListLimit
+	}
+
+	var deadLettersDB []DeadLetterSchema
+	query := dao.db.NewSelect().
+		Model(&deadLettersDB).
+		Where("id > ?", cursor).
+		OrderExpr("id ASC").
+		// select one more than the limit to determine whether there's a next page
+		Limit(limit + 1)
+	if topic != "" {
+		query = query.Where("topic = ?", topic)
+	}
+	if err := query.Scan(ctx); err != nil {
+		return nil, store.NewStorageError("failed to list dead letters", err)
+	}
+
+	response := &models.DeadLetterListResponse{DeadLetters: []models.DeadLetter{}}
+	if len(deadLettersDB) > limit {
+		deadLettersDB = deadLettersDB[:limit]
+		response.NextCursor = deadLettersDB[limit-1].ID
+	}
+	for i := range deadLettersDB {
+		response.DeadLetters = append(
+			response.DeadLetters,
+			*deadLetterSchemaToDeadLetter(&deadLettersDB[i]),
+		)
+	}
+
+	return response, nil
+}
+
+func (dao *DeadLetterStoreDAO) GetDeadLetter(
+	ctx context.Context,
+	deadLetterUUID uuid.UUID,
+) (*models.DeadLetter, error) {
+	deadLetterDB := &DeadLetterSchema{}
+	err := dao.db.NewSelect().
+		Model(deadLetterDB).
+		Where("uuid = ?", deadLetterUUID).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.NewNotFoundError("dead letter " + deadLetterUUID.String())
+		}
+		return nil, store.NewStorageError("failed to get dead letter", err)
+	}
+
+	return deadLetterSchemaToDeadLetter(deadLetterDB), nil
+}
+
+func (dao *DeadLetterStoreDAO) DeleteDeadLetter(
+	ctx context.Context,
+	deadLetterUUID uuid.UUID,
+) error {
+	r, err := dao.db.NewDelete().
+		Model((*DeadLetterSchema)(nil)).
+		Where("uuid = ?", deadLetterUUID).
+		Exec(ctx)
+	if err != nil {
+		return store.NewStorageError("failed to delete dead letter", err)
+	}
+	rowsAffected, err := r.RowsAffected()
+	if err != nil {
+		return store.NewStorageError("failed to delete dead letter", err)
+	}
+	if rowsAffected == 0 {
+		return models.NewNotFoundError("dead letter " + deadLetterUUID.String())
+	}
+
+	return nil
+}
+
+func deadLetterSchemaToDeadLetter(deadLetterDB *DeadLetterSchema) *models.DeadLetter {
+	return &models.DeadLetter{
+		UUID:        deadLetterDB.UUID,
+		ID:          deadLetterDB.ID,
+		CreatedAt:   deadLetterDB.CreatedAt,
+		MessageUUID: deadLetterDB.MessageUUID,
+		Topic:       models.TaskTopic(deadLetterDB.Topic),
+		Handler:     deadLetterDB.Handler,
+		Metadata:    deadLetterDB.Metadata,
+		Payload:     deadLetterDB.Payload,
+		Error:       deadLetterDB.Error,
+	}
+}
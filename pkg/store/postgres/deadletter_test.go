@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/testutils"
+)
+
+func TestDeadLetterStoreDAO(t *testing.T) {
+	ctx := context.Background()
+	deadLetterStore := NewDeadLetterStoreDAO(testDB)
+
+	// a topic unique to this test, so that listing isn't affected by other dead letters
+	topic := models.TaskTopic(testutils.GenerateRandomString(16))
+
+	var deadLetters []*models.DeadLetter
+	for i := 0; i < 3; i++ {
+		deadLetter := &models.DeadLetter{
+			MessageUUID: uuid.NewString(),
+			Topic:       topic,
+			Handler:     string(topic),
+			Metadata:    map[string]string{"session_id": "session"},
+			Payload:     json.RawMessage(`[{"uuid":"1"}]`),
+			Error:       "extractor failed",
+		}
+		require.NoError(t, deadLetterStore.PutDeadLetter(ctx, deadLetter))
+		assert.NotEqual(t, uuid.Nil, deadLetter.UUID)
+		assert.NotZero(t, deadLetter.ID)
+		assert.False(t, deadLetter.CreatedAt.IsZero())
+		deadLetters = append(deadLetters, deadLetter)
+	}
+
+	t.Run("Get", func(t *testing.T) {
+		deadLetter, err := deadLetterStore.GetDeadLetter(ctx, deadLetters[0].UUID)
+		require.NoError(t, err)
+		assert.Equal(t, deadLetters[0].MessageUUID, deadLetter.MessageUUID)
+		assert.Equal(t, topic, deadLetter.Topic)
+		assert.Equal(t, map[string]string{"session_id": "session"}, deadLetter.Metadata)
+		assert.JSONEq(t, `[{"uuid":"1"}]`, string(deadLetter.Payload))
+		assert.Equal(t, "extractor failed", deadLetter.Error)
+	})
+
+	t.Run("Put non-JSON payload", func(t *testing.T) {
+		deadLetter := &models.DeadLetter{
+			MessageUUID: uuid.NewString(),
+			Topic:       models.TaskTopic(testutils.GenerateRandomString(16)),
+			Payload:     json.RawMessage("not json"),
+		}
+		require.NoError(t, deadLetterStore.PutDeadLetter(ctx, deadLetter))
+		assert.JSONEq(t, `"not json"`, string(deadLetter.Payload))
+	})
+
+	t.Run("List with cursor", func(t *testing.T) {
+		page, err := deadLetterStore.ListDeadLetters(ctx, topic, 0, 2)
+		require.NoError(t, err)
+		require.Len(t, page.DeadLetters, 2)
+		assert.Equal(t, deadLetters[0].UUID, page.DeadLetters[0].UUID)
+		assert.Equal(t, deadLetters[1].UUID, page.DeadLetters[1].UUID)
+		assert.Equal(t, deadLetters[1].ID, page.NextCursor)
+
+		page, err = deadLetterStore.ListDeadLetters(ctx, topic, page.NextCursor, 2)
+		require.NoError(t, err)
+		require.Len(t, page.DeadLetters, 1)
+		assert.Equal(t, deadLetters[2].UUID, page.DeadLetters[0].UUID)
+		assert.Zero(t, page.NextCursor)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		require.NoError(t, deadLetterStore.DeleteDeadLetter(ctx, deadLetters[0].UUID))
+
+		_, err := deadLetterStore.GetDeadLetter(ctx, deadLetters[0].UUID)
+		assert.ErrorIs(t, err, models.ErrNotFound)
+
+		page, err := deadLetterStore.ListDeadLetters(ctx, topic, 0, 0)
+		require.NoError(t, err)
+		assert.Len(t, page.DeadLetters, 2)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		_, err := deadLetterStore.GetDeadLetter(ctx, uuid.New())
+		assert.ErrorIs(t, err, models.ErrNotFound)
+
+		err = deadLetterStore.DeleteDeadLetter(ctx, uuid.New())
+		assert.ErrorIs(t, err, models.ErrNotFound)
+	})
+}
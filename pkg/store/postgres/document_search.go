@@ -16,7 +16,12 @@ import (
 	"github.com/getzep/zep/pkg/models"
 )
 
-const DefaultEFSearch = 100
+const (
+	DefaultEFSearch           = 100
+	DefaultHNSWM              = 16
+	DefaultHNSWEFConstruction = 64
+)
+
 const DefaultDocumentSearchLimit = 20
 const MaxParallelWorkersPerGather = 4
 
@@ -72,7 +77,10 @@ func (dso *documentSearchOperation) Execute() (*models.DocumentSearchResultPage,
 			}
 		case "hnsw":
 			if dso.collection.IsIndexed {
-				_, err = tx.Exec("SET LOCAL hnsw.ef_search = ?", DefaultEFSearch)
+				_, err = tx.Exec(
+					"SET LOCAL hnsw.ef_search = ?",
+					hnswEFSearch(&dso.appState.Config.Store.Postgres.HNSW),
+				)
 			} else {
 				_, err = tx.Exec("SET LOCAL max_parallel_workers_per_gather = ?", MaxParallelWorkersPerGather)
 			}
@@ -221,17 +229,17 @@ func (dso *documentSearchOperation) getDocQueryVector(
 	queryText string,
 ) (pgvector.Vector, error) {
 	documentType := "document"
-	model, err := llms.GetEmbeddingModel(dso.appState, documentType)
+	model, err := llms.GetCollectionEmbeddingModel(dso.appState, dso.collection)
 	if err != nil {
 		return pgvector.Vector{}, fmt.Errorf("failed to get document embedding model %w", err)
 	}
 
-	e, err := llms.EmbedTexts(dso.ctx, dso.appState, model, documentType, []string{queryText})
+	e, err := llms.EmbedQuery(dso.ctx, dso.appState, model, documentType, queryText)
 	if err != nil {
 		return pgvector.Vector{}, fmt.Errorf("failed to embed query %w", err)
 	}
 
-	v := pgvector.NewVector(e[0])
+	v := pgvector.NewVector(e)
 	return v, nil
 }
 
@@ -253,6 +261,9 @@ func (dso *documentSearchOperation) applyDocsMetadataFilter(
 		if err != nil {
 			return nil, fmt.Errorf("error unmarshalling metadata %w", err)
 		}
+		if err := jq.validate(); err != nil {
+			return nil, err
+		}
 		qb = parseJSONQuery(qb, &jq, false, "")
 	}
 
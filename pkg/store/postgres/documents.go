@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/getzep/zep/pkg/chunker"
+	"github.com/getzep/zep/pkg/llms"
 	"github.com/getzep/zep/pkg/store"
 
 	"github.com/getzep/zep/pkg/models"
@@ -29,7 +31,8 @@ type DocumentCollectionDAO struct {
 }
 
 // Create inserts a collection into the collections table and creates a
-// table for the collection's documents.
+// table for the collection's documents. The collection is created in the
+// project ctx is scoped to.
 func (dc *DocumentCollectionDAO) Create(
 	ctx context.Context,
 ) error {
@@ -61,7 +64,30 @@ func (dc *DocumentCollectionDAO) Create(
 	// We only support cosine distance function for now.
 	dc.DistanceFunction = "cosine"
 
-	collectionRecord := DocumentCollectionSchema{DocumentCollection: dc.DocumentCollection}
+	if dc.IsAutoEmbedded {
+		err := llms.SetCollectionEmbeddingModel(dc.appState, &dc.DocumentCollection)
+		if err != nil {
+			return err
+		}
+	}
+
+	// documents are chunked before they're embedded, so only auto-embedded collections chunk
+	if dc.Chunking != nil {
+		if !dc.IsAutoEmbedded {
+			return models.NewBadRequestError(
+				"chunking is only supported by auto-embedded collections",
+			)
+		}
+		if err := chunker.Validate(dc.Chunking); err != nil {
+			return models.NewBadRequestError(err.Error())
+		}
+	}
+
+	projectID, _ := models.ProjectIDFromContext(ctx)
+	collectionRecord := DocumentCollectionSchema{
+		DocumentCollection: dc.DocumentCollection,
+		ProjectID:          projectID,
+	}
 
 	_, err := dc.db.NewInsert().
 		Model(&collectionRecord).
@@ -97,6 +123,7 @@ func (dc *DocumentCollectionDAO) Update(
 	r, err := dc.db.NewUpdate().
 		Model(&collectionRecord).
 		Where("name = ?", dc.getName()).
+		ApplyQueryBuilder(whereProject(ctx)).
 		OmitZero().
 		Returning("*").
 		Exec(ctx)
@@ -128,6 +155,7 @@ func (dc *DocumentCollectionDAO) GetByName(
 	err := dc.db.NewSelect().
 		Model(&collectionRecord).
 		Where("name = ?", dc.getName()).
+		ApplyQueryBuilder(whereProject(ctx)).
 		Scan(ctx)
 	if err != nil {
 		if strings.Contains(err.Error(), "no rows in result set") {
@@ -156,12 +184,20 @@ func (dc *DocumentCollectionDAO) GetByName(
 func (dc *DocumentCollectionDAO) GetAll(
 	ctx context.Context,
 ) ([]models.DocumentCollection, error) {
-	var collections []models.DocumentCollection
-	err := dc.db.NewSelect().Model(&collections).ModelTableExpr("document_collection").Scan(ctx)
+	var collectionRecords []DocumentCollectionSchema
+	err := dc.db.NewSelect().
+		Model(&collectionRecords).
+		ApplyQueryBuilder(whereProject(ctx)).
+		Scan(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get collection list: %w", err)
 	}
 
+	collections := make([]models.DocumentCollection, len(collectionRecords))
+	for i := range collectionRecords {
+		collections[i] = collectionRecords[i].DocumentCollection
+	}
+
 	for i := range collections {
 		c := NewDocumentCollectionDAO(dc.appState, dc.db, collections[i])
 		err = c.GetByName(ctx)
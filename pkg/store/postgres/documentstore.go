@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/getzep/zep/pkg/chunker"
 	"github.com/getzep/zep/pkg/store"
 
 	"github.com/google/uuid"
@@ -191,6 +192,13 @@ func (ds *DocumentStore) CreateDocuments(
 		)
 	}
 
+	if collection.IsAutoEmbedded && collection.Chunking != nil {
+		documents, err = chunker.ChunkDocuments(documents, collection.Chunking)
+		if err != nil {
+			return nil, models.NewBadRequestError(err.Error())
+		}
+	}
+
 	uuids, err := collection.CreateDocuments(ctx, documents)
 	if err != nil {
 		return nil, fmt.Errorf("failed to Create documents: %w", err)
@@ -199,7 +207,9 @@ func (ds *DocumentStore) CreateDocuments(
 	// if the collection is configured to auto-embed, send the documents
 	// to the document embedding tasker
 	if collection.IsAutoEmbedded {
-		ds.documentEmbeddingTasker(collectionName, documents)
+		ds.documentEmbeddingTasker(ctx, collectionName, documents)
+	} else {
+		ds.createCollectionIndexIfReady(ctx, collectionName)
 	}
 
 	return uuids, nil
@@ -223,6 +233,11 @@ func (ds *DocumentStore) UpdateDocuments(
 		return fmt.Errorf("failed to Update documents: %w", err)
 	}
 
+	// the index is created once the collection's documents are embedded
+	if len(documents) > 0 && len(documents[0].Embedding) > 0 {
+		ds.createCollectionIndexIfReady(ctx, collectionName)
+	}
+
 	return nil
 }
 
@@ -315,21 +330,11 @@ func (ds *DocumentStore) CreateCollectionIndex(
 		return nil
 	}
 
-	vci, err := NewVectorColIndex(ctx, ds.appState, collection.DocumentCollection)
-	if err != nil {
-		return fmt.Errorf("failed to create vector column index: %w", err)
-	}
-
-	// use the default MinRows value
-	err = vci.CreateIndex(ctx, force)
-	if err != nil {
-		return fmt.Errorf("failed to create index: %w", err)
-	}
-
-	return nil
+	return ds.createIVFFLATIndex(ctx, &collection.DocumentCollection, force)
 }
 
 func (ds *DocumentStore) documentEmbeddingTasker(
+	ctx context.Context,
 	collectionName string,
 	documents []models.Document,
 ) {
@@ -350,6 +355,7 @@ func (ds *DocumentStore) documentEmbeddingTasker(
 
 	for _, taskChunk := range taskChunks {
 		err := ds.appState.TaskPublisher.Publish(
+			ctx,
 			"document_embedder",
 			map[string]string{
 				"collection_name": collectionName,
@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/uptrace/bun"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/store"
+)
+
+// EmbeddingCacheSchema is a cached embedding, keyed by the hash of its text and embedding model
+type EmbeddingCacheSchema struct {
+	bun.BaseModel `bun:"table:embedding_cache,alias:ec" yaml:"-"`
+
+	Hash      string    `bun:",pk"`
+	CreatedAt time.Time `bun:"type:timestamptz,notnull,default:current_timestamp"`
+	Embedding []float32 `bun:"type:real[],array,notnull"`
+}
+
+func (*EmbeddingCacheSchema) AfterCreateTable(
+	ctx context.Context,
+	query *bun.CreateTableQuery,
+) error {
+	_, err := query.DB().NewCreateIndex().
+		Model((*EmbeddingCacheSchema)(nil)).
+		Index("embedding_cache_created_at_idx").
+		Column("created_at").
+		IfNotExists().
+		Exec(ctx)
+	return err
+}
+
+var _ models.EmbeddingCache = &EmbeddingCacheDAO{}
+
+type EmbeddingCacheDAO struct {
+	db *bun.DB
+}
+
+func NewEmbeddingCacheDAO(db *bun.DB) *EmbeddingCacheDAO {
+	return &EmbeddingCacheDAO{
+		db: db,
+	}
+}
+
+func (dao *EmbeddingCacheDAO) GetEmbeddings(
+	ctx context.Context,
+	keys []string,
+) (map[string][]float32, error) {
+	embeddings := make(map[string][]float32, len(keys))
+	if len(keys) == 0 {
+		return embeddings, nil
+	}
+
+	var cachedDB []EmbeddingCacheSchema
+	err := dao.db.NewSelect().
+		Model(&cachedDB).
+		Where("hash IN (?)", bun.In(keys)).
+		Scan(ctx)
+	if err != nil {
+		return nil, store.NewStorageError("failed to get cached embeddings", err)
+	}
+	for _, cached := range cachedDB {
+		embeddings[cached.Hash] = cached.Embedding
+	}
+
+	return embeddings, nil
+}
+
+func (dao *EmbeddingCacheDAO) PutEmbeddings(
+	ctx context.Context,
+	embeddings map[string][]float32,
+) error {
+	if len(embeddings) == 0 {
+		return nil
+	}
+
+	// keys are inserted in order, so that concurrent inserts of the same keys don't deadlock
+	keys := make([]string, 0, len(embeddings))
+	for key := range embeddings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	cachedDB := make([]EmbeddingCacheSchema, len(keys))
+	for i, key := range keys {
+		cachedDB[i] = EmbeddingCacheSchema{Hash: key, Embedding: embeddings[key]}
+	}
+
+	// identical texts embedded concurrently are cached once
+	_, err := dao.db.NewInsert().
+		Model(&cachedDB).
+		On("CONFLICT (hash) DO NOTHING").
+		Returning("NULL").
+		Exec(ctx)
+	if err != nil {
+		return store.NewStorageError("failed to put cached embeddings", err)
+	}
+
+	return nil
+}
+
+func (dao *EmbeddingCacheDAO) PurgeEmbeddings(ctx context.Context, before time.Time) (int64, error) {
+	r, err := dao.db.NewDelete().
+		Model((*EmbeddingCacheSchema)(nil)).
+		Where("created_at < ?", before).
+		Exec(ctx)
+	if err != nil {
+		return 0, store.NewStorageError("failed to purge cached embeddings", err)
+	}
+	purged, err := r.RowsAffected()
+	if err != nil {
+		return 0, store.NewStorageError("failed to purge cached embeddings", err)
+	}
+
+	return purged, nil
+}
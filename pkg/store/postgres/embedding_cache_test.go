@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/pkg/testutils"
+)
+
+func TestEmbeddingCacheDAO(t *testing.T) {
+	dao := NewEmbeddingCacheDAO(testDB)
+	key := testutils.GenerateRandomString(16)
+	embedding := []float32{0.1, 0.2, 0.3}
+
+	err := dao.PutEmbeddings(testCtx, map[string][]float32{key: embedding})
+	require.NoError(t, err)
+	// caching a key again doesn't fail
+	err = dao.PutEmbeddings(testCtx, map[string][]float32{key: {0.4, 0.5, 0.6}})
+	require.NoError(t, err)
+
+	cached, err := dao.GetEmbeddings(testCtx, []string{key, "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]float32{key: embedding}, cached)
+
+	purged, err := dao.PurgeEmbeddings(testCtx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, purged)
+
+	purged, err = dao.PurgeEmbeddings(testCtx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, purged, int64(1))
+
+	cached, err = dao.GetEmbeddings(testCtx, []string{key})
+	require.NoError(t, err)
+	assert.Empty(t, cached)
+}
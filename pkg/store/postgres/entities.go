@@ -0,0 +1,360 @@
+package postgres
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/store"
+)
+
+// DefaultEntityListLimit is the number of entities or entity mentions listed if no limit is
+// given
+const DefaultEntityListLimit = 50
+
+// EntitySchema stores an entity extracted from messages. Entities are shared by sessions, and
+// identified by their normalized name and label.
+type EntitySchema struct {
+	bun.BaseModel `bun:"table:entity,alias:e" yaml:"-"`
+
+	UUID      uuid.UUID `bun:",pk,type:uuid,default:gen_random_uuid()"`
+	CreatedAt time.Time `bun:"type:timestamptz,notnull,default:current_timestamp"`
+	// Name is the entity's name as it was first mentioned
+	Name           string `bun:",notnull"`
+	NormalizedName string `bun:",notnull"`
+	Label          string `bun:",notnull"`
+}
+
+var _ bun.AfterCreateTableHook = (*EntitySchema)(nil)
+
+func (*EntitySchema) AfterCreateTable(
+	ctx context.Context,
+	query *bun.CreateTableQuery,
+) error {
+	// entities are upserted on this index
+	_, err := query.DB().NewCreateIndex().
+		Model((*EntitySchema)(nil)).
+		Index("entity_normalized_name_label_idx").
+		Unique().
+		Column("normalized_name", "label").
+		IfNotExists().
+		Exec(ctx)
+	return err
+}
+
+// EntityMentionSchema links an entity to a message that mentions it. Mentions are deleted
+// along with their session, and those of deleted messages are ignored.
+type EntityMentionSchema struct {
+	bun.BaseModel `bun:"table:entity_mention,alias:em" yaml:"-"`
+
+	UUID        uuid.UUID      `bun:",pk,type:uuid,default:gen_random_uuid()"`
+	CreatedAt   time.Time      `bun:"type:timestamptz,notnull,default:current_timestamp"`
+	EntityUUID  uuid.UUID      `bun:"type:uuid,notnull"`
+	SessionID   string         `bun:",notnull"`
+	MessageUUID uuid.UUID      `bun:"type:uuid,notnull"`
+	Entity      *EntitySchema  `bun:"rel:belongs-to,join:entity_uuid=uuid,on_delete:cascade"`
+	Session     *SessionSchema `bun:"rel:belongs-to,join:session_id=session_id,on_delete:cascade"`
+}
+
+var _ bun.AfterCreateTableHook = (*EntityMentionSchema)(nil)
+
+func (*EntityMentionSchema) AfterCreateTable(
+	ctx context.Context,
+	query *bun.CreateTableQuery,
+) error {
+	_, err := query.DB().NewCreateIndex().
+		Model((*EntityMentionSchema)(nil)).
+		Index("entity_mention_entity_uuid_message_uuid_idx").
+		Unique().
+		Column("entity_uuid", "message_uuid").
+		IfNotExists().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = query.DB().NewCreateIndex().
+		Model((*EntityMentionSchema)(nil)).
+		Index("entity_mention_message_uuid_idx").
+		Column("message_uuid").
+		IfNotExists().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = query.DB().NewCreateIndex().
+		Model((*EntityMentionSchema)(nil)).
+		Index("entity_mention_session_id_idx").
+		Column("session_id").
+		IfNotExists().
+		Exec(ctx)
+	return err
+}
+
+// PutEntityMentions stores the entities mentioned by a session's messages. The mentions
+// previously stored for the messages are replaced, so that the entities of messages whose
+// content is updated stay current.
+func (pms *PostgresMemoryStore) PutEntityMentions(
+	ctx context.Context,
+	sessionID string,
+	entities map[uuid.UUID][]models.Entity,
+) error {
+	if len(entities) == 0 {
+		return nil
+	}
+
+	messageUUIDs := make([]uuid.UUID, 0, len(entities))
+	for messageUUID := range entities {
+		messageUUIDs = append(messageUUIDs, messageUUID)
+	}
+
+	entitiesDB := uniqueEntities(entities)
+
+	err := pms.Client.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if len(entitiesDB) > 0 {
+			// the no-op update returns the UUIDs of existing entities
+			_, err := tx.NewInsert().
+				Model(&entitiesDB).
+				On("CONFLICT (normalized_name, label) DO UPDATE").
+				Set("normalized_name = EXCLUDED.normalized_name").
+				Returning("uuid, normalized_name, label").
+				Exec(ctx)
+			if err != nil {
+				return store.NewStorageError("failed to put entities", err)
+			}
+		}
+
+		_, err := tx.NewDelete().
+			Model((*EntityMentionSchema)(nil)).
+			Where("message_uuid IN (?)", bun.In(messageUUIDs)).
+			Exec(ctx)
+		if err != nil {
+			return store.NewStorageError("failed to delete entity mentions", err)
+		}
+
+		mentionsDB := entityMentions(sessionID, entities, entitiesDB)
+		if len(mentionsDB) == 0 {
+			return nil
+		}
+		_, err = tx.NewInsert().
+			Model(&mentionsDB).
+			Returning("NULL").
+			Exec(ctx)
+		if err != nil {
+			return store.NewStorageError("failed to put entity mentions", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetUserEntities returns the entities mentioned in the undeleted messages of a user's
+// undeleted sessions, most mentioned first.
+func (pms *PostgresMemoryStore) GetUserEntities(
+	ctx context.Context,
+	userID string,
+	label string,
+	limit int,
+) (*models.UserEntityListResponse, error) {
+	if _, err := NewUserStoreDAO(pms.Client).Get(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = DefaultEntityListLimit
+	}
+
+	entities := make([]models.UserEntity, 0)
+	query := pms.Client.NewSelect().
+		TableExpr("entity_mention AS em").
+		ColumnExpr("e.name, e.label").
+		ColumnExpr("count(*) AS mention_count").
+		ColumnExpr("max(m.created_at) AS last_mentioned_at").
+		Join("JOIN entity AS e ON e.uuid = em.entity_uuid").
+		Join("JOIN message AS m ON m.uuid = em.message_uuid AND m.session_id = em.session_id").
+		Join("JOIN session AS s ON s.session_id = em.session_id").
+		Where("s.user_id = ?", userID).
+		Where("s.deleted_at IS NULL").
+		Where("m.deleted_at IS NULL").
+		GroupExpr("e.uuid, e.name, e.label").
+		OrderExpr("mention_count DESC, last_mentioned_at DESC").
+		Limit(limit)
+	if label != "" {
+		query = query.Where("e.label = ?", label)
+	}
+	if err := query.Scan(ctx, &entities); err != nil {
+		return nil, store.NewStorageError("failed to get user entities", err)
+	}
+
+	return &models.UserEntityListResponse{Entities: entities, RowCount: len(entities)}, nil
+}
+
+// ListEntityMentions returns the undeleted messages of a user's undeleted sessions that
+// mention an entity, paginated by message ID.
+func (pms *PostgresMemoryStore) ListEntityMentions(
+	ctx context.Context,
+	userID string,
+	name string,
+	label string,
+	cursor int64,
+	limit int,
+) (*models.EntityMentionListResponse, error) {
+	normalizedName := normalizeEntityName(name)
+	if normalizedName == "" {
+		return nil, models.NewBadRequestError("entity name cannot be empty")
+	}
+
+	if _, err := NewUserStoreDAO(pms.Client).Get(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = DefaultEntityListLimit
+	}
+
+	mentioning := pms.Client.NewSelect().
+		TableExpr("entity_mention AS em").
+		Column("em.message_uuid").
+		Join("JOIN entity AS e ON e.uuid = em.entity_uuid").
+		Where("e.normalized_name = ?", normalizedName)
+	if label != "" {
+		mentioning = mentioning.Where("e.label = ?", label)
+	}
+
+	var messagesDB []MessageStoreSchema
+	query := pms.Client.NewSelect().
+		Model(&messagesDB).
+		Join("JOIN session AS s ON s.session_id = m.session_id").
+		Where("s.user_id = ?", userID).
+		Where("s.deleted_at IS NULL").
+		Where("m.uuid IN (?)", mentioning).
+		OrderExpr("m.id DESC").
+		// select one more than the limit to determine whether there's a next page
+		Limit(limit + 1)
+	if cursor > 0 {
+		query = query.Where("m.id < ?", cursor)
+	}
+	if err := query.Scan(ctx); err != nil {
+		return nil, store.NewStorageError("failed to list entity mentions", err)
+	}
+
+	response := &models.EntityMentionListResponse{Mentions: []models.EntityMention{}}
+	if len(messagesDB) > limit {
+		messagesDB = messagesDB[:limit]
+		response.NextCursor = messagesDB[limit-1].ID
+	}
+	messages := messageSchemaToMessages(messagesDB)
+	for i := range messages {
+		response.Mentions = append(response.Mentions, models.EntityMention{
+			SessionID: messagesDB[i].SessionID,
+			Message:   messages[i],
+		})
+	}
+	response.RowCount = len(response.Mentions)
+
+	return response, nil
+}
+
+// deleteUnmentionedEntities deletes the entities that are no longer mentioned in any session,
+// returning the number deleted
+func deleteUnmentionedEntities(ctx context.Context, db bun.IDB) (int, error) {
+	r, err := db.NewDelete().
+		Model((*EntitySchema)(nil)).
+		Where("NOT EXISTS (SELECT 1 FROM entity_mention AS em WHERE em.entity_uuid = e.uuid)").
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := r.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(deleted), nil
+}
+
+// uniqueEntities returns the distinct entities mentioned by messages, by normalized name and
+// label, ordered so that concurrent upserts of the same entities don't deadlock. Entities
+// without a name are dropped.
+func uniqueEntities(entities map[uuid.UUID][]models.Entity) []EntitySchema {
+	byKey := make(map[string]EntitySchema)
+	for _, messageEntities := range entities {
+		for _, entity := range messageEntities {
+			e := EntitySchema{
+				Name:           strings.TrimSpace(entity.Name),
+				NormalizedName: normalizeEntityName(entity.Name),
+				Label:          entity.Label,
+			}
+			if e.NormalizedName == "" {
+				continue
+			}
+			key := entityKey(e.NormalizedName, e.Label)
+			if _, ok := byKey[key]; !ok {
+				byKey[key] = e
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(byKey))
+	for key := range byKey {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	unique := make([]EntitySchema, len(keys))
+	for i, key := range keys {
+		unique[i] = byKey[key]
+	}
+	return unique
+}
+
+// entityMentions returns a mention of each distinct entity of each message. entitiesDB are
+// the stored entities, whose UUIDs are set.
+func entityMentions(
+	sessionID string,
+	entities map[uuid.UUID][]models.Entity,
+	entitiesDB []EntitySchema,
+) []EntityMentionSchema {
+	entityUUIDs := make(map[string]uuid.UUID, len(entitiesDB))
+	for _, e := range entitiesDB {
+		entityUUIDs[entityKey(e.NormalizedName, e.Label)] = e.UUID
+	}
+
+	var mentions []EntityMentionSchema
+	for messageUUID, messageEntities := range entities {
+		seen := make(map[uuid.UUID]bool)
+		for _, entity := range messageEntities {
+			entityUUID, ok := entityUUIDs[entityKey(normalizeEntityName(entity.Name), entity.Label)]
+			if !ok || seen[entityUUID] {
+				continue
+			}
+			seen[entityUUID] = true
+			mentions = append(mentions, EntityMentionSchema{
+				EntityUUID:  entityUUID,
+				SessionID:   sessionID,
+				MessageUUID: messageUUID,
+			})
+		}
+	}
+	return mentions
+}
+
+// normalizeEntityName lowercases an entity's name and collapses its whitespace, so that
+// mentions of an entity that differ only in case or spacing are linked to the same entity
+func normalizeEntityName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+func entityKey(normalizedName, label string) string {
+	return normalizedName + "\x00" + label
+}
@@ -0,0 +1,148 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/testutils"
+)
+
+func TestNormalizeEntityName(t *testing.T) {
+	assert.Equal(t, "new york", normalizeEntityName("  New\tYork "))
+	assert.Equal(t, "", normalizeEntityName(" "))
+}
+
+func TestUniqueEntities(t *testing.T) {
+	entities := uniqueEntities(map[uuid.UUID][]models.Entity{
+		uuid.New(): {
+			{Name: "Berlin", Label: "GPE"},
+			{Name: "Ada", Label: "PERSON"},
+		},
+		uuid.New(): {
+			{Name: "berlin ", Label: "GPE"},
+			{Name: "Berlin", Label: "ORG"},
+			{Name: " ", Label: "PERSON"},
+		},
+	})
+
+	require.Len(t, entities, 3)
+	assert.Equal(t, "ada", entities[0].NormalizedName)
+	assert.Equal(t, "berlin", entities[1].NormalizedName)
+	assert.Equal(t, "GPE", entities[1].Label)
+	assert.Equal(t, "berlin", entities[2].NormalizedName)
+	assert.Equal(t, "ORG", entities[2].Label)
+}
+
+func TestEntityMentions(t *testing.T) {
+	messageUUID := uuid.New()
+	entitiesDB := []EntitySchema{
+		{UUID: uuid.New(), NormalizedName: "berlin", Label: "GPE"},
+	}
+
+	mentions := entityMentions(
+		"session",
+		map[uuid.UUID][]models.Entity{
+			messageUUID: {{Name: "Berlin", Label: "GPE"}, {Name: "BERLIN", Label: "GPE"}},
+			uuid.New():  {},
+		},
+		entitiesDB,
+	)
+
+	assert.Equal(t, []EntityMentionSchema{
+		{EntityUUID: entitiesDB[0].UUID, SessionID: "session", MessageUUID: messageUUID},
+	}, mentions)
+}
+
+func TestEntityGraph(t *testing.T) {
+	userID := testutils.GenerateRandomString(16)
+	_, err := NewUserStoreDAO(testDB).Create(testCtx, &models.CreateUserRequest{UserID: userID})
+	require.NoError(t, err)
+
+	sessionIDs := make([]string, 2)
+	messages := make([][]models.Message, 2)
+	for i := range sessionIDs {
+		sessionIDs[i] = testutils.GenerateRandomString(16)
+		_, err := NewSessionDAO(testDB).Create(testCtx, &models.CreateSessionRequest{
+			SessionID: sessionIDs[i],
+			UserID:    &userID,
+		})
+		require.NoError(t, err)
+		messages[i], err = putMessages(testCtx, testDB, sessionIDs[i], []models.Message{
+			{Role: "user", Content: "I'm flying to Berlin with Ada"},
+			{Role: "assistant", Content: "Enjoy your trip"},
+		})
+		require.NoError(t, err)
+	}
+
+	for i := range sessionIDs {
+		err := appState.MemoryStore.PutEntityMentions(testCtx, sessionIDs[i], map[uuid.UUID][]models.Entity{
+			messages[i][0].UUID: {{Name: "Berlin", Label: "GPE"}, {Name: "Ada", Label: "PERSON"}},
+			messages[i][1].UUID: {},
+		})
+		require.NoError(t, err)
+	}
+
+	entities, err := appState.MemoryStore.GetUserEntities(testCtx, userID, "", 0)
+	require.NoError(t, err)
+	require.Equal(t, 2, entities.RowCount)
+	assert.Equal(t, 2, entities.Entities[0].MentionCount)
+
+	entities, err = appState.MemoryStore.GetUserEntities(testCtx, userID, "PERSON", 0)
+	require.NoError(t, err)
+	require.Len(t, entities.Entities, 1)
+	assert.Equal(t, "Ada", entities.Entities[0].Name)
+
+	mentions, err := appState.MemoryStore.ListEntityMentions(testCtx, userID, "berlin", "", 0, 1)
+	require.NoError(t, err)
+	require.Len(t, mentions.Mentions, 1)
+	assert.Equal(t, sessionIDs[1], mentions.Mentions[0].SessionID)
+	assert.Equal(t, messages[1][0].UUID, mentions.Mentions[0].Message.UUID)
+	assert.NotZero(t, mentions.NextCursor)
+
+	mentions, err = appState.MemoryStore.ListEntityMentions(
+		testCtx,
+		userID,
+		"berlin",
+		"",
+		mentions.NextCursor,
+		1,
+	)
+	require.NoError(t, err)
+	require.Len(t, mentions.Mentions, 1)
+	assert.Equal(t, sessionIDs[0], mentions.Mentions[0].SessionID)
+	assert.Zero(t, mentions.NextCursor)
+
+	t.Run("replaces the mentions of updated messages", func(t *testing.T) {
+		err := appState.MemoryStore.PutEntityMentions(testCtx, sessionIDs[0], map[uuid.UUID][]models.Entity{
+			messages[0][0].UUID: {{Name: "Paris", Label: "GPE"}},
+		})
+		require.NoError(t, err)
+
+		mentions, err := appState.MemoryStore.ListEntityMentions(testCtx, userID, "Berlin", "GPE", 0, 0)
+		require.NoError(t, err)
+		require.Len(t, mentions.Mentions, 1)
+		assert.Equal(t, sessionIDs[1], mentions.Mentions[0].SessionID)
+	})
+
+	t.Run("excludes deleted sessions", func(t *testing.T) {
+		require.NoError(t, appState.MemoryStore.DeleteSession(testCtx, sessionIDs[1]))
+
+		mentions, err := appState.MemoryStore.ListEntityMentions(testCtx, userID, "Berlin", "", 0, 0)
+		require.NoError(t, err)
+		assert.Empty(t, mentions.Mentions)
+	})
+
+	t.Run("empty name returns bad request", func(t *testing.T) {
+		_, err := appState.MemoryStore.ListEntityMentions(testCtx, userID, " ", "", 0, 0)
+		assert.ErrorIs(t, err, models.ErrBadRequest)
+	})
+
+	t.Run("non-existent user returns not found", func(t *testing.T) {
+		_, err := appState.MemoryStore.GetUserEntities(testCtx, "nonexistent", "", 0)
+		assert.ErrorIs(t, err, models.ErrNotFound)
+	})
+}
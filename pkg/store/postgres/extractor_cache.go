@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/uptrace/bun"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/store"
+)
+
+// ExtractorResultCacheSchema is a cached extractor result, keyed by the hash of its extractor
+// and input
+type ExtractorResultCacheSchema struct {
+	bun.BaseModel `bun:"table:extractor_result_cache,alias:erc" yaml:"-"`
+
+	Hash      string          `bun:",pk"`
+	Extractor string          `bun:",notnull"`
+	CreatedAt time.Time       `bun:"type:timestamptz,notnull,default:current_timestamp"`
+	Result    json.RawMessage `bun:"type:jsonb,notnull"`
+}
+
+func (*ExtractorResultCacheSchema) AfterCreateTable(
+	ctx context.Context,
+	query *bun.CreateTableQuery,
+) error {
+	_, err := query.DB().NewCreateIndex().
+		Model((*ExtractorResultCacheSchema)(nil)).
+		Index("extractor_result_cache_created_at_idx").
+		Column("created_at").
+		IfNotExists().
+		Exec(ctx)
+	return err
+}
+
+var _ models.ExtractorResultCache = &ExtractorResultCacheDAO{}
+
+type ExtractorResultCacheDAO struct {
+	db *bun.DB
+}
+
+func NewExtractorResultCacheDAO(db *bun.DB) *ExtractorResultCacheDAO {
+	return &ExtractorResultCacheDAO{
+		db: db,
+	}
+}
+
+func (dao *ExtractorResultCacheDAO) GetResults(
+	ctx context.Context,
+	keys []string,
+) (map[string]json.RawMessage, error) {
+	results := make(map[string]json.RawMessage, len(keys))
+	if len(keys) == 0 {
+		return results, nil
+	}
+
+	var cachedDB []ExtractorResultCacheSchema
+	err := dao.db.NewSelect().
+		Model(&cachedDB).
+		Where("hash IN (?)", bun.In(keys)).
+		Scan(ctx)
+	if err != nil {
+		return nil, store.NewStorageError("failed to get cached extractor results", err)
+	}
+	for _, cached := range cachedDB {
+		results[cached.Hash] = cached.Result
+	}
+
+	return results, nil
+}
+
+func (dao *ExtractorResultCacheDAO) PutResults(
+	ctx context.Context,
+	extractor string,
+	results map[string]json.RawMessage,
+) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	// keys are inserted in order, so that concurrent inserts of the same keys don't deadlock
+	keys := make([]string, 0, len(results))
+	for key := range results {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	cachedDB := make([]ExtractorResultCacheSchema, len(keys))
+	for i, key := range keys {
+		cachedDB[i] = ExtractorResultCacheSchema{
+			Hash:      key,
+			Extractor: extractor,
+			Result:    results[key],
+		}
+	}
+
+	_, err := dao.db.NewInsert().
+		Model(&cachedDB).
+		On("CONFLICT (hash) DO NOTHING").
+		Returning("NULL").
+		Exec(ctx)
+	if err != nil {
+		return store.NewStorageError("failed to put cached extractor results", err)
+	}
+
+	return nil
+}
+
+func (dao *ExtractorResultCacheDAO) PurgeResults(
+	ctx context.Context,
+	before time.Time,
+) (int64, error) {
+	r, err := dao.db.NewDelete().
+		Model((*ExtractorResultCacheSchema)(nil)).
+		Where("created_at < ?", before).
+		Exec(ctx)
+	if err != nil {
+		return 0, store.NewStorageError("failed to purge cached extractor results", err)
+	}
+	purged, err := r.RowsAffected()
+	if err != nil {
+		return 0, store.NewStorageError("failed to purge cached extractor results", err)
+	}
+
+	return purged, nil
+}
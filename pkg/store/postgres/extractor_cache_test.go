@@ -0,0 +1,38 @@
+package postgres
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/pkg/testutils"
+)
+
+func TestExtractorResultCacheDAO(t *testing.T) {
+	dao := NewExtractorResultCacheDAO(testDB)
+	key := testutils.GenerateRandomString(16)
+	result := json.RawMessage(`"greeting"`)
+
+	err := dao.PutResults(testCtx, "intent", map[string]json.RawMessage{key: result})
+	require.NoError(t, err)
+	// caching a key again doesn't fail
+	err = dao.PutResults(testCtx, "intent", map[string]json.RawMessage{key: json.RawMessage(`"other"`)})
+	require.NoError(t, err)
+
+	cached, err := dao.GetResults(testCtx, []string{key, "missing"})
+	require.NoError(t, err)
+	require.Contains(t, cached, key)
+	assert.JSONEq(t, string(result), string(cached[key]))
+	assert.NotContains(t, cached, "missing")
+
+	purged, err := dao.PurgeResults(testCtx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, purged, int64(1))
+
+	cached, err = dao.GetResults(testCtx, []string{key})
+	require.NoError(t, err)
+	assert.Empty(t, cached)
+}
@@ -0,0 +1,180 @@
+package postgres
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/store"
+)
+
+// FactSchema stores a fact about a user. A user has one fact for a subject and predicate,
+// compared without case.
+type FactSchema struct {
+	bun.BaseModel `bun:"table:fact,alias:f" yaml:"-"`
+
+	UUID        uuid.UUID   `bun:",pk,type:uuid,default:gen_random_uuid()"`
+	CreatedAt   time.Time   `bun:"type:timestamptz,notnull,default:current_timestamp"`
+	UpdatedAt   time.Time   `bun:"type:timestamptz,notnull,default:current_timestamp"`
+	UserID      string      `bun:",notnull"`
+	Subject     string      `bun:",notnull"`
+	Predicate   string      `bun:",notnull"`
+	Object      string      `bun:",notnull"`
+	SessionID   string      `bun:",nullzero"`
+	MessageUUID uuid.UUID   `bun:"type:uuid,nullzero"`
+	User        *UserSchema `bun:"rel:belongs-to,join:user_id=user_id,on_delete:cascade"`
+}
+
+var _ bun.AfterCreateTableHook = (*FactSchema)(nil)
+
+func (*FactSchema) AfterCreateTable(
+	ctx context.Context,
+	query *bun.CreateTableQuery,
+) error {
+	// facts are upserted on this index
+	_, err := query.DB().NewCreateIndex().
+		Model((*FactSchema)(nil)).
+		Index("fact_user_id_subject_predicate_idx").
+		Unique().
+		Column("user_id").
+		ColumnExpr("lower(subject)").
+		ColumnExpr("lower(predicate)").
+		IfNotExists().
+		Exec(ctx)
+	return err
+}
+
+// PutFacts upserts facts about a user. The object of an existing fact with the same subject
+// and predicate is replaced, along with the message it was extracted from.
+func (pms *PostgresMemoryStore) PutFacts(
+	ctx context.Context,
+	userID string,
+	facts []models.Fact,
+) error {
+	facts = dedupeFacts(facts)
+	if len(facts) == 0 {
+		return nil
+	}
+
+	factsDB := make([]FactSchema, len(facts))
+	for i := range facts {
+		factsDB[i] = FactSchema{
+			UserID:    userID,
+			Subject:   facts[i].Subject,
+			Predicate: facts[i].Predicate,
+			Object:    facts[i].Object,
+			SessionID: facts[i].SessionID,
+		}
+		if facts[i].MessageUUID != nil {
+			factsDB[i].MessageUUID = *facts[i].MessageUUID
+		}
+	}
+
+	_, err := pms.Client.NewInsert().
+		Model(&factsDB).
+		On("CONFLICT (user_id, lower(subject), lower(predicate)) DO UPDATE").
+		Set("subject = EXCLUDED.subject").
+		Set("predicate = EXCLUDED.predicate").
+		Set("object = EXCLUDED.object").
+		Set("session_id = EXCLUDED.session_id").
+		Set("message_uuid = EXCLUDED.message_uuid").
+		Set("updated_at = current_timestamp").
+		Returning("NULL").
+		Exec(ctx)
+	if err != nil {
+		return store.NewStorageError("failed to put facts", err)
+	}
+
+	return nil
+}
+
+// GetFacts returns up to limit of a user's facts, most recently updated first, or all of
+// them if limit is 0.
+func (pms *PostgresMemoryStore) GetFacts(
+	ctx context.Context,
+	userID string,
+	limit int,
+) ([]models.Fact, error) {
+	if _, err := NewUserStoreDAO(pms.Client).Get(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return getFacts(ctx, pms.Client, userID, limit)
+}
+
+func getFacts(
+	ctx context.Context,
+	db bun.IDB,
+	userID string,
+	limit int,
+) ([]models.Fact, error) {
+	var factsDB []FactSchema
+	query := db.NewSelect().
+		Model(&factsDB).
+		Where("user_id = ?", userID).
+		Order("updated_at DESC", "uuid")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Scan(ctx); err != nil {
+		return nil, store.NewStorageError("failed to get facts", err)
+	}
+
+	facts := make([]models.Fact, len(factsDB))
+	for i := range factsDB {
+		facts[i] = models.Fact{
+			UUID:      factsDB[i].UUID,
+			CreatedAt: factsDB[i].CreatedAt,
+			UpdatedAt: factsDB[i].UpdatedAt,
+			Subject:   factsDB[i].Subject,
+			Predicate: factsDB[i].Predicate,
+			Object:    factsDB[i].Object,
+			SessionID: factsDB[i].SessionID,
+		}
+		if factsDB[i].MessageUUID != uuid.Nil {
+			messageUUID := factsDB[i].MessageUUID
+			facts[i].MessageUUID = &messageUUID
+		}
+	}
+
+	return facts, nil
+}
+
+// dedupeFacts drops facts without a subject, predicate or object, and keeps the last of the
+// facts with the same subject and predicate, as a row can't be upserted twice by one insert.
+// Facts are returned ordered by subject and predicate, so that concurrent upserts of the same
+// facts don't deadlock.
+func dedupeFacts(facts []models.Fact) []models.Fact {
+	byKey := make(map[string]models.Fact, len(facts))
+	for _, f := range facts {
+		f.Subject = strings.TrimSpace(f.Subject)
+		f.Predicate = strings.TrimSpace(f.Predicate)
+		f.Object = strings.TrimSpace(f.Object)
+		if f.Subject == "" || f.Predicate == "" || f.Object == "" {
+			continue
+		}
+		byKey[factKey(&f)] = f
+	}
+
+	keys := make([]string, 0, len(byKey))
+	for key := range byKey {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	deduped := make([]models.Fact, len(keys))
+	for i, key := range keys {
+		deduped[i] = byKey[key]
+	}
+	return deduped
+}
+
+// factKey identifies a user's fact by its subject and predicate, without case
+func factKey(f *models.Fact) string {
+	return strings.ToLower(f.Subject) + "\x00" + strings.ToLower(f.Predicate)
+}
@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/testutils"
+)
+
+func TestDedupeFacts(t *testing.T) {
+	facts := dedupeFacts([]models.Fact{
+		{Subject: "user", Predicate: "lives in", Object: "Munich"},
+		{Subject: "user", Predicate: "works as", Object: "nurse"},
+		{Subject: "User", Predicate: "Lives In ", Object: "Berlin"},
+		{Subject: "user", Predicate: "likes", Object: " "},
+	})
+
+	assert.Equal(t, []models.Fact{
+		{Subject: "User", Predicate: "Lives In", Object: "Berlin"},
+		{Subject: "user", Predicate: "works as", Object: "nurse"},
+	}, facts)
+}
+
+func TestFacts(t *testing.T) {
+	userID := testutils.GenerateRandomString(16)
+	_, err := NewUserStoreDAO(testDB).Create(testCtx, &models.CreateUserRequest{UserID: userID})
+	require.NoError(t, err)
+
+	messageUUID := uuid.New()
+	err = appState.MemoryStore.PutFacts(testCtx, userID, []models.Fact{
+		{Subject: "user", Predicate: "lives in", Object: "Munich"},
+		{Subject: "user", Predicate: "has children", Object: "two"},
+	})
+	require.NoError(t, err)
+
+	// a fact with the same subject and predicate replaces the existing fact's object
+	err = appState.MemoryStore.PutFacts(testCtx, userID, []models.Fact{
+		{
+			Subject:     "User",
+			Predicate:   "Lives in",
+			Object:      "Berlin",
+			SessionID:   "session",
+			MessageUUID: &messageUUID,
+		},
+	})
+	require.NoError(t, err)
+
+	facts, err := appState.MemoryStore.GetFacts(testCtx, userID, 0)
+	require.NoError(t, err)
+	require.Len(t, facts, 2)
+	assert.Equal(t, "Lives in", facts[0].Predicate)
+	assert.Equal(t, "Berlin", facts[0].Object)
+	assert.Equal(t, "session", facts[0].SessionID)
+	assert.Equal(t, &messageUUID, facts[0].MessageUUID)
+	assert.Equal(t, "two", facts[1].Object)
+	assert.Nil(t, facts[1].MessageUUID)
+
+	facts, err = appState.MemoryStore.GetFacts(testCtx, userID, 1)
+	require.NoError(t, err)
+	assert.Len(t, facts, 1)
+
+	t.Run("memory includes the user's facts", func(t *testing.T) {
+		sessionID := testutils.GenerateRandomString(16)
+		_, err := NewSessionDAO(testDB).Create(testCtx, &models.CreateSessionRequest{
+			SessionID: sessionID,
+			UserID:    &userID,
+		})
+		require.NoError(t, err)
+
+		appState.Config.Extractors.Messages.Facts.Enabled = true
+		defer func() { appState.Config.Extractors.Messages.Facts.Enabled = false }()
+
+		memory, err := appState.MemoryStore.GetMemory(testCtx, appState, sessionID, 0, nil)
+		require.NoError(t, err)
+		require.Len(t, memory.Facts, 2)
+		assert.Equal(t, "Berlin", memory.Facts[0].Object)
+	})
+
+	t.Run("non-existent user returns not found", func(t *testing.T) {
+		_, err := appState.MemoryStore.GetFacts(testCtx, "nonexistent", 0)
+		assert.ErrorIs(t, err, models.ErrNotFound)
+	})
+}
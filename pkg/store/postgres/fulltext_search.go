@@ -0,0 +1,199 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/search"
+	"github.com/getzep/zep/pkg/store"
+	"github.com/google/uuid"
+	"github.com/pgvector/pgvector-go"
+	"github.com/uptrace/bun"
+)
+
+// validateTextSearchLanguage checks that language is a text search configuration known to
+// Postgres. to_tsvector and the regconfig language columns only accept known configurations.
+func validateTextSearchLanguage(ctx context.Context, db bun.IDB, language string) error {
+	var exists bool
+	err := db.NewRaw(
+		"SELECT EXISTS(SELECT 1 FROM pg_ts_config WHERE cfgname = ?)",
+		language,
+	).Scan(ctx, &exists)
+	if err != nil {
+		return fmt.Errorf("failed to check text search language: %w", err)
+	}
+	if !exists {
+		return models.NewBadRequestError("unsupported language: " + language)
+	}
+
+	return nil
+}
+
+// searchMessages performs a full-text search over a session's messages using the session's
+// text search language, returning at most limit messages ordered by rank.
+func searchMessages(
+	ctx context.Context,
+	db *bun.DB,
+	sessionID string,
+	query string,
+	limit int,
+) ([]models.Message, error) {
+	if sessionID == "" {
+		return nil, store.NewStorageError("sessionID cannot be empty", nil)
+	}
+	if query == "" {
+		return nil, models.NewBadRequestError("query cannot be empty")
+	}
+
+	language, err := getSessionLanguage(ctx, db, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []MessageStoreSchema
+	err = db.NewSelect().
+		Model(&messages).
+		Where("session_id = ?", sessionID).
+		Where("search_vector @@ plainto_tsquery(?::regconfig, ?)", language, query).
+		OrderExpr("ts_rank(search_vector, plainto_tsquery(?::regconfig, ?)) DESC", language, query).
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, store.NewStorageError("failed to search messages", err)
+	}
+
+	results := make([]models.Message, len(messages))
+	for i, msg := range messages {
+		results[i] = models.Message{
+			UUID:       msg.UUID,
+			CreatedAt:  msg.CreatedAt,
+			UpdatedAt:  msg.UpdatedAt,
+			Role:       msg.Role,
+			Content:    msg.Content,
+			Metadata:   msg.Metadata,
+			TokenCount: msg.TokenCount,
+			IsSystem:   msg.IsSystem,
+		}
+	}
+
+	return results, nil
+}
+
+// searchMessagesHybrid searches a session's messages by both full-text relevance and vector
+// similarity to the query text. Each search ranks up to DefaultHybridMultiplier times limit
+// candidates, and the rankings are combined using Reciprocal Rank Fusion. The Dist of each
+// result is its fused score.
+func searchMessagesHybrid(
+	ctx context.Context,
+	appState *models.AppState,
+	db *bun.DB,
+	sessionID string,
+	query *models.MemorySearchPayload,
+	limit int,
+) ([]models.MemorySearchResult, error) {
+	if query.SearchScope != models.SearchScopeMessages && query.SearchScope != "" {
+		return nil, models.NewBadRequestError("hybrid search is only supported for messages")
+	}
+	if query.Text == "" {
+		return nil, models.NewBadRequestError("hybrid search requires query text")
+	}
+
+	if limit == 0 {
+		limit = DefaultMemorySearchLimit
+	}
+	candidateLimit := limit * DefaultHybridMultiplier
+
+	language, err := getSessionLanguage(ctx, db, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	queryEmbedding, err := embedMemoryQuery(ctx, appState, "message", query.Text)
+	if err != nil {
+		return nil, store.NewStorageError("error embedding query", err)
+	}
+	vector := pgvector.NewVector(queryEmbedding)
+
+	newQuery := func() (*bun.SelectQuery, error) {
+		dbQuery := buildMessageSearchQuery(ctx, db, query).
+			ColumnExpr("(embedding <#> ?) * -1 AS dist", vector).
+			Where("m.session_id = ?", sessionID).
+			Where("m.deleted_at IS NULL")
+		dbQuery = applyMemoryDateFilter(dbQuery, query, "m")
+		if len(query.Metadata) > 0 {
+			return applyMemoryMetadataFilter(dbQuery, query.Metadata, "m")
+		}
+		return dbQuery, nil
+	}
+
+	vectorQuery, err := newQuery()
+	if err != nil {
+		return nil, store.NewStorageError("error applying metadata filter", err)
+	}
+	vectorQuery = vectorQuery.Order("dist DESC").Limit(candidateLimit)
+	vectorResults, err := executeMemoryVectorSearchScan(ctx, appState, db, vectorQuery)
+	if err != nil {
+		return nil, store.NewStorageError("memory vector search failed", err)
+	}
+	vectorResults = filterValidMessageSearchResults(vectorResults, query.Metadata)
+
+	textQuery, err := newQuery()
+	if err != nil {
+		return nil, store.NewStorageError("error applying metadata filter", err)
+	}
+	textQuery = textQuery.
+		Where("m.search_vector @@ plainto_tsquery(?::regconfig, ?)", language, query.Text).
+		OrderExpr(
+			"ts_rank(m.search_vector, plainto_tsquery(?::regconfig, ?)) DESC",
+			language,
+			query.Text,
+		).
+		Limit(candidateLimit)
+	textResults, err := executeMessagesSearchScan(ctx, textQuery)
+	if err != nil {
+		return nil, store.NewStorageError("memory full-text search failed", err)
+	}
+
+	resultMap := make(map[uuid.UUID]models.MemorySearchResult)
+	rankings := make([][]uuid.UUID, 2)
+	for i, results := range [][]models.MemorySearchResult{vectorResults, textResults} {
+		for _, r := range results {
+			resultMap[r.Message.UUID] = r
+			rankings[i] = append(rankings[i], r.Message.UUID)
+		}
+	}
+
+	fused := search.ReciprocalRankFusion(search.DefaultRRFConstant, rankings...)
+	if len(fused) > limit {
+		fused = fused[:limit]
+	}
+
+	results := make([]models.MemorySearchResult, len(fused))
+	for i, f := range fused {
+		results[i] = resultMap[f.Item]
+		results[i].Dist = f.Score
+	}
+
+	return results, nil
+}
+
+// getSessionLanguage returns the text search language of a session
+func getSessionLanguage(ctx context.Context, db bun.IDB, sessionID string) (string, error) {
+	var language string
+	err := db.NewSelect().
+		Model((*SessionSchema)(nil)).
+		Column("language").
+		Where("session_id = ?", sessionID).
+		Scan(ctx, &language)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", models.NewNotFoundError("session " + sessionID)
+		}
+		return "", store.NewStorageError("failed to get session", err)
+	}
+
+	return language, nil
+}
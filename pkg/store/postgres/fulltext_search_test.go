@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchMessages(t *testing.T) {
+	sessionID, err := testutils.GenerateRandomSessionID(16)
+	require.NoError(t, err)
+
+	sessionStore := NewSessionDAO(testDB)
+	session, err := sessionStore.Create(testCtx, &models.CreateSessionRequest{
+		SessionID: sessionID,
+		Language:  "french",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "french", session.Language)
+
+	messages := []models.Message{
+		{Role: "user", Content: "Les chevaux courent dans les champs"},
+		{Role: "assistant", Content: "Le chat dort sur le canapé"},
+	}
+	_, err = putMessages(testCtx, testDB, sessionID, messages)
+	require.NoError(t, err)
+
+	var languages []string
+	err = testDB.NewSelect().
+		Model((*MessageStoreSchema)(nil)).
+		Column("language").
+		Where("session_id = ?", sessionID).
+		Scan(testCtx, &languages)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"french", "french"}, languages)
+
+	t.Run("matches stemmed terms using the session language", func(t *testing.T) {
+		results, err := searchMessages(testCtx, testDB, sessionID, "champ", 10)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, messages[0].Content, results[0].Content)
+	})
+
+	t.Run("non-existent session returns not found", func(t *testing.T) {
+		_, err := searchMessages(testCtx, testDB, "nonexistent", "chat", 10)
+		assert.ErrorIs(t, err, models.ErrNotFound)
+	})
+}
+
+func TestCreateSessionRejectsUnknownLanguage(t *testing.T) {
+	sessionID, err := testutils.GenerateRandomSessionID(16)
+	require.NoError(t, err)
+
+	_, err = NewSessionDAO(testDB).Create(testCtx, &models.CreateSessionRequest{
+		SessionID: sessionID,
+		Language:  "klingon",
+	})
+	assert.ErrorIs(t, err, models.ErrBadRequest)
+}
@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/uptrace/bun"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/store/postgres/migrations"
+)
+
+// NewHealthChecks returns the readiness checks of the database: that it's reachable with
+// all migrations applied, and that the pgvector extension is installed
+func NewHealthChecks(db *bun.DB, cfg *config.PostgresConfig) []models.HealthCheck {
+	return []models.HealthCheck{
+		{
+			Name: "postgres",
+			Check: func(ctx context.Context) error {
+				return checkPostgres(ctx, db)
+			},
+		},
+		{
+			Name: "vector_extension",
+			Check: func(ctx context.Context) error {
+				if cfg.CockroachDB {
+					// CockroachDB has a built-in VECTOR type
+					return models.ErrHealthCheckSkipped
+				}
+				return checkVectorExtension(ctx, db)
+			},
+		},
+	}
+}
+
+func checkPostgres(ctx context.Context, db *bun.DB) error {
+	if err := db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	pending, err := migrations.PendingMigrations(ctx, db)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("migrations are pending: %s", strings.Join(pending, ", "))
+	}
+
+	return nil
+}
+
+func checkVectorExtension(ctx context.Context, db *bun.DB) error {
+	var version string
+	err := db.NewRaw("SELECT extversion FROM pg_extension WHERE extname = 'vector'").
+		Scan(ctx, &version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New("pgvector extension is not installed")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check pgvector extension: %w", err)
+	}
+
+	return nil
+}
@@ -1,3 +1,11 @@
+// Package postgres stores Zep's sessions, memory, users and documents in Postgres, with
+// pgvector for embeddings.
+//
+// The SessionObserver implements the logical replication protocol it needs itself: the
+// replication handshake, CopyBoth streaming, standby status updates and the decoding of
+// pgoutput messages. It uses pgconn and pgproto3 from pgx rather than jackc/pglogrepl,
+// which can't be vendored into this module's build. The protocol code is maintained here on
+// purpose, and covered by byte-level tests of the protocol messages.
 package postgres
 
 import (
@@ -7,13 +15,17 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/getzep/zep/pkg/store"
 	"github.com/google/uuid"
 
 	"github.com/getzep/zep/internal"
 
+	"github.com/getzep/zep/pkg/llms"
 	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/pii"
+	"github.com/sirupsen/logrus"
 	"github.com/uptrace/bun"
 )
 
@@ -46,6 +58,22 @@ var _ models.MemoryStore[*bun.DB] = &PostgresMemoryStore{}
 type PostgresMemoryStore struct {
 	store.BaseMemoryStore[*bun.DB]
 	SessionStore *SessionDAO
+	// VectorIndex, if set, stores and searches message embeddings in place of pgvector.
+	// Messages, summaries and summary embeddings remain in Postgres.
+	VectorIndex models.MessageVectorIndex
+	// ArchiveStore, if set, holds the archives of inactive sessions. Archived sessions are
+	// rehydrated when they're next read or written.
+	ArchiveStore models.ObjectStore
+	// Webhooks, if set, is notified when sessions are created or deleted, and when they're
+	// summarized
+	Webhooks models.WebhookNotifier
+	// ReadClient, if set, is a read replica that memory retrieval, message listing and memory
+	// search read from
+	ReadClient *bun.DB
+	// ReadAfterWriteWindow is how long a session's reads go to Client rather than ReadClient
+	// after its messages or summaries are written
+	ReadAfterWriteWindow time.Duration
+	recentWrites         recentWrites
 }
 
 func (pms *PostgresMemoryStore) OnStart(
@@ -79,7 +107,12 @@ func (pms *PostgresMemoryStore) CreateSession(
 	_ *models.AppState,
 	session *models.CreateSessionRequest,
 ) (*models.Session, error) {
-	return pms.SessionStore.Create(ctx, session)
+	created, err := pms.SessionStore.Create(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+	pms.notifyWebhooks(ctx, models.WebhookEventSessionCreated, created.SessionID)
+	return created, nil
 }
 
 // UpdateSession creates or updates a Session for a given sessionID.
@@ -92,8 +125,64 @@ func (pms *PostgresMemoryStore) UpdateSession(
 }
 
 // DeleteSession deletes a session from the memory store. This is a soft Delete.
+// If a VectorIndex is set, the session's message embeddings are removed from the index.
 func (pms *PostgresMemoryStore) DeleteSession(ctx context.Context, sessionID string) error {
-	return pms.SessionStore.Delete(ctx, sessionID)
+	if err := pms.SessionStore.Delete(ctx, sessionID, false); err != nil {
+		return err
+	}
+	pms.notifyWebhooks(ctx, models.WebhookEventSessionDeleted, sessionID)
+	if pms.VectorIndex != nil {
+		return pms.VectorIndex.DeleteSessionEmbeddings(ctx, sessionID)
+	}
+	return nil
+}
+
+// RestoreSession undeletes a soft-deleted session. The session's embeddings are deleted from a
+// VectorIndex when it's deleted, so its messages are embedded and indexed again.
+func (pms *PostgresMemoryStore) RestoreSession(
+	ctx context.Context,
+	appState *models.AppState,
+	sessionID string,
+) (*models.Session, error) {
+	session, err := pms.SessionStore.Restore(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if pms.VectorIndex != nil {
+		model, err := llms.GetEmbeddingModel(appState, "message")
+		if err != nil {
+			return nil, store.NewStorageError("failed to get message embedding model", err)
+		}
+		_, err = reindexSession(ctx, appState, pms.Client, pms.VectorIndex, model, sessionID)
+		if err != nil {
+			return nil, store.NewStorageError("failed to reindex restored session", err)
+		}
+	}
+
+	return session, nil
+}
+
+// PurgeSession hard deletes a session, whether or not it has been soft deleted, along with
+// its archive.
+func (pms *PostgresMemoryStore) PurgeSession(ctx context.Context, sessionID string) error {
+	var archiveKeys []string
+	if pms.ArchiveStore != nil {
+		var err error
+		archiveKeys, err = sessionArchiveKeys(ctx, pms.Client, "session_id = ?", sessionID)
+		if err != nil {
+			return store.NewStorageError("failed to get session archive", err)
+		}
+	}
+	if err := pms.SessionStore.Delete(ctx, sessionID, true); err != nil {
+		return err
+	}
+	pms.notifyWebhooks(ctx, models.WebhookEventSessionDeleted, sessionID)
+	pms.deleteSessionArchives(ctx, archiveKeys)
+	if pms.VectorIndex != nil {
+		return pms.VectorIndex.DeleteSessionEmbeddings(ctx, sessionID)
+	}
+	return nil
 }
 
 // ListSessions returns a list of all Sessions.
@@ -126,42 +215,75 @@ func (pms *PostgresMemoryStore) ListSessionsOrdered(
 //   - all messages since the last SummaryPoint, if lastNMessages == 0
 //   - if no Summary (and no SummaryPoint) exists and lastNMessages == 0, returns
 //     all undeleted messages up to the configured message window
+//
+// If request.MaxTokens is set, the messages are further limited to the most recent that fit
+// within the token budget, and the message window doesn't apply.
 func (pms *PostgresMemoryStore) GetMemory(
 	ctx context.Context,
 	appState *models.AppState,
 	sessionID string,
 	lastNMessages int,
-) (*models.Memory, error) {
+	request *models.GetMessagesRequest,
+) (_ *models.Memory, err error) {
+	ctx, span := startSpan(ctx, "GetMemory", sessionID)
+	defer func() { endSpan(span, err) }()
+
 	if appState == nil {
 		return nil, store.NewStorageError("nil appState received", nil)
 	}
 
+	if err := pms.openSession(ctx, sessionID); err != nil {
+		return nil, err
+	}
+
 	if lastNMessages < 0 {
 		return nil, store.NewStorageError("cannot specify negative lastNMessages", nil)
 	}
 
+	db := pms.readClient(sessionID)
+
 	// Get the most recent summary
-	summary, err := getSummary(ctx, pms.Client, sessionID)
+	summary, err := getSummary(ctx, db, sessionID)
 	if err != nil {
 		return nil, store.NewStorageError("failed to get summary", err)
 	}
 	if summary != nil {
-		log.Debugf("Got summary for %s: %s", sessionID, summary.UUID)
+		internal.LoggerFromContext(ctx).WithFields(logrus.Fields{
+			"session_id":   sessionID,
+			"summary_uuid": summary.UUID,
+		}).Debug("GetMemory got summary")
+	}
+
+	session, err := pms.SessionStore.Get(ctx, sessionID)
+	if err != nil && !errors.Is(err, models.ErrNotFound) {
+		return nil, store.NewStorageError("failed to get session", err)
+	}
+
+	messageWindow := appState.Config.Memory.MessageWindow
+	if session != nil {
+		messageWindow, err = models.SessionMessageWindow(session, messageWindow)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	messages, err := getMessages(
 		ctx,
-		pms.Client,
+		db,
 		sessionID,
-		appState.Config.Memory.MessageWindow,
+		messageWindow,
 		summary,
 		lastNMessages,
+		request,
 	)
 	if err != nil {
 		return nil, store.NewStorageError("failed to get messages", err)
 	}
 	if messages != nil {
-		log.Debugf("Got messages for %s: %d", sessionID, len(messages))
+		internal.LoggerFromContext(ctx).WithFields(logrus.Fields{
+			"session_id": sessionID,
+			"messages":   len(messages),
+		}).Debug("GetMemory got messages")
 	}
 
 	memory := models.Memory{
@@ -169,6 +291,40 @@ func (pms *PostgresMemoryStore) GetMemory(
 		Summary:  summary,
 	}
 
+	hierarchical := appState.Config.Extractors.Messages.Summarizer.Hierarchical
+	if hierarchical.Enabled && summary != nil {
+		memory.Abstract, err = getSummaryAbstract(ctx, db, sessionID)
+		if err != nil {
+			return nil, err
+		}
+
+		windowSummaries := hierarchical.WindowSummaries
+		if windowSummaries <= 0 {
+			windowSummaries = models.DefaultWindowSummaries
+		}
+		memory.WindowSummaries, err = getRecentSummaries(
+			ctx,
+			db,
+			sessionID,
+			windowSummaries,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	facts := appState.Config.Extractors.Messages.Facts
+	if facts.Enabled && session != nil && session.UserID != nil {
+		memoryFacts := facts.MemoryFacts
+		if memoryFacts <= 0 {
+			memoryFacts = models.DefaultMemoryFacts
+		}
+		memory.Facts, err = getFacts(ctx, db, *session.UserID, memoryFacts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &memory, nil
 }
 
@@ -184,7 +340,18 @@ func (pms *PostgresMemoryStore) GetMessageList(
 		return nil, store.NewStorageError("nil appState received", nil)
 	}
 
-	messages, err := getMessageList(ctx, pms.Client, sessionID, pageNumber, pageSize)
+	if err := pms.openSession(ctx, sessionID); err != nil {
+		return nil, err
+	}
+
+	messages, err := getMessageList(
+		ctx,
+		pms.readClient(sessionID),
+		sessionID,
+		pageNumber,
+		pageSize,
+		nil,
+	)
 	if err != nil {
 		return nil, store.NewStorageError("failed to get messages", err)
 	}
@@ -192,12 +359,36 @@ func (pms *PostgresMemoryStore) GetMessageList(
 	return messages, nil
 }
 
+// ListMessages retrieves a list of messages for a given sessionID. Paginated by cursor and limit.
+func (pms *PostgresMemoryStore) ListMessages(
+	ctx context.Context,
+	appState *models.AppState,
+	sessionID string,
+	cursor int64,
+	limit int,
+	opts *models.MessageListOptions,
+) (*models.MessageListResponse, error) {
+	if appState == nil {
+		return nil, store.NewStorageError("nil appState received", nil)
+	}
+
+	if err := pms.openSession(ctx, sessionID); err != nil {
+		return nil, err
+	}
+
+	return getMessageListByCursor(ctx, pms.readClient(sessionID), sessionID, cursor, limit, opts)
+}
+
 func (pms *PostgresMemoryStore) GetMessagesByUUID(
 	ctx context.Context,
 	_ *models.AppState,
 	sessionID string,
 	uuids []uuid.UUID,
 ) ([]models.Message, error) {
+	if err := pms.openSession(ctx, sessionID); err != nil {
+		return nil, err
+	}
+
 	messages, err := getMessagesByUUID(ctx, pms.Client, sessionID, uuids)
 	if err != nil {
 		return nil, store.NewStorageError("failed to get messages", err)
@@ -206,11 +397,41 @@ func (pms *PostgresMemoryStore) GetMessagesByUUID(
 	return messages, nil
 }
 
+func (pms *PostgresMemoryStore) GetActiveSessionCount(
+	ctx context.Context,
+	tenantID string,
+) (int64, error) {
+	return GetActiveSessionCount(ctx, pms.Client, tenantID)
+}
+
+func (pms *PostgresMemoryStore) SearchMessages(
+	ctx context.Context,
+	_ *models.AppState,
+	sessionID string,
+	query string,
+	limit int,
+) ([]models.Message, error) {
+	if err := pms.openSession(ctx, sessionID); err != nil {
+		return nil, err
+	}
+
+	messages, err := searchMessages(ctx, pms.Client, sessionID, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search messages: %w", err)
+	}
+
+	return messages, nil
+}
+
 func (pms *PostgresMemoryStore) GetSummary(
 	ctx context.Context,
 	_ *models.AppState,
 	sessionID string,
 ) (*models.Summary, error) {
+	if err := pms.openSession(ctx, sessionID); err != nil {
+		return nil, err
+	}
+
 	summary, err := getSummary(ctx, pms.Client, sessionID)
 	if err != nil {
 		return nil, store.NewStorageError("failed to get summary", err)
@@ -223,6 +444,10 @@ func (pms *PostgresMemoryStore) GetSummaryByUUID(ctx context.Context,
 	appState *models.AppState,
 	sessionID string,
 	uuid uuid.UUID) (*models.Summary, error) {
+	if err := pms.openSession(ctx, sessionID); err != nil {
+		return nil, err
+	}
+
 	summary, err := getSummaryByUUID(ctx, appState, pms.Client, sessionID, uuid)
 	if err != nil {
 		return nil, store.NewStorageError("failed to get summary", err)
@@ -242,6 +467,10 @@ func (pms *PostgresMemoryStore) GetSummaryList(
 		return nil, store.NewStorageError("nil appState received", nil)
 	}
 
+	if err := pms.openSession(ctx, sessionID); err != nil {
+		return nil, err
+	}
+
 	summaries, err := getSummaryList(ctx, pms.Client, sessionID, pageNumber, pageSize)
 	if err != nil {
 		return nil, store.NewStorageError("failed to get summaries", err)
@@ -256,16 +485,78 @@ func (pms *PostgresMemoryStore) PutSummary(
 	sessionID string,
 	summary *models.Summary,
 ) error {
+	if err := checkSessionProject(ctx, pms.Client, sessionID); err != nil {
+		return err
+	}
+
 	retSummary, err := putSummary(ctx, pms.Client, sessionID, summary)
 	if err != nil {
 		return store.NewStorageError("failed to Create summary", err)
 	}
+	pms.sessionWritten(sessionID)
+
+	return pms.publishSummary(ctx, appState, sessionID, retSummary)
+}
+
+func (pms *PostgresMemoryStore) GetSummaryAbstract(
+	ctx context.Context,
+	_ *models.AppState,
+	sessionID string,
+) (*models.SummaryAbstract, error) {
+	if err := checkSessionProject(ctx, pms.Client, sessionID); err != nil {
+		return nil, err
+	}
+
+	return getSummaryAbstract(ctx, pms.Client, sessionID)
+}
+
+func (pms *PostgresMemoryStore) PutSummaryAbstract(
+	ctx context.Context,
+	_ *models.AppState,
+	sessionID string,
+	abstract *models.SummaryAbstract,
+) error {
+	if err := checkSessionProject(ctx, pms.Client, sessionID); err != nil {
+		return err
+	}
 
+	_, err := putSummaryAbstract(ctx, pms.Client, sessionID, abstract)
+	return err
+}
+
+func (pms *PostgresMemoryStore) ReplaceSummaries(
+	ctx context.Context,
+	appState *models.AppState,
+	sessionID string,
+	summary *models.Summary,
+) error {
+	if err := checkSessionProject(ctx, pms.Client, sessionID); err != nil {
+		return err
+	}
+
+	retSummary, err := replaceSummaries(ctx, pms.Client, sessionID, summary)
+	if err != nil {
+		return store.NewStorageError("failed to replace summaries", err)
+	}
+	pms.sessionWritten(sessionID)
+
+	return pms.publishSummary(ctx, appState, sessionID, retSummary)
+}
+
+// publishSummary publishes a stored summary to the summary extractors, session subscribers
+// and webhooks
+func (pms *PostgresMemoryStore) publishSummary(
+	ctx context.Context,
+	appState *models.AppState,
+	sessionID string,
+	retSummary *models.Summary,
+) error {
 	// Publish a message to the message summary embeddings topic
 	task := models.MessageSummaryTask{
 		UUID: retSummary.UUID,
 	}
-	err = appState.TaskPublisher.Publish(
+	err := appState.TaskPublisher.Publish(
+		ctx,
 		models.MessageSummaryEmbedderTopic,
 		map[string]string{
 			"session_id": sessionID,
@@ -277,6 +568,7 @@ func (pms *PostgresMemoryStore) PutSummary(
 	}
 
 	err = appState.TaskPublisher.Publish(
+		ctx,
 		models.MessageSummaryNERTopic,
 		map[string]string{
 			"session_id": sessionID,
@@ -287,9 +579,48 @@ func (pms *PostgresMemoryStore) PutSummary(
 		return fmt.Errorf("MessageSummaryTask publish failed: %w", err)
 	}
 
+	event := &models.SessionEvent{
+		Type:      models.SessionEventSummary,
+		SessionID: sessionID,
+		Summary:   retSummary,
+	}
+	if appState.SessionEvents != nil {
+		appState.SessionEvents.Publish(event)
+	}
+	// the outbox dispatcher publishes the event with the EventProducer and the webhooks
+	outboxEvent := OutboxEventSchema{SessionID: sessionID}
+	if appState.EventProducer != nil {
+		outboxEvent.SessionEvent = event
+	}
+	if pms.Webhooks != nil {
+		outboxEvent.WebhookEvent = &models.WebhookEvent{
+			Type:      models.WebhookEventSummaryCreated,
+			SessionID: sessionID,
+			Summary:   retSummary,
+		}
+	}
+	if outboxEvent.SessionEvent != nil || outboxEvent.WebhookEvent != nil {
+		pms.writeOutbox(ctx, appState, &outboxEvent)
+	}
+
 	return nil
 }
 
+// notifyWebhooks writes a session's event to the outbox, to be sent to the webhooks, if there
+// are any
+func (pms *PostgresMemoryStore) notifyWebhooks(
+	ctx context.Context,
+	eventType models.WebhookEventType,
+	sessionID string,
+) {
+	if pms.Webhooks != nil {
+		pms.writeOutbox(ctx, nil, &OutboxEventSchema{
+			SessionID:    sessionID,
+			WebhookEvent: &models.WebhookEvent{Type: eventType, SessionID: sessionID},
+		})
+	}
+}
+
 func (pms *PostgresMemoryStore) UpdateSummaryMetadata(ctx context.Context,
 	_ *models.AppState,
 	summary *models.Summary) error {
@@ -307,6 +638,10 @@ func (pms *PostgresMemoryStore) PutSummaryEmbedding(
 	sessionID string,
 	embedding *models.TextData,
 ) error {
+	if err := checkSessionProject(ctx, pms.Client, sessionID); err != nil {
+		return err
+	}
+
 	err := putSummaryEmbedding(ctx, pms.Client, sessionID, embedding)
 	if err != nil {
 		return store.NewStorageError("failed to Create summary embedding", err)
@@ -315,26 +650,222 @@ func (pms *PostgresMemoryStore) PutSummaryEmbedding(
 	return nil
 }
 
+// moderateMessages moderates messages before they're stored, returning a bad request error
+// if any message reaches the moderation reject threshold
+func moderateMessages(
+	ctx context.Context,
+	appState *models.AppState,
+	messages []models.Message,
+) ([]models.Moderation, error) {
+	texts := make([]string, len(messages))
+	for i := range messages {
+		texts[i] = messages[i].Content
+	}
+	moderations, err := llms.ModerateTexts(ctx, appState, texts)
+	if err != nil {
+		return nil, store.NewStorageError("failed to moderate messages", err)
+	}
+
+	rejectThreshold := appState.Config.Extractors.Messages.Moderation.RejectThreshold
+	for i, m := range moderations {
+		if m.Score >= rejectThreshold {
+			return nil, models.NewBadRequestError(fmt.Sprintf(
+				"message %d rejected by moderation: %s score %.2f reaches the threshold of %.2f",
+				i,
+				m.Category,
+				m.Score,
+				rejectThreshold,
+			))
+		}
+	}
+
+	return moderations, nil
+}
+
+// putSystemMetadata records the PII found in messages and their moderation, if any, in
+// their system metadata
+func (pms *PostgresMemoryStore) putSystemMetadata(
+	ctx context.Context,
+	appState *models.AppState,
+	sessionID string,
+	messages []models.Message,
+	entities [][]pii.Entity,
+	moderations []models.Moderation,
+) error {
+	systemMessages := systemMetadataMessages(messages, entities, moderations)
+	if len(systemMessages) == 0 {
+		return nil
+	}
+
+	return pms.PutMessageMetadata(ctx, appState, sessionID, systemMessages, true)
+}
+
+// systemMetadataMessages returns the system metadata recording the PII found in messages and
+// their moderation, for the messages that have either
+func systemMetadataMessages(
+	messages []models.Message,
+	entities [][]pii.Entity,
+	moderations []models.Moderation,
+) []models.Message {
+	var systemMessages []models.Message
+	for i := range messages {
+		system := make(map[string]interface{})
+		if i < len(entities) && len(entities[i]) > 0 {
+			system["pii"] = entities[i]
+		}
+		if i < len(moderations) {
+			system["moderation"] = moderations[i]
+		}
+		if len(system) == 0 {
+			continue
+		}
+		systemMessages = append(systemMessages, models.Message{
+			UUID:     messages[i].UUID,
+			Metadata: map[string]interface{}{"system": system},
+		})
+	}
+
+	return systemMessages
+}
+
+// putMemoryMessages stores messages as putMessages does. In the same transaction, the
+// system metadata of the PII found in messages and their moderation is written, as is the
+// event of the new messages to the outbox if there's an event producer and publish is true,
+// and the event of the session to the outbox if it's created and there are webhooks. The
+// transaction is retried if it fails to serialize.
+func (pms *PostgresMemoryStore) putMemoryMessages(
+	ctx context.Context,
+	appState *models.AppState,
+	sessionID string,
+	messages []models.Message,
+	piiEntities [][]pii.Entity,
+	moderations []models.Moderation,
+	publish bool,
+) ([]models.Message, error) {
+	var result []models.Message
+	err := runInTxWithRetry(ctx, pms.Client, func(ctx context.Context, tx bun.Tx) error {
+		var events []OutboxEventSchema
+		if pms.Webhooks != nil {
+			exists, err := tx.NewSelect().
+				Model((*SessionSchema)(nil)).
+				WhereAllWithDeleted().
+				Where("session_id = ?", sessionID).
+				Exists(ctx)
+			if err != nil {
+				return store.NewStorageError("failed to check session", err)
+			}
+			if !exists {
+				events = append(events, OutboxEventSchema{
+					SessionID: sessionID,
+					WebhookEvent: &models.WebhookEvent{
+						// the event's UUID is set now, so that it's the same if it's
+						// dispatched more than once
+						UUID:      uuid.New(),
+						Type:      models.WebhookEventSessionCreated,
+						CreatedAt: time.Now().UTC(),
+						SessionID: sessionID,
+					},
+				})
+			}
+		}
+
+		// each attempt writes a copy of messages, as they're updated with what's written
+		var err error
+		result, err = putMessagesTx(ctx, tx, sessionID, append([]models.Message(nil), messages...))
+		if err != nil {
+			return err
+		}
+
+		systemMessages := systemMetadataMessages(result, piiEntities, moderations)
+		if len(systemMessages) > 0 {
+			_, err = putMessageMetadata(ctx, tx, sessionID, systemMessages, true)
+			if err != nil {
+				return store.NewStorageError("failed to Create message metadata", err)
+			}
+		}
+
+		if publish && appState.EventProducer != nil && len(result) > 0 {
+			events = append(events, OutboxEventSchema{
+				SessionID: sessionID,
+				SessionEvent: &models.SessionEvent{
+					Type:      models.SessionEventMessages,
+					SessionID: sessionID,
+					Messages:  result,
+				},
+			})
+		}
+		if len(events) > 0 {
+			if _, err := tx.NewInsert().Model(&events).Exec(ctx); err != nil {
+				return store.NewStorageError("failed to write outbox events", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	copy(messages, result)
+
+	return result, nil
+}
+
 func (pms *PostgresMemoryStore) PutMemory(
 	ctx context.Context,
 	appState *models.AppState,
 	sessionID string,
 	memoryMessages *models.Memory,
 	skipNotify bool,
-) error {
+) (err error) {
+	ctx, span := startSpan(ctx, "PutMemory", sessionID)
+	defer func() { endSpan(span, err) }()
+
 	if appState == nil {
 		return store.NewStorageError("nil appState received", nil)
 	}
 
-	messageResult, err := putMessages(
+	if err := pms.openSession(ctx, sessionID); err != nil {
+		return err
+	}
+
+	// PII is redacted before messages are stored, so before they're sent to any llm
+	piiConfig := &appState.Config.Extractors.Messages.PII
+	var piiEntities [][]pii.Entity
+	if piiConfig.Enabled {
+		piiEntities, err = pii.RedactMessages(piiConfig, memoryMessages.Messages)
+		if err != nil {
+			return store.NewStorageError("failed to redact pii", err)
+		}
+	}
+
+	// Messages are only moderated as they're stored if they may be rejected. Otherwise,
+	// moderation is left to the moderation extractor.
+	moderationConfig := &appState.Config.Extractors.Messages.Moderation
+	var moderations []models.Moderation
+	if moderationConfig.Enabled && moderationConfig.RejectThreshold > 0 {
+		moderations, err = moderateMessages(ctx, appState, memoryMessages.Messages)
+		if err != nil {
+			return err
+		}
+	}
+
+	// If events are delivered outside the process, they're written to the outbox in the
+	// transaction that stores the messages, so that they're delivered even if the process
+	// stops before they're published.
+	useOutbox := appState.EventProducer != nil || pms.Webhooks != nil
+	messageResult, err := pms.putMemoryMessages(
 		ctx,
-		pms.Client,
+		appState,
 		sessionID,
 		memoryMessages.Messages,
+		piiEntities,
+		moderations,
+		!skipNotify,
 	)
 	if err != nil {
 		return store.NewStorageError("failed to Create messages", err)
 	}
+	pms.sessionWritten(sessionID)
 
 	// If we are skipping pushing new messages to the message router, return early
 	if skipNotify {
@@ -348,6 +879,7 @@ func (pms *PostgresMemoryStore) PutMemory(
 
 	// Send new messages to the message router
 	err = appState.TaskPublisher.PublishMessage(
+		ctx,
 		map[string]string{"session_id": sessionID},
 		mt,
 	)
@@ -355,6 +887,22 @@ func (pms *PostgresMemoryStore) PutMemory(
 		return store.NewStorageError("failed to publish new messages", err)
 	}
 
+	if len(messageResult) > 0 {
+		event := &models.SessionEvent{
+			Type:      models.SessionEventMessages,
+			SessionID: sessionID,
+			Messages:  messageResult,
+		}
+		// the outbox dispatcher publishes the event with the EventProducer
+		if useOutbox {
+			if appState.SessionEvents != nil {
+				appState.SessionEvents.Publish(event)
+			}
+		} else {
+			appState.PublishSessionEvent(event)
+		}
+	}
+
 	return nil
 }
 
@@ -365,6 +913,10 @@ func (pms *PostgresMemoryStore) PutMessageMetadata(
 	messages []models.Message,
 	isPrivileged bool,
 ) error {
+	if err := checkSessionProject(ctx, pms.Client, sessionID); err != nil {
+		return err
+	}
+
 	_, err := putMessageMetadata(ctx, pms.Client, sessionID, messages, isPrivileged)
 	if err != nil {
 		return store.NewStorageError("failed to Create message metadata", err)
@@ -372,18 +924,184 @@ func (pms *PostgresMemoryStore) PutMessageMetadata(
 	return nil
 }
 
+func (pms *PostgresMemoryStore) UpdateMessageContent(
+	ctx context.Context,
+	appState *models.AppState,
+	sessionID string,
+	messageUUID uuid.UUID,
+	content string,
+) (*models.Message, error) {
+	if appState == nil {
+		return nil, store.NewStorageError("nil appState received", nil)
+	}
+
+	if err := pms.openSession(ctx, sessionID); err != nil {
+		return nil, err
+	}
+
+	// the new content is redacted and moderated as new messages are
+	messages := []models.Message{{UUID: messageUUID, Content: content}}
+	piiConfig := &appState.Config.Extractors.Messages.PII
+	var piiEntities [][]pii.Entity
+	if piiConfig.Enabled {
+		var err error
+		piiEntities, err = pii.RedactMessages(piiConfig, messages)
+		if err != nil {
+			return nil, store.NewStorageError("failed to redact pii", err)
+		}
+	}
+
+	moderationConfig := &appState.Config.Extractors.Messages.Moderation
+	var moderations []models.Moderation
+	if moderationConfig.Enabled && moderationConfig.RejectThreshold > 0 {
+		var err error
+		moderations, err = moderateMessages(ctx, appState, messages)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	message, summariesDeleted, err := updateMessageContent(
+		ctx,
+		pms.Client,
+		sessionID,
+		messageUUID,
+		messages[0].Content,
+	)
+	if err != nil {
+		return nil, err
+	}
+	pms.sessionWritten(sessionID)
+	if summariesDeleted {
+		log.Debugf("deleted summaries including message %s of session %s", messageUUID, sessionID)
+	}
+
+	err = pms.putSystemMetadata(
+		ctx,
+		appState,
+		sessionID,
+		[]models.Message{*message},
+		piiEntities,
+		moderations,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// the extractors re-embed the message, recount its tokens, and resummarize the session
+	err = appState.TaskPublisher.PublishMessage(
+		ctx,
+		map[string]string{"session_id": sessionID},
+		[]models.MessageTask{{UUID: messageUUID}},
+	)
+	if err != nil {
+		return nil, store.NewStorageError("failed to publish updated message", err)
+	}
+
+	return message, nil
+}
+
+// DeleteMessage deletes a message from Postgres. If a VectorIndex is set, the message's
+// embedding is removed from the index.
+func (pms *PostgresMemoryStore) DeleteMessage(
+	ctx context.Context,
+	sessionID string,
+	messageUUID uuid.UUID,
+) error {
+	if err := pms.openSession(ctx, sessionID); err != nil {
+		return err
+	}
+
+	if err := deleteMessage(ctx, pms.Client, sessionID, messageUUID); err != nil {
+		return err
+	}
+	pms.sessionWritten(sessionID)
+
+	if pms.VectorIndex != nil {
+		return pms.VectorIndex.DeleteMessageEmbedding(ctx, sessionID, messageUUID)
+	}
+
+	return nil
+}
+
 func (pms *PostgresMemoryStore) SearchMemory(
 	ctx context.Context,
 	appState *models.AppState,
 	sessionID string,
 	query *models.MemorySearchPayload,
 	limit int,
-) ([]models.MemorySearchResult, error) {
-	searchResults, err := searchMemory(ctx, appState, pms.Client, sessionID, query, limit)
-	return searchResults, err
+) (_ []models.MemorySearchResult, err error) {
+	ctx, span := startSpan(ctx, "SearchMemory", sessionID)
+	defer func() { endSpan(span, err) }()
+
+	if err := pms.openSession(ctx, sessionID); err != nil {
+		return nil, err
+	}
+
+	db := pms.readClient(sessionID)
+	searchScope := func(
+		query *models.MemorySearchPayload,
+		limit int,
+	) ([]models.MemorySearchResult, error) {
+		if pms.VectorIndex != nil && query != nil &&
+			(query.SearchScope == models.SearchScopeMessages || query.SearchScope == "") {
+			return searchMemoryVectorIndex(
+				ctx,
+				appState,
+				db,
+				pms.VectorIndex,
+				sessionID,
+				query,
+				limit,
+			)
+		}
+		return searchMemory(ctx, appState, db, sessionID, query, limit)
+	}
+	search := func(limit int) ([]models.MemorySearchResult, error) {
+		// fuse the scopes here so that messages are searched using the vector index
+		if query != nil && query.SearchScope == models.SearchScopeAll {
+			return searchAllMemory(query, limit, searchScope)
+		}
+		return searchScope(query, limit)
+	}
+
+	if query != nil && query.Rerank {
+		unranked := search
+		search = func(limit int) ([]models.MemorySearchResult, error) {
+			return rerankMemorySearch(ctx, appState, query, limit, unranked)
+		}
+	}
+	if query != nil && query.RecencyHalfLifeDays > 0 {
+		return decayMemorySearch(query, limit, time.Now(), search)
+	}
+	return search(limit)
+}
+
+func (pms *PostgresMemoryStore) SearchUserMemory(
+	ctx context.Context,
+	appState *models.AppState,
+	userID string,
+	query *models.MemorySearchPayload,
+	limit int,
+) ([]models.UserMemorySearchResult, error) {
+	return searchUserMemory(
+		ctx,
+		pms.Client,
+		userID,
+		query,
+		limit,
+		func(sessionID string, limit int) ([]models.MemorySearchResult, error) {
+			return pms.SearchMemory(ctx, appState, sessionID, query, limit)
+		},
+	)
 }
 
 func (pms *PostgresMemoryStore) Close() error {
+	if pms.ReadClient != nil {
+		if err := pms.ReadClient.Close(); err != nil {
+			return err
+		}
+	}
 	if pms.Client != nil {
 		return pms.Client.Close()
 	}
@@ -401,8 +1119,16 @@ func (pms *PostgresMemoryStore) PutMessageEmbeddings(ctx context.Context,
 	if len(embeddings) == 0 {
 		return store.NewStorageError("no embeddings received", nil)
 	}
+	if err := checkSessionProject(ctx, pms.Client, sessionID); err != nil {
+		return err
+	}
 
-	err := putMessageEmbeddings(ctx, pms.Client, sessionID, embeddings)
+	var err error
+	if pms.VectorIndex != nil {
+		err = pms.VectorIndex.PutMessageEmbeddings(ctx, sessionID, embeddings)
+	} else {
+		err = putMessageEmbeddings(ctx, pms.Client, sessionID, embeddings)
+	}
 	if err != nil {
 		return store.NewStorageError("failed to Create embeddings", err)
 	}
@@ -414,7 +1140,17 @@ func (pms *PostgresMemoryStore) GetMessageEmbeddings(ctx context.Context,
 	_ *models.AppState,
 	sessionID string,
 ) ([]models.TextData, error) {
-	embeddings, err := getMessageEmbeddings(ctx, pms.Client, sessionID)
+	if err := pms.openSession(ctx, sessionID); err != nil {
+		return nil, err
+	}
+
+	var embeddings []models.TextData
+	var err error
+	if pms.VectorIndex != nil {
+		embeddings, err = getMessageEmbeddingsFromIndex(ctx, pms.Client, pms.VectorIndex, sessionID)
+	} else {
+		embeddings, err = getMessageEmbeddings(ctx, pms.Client, sessionID)
+	}
 	if err != nil {
 		return nil, store.NewStorageError("GetMessageEmbeddings failed to get embeddings", err)
 	}
@@ -423,10 +1159,20 @@ func (pms *PostgresMemoryStore) GetMessageEmbeddings(ctx context.Context,
 }
 
 func (pms *PostgresMemoryStore) PurgeDeleted(ctx context.Context) error {
+	var archiveKeys []string
+	if pms.ArchiveStore != nil {
+		var err error
+		archiveKeys, err = sessionArchiveKeys(ctx, pms.Client, "deleted_at IS NOT NULL")
+		if err != nil {
+			return store.NewStorageError("failed to get deleted session archives", err)
+		}
+	}
+
 	err := purgeDeleted(ctx, pms.Client)
 	if err != nil {
 		return store.NewStorageError("failed to purge deleted", err)
 	}
+	pms.deleteSessionArchives(ctx, archiveKeys)
 
 	return nil
 }
@@ -439,7 +1185,8 @@ func acquireAdvisoryXactLock(ctx context.Context, tx bun.Tx, key string) error {
 	hasher := sha256.New()
 	hasher.Write([]byte(key))
 	hash := hasher.Sum(nil)
-	lockID := binary.BigEndian.Uint64(hash[:8])
+	// lock IDs are bigints, which half of the uint64 hashes would overflow
+	lockID := int64(binary.BigEndian.Uint64(hash[:8]))
 
 	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(?)", lockID); err != nil {
 		return store.NewStorageError("failed to acquire advisory lock", err)
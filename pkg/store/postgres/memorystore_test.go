@@ -1,12 +1,19 @@
 package postgres
 
 import (
+	"encoding/json"
+	"fmt"
 	"math/rand"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/getzep/zep/config"
+
 	"github.com/getzep/zep/pkg/llms"
 	"github.com/getzep/zep/pkg/tasks"
 
@@ -14,7 +21,9 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/store"
 	"github.com/getzep/zep/pkg/testutils"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/uptrace/bun"
@@ -132,7 +141,7 @@ func TestPutMessages(t *testing.T) {
 	})
 
 	t.Run(
-		"upsert messages with deleted session should not error",
+		"upsert messages with deleted session should return not found",
 		func(t *testing.T) {
 			sessionID := createSession(t)
 
@@ -140,10 +149,10 @@ func TestPutMessages(t *testing.T) {
 			assert.NoError(t, err, "putMessages should not return an error")
 
 			sessionStore := NewSessionDAO(testDB)
-			err = sessionStore.Delete(testCtx, sessionID)
+			err = sessionStore.Delete(testCtx, sessionID, false)
 			assert.NoError(t, err, "deleteSession should not return an error")
 
-			messagesOnceDeleted, err := getMessages(testCtx, testDB, sessionID, 12, nil, 0)
+			messagesOnceDeleted, err := getMessages(testCtx, testDB, sessionID, 12, nil, 0, nil)
 			assert.NoError(t, err, "getMessages should not return an error")
 
 			// confirm that no records were returned
@@ -151,9 +160,51 @@ func TestPutMessages(t *testing.T) {
 
 			// Call putMessages function to upsert the messages
 			_, err = putMessages(testCtx, testDB, sessionID, insertedMessages)
-			assert.NoError(t, err, "putMessages should not return an error")
+			assert.ErrorIs(t, err, models.ErrNotFound, "putMessages should return ErrNotFound")
 		},
 	)
+
+	t.Run("message UUID of another session is rejected", func(t *testing.T) {
+		sessionID := createSession(t)
+		insertedMessages, err := putMessages(testCtx, testDB, sessionID, messages)
+		require.NoError(t, err)
+
+		otherSessionID := createSession(t)
+		_, err = putMessages(testCtx, testDB, otherSessionID, insertedMessages[:1])
+		assert.ErrorIs(t, err, models.ErrBadRequest)
+
+		count, err := countMessages(testCtx, testDB, otherSessionID, nil)
+		require.NoError(t, err)
+		assert.Zero(t, count)
+		// the message isn't moved to the other session
+		count, err = countMessages(testCtx, testDB, sessionID, nil)
+		require.NoError(t, err)
+		assert.Equal(t, len(messages), count)
+	})
+
+	t.Run("insert messages in batches", func(t *testing.T) {
+		sessionID := createSession(t)
+		manyMessages := make([]models.Message, MessageInsertBatchSize+1)
+		for i := range manyMessages {
+			manyMessages[i] = models.Message{Role: "user", Content: fmt.Sprintf("message %d", i)}
+		}
+
+		var stored []int
+		ctx := models.WithMessageProgress(testCtx, func(n, total int) {
+			assert.Equal(t, len(manyMessages), total)
+			stored = append(stored, n)
+		})
+		resultMessages, err := putMessages(ctx, testDB, sessionID, manyMessages)
+		require.NoError(t, err)
+		assert.Equal(t, []int{MessageInsertBatchSize, MessageInsertBatchSize + 1}, stored)
+
+		count, err := countMessages(testCtx, testDB, sessionID, nil)
+		require.NoError(t, err)
+		assert.Equal(t, len(manyMessages), count)
+		for _, m := range resultMessages {
+			assert.NotEqual(t, uuid.Nil, m.UUID)
+		}
+	})
 }
 
 func createSession(t *testing.T) string {
@@ -305,6 +356,7 @@ func TestGetMessages(t *testing.T) {
 				messageWindow,
 				summary,
 				tt.lastNMessages,
+				nil,
 			)
 			assert.NoError(t, err)
 
@@ -377,7 +429,14 @@ func TestGetMessageList(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := getMessageList(testCtx, testDB, tt.sessionID, tt.pageNumber, tt.pageSize)
+			result, err := getMessageList(
+				testCtx,
+				testDB,
+				tt.sessionID,
+				tt.pageNumber,
+				tt.pageSize,
+				nil,
+			)
 			assert.NoError(t, err)
 
 			if tt.expectedLength > 0 {
@@ -402,6 +461,78 @@ func TestGetMessageList(t *testing.T) {
 	}
 }
 
+func TestGetMessageListByCursor(t *testing.T) {
+	sessionID, err := testutils.GenerateRandomSessionID(16)
+	assert.NoError(t, err, "GenerateRandomSessionID should not return an error")
+
+	messages, err := putMessages(testCtx, testDB, sessionID, testutils.TestMessages)
+	assert.NoError(t, err)
+
+	pageSize := 7
+	var listed []models.Message
+	var cursor int64
+	for {
+		result, err := getMessageListByCursor(testCtx, testDB, sessionID, cursor, pageSize, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, len(messages), result.TotalCount)
+		assert.LessOrEqual(t, result.RowCount, pageSize)
+		listed = append(listed, result.Messages...)
+		if result.NextCursor == 0 {
+			break
+		}
+		assert.Greater(t, result.NextCursor, cursor)
+		cursor = result.NextCursor
+	}
+
+	// all messages are listed once, oldest first
+	assert.Equal(t, len(messages), len(listed))
+	for i, msg := range listed {
+		assert.Equal(t, messages[i].UUID, msg.UUID)
+	}
+
+	t.Run("Non-existent session", func(t *testing.T) {
+		result, err := getMessageListByCursor(testCtx, testDB, "nonexistent", 0, pageSize, nil)
+		assert.NoError(t, err)
+		assert.Empty(t, result.Messages)
+		assert.Zero(t, result.NextCursor)
+	})
+
+	t.Run("Role filter", func(t *testing.T) {
+		opts := &models.MessageListOptions{Role: "assistant"}
+		result, err := getMessageListByCursor(testCtx, testDB, sessionID, 0, 100, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, 10, result.TotalCount)
+		assert.Len(t, result.Messages, 10)
+		for _, msg := range result.Messages {
+			assert.Equal(t, "assistant", msg.Role)
+		}
+	})
+
+	t.Run("Descending", func(t *testing.T) {
+		opts := &models.MessageListOptions{Desc: true}
+		result, err := getMessageListByCursor(testCtx, testDB, sessionID, 0, pageSize, opts)
+		assert.NoError(t, err)
+		assert.Len(t, result.Messages, pageSize)
+		assert.Equal(t, messages[len(messages)-1].UUID, result.Messages[0].UUID)
+
+		result, err = getMessageListByCursor(
+			testCtx,
+			testDB,
+			sessionID,
+			result.NextCursor,
+			pageSize,
+			opts,
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, messages[len(messages)-1-pageSize].UUID, result.Messages[0].UUID)
+	})
+
+	t.Run("Negative cursor", func(t *testing.T) {
+		_, err := getMessageListByCursor(testCtx, testDB, sessionID, -1, pageSize, nil)
+		assert.ErrorIs(t, err, models.ErrBadRequest)
+	})
+}
+
 // equate map[string]interface{}(nil) and map[string]interface{}{}
 // the latter is returned by the database when a row has no metadata.
 // both eval to len == 0
@@ -496,3 +627,425 @@ func checkForTable(t *testing.T, testDB *bun.DB, schema interface{}) {
 	_, err := testDB.NewSelect().Model(schema).Limit(0).Exec(context.Background())
 	require.NoError(t, err)
 }
+
+func TestGetMessagesExcludeSystemMessages(t *testing.T) {
+	sessionID, err := testutils.GenerateRandomSessionID(16)
+	require.NoError(t, err)
+
+	messages := []models.Message{
+		{Role: "user", Content: "What's the weather?"},
+		{Role: "tool", Content: "{\"temperature\": 21}", IsSystem: true},
+		{Role: "assistant", Content: "It's 21 degrees."},
+	}
+	_, err = putMessages(testCtx, testDB, sessionID, messages)
+	require.NoError(t, err)
+
+	t.Run("system messages are included by default", func(t *testing.T) {
+		result, err := getMessages(testCtx, testDB, sessionID, 12, nil, 0, nil)
+		require.NoError(t, err)
+		require.Len(t, result, 3)
+		assert.False(t, result[0].IsSystem)
+		assert.True(t, result[1].IsSystem)
+	})
+
+	request := &models.GetMessagesRequest{ExcludeSystemMessages: true}
+	for _, lastN := range []int{0, 3} {
+		result, err := getMessages(testCtx, testDB, sessionID, 12, nil, lastN, request)
+		require.NoError(t, err)
+		require.Len(t, result, 2, "lastN %d", lastN)
+		for _, m := range result {
+			assert.False(t, m.IsSystem)
+		}
+	}
+}
+
+func TestGetMessagesMaxTokens(t *testing.T) {
+	sessionID, err := testutils.GenerateRandomSessionID(16)
+	require.NoError(t, err)
+
+	messages := make([]models.Message, 5)
+	for i := range messages {
+		messages[i] = models.Message{
+			Role:       "user",
+			Content:    fmt.Sprintf("message %d", i),
+			TokenCount: 10,
+		}
+	}
+	messages[4].IsSystem = true
+	_, err = putMessages(testCtx, testDB, sessionID, messages)
+	require.NoError(t, err)
+
+	contents := func(messages []models.Message) []string {
+		c := make([]string, len(messages))
+		for i, m := range messages {
+			c[i] = m.Content
+		}
+		return c
+	}
+
+	tests := []struct {
+		name     string
+		lastN    int
+		request  *models.GetMessagesRequest
+		expected []string
+	}{
+		{
+			"within budget",
+			0,
+			&models.GetMessagesRequest{MaxTokens: 25},
+			[]string{"message 3", "message 4"},
+		},
+		{
+			"budget exceeds messages",
+			0,
+			&models.GetMessagesRequest{MaxTokens: 1000},
+			[]string{"message 0", "message 1", "message 2", "message 3", "message 4"},
+		},
+		{"with lastn", 1, &models.GetMessagesRequest{MaxTokens: 25}, []string{"message 4"}},
+		{
+			"excluding system messages",
+			0,
+			&models.GetMessagesRequest{MaxTokens: 20, ExcludeSystemMessages: true},
+			[]string{"message 2", "message 3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := getMessages(testCtx, testDB, sessionID, 2, nil, tt.lastN, tt.request)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, contents(result))
+		})
+	}
+}
+
+func TestGetMessagesForRoles(t *testing.T) {
+	sessionID, err := testutils.GenerateRandomSessionID(16)
+	require.NoError(t, err)
+
+	messages := []models.Message{
+		{Role: "user", Content: "What's the weather?"},
+		{Role: "tool", Content: "{\"temperature\": 21}"},
+		{Role: "assistant", Content: "It's 21 degrees."},
+		{Role: "user", Content: "Thanks!"},
+		{Role: "tool", Content: "{\"temperature\": 22}"},
+	}
+	_, err = putMessages(testCtx, testDB, sessionID, messages)
+	require.NoError(t, err)
+
+	t.Run("with positions", func(t *testing.T) {
+		result, err := GetMessagesForRoles(testCtx, testDB, sessionID, []string{"tool"}, true)
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+		assert.Equal(t, messages[1].Content, result[0].Content)
+		assert.Equal(t, 2, result[0].AbsolutePosition)
+		assert.InDelta(t, 0.25, result[0].RelativePosition, 0.0001)
+		assert.Equal(t, 5, result[1].AbsolutePosition)
+		assert.InDelta(t, 1.0, result[1].RelativePosition, 0.0001)
+	})
+
+	t.Run("without positions", func(t *testing.T) {
+		result, err := GetMessagesForRoles(
+			testCtx,
+			testDB,
+			sessionID,
+			[]string{"user", "assistant"},
+			false,
+		)
+		require.NoError(t, err)
+		require.Len(t, result, 3)
+		assert.Equal(t, 0, result[0].AbsolutePosition)
+	})
+
+	t.Run("no roles", func(t *testing.T) {
+		_, err := GetMessagesForRoles(testCtx, testDB, sessionID, nil, true)
+		assert.ErrorIs(t, err, models.ErrBadRequest)
+	})
+}
+
+func TestPutMemory_RedactsPII(t *testing.T) {
+	appState.Config.Extractors.Messages.PII.Enabled = true
+	defer func() { appState.Config.Extractors.Messages.PII.Enabled = false }()
+
+	sessionID := testutils.GenerateRandomString(16)
+	memory := &models.Memory{Messages: []models.Message{
+		{Role: "user", Content: "Email me at jane@example.com"},
+		{Role: "assistant", Content: "Will do"},
+	}}
+	err := appState.MemoryStore.PutMemory(testCtx, appState, sessionID, memory, true)
+	require.NoError(t, err)
+
+	messageList, err := getMessageListByCursor(testCtx, testDB, sessionID, 0, 10, nil)
+	require.NoError(t, err)
+	require.Len(t, messageList.Messages, 2)
+	assert.Equal(t, "Email me at [EMAIL]", messageList.Messages[0].Content)
+	assert.Equal(t, "Will do", messageList.Messages[1].Content)
+
+	system, ok := messageList.Messages[0].Metadata["system"].(map[string]interface{})
+	require.True(t, ok, "pii should be recorded in system metadata")
+	assert.Len(t, system["pii"], 1)
+	assert.NotContains(t, messageList.Messages[1].Metadata, "system")
+}
+
+func TestPutMemory_Moderation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Texts []string `json:"texts"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+		results := make([]models.ModerationResult, len(request.Texts))
+		for i, text := range request.Texts {
+			score := 0.1
+			if strings.Contains(text, "hateful") {
+				score = 0.95
+			}
+			results[i] = models.ModerationResult{
+				CategoryScores: map[string]float64{"hate": score},
+			}
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(map[string]interface{}{"results": results}))
+	}))
+	defer server.Close()
+
+	serverURL := appState.Config.NLP.ServerURL
+	appState.Config.NLP.ServerURL = server.URL
+	appState.Config.Extractors.Messages.Moderation = config.ModerationConfig{
+		Enabled:         true,
+		Service:         "local",
+		RejectThreshold: 0.9,
+	}
+	defer func() {
+		appState.Config.NLP.ServerURL = serverURL
+		appState.Config.Extractors.Messages.Moderation = config.ModerationConfig{}
+	}()
+
+	sessionID := testutils.GenerateRandomString(16)
+	err := appState.MemoryStore.PutMemory(testCtx, appState, sessionID, &models.Memory{
+		Messages: []models.Message{{Role: "user", Content: "Hello"}},
+	}, true)
+	require.NoError(t, err)
+
+	messageList, err := getMessageListByCursor(testCtx, testDB, sessionID, 0, 10, nil)
+	require.NoError(t, err)
+	require.Len(t, messageList.Messages, 1)
+	system, ok := messageList.Messages[0].Metadata["system"].(map[string]interface{})
+	require.True(t, ok, "moderation should be recorded in system metadata")
+	moderation, ok := system["moderation"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, false, moderation["flagged"])
+
+	// a message reaching the reject threshold fails the whole call
+	err = appState.MemoryStore.PutMemory(testCtx, appState, sessionID, &models.Memory{
+		Messages: []models.Message{
+			{Role: "user", Content: "Fine"},
+			{Role: "user", Content: "Something hateful"},
+		},
+	}, true)
+	assert.ErrorIs(t, err, models.ErrBadRequest)
+
+	messageList, err = getMessageListByCursor(testCtx, testDB, sessionID, 0, 10, nil)
+	require.NoError(t, err)
+	assert.Len(t, messageList.Messages, 1)
+}
+
+func TestGetMemory_SessionMessageWindow(t *testing.T) {
+	messages := make([]models.Message, 6)
+	for i := range messages {
+		messages[i] = models.Message{Role: "user", Content: fmt.Sprintf("message %d", i)}
+	}
+
+	newSession := func(metadata map[string]interface{}) string {
+		sessionID := testutils.GenerateRandomString(16)
+		_, err := appState.MemoryStore.CreateSession(
+			testCtx,
+			appState,
+			&models.CreateSessionRequest{SessionID: sessionID, Metadata: metadata},
+		)
+		require.NoError(t, err)
+		_, err = putMessages(testCtx, testDB, sessionID, messages)
+		require.NoError(t, err)
+		return sessionID
+	}
+
+	sessionID := newSession(map[string]interface{}{models.MessageWindowMetadataKey: 4})
+	memory, err := appState.MemoryStore.GetMemory(testCtx, appState, sessionID, 0, nil)
+	require.NoError(t, err)
+	require.Len(t, memory.Messages, 4)
+	assert.Equal(t, "message 0", memory.Messages[0].Content)
+
+	sessionID = newSession(map[string]interface{}{models.MessageWindowMetadataKey: "four"})
+	_, err = appState.MemoryStore.GetMemory(testCtx, appState, sessionID, 0, nil)
+	assert.ErrorIs(t, err, models.ErrBadRequest)
+}
+
+func TestUpdateMessageContent(t *testing.T) {
+	sessionID := createSession(t)
+
+	messages, err := putMessages(testCtx, testDB, sessionID, []models.Message{
+		{Role: "user", Content: "message 0"},
+		{Role: "user", Content: "message 1"},
+		{Role: "user", Content: "message 2"},
+	})
+	require.NoError(t, err)
+
+	vector := make([]float32, embeddingModel.Dimensions)
+	for i := range vector {
+		vector[i] = 0.5
+	}
+	embeddings := make([]models.TextData, len(messages))
+	for i, m := range messages {
+		embeddings[i] = models.TextData{TextUUID: m.UUID, Text: m.Content, Embedding: vector}
+	}
+	err = putMessageEmbeddings(testCtx, testDB, sessionID, embeddings)
+	require.NoError(t, err)
+
+	before, err := putSummary(testCtx, testDB, sessionID, &models.Summary{
+		Content:          "summary before the update",
+		SummaryPointUUID: messages[0].UUID,
+	})
+	require.NoError(t, err)
+	_, err = putSummary(testCtx, testDB, sessionID, &models.Summary{
+		Content:          "summary including the update",
+		SummaryPointUUID: messages[2].UUID,
+	})
+	require.NoError(t, err)
+
+	updated, summariesDeleted, err := updateMessageContent(
+		testCtx,
+		testDB,
+		sessionID,
+		messages[1].UUID,
+		"message 1, edited",
+	)
+	require.NoError(t, err)
+	assert.True(t, summariesDeleted)
+	assert.Equal(t, "message 1, edited", updated.Content)
+	assert.Zero(t, updated.TokenCount)
+
+	var embedding MessageVectorStoreSchema
+	err = testDB.NewSelect().
+		Model(&embedding).
+		Where("message_uuid = ?", messages[1].UUID).
+		Scan(testCtx)
+	require.NoError(t, err)
+	assert.False(t, embedding.IsEmbedded)
+
+	// only the summary including the updated message is deleted
+	var summaries []SummaryStoreSchema
+	err = testDB.NewSelect().
+		Model(&summaries).
+		WhereAllWithDeleted().
+		Where("session_id = ?", sessionID).
+		Scan(testCtx)
+	require.NoError(t, err)
+	require.Len(t, summaries, 1)
+	assert.Equal(t, before.UUID, summaries[0].UUID)
+
+	// re-embedding the message marks its embedding current
+	err = putMessageEmbeddings(testCtx, testDB, sessionID, embeddings[1:2])
+	require.NoError(t, err)
+	err = testDB.NewSelect().
+		Model(&embedding).
+		Where("message_uuid = ?", messages[1].UUID).
+		Scan(testCtx)
+	require.NoError(t, err)
+	assert.True(t, embedding.IsEmbedded)
+
+	t.Run("non-existent message returns not found", func(t *testing.T) {
+		_, _, err := updateMessageContent(testCtx, testDB, sessionID, uuid.New(), "content")
+		assert.ErrorIs(t, err, models.ErrNotFound)
+	})
+}
+
+func TestDeleteMessage(t *testing.T) {
+	sessionID := createSession(t)
+
+	messages, err := putMessages(testCtx, testDB, sessionID, []models.Message{
+		{Role: "user", Content: "message 0"},
+		{Role: "user", Content: "message 1"},
+		{Role: "user", Content: "message 2"},
+		{Role: "user", Content: "message 3"},
+	})
+	require.NoError(t, err)
+
+	vector := make([]float32, embeddingModel.Dimensions)
+	for i := range vector {
+		vector[i] = 0.5
+	}
+	err = putMessageEmbeddings(testCtx, testDB, sessionID, []models.TextData{
+		{TextUUID: messages[3].UUID, Text: messages[3].Content, Embedding: vector},
+	})
+	require.NoError(t, err)
+
+	first, err := putSummary(testCtx, testDB, sessionID, &models.Summary{
+		Content:          "first summary",
+		SummaryPointUUID: messages[1].UUID,
+	})
+	require.NoError(t, err)
+	second, err := putSummary(testCtx, testDB, sessionID, &models.Summary{
+		Content:          "second summary",
+		SummaryPointUUID: messages[3].UUID,
+	})
+	require.NoError(t, err)
+
+	summaryPoints := func() map[uuid.UUID]uuid.UUID {
+		var summaries []SummaryStoreSchema
+		err := testDB.NewSelect().
+			Model(&summaries).
+			WhereAllWithDeleted().
+			Where("session_id = ?", sessionID).
+			Scan(testCtx)
+		require.NoError(t, err)
+		points := make(map[uuid.UUID]uuid.UUID, len(summaries))
+		for _, s := range summaries {
+			points[s.UUID] = s.SummaryPointUUID
+		}
+		return points
+	}
+
+	t.Run("summary point moves to the preceding message", func(t *testing.T) {
+		err := deleteMessage(testCtx, testDB, sessionID, messages[3].UUID)
+		require.NoError(t, err)
+
+		count, err := testDB.NewSelect().
+			Model((*MessageVectorStoreSchema)(nil)).
+			WhereAllWithDeleted().
+			Where("message_uuid = ?", messages[3].UUID).
+			Count(testCtx)
+		require.NoError(t, err)
+		assert.Zero(t, count)
+
+		assert.Equal(t, map[uuid.UUID]uuid.UUID{
+			first.UUID:  messages[1].UUID,
+			second.UUID: messages[2].UUID,
+		}, summaryPoints())
+
+		result, err := getMessages(testCtx, testDB, sessionID, 10, nil, 0, nil)
+		require.NoError(t, err)
+		assert.Len(t, result, 3)
+	})
+
+	t.Run("summary is deleted at an earlier summary point", func(t *testing.T) {
+		err := deleteMessage(testCtx, testDB, sessionID, messages[2].UUID)
+		require.NoError(t, err)
+		assert.Equal(t, map[uuid.UUID]uuid.UUID{first.UUID: messages[1].UUID}, summaryPoints())
+	})
+
+	t.Run("non-existent message returns not found", func(t *testing.T) {
+		err := deleteMessage(testCtx, testDB, sessionID, messages[3].UUID)
+		assert.ErrorIs(t, err, models.ErrNotFound)
+	})
+
+	t.Run("embedding is deleted from the vector index", func(t *testing.T) {
+		index := &staticVectorIndex{}
+		pms := &PostgresMemoryStore{
+			BaseMemoryStore: store.BaseMemoryStore[*bun.DB]{Client: testDB},
+			SessionStore:    NewSessionDAO(testDB),
+			VectorIndex:     index,
+		}
+
+		err := pms.DeleteMessage(testCtx, sessionID, messages[0].UUID)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{messages[0].UUID}, index.deleted)
+	})
+}
@@ -68,8 +68,14 @@ func putMessageEmbeddings(
 		}
 	}
 
+	// messages whose content is updated are embedded again. The unique constraint on
+	// message_uuid includes session_id if the table is partitioned.
 	_, err := db.NewInsert().
 		Model(&embeddingVectors).
+		On("CONFLICT ON CONSTRAINT message_embedding_message_uuid_key DO UPDATE").
+		Set("embedding = EXCLUDED.embedding").
+		Set("is_embedded = EXCLUDED.is_embedded").
+		Set("updated_at = EXCLUDED.updated_at").
 		Exec(ctx)
 
 	if err != nil {
@@ -96,7 +96,7 @@ func TestPutUnPrivilegedMetadata(t *testing.T) {
 	_, err = putMessageMetadata(testCtx, testDB, sessionID, metadataToMerge, false)
 	assert.NoError(t, err, "putMetadata should not return an error")
 
-	msgs, err := getMessages(testCtx, testDB, sessionID, 12, &models.Summary{}, 0)
+	msgs, err := getMessages(testCtx, testDB, sessionID, 12, &models.Summary{}, 0, nil)
 	assert.NoError(t, err, "getMessages should not return an error")
 
 	for _, testCase := range testCases {
@@ -322,7 +322,7 @@ func TestPutMetadata(t *testing.T) {
 	_, err = putMessageMetadata(testCtx, testDB, sessionID, metadataToMerge, true)
 	assert.NoError(t, err, "putMetadata should not return an error")
 
-	msgs, err := getMessages(testCtx, testDB, sessionID, 12, &models.Summary{}, 0)
+	msgs, err := getMessages(testCtx, testDB, sessionID, 12, &models.Summary{}, 0, nil)
 	assert.NoError(t, err, "getMessages should not return an error")
 
 	for _, testCase := range testCases {
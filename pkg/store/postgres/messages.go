@@ -13,42 +13,94 @@ import (
 	"github.com/getzep/zep/pkg/store"
 	"github.com/jinzhu/copier"
 	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/driver/pgdriver"
 )
 
+// DefaultMessageListLimit is the number of messages returned by getMessageListByCursor if no
+// limit is given
+const DefaultMessageListLimit = 100
+
+// MessageInsertBatchSize is the number of messages inserted by each statement of putMessages.
+// Large batches, such as those of imports, are inserted in several statements rather than
+// one, which keeps statements to a size Postgres plans and executes efficiently.
+const MessageInsertBatchSize = 500
+
 // putMessages stores a new or updates existing messages for a session. Existing
 // messages are determined by message UUID. Sessions are created if they do not
 // exist.
-// If the session is deleted, an error is returned.
+// If the session is deleted, a NotFoundError is returned. If a message's UUID is that of
+// another session's message, a BadRequestError is returned.
+// The session, messages and their metadata are written in a single transaction, which is
+// retried if it fails to serialize. db may be a bun.Tx, in which case the caller is
+// responsible for committing and retrying it.
 func putMessages(
 	ctx context.Context,
-	db *bun.DB,
+	db bun.IDB,
 	sessionID string,
 	messages []models.Message,
 ) ([]models.Message, error) {
+	if tx, ok := db.(bun.Tx); ok {
+		return putMessagesTx(ctx, tx, sessionID, messages)
+	}
+
+	var result []models.Message
+	err := runInTxWithRetry(ctx, db, func(ctx context.Context, tx bun.Tx) error {
+		// each attempt writes a copy of messages, as they're updated with what's written
+		var err error
+		result, err = putMessagesTx(ctx, tx, sessionID, append([]models.Message(nil), messages...))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	copy(messages, result)
+
+	return result, nil
+}
+
+// putMessagesTx stores messages as putMessages does, in tx
+func putMessagesTx(
+	ctx context.Context,
+	tx bun.Tx,
+	sessionID string,
+	messages []models.Message,
+) ([]models.Message, error) {
+	putLog := internal.LoggerFromContext(ctx).WithField("session_id", sessionID)
 	if len(messages) == 0 {
-		log.Warn("putMessages called with no messages")
+		putLog.Warn("putMessages called with no messages")
 		return nil, nil
 	}
-	log.Debugf(
-		"putMessages called for session %s with %d messages",
-		sessionID,
-		len(messages),
-	)
-
-	// Try Update the session first. If no rows are affected, create a new session.
-	sessionStore := NewSessionDAO(db)
-	_, err := sessionStore.Update(ctx, &models.UpdateSessionRequest{
-		SessionID: sessionID,
-	}, false)
-	if err != nil {
-		if errors.Is(err, models.ErrNotFound) {
-			_, err = sessionStore.Create(ctx, &models.CreateSessionRequest{
-				SessionID: sessionID,
-			})
-			if err != nil {
-				return nil, err
-			}
-		} else {
+	putLog.WithField("messages", len(messages)).Debug("putMessages called")
+
+	// Check whether the session exists, including soft-deleted sessions. Deleted
+	// sessions are not written to. New sessions are created.
+	sessionStore := NewSessionDAO(tx)
+	session := SessionSchema{}
+	err := tx.NewSelect().
+		Model(&session).
+		Column("deleted_at", "language").
+		WhereAllWithDeleted().
+		Where("session_id = ?", sessionID).
+		Scan(ctx)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		newSession, err := sessionStore.Create(ctx, &models.CreateSessionRequest{
+			SessionID: sessionID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		session.Language = newSession.Language
+	case err != nil:
+		return nil, store.NewStorageError("failed to get session", err)
+	case !session.DeletedAt.IsZero():
+		return nil, models.NewNotFoundError("session " + sessionID)
+	default:
+		// Touch the session's updated_at
+		_, err = sessionStore.Update(ctx, &models.UpdateSessionRequest{
+			SessionID: sessionID,
+		}, false)
+		if err != nil {
 			return nil, err
 		}
 	}
@@ -62,17 +114,59 @@ func putMessages(
 			Content:    msg.Content,
 			TokenCount: msg.TokenCount,
 			Metadata:   msg.Metadata,
+			IsSystem:   msg.IsSystem,
+			// messages default to the session's text search language
+			Language: session.Language,
 		}
 	}
 
-	// Insert messages
-	_, err = db.NewInsert().
-		Model(&pgMessages).
-		Column("uuid", "session_id", "role", "content", "token_count", "updated_at").
-		On("CONFLICT (uuid) DO UPDATE").
-		Exec(ctx)
-	if err != nil {
-		return nil, store.NewStorageError("failed to Create messages", err)
+	// Insert messages in batches, reporting progress through batches of large puts
+	onProgress := models.MessageProgressFromContext(ctx)
+	if len(pgMessages) <= MessageInsertBatchSize {
+		onProgress = nil
+	}
+	for start := 0; start < len(pgMessages); start += MessageInsertBatchSize {
+		end := start + MessageInsertBatchSize
+		if end > len(pgMessages) {
+			end = len(pgMessages)
+		}
+		batch := pgMessages[start:end]
+		r, err := tx.NewInsert().
+			Model(&batch).
+			Column(
+				"uuid",
+				"session_id",
+				"role",
+				"content",
+				"token_count",
+				"updated_at",
+				"language",
+				"is_system",
+			).
+			// the primary key includes session_id if the table is partitioned, in which case
+			// the message_uuid table's trigger rejects the UUIDs of other sessions' messages
+			On("CONFLICT ON CONSTRAINT message_pkey DO UPDATE").
+			// don't update, or move, the messages of other sessions
+			Where("m.session_id = EXCLUDED.session_id").
+			Exec(ctx)
+		if err != nil {
+			if pgErr, ok := err.(pgdriver.Error); ok && pgErr.IntegrityViolation() {
+				return nil, models.NewBadRequestError(pgErr.Field('M'))
+			}
+			return nil, store.NewStorageError("failed to Create messages", err)
+		}
+		upserted, err := r.RowsAffected()
+		if err != nil {
+			return nil, store.NewStorageError("failed to Create messages", err)
+		}
+		if int(upserted) < len(batch) {
+			return nil, models.NewBadRequestError(
+				fmt.Sprintf("%d messages belong to another session", len(batch)-int(upserted)),
+			)
+		}
+		if onProgress != nil {
+			onProgress(end, len(pgMessages))
+		}
 	}
 
 	// copy the UUIDs back into the original messages
@@ -83,23 +177,25 @@ func putMessages(
 
 	// insert/update message metadata. isPrivileged is false because we are
 	// most likely being called by the PutMemory handler.
-	messages, err = putMessageMetadata(ctx, db, sessionID, messages, false)
+	messages, err = putMessageMetadata(ctx, tx, sessionID, messages, false)
 	if err != nil {
 		return nil, err
 	}
 
-	log.Debugf("putMessages completed for session %s with %d messages", sessionID, len(messages))
+	putLog.WithField("messages", len(messages)).Debug("putMessages completed")
 
 	return messages, nil
 }
 
-// getMessageList retrieves all messages for a sessionID with pagination.
+// getMessageList retrieves all messages for a sessionID with pagination. opts may be nil,
+// in which case all messages are listed oldest first.
 func getMessageList(
 	ctx context.Context,
 	db *bun.DB,
 	sessionID string,
 	currentPage int,
 	pageSize int,
+	opts *models.MessageListOptions,
 ) (*models.MessageListResponse, error) {
 	if sessionID == "" {
 		return nil, store.NewStorageError("sessionID cannot be empty", nil)
@@ -109,20 +205,14 @@ func getMessageList(
 	}
 
 	// Get count of all messages for this session
-	count, err := db.NewSelect().
-		Model(&MessageStoreSchema{}).
-		Where("session_id = ?", sessionID).
-		Count(ctx)
+	count, err := countMessages(ctx, db, sessionID, opts)
 	if err != nil {
-		return nil, store.NewStorageError("failed to get message count", err)
+		return nil, err
 	}
 
 	// Get messages
 	var messages []MessageStoreSchema
-	err = db.NewSelect().
-		Model(&messages).
-		Where("session_id = ?", sessionID).
-		OrderExpr("id ASC").
+	err = applyMessageListOptions(db.NewSelect().Model(&messages), sessionID, opts).
 		Limit(pageSize).
 		Offset((currentPage - 1) * pageSize).
 		Scan(ctx)
@@ -133,6 +223,105 @@ func getMessageList(
 		return nil, nil
 	}
 
+	r := &models.MessageListResponse{
+		Messages:   messageSchemaToMessages(messages),
+		TotalCount: count,
+		RowCount:   len(messages),
+	}
+
+	return r, nil
+}
+
+// getMessageListByCursor retrieves up to limit messages for a sessionID after cursor, oldest
+// first unless opts.Desc is set. Unlike getMessageList, pages are found using the primary
+// key rather than an offset, so they are cheap to fetch for large sessions and aren't
+// shifted by messages added between requests. NextCursor is set if there may be more
+// messages.
+func getMessageListByCursor(
+	ctx context.Context,
+	db *bun.DB,
+	sessionID string,
+	cursor int64,
+	limit int,
+	opts *models.MessageListOptions,
+) (*models.MessageListResponse, error) {
+	if sessionID == "" {
+		return nil, store.NewStorageError("sessionID cannot be empty", nil)
+	}
+	if cursor < 0 {
+		return nil, models.NewBadRequestError("cursor must not be negative")
+	}
+	if limit < 1 {
+		limit = DefaultMessageListLimit
+	}
+
+	count, err := countMessages(ctx, db, sessionID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []MessageStoreSchema
+	query := applyMessageListOptions(db.NewSelect().Model(&messages), sessionID, opts)
+	if opts != nil && opts.Desc {
+		// a zero cursor starts from the newest message
+		if cursor > 0 {
+			query = query.Where("id < ?", cursor)
+		}
+	} else {
+		query = query.Where("id > ?", cursor)
+	}
+	err = query.Limit(limit).Scan(ctx)
+	if err != nil {
+		return nil, store.NewStorageError("failed to get messages", err)
+	}
+
+	r := &models.MessageListResponse{
+		Messages:   messageSchemaToMessages(messages),
+		TotalCount: count,
+		RowCount:   len(messages),
+	}
+	if len(messages) == limit {
+		r.NextCursor = messages[len(messages)-1].ID
+	}
+
+	return r, nil
+}
+
+// countMessages returns the number of messages for a sessionID with the role in opts, if set
+func countMessages(
+	ctx context.Context,
+	db *bun.DB,
+	sessionID string,
+	opts *models.MessageListOptions,
+) (int, error) {
+	query := db.NewSelect().
+		Model(&MessageStoreSchema{}).
+		Where("session_id = ?", sessionID)
+	if opts != nil && opts.Role != "" {
+		query = query.Where("role = ?", opts.Role)
+	}
+	count, err := query.Count(ctx)
+	if err != nil {
+		return 0, store.NewStorageError("failed to get message count", err)
+	}
+	return count, nil
+}
+
+// applyMessageListOptions filters a message query to a sessionID and the role in opts, if
+// set, and orders it by id in the direction set by opts
+func applyMessageListOptions(
+	query *bun.SelectQuery,
+	sessionID string,
+	opts *models.MessageListOptions,
+) *bun.SelectQuery {
+	query = query.Where("session_id = ?", sessionID)
+	if opts != nil && opts.Role != "" {
+		query = query.Where("role = ?", opts.Role)
+	}
+	return query.OrderExpr("id " + getAscDesc(opts == nil || !opts.Desc))
+}
+
+func messageSchemaToMessages(messages []MessageStoreSchema) []models.Message {
 	messageList := make([]models.Message, len(messages))
 	for i, msg := range messages {
 		messageList[i] = models.Message{
@@ -142,16 +331,10 @@ func getMessageList(
 			Content:    msg.Content,
 			TokenCount: msg.TokenCount,
 			Metadata:   msg.Metadata,
+			IsSystem:   msg.IsSystem,
 		}
 	}
-
-	r := &models.MessageListResponse{
-		Messages:   messageList,
-		TotalCount: count,
-		RowCount:   len(messages),
-	}
-
-	return r, nil
+	return messageList
 }
 
 func getMessagesByUUID(
@@ -188,13 +371,262 @@ func getMessagesByUUID(
 			Content:    msg.Content,
 			TokenCount: msg.TokenCount,
 			Metadata:   msg.Metadata,
+			IsSystem:   msg.IsSystem,
 		}
 	}
 
 	return messageList, nil
 }
 
+// updateMessageContent updates the content of a session's message and marks its embedding
+// stale. Its token count is reset, as it no longer applies. The summaries that include the
+// message, those whose summary point is the message or a later one, are deleted along with
+// their embeddings, and whether any were deleted is returned.
+func updateMessageContent(
+	ctx context.Context,
+	db *bun.DB,
+	sessionID string,
+	messageUUID uuid.UUID,
+	content string,
+) (*models.Message, bool, error) {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, false, store.NewStorageError("failed to begin transaction", err)
+	}
+	defer rollbackOnError(tx)
+
+	message := MessageStoreSchema{}
+	err = tx.NewSelect().
+		Model(&message).
+		Where("session_id = ?", sessionID).
+		Where("uuid = ?", messageUUID).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, false, models.NewNotFoundError("message " + messageUUID.String())
+		}
+		return nil, false, store.NewStorageError("failed to get message", err)
+	}
+
+	message.Content = content
+	message.TokenCount = 0
+	_, err = tx.NewUpdate().
+		Model(&message).
+		Column("content", "token_count", "updated_at").
+		WherePK().
+		Exec(ctx)
+	if err != nil {
+		return nil, false, store.NewStorageError("failed to update message", err)
+	}
+
+	_, err = tx.NewUpdate().
+		Model((*MessageVectorStoreSchema)(nil)).
+		Set("is_embedded = false").
+		Where("message_uuid = ?", messageUUID).
+		Exec(ctx)
+	if err != nil {
+		return nil, false, store.NewStorageError("failed to mark message embedding stale", err)
+	}
+
+	// summaries are deleted rather than soft deleted as summary points are unique, and the
+	// messages will be summarized again
+	var summaryUUIDs []uuid.UUID
+	err = tx.NewSelect().
+		Model((*SummaryStoreSchema)(nil)).
+		Column("uuid").
+		Where("session_id = ?", sessionID).
+		Where(
+			"summary_point_uuid IN (SELECT uuid FROM message WHERE session_id = ? AND id >= ?)",
+			sessionID,
+			message.ID,
+		).
+		WhereAllWithDeleted().
+		Scan(ctx, &summaryUUIDs)
+	if err != nil {
+		return nil, false, store.NewStorageError("failed to get message summaries", err)
+	}
+	if len(summaryUUIDs) > 0 {
+		_, err = tx.NewDelete().
+			Model((*SummaryVectorStoreSchema)(nil)).
+			Where("summary_uuid IN (?)", bun.In(summaryUUIDs)).
+			WhereAllWithDeleted().
+			ForceDelete().
+			Exec(ctx)
+		if err != nil {
+			return nil, false, store.NewStorageError("failed to delete summary embeddings", err)
+		}
+
+		_, err = tx.NewDelete().
+			Model((*SummaryStoreSchema)(nil)).
+			Where("uuid IN (?)", bun.In(summaryUUIDs)).
+			WhereAllWithDeleted().
+			ForceDelete().
+			Exec(ctx)
+		if err != nil {
+			return nil, false, store.NewStorageError("failed to delete summaries", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, false, store.NewStorageError("failed to commit transaction", err)
+	}
+
+	return &models.Message{
+		UUID:       message.UUID,
+		CreatedAt:  message.CreatedAt,
+		UpdatedAt:  message.UpdatedAt,
+		Role:       message.Role,
+		Content:    message.Content,
+		TokenCount: message.TokenCount,
+		Metadata:   message.Metadata,
+		IsSystem:   message.IsSystem,
+	}, len(summaryUUIDs) > 0, nil
+}
+
+// deleteMessage soft deletes a session's message and deletes its embedding. A summary whose
+// summary point is the message is moved to the preceding message. If there's no preceding
+// message, or it's already the summary point of an earlier summary, the summary is deleted
+// along with its embedding, and the session will be summarized again.
+func deleteMessage(
+	ctx context.Context,
+	db *bun.DB,
+	sessionID string,
+	messageUUID uuid.UUID,
+) error {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return store.NewStorageError("failed to begin transaction", err)
+	}
+	defer rollbackOnError(tx)
+
+	message := MessageStoreSchema{}
+	err = tx.NewSelect().
+		Model(&message).
+		Where("session_id = ?", sessionID).
+		Where("uuid = ?", messageUUID).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.NewNotFoundError("message " + messageUUID.String())
+		}
+		return store.NewStorageError("failed to get message", err)
+	}
+
+	_, err = tx.NewDelete().
+		Model((*MessageVectorStoreSchema)(nil)).
+		Where("message_uuid = ?", messageUUID).
+		WhereAllWithDeleted().
+		ForceDelete().
+		Exec(ctx)
+	if err != nil {
+		return store.NewStorageError("failed to delete message embedding", err)
+	}
+
+	summary := SummaryStoreSchema{}
+	err = tx.NewSelect().
+		Model(&summary).
+		Where("summary_point_uuid = ?", messageUUID).
+		WhereAllWithDeleted().
+		Scan(ctx)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return store.NewStorageError("failed to get message summary", err)
+	default:
+		if err := moveSummaryPoint(ctx, tx, &summary, message.ID); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.NewDelete().
+		Model(&message).
+		WherePK().
+		Exec(ctx)
+	if err != nil {
+		return store.NewStorageError("failed to delete message", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return store.NewStorageError("failed to commit transaction", err)
+	}
+
+	return nil
+}
+
+// moveSummaryPoint moves the summary point of summary to the message preceding messageID,
+// or deletes the summary if it can't be moved
+func moveSummaryPoint(
+	ctx context.Context,
+	tx bun.Tx,
+	summary *SummaryStoreSchema,
+	messageID int64,
+) error {
+	var precedingUUIDs []uuid.UUID
+	err := tx.NewSelect().
+		Model((*MessageStoreSchema)(nil)).
+		Column("uuid").
+		Where("session_id = ?", summary.SessionID).
+		Where("id < ?", messageID).
+		Order("id DESC").
+		Limit(1).
+		Scan(ctx, &precedingUUIDs)
+	if err != nil {
+		return store.NewStorageError("failed to get preceding message", err)
+	}
+
+	// summary points are unique, so the summary can't be moved to an earlier summary's point
+	canMove := len(precedingUUIDs) > 0
+	if canMove {
+		isSummaryPoint, err := tx.NewSelect().
+			Model((*SummaryStoreSchema)(nil)).
+			Where("summary_point_uuid = ?", precedingUUIDs[0]).
+			WhereAllWithDeleted().
+			Exists(ctx)
+		if err != nil {
+			return store.NewStorageError("failed to get preceding message summary", err)
+		}
+		canMove = !isSummaryPoint
+	}
+
+	if canMove {
+		summary.SummaryPointUUID = precedingUUIDs[0]
+		_, err = tx.NewUpdate().
+			Model(summary).
+			Column("summary_point_uuid", "updated_at").
+			WherePK().
+			WhereAllWithDeleted().
+			Exec(ctx)
+		if err != nil {
+			return store.NewStorageError("failed to move summary point", err)
+		}
+		return nil
+	}
+
+	_, err = tx.NewDelete().
+		Model((*SummaryVectorStoreSchema)(nil)).
+		Where("summary_uuid = ?", summary.UUID).
+		WhereAllWithDeleted().
+		ForceDelete().
+		Exec(ctx)
+	if err != nil {
+		return store.NewStorageError("failed to delete summary embedding", err)
+	}
+
+	_, err = tx.NewDelete().
+		Model(summary).
+		WherePK().
+		WhereAllWithDeleted().
+		ForceDelete().
+		Exec(ctx)
+	if err != nil {
+		return store.NewStorageError("failed to delete summary", err)
+	}
+
+	return nil
+}
+
 // getMessages retrieves recent messages from the memory store. If lastNMessages is 0, the last SummaryPoint is retrieved.
+// request may be nil, in which case all messages are retrieved.
 func getMessages(
 	ctx context.Context,
 	db *bun.DB,
@@ -202,6 +634,7 @@ func getMessages(
 	memoryWindow int,
 	summary *models.Summary,
 	lastNMessages int,
+	request *models.GetMessagesRequest,
 ) ([]models.Message, error) {
 	if sessionID == "" {
 		return nil, store.NewStorageError("sessionID cannot be empty", nil)
@@ -210,12 +643,43 @@ func getMessages(
 		return nil, store.NewStorageError("memory.message_window must be greater than 0", nil)
 	}
 
+	if request == nil {
+		request = &models.GetMessagesRequest{}
+	}
+
 	var messages []MessageStoreSchema
 	var err error
-	if lastNMessages > 0 {
-		messages, err = fetchLastNMessages(ctx, db, sessionID, lastNMessages)
+	if request.MaxTokens > 0 {
+		// as when only lastNMessages is set, the summary point doesn't apply
+		if lastNMessages > 0 {
+			summary = nil
+		}
+		messages, err = fetchMessagesWithinTokens(
+			ctx,
+			db,
+			sessionID,
+			summary,
+			lastNMessages,
+			request.MaxTokens,
+			request.ExcludeSystemMessages,
+		)
+	} else if lastNMessages > 0 {
+		messages, err = fetchLastNMessages(
+			ctx,
+			db,
+			sessionID,
+			lastNMessages,
+			request.ExcludeSystemMessages,
+		)
 	} else {
-		messages, err = fetchMessagesAfterSummaryPoint(ctx, db, sessionID, summary, memoryWindow)
+		messages, err = fetchMessagesAfterSummaryPoint(
+			ctx,
+			db,
+			sessionID,
+			summary,
+			memoryWindow,
+			request.ExcludeSystemMessages,
+		)
 	}
 	if err != nil {
 		return nil, store.NewStorageError("failed to get messages", err)
@@ -241,6 +705,7 @@ func fetchMessagesAfterSummaryPoint(
 	sessionID string,
 	summary *models.Summary,
 	memoryWindow int,
+	excludeSystem bool,
 ) ([]MessageStoreSchema, error) {
 	var summaryPointIndex int64
 	var err error
@@ -261,6 +726,10 @@ func fetchMessagesAfterSummaryPoint(
 		query.Where("id > ?", summaryPointIndex)
 	}
 
+	if excludeSystem {
+		query.Where("is_system = false")
+	}
+
 	// Always limit to the memory window
 	query.Limit(memoryWindow)
 
@@ -274,6 +743,7 @@ func fetchLastNMessages(
 	db *bun.DB,
 	sessionID string,
 	lastNMessages int,
+	excludeSystem bool,
 ) ([]MessageStoreSchema, error) {
 	messages := make([]MessageStoreSchema, 0)
 	query := db.NewSelect().
@@ -282,6 +752,10 @@ func fetchLastNMessages(
 		Order("id DESC").
 		Limit(lastNMessages)
 
+	if excludeSystem {
+		query.Where("is_system = false")
+	}
+
 	err := query.Scan(ctx)
 
 	if err == nil && len(messages) > 0 {
@@ -291,6 +765,59 @@ func fetchLastNMessages(
 	return messages, err
 }
 
+// fetchMessagesWithinTokens retrieves the most recent messages for a session whose total
+// token count is at most maxTokens, in ascending order of ID. Only messages after the summary
+// point are retrieved if summary is set, and at most lastNMessages if lastNMessages > 0.
+func fetchMessagesWithinTokens(
+	ctx context.Context,
+	db *bun.DB,
+	sessionID string,
+	summary *models.Summary,
+	lastNMessages int,
+	maxTokens int,
+	excludeSystem bool,
+) ([]MessageStoreSchema, error) {
+	var summaryPointIndex int64
+	var err error
+	if summary != nil {
+		summaryPointIndex, err = getSummaryPointIndex(ctx, db, sessionID, summary.SummaryPointUUID)
+		if err != nil {
+			return nil, store.NewStorageError("unable to retrieve summary", nil)
+		}
+	}
+
+	// the tokens of each message and all newer messages
+	runningTokens := db.NewSelect().
+		Model((*MessageStoreSchema)(nil)).
+		Column("id").
+		ColumnExpr("SUM(token_count) OVER (ORDER BY id DESC) AS running_tokens").
+		Where("session_id = ?", sessionID).
+		Order("id DESC")
+	if summaryPointIndex > 0 {
+		runningTokens.Where("id > ?", summaryPointIndex)
+	}
+	if excludeSystem {
+		runningTokens.Where("is_system = false")
+	}
+	if lastNMessages > 0 {
+		runningTokens.Limit(lastNMessages)
+	}
+
+	withinTokens := db.NewSelect().
+		TableExpr("(?) AS running", runningTokens).
+		Column("id").
+		Where("running_tokens <= ?", maxTokens)
+
+	messages := make([]MessageStoreSchema, 0)
+	err = db.NewSelect().
+		Model(&messages).
+		Where("id IN (?)", withinTokens).
+		Order("id ASC").
+		Scan(ctx)
+
+	return messages, err
+}
+
 // getSummaryPointIndex retrieves the index of the last summary point for a session
 // This is a bit of a hack since UUIDs are not sortable.
 // If the SummaryPoint does not exist (for e.g. if it was deleted), returns 0.
@@ -324,3 +851,72 @@ func getSummaryPointIndex(
 
 	return message.ID, nil
 }
+
+// positionedMessageRow is a message row along with its position in the session's message stream
+type positionedMessageRow struct {
+	MessageStoreSchema `bun:",extend"`
+	AbsolutePosition   int `bun:"absolute_position,scanonly"`
+	TotalCount         int `bun:"total_count,scanonly"`
+}
+
+// GetMessagesForRoles returns a session's messages with one of the given roles, ordered by ID.
+// If includePositions is true, each message's position in the session's full message stream
+// is included. Positions are calculated with window functions over all of the session's
+// messages, before filtering by role.
+func GetMessagesForRoles(
+	ctx context.Context,
+	db *bun.DB,
+	sessionID string,
+	roles []string,
+	includePositions bool,
+) ([]models.PositionedMessage, error) {
+	if sessionID == "" {
+		return nil, store.NewStorageError("sessionID cannot be empty", nil)
+	}
+	if len(roles) == 0 {
+		return nil, models.NewBadRequestError("at least one role must be specified")
+	}
+
+	messagesQuery := db.NewSelect().
+		Model((*MessageStoreSchema)(nil)).
+		ColumnExpr("?TableColumns").
+		Where("session_id = ?", sessionID)
+	if includePositions {
+		messagesQuery.
+			ColumnExpr("ROW_NUMBER() OVER (ORDER BY m.id) AS absolute_position").
+			ColumnExpr("COUNT(*) OVER () AS total_count")
+	}
+
+	var rows []positionedMessageRow
+	err := db.NewSelect().
+		TableExpr("(?) AS m", messagesQuery).
+		Where("m.role IN (?)", bun.In(roles)).
+		OrderExpr("m.id ASC").
+		Scan(ctx, &rows)
+	if err != nil {
+		return nil, store.NewStorageError("failed to get messages for roles", err)
+	}
+
+	messages := make([]models.PositionedMessage, len(rows))
+	for i, row := range rows {
+		messages[i] = models.PositionedMessage{
+			Message: models.Message{
+				UUID:       row.UUID,
+				CreatedAt:  row.CreatedAt,
+				UpdatedAt:  row.UpdatedAt,
+				Role:       row.Role,
+				Content:    row.Content,
+				TokenCount: row.TokenCount,
+				Metadata:   row.Metadata,
+				IsSystem:   row.IsSystem,
+			},
+			AbsolutePosition: row.AbsolutePosition,
+		}
+		if row.TotalCount > 1 {
+			messages[i].RelativePosition = float64(row.AbsolutePosition-1) /
+				float64(row.TotalCount-1)
+		}
+	}
+
+	return messages, nil
+}
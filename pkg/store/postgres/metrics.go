@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/uptrace/bun"
+
+	"github.com/getzep/zep/pkg/metrics"
+)
+
+var (
+	queryDuration = metrics.NewHistogramVec(
+		"zep_store_query_duration_seconds",
+		"Latency of store queries by operation",
+		metrics.DefaultBuckets,
+		"operation",
+	)
+	queryErrors = metrics.NewCounterVec(
+		"zep_store_query_errors_total",
+		"Failed store queries by operation. Queries returning no rows aren't counted.",
+		"operation",
+	)
+)
+
+var _ bun.QueryHook = (*metricsQueryHook)(nil)
+
+// metricsQueryHook records the latency and errors of queries
+type metricsQueryHook struct{}
+
+func (*metricsQueryHook) BeforeQuery(ctx context.Context, _ *bun.QueryEvent) context.Context {
+	return ctx
+}
+
+func (*metricsQueryHook) AfterQuery(_ context.Context, event *bun.QueryEvent) {
+	operation := event.Operation()
+	queryDuration.Observe(time.Since(event.StartTime).Seconds(), operation)
+	if event.Err != nil && !errors.Is(event.Err, sql.ErrNoRows) {
+		queryErrors.Inc(operation)
+	}
+}
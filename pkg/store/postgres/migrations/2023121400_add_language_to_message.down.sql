@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS message_search_vector_idx;
+
+--bun:split
+ALTER TABLE message
+    DROP COLUMN IF EXISTS search_vector,
+    DROP COLUMN IF EXISTS language;
+
+--bun:split
+ALTER TABLE session
+    DROP COLUMN IF EXISTS language;
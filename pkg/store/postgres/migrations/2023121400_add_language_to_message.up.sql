@@ -0,0 +1,35 @@
+-- language columns are regconfig so that only text search configurations known to
+-- Postgres can be stored. to_tsvector(regconfig, text) is immutable, which allows it to
+-- be used in a generated column.
+DO $$
+BEGIN
+    IF EXISTS(
+        SELECT
+        FROM
+            pg_tables
+        WHERE
+            tablename = 'session') THEN
+    ALTER TABLE session
+        ADD COLUMN IF NOT EXISTS language regconfig NOT NULL DEFAULT 'english';
+END IF;
+END
+$$;
+
+--bun:split
+DO $$
+BEGIN
+    IF EXISTS(
+        SELECT
+        FROM
+            pg_tables
+        WHERE
+            tablename = 'message') THEN
+    ALTER TABLE message
+        ADD COLUMN IF NOT EXISTS language regconfig NOT NULL DEFAULT 'english';
+    ALTER TABLE message
+        ADD COLUMN IF NOT EXISTS search_vector tsvector
+            GENERATED ALWAYS AS (to_tsvector(language, coalesce(content, ''))) STORED;
+    CREATE INDEX IF NOT EXISTS message_search_vector_idx ON message USING GIN (search_vector);
+END IF;
+END
+$$;
@@ -0,0 +1,2 @@
+ALTER TABLE message
+    DROP COLUMN IF EXISTS is_system;
@@ -0,0 +1,13 @@
+DO $$
+BEGIN
+    IF EXISTS(
+        SELECT
+        FROM
+            pg_tables
+        WHERE
+            tablename = 'message') THEN
+    ALTER TABLE message
+        ADD COLUMN IF NOT EXISTS is_system boolean NOT NULL DEFAULT false;
+END IF;
+END
+$$;
@@ -0,0 +1,2 @@
+ALTER TABLE document_collection
+    DROP COLUMN IF EXISTS embedding_service;
@@ -0,0 +1,13 @@
+DO $$
+BEGIN
+    IF EXISTS(
+        SELECT
+        FROM
+            pg_tables
+        WHERE
+            tablename = 'document_collection') THEN
+    ALTER TABLE document_collection
+        ADD COLUMN IF NOT EXISTS embedding_service text NOT NULL DEFAULT '';
+END IF;
+END
+$$;
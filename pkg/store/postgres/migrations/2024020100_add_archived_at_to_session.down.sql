@@ -0,0 +1,2 @@
+ALTER TABLE session
+    DROP COLUMN IF EXISTS archived_at;
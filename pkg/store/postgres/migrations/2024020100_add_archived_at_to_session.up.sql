@@ -0,0 +1,13 @@
+DO $$
+BEGIN
+    IF EXISTS(
+        SELECT
+        FROM
+            pg_tables
+        WHERE
+            tablename = 'session') THEN
+    ALTER TABLE session
+        ADD COLUMN IF NOT EXISTS archived_at timestamptz;
+END IF;
+END
+$$;
@@ -0,0 +1,16 @@
+DROP INDEX IF EXISTS session_project_id_idx;
+
+--bun:split
+DROP INDEX IF EXISTS users_project_id_idx;
+
+--bun:split
+ALTER TABLE session
+    DROP COLUMN IF EXISTS project_id;
+
+--bun:split
+ALTER TABLE users
+    DROP COLUMN IF EXISTS project_id;
+
+--bun:split
+ALTER TABLE document_collection
+    DROP COLUMN IF EXISTS project_id;
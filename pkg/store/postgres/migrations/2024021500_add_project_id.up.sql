@@ -0,0 +1,45 @@
+DO $$
+BEGIN
+    IF EXISTS(
+        SELECT
+        FROM
+            pg_tables
+        WHERE
+            tablename = 'session') THEN
+    ALTER TABLE session
+        ADD COLUMN IF NOT EXISTS project_id text NOT NULL DEFAULT 'default';
+    CREATE INDEX IF NOT EXISTS session_project_id_idx ON session(project_id);
+END IF;
+END
+$$;
+
+--bun:split
+DO $$
+BEGIN
+    IF EXISTS(
+        SELECT
+        FROM
+            pg_tables
+        WHERE
+            tablename = 'users') THEN
+    ALTER TABLE users
+        ADD COLUMN IF NOT EXISTS project_id text NOT NULL DEFAULT 'default';
+    CREATE INDEX IF NOT EXISTS users_project_id_idx ON users(project_id);
+END IF;
+END
+$$;
+
+--bun:split
+DO $$
+BEGIN
+    IF EXISTS(
+        SELECT
+        FROM
+            pg_tables
+        WHERE
+            tablename = 'document_collection') THEN
+    ALTER TABLE document_collection
+        ADD COLUMN IF NOT EXISTS project_id text NOT NULL DEFAULT 'default';
+END IF;
+END
+$$;
@@ -0,0 +1,25 @@
+DO $$
+DECLARE
+    collection_table text;
+BEGIN
+    IF EXISTS(
+        SELECT
+        FROM
+            pg_tables
+        WHERE
+            tablename = 'document_collection') THEN
+    FOR collection_table IN
+    SELECT
+        table_name
+    FROM
+        document_collection LOOP
+            EXECUTE format('ALTER TABLE IF EXISTS %I DROP COLUMN IF EXISTS parent_document_id', collection_table);
+            EXECUTE format('ALTER TABLE IF EXISTS %I DROP COLUMN IF EXISTS chunk_index', collection_table);
+    END LOOP;
+END IF;
+END
+$$;
+
+--bun:split
+ALTER TABLE document_collection
+    DROP COLUMN IF EXISTS chunking;
@@ -0,0 +1,43 @@
+DO $$
+BEGIN
+    IF EXISTS(
+        SELECT
+        FROM
+            pg_tables
+        WHERE
+            tablename = 'document_collection') THEN
+    ALTER TABLE document_collection
+        ADD COLUMN IF NOT EXISTS chunking jsonb;
+END IF;
+END
+$$;
+
+--bun:split
+DO $$
+DECLARE
+    collection_table text;
+BEGIN
+    IF EXISTS(
+        SELECT
+        FROM
+            pg_tables
+        WHERE
+            tablename = 'document_collection') THEN
+    FOR collection_table IN
+    SELECT
+        table_name
+    FROM
+        document_collection LOOP
+            IF EXISTS(
+                SELECT
+                FROM
+                    pg_tables
+                WHERE
+                    tablename = collection_table) THEN
+            EXECUTE format('ALTER TABLE %I ADD COLUMN IF NOT EXISTS parent_document_id text', collection_table);
+            EXECUTE format('ALTER TABLE %I ADD COLUMN IF NOT EXISTS chunk_index bigint NOT NULL DEFAULT 0', collection_table);
+        END IF;
+    END LOOP;
+END IF;
+END
+$$;
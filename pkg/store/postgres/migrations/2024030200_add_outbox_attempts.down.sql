@@ -0,0 +1,6 @@
+ALTER TABLE IF EXISTS event_outbox
+    DROP COLUMN IF EXISTS attempts;
+
+--bun:split
+ALTER TABLE IF EXISTS event_outbox
+    DROP COLUMN IF EXISTS error;
@@ -0,0 +1,15 @@
+DO $$
+BEGIN
+    IF EXISTS(
+        SELECT
+        FROM
+            pg_tables
+        WHERE
+            tablename = 'event_outbox') THEN
+    ALTER TABLE event_outbox
+        ADD COLUMN IF NOT EXISTS attempts bigint NOT NULL DEFAULT 0;
+    ALTER TABLE event_outbox
+        ADD COLUMN IF NOT EXISTS error varchar;
+END IF;
+END
+$$;
@@ -1,9 +1,15 @@
 package migrations
 
 import (
+	"bufio"
 	"context"
+	"database/sql"
 	"embed"
+	"errors"
 	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
 
 	"github.com/getzep/zep/internal"
 	"github.com/uptrace/bun"
@@ -15,21 +21,31 @@ var log = internal.GetLogger()
 //go:embed *.sql
 var sqlMigrations embed.FS
 
-func Migrate(ctx context.Context, db *bun.DB) error {
+// migrationsTable is the bun default table used to record applied migrations.
+const migrationsTable = "bun_migrations"
+
+// MigrateDB applies all pending migrations. If dryRun is true, the SQL statements of the
+// pending migrations are returned in the order they would run and nothing is executed: no
+// transaction is opened and the migration state tables are neither created nor updated.
+func MigrateDB(ctx context.Context, db *bun.DB, dryRun bool) ([]string, error) {
+	if dryRun {
+		return pendingMigrationSQL(ctx, db)
+	}
+
 	migrations := migrate.NewMigrations()
 
 	if err := migrations.Discover(sqlMigrations); err != nil {
-		return fmt.Errorf("failed to discover migrations: %w", err)
+		return nil, fmt.Errorf("failed to discover migrations: %w", err)
 	}
 
 	migrator := migrate.NewMigrator(db, migrations)
 
 	if err := migrator.Init(ctx); err != nil {
-		return fmt.Errorf("failed to init migrator: %w", err)
+		return nil, fmt.Errorf("failed to init migrator: %w", err)
 	}
 
 	if err := migrator.Lock(ctx); err != nil {
-		return fmt.Errorf("failed to lock migrator: %w", err)
+		return nil, fmt.Errorf("failed to lock migrator: %w", err)
 	}
 	defer migrator.Unlock(ctx) //nolint:errcheck
 
@@ -46,9 +62,144 @@ func Migrate(ctx context.Context, db *bun.DB) error {
 
 	if group.IsZero() {
 		log.Info("there are no new migrations to run (database is up to date)")
-		return nil
+		return nil, nil
 	}
 	log.Infof("migrated to %s\n", group)
 
-	return nil
+	return nil, nil
+}
+
+// pendingMigrationSQL returns the statements of all up migrations that have not yet been
+// applied. It only reads from the database.
+func pendingMigrationSQL(ctx context.Context, db *bun.DB) ([]string, error) {
+	files, err := pendingMigrationFiles(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	statements := make([]string, 0)
+	for _, file := range files {
+		s, err := readMigrationStatements(file)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, s...)
+	}
+
+	return statements, nil
+}
+
+// PendingMigrations returns the names of the migrations that have not yet been applied, in
+// the order they would run. It only reads from the database.
+func PendingMigrations(ctx context.Context, db *bun.DB) ([]string, error) {
+	files, err := pendingMigrationFiles(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		name, _, _ := strings.Cut(file, "_")
+		names = append(names, name)
+	}
+
+	return names, nil
+}
+
+// pendingMigrationFiles returns the up migration files that have not yet been applied,
+// sorted in the order they would run
+func pendingMigrationFiles(ctx context.Context, db *bun.DB) ([]string, error) {
+	applied, err := appliedMigrationNames(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := fs.Glob(sqlMigrations, "*.up.sql")
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover migrations: %w", err)
+	}
+	sort.Strings(files)
+
+	pending := make([]string, 0)
+	for _, file := range files {
+		name, _, _ := strings.Cut(file, "_")
+		if !applied[name] {
+			pending = append(pending, file)
+		}
+	}
+
+	return pending, nil
+}
+
+// appliedMigrationNames returns the names of migrations recorded in the migrations table.
+// If the table does not exist, no migrations have been applied.
+func appliedMigrationNames(ctx context.Context, db *bun.DB) (map[string]bool, error) {
+	var table sql.NullString
+	err := db.NewRaw("SELECT to_regclass(?)::text", migrationsTable).Scan(ctx, &table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for migrations table: %w", err)
+	}
+
+	applied := make(map[string]bool)
+	if !table.Valid {
+		return applied, nil
+	}
+
+	var names []string
+	err = db.NewSelect().
+		Column("name").
+		Table(migrationsTable).
+		Scan(ctx, &names)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+	for _, name := range names {
+		applied[name] = true
+	}
+
+	return applied, nil
+}
+
+// readMigrationStatements splits a migration file into statements on `--bun:split`
+// directives, mirroring how bun executes SQL migrations. Each statement is terminated
+// with a semicolon so that the result can be pasted into psql.
+func readMigrationStatements(file string) ([]string, error) {
+	f, err := sqlMigrations.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open migration %s: %w", file, err)
+	}
+	defer f.Close()
+
+	var statements []string
+	var current strings.Builder
+	flush := func() {
+		s := strings.TrimSpace(current.String())
+		current.Reset()
+		if s == "" {
+			return
+		}
+		if !strings.HasSuffix(s, ";") {
+			s += ";"
+		}
+		statements = append(statements, s)
+	}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "--bun:") {
+			if line == "--bun:split" {
+				flush()
+			}
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read migration %s: %w", file, err)
+	}
+	flush()
+
+	return statements, nil
 }
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadMigrationStatements(t *testing.T) {
+	statements, err := readMigrationStatements(
+		"20230822100800_add_user_uuid_to_session_schema.up.sql",
+	)
+	require.NoError(t, err)
+
+	// the migration has two --bun:split directives
+	assert.Len(t, statements, 3)
+	for _, s := range statements {
+		assert.NotContains(t, s, "--bun:")
+		assert.True(t, strings.HasSuffix(s, ";"), "statement should be terminated: %s", s)
+	}
+	assert.Contains(t, statements[0], "ADD COLUMN user_id")
+	assert.Contains(t, statements[1], "CREATE INDEX session_user_id_idx")
+	assert.Contains(t, statements[2], "ADD COLUMN id BIGSERIAL")
+}
@@ -0,0 +1,227 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/store"
+)
+
+const (
+	// outboxDispatchBatchSize is the number of outbox events dispatched per transaction
+	outboxDispatchBatchSize = 100
+	// outboxMaxAttempts is the number of times an event fails to dispatch before it's
+	// abandoned
+	outboxMaxAttempts = 10
+)
+
+// OutboxEventSchema is an event written to the outbox in the transaction that raised it. The
+// outbox dispatcher delivers it once the transaction is committed, so that events aren't
+// lost if the process stops before publishing them.
+type OutboxEventSchema struct {
+	bun.BaseModel `bun:"table:event_outbox,alias:eo" yaml:"-"`
+
+	UUID uuid.UUID `bun:",pk,type:uuid,default:gen_random_uuid()"`
+	// ID orders the events for dispatch
+	ID        int64     `bun:",autoincrement"`
+	CreatedAt time.Time `bun:"type:timestamptz,notnull,default:current_timestamp"`
+	SessionID string    `bun:",notnull"`
+	// SessionEvent, if set, is published with the event producer
+	SessionEvent *models.SessionEvent `bun:"type:jsonb,nullzero"`
+	// WebhookEvent, if set, is sent to the webhooks
+	WebhookEvent *models.WebhookEvent `bun:"type:jsonb,nullzero"`
+	// Attempts is the number of times the event failed to dispatch, and Error the last
+	// failure. Events are abandoned after outboxMaxAttempts, and left in the outbox.
+	Attempts int    `bun:",notnull,default:0"`
+	Error    string `bun:",nullzero"`
+}
+
+func (*OutboxEventSchema) AfterCreateTable(
+	ctx context.Context,
+	query *bun.CreateTableQuery,
+) error {
+	_, err := query.DB().NewCreateIndex().
+		Model((*OutboxEventSchema)(nil)).
+		Index("event_outbox_id_idx").
+		Column("id").
+		IfNotExists().
+		Exec(ctx)
+	return err
+}
+
+// DispatchOutbox publishes the events in the outbox, oldest first, and deletes those that are
+// acknowledged. Events that fail to publish are left in the outbox for the next dispatch,
+// along with the later events of their session, so that a session's events stay in order.
+// The events of other sessions are dispatched. An event that fails outboxMaxAttempts times is
+// abandoned, so that it doesn't hold back its session's later events forever.
+// Events are delivered at least once: if the process stops after publishing events and
+// before deleting them, or an event's publication succeeds and its webhook notification
+// fails, they're published again.
+func (pms *PostgresMemoryStore) DispatchOutbox(
+	ctx context.Context,
+	appState *models.AppState,
+) (int, error) {
+	cursor := &outboxCursor{blocked: make(map[string]bool)}
+	var dispatched int
+	for {
+		delivered, selected, err := pms.dispatchOutboxBatch(ctx, appState, cursor)
+		dispatched += delivered
+		if err != nil || selected < outboxDispatchBatchSize {
+			return dispatched, err
+		}
+	}
+}
+
+// outboxCursor is a dispatch's position in the outbox. Sessions with an event that failed
+// are blocked for the rest of the dispatch.
+type outboxCursor struct {
+	afterID int64
+	blocked map[string]bool
+}
+
+// dispatchOutboxBatch publishes the oldest events after the cursor that aren't of blocked
+// sessions, and deletes those that are acknowledged. It returns the number of events
+// delivered and selected. Events locked by a concurrent dispatcher are skipped.
+func (pms *PostgresMemoryStore) dispatchOutboxBatch(
+	ctx context.Context,
+	appState *models.AppState,
+	cursor *outboxCursor,
+) (int, int, error) {
+	tx, err := pms.Client.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return 0, 0, store.NewStorageError("failed to begin transaction", err)
+	}
+	defer rollbackOnError(tx)
+
+	var events []OutboxEventSchema
+	query := tx.NewSelect().
+		Model(&events).
+		Where("id > ?", cursor.afterID).
+		Where("attempts < ?", outboxMaxAttempts).
+		OrderExpr("id ASC").
+		Limit(outboxDispatchBatchSize).
+		For("UPDATE SKIP LOCKED")
+	if len(cursor.blocked) > 0 {
+		blocked := make([]string, 0, len(cursor.blocked))
+		for sessionID := range cursor.blocked {
+			blocked = append(blocked, sessionID)
+		}
+		query = query.Where("session_id NOT IN (?)", bun.In(blocked))
+	}
+	if err := query.Scan(ctx); err != nil {
+		return 0, 0, store.NewStorageError("failed to get outbox events", err)
+	}
+	if len(events) == 0 {
+		return 0, 0, nil
+	}
+	cursor.afterID = events[len(events)-1].ID
+
+	var delivered []OutboxEventSchema
+	for i := range events {
+		event := &events[i]
+		if cursor.blocked[event.SessionID] {
+			continue
+		}
+		deliverErr := pms.deliverOutboxEvent(ctx, appState, event)
+		if deliverErr == nil {
+			delivered = append(delivered, *event)
+			continue
+		}
+
+		cursor.blocked[event.SessionID] = true
+		event.Attempts++
+		if event.Attempts >= outboxMaxAttempts {
+			log.Errorf(
+				"abandoning outbox event %s after %d attempts: %v",
+				event.UUID,
+				event.Attempts,
+				deliverErr,
+			)
+		} else {
+			log.Errorf("failed to dispatch outbox event %s: %v", event.UUID, deliverErr)
+		}
+		_, err = tx.NewUpdate().
+			Model(event).
+			Set("attempts = ?", event.Attempts).
+			Set("error = ?", deliverErr.Error()).
+			WherePK().
+			Exec(ctx)
+		if err != nil {
+			return 0, 0, store.NewStorageError("failed to update outbox event", err)
+		}
+	}
+
+	if len(delivered) > 0 {
+		_, err = tx.NewDelete().
+			Model(&delivered).
+			WherePK().
+			Exec(ctx)
+		if err != nil {
+			return 0, 0, store.NewStorageError("failed to delete outbox events", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, store.NewStorageError("failed to commit transaction", err)
+	}
+
+	return len(delivered), len(events), nil
+}
+
+// deliverOutboxEvent publishes an outbox event with the EventProducer and sends it to the
+// webhooks, waiting for both to acknowledge it
+func (pms *PostgresMemoryStore) deliverOutboxEvent(
+	ctx context.Context,
+	appState *models.AppState,
+	event *OutboxEventSchema,
+) error {
+	if event.SessionEvent != nil && appState.EventProducer != nil {
+		if err := appState.EventProducer.PublishSync(ctx, event.SessionEvent); err != nil {
+			return err
+		}
+	}
+	if event.WebhookEvent != nil && pms.Webhooks != nil {
+		if err := pms.Webhooks.NotifySync(ctx, event.WebhookEvent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeOutbox writes events to the outbox for the operations that don't write them in their
+// own transaction, such as storing summaries and deleting sessions. Once written, events are
+// dispatched as other outbox events are, but they're lost if the process stops after the
+// operation is committed and before they're written. If they can't be written, they're sent
+// directly, without waiting for acknowledgement.
+func (pms *PostgresMemoryStore) writeOutbox(
+	ctx context.Context,
+	appState *models.AppState,
+	event *OutboxEventSchema,
+) {
+	if event.WebhookEvent != nil {
+		// the event's UUID is set now, so that it's the same if it's dispatched more than once
+		if event.WebhookEvent.UUID == uuid.Nil {
+			event.WebhookEvent.UUID = uuid.New()
+		}
+		if event.WebhookEvent.CreatedAt.IsZero() {
+			event.WebhookEvent.CreatedAt = time.Now().UTC()
+		}
+	}
+
+	_, err := pms.Client.NewInsert().Model(event).Exec(ctx)
+	if err == nil {
+		return
+	}
+	log.Errorf("failed to write outbox event, sending it directly: %v", err)
+	if event.SessionEvent != nil && appState != nil && appState.EventProducer != nil {
+		appState.EventProducer.Publish(event.SessionEvent)
+	}
+	if event.WebhookEvent != nil && pms.Webhooks != nil {
+		pms.Webhooks.Notify(ctx, event.WebhookEvent)
+	}
+}
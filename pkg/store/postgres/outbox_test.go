@@ -0,0 +1,274 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/testutils"
+)
+
+type recordingEventProducer struct {
+	mu     sync.Mutex
+	events []*models.SessionEvent
+	err    error
+}
+
+func (p *recordingEventProducer) Publish(event *models.SessionEvent) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, event)
+}
+
+func (p *recordingEventProducer) PublishSync(_ context.Context, event *models.SessionEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	p.events = append(p.events, event)
+	return nil
+}
+
+func (p *recordingEventProducer) Close() error { return nil }
+
+type recordingWebhookNotifier struct {
+	mu     sync.Mutex
+	events []*models.WebhookEvent
+	err    error
+	// failUUID is the UUID of an event that fails to be sent
+	failUUID uuid.UUID
+}
+
+func (n *recordingWebhookNotifier) Notify(_ context.Context, event *models.WebhookEvent) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.events = append(n.events, event)
+}
+
+func (n *recordingWebhookNotifier) NotifySync(
+	_ context.Context,
+	event *models.WebhookEvent,
+) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.err != nil {
+		return n.err
+	}
+	if n.failUUID != uuid.Nil && event.UUID == n.failUUID {
+		return errors.New("webhook unavailable")
+	}
+	n.events = append(n.events, event)
+	return nil
+}
+
+// nopTaskPublisher accepts tasks without publishing them
+type nopTaskPublisher struct {
+	models.TaskPublisher
+}
+
+func (nopTaskPublisher) Publish(context.Context, models.TaskTopic, map[string]string, any) error {
+	return nil
+}
+
+func TestOutbox(t *testing.T) {
+	producer := &recordingEventProducer{}
+	webhooks := &recordingWebhookNotifier{}
+	outboxAppState := *appState
+	outboxAppState.EventProducer = producer
+
+	pms, err := NewPostgresMemoryStore(&outboxAppState, testDB)
+	require.NoError(t, err)
+	pms.Webhooks = webhooks
+
+	sessionID := testutils.GenerateRandomString(10)
+	messages := []models.Message{
+		{Role: "human", Content: "Hello"},
+		{Role: "ai", Content: "Hi there"},
+	}
+
+	result, err := pms.putMemoryMessages(testCtx, &outboxAppState, sessionID, messages, nil, nil, true)
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+
+	// events aren't published until they're dispatched
+	assert.Empty(t, producer.events)
+	assert.Empty(t, webhooks.events)
+
+	var outboxed []OutboxEventSchema
+	err = testDB.NewSelect().
+		Model(&outboxed).
+		Where("session_id = ?", sessionID).
+		OrderExpr("id ASC").
+		Scan(testCtx)
+	require.NoError(t, err)
+	require.Len(t, outboxed, 2)
+
+	dispatched, err := pms.DispatchOutbox(testCtx, &outboxAppState)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, dispatched, 2)
+
+	require.Len(t, webhooks.events, 1)
+	assert.Equal(t, models.WebhookEventSessionCreated, webhooks.events[0].Type)
+	assert.Equal(t, sessionID, webhooks.events[0].SessionID)
+	assert.Equal(t, outboxed[0].WebhookEvent.UUID, webhooks.events[0].UUID)
+
+	require.Len(t, producer.events, 1)
+	assert.Equal(t, models.SessionEventMessages, producer.events[0].Type)
+	assert.Equal(t, sessionID, producer.events[0].SessionID)
+	require.Len(t, producer.events[0].Messages, 2)
+	assert.Equal(t, result[0].UUID, producer.events[0].Messages[0].UUID)
+
+	count, err := testDB.NewSelect().
+		Model((*OutboxEventSchema)(nil)).
+		Where("session_id = ?", sessionID).
+		Count(testCtx)
+	require.NoError(t, err)
+	assert.Zero(t, count, "dispatched events should be deleted")
+
+	// the session exists and the messages aren't published, so no events are written
+	_, err = pms.putMemoryMessages(testCtx, &outboxAppState, sessionID, messages[:1], nil, nil, false)
+	require.NoError(t, err)
+	count, err = testDB.NewSelect().
+		Model((*OutboxEventSchema)(nil)).
+		Where("session_id = ?", sessionID).
+		Count(testCtx)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	t.Run("failed events are left for the next dispatch", func(t *testing.T) {
+		producer.events, webhooks.events = nil, nil
+		webhooks.err = errors.New("webhooks unavailable")
+
+		sessionID := testutils.GenerateRandomString(10)
+		_, err := pms.putMemoryMessages(testCtx, &outboxAppState, sessionID, messages, nil, nil, true)
+		require.NoError(t, err)
+
+		_, err = pms.DispatchOutbox(testCtx, &outboxAppState)
+		require.NoError(t, err)
+		// the session's messages event follows its failed webhook event, so it's held back
+		assert.Empty(t, producer.events)
+		count, err := testDB.NewSelect().
+			Model((*OutboxEventSchema)(nil)).
+			Where("session_id = ?", sessionID).
+			Count(testCtx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		webhooks.err = nil
+		_, err = pms.DispatchOutbox(testCtx, &outboxAppState)
+		require.NoError(t, err)
+		require.Len(t, webhooks.events, 1)
+		require.Len(t, producer.events, 1)
+		count, err = testDB.NewSelect().
+			Model((*OutboxEventSchema)(nil)).
+			Where("session_id = ?", sessionID).
+			Count(testCtx)
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+
+	t.Run("failed events don't hold back other sessions", func(t *testing.T) {
+		producer.events, webhooks.events = nil, nil
+		blockedSessionID := testutils.GenerateRandomString(10)
+		otherSessionID := testutils.GenerateRandomString(10)
+
+		// the blocked session's events fill a batch, and its first event always fails
+		var events []OutboxEventSchema
+		for i := 0; i <= outboxDispatchBatchSize; i++ {
+			events = append(events, OutboxEventSchema{
+				UUID:      uuid.New(),
+				SessionID: blockedSessionID,
+				WebhookEvent: &models.WebhookEvent{
+					UUID:      uuid.New(),
+					Type:      models.WebhookEventSessionCreated,
+					SessionID: blockedSessionID,
+				},
+			})
+		}
+		events = append(events, OutboxEventSchema{
+			SessionID: otherSessionID,
+			WebhookEvent: &models.WebhookEvent{
+				UUID:      uuid.New(),
+				Type:      models.WebhookEventSessionCreated,
+				SessionID: otherSessionID,
+			},
+		})
+		_, err := testDB.NewInsert().Model(&events).Exec(testCtx)
+		require.NoError(t, err)
+		webhooks.failUUID = events[0].WebhookEvent.UUID
+		defer func() { webhooks.failUUID = uuid.Nil }()
+
+		countEvents := func(sessionID string) int {
+			count, err := testDB.NewSelect().
+				Model((*OutboxEventSchema)(nil)).
+				Where("session_id = ?", sessionID).
+				Count(testCtx)
+			require.NoError(t, err)
+			return count
+		}
+
+		dispatched, err := pms.DispatchOutbox(testCtx, &outboxAppState)
+		require.NoError(t, err)
+		assert.Equal(t, 1, dispatched)
+		require.Len(t, webhooks.events, 1)
+		assert.Equal(t, otherSessionID, webhooks.events[0].SessionID)
+		assert.Zero(t, countEvents(otherSessionID))
+		assert.Equal(t, len(events)-1, countEvents(blockedSessionID))
+
+		// the failing event is abandoned once it reaches the maximum attempts
+		for i := 1; i < outboxMaxAttempts; i++ {
+			dispatched, err = pms.DispatchOutbox(testCtx, &outboxAppState)
+			require.NoError(t, err)
+			assert.Zero(t, dispatched)
+		}
+		dispatched, err = pms.DispatchOutbox(testCtx, &outboxAppState)
+		require.NoError(t, err)
+		assert.Equal(t, outboxDispatchBatchSize, dispatched)
+
+		var abandoned []OutboxEventSchema
+		err = testDB.NewSelect().
+			Model(&abandoned).
+			Where("session_id = ?", blockedSessionID).
+			Scan(testCtx)
+		require.NoError(t, err)
+		require.Len(t, abandoned, 1)
+		assert.Equal(t, events[0].UUID, abandoned[0].UUID)
+		assert.Equal(t, outboxMaxAttempts, abandoned[0].Attempts)
+		assert.Equal(t, "webhook unavailable", abandoned[0].Error)
+
+		_, err = testDB.NewDelete().Model(&abandoned).WherePK().Exec(testCtx)
+		require.NoError(t, err)
+	})
+
+	t.Run("summaries and deleted sessions are written to the outbox", func(t *testing.T) {
+		producer.events, webhooks.events = nil, nil
+		summaryAppState := outboxAppState
+		summaryAppState.TaskPublisher = nopTaskPublisher{}
+
+		err := pms.PutSummary(testCtx, &summaryAppState, sessionID, &models.Summary{
+			Content:          "a greeting",
+			SummaryPointUUID: result[1].UUID,
+		})
+		require.NoError(t, err)
+		err = pms.DeleteSession(testCtx, sessionID)
+		require.NoError(t, err)
+		assert.Empty(t, producer.events)
+		assert.Empty(t, webhooks.events)
+
+		_, err = pms.DispatchOutbox(testCtx, &outboxAppState)
+		require.NoError(t, err)
+		require.Len(t, producer.events, 1)
+		assert.Equal(t, models.SessionEventSummary, producer.events[0].Type)
+		require.Len(t, webhooks.events, 2)
+		assert.Equal(t, models.WebhookEventSummaryCreated, webhooks.events[0].Type)
+		assert.Equal(t, models.WebhookEventSessionDeleted, webhooks.events[1].Type)
+		assert.NotEqual(t, uuid.Nil, webhooks.events[1].UUID)
+	})
+}
@@ -0,0 +1,293 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pgvector/pgvector-go"
+	"github.com/uptrace/bun"
+)
+
+// partitionedTables are the tables hash partitioned by session ID when
+// store.postgres.message_partitions is set
+var partitionedTables = []string{"message", "message_embedding"}
+
+// partitionedMessageSchema is the message table partitioned by session ID. The primary key of
+// a partitioned table must include the partition key, so it doesn't keep message UUIDs unique
+// across sessions, which lookups by message UUID rely on. The message_uuid table does instead.
+type partitionedMessageSchema struct {
+	MessageStoreSchema `bun:",extend"`
+
+	SessionID string `bun:",pk,notnull"`
+}
+
+// partitionedMessageVectorStoreSchema is the message_embedding table partitioned by session
+// ID. It references messages by UUID and session ID, and its unique constraint on message_uuid
+// includes the session ID. The constraint keeps the name Postgres gives it in the unpartitioned
+// table, so that upserts may target it in both.
+//
+// Its columns must be those of MessageVectorStoreSchema.
+type partitionedMessageVectorStoreSchema struct {
+	bun.BaseModel `bun:"table:message_embedding,alias:me"`
+
+	UUID        uuid.UUID           `bun:",pk,type:uuid,default:gen_random_uuid()"`
+	CreatedAt   time.Time           `bun:"type:timestamptz,notnull,default:current_timestamp"`
+	UpdatedAt   time.Time           `bun:"type:timestamptz,nullzero,default:current_timestamp"`
+	DeletedAt   time.Time           `bun:"type:timestamptz,soft_delete,nullzero"`
+	SessionID   string              `bun:",pk,notnull,unique:message_embedding_message_uuid_key"`
+	MessageUUID uuid.UUID           `bun:"type:uuid,notnull,unique:message_embedding_message_uuid_key"`
+	Embedding   pgvector.Vector     `bun:"type:vector(1536)"`
+	IsEmbedded  bool                `bun:"type:bool,notnull,default:false"`
+	Session     *SessionSchema      `bun:"rel:belongs-to,join:session_id=session_id,on_delete:cascade"`
+	Message     *MessageStoreSchema `bun:"rel:belongs-to,join:message_uuid=uuid,join:session_id=session_id,on_delete:cascade"`
+}
+
+func (*partitionedMessageVectorStoreSchema) AfterCreateTable(
+	ctx context.Context,
+	query *bun.CreateTableQuery,
+) error {
+	return (*MessageVectorStoreSchema)(nil).AfterCreateTable(ctx, query)
+}
+
+// partitionedSummarySchema is the summary table when messages are partitioned. It references
+// its summary point message by UUID and session ID.
+type partitionedSummarySchema struct {
+	SummaryStoreSchema `bun:",extend"`
+
+	Message *MessageStoreSchema `bun:"rel:belongs-to,join:summary_point_uuid=uuid,join:session_id=session_id,on_delete:cascade"`
+}
+
+// createTableQuery returns the query creating a schema's table. If partitions isn't zero, the
+// message tables are hash partitioned by session ID, and the tables referencing them are
+// created accordingly.
+func createTableQuery(
+	db *bun.DB,
+	schema bun.AfterCreateTableHook,
+	partitions int,
+) *bun.CreateTableQuery {
+	var model interface{} = schema
+	var partitioned bool
+	if partitions > 0 {
+		switch schema.(type) {
+		case *MessageStoreSchema:
+			model, partitioned = (*partitionedMessageSchema)(nil), true
+		case *MessageVectorStoreSchema:
+			model, partitioned = (*partitionedMessageVectorStoreSchema)(nil), true
+		case *SummaryStoreSchema:
+			model = (*partitionedSummarySchema)(nil)
+		}
+	}
+
+	query := db.NewCreateTable().
+		Model(model).
+		IfNotExists().
+		WithForeignKeys()
+	if partitioned {
+		query = query.PartitionBy("HASH (session_id)")
+	}
+
+	return query
+}
+
+// checkMessagePartitions returns an error if the message tables exist and aren't partitioned
+// into partitions. Tables are only partitioned when they're created, so existing databases
+// aren't partitioned, and hash partitions can't be added or removed once they are.
+func checkMessagePartitions(ctx context.Context, db *bun.DB, partitions int) error {
+	for _, table := range partitionedTables {
+		var exists bool
+		err := db.NewRaw("SELECT to_regclass(?) IS NOT NULL", table).Scan(ctx, &exists)
+		if err != nil {
+			return fmt.Errorf("error checking %s table: %w", table, err)
+		}
+		if !exists {
+			continue
+		}
+
+		partitioned, err := isPartitioned(ctx, db, table)
+		if err != nil {
+			return err
+		}
+		if !partitioned {
+			return fmt.Errorf(
+				"the %s table exists and isn't partitioned: message_partitions only applies to "+
+					"new databases. Restore a zep backup into a new database to partition "+
+					"existing data",
+				table,
+			)
+		}
+
+		var count int
+		err = db.NewRaw(
+			"SELECT count(*) FROM pg_inherits WHERE inhparent = to_regclass(?)",
+			table,
+		).Scan(ctx, &count)
+		if err != nil {
+			return fmt.Errorf("error counting %s partitions: %w", table, err)
+		}
+		if count > 0 && count != partitions {
+			return fmt.Errorf(
+				"the %s table has %d partitions, not %d: hash partitions can't be changed once created",
+				table,
+				count,
+				partitions,
+			)
+		}
+	}
+
+	return nil
+}
+
+// createMessagePartitions creates the hash partitions of the message tables that don't exist
+func createMessagePartitions(ctx context.Context, db *bun.DB, partitions int) error {
+	for _, table := range partitionedTables {
+		for i := 0; i < partitions; i++ {
+			_, err := db.ExecContext(
+				ctx,
+				"CREATE TABLE IF NOT EXISTS ? PARTITION OF ? FOR VALUES WITH (MODULUS ?, REMAINDER ?)",
+				bun.Ident(fmt.Sprintf("%s_p%d", table, i)),
+				bun.Ident(table),
+				partitions,
+				i,
+			)
+			if err != nil {
+				return fmt.Errorf("error creating %s partition %d: %w", table, i, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// messageUUIDSchema registers the UUID of each message of a partitioned message table, along
+// with its session. Its primary key keeps message UUIDs unique across the partitions. It's
+// maintained by triggers on the message table, so that every write of a message is checked,
+// in the transaction that writes it.
+type messageUUIDSchema struct {
+	bun.BaseModel `bun:"table:message_uuid,alias:mu"`
+
+	UUID      uuid.UUID `bun:",pk,type:uuid"`
+	SessionID string    `bun:",notnull"`
+}
+
+// messageUUIDFunctions register the UUIDs of inserted messages, rejecting those of other
+// sessions' messages, and follow messages that are deleted or moved to another session. A
+// message moved to another partition is deleted and inserted.
+var messageUUIDFunctions = []string{
+	`CREATE OR REPLACE FUNCTION message_uuid_insert() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO message_uuid (uuid, session_id) VALUES (NEW.uuid, NEW.session_id)
+        ON CONFLICT (uuid) DO NOTHING;
+    IF NOT FOUND THEN
+        RAISE EXCEPTION 'message % belongs to another session', NEW.uuid
+            USING ERRCODE = 'unique_violation';
+    END IF;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql`,
+	`CREATE OR REPLACE FUNCTION message_uuid_update() RETURNS trigger AS $$
+BEGIN
+    UPDATE message_uuid SET session_id = NEW.session_id
+        WHERE uuid = OLD.uuid AND session_id = OLD.session_id;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql`,
+	`CREATE OR REPLACE FUNCTION message_uuid_delete() RETURNS trigger AS $$
+BEGIN
+    DELETE FROM message_uuid WHERE uuid = OLD.uuid AND session_id = OLD.session_id;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql`,
+}
+
+// messageUUIDTriggers are the message table's triggers calling messageUUIDFunctions, by name
+var messageUUIDTriggers = map[string]string{
+	"message_uuid_insert": `CREATE TRIGGER message_uuid_insert AFTER INSERT ON message
+    FOR EACH ROW EXECUTE FUNCTION message_uuid_insert()`,
+	"message_uuid_update": `CREATE TRIGGER message_uuid_update AFTER UPDATE OF session_id ON message
+    FOR EACH ROW WHEN (OLD.session_id IS DISTINCT FROM NEW.session_id)
+    EXECUTE FUNCTION message_uuid_update()`,
+	"message_uuid_delete": `CREATE TRIGGER message_uuid_delete AFTER DELETE ON message
+    FOR EACH ROW EXECUTE FUNCTION message_uuid_delete()`,
+}
+
+// createMessageUUIDs creates the message_uuid table of a partitioned message table and its
+// triggers. The UUIDs of existing messages are registered when the table is created.
+func createMessageUUIDs(ctx context.Context, db *bun.DB) error {
+	return db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		var exists bool
+		err := tx.NewRaw("SELECT to_regclass('message_uuid') IS NOT NULL").Scan(ctx, &exists)
+		if err != nil {
+			return fmt.Errorf("error checking message_uuid table: %w", err)
+		}
+
+		if !exists {
+			_, err = tx.NewCreateTable().Model((*messageUUIDSchema)(nil)).Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("error creating message_uuid table: %w", err)
+			}
+			_, err = tx.ExecContext(
+				ctx,
+				`INSERT INTO message_uuid (uuid, session_id)
+SELECT uuid, session_id FROM message ON CONFLICT (uuid) DO NOTHING`,
+			)
+			if err != nil {
+				return fmt.Errorf("error registering message UUIDs: %w", err)
+			}
+		}
+
+		for _, function := range messageUUIDFunctions {
+			if _, err := tx.ExecContext(ctx, function); err != nil {
+				return fmt.Errorf("error creating message_uuid functions: %w", err)
+			}
+		}
+		// triggers are only created if they're missing, as creating them locks the table
+		for name, trigger := range messageUUIDTriggers {
+			var exists bool
+			err := tx.NewRaw(
+				"SELECT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = ? AND tgrelid = to_regclass('message'))",
+				name,
+			).Scan(ctx, &exists)
+			if err != nil {
+				return fmt.Errorf("error checking trigger %s: %w", name, err)
+			}
+			if exists {
+				continue
+			}
+			if _, err := tx.ExecContext(ctx, trigger); err != nil {
+				return fmt.Errorf("error creating trigger %s: %w", name, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// isPartitioned returns whether table is partitioned
+func isPartitioned(ctx context.Context, db bun.IDB, table string) (bool, error) {
+	var partitioned bool
+	err := db.NewRaw(
+		"SELECT EXISTS (SELECT 1 FROM pg_partitioned_table WHERE partrelid = to_regclass(?))",
+		table,
+	).Scan(ctx, &partitioned)
+	if err != nil {
+		return false, fmt.Errorf("error checking if %s is partitioned: %w", table, err)
+	}
+
+	return partitioned, nil
+}
+
+// indexConcurrently returns the CONCURRENTLY keyword if indexes on table may be created and
+// dropped concurrently. Indexes on partitioned tables can't be.
+func indexConcurrently(ctx context.Context, db bun.IDB, table string) (bun.Safe, error) {
+	partitioned, err := isPartitioned(ctx, db, table)
+	if err != nil {
+		return "", err
+	}
+	if partitioned {
+		return "", nil
+	}
+
+	return "CONCURRENTLY", nil
+}
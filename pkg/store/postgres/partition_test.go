@@ -0,0 +1,181 @@
+package postgres
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/testutils"
+)
+
+func TestPartitionedMessageVectorStoreSchemaColumns(t *testing.T) {
+	columns := func(model interface{}) []string {
+		var names []string
+		for _, field := range testDB.Table(reflect.TypeOf(model)).Fields {
+			names = append(names, field.Name)
+		}
+		return names
+	}
+
+	assert.ElementsMatch(
+		t,
+		columns(MessageVectorStoreSchema{}),
+		columns(partitionedMessageVectorStoreSchema{}),
+	)
+}
+
+func TestMessagePartitions(t *testing.T) {
+	const partitions = 4
+
+	CleanDB(t, testDB)
+	appState.Config.Store.Postgres.MessagePartitions = partitions
+	defer func() {
+		appState.Config.Store.Postgres.MessagePartitions = 0
+		CleanDB(t, testDB)
+		require.NoError(t, CreateSchema(testCtx, appState, testDB))
+	}()
+
+	require.NoError(t, CreateSchema(testCtx, appState, testDB))
+	// creating the schema again doesn't change the partitions
+	require.NoError(t, CreateSchema(testCtx, appState, testDB))
+
+	for _, table := range partitionedTables {
+		partitioned, err := isPartitioned(testCtx, testDB, table)
+		require.NoError(t, err)
+		assert.True(t, partitioned, table)
+
+		var count int
+		err = testDB.NewRaw(
+			"SELECT count(*) FROM pg_inherits WHERE inhparent = to_regclass(?)",
+			table,
+		).Scan(testCtx, &count)
+		require.NoError(t, err)
+		assert.Equal(t, partitions, count, table)
+	}
+
+	t.Run("messages and embeddings are upserted", func(t *testing.T) {
+		sessionID := testutils.GenerateRandomString(10)
+		messages, err := putMessages(testCtx, testDB, sessionID, []models.Message{
+			{Role: "human", Content: "Hello"},
+		})
+		require.NoError(t, err)
+
+		messages[0].Content = "Hello again"
+		_, err = putMessages(testCtx, testDB, sessionID, messages)
+		require.NoError(t, err)
+
+		embeddings := []models.TextData{
+			{
+				TextUUID:  messages[0].UUID,
+				Embedding: make([]float32, appState.Config.Extractors.Messages.Embeddings.Dimensions),
+			},
+		}
+		for i := 0; i < 2; i++ {
+			err = putMessageEmbeddings(testCtx, testDB, sessionID, embeddings)
+			require.NoError(t, err)
+		}
+
+		stored, err := getMessagesByUUID(testCtx, testDB, sessionID, []uuid.UUID{messages[0].UUID})
+		require.NoError(t, err)
+		require.Len(t, stored, 1)
+		assert.Equal(t, "Hello again", stored[0].Content)
+
+		// the primary key doesn't prevent another session reusing the message's UUID
+		_, err = putMessages(testCtx, testDB, testutils.GenerateRandomString(10), messages)
+		assert.ErrorIs(t, err, models.ErrBadRequest)
+	})
+
+	t.Run("concurrent writes of a message UUID to two sessions", func(t *testing.T) {
+		messageUUID := uuid.New()
+		errs := make([]error, 2)
+		var wg sync.WaitGroup
+		for i := range errs {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_, errs[i] = putMessages(
+					testCtx,
+					testDB,
+					testutils.GenerateRandomString(10),
+					[]models.Message{{UUID: messageUUID, Role: "human", Content: "Hello"}},
+				)
+			}(i)
+		}
+		wg.Wait()
+
+		// one session's write succeeds and the other's is rejected
+		if errs[0] == nil {
+			assert.ErrorIs(t, errs[1], models.ErrBadRequest)
+		} else {
+			assert.ErrorIs(t, errs[0], models.ErrBadRequest)
+			assert.NoError(t, errs[1])
+		}
+		count, err := testDB.NewSelect().
+			Model((*MessageStoreSchema)(nil)).
+			Where("uuid = ?", messageUUID).
+			Count(testCtx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("observer sees changes to partitioned messages", func(t *testing.T) {
+		dsn := appState.Config.Store.Postgres.DSN
+		require.NoError(t, createObserverPublication(testCtx, dsn))
+		defer func() {
+			_, err := testDB.ExecContext(
+				testCtx,
+				"DROP PUBLICATION IF EXISTS "+sessionObserverPublication,
+			)
+			require.NoError(t, err)
+		}()
+
+		var viaRoot bool
+		err := testDB.NewRaw(
+			"SELECT pubviaroot FROM pg_publication WHERE pubname = ?",
+			sessionObserverPublication,
+		).Scan(testCtx, &viaRoot)
+		require.NoError(t, err)
+		assert.True(t, viaRoot)
+
+		var walLevel string
+		require.NoError(t, testDB.NewRaw("SHOW wal_level").Scan(testCtx, &walLevel))
+		if walLevel != "logical" {
+			t.Skip("streaming requires wal_level=logical")
+		}
+
+		o, err := NewSessionObserver(dsn, "zep_test_partitioned_observer")
+		require.NoError(t, err)
+		defer o.Close()
+
+		sessionID := testutils.GenerateRandomString(10)
+		messages, err := putMessages(testCtx, testDB, sessionID, []models.Message{
+			{Role: "human", Content: "Hello"},
+		})
+		require.NoError(t, err)
+
+		select {
+		case event := <-o.Events():
+			assert.Equal(t, MessageEventInsert, event.Type)
+			assert.Equal(t, sessionID, event.SessionID)
+			assert.Equal(t, messages[0].UUID, event.Message.UUID)
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out waiting for the message event")
+		}
+	})
+
+	t.Run("partition count can't be changed", func(t *testing.T) {
+		err := checkMessagePartitions(testCtx, testDB, partitions*2)
+		assert.ErrorContains(t, err, "hash partitions can't be changed")
+	})
+}
+
+func TestCheckMessagePartitionsUnpartitioned(t *testing.T) {
+	err := checkMessagePartitions(testCtx, testDB, 4)
+	assert.ErrorContains(t, err, "isn't partitioned")
+}
@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/uptrace/bun"
+
+	"github.com/getzep/zep/pkg/models"
+)
+
+// Sessions, users and collections belong to a project, and their records are only read and
+// written within it when the context is scoped to a project. Messages, summaries and their
+// embeddings belong to their session's project, and documents to their collection's. The IDs
+// of sessions and users, and collection names, remain unique across projects.
+
+// whereProject filters a query of the sessions, users or collections to the project ctx is
+// scoped to, for use with ApplyQueryBuilder. The queries of unscoped contexts aren't filtered.
+func whereProject(ctx context.Context) func(bun.QueryBuilder) bun.QueryBuilder {
+	return func(q bun.QueryBuilder) bun.QueryBuilder {
+		if projectID, ok := models.ProjectIDFromContext(ctx); ok {
+			return q.Where("?TableAlias.project_id = ?", projectID)
+		}
+		return q
+	}
+}
+
+// checkSessionProject returns a not found error if the session, deleted or not, belongs to a
+// project other than the one ctx is scoped to. A session that doesn't exist passes the check,
+// as writing to it creates it in ctx's project.
+func checkSessionProject(ctx context.Context, db bun.IDB, sessionID string) error {
+	other, err := inOtherProject(ctx, db, (*SessionSchema)(nil), "session_id", sessionID)
+	if err != nil {
+		return err
+	}
+	if other {
+		return models.NewNotFoundError("session " + sessionID)
+	}
+	return nil
+}
+
+// checkUserProject returns a not found error if the user, deleted or not, belongs to a
+// project other than the one ctx is scoped to
+func checkUserProject(ctx context.Context, db bun.IDB, userID string) error {
+	other, err := inOtherProject(ctx, db, (*UserSchema)(nil), "user_id", userID)
+	if err != nil {
+		return err
+	}
+	if other {
+		return models.NewNotFoundError("user " + userID)
+	}
+	return nil
+}
+
+func inOtherProject(
+	ctx context.Context,
+	db bun.IDB,
+	model interface{},
+	column string,
+	id string,
+) (bool, error) {
+	projectID, ok := models.ProjectIDFromContext(ctx)
+	if !ok {
+		return false, nil
+	}
+	exists, err := db.NewSelect().
+		Model(model).
+		WhereAllWithDeleted().
+		Where("? = ?", bun.Ident(column), id).
+		Where("project_id != ?", projectID).
+		Exists(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get project of %s %s: %w", column, id, err)
+	}
+	return exists, nil
+}
+
+// openSession checks that a session may be read or written from ctx's project and rehydrates
+// it if it's archived
+func (pms *PostgresMemoryStore) openSession(ctx context.Context, sessionID string) error {
+	if err := checkSessionProject(ctx, pms.Client, sessionID); err != nil {
+		return err
+	}
+	return pms.rehydrateSession(ctx, sessionID)
+}
@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/testutils"
+)
+
+func TestProjectIsolation(t *testing.T) {
+	ctxA := models.WithProjectID(testCtx, testutils.GenerateRandomString(8))
+	ctxB := models.WithProjectID(testCtx, testutils.GenerateRandomString(8))
+
+	userStore := NewUserStoreDAO(testDB)
+	userID := testutils.GenerateRandomString(16)
+	_, err := userStore.Create(ctxA, &models.CreateUserRequest{UserID: userID})
+	require.NoError(t, err)
+	sessionID := testutils.GenerateRandomString(16)
+	_, err = appState.MemoryStore.CreateSession(ctxA, appState, &models.CreateSessionRequest{
+		SessionID: sessionID,
+		UserID:    &userID,
+	})
+	require.NoError(t, err)
+	err = appState.MemoryStore.PutMemory(ctxA, appState, sessionID, &models.Memory{
+		Messages: []models.Message{{Role: "user", Content: "Hello"}},
+	}, true)
+	require.NoError(t, err)
+
+	t.Run("other projects can't read the records", func(t *testing.T) {
+		_, err := appState.MemoryStore.GetSession(ctxB, appState, sessionID)
+		assert.ErrorIs(t, err, models.ErrNotFound)
+		_, err = appState.MemoryStore.GetMemory(ctxB, appState, sessionID, 0, nil)
+		assert.ErrorIs(t, err, models.ErrNotFound)
+		_, err = userStore.Get(ctxB, userID)
+		assert.ErrorIs(t, err, models.ErrNotFound)
+
+		sessions, err := appState.MemoryStore.ListSessions(ctxB, appState, 0, 100)
+		require.NoError(t, err)
+		assert.Empty(t, sessions)
+		users, err := userStore.ListAll(ctxB, 0, 100)
+		require.NoError(t, err)
+		assert.Empty(t, users)
+	})
+
+	t.Run("other projects can't write the records", func(t *testing.T) {
+		err := appState.MemoryStore.PutMemory(ctxB, appState, sessionID, &models.Memory{
+			Messages: []models.Message{{Role: "user", Content: "Intruder"}},
+		}, true)
+		assert.ErrorIs(t, err, models.ErrNotFound)
+		err = appState.MemoryStore.DeleteSession(ctxB, sessionID)
+		assert.ErrorIs(t, err, models.ErrNotFound)
+		err = userStore.Delete(ctxB, userID)
+		assert.ErrorIs(t, err, models.ErrNotFound)
+
+		// a session can't be created for another project's user
+		_, err = appState.MemoryStore.CreateSession(ctxB, appState, &models.CreateSessionRequest{
+			SessionID: testutils.GenerateRandomString(16),
+			UserID:    &userID,
+		})
+		assert.ErrorIs(t, err, models.ErrBadRequest)
+	})
+
+	t.Run("the project reads its records", func(t *testing.T) {
+		memory, err := appState.MemoryStore.GetMemory(ctxA, appState, sessionID, 0, nil)
+		require.NoError(t, err)
+		require.Len(t, memory.Messages, 1)
+		assert.Equal(t, "Hello", memory.Messages[0].Content)
+
+		sessions, err := userStore.GetSessions(ctxA, userID)
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+		assert.Equal(t, sessionID, sessions[0].SessionID)
+	})
+
+	t.Run("unscoped contexts read all projects", func(t *testing.T) {
+		session, err := appState.MemoryStore.GetSession(testCtx, appState, sessionID)
+		require.NoError(t, err)
+		assert.Equal(t, &userID, session.UserID)
+	})
+}
+
+func TestProjectCollections(t *testing.T) {
+	ctxA := models.WithProjectID(testCtx, testutils.GenerateRandomString(8))
+	ctxB := models.WithProjectID(testCtx, testutils.GenerateRandomString(8))
+
+	collection := NewDocumentCollectionDAO(appState, testDB, models.DocumentCollection{
+		Name:                testutils.GenerateRandomString(10),
+		EmbeddingDimensions: 10,
+	})
+	require.NoError(t, collection.Create(ctxA))
+
+	other := NewDocumentCollectionDAO(appState, testDB, models.DocumentCollection{
+		Name: collection.Name,
+	})
+	assert.ErrorIs(t, other.GetByName(ctxB), models.ErrNotFound)
+	assert.ErrorIs(t, other.Delete(ctxB), models.ErrNotFound)
+	collections, err := other.GetAll(ctxB)
+	require.NoError(t, err)
+	assert.Empty(t, collections)
+
+	require.NoError(t, other.GetByName(ctxA))
+	assert.Equal(t, collection.TableName, other.TableName)
+	require.NoError(t, other.Delete(ctxA))
+}
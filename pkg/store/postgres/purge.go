@@ -23,6 +23,11 @@ func purgeDeleted(ctx context.Context, db *bun.DB) error {
 		}
 	}
 
+	// the mentions of purged sessions were deleted with them
+	if _, err := deleteUnmentionedEntities(ctx, db); err != nil {
+		return fmt.Errorf("error purging entities: %w", err)
+	}
+
 	// Vacuum database post-purge. This is avoids issues with HNSW indexes
 	// after deleting a large number of rows.
 	// https://github.com/pgvector/pgvector/issues/244
@@ -11,7 +11,7 @@ func TestPurgeDeleted(t *testing.T) {
 	assert.NoError(t, err, "setupTestDeleteData should not return an error")
 
 	sessionStore := NewSessionDAO(testDB)
-	err = sessionStore.Delete(testCtx, sessionID)
+	err = sessionStore.Delete(testCtx, sessionID, false)
 	assert.NoError(t, err, "deleteSession should not return an error")
 
 	err = purgeDeleted(testCtx, testDB)
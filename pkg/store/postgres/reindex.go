@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/getzep/zep/pkg/llms"
+	"github.com/getzep/zep/pkg/models"
+	"github.com/uptrace/bun"
+)
+
+const reindexPageSize = 100
+
+// ReindexMessageEmbeddings embeds the messages of all sessions and writes them to a message
+// vector index, returning the number of messages indexed. Messages are re-embedded rather
+// than copied from the message_embedding table, so the index may be populated regardless of
+// where embeddings were previously stored.
+func ReindexMessageEmbeddings(
+	ctx context.Context,
+	appState *models.AppState,
+	db *bun.DB,
+	index models.MessageVectorIndex,
+) (int, error) {
+	documentType := "message"
+	model, err := llms.GetEmbeddingModel(appState, documentType)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get message embedding model: %w", err)
+	}
+
+	dao := NewSessionDAO(db)
+	count := 0
+	var cursor int64
+	for {
+		sessions, err := dao.ListAll(ctx, cursor, reindexPageSize)
+		if err != nil {
+			return count, err
+		}
+		if len(sessions) == 0 {
+			break
+		}
+
+		for _, session := range sessions {
+			n, err := reindexSession(ctx, appState, db, index, model, session.SessionID)
+			if err != nil {
+				return count, fmt.Errorf(
+					"failed to reindex session %s: %w",
+					session.SessionID,
+					err,
+				)
+			}
+			count += n
+			log.Debugf("reindexed %d messages for session %s", n, session.SessionID)
+		}
+
+		cursor = sessions[len(sessions)-1].ID
+	}
+
+	return count, nil
+}
+
+func reindexSession(
+	ctx context.Context,
+	appState *models.AppState,
+	db *bun.DB,
+	index models.MessageVectorIndex,
+	model *models.EmbeddingModel,
+	sessionID string,
+) (int, error) {
+	count := 0
+	var cursor int64
+	for {
+		messageList, err := getMessageListByCursor(
+			ctx,
+			db,
+			sessionID,
+			cursor,
+			reindexPageSize,
+			nil,
+		)
+		if err != nil {
+			return count, err
+		}
+		if len(messageList.Messages) == 0 {
+			break
+		}
+
+		messages := messageList.Messages
+		texts := make([]string, len(messages))
+		for i, m := range messages {
+			texts[i] = m.Content
+		}
+		embeddings, err := llms.EmbedTexts(ctx, appState, model, "message", texts)
+		if err != nil {
+			return count, fmt.Errorf("failed to embed messages: %w", err)
+		}
+
+		records := make([]models.TextData, len(messages))
+		for i, m := range messages {
+			records[i] = models.TextData{
+				TextUUID:  m.UUID,
+				Text:      m.Content,
+				Embedding: embeddings[i],
+				Metadata:  m.Metadata,
+			}
+		}
+		if err := index.PutMessageEmbeddings(ctx, sessionID, records); err != nil {
+			return count, err
+		}
+		count += len(messages)
+
+		if messageList.NextCursor == 0 {
+			break
+		}
+		cursor = messageList.NextCursor
+	}
+
+	return count, nil
+}
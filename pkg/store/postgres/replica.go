@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"sync"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// recentWrites records when sessions were last written, so that they're read from the
+// primary until the replica has caught up
+type recentWrites struct {
+	mu         sync.Mutex
+	written    map[string]time.Time
+	lastPruned time.Time
+}
+
+// add records that sessionID was written at now. Writes older than window are pruned at most
+// once per window, so that the sessions written aren't kept forever.
+func (w *recentWrites) add(sessionID string, now time.Time, window time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.written == nil {
+		w.written = make(map[string]time.Time)
+	}
+	w.written[sessionID] = now
+
+	if now.Sub(w.lastPruned) < window {
+		return
+	}
+	for id, t := range w.written {
+		if now.Sub(t) >= window {
+			delete(w.written, id)
+		}
+	}
+	w.lastPruned = now
+}
+
+// since returns whether sessionID was written within window of now
+func (w *recentWrites) since(sessionID string, now time.Time, window time.Duration) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	t, ok := w.written[sessionID]
+	return ok && now.Sub(t) < window
+}
+
+// readClient returns the database the session's reads go to: the read replica, unless there
+// isn't one or the session was written within ReadAfterWriteWindow
+func (pms *PostgresMemoryStore) readClient(sessionID string) *bun.DB {
+	if pms.ReadClient == nil {
+		return pms.Client
+	}
+	if pms.ReadAfterWriteWindow > 0 &&
+		pms.recentWrites.since(sessionID, time.Now(), pms.ReadAfterWriteWindow) {
+		return pms.Client
+	}
+	return pms.ReadClient
+}
+
+// sessionWritten records that a session was written, so that it's read from the primary for
+// ReadAfterWriteWindow
+func (pms *PostgresMemoryStore) sessionWritten(sessionID string) {
+	if pms.ReadClient == nil || pms.ReadAfterWriteWindow <= 0 {
+		return
+	}
+	pms.recentWrites.add(sessionID, time.Now(), pms.ReadAfterWriteWindow)
+}
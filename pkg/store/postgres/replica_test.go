@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uptrace/bun"
+
+	"github.com/getzep/zep/pkg/store"
+)
+
+func TestRecentWrites(t *testing.T) {
+	var writes recentWrites
+	window := 5 * time.Second
+	now := time.Now()
+
+	writes.add("a", now, window)
+	assert.True(t, writes.since("a", now.Add(time.Second), window))
+	assert.False(t, writes.since("a", now.Add(window), window))
+	assert.False(t, writes.since("b", now, window))
+
+	t.Run("prunes expired writes", func(t *testing.T) {
+		writes.add("b", now.Add(2*window), window)
+		assert.NotContains(t, writes.written, "a")
+		assert.Contains(t, writes.written, "b")
+	})
+}
+
+func TestReadClient(t *testing.T) {
+	primary := &bun.DB{}
+	replica := &bun.DB{}
+
+	t.Run("reads from the primary without a replica", func(t *testing.T) {
+		pms := &PostgresMemoryStore{BaseMemoryStore: store.BaseMemoryStore[*bun.DB]{Client: primary}}
+		pms.sessionWritten("session")
+		assert.Same(t, primary, pms.readClient("session"))
+	})
+
+	t.Run("reads from the replica", func(t *testing.T) {
+		pms := &PostgresMemoryStore{
+			BaseMemoryStore: store.BaseMemoryStore[*bun.DB]{Client: primary},
+			ReadClient:      replica,
+		}
+		pms.sessionWritten("session")
+		assert.Same(t, replica, pms.readClient("session"))
+	})
+
+	t.Run("reads recently written sessions from the primary", func(t *testing.T) {
+		pms := &PostgresMemoryStore{
+			BaseMemoryStore:      store.BaseMemoryStore[*bun.DB]{Client: primary},
+			ReadClient:           replica,
+			ReadAfterWriteWindow: time.Minute,
+		}
+		pms.sessionWritten("session")
+		assert.Same(t, primary, pms.readClient("session"))
+		assert.Same(t, replica, pms.readClient("other"))
+	})
+}
@@ -0,0 +1,302 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/store"
+)
+
+// DefaultRetentionAuditListLimit is the number of retention audit records listed if no limit
+// is given
+const DefaultRetentionAuditListLimit = 100
+
+// RetentionAuditSchema records an application of a retention rule
+type RetentionAuditSchema struct {
+	bun.BaseModel `bun:"table:retention_audit,alias:ra" yaml:"-"`
+
+	UUID        uuid.UUID   `bun:",pk,type:uuid,default:gen_random_uuid()"`
+	ID          int64       `bun:",autoincrement"`
+	CreatedAt   time.Time   `bun:"type:timestamptz,notnull,default:current_timestamp"`
+	Rule        string      `bun:",notnull"`
+	Target      string      `bun:",notnull"`
+	Action      string      `bun:",notnull"`
+	Cutoff      time.Time   `bun:"type:timestamptz,notnull"`
+	RecordCount int         `bun:",notnull"`
+	RecordUUIDs []uuid.UUID `bun:"type:jsonb,nullzero"`
+}
+
+func (*RetentionAuditSchema) AfterCreateTable(
+	ctx context.Context,
+	query *bun.CreateTableQuery,
+) error {
+	_, err := query.DB().NewCreateIndex().
+		Model((*RetentionAuditSchema)(nil)).
+		Index("retention_audit_rule_idx").
+		Column("rule").
+		IfNotExists().
+		Exec(ctx)
+	return err
+}
+
+// ValidateRetentionRules returns an error if a rule has no name or a duplicate name, an
+// unknown target or action, or doesn't apply to records older than at least a day
+func ValidateRetentionRules(rules []config.RetentionRuleConfig) error {
+	names := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		if rule.Name == "" {
+			return errors.New("retention rules must have a name")
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("duplicate retention rule name: %s", rule.Name)
+		}
+		names[rule.Name] = true
+
+		switch models.RetentionTarget(rule.Target) {
+		case models.RetentionTargetMessages, models.RetentionTargetSummaries:
+		default:
+			return fmt.Errorf("retention rule %s has an invalid target: %s", rule.Name, rule.Target)
+		}
+		switch models.RetentionAction(rule.Action) {
+		case models.RetentionActionPurge, models.RetentionActionAnonymize:
+		default:
+			return fmt.Errorf("retention rule %s has an invalid action: %s", rule.Name, rule.Action)
+		}
+		if rule.OlderThanDays <= 0 {
+			return fmt.Errorf("retention rule %s older_than_days must be positive", rule.Name)
+		}
+	}
+	return nil
+}
+
+// ApplyRetentionRules applies the rules in order, each in its own transaction along with its
+// audit record. Rules apply to soft-deleted records too.
+func (pms *PostgresMemoryStore) ApplyRetentionRules(
+	ctx context.Context,
+	rules []config.RetentionRuleConfig,
+) ([]models.RetentionAudit, error) {
+	if err := ValidateRetentionRules(rules); err != nil {
+		return nil, models.NewBadRequestError(err.Error())
+	}
+
+	now := time.Now()
+	audits := make([]models.RetentionAudit, 0, len(rules))
+	for _, rule := range rules {
+		cutoff := now.AddDate(0, 0, -rule.OlderThanDays)
+		audit, err := applyRetentionRule(ctx, pms.Client, rule, cutoff)
+		if err != nil {
+			return audits, store.NewStorageError(
+				"failed to apply retention rule "+rule.Name,
+				err,
+			)
+		}
+		audits = append(audits, *audit)
+	}
+
+	return audits, nil
+}
+
+func applyRetentionRule(
+	ctx context.Context,
+	db *bun.DB,
+	rule config.RetentionRuleConfig,
+	cutoff time.Time,
+) (*models.RetentionAudit, error) {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer rollbackOnError(tx)
+
+	var recordUUIDs []uuid.UUID
+	target := models.RetentionTarget(rule.Target)
+	switch models.RetentionAction(rule.Action) {
+	case models.RetentionActionPurge:
+		if target == models.RetentionTargetMessages {
+			recordUUIDs, err = purgeMessagesBefore(ctx, tx, cutoff)
+		} else {
+			recordUUIDs, err = purgeSummariesBefore(ctx, tx, cutoff)
+		}
+	case models.RetentionActionAnonymize:
+		var model interface{} = (*MessageStoreSchema)(nil)
+		if target == models.RetentionTargetSummaries {
+			model = (*SummaryStoreSchema)(nil)
+		}
+		err = tx.NewUpdate().
+			Model(model).
+			Set("metadata = NULL").
+			WhereAllWithDeleted().
+			Where("created_at < ?", cutoff).
+			Where("metadata IS NOT NULL").
+			Returning("uuid").
+			Scan(ctx, &recordUUIDs)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	audit := RetentionAuditSchema{
+		Rule:        rule.Name,
+		Target:      rule.Target,
+		Action:      rule.Action,
+		Cutoff:      cutoff,
+		RecordCount: len(recordUUIDs),
+		RecordUUIDs: recordUUIDs,
+	}
+	if _, err := tx.NewInsert().Model(&audit).Returning("*").Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to put retention audit: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return retentionAuditSchemaToRetentionAudit(&audit), nil
+}
+
+// purgeMessagesBefore hard deletes the messages created before cutoff, and their embeddings.
+// Messages that are a summary's summary point are emptied of their content and metadata
+// rather than deleted, as deleting them would delete the summary. The UUIDs of the deleted
+// and emptied messages are returned.
+func purgeMessagesBefore(ctx context.Context, tx bun.Tx, cutoff time.Time) ([]uuid.UUID, error) {
+	messagesBefore := tx.NewSelect().
+		Model((*MessageStoreSchema)(nil)).
+		Column("uuid").
+		WhereAllWithDeleted().
+		Where("created_at < ?", cutoff)
+
+	_, err := tx.NewDelete().
+		Model((*MessageVectorStoreSchema)(nil)).
+		WhereAllWithDeleted().
+		Where("message_uuid IN (?)", messagesBefore).
+		ForceDelete().
+		Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge message embeddings: %w", err)
+	}
+
+	var emptied []uuid.UUID
+	err = tx.NewUpdate().
+		Model((*MessageStoreSchema)(nil)).
+		Set("content = ''").
+		Set("metadata = NULL").
+		Set("token_count = 0").
+		WhereAllWithDeleted().
+		Where("created_at < ?", cutoff).
+		Where("uuid IN (SELECT summary_point_uuid FROM summary)").
+		Where("(content <> '' OR metadata IS NOT NULL)").
+		Returning("uuid").
+		Scan(ctx, &emptied)
+	if err != nil {
+		return nil, fmt.Errorf("failed to empty summary point messages: %w", err)
+	}
+
+	var deleted []uuid.UUID
+	err = tx.NewDelete().
+		Model((*MessageStoreSchema)(nil)).
+		WhereAllWithDeleted().
+		Where("created_at < ?", cutoff).
+		Where("uuid NOT IN (SELECT summary_point_uuid FROM summary)").
+		ForceDelete().
+		Returning("uuid").
+		Scan(ctx, &deleted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge messages: %w", err)
+	}
+
+	return append(deleted, emptied...), nil
+}
+
+// purgeSummariesBefore hard deletes the summaries created before cutoff, and their embeddings
+func purgeSummariesBefore(ctx context.Context, tx bun.Tx, cutoff time.Time) ([]uuid.UUID, error) {
+	summariesBefore := tx.NewSelect().
+		Model((*SummaryStoreSchema)(nil)).
+		Column("uuid").
+		WhereAllWithDeleted().
+		Where("created_at < ?", cutoff)
+
+	_, err := tx.NewDelete().
+		Model((*SummaryVectorStoreSchema)(nil)).
+		WhereAllWithDeleted().
+		Where("summary_uuid IN (?)", summariesBefore).
+		ForceDelete().
+		Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge summary embeddings: %w", err)
+	}
+
+	var deleted []uuid.UUID
+	err = tx.NewDelete().
+		Model((*SummaryStoreSchema)(nil)).
+		WhereAllWithDeleted().
+		Where("created_at < ?", cutoff).
+		ForceDelete().
+		Returning("uuid").
+		Scan(ctx, &deleted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge summaries: %w", err)
+	}
+
+	return deleted, nil
+}
+
+func (pms *PostgresMemoryStore) ListRetentionAudits(
+	ctx context.Context,
+	rule string,
+	cursor int64,
+	limit int,
+) (*models.RetentionAuditListResponse, error) {
+	if limit <= 0 {
+		limit = DefaultRetentionAuditListLimit
+	}
+
+	var auditsDB []RetentionAuditSchema
+	query := pms.Client.NewSelect().
+		Model(&auditsDB).
+		Where("id > ?", cursor).
+		OrderExpr("id ASC").
+		// select one more than the limit to determine whether there's a next page
+		Limit(limit + 1)
+	if rule != "" {
+		query = query.Where("rule = ?", rule)
+	}
+	if err := query.Scan(ctx); err != nil {
+		return nil, store.NewStorageError("failed to list retention audits", err)
+	}
+
+	response := &models.RetentionAuditListResponse{Audits: []models.RetentionAudit{}}
+	if len(auditsDB) > limit {
+		auditsDB = auditsDB[:limit]
+		response.NextCursor = auditsDB[limit-1].ID
+	}
+	for i := range auditsDB {
+		response.Audits = append(
+			response.Audits,
+			*retentionAuditSchemaToRetentionAudit(&auditsDB[i]),
+		)
+	}
+
+	return response, nil
+}
+
+func retentionAuditSchemaToRetentionAudit(auditDB *RetentionAuditSchema) *models.RetentionAudit {
+	return &models.RetentionAudit{
+		UUID:        auditDB.UUID,
+		ID:          auditDB.ID,
+		CreatedAt:   auditDB.CreatedAt,
+		Rule:        auditDB.Rule,
+		Target:      models.RetentionTarget(auditDB.Target),
+		Action:      models.RetentionAction(auditDB.Action),
+		Cutoff:      auditDB.Cutoff,
+		RecordCount: auditDB.RecordCount,
+		RecordUUIDs: auditDB.RecordUUIDs,
+	}
+}
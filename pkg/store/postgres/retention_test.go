@@ -0,0 +1,136 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+)
+
+func TestApplyRetentionRules(t *testing.T) {
+	CleanDB(t, testDB)
+	err := CreateSchema(testCtx, appState, testDB)
+	require.NoError(t, err)
+
+	sessionID := createSession(t)
+	messages, err := putMessages(testCtx, testDB, sessionID, []models.Message{
+		{Role: "user", Content: "old", Metadata: map[string]interface{}{"key": "value"}},
+		{Role: "user", Content: "old summary point", Metadata: map[string]interface{}{"k": "v"}},
+		{Role: "user", Content: "recent", Metadata: map[string]interface{}{"key": "value"}},
+		{Role: "user", Content: "recent summary point"},
+	})
+	require.NoError(t, err)
+	_, err = putSummary(testCtx, testDB, sessionID, &models.Summary{
+		Content:          "old summary",
+		SummaryPointUUID: messages[1].UUID,
+	})
+	require.NoError(t, err)
+	recentSummary, err := putSummary(testCtx, testDB, sessionID, &models.Summary{
+		Content:          "recent summary",
+		SummaryPointUUID: messages[3].UUID,
+	})
+	require.NoError(t, err)
+
+	age := func(model interface{}, uuids []uuid.UUID, days int) {
+		_, err := testDB.NewUpdate().
+			Model(model).
+			Set("created_at = ?", time.Now().AddDate(0, 0, -days)).
+			Where("uuid IN (?)", bun.In(uuids)).
+			Exec(testCtx)
+		require.NoError(t, err)
+	}
+	age((*MessageStoreSchema)(nil), []uuid.UUID{messages[0].UUID, messages[1].UUID}, 100)
+	age((*MessageStoreSchema)(nil), []uuid.UUID{messages[2].UUID, messages[3].UUID}, 40)
+
+	rules := []config.RetentionRuleConfig{
+		{Name: "purge", Target: "messages", Action: "purge", OlderThanDays: 90},
+		{Name: "anonymize", Target: "messages", Action: "anonymize", OlderThanDays: 30},
+	}
+	audits, err := appState.MemoryStore.ApplyRetentionRules(testCtx, rules)
+	require.NoError(t, err)
+	require.Len(t, audits, 2)
+
+	// the summary point of the old summary is emptied rather than deleted
+	assert.Equal(t, "purge", audits[0].Rule)
+	assert.ElementsMatch(t, []uuid.UUID{messages[0].UUID, messages[1].UUID}, audits[0].RecordUUIDs)
+	assert.Equal(t, "anonymize", audits[1].Rule)
+	assert.Equal(t, []uuid.UUID{messages[2].UUID}, audits[1].RecordUUIDs)
+
+	var remaining []MessageStoreSchema
+	err = testDB.NewSelect().
+		Model(&remaining).
+		Where("session_id = ?", sessionID).
+		Order("id ASC").
+		Scan(testCtx)
+	require.NoError(t, err)
+	require.Len(t, remaining, 3)
+	assert.Equal(t, messages[1].UUID, remaining[0].UUID)
+	assert.Empty(t, remaining[0].Content)
+	assert.Nil(t, remaining[0].Metadata)
+	assert.Equal(t, "recent", remaining[1].Content)
+	assert.Nil(t, remaining[1].Metadata)
+
+	// summaries are kept without a rule
+	summaryCount, err := testDB.NewSelect().
+		Model((*SummaryStoreSchema)(nil)).
+		Where("session_id = ?", sessionID).
+		Count(testCtx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, summaryCount)
+
+	list, err := appState.MemoryStore.ListRetentionAudits(testCtx, "purge", 0, 0)
+	require.NoError(t, err)
+	require.Len(t, list.Audits, 1)
+	assert.Equal(t, audits[0].UUID, list.Audits[0].UUID)
+	assert.Equal(t, 2, list.Audits[0].RecordCount)
+
+	t.Run("summaries", func(t *testing.T) {
+		age((*SummaryStoreSchema)(nil), []uuid.UUID{recentSummary.UUID}, 10)
+		audits, err := appState.MemoryStore.ApplyRetentionRules(
+			testCtx,
+			[]config.RetentionRuleConfig{
+				{Name: "summaries", Target: "summaries", Action: "purge", OlderThanDays: 5},
+			},
+		)
+		require.NoError(t, err)
+		require.Len(t, audits, 1)
+		assert.Equal(t, []uuid.UUID{recentSummary.UUID}, audits[0].RecordUUIDs)
+	})
+}
+
+func TestValidateRetentionRules(t *testing.T) {
+	valid := config.RetentionRuleConfig{
+		Name:          "purge",
+		Target:        "messages",
+		Action:        "purge",
+		OlderThanDays: 90,
+	}
+	assert.NoError(t, ValidateRetentionRules([]config.RetentionRuleConfig{valid}))
+
+	withTarget, withAction, withAge := valid, valid, valid
+	withTarget.Target = "sessions"
+	withAction.Action = "archive"
+	withAge.OlderThanDays = 0
+	tests := []struct {
+		name  string
+		rules []config.RetentionRuleConfig
+		err   string
+	}{
+		{"no name", []config.RetentionRuleConfig{{Target: "messages"}}, "must have a name"},
+		{"duplicate name", []config.RetentionRuleConfig{valid, valid}, "duplicate retention rule"},
+		{"invalid target", []config.RetentionRuleConfig{withTarget}, "invalid target"},
+		{"invalid action", []config.RetentionRuleConfig{withAction}, "invalid action"},
+		{"invalid age", []config.RetentionRuleConfig{withAge}, "must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, ValidateRetentionRules(tt.rules), tt.err)
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,9 +15,11 @@ import (
 	"github.com/getzep/zep/pkg/store/postgres/migrations"
 
 	"github.com/Masterminds/semver/v3"
-	_ "github.com/jackc/pgx/v5/stdlib" // required for pgx to work
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/uptrace/bun/driver/pgdriver"
 
+	"github.com/getzep/zep/config"
 	"github.com/getzep/zep/pkg/llms"
 	"github.com/uptrace/bun/dialect/pgdialect"
 
@@ -44,6 +47,11 @@ type SessionSchema struct {
 	// UserUUID must be pointer type in order to be nullable
 	UserID *string     `bun:","                                                           yaml:"user_id,omitempty"`
 	User   *UserSchema `bun:"rel:belongs-to,join:user_id=user_id,on_delete:cascade"       yaml:"-"`
+	// Language is the Postgres text search configuration used for the session's messages
+	Language string `bun:"type:regconfig,nullzero,notnull,default:'english'"           yaml:"language,omitempty"`
+	// ArchivedAt is set while the session's records are archived to object storage
+	ArchivedAt time.Time `bun:"type:timestamptz,nullzero"                                   yaml:"archived_at,omitempty"`
+	ProjectID  string    `bun:",notnull,default:'default'"                                  yaml:"project_id,omitempty"`
 }
 
 var _ bun.BeforeAppendModelHook = (*SessionSchema)(nil)
@@ -69,7 +77,11 @@ type MessageStoreSchema struct {
 	Content    string                 `bun:",notnull"                                                    yaml:"content,omitempty"`
 	TokenCount int                    `bun:",notnull"                                                    yaml:"token_count,omitempty"`
 	Metadata   map[string]interface{} `bun:"type:jsonb,nullzero,json_use_number"                         yaml:"metadata,omitempty"`
-	Session    *SessionSchema         `bun:"rel:belongs-to,join:session_id=session_id,on_delete:cascade" yaml:"-"`
+	// Language is the Postgres text search configuration used to build the message's search_vector
+	Language string `bun:"type:regconfig,nullzero,notnull,default:'english'"           yaml:"language,omitempty"`
+	// IsSystem is true for messages injected by the system rather than a participant
+	IsSystem bool           `bun:",notnull,default:false"                                      yaml:"is_system,omitempty"`
+	Session  *SessionSchema `bun:"rel:belongs-to,join:session_id=session_id,on_delete:cascade" yaml:"-"`
 }
 
 var _ bun.BeforeAppendModelHook = (*MessageStoreSchema)(nil)
@@ -131,6 +143,31 @@ func (s *SummaryStoreSchema) BeforeAppendModel(_ context.Context, query bun.Quer
 	return nil
 }
 
+// SummaryAbstractSchema stores the abstract of a session's summaries created by hierarchical
+// summarization. A session has at most one abstract.
+type SummaryAbstractSchema struct {
+	bun.BaseModel `bun:"table:summary_abstract,alias:sa" yaml:"-"`
+
+	UUID            uuid.UUID      `bun:",pk,type:uuid,default:gen_random_uuid()"`
+	CreatedAt       time.Time      `bun:"type:timestamptz,notnull,default:current_timestamp"`
+	UpdatedAt       time.Time      `bun:"type:timestamptz,nullzero,default:current_timestamp"`
+	DeletedAt       time.Time      `bun:"type:timestamptz,soft_delete,nullzero"`
+	SessionID       string         `bun:",notnull,unique"`
+	Content         string         `bun:",nullzero"`
+	LastSummaryUUID uuid.UUID      `bun:"type:uuid,notnull"` // the UUID of the most recent summary in the abstract
+	TokenCount      int            `bun:",notnull"`
+	Session         *SessionSchema `bun:"rel:belongs-to,join:session_id=session_id,on_delete:cascade"`
+}
+
+var _ bun.BeforeAppendModelHook = (*SummaryAbstractSchema)(nil)
+
+func (s *SummaryAbstractSchema) BeforeAppendModel(_ context.Context, query bun.Query) error {
+	if _, ok := query.(*bun.UpdateQuery); ok {
+		s.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
 type SummaryVectorStoreSchema struct {
 	bun.BaseModel `bun:"table:summary_embedding,alias:se" yaml:"-"`
 
@@ -159,6 +196,7 @@ func (s *SummaryVectorStoreSchema) BeforeAppendModel(_ context.Context, query bu
 type DocumentCollectionSchema struct {
 	bun.BaseModel             `bun:"table:document_collection,alias:dc" yaml:"-"`
 	models.DocumentCollection `                                         yaml:",inline"`
+	ProjectID                 string `bun:",notnull,default:'default'"         yaml:"project_id,omitempty"`
 }
 
 var _ bun.BeforeAppendModelHook = (*DocumentCollectionSchema)(nil)
@@ -191,6 +229,7 @@ type UserSchema struct {
 	FirstName string                 `bun:","                                                   yaml:"first_name,omitempty"`
 	LastName  string                 `bun:","                                                   yaml:"last_name,omitempty"`
 	Metadata  map[string]interface{} `bun:"type:jsonb,nullzero,json_use_number"                 yaml:"metadata,omitempty"`
+	ProjectID string                 `bun:",notnull,default:'default'"                          yaml:"project_id,omitempty"`
 }
 
 var _ bun.BeforeAppendModelHook = (*UserSchema)(nil)
@@ -207,6 +246,7 @@ var _ bun.AfterCreateTableHook = (*SessionSchema)(nil)
 var _ bun.AfterCreateTableHook = (*MessageStoreSchema)(nil)
 var _ bun.AfterCreateTableHook = (*MessageVectorStoreSchema)(nil)
 var _ bun.AfterCreateTableHook = (*SummaryStoreSchema)(nil)
+var _ bun.AfterCreateTableHook = (*SummaryAbstractSchema)(nil)
 var _ bun.AfterCreateTableHook = (*SummaryVectorStoreSchema)(nil)
 var _ bun.AfterCreateTableHook = (*UserSchema)(nil)
 
@@ -288,6 +328,14 @@ func (*SummaryStoreSchema) AfterCreateTable(
 	return err
 }
 
+func (*SummaryAbstractSchema) AfterCreateTable(
+	_ context.Context,
+	_ *bun.CreateTableQuery,
+) error {
+	// session_id is indexed by its unique constraint
+	return nil
+}
+
 func (*SummaryVectorStoreSchema) AfterCreateTable(
 	ctx context.Context,
 	query *bun.CreateTableQuery,
@@ -347,6 +395,7 @@ var messageTableList = []bun.AfterCreateTableHook{
 	&MessageVectorStoreSchema{},
 	&SummaryVectorStoreSchema{},
 	&SummaryStoreSchema{},
+	&SummaryAbstractSchema{},
 	&MessageStoreSchema{},
 	&SessionSchema{},
 }
@@ -387,7 +436,7 @@ func createDocumentTable(
 
 	// If HNSW indexes are available, create an HNSW index on the embedding column
 	if appState.Config.Store.Postgres.AvailableIndexes.HSNW {
-		err = createHNSWIndex(ctx, db, tableName, "embedding")
+		err = createHNSWIndex(ctx, db, &appState.Config.Store.Postgres.HNSW, tableName, "embedding")
 		if err != nil {
 			return fmt.Errorf("error creating hnsw index: %w", err)
 		}
@@ -426,20 +475,40 @@ func CreateSchema(
 	appState *models.AppState,
 	db *bun.DB,
 ) error {
-	// Create new tableList slice and append DocumentCollectionSchema to it
+	// Create new tableList slice and append DocumentCollectionSchema to it. Entity mentions
+	// reference sessions, so they're listed first to be created last.
 	tableList := append( //nolint:gocritic
-		messageTableList,
+		[]bun.AfterCreateTableHook{&EntityMentionSchema{}},
+		messageTableList...,
+	)
+	tableList = append(
+		tableList,
+		&FactSchema{},
 		&UserSchema{},
 		&DocumentCollectionSchema{},
+		&DeadLetterSchema{},
+		&RetentionAuditSchema{},
+		&APIKeySchema{},
+		&AuditEventSchema{},
+		&OutboxEventSchema{},
+		&EmbeddingCacheSchema{},
+		&ExtractorResultCacheSchema{},
+		&EntitySchema{},
 	)
+	partitions := appState.Config.Store.Postgres.MessagePartitions
+	if partitions > 0 {
+		if appState.Config.Store.Postgres.CockroachDB {
+			return errors.New("message_partitions isn't supported on CockroachDB")
+		}
+		if err := checkMessagePartitions(ctx, db, partitions); err != nil {
+			return err
+		}
+	}
+
 	// iterate through messageTableList in reverse order to create tables with foreign keys first
 	for i := len(tableList) - 1; i >= 0; i-- {
 		schema := tableList[i]
-		_, err := db.NewCreateTable().
-			Model(schema).
-			IfNotExists().
-			WithForeignKeys().
-			Exec(ctx)
+		_, err := createTableQuery(db, schema, partitions).Exec(ctx)
 		if err != nil {
 			// bun still trying to create indexes despite IfNotExists flag
 			if strings.Contains(err.Error(), "already exists") {
@@ -449,8 +518,17 @@ func CreateSchema(
 		}
 	}
 
+	if partitions > 0 {
+		if err := createMessagePartitions(ctx, db, partitions); err != nil {
+			return err
+		}
+		if err := createMessageUUIDs(ctx, db); err != nil {
+			return err
+		}
+	}
+
 	// apply migrations
-	if err := migrations.Migrate(ctx, db); err != nil {
+	if _, err := migrations.MigrateDB(ctx, db, false); err != nil {
 		return fmt.Errorf("failed to apply migrations: %w", err)
 	}
 
@@ -465,44 +543,252 @@ func CreateSchema(
 	// Create HNSW index on message and summary embeddings if available
 	if appState.Config.Store.Postgres.AvailableIndexes.HSNW {
 		c := "embedding"
-		if err := createHNSWIndex(ctx, db, "message_embedding", c); err != nil {
+		hnswConfig := &appState.Config.Store.Postgres.HNSW
+		if err := createHNSWIndex(ctx, db, hnswConfig, "message_embedding", c); err != nil {
 			return fmt.Errorf("error creating hnsw index: %w", err)
 		}
 
-		if err := createHNSWIndex(ctx, db, "summary_embedding", c); err != nil {
+		if err := createHNSWIndex(ctx, db, hnswConfig, "summary_embedding", c); err != nil {
 			return fmt.Errorf("error creating hnsw index: %w", err)
 		}
+
+		if err := migrateDocumentHNSWIndexes(ctx, appState, db); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
 // createHNSWIndex creates an HNSW index on the given table and column if it does not exist.
-// The index is created with the default M and efConstruction values. Only vector_cosine_ops is supported.
-func createHNSWIndex(ctx context.Context, db *bun.DB, table, column string) error {
-	const (
-		m              = 16
-		efConstruction = 64
-	)
+// The index is created with the configured M and efConstruction values. If the index exists
+// but was built with different values, or is invalid following a failed build, it is rebuilt.
+// Only vector_cosine_ops is supported.
+func createHNSWIndex(
+	ctx context.Context,
+	db *bun.DB,
+	cfg *config.HNSWConfig,
+	table, column string,
+) error {
+	m, efConstruction := hnswIndexParams(cfg)
 
 	idx := table + "_" + column + "_hnsw_idx"
 
+	concurrently, err := indexConcurrently(ctx, db, table)
+	if err != nil {
+		return err
+	}
+
+	existing, err := getHNSWIndexParams(ctx, db, idx)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		if existing.valid && existing.m == m && existing.efConstruction == efConstruction {
+			return nil
+		}
+		return rebuildHNSWIndex(
+			ctx,
+			db,
+			idx,
+			table,
+			column,
+			m,
+			efConstruction,
+			existing.valid,
+			concurrently,
+		)
+	}
+
 	log.Infof("creating hnsw index on %s.%s if it does not exist", table, column)
 
-	_, err := db.ExecContext(
+	err = execCreateHNSWIndex(ctx, db, idx, table, column, m, efConstruction, concurrently)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("created hnsw index successfully on %s.%s if it did not exist", table, column)
+
+	return nil
+}
+
+// rebuildHNSWIndex replaces an existing HNSW index. A valid index is replaced by building the
+// new index alongside it and swapping the two, so that the table remains searchable using
+// the old index during the build. An invalid index is dropped before the new index is built.
+func rebuildHNSWIndex(
+	ctx context.Context,
+	db *bun.DB,
+	idx, table, column string,
+	m, efConstruction int,
+	valid bool,
+	concurrently bun.Safe,
+) error {
+	log.Infof(
+		"rebuilding hnsw index on %s.%s with M = %d, ef_construction = %d",
+		table,
+		column,
+		m,
+		efConstruction,
+	)
+
+	if !valid {
+		_, err := db.ExecContext(ctx, "DROP INDEX ? IF EXISTS ?", concurrently, bun.Ident(idx))
+		if err != nil {
+			return fmt.Errorf("error dropping invalid hnsw index: %w", err)
+		}
+		err = execCreateHNSWIndex(ctx, db, idx, table, column, m, efConstruction, concurrently)
+		if err != nil {
+			return fmt.Errorf("error creating hnsw index: %w", err)
+		}
+		return nil
+	}
+
+	newIdx := idx + "_new"
+	// remove any index left by an interrupted rebuild
+	_, err := db.ExecContext(ctx, "DROP INDEX ? IF EXISTS ?", concurrently, bun.Ident(newIdx))
+	if err != nil {
+		return fmt.Errorf("error dropping hnsw index: %w", err)
+	}
+	err = execCreateHNSWIndex(ctx, db, newIdx, table, column, m, efConstruction, concurrently)
+	if err != nil {
+		return fmt.Errorf("error creating hnsw index: %w", err)
+	}
+	if _, err := db.ExecContext(ctx, "DROP INDEX ? ?", concurrently, bun.Ident(idx)); err != nil {
+		return fmt.Errorf("error dropping hnsw index: %w", err)
+	}
+	_, err = db.ExecContext(ctx, "ALTER INDEX ? RENAME TO ?", bun.Ident(newIdx), bun.Ident(idx))
+	if err != nil {
+		return fmt.Errorf("error renaming hnsw index: %w", err)
+	}
+
+	log.Infof("rebuilt hnsw index successfully on %s.%s", table, column)
+
+	return nil
+}
+
+func execCreateHNSWIndex(
+	ctx context.Context,
+	db *bun.DB,
+	idx, table, column string,
+	m, efConstruction int,
+	concurrently bun.Safe,
+) error {
+	return execWithoutStatementTimeout(
 		ctx,
-		"CREATE INDEX CONCURRENTLY IF NOT EXISTS ? ON ? USING hnsw (? vector_cosine_ops) WITH (M = ?, ef_construction = ?);",
-		bun.Safe(idx),
+		db,
+		"CREATE INDEX ? IF NOT EXISTS ? ON ? USING hnsw (? vector_cosine_ops) WITH (M = ?, ef_construction = ?);",
+		concurrently,
+		bun.Ident(idx),
 		bun.Ident(table),
 		bun.Ident(column),
 		m,
 		efConstruction,
 	)
+}
+
+type hnswIndexState struct {
+	m              int
+	efConstruction int
+	valid          bool
+}
+
+// getHNSWIndexParams returns the M and ef_construction values an existing HNSW index was
+// built with, or nil if the index does not exist
+func getHNSWIndexParams(ctx context.Context, db *bun.DB, idx string) (*hnswIndexState, error) {
+	var row struct {
+		RelOptions []string `bun:"reloptions,array"`
+		Valid      bool     `bun:"indisvalid"`
+	}
+	err := db.NewSelect().
+		TableExpr("pg_class AS c").
+		Join("JOIN pg_index AS i ON i.indexrelid = c.oid").
+		ColumnExpr("c.reloptions").
+		ColumnExpr("i.indisvalid").
+		Where("c.relname = ?", idx).
+		Where("c.relkind = 'i'").
+		Scan(ctx, &row)
 	if err != nil {
-		return err
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting hnsw index %s: %w", idx, err)
 	}
 
-	log.Infof("created hnsw index successfully on %s.%s if it did not exist", table, column)
+	// options that are not set use the pgvector defaults
+	state := &hnswIndexState{
+		m:              DefaultHNSWM,
+		efConstruction: DefaultHNSWEFConstruction,
+		valid:          row.Valid,
+	}
+	for _, option := range row.RelOptions {
+		key, value, ok := strings.Cut(option, "=")
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			continue
+		}
+		switch strings.ToLower(key) {
+		case "m":
+			state.m = n
+		case "ef_construction":
+			state.efConstruction = n
+		}
+	}
+
+	return state, nil
+}
+
+// hnswIndexParams returns the configured M and ef_construction values, or the defaults
+func hnswIndexParams(cfg *config.HNSWConfig) (int, int) {
+	m, efConstruction := DefaultHNSWM, DefaultHNSWEFConstruction
+	if cfg != nil && cfg.M > 0 {
+		m = cfg.M
+	}
+	if cfg != nil && cfg.EFConstruction > 0 {
+		efConstruction = cfg.EFConstruction
+	}
+	return m, efConstruction
+}
+
+// hnswEFSearch returns the configured ef_search value, or the default
+func hnswEFSearch(cfg *config.HNSWConfig) int {
+	if cfg != nil && cfg.EFSearch > 0 {
+		return cfg.EFSearch
+	}
+	return DefaultEFSearch
+}
+
+// migrateDocumentHNSWIndexes applies the configured HNSW index settings to the indexes of
+// existing document collections that use HNSW
+func migrateDocumentHNSWIndexes(
+	ctx context.Context,
+	appState *models.AppState,
+	db *bun.DB,
+) error {
+	var tableNames []string
+	err := db.NewSelect().
+		Model((*DocumentCollectionSchema)(nil)).
+		Column("table_name").
+		Where("index_type = ?", models.IndexType("hnsw")).
+		Scan(ctx, &tableNames)
+	if err != nil {
+		return fmt.Errorf("error getting hnsw document collections: %w", err)
+	}
+
+	for _, tableName := range tableNames {
+		err := createHNSWIndex(
+			ctx,
+			db,
+			&appState.Config.Store.Postgres.HNSW,
+			tableName,
+			"embedding",
+		)
+		if err != nil {
+			return fmt.Errorf("error migrating hnsw index for %s: %w", tableName, err)
+		}
+	}
 
 	return nil
 }
@@ -521,6 +807,9 @@ func checkEmbeddingDims(
 	if err != nil {
 		return fmt.Errorf("error getting %s embedding model: %w", documentType, err)
 	}
+	if err := llms.ValidateEmbeddingModel(appState.Config, model); err != nil {
+		return fmt.Errorf("invalid %s embedding model: %w", documentType, err)
+	}
 	width, err := getEmbeddingColumnWidth(ctx, tableName, db)
 	if err != nil {
 		return fmt.Errorf("error getting embedding column width: %w", err)
@@ -642,24 +931,20 @@ func MigrateEmbeddingDims(
 }
 
 // NewPostgresConn creates a new bun.DB connection to a postgres database using the provided DSN.
-// The connection is configured to pool connections based on the number of PROCs available.
+// The connection pool is configured using the store's pool settings, defaulting to a pool
+// sized on the number of PROCs available.
 func NewPostgresConn(appState *models.AppState) (*bun.DB, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	// WithReadTimeout is 10 minutes to avoid timeouts when creating indexes.
-	// TODO: This is not ideal. Use separate connections for index creation?
-	sqldb := sql.OpenDB(
-		pgdriver.NewConnector(
-			pgdriver.WithDSN(appState.Config.Store.Postgres.DSN),
-			pgdriver.WithReadTimeout(10*time.Minute),
-		),
-	)
-	sqldb.SetMaxOpenConns(maxOpenConns)
-	sqldb.SetMaxIdleConns(maxOpenConns)
+	db := openDB(&appState.Config.Store.Postgres, appState.Config.Store.Postgres.DSN)
 
-	db := bun.NewDB(sqldb, pgdialect.New())
-	db.AddQueryHook(bunotel.NewQueryHook(bunotel.WithDBName("zep")))
+	// CockroachDB has a built-in VECTOR type and supports neither the pgvector extension
+	// nor its ivfflat and hnsw indexes.
+	if appState.Config.Store.Postgres.CockroachDB {
+		appState.Config.Store.Postgres.AvailableIndexes = config.AvailableIndexes{}
+		return db, nil
+	}
 
 	// Enable pgvector extension
 	err := enablePgVectorExtension(ctx, db)
@@ -683,17 +968,166 @@ func NewPostgresConn(appState *models.AppState) (*bun.DB, error) {
 	return db, nil
 }
 
+// NewPostgresReadConn creates a new bun.DB connection to the read replica at the store's
+// ReadDSN. It's configured as the primary's connection is, with its own pool.
+func NewPostgresReadConn(appState *models.AppState) (*bun.DB, error) {
+	cfg := &appState.Config.Store.Postgres
+	if cfg.ReadDSN == "" {
+		return nil, errors.New("store.postgres.read_dsn is not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	db := openDB(cfg, cfg.ReadDSN)
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to read replica: %w", err)
+	}
+
+	return db, nil
+}
+
+// openDB returns a bun.DB connected to dsn, with the store's connection settings
+func openDB(cfg *config.PostgresConfig, dsn string) *bun.DB {
+	// WithReadTimeout is 10 minutes to avoid timeouts when creating indexes.
+	// TODO: This is not ideal. Use separate connections for index creation?
+	connector := pgdriver.NewConnector(
+		pgdriver.WithDSN(dsn),
+		pgdriver.WithReadTimeout(10*time.Minute),
+	)
+	if cfg.CockroachDB {
+		configureCockroachDB(connector.Config())
+	}
+	configureStatementTimeout(connector.Config(), cfg.StatementTimeout)
+	sqldb := sql.OpenDB(connector)
+	configurePool(sqldb, cfg)
+
+	db := bun.NewDB(sqldb, pgdialect.New())
+	db.AddQueryHook(bunotel.NewQueryHook(bunotel.WithDBName("zep")))
+	db.AddQueryHook(&metricsQueryHook{})
+
+	return db
+}
+
+// configureCockroachDB sets the session parameters Zep relies on when running on CockroachDB.
+// CockroachDB backs serial columns with unique_rowid() by default, which does not preserve
+// insertion order across nodes. Message ordering relies on the autoincrementing id column,
+// so serial columns are backed by a sequence instead.
+func configureCockroachDB(cfg *pgdriver.Config) {
+	if cfg.ConnParams == nil {
+		cfg.ConnParams = make(map[string]interface{})
+	}
+	cfg.ConnParams["serial_normalization"] = "sql_sequence"
+}
+
+// configurePool applies the connection pool settings to sqldb. Zero values are left at
+// their defaults: max open and idle connections default to maxOpenConns.
+func configurePool(sqldb *sql.DB, cfg *config.PostgresConfig) {
+	openConns := maxOpenConns
+	if cfg.MaxOpenConnections > 0 {
+		openConns = cfg.MaxOpenConnections
+	}
+	idleConns := openConns
+	if cfg.MaxIdleConnections > 0 {
+		idleConns = cfg.MaxIdleConnections
+	}
+	sqldb.SetMaxOpenConns(openConns)
+	sqldb.SetMaxIdleConns(idleConns)
+
+	if cfg.ConnectionMaxLifetime > 0 {
+		sqldb.SetConnMaxLifetime(cfg.ConnectionMaxLifetime)
+	}
+	if cfg.ConnectionMaxIdleTime > 0 {
+		sqldb.SetConnMaxIdleTime(cfg.ConnectionMaxIdleTime)
+	}
+}
+
+// configureStatementTimeout sets the statement_timeout of the connections, if timeout isn't
+// zero
+func configureStatementTimeout(cfg *pgdriver.Config, timeout time.Duration) {
+	if timeout <= 0 {
+		return
+	}
+	if cfg.ConnParams == nil {
+		cfg.ConnParams = make(map[string]interface{})
+	}
+	cfg.ConnParams["statement_timeout"] = timeout.Milliseconds()
+}
+
+// configureQueuePool applies the connection pool settings of the task queue to sqldb. The
+// queue's connections aren't limited unless QueueMaxOpenConnections is set.
+func configureQueuePool(sqldb *sql.DB, cfg *config.PostgresConfig) {
+	if cfg.QueueMaxOpenConnections > 0 {
+		sqldb.SetMaxOpenConns(cfg.QueueMaxOpenConnections)
+	}
+	if cfg.ConnectionMaxLifetime > 0 {
+		sqldb.SetConnMaxLifetime(cfg.ConnectionMaxLifetime)
+	}
+	if cfg.ConnectionMaxIdleTime > 0 {
+		sqldb.SetConnMaxIdleTime(cfg.ConnectionMaxIdleTime)
+	}
+}
+
 // NewPostgresConnForQueue creates a new pgx connection to a postgres database using the provided DSN.
 // This connection is intended to be used for queueing tasks.
 func NewPostgresConnForQueue(appState *models.AppState) (*sql.DB, error) {
-	db, err := sql.Open("pgx", appState.Config.Store.Postgres.DSN)
+	cfg := &appState.Config.Store.Postgres
+	connConfig, err := pgx.ParseConfig(cfg.DSN)
 	if err != nil {
 		return nil, err
 	}
+	if cfg.StatementTimeout > 0 {
+		connConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(
+			cfg.StatementTimeout.Milliseconds(),
+			10,
+		)
+	}
+
+	db := stdlib.OpenDB(*connConfig)
+	configureQueuePool(db, cfg)
 
 	return db, nil
 }
 
+// execWithoutStatementTimeout executes a query, such as an index build, that may run longer
+// than the statement timeout. The connection's statement timeout is restored afterwards.
+func execWithoutStatementTimeout(
+	ctx context.Context,
+	db *bun.DB,
+	query string,
+	args ...interface{},
+) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var timeout string
+	if err := conn.QueryRowContext(ctx, "SHOW statement_timeout").Scan(&timeout); err != nil {
+		return err
+	}
+	if timeout != "0" {
+		if _, err := conn.ExecContext(ctx, "SET statement_timeout = 0"); err != nil {
+			return err
+		}
+		defer func() {
+			_, err := conn.ExecContext(
+				context.Background(),
+				"SELECT set_config('statement_timeout', ?, false)",
+				timeout,
+			)
+			if err != nil {
+				log.Errorf("failed to restore statement timeout: %v", err)
+			}
+		}()
+	}
+
+	_, err = conn.ExecContext(ctx, query, args...)
+	return err
+}
+
 // isHNSWAvailable checks if the vector extension version is 0.5.0+.
 func isHNSWAvailable(ctx context.Context, db *bun.DB) (bool, error) {
 	const minVersion = "0.5.0"
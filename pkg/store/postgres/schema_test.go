@@ -2,13 +2,18 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
 	"reflect"
 	"testing"
 	"time"
 
+	"github.com/getzep/zep/config"
 	"github.com/getzep/zep/pkg/llms"
+	"github.com/getzep/zep/pkg/store/postgres/migrations"
 	"github.com/stretchr/testify/assert"
 	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"github.com/uptrace/bun/driver/pgdriver"
 )
 
 func TestEnsurePostgresSchemaSetup(t *testing.T) {
@@ -26,6 +31,11 @@ func TestEnsurePostgresSchemaSetup(t *testing.T) {
 		err := CreateSchema(testCtx, appState, testDB)
 		assert.NoError(t, err)
 	})
+	t.Run("dry run should report no pending migrations", func(t *testing.T) {
+		statements, err := migrations.MigrateDB(testCtx, testDB, true)
+		assert.NoError(t, err)
+		assert.Empty(t, statements)
+	})
 }
 
 func TestCreateDocumentTable(t *testing.T) {
@@ -113,3 +123,128 @@ func TestCheckEmbeddingDims(t *testing.T) {
 		assert.NoError(t, err)
 	}
 }
+
+func TestConfigurePool(t *testing.T) {
+	t.Run("defaults to maxOpenConns", func(t *testing.T) {
+		sqldb := sql.OpenDB(pgdriver.NewConnector())
+		defer sqldb.Close()
+
+		configurePool(sqldb, &config.PostgresConfig{})
+		assert.Equal(t, maxOpenConns, sqldb.Stats().MaxOpenConnections)
+	})
+
+	t.Run("uses configured max open connections", func(t *testing.T) {
+		sqldb := sql.OpenDB(pgdriver.NewConnector())
+		defer sqldb.Close()
+
+		configurePool(sqldb, &config.PostgresConfig{
+			MaxOpenConnections:    7,
+			MaxIdleConnections:    2,
+			ConnectionMaxLifetime: time.Minute,
+			ConnectionMaxIdleTime: time.Second,
+		})
+		assert.Equal(t, 7, sqldb.Stats().MaxOpenConnections)
+	})
+}
+
+func TestConfigureQueuePool(t *testing.T) {
+	t.Run("doesn't limit connections by default", func(t *testing.T) {
+		sqldb := sql.OpenDB(pgdriver.NewConnector())
+		defer sqldb.Close()
+
+		configureQueuePool(sqldb, &config.PostgresConfig{MaxOpenConnections: 7})
+		assert.Equal(t, 0, sqldb.Stats().MaxOpenConnections)
+	})
+
+	t.Run("uses configured max open connections", func(t *testing.T) {
+		sqldb := sql.OpenDB(pgdriver.NewConnector())
+		defer sqldb.Close()
+
+		configureQueuePool(sqldb, &config.PostgresConfig{QueueMaxOpenConnections: 3})
+		assert.Equal(t, 3, sqldb.Stats().MaxOpenConnections)
+	})
+}
+
+func TestConfigureStatementTimeout(t *testing.T) {
+	t.Run("sets statement_timeout in milliseconds", func(t *testing.T) {
+		connector := pgdriver.NewConnector()
+		configureStatementTimeout(connector.Config(), 30*time.Second)
+		assert.Equal(t, int64(30000), connector.Config().ConnParams["statement_timeout"])
+	})
+
+	t.Run("zero doesn't set statement_timeout", func(t *testing.T) {
+		connector := pgdriver.NewConnector()
+		configureStatementTimeout(connector.Config(), 0)
+		assert.NotContains(t, connector.Config().ConnParams, "statement_timeout")
+	})
+}
+
+func TestExecWithoutStatementTimeout(t *testing.T) {
+	connector := pgdriver.NewConnector(pgdriver.WithDSN(appState.Config.Store.Postgres.DSN))
+	configureStatementTimeout(connector.Config(), 100*time.Millisecond)
+	db := bun.NewDB(sql.OpenDB(connector), pgdialect.New())
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	_, err := db.ExecContext(testCtx, "SELECT pg_sleep(0.5)")
+	assert.Error(t, err, "statement should time out")
+
+	err = execWithoutStatementTimeout(testCtx, db, "SELECT pg_sleep(?)", 0.5)
+	assert.NoError(t, err)
+
+	// the timeout is restored on the connection
+	var timeout string
+	err = db.QueryRowContext(testCtx, "SHOW statement_timeout").Scan(&timeout)
+	assert.NoError(t, err)
+	assert.Equal(t, "100ms", timeout)
+}
+
+func TestConfigureCockroachDB(t *testing.T) {
+	connector := pgdriver.NewConnector(
+		pgdriver.WithDSN("postgres://root@localhost:26257/zep?search_path=zep"),
+	)
+	configureCockroachDB(connector.Config())
+	assert.Equal(t, map[string]interface{}{
+		"search_path":          "zep",
+		"serial_normalization": "sql_sequence",
+	}, connector.Config().ConnParams)
+}
+
+func TestHNSWIndexParams(t *testing.T) {
+	m, efConstruction := hnswIndexParams(&config.HNSWConfig{})
+	assert.Equal(t, DefaultHNSWM, m)
+	assert.Equal(t, DefaultHNSWEFConstruction, efConstruction)
+	assert.Equal(t, DefaultEFSearch, hnswEFSearch(&config.HNSWConfig{}))
+
+	cfg := &config.HNSWConfig{M: 8, EFConstruction: 32, EFSearch: 20}
+	m, efConstruction = hnswIndexParams(cfg)
+	assert.Equal(t, 8, m)
+	assert.Equal(t, 32, efConstruction)
+	assert.Equal(t, 20, hnswEFSearch(cfg))
+}
+
+func TestCreateHNSWIndexRebuildsOnChangedParams(t *testing.T) {
+	if !appState.Config.Store.Postgres.AvailableIndexes.HSNW {
+		t.Skip("hnsw indexes are not available")
+	}
+	idx := "message_embedding_embedding_hnsw_idx"
+
+	err := createHNSWIndex(testCtx, testDB, &config.HNSWConfig{}, "message_embedding", "embedding")
+	assert.NoError(t, err)
+	state, err := getHNSWIndexParams(testCtx, testDB, idx)
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultHNSWM, state.m)
+
+	cfg := &config.HNSWConfig{M: 8, EFConstruction: 32}
+	err = createHNSWIndex(testCtx, testDB, cfg, "message_embedding", "embedding")
+	assert.NoError(t, err)
+	state, err = getHNSWIndexParams(testCtx, testDB, idx)
+	assert.NoError(t, err)
+	assert.Equal(t, 8, state.m)
+	assert.Equal(t, 32, state.efConstruction)
+	assert.True(t, state.valid)
+
+	// restore the defaults for other tests
+	err = createHNSWIndex(testCtx, testDB, &config.HNSWConfig{}, "message_embedding", "embedding")
+	assert.NoError(t, err)
+}
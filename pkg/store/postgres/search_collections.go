@@ -0,0 +1,193 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/getzep/zep/pkg/llms"
+	"github.com/getzep/zep/pkg/models"
+)
+
+// SearchCollections searches the collections named by the query and those tagged with its
+// tag. Text queries are embedded once for each embedding model used by the collections.
+func (ds *DocumentStore) SearchCollections(
+	ctx context.Context,
+	query *models.CollectionsSearchPayload,
+	limit int,
+) (*models.CollectionsSearchResultPage, error) {
+	if len(query.CollectionNames) == 0 && query.Tag == "" {
+		return nil, models.NewBadRequestError("collection_names or tag must be specified")
+	}
+	if len(query.Text) > 0 && len(query.Embedding) > 0 {
+		return nil, models.NewBadRequestError("cannot specify both text and embedding")
+	}
+
+	collections, err := ds.getSearchCollections(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	queryVectors := make(map[models.EmbeddingModel][]float32)
+	return searchCollections(
+		query,
+		collections,
+		limit,
+		func(collection *models.DocumentCollection, limit int) (*models.DocumentSearchResultPage, error) {
+			payload := &models.DocumentSearchPayload{
+				CollectionName: collection.Name,
+				Text:           query.Text,
+				Embedding:      query.Embedding,
+				Metadata:       query.Metadata,
+				SearchType:     query.SearchType,
+				MMRLambda:      query.MMRLambda,
+			}
+			if query.Text != "" {
+				model, err := llms.GetCollectionEmbeddingModel(ds.appState, collection)
+				if err != nil {
+					return nil, fmt.Errorf("failed to get document embedding model %w", err)
+				}
+				v, ok := queryVectors[*model]
+				if !ok {
+					v, err = llms.EmbedQuery(ctx, ds.appState, model, "document", query.Text)
+					if err != nil {
+						return nil, fmt.Errorf("failed to embed query %w", err)
+					}
+					queryVectors[*model] = v
+				}
+				payload.Text = ""
+				payload.Embedding = v
+			}
+			return ds.SearchCollection(ctx, payload, limit, 0, 0)
+		},
+	)
+}
+
+// getSearchCollections returns the collections named by a query and those whose metadata
+// has a tags array containing the query's tag, without duplicates.
+func (ds *DocumentStore) getSearchCollections(
+	ctx context.Context,
+	query *models.CollectionsSearchPayload,
+) ([]models.DocumentCollection, error) {
+	var collections []models.DocumentCollection
+	seen := make(map[string]bool)
+
+	for _, name := range query.CollectionNames {
+		collection := NewDocumentCollectionDAO(
+			ds.appState,
+			ds.Client,
+			models.DocumentCollection{Name: name},
+		)
+		if err := collection.GetByName(ctx); err != nil {
+			return nil, fmt.Errorf("failed to get collection: %w", err)
+		}
+		if !seen[collection.Name] {
+			seen[collection.Name] = true
+			collections = append(collections, collection.DocumentCollection)
+		}
+	}
+
+	if query.Tag != "" {
+		tags, err := json.Marshal(map[string][]string{"tags": {query.Tag}})
+		if err != nil {
+			return nil, fmt.Errorf("error marshalling tag %w", err)
+		}
+		var records []DocumentCollectionSchema
+		err = ds.Client.NewSelect().
+			Model(&records).
+			Where("metadata @> ?::jsonb", string(tags)).
+			ApplyQueryBuilder(whereProject(ctx)).
+			Order("name").
+			Scan(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get collections tagged %s: %w", query.Tag, err)
+		}
+		for i := range records {
+			if !seen[records[i].Name] {
+				seen[records[i].Name] = true
+				collections = append(collections, records[i].DocumentCollection)
+			}
+		}
+	}
+
+	return collections, nil
+}
+
+// searchCollections searches each collection for up to limit results, normalizes the scores
+// of each collection's results, and returns the top limit results across the collections.
+// Results of searches without text or an embedding are returned in collection order, as they
+// aren't scored.
+func searchCollections(
+	query *models.CollectionsSearchPayload,
+	collections []models.DocumentCollection,
+	limit int,
+	searchCollection func(collection *models.DocumentCollection, limit int) (*models.DocumentSearchResultPage, error),
+) (*models.CollectionsSearchResultPage, error) {
+	if limit <= 0 {
+		limit = DefaultDocumentSearchLimit
+	}
+	scored := query.Text != "" || len(query.Embedding) > 0
+
+	results := make([]models.CollectionsSearchResult, 0)
+	for i := range collections {
+		page, err := searchCollection(&collections[i], limit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search collection %s: %w", collections[i].Name, err)
+		}
+
+		scores := make([]float64, len(page.Results))
+		for j := range page.Results {
+			scores[j] = page.Results[j].Score
+		}
+		if scored && query.ScoreNormalization != models.ScoreNormalizationNone {
+			scores = normalizeMinMax(scores)
+		}
+
+		for j := range page.Results {
+			result := models.CollectionsSearchResult{
+				DocumentSearchResult: page.Results[j],
+				CollectionName:       collections[i].Name,
+				RawScore:             page.Results[j].Score,
+			}
+			result.Score = scores[j]
+			results = append(results, result)
+		}
+	}
+
+	if scored {
+		sort.SliceStable(results, func(i, j int) bool {
+			return results[i].Score > results[j].Score
+		})
+	}
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	return &models.CollectionsSearchResultPage{
+		Results:     results,
+		ResultCount: len(results),
+	}, nil
+}
+
+// normalizeMinMax rescales scores to between 0 and 1. If the scores are equal, they're all 1.
+func normalizeMinMax(scores []float64) []float64 {
+	if len(scores) == 0 {
+		return scores
+	}
+	low, high := scores[0], scores[0]
+	for _, s := range scores {
+		low = min(low, s)
+		high = max(high, s)
+	}
+
+	normalized := make([]float64, len(scores))
+	for i, s := range scores {
+		if high == low {
+			normalized[i] = 1
+			continue
+		}
+		normalized[i] = (s - low) / (high - low)
+	}
+	return normalized
+}
@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/pkg/models"
+)
+
+func TestSearchCollections(t *testing.T) {
+	collections := []models.DocumentCollection{{Name: "first"}, {Name: "second"}}
+	collectionScores := map[string][]float64{
+		"first":  {0.95, 0.9, 0.85},
+		"second": {0.7, 0.6},
+	}
+	searchCollection := func(
+		collection *models.DocumentCollection,
+		limit int,
+	) (*models.DocumentSearchResultPage, error) {
+		page := &models.DocumentSearchResultPage{}
+		for _, score := range collectionScores[collection.Name] {
+			page.Results = append(page.Results, models.DocumentSearchResult{
+				DocumentResponse: &models.DocumentResponse{UUID: uuid.New()},
+				Score:            score,
+			})
+		}
+		return page, nil
+	}
+
+	t.Run("scores are normalized per collection", func(t *testing.T) {
+		query := &models.CollectionsSearchPayload{Text: "travel"}
+		page, err := searchCollections(query, collections, 3, searchCollection)
+		require.NoError(t, err)
+
+		require.Len(t, page.Results, 3)
+		assert.Equal(t, 3, page.ResultCount)
+		assert.Equal(t, "first", page.Results[0].CollectionName)
+		assert.Equal(t, 1.0, page.Results[0].Score)
+		assert.Equal(t, 0.95, page.Results[0].RawScore)
+		assert.Equal(t, "second", page.Results[1].CollectionName)
+		assert.Equal(t, 1.0, page.Results[1].Score)
+		assert.Equal(t, 0.7, page.Results[1].RawScore)
+		assert.Equal(t, "first", page.Results[2].CollectionName)
+		assert.InDelta(t, 0.5, page.Results[2].Score, 1e-9)
+	})
+
+	t.Run("raw scores are merged without normalization", func(t *testing.T) {
+		query := &models.CollectionsSearchPayload{
+			Text:               "travel",
+			ScoreNormalization: models.ScoreNormalizationNone,
+		}
+		page, err := searchCollections(query, collections, 4, searchCollection)
+		require.NoError(t, err)
+
+		require.Len(t, page.Results, 4)
+		for i, score := range []float64{0.95, 0.9, 0.85, 0.7} {
+			assert.Equal(t, score, page.Results[i].Score)
+			assert.Equal(t, score, page.Results[i].RawScore)
+		}
+		assert.Equal(t, "second", page.Results[3].CollectionName)
+	})
+
+	t.Run("collection errors are returned", func(t *testing.T) {
+		query := &models.CollectionsSearchPayload{Text: "travel"}
+		_, err := searchCollections(
+			query,
+			collections,
+			3,
+			func(*models.DocumentCollection, int) (*models.DocumentSearchResultPage, error) {
+				return nil, models.NewNotFoundError("collection")
+			},
+		)
+		assert.True(t, errors.Is(err, models.ErrNotFound))
+	})
+}
+
+func TestNormalizeMinMax(t *testing.T) {
+	assert.Equal(t, []float64{1, 0, 0.5}, normalizeMinMax([]float64{4, 2, 3}))
+	assert.Equal(t, []float64{1, 1}, normalizeMinMax([]float64{0.8, 0.8}))
+	assert.Empty(t, normalizeMinMax(nil))
+}
@@ -2,25 +2,36 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
+	"sort"
+	"time"
 
 	"github.com/getzep/zep/pkg/llms"
 	"github.com/getzep/zep/pkg/models"
 	"github.com/getzep/zep/pkg/search"
 	"github.com/getzep/zep/pkg/store"
+	"github.com/google/uuid"
 	"github.com/pgvector/pgvector-go"
 	"github.com/uptrace/bun"
 )
 
 const DefaultMemorySearchLimit = 10
 
+// JSONQuery is a metadata filter. A JSONPath matches metadata for which the jsonpath
+// returns an item. A Field compares the metadata value at Field with Value using Op, where
+// Field is a jsonpath or dot-separated metadata keys. And, Or and Not combine filters.
 type JSONQuery struct {
 	JSONPath string       `json:"jsonpath"`
+	Field    string       `json:"field,omitempty"`
+	Op       FilterOp     `json:"op,omitempty"`
+	Value    interface{}  `json:"value,omitempty"`
 	And      []*JSONQuery `json:"and,omitempty"`
 	Or       []*JSONQuery `json:"or,omitempty"`
+	Not      *JSONQuery   `json:"not,omitempty"`
 }
 
 func searchMemory(
@@ -35,20 +46,38 @@ func searchMemory(
 		return nil, store.NewStorageError("nil query or appState received", nil)
 	}
 
-	if query.Text == "" && len(query.Metadata) == 0 {
+	if isEmptyMemorySearch(query) {
 		return nil, errors.New("empty query")
 	}
 
+	if err := validateMemorySearchPayload(query); err != nil {
+		return nil, err
+	}
+
+	if query.SearchType == models.SearchTypeHybrid && query.SearchScope != models.SearchScopeAll {
+		return searchMessagesHybrid(ctx, appState, db, sessionID, query, limit)
+	}
+
 	var dbQuery *bun.SelectQuery
 	var tablePrefix string
+	var documentType string
 
 	switch query.SearchScope {
 	case models.SearchScopeMessages, "":
 		dbQuery = buildMessageSearchQuery(ctx, db, query)
 		tablePrefix = "m"
+		documentType = "message"
 	case models.SearchScopeSummary:
 		dbQuery = buildSummarySearchQuery(ctx, db, query)
 		tablePrefix = "s"
+		documentType = "summary"
+	case models.SearchScopeAll:
+		return searchAllMemory(query, limit, func(
+			scopeQuery *models.MemorySearchPayload,
+			limit int,
+		) ([]models.MemorySearchResult, error) {
+			return searchMemory(ctx, appState, db, sessionID, scopeQuery, limit)
+		})
 	default:
 		return nil, errors.New("invalid search scope")
 	}
@@ -56,7 +85,13 @@ func searchMemory(
 	var err error
 	var queryEmbedding []float32
 	if query.Text != "" {
-		dbQuery, queryEmbedding, err = addMemoryVectorColumn(ctx, appState, dbQuery, query.Text)
+		dbQuery, queryEmbedding, err = addMemoryVectorColumn(
+			ctx,
+			appState,
+			dbQuery,
+			documentType,
+			query.Text,
+		)
 		if err != nil {
 			return nil, store.NewStorageError("error adding vector column", err)
 		}
@@ -69,6 +104,8 @@ func searchMemory(
 		}
 	}
 
+	dbQuery = applyMemoryDateFilter(dbQuery, query, tablePrefix)
+
 	dbQuery = dbQuery.Where("?.session_id = ?", bun.Safe(tablePrefix), sessionID)
 
 	// Ensure we don't return deleted records.
@@ -96,7 +133,12 @@ func searchMemory(
 		dbQuery = dbQuery.Limit(limit)
 	}
 
-	results, err := executeMessagesSearchScan(ctx, dbQuery)
+	var results []models.MemorySearchResult
+	if query.Text != "" {
+		results, err = executeMemoryVectorSearchScan(ctx, appState, db, dbQuery)
+	} else {
+		results, err = executeMessagesSearchScan(ctx, dbQuery)
+	}
 	if err != nil {
 		return nil, store.NewStorageError("memory searchMemory failed", err)
 	}
@@ -119,6 +161,212 @@ func searchMemory(
 	return filteredResults, nil
 }
 
+// searchAllMemory searches messages and summaries separately using searchScope, and fuses
+// the two rankings using Reciprocal Rank Fusion. The Dist of each result is its fused score.
+// Summaries aren't full-text indexed, so a hybrid search only applies to messages.
+func searchAllMemory(
+	query *models.MemorySearchPayload,
+	limit int,
+	searchScope func(
+		query *models.MemorySearchPayload,
+		limit int,
+	) ([]models.MemorySearchResult, error),
+) ([]models.MemorySearchResult, error) {
+	if err := validateMemorySearchPayload(query); err != nil {
+		return nil, err
+	}
+	if limit == 0 {
+		limit = DefaultMemorySearchLimit
+	}
+
+	resultMap := make(map[uuid.UUID]models.MemorySearchResult)
+	var rankings [][]uuid.UUID
+	scopes := []models.SearchScope{models.SearchScopeMessages, models.SearchScopeSummary}
+	for _, scope := range scopes {
+		scopeQuery := *query
+		scopeQuery.SearchScope = scope
+		if scope == models.SearchScopeSummary &&
+			scopeQuery.SearchType == models.SearchTypeHybrid {
+			scopeQuery.SearchType = models.SearchTypeSimilarity
+		}
+
+		results, err := searchScope(&scopeQuery, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		ranking := make([]uuid.UUID, 0, len(results))
+		for _, r := range results {
+			var id uuid.UUID
+			switch {
+			case r.Message != nil:
+				id = r.Message.UUID
+			case r.Summary != nil:
+				id = r.Summary.UUID
+			default:
+				continue
+			}
+			resultMap[id] = r
+			ranking = append(ranking, id)
+		}
+		rankings = append(rankings, ranking)
+	}
+
+	fused := search.ReciprocalRankFusion(search.DefaultRRFConstant, rankings...)
+	if len(fused) > limit {
+		fused = fused[:limit]
+	}
+
+	results := make([]models.MemorySearchResult, len(fused))
+	for i, f := range fused {
+		results[i] = resultMap[f.Item]
+		results[i].Dist = f.Score
+	}
+
+	return results, nil
+}
+
+// validateMemorySearchPayload returns a bad request error if the search type is unknown,
+// if an MMR search has no query text or a lambda outside of 0 to 1, if a reranked search
+// has no query text or is an MMR search, if a search of all scopes has no query text, if a
+// recency half-life is negative or set without query text, or if the end date is before the
+// start date
+func validateMemorySearchPayload(query *models.MemorySearchPayload) error {
+	switch query.SearchType {
+	case models.SearchTypeSimilarity, models.SearchTypeHybrid, "":
+	case models.SearchTypeMMR:
+		if query.Text == "" {
+			return models.NewBadRequestError("mmr search requires query text")
+		}
+		if query.MMRLambda < 0 || query.MMRLambda > 1 {
+			return models.NewBadRequestError("mmr_lambda must be between 0 and 1")
+		}
+		if query.Rerank {
+			return models.NewBadRequestError("mmr search can't be reranked")
+		}
+	default:
+		return models.NewBadRequestError("invalid search type: " + string(query.SearchType))
+	}
+
+	if query.Rerank && query.Text == "" {
+		return models.NewBadRequestError("rerank requires query text")
+	}
+
+	if query.RecencyHalfLifeDays < 0 {
+		return models.NewBadRequestError("recency_half_life_days must not be negative")
+	}
+	if query.RecencyHalfLifeDays > 0 && query.Text == "" {
+		return models.NewBadRequestError("recency_half_life_days requires query text")
+	}
+
+	if query.SearchScope == models.SearchScopeAll && query.Text == "" {
+		return models.NewBadRequestError("search_scope all requires query text")
+	}
+
+	if query.StartDate != nil && query.EndDate != nil && query.EndDate.Before(*query.StartDate) {
+		return models.NewBadRequestError("end_date must not be before start_date")
+	}
+
+	return nil
+}
+
+// rerankMemorySearch retrieves DefaultRerankMultiplier times limit candidates using search,
+// and returns the limit most relevant to the query text according to the configured
+// reranker. The Dist of each result is its relevance score.
+func rerankMemorySearch(
+	ctx context.Context,
+	appState *models.AppState,
+	query *models.MemorySearchPayload,
+	limit int,
+	search func(limit int) ([]models.MemorySearchResult, error),
+) ([]models.MemorySearchResult, error) {
+	if limit == 0 {
+		limit = DefaultMemorySearchLimit
+	}
+
+	reranker, err := llms.NewReranker(appState)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := search(limit * DefaultRerankMultiplier)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return candidates, nil
+	}
+
+	texts := make([]string, len(candidates))
+	for i, c := range candidates {
+		if c.Summary != nil {
+			texts[i] = c.Summary.Content
+		} else if c.Message != nil {
+			texts[i] = c.Message.Content
+		}
+	}
+
+	rerankResults, err := reranker.Rerank(ctx, query.Text, texts)
+	if err != nil {
+		return nil, store.NewStorageError("error reranking results", err)
+	}
+	if len(rerankResults) > limit {
+		rerankResults = rerankResults[:limit]
+	}
+
+	results := make([]models.MemorySearchResult, len(rerankResults))
+	for i, r := range rerankResults {
+		results[i] = candidates[r.Index]
+		results[i].Dist = r.Score
+	}
+
+	return results, nil
+}
+
+// decayMemorySearch retrieves DefaultRerankMultiplier times limit candidates using search,
+// weights the Dist of each by its age at now, halving it every query.RecencyHalfLifeDays, and
+// returns the limit highest weighted.
+func decayMemorySearch(
+	query *models.MemorySearchPayload,
+	limit int,
+	now time.Time,
+	search func(limit int) ([]models.MemorySearchResult, error),
+) ([]models.MemorySearchResult, error) {
+	if limit == 0 {
+		limit = DefaultMemorySearchLimit
+	}
+
+	results, err := search(limit * DefaultRerankMultiplier)
+	if err != nil {
+		return nil, err
+	}
+
+	halfLife := time.Duration(query.RecencyHalfLifeDays * float64(24*time.Hour))
+	for i, r := range results {
+		var createdAt time.Time
+		switch {
+		case r.Message != nil:
+			createdAt = r.Message.CreatedAt
+		case r.Summary != nil:
+			createdAt = r.Summary.CreatedAt
+		}
+		age := now.Sub(createdAt)
+		if age < 0 {
+			age = 0
+		}
+		results[i].Dist = r.Dist * math.Pow(0.5, float64(age)/float64(halfLife))
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Dist > results[j].Dist
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results, nil
+}
+
 // rerankMMR reranks the results using the Maximal Marginal Relevance algorithm
 func rerankMMR(
 	results []models.MemorySearchResult,
@@ -151,9 +399,11 @@ func buildMessageSearchQuery(
 	db *bun.DB,
 	query *models.MemorySearchPayload,
 ) *bun.SelectQuery {
+	// stale embeddings, of messages whose content has been updated, aren't searched
 	dbQuery := db.NewSelect().TableExpr("message_embedding AS me").
 		Join("JOIN message AS m").
 		JoinOn("me.message_uuid = m.uuid").
+		Where("me.is_embedded").
 		ColumnExpr("m.uuid AS message__uuid").
 		ColumnExpr("m.created_at AS message__created_at").
 		ColumnExpr("m.role AS message__role").
@@ -207,6 +457,9 @@ func applyMemoryMetadataFilter(
 		if err != nil {
 			return nil, store.NewStorageError("error unmarshalling metadata", err)
 		}
+		if err := jq.validate(); err != nil {
+			return nil, err
+		}
 		qb = parseJSONQuery(qb, &jq, false, tablePrefix)
 	}
 
@@ -239,6 +492,38 @@ func executeMessagesSearchScan(
 	return results, nil
 }
 
+// executeMemoryVectorSearchScan executes a query ordered by vector distance. If HNSW
+// indexes are available, the query is run in a transaction that sets hnsw.ef_search.
+func executeMemoryVectorSearchScan(
+	ctx context.Context,
+	appState *models.AppState,
+	db *bun.DB,
+	dbQuery *bun.SelectQuery,
+) ([]models.MemorySearchResult, error) {
+	if !appState.Config.Store.Postgres.AvailableIndexes.HSNW {
+		return executeMessagesSearchScan(ctx, dbQuery)
+	}
+
+	var results []models.MemorySearchResult
+	// run in transaction to set LOCAL
+	err := db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.ExecContext(
+			ctx,
+			"SET LOCAL hnsw.ef_search = ?",
+			hnswEFSearch(&appState.Config.Store.Postgres.HNSW),
+		)
+		if err != nil {
+			return fmt.Errorf("error setting ef_search: %w", err)
+		}
+		results, err = executeMessagesSearchScan(ctx, dbQuery.Conn(tx))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
 func filterValidMessageSearchResults(
 	results []models.MemorySearchResult,
 	metadata map[string]interface{},
@@ -252,6 +537,29 @@ func filterValidMessageSearchResults(
 	return filteredResults
 }
 
+// isEmptyMemorySearch returns true if the search has no query text, metadata or dates to
+// search by
+func isEmptyMemorySearch(query *models.MemorySearchPayload) bool {
+	return query.Text == "" && len(query.Metadata) == 0 &&
+		query.StartDate == nil && query.EndDate == nil
+}
+
+// applyMemoryDateFilter limits the query to messages or summaries created between the
+// search's start and end dates
+func applyMemoryDateFilter(
+	dbQuery *bun.SelectQuery,
+	query *models.MemorySearchPayload,
+	tablePrefix string,
+) *bun.SelectQuery {
+	if query.StartDate != nil {
+		dbQuery = dbQuery.Where("?.created_at >= ?", bun.Safe(tablePrefix), *query.StartDate)
+	}
+	if query.EndDate != nil {
+		dbQuery = dbQuery.Where("?.created_at <= ?", bun.Safe(tablePrefix), *query.EndDate)
+	}
+	return dbQuery
+}
+
 // addMessageDateFilters adds date filters to the query
 func addMessageDateFilters(qb *bun.QueryBuilder, m map[string]any, tablePrefix string) {
 	if startDate, ok := m["start_date"]; ok {
@@ -262,24 +570,44 @@ func addMessageDateFilters(qb *bun.QueryBuilder, m map[string]any, tablePrefix s
 	}
 }
 
-// addMemoryVectorColumn adds a column to the query that calculates the distance between the query text and the message embedding
+// addMemoryVectorColumn adds a column to the query that calculates the distance between the
+// query text and the message or summary embedding
 func addMemoryVectorColumn(
 	ctx context.Context,
 	appState *models.AppState,
 	q *bun.SelectQuery,
+	documentType string,
 	queryText string,
 ) (*bun.SelectQuery, []float32, error) {
-	documentType := "message"
+	e, err := embedMemoryQuery(ctx, appState, documentType, queryText)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	vector := pgvector.NewVector(e)
+	return q.ColumnExpr("(embedding <#> ?) * -1 AS dist", vector), e, nil
+}
+
+// embedMemoryQuery embeds the query text using the embedding model of documentType, either
+// "message" or "summary"
+func embedMemoryQuery(
+	ctx context.Context,
+	appState *models.AppState,
+	documentType string,
+	queryText string,
+) ([]float32, error) {
 	model, err := llms.GetEmbeddingModel(appState, documentType)
 	if err != nil {
-		return nil, nil, store.NewStorageError("failed to get message embedding model", err)
+		return nil, store.NewStorageError(
+			fmt.Sprintf("failed to get %s embedding model", documentType),
+			err,
+		)
 	}
 
-	e, err := llms.EmbedTexts(ctx, appState, model, documentType, []string{queryText})
+	e, err := llms.EmbedQuery(ctx, appState, model, documentType, queryText)
 	if err != nil {
-		return nil, nil, store.NewStorageError("failed to embed query", err)
+		return nil, store.NewStorageError("failed to embed query", err)
 	}
 
-	vector := pgvector.NewVector(e[0])
-	return q.ColumnExpr("(embedding <#> ?) * -1 AS dist", vector), e[0], nil
+	return e, nil
 }
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/uptrace/bun"
 
 	"github.com/getzep/zep/pkg/models"
@@ -68,6 +69,15 @@ DONE:
 		{"Limit 5", "travel", 5, "", models.SearchScopeMessages, models.SearchTypeSimilarity},
 		{"Limit 5 Empty SearchScope", "travel", 5, "", "", models.SearchTypeSimilarity},
 		{"MMR Query", "travel", 5, "", models.SearchScopeMessages, models.SearchTypeMMR},
+		{"Hybrid Query", "travel", 5, "", models.SearchScopeMessages, models.SearchTypeHybrid},
+		{
+			"SearchScope Summary Hybrid",
+			"travel",
+			1,
+			"hybrid search is only supported for messages",
+			models.SearchScopeSummary,
+			models.SearchTypeHybrid,
+		},
 		{
 			"SearchScope Summary",
 			"travel",
@@ -133,6 +143,181 @@ DONE:
 	}
 }
 
+func TestValidateMemorySearchPayload(t *testing.T) {
+	startDate := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2022, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name              string
+		query             models.MemorySearchPayload
+		expectedErrorText string
+	}{
+		{"Similarity", models.MemorySearchPayload{Text: "travel"}, ""},
+		{
+			"MMR",
+			models.MemorySearchPayload{
+				Text:       "travel",
+				SearchType: models.SearchTypeMMR,
+				MMRLambda:  0.7,
+			},
+			"",
+		},
+		{
+			"MMR Without Text",
+			models.MemorySearchPayload{
+				Metadata:   map[string]interface{}{"start_date": "2023-01-01"},
+				SearchType: models.SearchTypeMMR,
+			},
+			"mmr search requires query text",
+		},
+		{
+			"MMR Lambda Out Of Range",
+			models.MemorySearchPayload{
+				Text:       "travel",
+				SearchType: models.SearchTypeMMR,
+				MMRLambda:  1.5,
+			},
+			"mmr_lambda must be between 0 and 1",
+		},
+		{
+			"Rerank Without Text",
+			models.MemorySearchPayload{
+				Metadata: map[string]interface{}{"start_date": "2023-01-01"},
+				Rerank:   true,
+			},
+			"rerank requires query text",
+		},
+		{
+			"Rerank MMR",
+			models.MemorySearchPayload{
+				Text:       "travel",
+				SearchType: models.SearchTypeMMR,
+				Rerank:     true,
+			},
+			"mmr search can't be reranked",
+		},
+		{
+			"End Date Before Start Date",
+			models.MemorySearchPayload{
+				Text:      "travel",
+				StartDate: &endDate,
+				EndDate:   &startDate,
+			},
+			"end_date must not be before start_date",
+		},
+		{
+			"Invalid Search Type",
+			models.MemorySearchPayload{Text: "travel", SearchType: "keyword"},
+			"invalid search type: keyword",
+		},
+		{
+			"Negative Recency Half-Life",
+			models.MemorySearchPayload{Text: "travel", RecencyHalfLifeDays: -1},
+			"recency_half_life_days must not be negative",
+		},
+		{
+			"Recency Half-Life Without Text",
+			models.MemorySearchPayload{
+				Metadata:            map[string]interface{}{"start_date": "2023-01-01"},
+				RecencyHalfLifeDays: 30,
+			},
+			"recency_half_life_days requires query text",
+		},
+		{
+			"SearchScope All Without Text",
+			models.MemorySearchPayload{
+				Metadata:    map[string]interface{}{"start_date": "2023-01-01"},
+				SearchScope: models.SearchScopeAll,
+			},
+			"search_scope all requires query text",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateMemorySearchPayload(&tc.query)
+			if tc.expectedErrorText != "" {
+				assert.ErrorContains(t, err, tc.expectedErrorText)
+				assert.ErrorIs(t, err, models.ErrBadRequest)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSearchAllMemory(t *testing.T) {
+	messages := []models.Message{{UUID: uuid.New()}, {UUID: uuid.New()}}
+	summaries := []models.Summary{{UUID: uuid.New()}, {UUID: uuid.New()}}
+
+	var searchTypes []models.SearchType
+	searchScope := func(
+		query *models.MemorySearchPayload,
+		limit int,
+	) ([]models.MemorySearchResult, error) {
+		assert.Equal(t, 3, limit)
+		searchTypes = append(searchTypes, query.SearchType)
+		if query.SearchScope == models.SearchScopeSummary {
+			return []models.MemorySearchResult{
+				{Summary: &summaries[0]},
+				{Summary: &summaries[1]},
+			}, nil
+		}
+		return []models.MemorySearchResult{
+			{Message: &messages[0]},
+			{Message: &messages[1]},
+		}, nil
+	}
+
+	query := &models.MemorySearchPayload{
+		Text:        "travel",
+		SearchScope: models.SearchScopeAll,
+		SearchType:  models.SearchTypeHybrid,
+	}
+	results, err := searchAllMemory(query, 3, searchScope)
+	assert.NoError(t, err)
+
+	// the top ranked message and summary tie, and the message is ranked first
+	assert.Len(t, results, 3)
+	assert.Equal(t, &messages[0], results[0].Message)
+	assert.Equal(t, &summaries[0], results[1].Summary)
+	assert.Equal(t, results[0].Dist, results[1].Dist)
+	assert.Equal(t, &messages[1], results[2].Message)
+	assert.Greater(t, results[1].Dist, results[2].Dist)
+
+	// summaries aren't full-text indexed, so only messages are searched hybrid
+	assert.Equal(
+		t,
+		[]models.SearchType{models.SearchTypeHybrid, models.SearchTypeSimilarity},
+		searchTypes,
+	)
+}
+
+func TestDecayMemorySearch(t *testing.T) {
+	now := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	old := &models.Message{Content: "old", CreatedAt: now.AddDate(0, 0, -60)}
+	recent := &models.Message{Content: "recent", CreatedAt: now.AddDate(0, 0, -30)}
+	summary := &models.Summary{Content: "summary", CreatedAt: now}
+
+	search := func(limit int) ([]models.MemorySearchResult, error) {
+		assert.Equal(t, 2*DefaultRerankMultiplier, limit)
+		return []models.MemorySearchResult{
+			{Message: old, Dist: 0.9},
+			{Message: recent, Dist: 0.8},
+			{Summary: summary, Dist: 0.3},
+		}, nil
+	}
+
+	query := &models.MemorySearchPayload{Text: "travel", RecencyHalfLifeDays: 30}
+	results, err := decayMemorySearch(query, 2, now, search)
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, recent, results[0].Message)
+	assert.InDelta(t, 0.4, results[0].Dist, 0.0001)
+	assert.Equal(t, summary, results[1].Summary)
+	assert.InDelta(t, 0.3, results[1].Dist, 0.0001)
+}
+
 func TestAddDateFilters(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -181,3 +366,38 @@ func TestAddDateFilters(t *testing.T) {
 		})
 	}
 }
+
+func TestApplyMemoryDateFilter(t *testing.T) {
+	startDate := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2022, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		query        models.MemorySearchPayload
+		expectedCond string
+	}{
+		{
+			name:         "Start Date only",
+			query:        models.MemorySearchPayload{StartDate: &startDate},
+			expectedCond: `WHERE (s.created_at >= '2022-01-01 00:00:00+00:00')`,
+		},
+		{
+			name:  "Start and End Dates",
+			query: models.MemorySearchPayload{StartDate: &startDate, EndDate: &endDate},
+			expectedCond: `WHERE (s.created_at >= '2022-01-01 00:00:00+00:00') ` +
+				`AND (s.created_at <= '2022-01-31 00:00:00+00:00')`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbQuery := testDB.NewSelect().TableExpr("summary AS s")
+			dbQuery = applyMemoryDateFilter(dbQuery, &tt.query, "s")
+
+			sql := dbQuery.String()
+			whereIndex := strings.Index(sql, "WHERE")
+			assert.True(t, whereIndex > 0, "WHERE clause should be present")
+			assert.Equal(t, tt.expectedCond, sql[whereIndex:])
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/store"
+	"github.com/uptrace/bun"
+)
+
+// searchUserMemory searches each of a user's sessions using searchSession, so that every
+// search type and vector store is supported. The limit best results across the sessions are
+// returned grouped by session, with the groups ordered by their best result. Results are
+// ranked by Dist, or by creation date if there's no query text to rank them by.
+func searchUserMemory(
+	ctx context.Context,
+	db *bun.DB,
+	userID string,
+	query *models.MemorySearchPayload,
+	limit int,
+	searchSession func(sessionID string, limit int) ([]models.MemorySearchResult, error),
+) ([]models.UserMemorySearchResult, error) {
+	if query == nil {
+		return nil, store.NewStorageError("nil query received", nil)
+	}
+	if isEmptyMemorySearch(query) {
+		return nil, errors.New("empty query")
+	}
+
+	if limit == 0 {
+		limit = DefaultMemorySearchLimit
+	}
+
+	userStore := NewUserStoreDAO(db)
+	if _, err := userStore.Get(ctx, userID); err != nil {
+		return nil, err
+	}
+	sessions, err := userStore.GetSessions(ctx, userID)
+	if err != nil {
+		return nil, store.NewStorageError("failed to get user sessions", err)
+	}
+
+	type sessionResult struct {
+		sessionID string
+		result    models.MemorySearchResult
+	}
+	var results []sessionResult
+	for _, session := range sessions {
+		sessionResults, err := searchSession(session.SessionID, limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range sessionResults {
+			results = append(results, sessionResult{sessionID: session.SessionID, result: r})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if query.Text != "" {
+			return results[i].result.Dist > results[j].result.Dist
+		}
+		return memorySearchResultCreatedAt(results[i].result).
+			After(memorySearchResultCreatedAt(results[j].result))
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	groups := make([]models.UserMemorySearchResult, 0)
+	groupIndexes := make(map[string]int)
+	for _, r := range results {
+		i, ok := groupIndexes[r.sessionID]
+		if !ok {
+			i = len(groups)
+			groupIndexes[r.sessionID] = i
+			groups = append(groups, models.UserMemorySearchResult{SessionID: r.sessionID})
+		}
+		groups[i].Results = append(groups[i].Results, r.result)
+	}
+
+	return groups, nil
+}
+
+// memorySearchResultCreatedAt returns the creation date of a result's message or summary
+func memorySearchResultCreatedAt(result models.MemorySearchResult) time.Time {
+	if result.Summary != nil {
+		return result.Summary.CreatedAt
+	}
+	if result.Message != nil {
+		return result.Message.CreatedAt
+	}
+	return time.Time{}
+}
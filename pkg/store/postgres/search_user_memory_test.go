@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/testutils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchUserMemory(t *testing.T) {
+	userID := testutils.GenerateRandomString(16)
+	_, err := NewUserStoreDAO(testDB).Create(testCtx, &models.CreateUserRequest{UserID: userID})
+	require.NoError(t, err)
+
+	sessionDists := map[string][]float64{}
+	sessionStore := NewSessionDAO(testDB)
+	for _, dists := range [][]float64{{0.9, 0.5}, {0.8, 0.7}} {
+		sessionID, err := testutils.GenerateRandomSessionID(16)
+		require.NoError(t, err)
+		_, err = sessionStore.Create(testCtx, &models.CreateSessionRequest{
+			SessionID: sessionID,
+			UserID:    &userID,
+		})
+		require.NoError(t, err)
+		sessionDists[sessionID] = dists
+	}
+
+	searchSession := func(sessionID string, limit int) ([]models.MemorySearchResult, error) {
+		var results []models.MemorySearchResult
+		for _, dist := range sessionDists[sessionID] {
+			results = append(results, models.MemorySearchResult{
+				Message: &models.Message{UUID: uuid.New()},
+				Dist:    dist,
+			})
+		}
+		return results, nil
+	}
+
+	t.Run("results are grouped by session", func(t *testing.T) {
+		query := &models.MemorySearchPayload{Text: "travel"}
+		groups, err := searchUserMemory(testCtx, testDB, userID, query, 3, searchSession)
+		require.NoError(t, err)
+
+		require.Len(t, groups, 2)
+		assert.Len(t, groups[0].Results, 1)
+		assert.Equal(t, 0.9, groups[0].Results[0].Dist)
+		require.Len(t, groups[1].Results, 2)
+		assert.Equal(t, 0.8, groups[1].Results[0].Dist)
+		assert.Equal(t, 0.7, groups[1].Results[1].Dist)
+		assert.Equal(t, sessionDists[groups[1].SessionID], []float64{0.8, 0.7})
+	})
+
+	t.Run("non-existent user returns not found", func(t *testing.T) {
+		query := &models.MemorySearchPayload{Text: "travel"}
+		_, err := searchUserMemory(testCtx, testDB, "nonexistent", query, 3, searchSession)
+		assert.ErrorIs(t, err, models.ErrNotFound)
+	})
+}
@@ -1,15 +1,94 @@
 package postgres
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 
+	"github.com/getzep/zep/pkg/models"
 	"github.com/uptrace/bun"
 )
 
 const DefaultMMRMultiplier = 2
 const DefaultMMRLambda = 0.5
 
+// DefaultHybridMultiplier is the number of candidates, as a multiple of the limit, ranked by
+// each of the searches combined by hybrid search
+const DefaultHybridMultiplier = 3
+
+// DefaultRerankMultiplier is the number of candidates, as a multiple of the limit, retrieved
+// for reranking
+const DefaultRerankMultiplier = 3
+
+// FilterOp is the comparison operator of a JSONQuery field filter
+type FilterOp string
+
+const (
+	FilterOpEq         FilterOp = "eq"
+	FilterOpNe         FilterOp = "ne"
+	FilterOpGt         FilterOp = "gt"
+	FilterOpGte        FilterOp = "gte"
+	FilterOpLt         FilterOp = "lt"
+	FilterOpLte        FilterOp = "lte"
+	FilterOpIn         FilterOp = "in"
+	FilterOpStartsWith FilterOp = "starts_with"
+	FilterOpExists     FilterOp = "exists"
+)
+
+// filterOpPredicates are the jsonpath predicates of the filter operators. $value is passed
+// to jsonb_path_exists as a variable, so values are never interpolated into the jsonpath.
+var filterOpPredicates = map[FilterOp]string{
+	FilterOpEq:         "@ == $value",
+	FilterOpNe:         "@ != $value",
+	FilterOpGt:         "@ > $value",
+	FilterOpGte:        "@ >= $value",
+	FilterOpLt:         "@ < $value",
+	FilterOpLte:        "@ <= $value",
+	FilterOpIn:         "@ == $value[*]",
+	FilterOpStartsWith: "@ starts with $value",
+}
+
+// validate returns a bad request error if a field filter has an unknown operator, or a
+// value that the operator can't compare with
+func (jq *JSONQuery) validate() error {
+	if jq.Field != "" {
+		switch jq.Op {
+		case FilterOpExists:
+		case FilterOpIn:
+			if _, ok := jq.Value.([]interface{}); !ok {
+				return models.NewBadRequestError("the value of an in filter must be an array")
+			}
+		case FilterOpStartsWith:
+			if _, ok := jq.Value.(string); !ok {
+				return models.NewBadRequestError(
+					"the value of a starts_with filter must be a string",
+				)
+			}
+		case FilterOpEq, FilterOpNe, FilterOpGt, FilterOpGte, FilterOpLt, FilterOpLte:
+			if jq.Value == nil {
+				return models.NewBadRequestError(
+					fmt.Sprintf("a value is required by the %s filter", jq.Op),
+				)
+			}
+		default:
+			return models.NewBadRequestError(fmt.Sprintf("invalid filter op: %s", jq.Op))
+		}
+	} else if jq.Op != "" {
+		return models.NewBadRequestError("a field is required by the " + string(jq.Op) + " filter")
+	}
+
+	for _, subQuery := range append(jq.And, jq.Or...) {
+		if err := subQuery.validate(); err != nil {
+			return err
+		}
+	}
+	if jq.Not != nil {
+		return jq.Not.validate()
+	}
+
+	return nil
+}
+
 // parseJSONQuery recursively parses a JSONQuery and returns a bun.QueryBuilder.
 // TODO: fix the addition of extraneous parentheses in the query
 func parseJSONQuery(
@@ -17,30 +96,59 @@ func parseJSONQuery(
 	jq *JSONQuery,
 	isOr bool,
 	tablePrefix string,
+) bun.QueryBuilder {
+	return buildJSONQuery(qb, jq, isOr, false, tablePrefix)
+}
+
+// buildJSONQuery adds the conditions of a JSONQuery to qb. Negated queries are built using
+// De Morgan's laws, so that Not doesn't depend on how bun separates where groups.
+func buildJSONQuery(
+	qb bun.QueryBuilder,
+	jq *JSONQuery,
+	isOr bool,
+	negate bool,
+	tablePrefix string,
 ) bun.QueryBuilder {
 	var tp string
 	if tablePrefix != "" {
 		tp = tablePrefix + "."
 	}
+	where := func(qb bun.QueryBuilder, query string, args ...interface{}) bun.QueryBuilder {
+		if negate {
+			query = "NOT " + query
+		}
+		if isOr {
+			return qb.WhereOr(query, args...)
+		}
+		return qb.Where(query, args...)
+	}
+
 	if jq.JSONPath != "" {
 		path := strings.ReplaceAll(jq.JSONPath, "'", "\"")
-		if isOr {
-			qb = qb.WhereOr(
-				fmt.Sprintf("jsonb_path_exists(%smetadata, ?)", tp),
-				path,
-			)
+		qb = where(qb, fmt.Sprintf("jsonb_path_exists(%smetadata, ?)", tp), path)
+	}
+
+	if jq.Field != "" {
+		path := filterFieldPath(jq.Field)
+		if jq.Op == FilterOpExists {
+			qb = where(qb, fmt.Sprintf("jsonb_path_exists(%smetadata, ?)", tp), path)
 		} else {
-			qb = qb.Where(
-				fmt.Sprintf("jsonb_path_exists(%smetadata, ?)", tp),
-				path,
+			// validate ensures the value can be marshalled
+			vars, _ := json.Marshal(map[string]interface{}{"value": jq.Value})
+			qb = where(
+				qb,
+				fmt.Sprintf("jsonb_path_exists(%smetadata, ?, ?)", tp),
+				fmt.Sprintf("%s ? (%s)", path, filterOpPredicates[jq.Op]),
+				string(vars),
 			)
 		}
 	}
 
+	// Negating a group swaps AND and OR
 	if len(jq.And) > 0 {
 		qb = qb.WhereGroup(" AND ", func(qq bun.QueryBuilder) bun.QueryBuilder {
 			for _, subQuery := range jq.And {
-				qq = parseJSONQuery(qq, subQuery, false, tablePrefix)
+				qq = buildJSONQuery(qq, subQuery, negate, negate, tablePrefix)
 			}
 			return qq
 		})
@@ -49,15 +157,40 @@ func parseJSONQuery(
 	if len(jq.Or) > 0 {
 		qb = qb.WhereGroup(" AND ", func(qq bun.QueryBuilder) bun.QueryBuilder {
 			for _, subQuery := range jq.Or {
-				qq = parseJSONQuery(qq, subQuery, true, tablePrefix)
+				qq = buildJSONQuery(qq, subQuery, !negate, negate, tablePrefix)
 			}
 			return qq
 		})
 	}
 
+	if jq.Not != nil {
+		qb = qb.WhereGroup(" AND ", func(qq bun.QueryBuilder) bun.QueryBuilder {
+			return buildJSONQuery(qq, jq.Not, false, !negate, tablePrefix)
+		})
+	}
+
 	return qb
 }
 
+// filterFieldPath returns the jsonpath of a filter field. Fields starting with $ are
+// jsonpaths. Otherwise, they're dot-separated metadata keys, which are quoted so that keys
+// may contain any character other than a dot.
+func filterFieldPath(field string) string {
+	if strings.HasPrefix(field, "$") {
+		return field
+	}
+
+	var path strings.Builder
+	path.WriteString("$")
+	for _, key := range strings.Split(field, ".") {
+		key = strings.ReplaceAll(key, `\`, `\\`)
+		key = strings.ReplaceAll(key, `"`, `\"`)
+		path.WriteString(`."` + key + `"`)
+	}
+
+	return path.String()
+}
+
 func getAscDesc(asc bool) string {
 	if asc {
 		return "ASC"
@@ -35,6 +35,24 @@ func TestParseJSONQuery(t *testing.T) {
 			expectedCond: `WHERE ((jsonb_path_exists(m.metadata, '$.system.entities[*] ? (@.Label == "DATE")')) AND (jsonb_path_exists(m.metadata, '$.system.entities[*] ? (@.Label == "ORG")')) AND ((jsonb_path_exists(m.metadata, '$.system.entities[*] ? (@.Name == "Iceland")')) OR (jsonb_path_exists(m.metadata, '$.system.entities[*] ? (@.Name == "Canada")'))))`,
 			tablePrefix:  "m",
 		},
+		{
+			name:         "Field Comparison",
+			jsonQuery:    `{"where": {"and": [{"field": "$.count", "op": "gte", "value": 3},{"field": "tags", "op": "in", "value": ["a", "b"]}]}}`,
+			expectedCond: `WHERE ((jsonb_path_exists(m.metadata, '$.count ? (@ >= $value)', '{"value":3}')) AND (jsonb_path_exists(m.metadata, '$."tags" ? (@ == $value[*])', '{"value":["a","b"]}')))`,
+			tablePrefix:  "m",
+		},
+		{
+			name:         "Not",
+			jsonQuery:    `{"where": {"not": {"or": [{"jsonpath": "$.a"},{"field": "user.name", "op": "starts_with", "value": "Jo"}]}}}`,
+			expectedCond: `WHERE (((NOT jsonb_path_exists(m.metadata, '$.a')) AND (NOT jsonb_path_exists(m.metadata, '$."user"."name" ? (@ starts with $value)', '{"value":"Jo"}'))))`,
+			tablePrefix:  "m",
+		},
+		{
+			name:         "Exists And Not",
+			jsonQuery:    `{"where": {"and": [{"field": "x", "op": "exists"},{"not": {"field": "y", "op": "ne", "value": false}}]}}`,
+			expectedCond: `WHERE ((jsonb_path_exists(metadata, '$."x"')) AND ((NOT jsonb_path_exists(metadata, '$."y" ? (@ != $value)', '{"value":false}'))))`,
+			tablePrefix:  "",
+		},
 	}
 
 	for _, tt := range tests {
@@ -69,3 +87,50 @@ func TestParseJSONQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestJSONQueryValidate(t *testing.T) {
+	tests := []struct {
+		name              string
+		jsonQuery         JSONQuery
+		expectedErrorText string
+	}{
+		{
+			name:      "Valid",
+			jsonQuery: JSONQuery{Field: "topic", Op: FilterOpEq, Value: "travel"},
+		},
+		{
+			name: "Nested Invalid Op",
+			jsonQuery: JSONQuery{
+				Not: &JSONQuery{Or: []*JSONQuery{{Field: "topic", Op: "like", Value: "t"}}},
+			},
+			expectedErrorText: "invalid filter op: like",
+		},
+		{
+			name:              "In Without Array",
+			jsonQuery:         JSONQuery{Field: "topic", Op: FilterOpIn, Value: "travel"},
+			expectedErrorText: "the value of an in filter must be an array",
+		},
+		{
+			name:              "Missing Value",
+			jsonQuery:         JSONQuery{Field: "count", Op: FilterOpGt},
+			expectedErrorText: "a value is required by the gt filter",
+		},
+		{
+			name:              "Missing Field",
+			jsonQuery:         JSONQuery{Op: FilterOpEq, Value: "travel"},
+			expectedErrorText: "a field is required by the eq filter",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.jsonQuery.validate()
+			if tt.expectedErrorText != "" {
+				assert.ErrorContains(t, err, tt.expectedErrorText)
+				assert.ErrorIs(t, err, models.ErrBadRequest)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/uptrace/bun"
+)
+
+// secureDeleteVacuumTables are vacuumed after a secure delete to remove dead tuples
+// containing the original content. Facts are overwritten when a user's data is erased.
+var secureDeleteVacuumTables = []string{"message", "summary", "fact"}
+
+// SecureDeleteSession hard deletes a session, first overwriting the content and metadata of
+// its messages and summaries so that the original data does not survive in dead tuples.
+// The tables are then vacuumed. If the vacuum fails, for example because the database role
+// does not own the tables, a warning is logged and VACUUM must be run manually.
+//
+// Known limitation: the original data is retained in the WAL, and in any WAL archives or
+// backups, until they are rotated.
+func SecureDeleteSession(ctx context.Context, db *bun.DB, sessionID string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer rollbackOnError(tx)
+
+	if err := secureDeleteSession(ctx, tx, sessionID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	vacuumSecureDeleteTables(ctx, db, "session "+sessionID)
+
+	return nil
+}
+
+// secureDeleteSession overwrites and hard deletes a session within tx. See SecureDeleteSession.
+func secureDeleteSession(ctx context.Context, tx bun.Tx, sessionID string) error {
+	_, err := tx.NewUpdate().
+		Model((*MessageStoreSchema)(nil)).
+		Set("content = md5(random()::text)").
+		Set("metadata = '{}'").
+		Set("token_count = 0").
+		WhereAllWithDeleted().
+		Where("session_id = ?", sessionID).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to overwrite messages: %w", err)
+	}
+
+	_, err = tx.NewUpdate().
+		Model((*SummaryStoreSchema)(nil)).
+		Set("content = md5(random()::text)").
+		Set("metadata = '{}'").
+		WhereAllWithDeleted().
+		Where("session_id = ?", sessionID).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to overwrite summaries: %w", err)
+	}
+
+	return deleteSession(ctx, tx, sessionID, true)
+}
+
+// vacuumSecureDeleteTables vacuums the tables overwritten by a secure delete. VACUUM cannot run
+// inside a transaction.
+func vacuumSecureDeleteTables(ctx context.Context, db *bun.DB, deleted string) {
+	for _, table := range secureDeleteVacuumTables {
+		if _, err := db.ExecContext(ctx, "VACUUM FREEZE ?", bun.Ident(table)); err != nil {
+			log.Warningf(
+				"secure delete of %s: failed to vacuum %s. "+
+					"VACUUM must be run manually to remove dead tuples: %s",
+				deleted,
+				table,
+				err,
+			)
+		}
+	}
+}
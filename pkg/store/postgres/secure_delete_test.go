@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecureDeleteSession(t *testing.T) {
+	sessionID := createSession(t)
+
+	msgs, err := putMessages(testCtx, testDB, sessionID, []models.Message{
+		{Role: "user", Content: "my password is hunter2"},
+		{Role: "assistant", Content: "I'll remember that"},
+	})
+	require.NoError(t, err)
+	_, err = putSummary(testCtx, testDB, sessionID, &models.Summary{
+		Content:          "the user's password is hunter2",
+		SummaryPointUUID: msgs[1].UUID,
+	})
+	require.NoError(t, err)
+
+	err = SecureDeleteSession(testCtx, testDB, sessionID)
+	require.NoError(t, err)
+
+	for _, schema := range messageTableList {
+		count, err := testDB.NewSelect().
+			Model(schema).
+			WhereAllWithDeleted().
+			Where("session_id = ?", sessionID).
+			Count(testCtx)
+		require.NoError(t, err)
+		assert.Zero(t, count, "%T", schema)
+	}
+
+	t.Run("non-existent session returns not found", func(t *testing.T) {
+		err := SecureDeleteSession(testCtx, testDB, "nonexistent")
+		assert.ErrorIs(t, err, models.ErrNotFound)
+	})
+}
@@ -18,12 +18,13 @@ var _ models.SessionManager = &SessionDAO{}
 
 // SessionDAO implements the SessionManager interface.
 type SessionDAO struct {
-	db *bun.DB
+	db bun.IDB
 }
 
 // NewSessionDAO is a constructor for the SessionDAO struct.
-// It takes a pointer to a bun.DB instance and returns a pointer to a new SessionDAO instance.
-func NewSessionDAO(db *bun.DB) *SessionDAO {
+// It takes a bun.IDB, which can be either a *bun.DB or a bun.Tx, and returns a pointer to a
+// new SessionDAO instance.
+func NewSessionDAO(db bun.IDB) *SessionDAO {
 	return &SessionDAO{
 		db: db,
 	}
@@ -32,6 +33,10 @@ func NewSessionDAO(db *bun.DB) *SessionDAO {
 // Create creates a new session in the database.
 // It takes a context and a pointer to a CreateSessionRequest struct.
 // It returns a pointer to the created Session struct or an error if the creation fails.
+// The session is created in the project ctx is scoped to, which must be its user's. If ctx
+// carries a session quota that its tenant has reached, a SessionQuotaExceededError is returned.
+// The quota is checked in the transaction the session is inserted in, which is begun if the
+// DAO's db isn't one.
 func (dao *SessionDAO) Create(
 	ctx context.Context,
 	session *models.CreateSessionRequest,
@@ -39,10 +44,47 @@ func (dao *SessionDAO) Create(
 	if session.SessionID == "" {
 		return nil, errors.New("sessionID cannot be empty")
 	}
+	if _, ok := models.SessionQuotaFromContext(ctx); ok {
+		tx, ok := dao.db.(bun.Tx)
+		if !ok {
+			var created *models.Session
+			err := dao.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+				var err error
+				created, err = NewSessionDAO(tx).Create(ctx, session)
+				return err
+			})
+			if err != nil {
+				return nil, err
+			}
+			return created, nil
+		}
+		if err := checkSessionQuota(ctx, tx); err != nil {
+			return nil, err
+		}
+	}
+	if session.Language != "" {
+		if err := validateTextSearchLanguage(ctx, dao.db, session.Language); err != nil {
+			return nil, err
+		}
+	}
+	// sessions belong to their user's project
+	projectID, _ := models.ProjectIDFromContext(ctx)
+	if session.UserID != nil {
+		if err := checkUserProject(ctx, dao.db, *session.UserID); err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				return nil, models.NewBadRequestError(
+					"user does not exist with user_id: " + *session.UserID,
+				)
+			}
+			return nil, err
+		}
+	}
 	sessionDB := SessionSchema{
 		SessionID: session.SessionID,
 		UserID:    session.UserID,
 		Metadata:  session.Metadata,
+		Language:  session.Language,
+		ProjectID: projectID,
 	}
 	_, err := dao.db.NewInsert().
 		Model(&sessionDB).
@@ -70,6 +112,7 @@ func (dao *SessionDAO) Create(
 		SessionID: sessionDB.SessionID,
 		Metadata:  sessionDB.Metadata,
 		UserID:    sessionDB.UserID,
+		Language:  sessionDB.Language,
 	}, nil
 }
 
@@ -81,6 +124,7 @@ func (dao *SessionDAO) Get(ctx context.Context, sessionID string) (*models.Sessi
 	err := dao.db.NewSelect().
 		Model(&session).
 		Where("session_id = ?", sessionID).
+		ApplyQueryBuilder(whereProject(ctx)).
 		Scan(ctx)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -97,6 +141,7 @@ func (dao *SessionDAO) Get(ctx context.Context, sessionID string) (*models.Sessi
 		SessionID: session.SessionID,
 		Metadata:  session.Metadata,
 		UserID:    session.UserID,
+		Language:  session.Language,
 	}
 	return &retSession, nil
 }
@@ -174,6 +219,7 @@ func (dao *SessionDAO) updateSession(
 		// use WhereAllWithDeleted to update soft-deleted sessions
 		WhereAllWithDeleted().
 		Where("session_id = ?", session.SessionID).
+		ApplyQueryBuilder(whereProject(ctx)).
 		Returning("*").
 		Exec(ctx)
 	if err != nil {
@@ -195,25 +241,68 @@ func (dao *SessionDAO) updateSession(
 		SessionID: sessionDB.SessionID,
 		Metadata:  sessionDB.Metadata,
 		UserID:    sessionDB.UserID,
+		Language:  sessionDB.Language,
 	}
 
 	return &returnedSession, nil
 }
 
-// Delete soft-deletes a session from the database by its sessionID.
-// It also soft-deletes all messages, message embeddings, and summaries associated with the session.
-func (dao *SessionDAO) Delete(ctx context.Context, sessionID string) error {
-	dbSession := &SessionSchema{}
-
+// Delete deletes a session from the database by its sessionID, along with all messages,
+// message embeddings, and summaries associated with the session.
+// If hardDelete is false, the records are soft-deleted by setting deleted_at, to the same time
+// for all records, so that they may be restored with the session. If hardDelete is true, the
+// records are removed from the database, including any that were already soft-deleted.
+// Both paths run in a single transaction.
+func (dao *SessionDAO) Delete(ctx context.Context, sessionID string, hardDelete bool) error {
 	tx, err := dao.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer rollbackOnError(tx)
 
+	if err := checkSessionProject(ctx, tx, sessionID); err != nil {
+		return err
+	}
+	if err := deleteSession(ctx, tx, sessionID, hardDelete); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// deleteSession deletes a session and its related records within tx. See SessionDAO.Delete.
+func deleteSession(ctx context.Context, tx bun.Tx, sessionID string, hardDelete bool) error {
+	if !hardDelete {
+		return softDeleteSession(ctx, tx, sessionID)
+	}
+
+	// Delete related records first so that a hard delete does not rely on
+	// foreign key cascades.
+	for _, schema := range messageTableList {
+		if _, ok := schema.(*SessionSchema); ok {
+			continue
+		}
+		log.Debugf("deleting session %s from schema %T", sessionID, schema)
+		_, err := tx.NewDelete().
+			Model(schema).
+			Where("session_id = ?", sessionID).
+			WhereAllWithDeleted().
+			ForceDelete().
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("error deleting rows from %T: %w", schema, err)
+		}
+	}
+
 	r, err := tx.NewDelete().
-		Model(dbSession).
+		Model(&SessionSchema{}).
 		Where("session_id = ?", sessionID).
+		WhereAllWithDeleted().
+		ForceDelete().
 		Exec(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to delete session: %w", err)
@@ -227,14 +316,39 @@ func (dao *SessionDAO) Delete(ctx context.Context, sessionID string) error {
 		return models.NewNotFoundError("session " + sessionID)
 	}
 
-	// delete all messages, message embeddings, and summaries associated with the session
+	return nil
+}
+
+// softDeleteSession soft deletes a session and its related records within tx. Records that
+// were already deleted keep their deleted_at, so they aren't restored with the session.
+func softDeleteSession(ctx context.Context, tx bun.Tx, sessionID string) error {
+	// timestamptz has microsecond precision
+	deletedAt := time.Now().Truncate(time.Microsecond)
+
+	r, err := tx.NewUpdate().
+		Model((*SessionSchema)(nil)).
+		Set("deleted_at = ?", deletedAt).
+		Where("session_id = ?", sessionID).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	rowsAffected, err := r.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return models.NewNotFoundError("session " + sessionID)
+	}
+
 	for _, schema := range messageTableList {
 		if _, ok := schema.(*SessionSchema); ok {
 			continue
 		}
 		log.Debugf("deleting session %s from schema %T", sessionID, schema)
-		_, err := tx.NewDelete().
+		_, err := tx.NewUpdate().
 			Model(schema).
+			Set("deleted_at = ?", deletedAt).
 			Where("session_id = ?", sessionID).
 			Exec(ctx)
 		if err != nil {
@@ -242,11 +356,67 @@ func (dao *SessionDAO) Delete(ctx context.Context, sessionID string) error {
 		}
 	}
 
+	return nil
+}
+
+// Restore undeletes a soft-deleted session, along with the messages, message embeddings, and
+// summaries that were deleted with it. Records deleted before the session remain deleted.
+// The session's updated_at is touched, so that it doesn't expire again as soon as it's restored.
+// A bad request error is returned if the session isn't deleted.
+func (dao *SessionDAO) Restore(ctx context.Context, sessionID string) (*models.Session, error) {
+	tx, err := dao.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer rollbackOnError(tx)
+
+	session := SessionSchema{}
+	err = tx.NewSelect().
+		Model(&session).
+		WhereAllWithDeleted().
+		Where("session_id = ?", sessionID).
+		ApplyQueryBuilder(whereProject(ctx)).
+		For("UPDATE").
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.NewNotFoundError("session " + sessionID)
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if session.DeletedAt.IsZero() {
+		return nil, models.NewBadRequestError("session " + sessionID + " is not deleted")
+	}
+
+	for _, schema := range messageTableList {
+		q := tx.NewUpdate().
+			Model(schema).
+			Set("deleted_at = NULL").
+			WhereAllWithDeleted().
+			Where("session_id = ?", sessionID).
+			Where("deleted_at = ?", session.DeletedAt)
+		if _, ok := schema.(*SessionSchema); ok {
+			q = q.Set("updated_at = current_timestamp")
+		}
+		if _, err := q.Exec(ctx); err != nil {
+			return nil, fmt.Errorf("error restoring rows of %T: %w", schema, err)
+		}
+	}
+
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	return nil
+	return &models.Session{
+		UUID:      session.UUID,
+		ID:        session.ID,
+		CreatedAt: session.CreatedAt,
+		UpdatedAt: session.UpdatedAt,
+		SessionID: session.SessionID,
+		Metadata:  session.Metadata,
+		UserID:    session.UserID,
+		Language:  session.Language,
+	}, nil
 }
 
 // ListAll retrieves all sessions from the database.
@@ -261,6 +431,7 @@ func (dao *SessionDAO) ListAll(
 	err := dao.db.NewSelect().
 		Model(&sessions).
 		Where("id > ?", cursor).
+		ApplyQueryBuilder(whereProject(ctx)).
 		Order("id ASC").
 		Limit(limit).
 		Scan(ctx)
@@ -295,6 +466,7 @@ func (dao *SessionDAO) ListAllOrdered(
 		defer wg.Done()
 		err := dao.db.NewSelect().
 			Model(&sessions).
+			ApplyQueryBuilder(whereProject(ctx)).
 			Order(fmt.Sprintf("%s %s", orderBy, getAscDesc(asc))).
 			Limit(pageSize).
 			Offset((pageNumber - 1) * pageSize).
@@ -313,6 +485,7 @@ func (dao *SessionDAO) ListAllOrdered(
 		var err error
 		totalCount, err = dao.db.NewSelect().
 			Model((*SessionSchema)(nil)).
+			ApplyQueryBuilder(whereProject(ctx)).
 			Count(ctx)
 
 		mu.Lock()
@@ -348,6 +521,7 @@ func sessionSchemaToSession(sessions []SessionSchema) []*models.Session {
 			SessionID: sessions[i].SessionID,
 			Metadata:  sessions[i].Metadata,
 			UserID:    sessions[i].UserID,
+			Language:  sessions[i].Language,
 		}
 	}
 	return retSessions
@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/uptrace/bun"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/store"
+)
+
+// sessionTTLExpr is a session's TTL, in minutes: its session_ttl metadata if that's a number,
+// or the configured TTL
+const sessionTTLExpr = "CASE WHEN jsonb_typeof(s.metadata->?) = 'number' " +
+	"THEN (s.metadata->>?)::numeric ELSE ? END"
+
+// ExpireSessions soft deletes the sessions that haven't been updated within their TTL, and
+// purges those that haven't been updated within their TTL and the grace period, whether or not
+// they've been deleted. Nothing is deleted if cfg.DryRun is set.
+func (pms *PostgresMemoryStore) ExpireSessions(
+	ctx context.Context,
+	cfg *config.SessionExpiryConfig,
+) (*models.SessionExpiryResult, error) {
+	now := time.Now()
+
+	purged, err := idleSessionIDs(ctx, pms.Client, now, cfg.TTL, cfg.GracePeriod, true)
+	if err != nil {
+		return nil, store.NewStorageError("failed to get sessions to purge", err)
+	}
+	idle, err := idleSessionIDs(ctx, pms.Client, now, cfg.TTL, 0, false)
+	if err != nil {
+		return nil, store.NewStorageError("failed to get expired sessions", err)
+	}
+
+	isPurged := make(map[string]bool, len(purged))
+	for _, sessionID := range purged {
+		isPurged[sessionID] = true
+	}
+	var expired []string
+	for _, sessionID := range idle {
+		if !isPurged[sessionID] {
+			expired = append(expired, sessionID)
+		}
+	}
+
+	result := &models.SessionExpiryResult{Expired: expired, Purged: purged, DryRun: cfg.DryRun}
+	if cfg.DryRun {
+		return result, nil
+	}
+
+	// sessions may be deleted concurrently
+	for _, sessionID := range purged {
+		err := pms.PurgeSession(ctx, sessionID)
+		if err != nil && !errors.Is(err, models.ErrNotFound) {
+			return nil, store.NewStorageError("failed to purge session "+sessionID, err)
+		}
+	}
+	for _, sessionID := range expired {
+		err := pms.DeleteSession(ctx, sessionID)
+		if err != nil && !errors.Is(err, models.ErrNotFound) {
+			return nil, store.NewStorageError("failed to delete session "+sessionID, err)
+		}
+	}
+
+	return result, nil
+}
+
+// idleSessionIDs returns the IDs of the sessions that, as of now, haven't been updated within
+// their TTL and graceMinutes. Sessions without a positive TTL are never idle.
+func idleSessionIDs(
+	ctx context.Context,
+	db *bun.DB,
+	now time.Time,
+	defaultTTL int,
+	graceMinutes int,
+	withDeleted bool,
+) ([]string, error) {
+	key := models.SessionTTLMetadataKey
+	q := db.NewSelect().
+		Model((*SessionSchema)(nil)).
+		Column("session_id").
+		Where("("+sessionTTLExpr+") > 0", key, key, defaultTTL).
+		Where(
+			"COALESCE(s.updated_at, s.created_at) < ?::timestamptz - interval '1 minute' * (("+
+				sessionTTLExpr+") + ?)",
+			now,
+			key,
+			key,
+			defaultTTL,
+			graceMinutes,
+		).
+		Order("id ASC")
+	if withDeleted {
+		q = q.WhereAllWithDeleted()
+	}
+
+	var sessionIDs []string
+	if err := q.Scan(ctx, &sessionIDs); err != nil {
+		return nil, err
+	}
+	return sessionIDs, nil
+}
@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/testutils"
+)
+
+func TestExpireSessions(t *testing.T) {
+	CleanDB(t, testDB)
+	err := CreateSchema(testCtx, appState, testDB)
+	require.NoError(t, err)
+
+	dao := NewSessionDAO(testDB)
+	newSession := func(idle time.Duration, metadata map[string]interface{}) string {
+		sessionID, err := testutils.GenerateRandomSessionID(16)
+		require.NoError(t, err)
+		_, err = dao.Create(testCtx, &models.CreateSessionRequest{
+			SessionID: sessionID,
+			Metadata:  metadata,
+		})
+		require.NoError(t, err)
+		_, err = testDB.NewUpdate().
+			Model((*SessionSchema)(nil)).
+			Set("updated_at = ?", time.Now().Add(-idle)).
+			Where("session_id = ?", sessionID).
+			Exec(testCtx)
+		require.NoError(t, err)
+		return sessionID
+	}
+
+	active := newSession(30*time.Minute, nil)
+	expired := newSession(90*time.Minute, nil)
+	purged := newSession(3*time.Hour, nil)
+	// the session's ttl overrides the configured ttl
+	longTTL := newSession(3*time.Hour, map[string]interface{}{models.SessionTTLMetadataKey: 600})
+	noExpiry := newSession(3*time.Hour, map[string]interface{}{models.SessionTTLMetadataKey: 0})
+
+	cfg := &config.SessionExpiryConfig{TTL: 60, GracePeriod: 60, DryRun: true}
+
+	t.Run("dry run", func(t *testing.T) {
+		result, err := appState.MemoryStore.ExpireSessions(testCtx, cfg)
+		require.NoError(t, err)
+		assert.Equal(t, &models.SessionExpiryResult{
+			Expired: []string{expired},
+			Purged:  []string{purged},
+			DryRun:  true,
+		}, result)
+
+		for _, sessionID := range []string{active, expired, purged, longTTL, noExpiry} {
+			_, err := dao.Get(testCtx, sessionID)
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run("expire", func(t *testing.T) {
+		cfg.DryRun = false
+		result, err := appState.MemoryStore.ExpireSessions(testCtx, cfg)
+		require.NoError(t, err)
+		assert.Equal(t, []string{expired}, result.Expired)
+		assert.Equal(t, []string{purged}, result.Purged)
+
+		for _, sessionID := range []string{active, longTTL, noExpiry} {
+			_, err := dao.Get(testCtx, sessionID)
+			assert.NoError(t, err)
+		}
+
+		// expired sessions may be restored
+		_, err = dao.Get(testCtx, expired)
+		assert.ErrorIs(t, err, models.ErrNotFound)
+		_, err = dao.Restore(testCtx, expired)
+		assert.NoError(t, err)
+
+		count, err := testDB.NewSelect().
+			Model((*SessionSchema)(nil)).
+			WhereAllWithDeleted().
+			Where("session_id = ?", purged).
+			Count(testCtx)
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+}
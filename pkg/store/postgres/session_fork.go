@@ -0,0 +1,285 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/store"
+)
+
+// ForkSession creates request.SessionID with copies of the session's messages up to and
+// including request.MessageUUID, and of their embeddings. The summaries whose summary point is
+// a copied message are copied with their embeddings, as is the summary abstract if its last
+// summary is copied. Deleted records aren't copied. The new session has the session's user
+// and language, and its metadata unless request.Metadata is set.
+func (pms *PostgresMemoryStore) ForkSession(
+	ctx context.Context,
+	sessionID string,
+	request *models.ForkSessionRequest,
+) (*models.Session, error) {
+	if request.SessionID == "" {
+		return nil, models.NewBadRequestError("session_id is required")
+	}
+	if request.MessageUUID == uuid.Nil {
+		return nil, models.NewBadRequestError("message_uuid is required")
+	}
+
+	if err := pms.openSession(ctx, sessionID); err != nil {
+		return nil, err
+	}
+
+	messageUUIDs, err := forkSession(ctx, pms.Client, sessionID, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if pms.VectorIndex != nil {
+		embeddings, err := pms.VectorIndex.GetMessageEmbeddings(ctx, sessionID)
+		if err != nil {
+			return nil, store.NewStorageError("failed to get session embeddings", err)
+		}
+		var forked []models.TextData
+		for _, e := range embeddings {
+			if newUUID, ok := messageUUIDs[e.TextUUID]; ok {
+				e.TextUUID = newUUID
+				forked = append(forked, e)
+			}
+		}
+		if len(forked) > 0 {
+			err := pms.VectorIndex.PutMessageEmbeddings(ctx, request.SessionID, forked)
+			if err != nil {
+				return nil, store.NewStorageError("failed to copy session embeddings", err)
+			}
+		}
+	}
+
+	return pms.SessionStore.Get(ctx, request.SessionID)
+}
+
+// forkSession copies the session in a transaction, returning the UUIDs of the copied messages
+// keyed by the UUIDs of the messages they copy
+func forkSession(
+	ctx context.Context,
+	db *bun.DB,
+	sessionID string,
+	request *models.ForkSessionRequest,
+) (map[uuid.UUID]uuid.UUID, error) {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, store.NewStorageError("failed to begin transaction", err)
+	}
+	defer rollbackOnError(tx)
+
+	// the session is locked so that its summaries aren't replaced while they're copied
+	session := SessionSchema{}
+	err = tx.NewSelect().
+		Model(&session).
+		Where("session_id = ?", sessionID).
+		For("SHARE").
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.NewNotFoundError("session " + sessionID)
+		}
+		return nil, store.NewStorageError("failed to get session", err)
+	}
+
+	forkPoint := MessageStoreSchema{}
+	err = tx.NewSelect().
+		Model(&forkPoint).
+		Column("id").
+		Where("session_id = ?", sessionID).
+		Where("uuid = ?", request.MessageUUID).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.NewNotFoundError("message " + request.MessageUUID.String())
+		}
+		return nil, store.NewStorageError("failed to get message", err)
+	}
+
+	metadata := request.Metadata
+	if metadata == nil {
+		metadata = session.Metadata
+	}
+	_, err = NewSessionDAO(tx).Create(ctx, &models.CreateSessionRequest{
+		SessionID: request.SessionID,
+		UserID:    session.UserID,
+		Metadata:  metadata,
+		Language:  session.Language,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []MessageStoreSchema
+	err = tx.NewSelect().
+		Model(&messages).
+		Where("session_id = ?", sessionID).
+		Where("id <= ?", forkPoint.ID).
+		Order("id ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, store.NewStorageError("failed to get messages", err)
+	}
+
+	// messages are inserted in order, so that the copies' ids keep it
+	messageUUIDs := make(map[uuid.UUID]uuid.UUID, len(messages))
+	oldMessageUUIDs := make([]uuid.UUID, len(messages))
+	for i := range messages {
+		oldMessageUUIDs[i] = messages[i].UUID
+		messageUUIDs[messages[i].UUID] = uuid.New()
+		messages[i] = MessageStoreSchema{
+			UUID:       messageUUIDs[messages[i].UUID],
+			CreatedAt:  messages[i].CreatedAt,
+			UpdatedAt:  messages[i].UpdatedAt,
+			SessionID:  request.SessionID,
+			Role:       messages[i].Role,
+			Content:    messages[i].Content,
+			TokenCount: messages[i].TokenCount,
+			Metadata:   messages[i].Metadata,
+			Language:   messages[i].Language,
+			IsSystem:   messages[i].IsSystem,
+		}
+	}
+	if _, err := tx.NewInsert().Model(&messages).Exec(ctx); err != nil {
+		return nil, store.NewStorageError("failed to copy messages", err)
+	}
+
+	var messageEmbeddings []MessageVectorStoreSchema
+	err = tx.NewSelect().
+		Model(&messageEmbeddings).
+		Where("message_uuid IN (?)", bun.In(oldMessageUUIDs)).
+		Where("is_embedded").
+		Scan(ctx)
+	if err != nil {
+		return nil, store.NewStorageError("failed to get message embeddings", err)
+	}
+	if len(messageEmbeddings) > 0 {
+		for i, e := range messageEmbeddings {
+			messageEmbeddings[i] = MessageVectorStoreSchema{
+				SessionID:   request.SessionID,
+				MessageUUID: messageUUIDs[e.MessageUUID],
+				Embedding:   e.Embedding,
+				IsEmbedded:  true,
+			}
+		}
+		if _, err := tx.NewInsert().Model(&messageEmbeddings).Exec(ctx); err != nil {
+			return nil, store.NewStorageError("failed to copy message embeddings", err)
+		}
+	}
+
+	if err := forkSummaries(ctx, tx, sessionID, request.SessionID, messageUUIDs); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, store.NewStorageError("failed to commit transaction", err)
+	}
+
+	return messageUUIDs, nil
+}
+
+// forkSummaries copies the summaries of the copied messages, their embeddings and the summary
+// abstract covering them, if there is one
+func forkSummaries(
+	ctx context.Context,
+	tx bun.Tx,
+	sessionID string,
+	forkSessionID string,
+	messageUUIDs map[uuid.UUID]uuid.UUID,
+) error {
+	oldMessageUUIDs := make([]uuid.UUID, 0, len(messageUUIDs))
+	for old := range messageUUIDs {
+		oldMessageUUIDs = append(oldMessageUUIDs, old)
+	}
+
+	var summaries []SummaryStoreSchema
+	err := tx.NewSelect().
+		Model(&summaries).
+		Where("session_id = ?", sessionID).
+		Where("summary_point_uuid IN (?)", bun.In(oldMessageUUIDs)).
+		Order("created_at ASC").
+		Scan(ctx)
+	if err != nil {
+		return store.NewStorageError("failed to get summaries", err)
+	}
+	if len(summaries) == 0 {
+		return nil
+	}
+
+	summaryUUIDs := make(map[uuid.UUID]uuid.UUID, len(summaries))
+	oldSummaryUUIDs := make([]uuid.UUID, len(summaries))
+	for i := range summaries {
+		oldSummaryUUIDs[i] = summaries[i].UUID
+		summaryUUIDs[summaries[i].UUID] = uuid.New()
+		summaries[i] = SummaryStoreSchema{
+			UUID:             summaryUUIDs[summaries[i].UUID],
+			CreatedAt:        summaries[i].CreatedAt,
+			UpdatedAt:        summaries[i].UpdatedAt,
+			SessionID:        forkSessionID,
+			Content:          summaries[i].Content,
+			Metadata:         summaries[i].Metadata,
+			TokenCount:       summaries[i].TokenCount,
+			SummaryPointUUID: messageUUIDs[summaries[i].SummaryPointUUID],
+		}
+	}
+	if _, err := tx.NewInsert().Model(&summaries).Exec(ctx); err != nil {
+		return store.NewStorageError("failed to copy summaries", err)
+	}
+
+	var summaryEmbeddings []SummaryVectorStoreSchema
+	err = tx.NewSelect().
+		Model(&summaryEmbeddings).
+		Where("summary_uuid IN (?)", bun.In(oldSummaryUUIDs)).
+		Where("is_embedded").
+		Scan(ctx)
+	if err != nil {
+		return store.NewStorageError("failed to get summary embeddings", err)
+	}
+	if len(summaryEmbeddings) > 0 {
+		for i, e := range summaryEmbeddings {
+			summaryEmbeddings[i] = SummaryVectorStoreSchema{
+				SessionID:   forkSessionID,
+				SummaryUUID: summaryUUIDs[e.SummaryUUID],
+				Embedding:   e.Embedding,
+				IsEmbedded:  true,
+			}
+		}
+		if _, err := tx.NewInsert().Model(&summaryEmbeddings).Exec(ctx); err != nil {
+			return store.NewStorageError("failed to copy summary embeddings", err)
+		}
+	}
+
+	// an abstract whose last summary isn't copied covers messages after the fork point
+	abstract := SummaryAbstractSchema{}
+	err = tx.NewSelect().
+		Model(&abstract).
+		Where("session_id = ?", sessionID).
+		Where("last_summary_uuid IN (?)", bun.In(oldSummaryUUIDs)).
+		Scan(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return store.NewStorageError("failed to get summary abstract", err)
+	}
+	abstract = SummaryAbstractSchema{
+		CreatedAt:       abstract.CreatedAt,
+		UpdatedAt:       abstract.UpdatedAt,
+		SessionID:       forkSessionID,
+		Content:         abstract.Content,
+		LastSummaryUUID: summaryUUIDs[abstract.LastSummaryUUID],
+		TokenCount:      abstract.TokenCount,
+	}
+	if _, err := tx.NewInsert().Model(&abstract).Exec(ctx); err != nil {
+		return store.NewStorageError("failed to copy summary abstract", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/testutils"
+)
+
+func TestForkSession(t *testing.T) {
+	sessionID := testutils.GenerateRandomString(16)
+	_, err := NewSessionDAO(testDB).Create(testCtx, &models.CreateSessionRequest{
+		SessionID: sessionID,
+		Metadata:  map[string]interface{}{"variant": "a"},
+	})
+	require.NoError(t, err)
+	messages, err := putMessages(testCtx, testDB, sessionID, []models.Message{
+		{Role: "user", Content: "Hello"},
+		{Role: "assistant", Content: "Hi there"},
+		{Role: "user", Content: "Tell me a joke"},
+	})
+	require.NoError(t, err)
+	first, err := putSummary(testCtx, testDB, sessionID, &models.Summary{
+		Content:          "A greeting",
+		SummaryPointUUID: messages[1].UUID,
+	})
+	require.NoError(t, err)
+	_, err = putSummary(testCtx, testDB, sessionID, &models.Summary{
+		Content:          "A greeting and a request for a joke",
+		SummaryPointUUID: messages[2].UUID,
+	})
+	require.NoError(t, err)
+
+	forkID := testutils.GenerateRandomString(16)
+	session, err := appState.MemoryStore.ForkSession(testCtx, sessionID, &models.ForkSessionRequest{
+		SessionID:   forkID,
+		MessageUUID: messages[1].UUID,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, forkID, session.SessionID)
+	assert.Equal(t, "a", session.Metadata["variant"])
+
+	messageList, err := getMessageListByCursor(testCtx, testDB, forkID, 0, 10, nil)
+	require.NoError(t, err)
+	forked := messageList.Messages
+	require.Len(t, forked, 2)
+	assert.Equal(t, "Hello", forked[0].Content)
+	assert.Equal(t, "Hi there", forked[1].Content)
+	assert.NotEqual(t, messages[0].UUID, forked[0].UUID)
+
+	// only the summary of the copied messages is copied
+	summary, err := getSummary(testCtx, testDB, forkID)
+	require.NoError(t, err)
+	assert.Equal(t, first.Content, summary.Content)
+	assert.Equal(t, forked[1].UUID, summary.SummaryPointUUID)
+
+	// the forked session is unchanged
+	messageList, err = getMessageListByCursor(testCtx, testDB, sessionID, 0, 10, nil)
+	require.NoError(t, err)
+	assert.Len(t, messageList.Messages, 3)
+
+	t.Run("an existing session can't be forked into", func(t *testing.T) {
+		_, err := appState.MemoryStore.ForkSession(
+			testCtx,
+			sessionID,
+			&models.ForkSessionRequest{SessionID: forkID, MessageUUID: messages[0].UUID},
+		)
+		assert.ErrorIs(t, err, models.ErrBadRequest)
+	})
+
+	t.Run("forking at an unknown message returns not found", func(t *testing.T) {
+		_, err := appState.MemoryStore.ForkSession(
+			testCtx,
+			sessionID,
+			&models.ForkSessionRequest{
+				SessionID:   testutils.GenerateRandomString(16),
+				MessageUUID: uuid.New(),
+			},
+		)
+		assert.ErrorIs(t, err, models.ErrNotFound)
+	})
+}
@@ -0,0 +1,209 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"dario.cat/mergo"
+	"github.com/uptrace/bun"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/store"
+)
+
+// MergeSessions moves the source session's messages and message embeddings, including deleted
+// ones, to the target session, and deletes the source session. The sessions' metadata are
+// deep merged, with request.MetadataConflict selecting the session whose values are kept for
+// keys both set. A session without a user takes the other's, and sessions of different users
+// can't be merged. Both sessions' summaries are deleted, and the target session is
+// summarized again if the summarizer is enabled.
+func (pms *PostgresMemoryStore) MergeSessions(
+	ctx context.Context,
+	appState *models.AppState,
+	targetSessionID string,
+	request *models.MergeSessionsRequest,
+) (*models.Session, error) {
+	sourceSessionID := request.SourceSessionID
+	if sourceSessionID == "" {
+		return nil, models.NewBadRequestError("source_session_id is required")
+	}
+	if sourceSessionID == targetSessionID {
+		return nil, models.NewBadRequestError("a session can't be merged into itself")
+	}
+	conflict := request.MetadataConflict
+	switch conflict {
+	case "":
+		conflict = models.MetadataConflictTarget
+	case models.MetadataConflictTarget, models.MetadataConflictSource:
+	default:
+		return nil, models.NewBadRequestError(
+			fmt.Sprintf("invalid metadata_conflict: %s", request.MetadataConflict),
+		)
+	}
+
+	for _, sessionID := range []string{targetSessionID, sourceSessionID} {
+		if err := pms.openSession(ctx, sessionID); err != nil {
+			return nil, err
+		}
+	}
+
+	err := mergeSessions(ctx, pms.Client, targetSessionID, sourceSessionID, conflict)
+	if err != nil {
+		return nil, err
+	}
+	// the source session is deleted by the merge
+	pms.notifyWebhooks(ctx, models.WebhookEventSessionDeleted, sourceSessionID)
+
+	if pms.VectorIndex != nil {
+		embeddings, err := pms.VectorIndex.GetMessageEmbeddings(ctx, sourceSessionID)
+		if err != nil {
+			return nil, store.NewStorageError("failed to get source session embeddings", err)
+		}
+		if len(embeddings) > 0 {
+			err := pms.VectorIndex.PutMessageEmbeddings(ctx, targetSessionID, embeddings)
+			if err != nil {
+				return nil, store.NewStorageError("failed to move source session embeddings", err)
+			}
+		}
+		if err := pms.VectorIndex.DeleteSessionEmbeddings(ctx, sourceSessionID); err != nil {
+			return nil, store.NewStorageError("failed to delete source session embeddings", err)
+		}
+	}
+
+	if appState.Config.Extractors.Messages.Summarizer.Enabled {
+		if err := appState.TaskPublisher.PublishSummarize(ctx, targetSessionID); err != nil {
+			return nil, store.NewStorageError("failed to publish summarize task", err)
+		}
+	}
+
+	return pms.SessionStore.Get(ctx, targetSessionID)
+}
+
+func mergeSessions(
+	ctx context.Context,
+	db *bun.DB,
+	targetSessionID string,
+	sourceSessionID string,
+	conflict models.MetadataConflict,
+) error {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return store.NewStorageError("failed to begin transaction", err)
+	}
+	defer rollbackOnError(tx)
+
+	// the sessions are locked in a consistent order, so that concurrent merges don't deadlock
+	var sessions []SessionSchema
+	err = tx.NewSelect().
+		Model(&sessions).
+		Column("session_id", "metadata", "user_id").
+		Where("session_id IN (?)", bun.In([]string{targetSessionID, sourceSessionID})).
+		Order("session_id ASC").
+		For("UPDATE").
+		Scan(ctx)
+	if err != nil {
+		return store.NewStorageError("failed to get sessions", err)
+	}
+	var target, source *SessionSchema
+	for i := range sessions {
+		if sessions[i].SessionID == targetSessionID {
+			target = &sessions[i]
+		} else {
+			source = &sessions[i]
+		}
+	}
+	if target == nil {
+		return models.NewNotFoundError("session " + targetSessionID)
+	}
+	if source == nil {
+		return models.NewNotFoundError("session " + sourceSessionID)
+	}
+
+	userID := target.UserID
+	if userID == nil {
+		userID = source.UserID
+	} else if source.UserID != nil && *source.UserID != *userID {
+		return models.NewBadRequestError("sessions of different users can't be merged")
+	}
+
+	metadata, err := mergeSessionMetadata(target.Metadata, source.Metadata, conflict)
+	if err != nil {
+		return store.NewStorageError("failed to merge metadata", err)
+	}
+
+	for _, schema := range []interface{}{
+		(*MessageStoreSchema)(nil),
+		(*MessageVectorStoreSchema)(nil),
+	} {
+		_, err := tx.NewUpdate().
+			Model(schema).
+			Set("session_id = ?", targetSessionID).
+			WhereAllWithDeleted().
+			Where("session_id = ?", sourceSessionID).
+			Exec(ctx)
+		if err != nil {
+			return store.NewStorageError(fmt.Sprintf("failed to move %T rows", schema), err)
+		}
+	}
+
+	// the summaries no longer cover the target session's messages
+	deletedAt := time.Now().Truncate(time.Microsecond)
+	for _, schema := range []interface{}{
+		(*SummaryVectorStoreSchema)(nil),
+		(*SummaryStoreSchema)(nil),
+		(*SummaryAbstractSchema)(nil),
+	} {
+		_, err := tx.NewUpdate().
+			Model(schema).
+			Set("deleted_at = ?", deletedAt).
+			Where("session_id IN (?)", bun.In([]string{targetSessionID, sourceSessionID})).
+			Exec(ctx)
+		if err != nil {
+			return store.NewStorageError(fmt.Sprintf("failed to delete %T rows", schema), err)
+		}
+	}
+
+	_, err = tx.NewUpdate().
+		Model((*SessionSchema)(nil)).
+		Set("metadata = ?", metadata).
+		Set("user_id = ?", userID).
+		Set("updated_at = current_timestamp").
+		Where("session_id = ?", targetSessionID).
+		Exec(ctx)
+	if err != nil {
+		return store.NewStorageError("failed to update target session", err)
+	}
+
+	if err := softDeleteSession(ctx, tx, sourceSessionID); err != nil {
+		return store.NewStorageError("failed to delete source session", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return store.NewStorageError("failed to commit transaction", err)
+	}
+
+	return nil
+}
+
+// mergeSessionMetadata deep merges the metadata of the source session into the target's. The
+// values of the session selected by conflict are kept where both set a key.
+func mergeSessionMetadata(
+	target map[string]interface{},
+	source map[string]interface{},
+	conflict models.MetadataConflict,
+) (map[string]interface{}, error) {
+	merged := make(map[string]interface{}, len(target)+len(source))
+	if err := mergo.Merge(&merged, target); err != nil {
+		return nil, err
+	}
+	var opts []func(*mergo.Config)
+	if conflict == models.MetadataConflictSource {
+		opts = append(opts, mergo.WithOverride)
+	}
+	if err := mergo.Merge(&merged, source, opts...); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
@@ -0,0 +1,135 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeSessions(t *testing.T) {
+	userID := testutils.GenerateRandomString(16)
+	_, err := NewUserStoreDAO(testDB).Create(testCtx, &models.CreateUserRequest{UserID: userID})
+	require.NoError(t, err)
+
+	sessionDAO := NewSessionDAO(testDB)
+	targetID := testutils.GenerateRandomString(16)
+	_, err = sessionDAO.Create(testCtx, &models.CreateSessionRequest{
+		SessionID: targetID,
+		UserID:    &userID,
+		Metadata:  map[string]interface{}{"plan": "pro", "prefs": map[string]interface{}{"a": 1}},
+	})
+	require.NoError(t, err)
+	sourceID := testutils.GenerateRandomString(16)
+	_, err = sessionDAO.Create(testCtx, &models.CreateSessionRequest{
+		SessionID: sourceID,
+		Metadata:  map[string]interface{}{"plan": "free", "prefs": map[string]interface{}{"b": 2}},
+	})
+	require.NoError(t, err)
+
+	msgs, err := putMessages(testCtx, testDB, targetID, []models.Message{
+		{Role: "user", Content: "Hello"},
+	})
+	require.NoError(t, err)
+	_, err = putSummary(testCtx, testDB, targetID, &models.Summary{
+		Content:          "a greeting",
+		SummaryPointUUID: msgs[0].UUID,
+	})
+	require.NoError(t, err)
+	_, err = putMessages(testCtx, testDB, sourceID, []models.Message{
+		{Role: "user", Content: "I browsed anonymously"},
+	})
+	require.NoError(t, err)
+
+	session, err := appState.MemoryStore.MergeSessions(
+		testCtx,
+		appState,
+		targetID,
+		&models.MergeSessionsRequest{SourceSessionID: sourceID},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, userID, *session.UserID)
+	// the target's values are kept by default
+	assert.Equal(t, "pro", session.Metadata["plan"])
+	assert.Equal(t, map[string]interface{}{"a": 1.0, "b": 2.0}, session.Metadata["prefs"])
+
+	count, err := testDB.NewSelect().
+		Model((*MessageStoreSchema)(nil)).
+		Where("session_id = ?", targetID).
+		Count(testCtx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// the target's summary no longer covers its messages
+	summary, err := getSummary(testCtx, testDB, targetID)
+	require.NoError(t, err)
+	assert.Nil(t, summary)
+
+	_, err = sessionDAO.Get(testCtx, sourceID)
+	assert.ErrorIs(t, err, models.ErrNotFound)
+
+	t.Run("merging a deleted session returns not found", func(t *testing.T) {
+		_, err := appState.MemoryStore.MergeSessions(
+			testCtx,
+			appState,
+			targetID,
+			&models.MergeSessionsRequest{SourceSessionID: sourceID},
+		)
+		assert.ErrorIs(t, err, models.ErrNotFound)
+	})
+
+	t.Run("sessions of different users can't be merged", func(t *testing.T) {
+		otherUserID := testutils.GenerateRandomString(16)
+		_, err := NewUserStoreDAO(testDB).Create(
+			testCtx,
+			&models.CreateUserRequest{UserID: otherUserID},
+		)
+		require.NoError(t, err)
+		otherID := testutils.GenerateRandomString(16)
+		_, err = sessionDAO.Create(testCtx, &models.CreateSessionRequest{
+			SessionID: otherID,
+			UserID:    &otherUserID,
+		})
+		require.NoError(t, err)
+
+		_, err = appState.MemoryStore.MergeSessions(
+			testCtx,
+			appState,
+			targetID,
+			&models.MergeSessionsRequest{SourceSessionID: otherID},
+		)
+		assert.ErrorIs(t, err, models.ErrBadRequest)
+	})
+}
+
+func TestMergeSessionMetadata(t *testing.T) {
+	target := map[string]interface{}{"a": 1, "nested": map[string]interface{}{"x": 1}}
+	source := map[string]interface{}{
+		"a":      2,
+		"b":      3,
+		"nested": map[string]interface{}{"x": 2, "y": 3},
+	}
+
+	merged, err := mergeSessionMetadata(target, source, models.MetadataConflictTarget)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"a":      1,
+		"b":      3,
+		"nested": map[string]interface{}{"x": 1, "y": 3},
+	}, merged)
+
+	target = map[string]interface{}{"a": 1, "nested": map[string]interface{}{"x": 1}}
+	merged, err = mergeSessionMetadata(target, source, models.MetadataConflictSource)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"a":      2,
+		"b":      3,
+		"nested": map[string]interface{}{"x": 2, "y": 3},
+	}, merged)
+
+	merged, err = mergeSessionMetadata(nil, nil, models.MetadataConflictTarget)
+	require.NoError(t, err)
+	assert.Empty(t, merged)
+}
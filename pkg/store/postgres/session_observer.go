@@ -0,0 +1,552 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgproto3"
+)
+
+const (
+	// sessionObserverPublication is the publication the observer's replication slot reads from
+	sessionObserverPublication = "zep_message_observer"
+	sessionObserverBufferSize  = 100
+	standbyStatusInterval      = 10 * time.Second
+	observerSetupTimeout       = 30 * time.Second
+)
+
+const (
+	MessageEventInsert = "insert"
+	MessageEventUpdate = "update"
+	MessageEventDelete = "delete"
+)
+
+// slot names are interpolated into replication commands, so restrict them to the
+// characters Postgres allows in slot names
+var slotNameRegex = regexp.MustCompile(`^[a-z0-9_]{1,63}$`)
+
+// postgresEpoch is the epoch used by the replication protocol's timestamps
+var postgresEpoch = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// MessageEvent is a change to a row in the message table. Soft deletes are reported as
+// updates. For deletes, only the message UUID is populated.
+type MessageEvent struct {
+	Type      string
+	SessionID string
+	Message   models.Message
+}
+
+// SessionObserver streams changes to messages using Postgres logical replication. Requires
+// wal_level=logical and a role with the REPLICATION attribute.
+type SessionObserver struct {
+	conn      *pgconn.PgConn
+	connStr   string
+	slotName  string
+	events    chan MessageEvent
+	relations map[uint32]*observedRelation
+	// lsn is the position up to which WAL has been received and delivered
+	lsn       uint64
+	cancel    context.CancelFunc
+	done      chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+}
+
+type observedRelation struct {
+	name    string
+	columns []string
+}
+
+// NewSessionObserver creates the slotName logical replication slot if it does not exist
+// and starts streaming message changes from it. The slot is retained across restarts of
+// the observer until Close is called, so no events are lost while the observer is down.
+func NewSessionObserver(connStr string, slotName string) (*SessionObserver, error) {
+	if !slotNameRegex.MatchString(slotName) {
+		return nil, models.NewBadRequestError("invalid replication slot name: " + slotName)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), observerSetupTimeout)
+	defer cancel()
+
+	if err := createObserverPublication(ctx, connStr); err != nil {
+		return nil, err
+	}
+
+	config, err := pgconn.ParseConfig(connStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse connection string: %w", err)
+	}
+	config.RuntimeParams["replication"] = "database"
+
+	conn, err := pgconn.ConnectConfig(ctx, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open replication connection: %w", err)
+	}
+
+	if err := startReplication(ctx, conn, slotName); err != nil {
+		_ = conn.Close(ctx)
+		return nil, err
+	}
+
+	runCtx, runCancel := context.WithCancel(context.Background())
+	o := &SessionObserver{
+		conn:      conn,
+		connStr:   connStr,
+		slotName:  slotName,
+		events:    make(chan MessageEvent, sessionObserverBufferSize),
+		relations: make(map[uint32]*observedRelation),
+		cancel:    runCancel,
+		done:      make(chan struct{}),
+	}
+	go o.run(runCtx)
+
+	return o, nil
+}
+
+// Events returns the channel on which message events are delivered. The channel is
+// closed when the observer stops.
+func (o *SessionObserver) Events() <-chan MessageEvent {
+	return o.events
+}
+
+// Close stops the observer, closes the replication connection, and drops the
+// replication slot.
+func (o *SessionObserver) Close() error {
+	o.closeOnce.Do(func() {
+		o.cancel()
+		<-o.done
+
+		ctx, cancel := context.WithTimeout(context.Background(), observerSetupTimeout)
+		defer cancel()
+
+		closeErr := o.conn.Close(ctx)
+
+		// the slot can't be dropped over the replication connection while it is
+		// streaming, so use a regular connection
+		conn, err := pgconn.Connect(ctx, o.connStr)
+		if err != nil {
+			o.closeErr = errors.Join(closeErr, fmt.Errorf("failed to connect: %w", err))
+			return
+		}
+		defer conn.Close(ctx)
+
+		_, err = conn.ExecParams(
+			ctx,
+			"SELECT pg_drop_replication_slot($1)",
+			[][]byte{[]byte(o.slotName)},
+			nil,
+			nil,
+			nil,
+		).Close()
+		if err != nil {
+			err = fmt.Errorf("failed to drop replication slot %s: %w", o.slotName, err)
+		}
+		o.closeErr = errors.Join(closeErr, err)
+	})
+
+	return o.closeErr
+}
+
+// createObserverPublication creates the observer's publication if it does not exist. If the
+// message table is hash partitioned, changes are published as changes to the message table
+// rather than to the partition they were made in, so that the observer recognizes them.
+func createObserverPublication(ctx context.Context, connStr string) error {
+	conn, err := pgconn.Connect(ctx, connStr)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close(ctx)
+
+	sql := fmt.Sprintf(`DO $$
+BEGIN
+    IF NOT EXISTS(SELECT FROM pg_publication WHERE pubname = '%[1]s') THEN
+        CREATE PUBLICATION %[1]s FOR TABLE message;
+    ELSIF NOT EXISTS(
+        SELECT FROM pg_publication_rel AS pr
+        JOIN pg_publication AS p ON p.oid = pr.prpubid
+        WHERE p.pubname = '%[1]s' AND pr.prrelid = to_regclass('message')
+    ) THEN
+        -- the message table was dropped and recreated since the publication was created
+        ALTER PUBLICATION %[1]s ADD TABLE message;
+    END IF;
+    -- the table may have been partitioned since the publication was created
+    IF EXISTS(SELECT FROM pg_partitioned_table WHERE partrelid = to_regclass('message')) THEN
+        ALTER PUBLICATION %[1]s SET (publish_via_partition_root = true);
+    END IF;
+END
+$$;`, sessionObserverPublication)
+	if _, err := conn.Exec(ctx, sql).ReadAll(); err != nil {
+		return fmt.Errorf("failed to create publication: %w", err)
+	}
+
+	return nil
+}
+
+func startReplication(ctx context.Context, conn *pgconn.PgConn, slotName string) error {
+	_, err := conn.Exec(
+		ctx,
+		fmt.Sprintf("CREATE_REPLICATION_SLOT %s LOGICAL pgoutput NOEXPORT_SNAPSHOT", slotName),
+	).ReadAll()
+	if err != nil {
+		// reuse an existing slot so that we resume where we left off
+		var pgErr *pgconn.PgError
+		if !errors.As(err, &pgErr) || pgErr.Code != "42710" {
+			return fmt.Errorf("failed to create replication slot %s: %w", slotName, err)
+		}
+	}
+
+	conn.Frontend().Send(&pgproto3.Query{
+		String: fmt.Sprintf(
+			"START_REPLICATION SLOT %s LOGICAL 0/0 (proto_version '1', publication_names '%s')",
+			slotName,
+			sessionObserverPublication,
+		),
+	})
+	if err := conn.Frontend().Flush(); err != nil {
+		return fmt.Errorf("failed to start replication: %w", err)
+	}
+
+	for {
+		msg, err := conn.ReceiveMessage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to start replication: %w", err)
+		}
+		switch msg := msg.(type) {
+		case *pgproto3.CopyBothResponse:
+			return nil
+		case *pgproto3.ErrorResponse:
+			return fmt.Errorf(
+				"failed to start replication: %w",
+				pgconn.ErrorResponseToPgError(msg),
+			)
+		}
+	}
+}
+
+func (o *SessionObserver) run(ctx context.Context) {
+	defer close(o.done)
+	defer close(o.events)
+
+	nextStatus := time.Now().Add(standbyStatusInterval)
+	for {
+		if time.Now().After(nextStatus) {
+			if err := o.sendStandbyStatus(); err != nil {
+				log.Errorf("session observer: %s", err)
+				return
+			}
+			nextStatus = time.Now().Add(standbyStatusInterval)
+		}
+
+		receiveCtx, cancel := context.WithDeadline(ctx, nextStatus)
+		msg, err := o.conn.ReceiveMessage(receiveCtx)
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if pgconn.Timeout(err) {
+				continue
+			}
+			log.Errorf("session observer: failed to receive message: %s", err)
+			return
+		}
+
+		switch msg := msg.(type) {
+		case *pgproto3.CopyData:
+			replyRequested, err := o.handleCopyData(ctx, msg.Data)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Errorf("session observer: %s", err)
+				}
+				return
+			}
+			if replyRequested {
+				nextStatus = time.Time{}
+			}
+		case *pgproto3.ErrorResponse:
+			log.Errorf("session observer: %s", pgconn.ErrorResponseToPgError(msg))
+			return
+		}
+	}
+}
+
+// handleCopyData handles a replication protocol message. It returns true if the server
+// has requested a standby status update.
+func (o *SessionObserver) handleCopyData(ctx context.Context, data []byte) (bool, error) {
+	if len(data) == 0 {
+		return false, nil
+	}
+
+	switch data[0] {
+	case 'k': // primary keepalive
+		if len(data) < 18 {
+			return false, errors.New("malformed keepalive message")
+		}
+		return data[17] == 1, nil
+	case 'w': // XLogData
+		if len(data) < 25 {
+			return false, errors.New("malformed XLogData message")
+		}
+		walStart := binary.BigEndian.Uint64(data[1:9])
+		walData := data[25:]
+
+		event, ok, err := o.decodeLogicalMessage(walData)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			select {
+			case o.events <- event:
+			case <-ctx.Done():
+				return false, ctx.Err()
+			}
+		}
+		o.lsn = walStart + uint64(len(walData))
+	}
+
+	return false, nil
+}
+
+// sendStandbyStatus reports the position up to which events have been delivered, allowing
+// the server to discard WAL the observer no longer needs.
+func (o *SessionObserver) sendStandbyStatus() error {
+	buf := make([]byte, 34)
+	buf[0] = 'r'
+	binary.BigEndian.PutUint64(buf[1:], o.lsn)  // written
+	binary.BigEndian.PutUint64(buf[9:], o.lsn)  // flushed
+	binary.BigEndian.PutUint64(buf[17:], o.lsn) // applied
+	binary.BigEndian.PutUint64(buf[25:], uint64(time.Since(postgresEpoch).Microseconds()))
+	buf[33] = 0
+
+	o.conn.Frontend().Send(&pgproto3.CopyData{Data: buf})
+	if err := o.conn.Frontend().Flush(); err != nil {
+		return fmt.Errorf("failed to send standby status: %w", err)
+	}
+
+	return nil
+}
+
+// decodeLogicalMessage decodes a pgoutput message. ok is false for messages that
+// don't describe a change to the message table. Only what startReplication requests is
+// decoded: protocol version 1, with values in text format.
+func (o *SessionObserver) decodeLogicalMessage(data []byte) (MessageEvent, bool, error) {
+	if len(data) == 0 {
+		return MessageEvent{}, false, nil
+	}
+
+	r := &pgoutputReader{buf: bytes.NewBuffer(data[1:])}
+	var eventType string
+	var tuple map[string]*string
+	switch data[0] {
+	case 'R':
+		id := r.uint32()
+		r.string() // namespace
+		rel := &observedRelation{name: r.string()}
+		r.byte() // replica identity
+		n := int(r.uint16())
+		for i := 0; i < n; i++ {
+			r.byte() // flags
+			rel.columns = append(rel.columns, r.string())
+			r.uint32() // type OID
+			r.uint32() // type modifier
+		}
+		if r.err != nil {
+			return MessageEvent{}, false, fmt.Errorf("malformed relation message: %w", r.err)
+		}
+		o.relations[id] = rel
+		return MessageEvent{}, false, nil
+	case 'I':
+		eventType = MessageEventInsert
+		tuple = o.readTuple(r, r.uint32(), "N")
+	case 'U':
+		eventType = MessageEventUpdate
+		id := r.uint32()
+		// skip the old tuple, if present
+		if b := r.peek(); b == 'K' || b == 'O' {
+			o.readTuple(r, id, string(b))
+		}
+		tuple = o.readTuple(r, id, "N")
+	case 'D':
+		eventType = MessageEventDelete
+		id := r.uint32()
+		tuple = o.readTuple(r, id, "KO")
+	default:
+		// begin, commit, origin, type, and truncate messages are not of interest
+		return MessageEvent{}, false, nil
+	}
+	if r.err != nil {
+		return MessageEvent{}, false, fmt.Errorf("malformed %s message: %w", eventType, r.err)
+	}
+	if tuple == nil {
+		return MessageEvent{}, false, nil
+	}
+
+	event, err := tupleToMessageEvent(eventType, tuple)
+	if err != nil {
+		return MessageEvent{}, false, err
+	}
+
+	return event, true, nil
+}
+
+// readTuple reads a TupleData section preceded by one of the markers in markers. It
+// returns nil if the relation is not the message table.
+func (o *SessionObserver) readTuple(
+	r *pgoutputReader,
+	relationID uint32,
+	markers string,
+) map[string]*string {
+	if r.err != nil {
+		return nil
+	}
+	marker := r.byte()
+	if !strings.ContainsRune(markers, rune(marker)) {
+		r.err = fmt.Errorf("unexpected tuple marker %q", marker)
+		return nil
+	}
+
+	n := int(r.uint16())
+	values := make([]*string, n)
+	for i := 0; i < n; i++ {
+		switch r.byte() {
+		case 't':
+			v := string(r.bytes(int(r.uint32())))
+			values[i] = &v
+		case 'n', 'u':
+			// null or unchanged TOAST value
+		default:
+			r.err = errors.New("unsupported tuple data type")
+		}
+	}
+
+	rel, ok := o.relations[relationID]
+	if r.err != nil || !ok || rel.name != "message" {
+		return nil
+	}
+	tuple := make(map[string]*string, n)
+	for i, v := range values {
+		if i < len(rel.columns) {
+			tuple[rel.columns[i]] = v
+		}
+	}
+
+	return tuple
+}
+
+func tupleToMessageEvent(eventType string, tuple map[string]*string) (MessageEvent, error) {
+	event := MessageEvent{Type: eventType}
+	var err error
+	for column, value := range tuple {
+		if value == nil {
+			continue
+		}
+		v := *value
+		switch column {
+		case "uuid":
+			event.Message.UUID, err = uuid.Parse(v)
+		case "session_id":
+			event.SessionID = v
+		case "created_at":
+			event.Message.CreatedAt, err = parseTimestamptz(v)
+		case "updated_at":
+			event.Message.UpdatedAt, err = parseTimestamptz(v)
+		case "role":
+			event.Message.Role = v
+		case "content":
+			event.Message.Content = v
+		case "token_count":
+			event.Message.TokenCount, err = strconv.Atoi(v)
+		case "is_system":
+			event.Message.IsSystem = v == "t"
+		case "metadata":
+			err = json.Unmarshal([]byte(v), &event.Message.Metadata)
+		}
+		if err != nil {
+			return MessageEvent{}, fmt.Errorf("failed to decode message %s: %w", column, err)
+		}
+	}
+
+	return event, nil
+}
+
+// parseTimestamptz parses a timestamptz in Postgres' text output format
+func parseTimestamptz(v string) (time.Time, error) {
+	t, err := time.Parse("2006-01-02 15:04:05.999999-07", v)
+	if err != nil {
+		return time.Parse("2006-01-02 15:04:05.999999-07:00", v)
+	}
+	return t, nil
+}
+
+// pgoutputReader reads the fields of a pgoutput message. The first error is retained
+// and subsequent reads return zero values.
+type pgoutputReader struct {
+	buf *bytes.Buffer
+	err error
+}
+
+func (r *pgoutputReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || r.buf.Len() < n {
+		r.err = errors.New("message too short")
+		return nil
+	}
+	return r.buf.Next(n)
+}
+
+func (r *pgoutputReader) byte() byte {
+	b := r.bytes(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (r *pgoutputReader) peek() byte {
+	if r.err != nil || r.buf.Len() == 0 {
+		return 0
+	}
+	return r.buf.Bytes()[0]
+}
+
+func (r *pgoutputReader) uint16() uint16 {
+	b := r.bytes(2)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint16(b)
+}
+
+func (r *pgoutputReader) uint32() uint32 {
+	b := r.bytes(4)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(b)
+}
+
+func (r *pgoutputReader) string() string {
+	if r.err != nil {
+		return ""
+	}
+	s, err := r.buf.ReadString(0)
+	if err != nil {
+		r.err = errors.New("unterminated string")
+		return ""
+	}
+	return s[:len(s)-1]
+}
@@ -0,0 +1,367 @@
+package postgres
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pgoutputBuilder builds pgoutput messages for tests
+type pgoutputBuilder []byte
+
+func (b pgoutputBuilder) byte(v byte) pgoutputBuilder { return append(b, v) }
+
+func (b pgoutputBuilder) uint16(v uint16) pgoutputBuilder {
+	return binary.BigEndian.AppendUint16(b, v)
+}
+
+func (b pgoutputBuilder) uint32(v uint32) pgoutputBuilder {
+	return binary.BigEndian.AppendUint32(b, v)
+}
+
+func (b pgoutputBuilder) string(v string) pgoutputBuilder { return append(append(b, v...), 0) }
+
+func (b pgoutputBuilder) tuple(marker byte, values ...*string) pgoutputBuilder {
+	b = b.byte(marker).uint16(uint16(len(values)))
+	for _, v := range values {
+		if v == nil {
+			b = b.byte('n')
+			continue
+		}
+		b = b.byte('t').uint32(uint32(len(*v)))
+		b = append(b, *v...)
+	}
+	return b
+}
+
+func relationMessage(id uint32, name string, columns ...string) []byte {
+	b := pgoutputBuilder{'R'}.uint32(id).string("public").string(name).byte('d')
+	b = b.uint16(uint16(len(columns)))
+	for _, c := range columns {
+		b = b.byte(0).string(c).uint32(25).uint32(0)
+	}
+	return b
+}
+
+func strPtr(s string) *string { return &s }
+
+// xLogData frames a pgoutput message as the XLogData message that carries it
+func xLogData(walStart uint64, data []byte) []byte {
+	b := binary.BigEndian.AppendUint64([]byte{'w'}, walStart)
+	b = binary.BigEndian.AppendUint64(b, walStart+uint64(len(data))) // WAL end
+	b = binary.BigEndian.AppendUint64(b, 0)                          // send time
+	return append(b, data...)
+}
+
+// keepalive builds a primary keepalive message
+func keepalive(walEnd uint64, replyRequested bool) []byte {
+	b := binary.BigEndian.AppendUint64([]byte{'k'}, walEnd)
+	b = binary.BigEndian.AppendUint64(b, 0) // send time
+	if replyRequested {
+		return append(b, 1)
+	}
+	return append(b, 0)
+}
+
+func TestSessionObserverDecodeLogicalMessage(t *testing.T) {
+	o := &SessionObserver{relations: make(map[uint32]*observedRelation)}
+	messageUUID := uuid.New()
+
+	_, ok, err := o.decodeLogicalMessage(relationMessage(
+		1,
+		"message",
+		"uuid", "session_id", "created_at", "role", "content", "metadata", "token_count", "is_system",
+	))
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	insert := pgoutputBuilder{'I'}.uint32(1).tuple(
+		'N',
+		strPtr(messageUUID.String()),
+		strPtr("session"),
+		strPtr("2023-12-14 10:11:12.123456+00"),
+		strPtr("user"),
+		strPtr("Hello"),
+		strPtr(`{"key": "value"}`),
+		strPtr("3"),
+		strPtr("t"),
+	)
+	event, ok, err := o.decodeLogicalMessage(insert)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, MessageEventInsert, event.Type)
+	assert.Equal(t, "session", event.SessionID)
+	assert.Equal(t, messageUUID, event.Message.UUID)
+	assert.Equal(t, "user", event.Message.Role)
+	assert.Equal(t, "Hello", event.Message.Content)
+	assert.Equal(t, "value", event.Message.Metadata["key"])
+	assert.Equal(t, 3, event.Message.TokenCount)
+	assert.True(t, event.Message.IsSystem)
+	assert.Equal(t, 2023, event.Message.CreatedAt.Year())
+
+	update := pgoutputBuilder{'U'}.uint32(1).tuple(
+		'N',
+		strPtr(messageUUID.String()),
+		strPtr("session"),
+		nil,
+		strPtr("user"),
+		strPtr("Hello again"),
+		nil,
+		strPtr("4"),
+		strPtr("f"),
+	)
+	event, ok, err = o.decodeLogicalMessage(update)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, MessageEventUpdate, event.Type)
+	assert.Equal(t, "Hello again", event.Message.Content)
+
+	del := pgoutputBuilder{'D'}.uint32(1).tuple(
+		'K',
+		strPtr(messageUUID.String()),
+		nil, nil, nil, nil, nil, nil, nil,
+	)
+	event, ok, err = o.decodeLogicalMessage(del)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, MessageEventDelete, event.Type)
+	assert.Equal(t, messageUUID, event.Message.UUID)
+
+	t.Run("ignores other relations", func(t *testing.T) {
+		_, _, err := o.decodeLogicalMessage(relationMessage(2, "session", "session_id"))
+		require.NoError(t, err)
+		_, ok, err := o.decodeLogicalMessage(
+			pgoutputBuilder{'I'}.uint32(2).tuple('N', strPtr("session")),
+		)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("rejects truncated messages", func(t *testing.T) {
+		_, _, err := o.decodeLogicalMessage(insert[:len(insert)-2])
+		assert.Error(t, err)
+	})
+}
+
+func TestSessionObserverHandleCopyData(t *testing.T) {
+	newObserver := func() *SessionObserver {
+		return &SessionObserver{
+			events:    make(chan MessageEvent, 1),
+			relations: make(map[uint32]*observedRelation),
+		}
+	}
+
+	t.Run("keepalive", func(t *testing.T) {
+		o := newObserver()
+		reply, err := o.handleCopyData(context.Background(), keepalive(100, false))
+		require.NoError(t, err)
+		assert.False(t, reply)
+
+		reply, err = o.handleCopyData(context.Background(), keepalive(100, true))
+		require.NoError(t, err)
+		assert.True(t, reply)
+		// keepalives don't advance the delivered position
+		assert.Zero(t, o.lsn)
+	})
+
+	t.Run("XLogData", func(t *testing.T) {
+		o := newObserver()
+		relation := relationMessage(1, "message", "uuid", "session_id")
+		_, err := o.handleCopyData(context.Background(), xLogData(1000, relation))
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1000+len(relation)), o.lsn)
+		assert.Empty(t, o.events)
+
+		insert := pgoutputBuilder{'I'}.uint32(1).tuple(
+			'N',
+			strPtr(uuid.NewString()),
+			strPtr("session"),
+		)
+		_, err = o.handleCopyData(context.Background(), xLogData(2000, insert))
+		require.NoError(t, err)
+		assert.Equal(t, uint64(2000+len(insert)), o.lsn)
+		require.Len(t, o.events, 1)
+		event := <-o.events
+		assert.Equal(t, MessageEventInsert, event.Type)
+		assert.Equal(t, "session", event.SessionID)
+
+		commit := pgoutputBuilder{'C'}.byte(0)
+		_, err = o.handleCopyData(context.Background(), xLogData(3000, commit))
+		require.NoError(t, err)
+		assert.Equal(t, uint64(3000+len(commit)), o.lsn)
+		assert.Empty(t, o.events)
+	})
+
+	t.Run("delivery is cancelled with the context", func(t *testing.T) {
+		o := newObserver()
+		o.events <- MessageEvent{}
+		_, err := o.handleCopyData(
+			context.Background(),
+			xLogData(1000, relationMessage(1, "message", "uuid")),
+		)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		insert := pgoutputBuilder{'I'}.uint32(1).tuple('N', strPtr(uuid.NewString()))
+		_, err = o.handleCopyData(ctx, xLogData(2000, insert))
+		assert.ErrorIs(t, err, context.Canceled)
+		// the undelivered event's WAL isn't acknowledged
+		assert.Equal(t, uint64(1000+len(relationMessage(1, "message", "uuid"))), o.lsn)
+	})
+
+	t.Run("rejects malformed messages", func(t *testing.T) {
+		o := newObserver()
+		_, err := o.handleCopyData(context.Background(), keepalive(100, true)[:17])
+		assert.Error(t, err)
+		_, err = o.handleCopyData(context.Background(), xLogData(100, nil)[:24])
+		assert.Error(t, err)
+	})
+}
+
+func TestSessionObserverDecodeTuples(t *testing.T) {
+	messageUUID := uuid.New()
+	newObserver := func(t *testing.T) *SessionObserver {
+		o := &SessionObserver{relations: make(map[uint32]*observedRelation)}
+		_, _, err := o.decodeLogicalMessage(relationMessage(1, "message", "uuid", "content"))
+		require.NoError(t, err)
+		return o
+	}
+
+	t.Run("update with old key", func(t *testing.T) {
+		o := newObserver(t)
+		update := pgoutputBuilder{'U'}.uint32(1).
+			tuple('K', strPtr(messageUUID.String()), nil).
+			tuple('N', strPtr(messageUUID.String()), strPtr("new"))
+		event, ok, err := o.decodeLogicalMessage(update)
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, "new", event.Message.Content)
+	})
+
+	t.Run("update with old row", func(t *testing.T) {
+		o := newObserver(t)
+		update := pgoutputBuilder{'U'}.uint32(1).
+			tuple('O', strPtr(messageUUID.String()), strPtr("old")).
+			tuple('N', strPtr(messageUUID.String()), strPtr("new"))
+		event, ok, err := o.decodeLogicalMessage(update)
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, "new", event.Message.Content)
+	})
+
+	t.Run("unchanged TOAST value", func(t *testing.T) {
+		o := newObserver(t)
+		update := pgoutputBuilder{'U'}.uint32(1).
+			byte('N').uint16(2).
+			byte('t').uint32(uint32(len(messageUUID.String())))
+		update = append(update, messageUUID.String()...)
+		update = update.byte('u')
+		event, ok, err := o.decodeLogicalMessage(update)
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, messageUUID, event.Message.UUID)
+		assert.Empty(t, event.Message.Content)
+	})
+
+	t.Run("delete with old row", func(t *testing.T) {
+		o := newObserver(t)
+		del := pgoutputBuilder{'D'}.uint32(1).
+			tuple('O', strPtr(messageUUID.String()), strPtr("old"))
+		event, ok, err := o.decodeLogicalMessage(del)
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, MessageEventDelete, event.Type)
+		assert.Equal(t, messageUUID, event.Message.UUID)
+	})
+
+	t.Run("relation is redefined", func(t *testing.T) {
+		o := newObserver(t)
+		_, _, err := o.decodeLogicalMessage(relationMessage(1, "message", "content", "uuid"))
+		require.NoError(t, err)
+		insert := pgoutputBuilder{'I'}.uint32(1).
+			tuple('N', strPtr("Hello"), strPtr(messageUUID.String()))
+		event, ok, err := o.decodeLogicalMessage(insert)
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, "Hello", event.Message.Content)
+		assert.Equal(t, messageUUID, event.Message.UUID)
+	})
+
+	t.Run("unknown relation is ignored", func(t *testing.T) {
+		o := newObserver(t)
+		insert := pgoutputBuilder{'I'}.uint32(2).tuple('N', strPtr(messageUUID.String()))
+		_, ok, err := o.decodeLogicalMessage(insert)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("transaction messages are ignored", func(t *testing.T) {
+		o := newObserver(t)
+		for _, tag := range []byte{'B', 'C', 'O', 'Y', 'T'} {
+			_, ok, err := o.decodeLogicalMessage([]byte{tag})
+			require.NoError(t, err)
+			assert.False(t, ok, string(tag))
+		}
+	})
+
+	t.Run("rejects unexpected tuple markers", func(t *testing.T) {
+		o := newObserver(t)
+		insert := pgoutputBuilder{'I'}.uint32(1).tuple('K', strPtr(messageUUID.String()), nil)
+		_, _, err := o.decodeLogicalMessage(insert)
+		assert.ErrorContains(t, err, "unexpected tuple marker")
+	})
+
+	t.Run("rejects binary values", func(t *testing.T) {
+		o := newObserver(t)
+		insert := pgoutputBuilder{'I'}.uint32(1).byte('N').uint16(1).byte('b').uint32(0)
+		_, _, err := o.decodeLogicalMessage(insert)
+		assert.ErrorContains(t, err, "unsupported tuple data type")
+	})
+
+	t.Run("rejects invalid values", func(t *testing.T) {
+		o := newObserver(t)
+		insert := pgoutputBuilder{'I'}.uint32(1).tuple('N', strPtr("not a uuid"), nil)
+		_, _, err := o.decodeLogicalMessage(insert)
+		assert.ErrorContains(t, err, "failed to decode message uuid")
+	})
+
+	t.Run("rejects malformed relations", func(t *testing.T) {
+		o := newObserver(t)
+		relation := relationMessage(2, "message", "uuid")
+		_, _, err := o.decodeLogicalMessage(relation[:len(relation)-1])
+		assert.ErrorContains(t, err, "malformed relation message")
+	})
+}
+
+func TestParseTimestamptz(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Time
+	}{
+		{
+			"2023-12-14 10:11:12.123456+00",
+			time.Date(2023, 12, 14, 10, 11, 12, 123456000, time.UTC),
+		},
+		{"2023-12-14 10:11:12+00", time.Date(2023, 12, 14, 10, 11, 12, 0, time.UTC)},
+		{"2023-12-14 15:41:12.5+05:30", time.Date(2023, 12, 14, 10, 11, 12, 5e8, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			parsed, err := parseTimestamptz(tt.value)
+			require.NoError(t, err)
+			assert.True(t, tt.expected.Equal(parsed), parsed)
+		})
+	}
+}
+
+func TestNewSessionObserverRejectsInvalidSlotName(t *testing.T) {
+	_, err := NewSessionObserver("postgres://localhost", "slot; DROP TABLE message")
+	assert.Error(t, err)
+}
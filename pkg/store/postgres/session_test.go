@@ -2,11 +2,14 @@ package postgres
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/getzep/zep/pkg/models"
 	"github.com/getzep/zep/pkg/testutils"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/uptrace/bun"
 )
 
@@ -224,7 +227,7 @@ func TestSessionDAO_Delete(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := dao.Delete(testCtx, tt.sessionID)
+			err := dao.Delete(testCtx, tt.sessionID, false)
 
 			if tt.expectedError != nil {
 				assert.Error(t, err)
@@ -240,6 +243,52 @@ func TestSessionDAO_Delete(t *testing.T) {
 	}
 }
 
+func TestSessionDAO_HardDelete(t *testing.T) {
+	sessionStore := NewSessionDAO(testDB)
+
+	t.Run("removes session and related records", func(t *testing.T) {
+		sessionID, err := setupSessionDeleteTestData(testCtx, testDB, "")
+		assert.NoError(t, err, "setupTestDeleteData should not return an error")
+
+		err = sessionStore.Delete(testCtx, sessionID, true)
+		assert.NoError(t, err, "hard delete should not return an error")
+
+		for _, schema := range messageTableList {
+			count, err := testDB.NewSelect().
+				Model(schema).
+				WhereAllWithDeleted().
+				Where("session_id = ?", sessionID).
+				Count(testCtx)
+			assert.NoError(t, err)
+			assert.Equal(t, 0, count, "no rows should remain in %T", schema)
+		}
+	})
+
+	t.Run("removes a soft-deleted session", func(t *testing.T) {
+		sessionID, err := setupSessionDeleteTestData(testCtx, testDB, "")
+		assert.NoError(t, err, "setupTestDeleteData should not return an error")
+
+		err = sessionStore.Delete(testCtx, sessionID, false)
+		assert.NoError(t, err, "soft delete should not return an error")
+
+		err = sessionStore.Delete(testCtx, sessionID, true)
+		assert.NoError(t, err, "hard delete should not return an error")
+
+		count, err := testDB.NewSelect().
+			Model((*SessionSchema)(nil)).
+			WhereAllWithDeleted().
+			Where("session_id = ?", sessionID).
+			Count(testCtx)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+
+	t.Run("non-existent session", func(t *testing.T) {
+		err := sessionStore.Delete(testCtx, "nonexistent", true)
+		assert.ErrorIs(t, err, models.ErrNotFound)
+	})
+}
+
 func TestSessionDAO_DeleteSessionDeletesSummaryMessages(t *testing.T) {
 	memoryWindow := 10
 	appState.Config.Memory.MessageWindow = memoryWindow
@@ -249,7 +298,7 @@ func TestSessionDAO_DeleteSessionDeletesSummaryMessages(t *testing.T) {
 	sessionID, err := setupSessionDeleteTestData(testCtx, testDB, "")
 	assert.NoError(t, err, "setupTestDeleteData should not return an error")
 
-	err = sessionStore.Delete(testCtx, sessionID)
+	err = sessionStore.Delete(testCtx, sessionID, false)
 	assert.NoError(t, err, "deleteSession should not return an error")
 
 	// Test that session is deleted
@@ -257,7 +306,7 @@ func TestSessionDAO_DeleteSessionDeletesSummaryMessages(t *testing.T) {
 	assert.ErrorIs(t, err, models.ErrNotFound)
 
 	// Test that messages are deleted
-	respMessages, err := getMessages(testCtx, testDB, sessionID, memoryWindow, nil, 0)
+	respMessages, err := getMessages(testCtx, testDB, sessionID, memoryWindow, nil, 0, nil)
 	assert.NoError(t, err, "getMessages should not return an error")
 	assert.Nil(t, respMessages, "getMessages should return nil")
 
@@ -273,7 +322,7 @@ func TestSessionDAO_UndeleteSession(t *testing.T) {
 
 	sessionStore := NewSessionDAO(testDB)
 
-	err = sessionStore.Delete(testCtx, sessionID)
+	err = sessionStore.Delete(testCtx, sessionID, false)
 	assert.NoError(t, err, "deleteSession should not return an error")
 
 	session := &models.UpdateSessionRequest{
@@ -287,11 +336,54 @@ func TestSessionDAO_UndeleteSession(t *testing.T) {
 	assert.Emptyf(t, updatesSession.DeletedAt, "Update should not have a DeletedAt value")
 
 	// Test that messages remain deleted
-	respMessages, err := getMessages(testCtx, testDB, sessionID, 2, nil, 0)
+	respMessages, err := getMessages(testCtx, testDB, sessionID, 2, nil, 0, nil)
 	assert.NoError(t, err, "getMessages should not return an error")
 	assert.Nil(t, respMessages, "getMessages should return nil")
 }
 
+func TestSessionDAO_Restore(t *testing.T) {
+	sessionID, err := setupSessionDeleteTestData(testCtx, testDB, "")
+	require.NoError(t, err, "setupTestDeleteData should not return an error")
+
+	sessionStore := NewSessionDAO(testDB)
+
+	// a message deleted before the session isn't restored with it
+	messages, err := getMessages(testCtx, testDB, sessionID, 2, nil, 0, nil)
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	err = deleteMessage(testCtx, testDB, sessionID, messages[1].UUID)
+	require.NoError(t, err)
+
+	err = sessionStore.Delete(testCtx, sessionID, false)
+	require.NoError(t, err, "deleteSession should not return an error")
+
+	restored, err := sessionStore.Restore(testCtx, sessionID)
+	require.NoError(t, err, "Restore should not return an error")
+	assert.Equal(t, sessionID, restored.SessionID)
+
+	_, err = sessionStore.Get(testCtx, sessionID)
+	assert.NoError(t, err, "Get should not return an error")
+
+	respMessages, err := getMessages(testCtx, testDB, sessionID, 2, nil, 0, nil)
+	assert.NoError(t, err, "getMessages should not return an error")
+	require.Len(t, respMessages, 1)
+	assert.Equal(t, messages[0].UUID, respMessages[0].UUID)
+
+	respSummary, err := getSummary(testCtx, testDB, sessionID)
+	assert.NoError(t, err, "getSummary should not return an error")
+	assert.NotNil(t, respSummary, "getSummary should return the restored summary")
+
+	t.Run("session that isn't deleted returns bad request", func(t *testing.T) {
+		_, err := sessionStore.Restore(testCtx, sessionID)
+		assert.ErrorIs(t, err, models.ErrBadRequest)
+	})
+
+	t.Run("non-existent session returns not found", func(t *testing.T) {
+		_, err := sessionStore.Restore(testCtx, "nonexistent")
+		assert.ErrorIs(t, err, models.ErrNotFound)
+	})
+}
+
 func setupSessionDeleteTestData(
 	ctx context.Context,
 	testDB *bun.DB,
@@ -513,3 +605,84 @@ func reverse(sessions []*models.Session) []*models.Session {
 	}
 	return reversed
 }
+
+func TestSessionQuota(t *testing.T) {
+	tenantID := testutils.GenerateRandomString(8)
+	ctx := models.WithProjectID(testCtx, tenantID)
+	ctx = models.WithSessionQuota(ctx, models.SessionQuota{TenantID: tenantID, Quota: 2})
+
+	_, err := appState.MemoryStore.CreateSession(ctx, appState, &models.CreateSessionRequest{
+		SessionID: testutils.GenerateRandomString(16),
+	})
+	require.NoError(t, err)
+
+	// writing memory to a session that doesn't exist creates it
+	sessionID := testutils.GenerateRandomString(16)
+	memory := &models.Memory{Messages: []models.Message{{Role: "user", Content: "Hello"}}}
+	err = appState.MemoryStore.PutMemory(ctx, appState, sessionID, memory, true)
+	require.NoError(t, err)
+
+	count, err := appState.MemoryStore.GetActiveSessionCount(ctx, tenantID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	t.Run("sessions can't be created once the quota is reached", func(t *testing.T) {
+		_, err := appState.MemoryStore.CreateSession(ctx, appState, &models.CreateSessionRequest{
+			SessionID: testutils.GenerateRandomString(16),
+		})
+		assert.ErrorIs(t, err, models.ErrSessionQuotaExceeded)
+
+		err = appState.MemoryStore.PutMemory(
+			ctx,
+			appState,
+			testutils.GenerateRandomString(16),
+			memory,
+			true,
+		)
+		assert.ErrorIs(t, err, models.ErrSessionQuotaExceeded)
+
+		messages, err := getMessages(testCtx, testDB, sessionID, 1, nil, 0, nil)
+		require.NoError(t, err)
+		require.Len(t, messages, 1)
+		_, err = appState.MemoryStore.ForkSession(ctx, sessionID, &models.ForkSessionRequest{
+			SessionID:   testutils.GenerateRandomString(16),
+			MessageUUID: messages[0].UUID,
+		})
+		assert.ErrorIs(t, err, models.ErrSessionQuotaExceeded)
+	})
+
+	t.Run("memory can be written to existing sessions", func(t *testing.T) {
+		err := appState.MemoryStore.PutMemory(ctx, appState, sessionID, memory, true)
+		assert.NoError(t, err)
+	})
+
+	t.Run("concurrent creates don't exceed the quota", func(t *testing.T) {
+		const quota = 3
+		tenantID := testutils.GenerateRandomString(8)
+		ctx := models.WithProjectID(testCtx, tenantID)
+		ctx = models.WithSessionQuota(ctx, models.SessionQuota{TenantID: tenantID, Quota: quota})
+
+		var wg sync.WaitGroup
+		var created atomic.Int64
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := NewSessionDAO(testDB).Create(ctx, &models.CreateSessionRequest{
+					SessionID: testutils.GenerateRandomString(16),
+				})
+				if err == nil {
+					created.Add(1)
+					return
+				}
+				assert.ErrorIs(t, err, models.ErrSessionQuotaExceeded)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int64(quota), created.Load())
+		count, err := GetActiveSessionCount(testCtx, testDB, tenantID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(quota), count)
+	})
+}
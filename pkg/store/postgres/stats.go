@@ -0,0 +1,247 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+	"github.com/uptrace/bun"
+)
+
+// rateGranularities are the date_trunc fields accepted by the rate queries. The granularity
+// is interpolated into SQL, so it must be validated against this list before use.
+var rateGranularities = map[string]bool{
+	"minute": true,
+	"hour":   true,
+	"day":    true,
+}
+
+// GetSessionCreationRate returns the number of sessions created per time bucket across the
+// deployment, most recent bucket first. granularity is one of "minute", "hour", or "day" and
+// last is the number of buckets to return. Soft-deleted sessions are included, as they
+// still count towards throughput.
+func GetSessionCreationRate(
+	ctx context.Context,
+	db *bun.DB,
+	granularity string,
+	last int,
+) ([]models.RateBucket, error) {
+	if err := validateRate(granularity, last); err != nil {
+		return nil, err
+	}
+
+	buckets := make([]models.RateBucket, 0)
+	err := db.NewSelect().
+		Model((*SessionSchema)(nil)).
+		ColumnExpr("date_trunc(?, s.created_at) AS bucket_start", granularity).
+		ColumnExpr("count(*) AS count").
+		WhereAllWithDeleted().
+		GroupExpr("bucket_start").
+		OrderExpr("bucket_start DESC").
+		Limit(last).
+		Scan(ctx, &buckets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session creation rate: %w", err)
+	}
+
+	return buckets, nil
+}
+
+// GetMessageCreationRate returns the number of messages created per time bucket across the
+// deployment and the sum of their token counts, most recent bucket first. Soft-deleted
+// messages are included.
+func GetMessageCreationRate(
+	ctx context.Context,
+	db *bun.DB,
+	granularity string,
+	last int,
+) ([]models.MessageRateBucket, error) {
+	if err := validateRate(granularity, last); err != nil {
+		return nil, err
+	}
+
+	buckets := make([]models.MessageRateBucket, 0)
+	err := db.NewSelect().
+		Model((*MessageStoreSchema)(nil)).
+		ColumnExpr("date_trunc(?, m.created_at) AS bucket_start", granularity).
+		ColumnExpr("count(*) AS count").
+		ColumnExpr("coalesce(sum(m.token_count), 0) AS tokens").
+		WhereAllWithDeleted().
+		GroupExpr("bucket_start").
+		OrderExpr("bucket_start DESC").
+		Limit(last).
+		Scan(ctx, &buckets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message creation rate: %w", err)
+	}
+
+	return buckets, nil
+}
+
+func validateRate(granularity string, last int) error {
+	if !rateGranularities[granularity] {
+		return models.NewBadRequestError("invalid granularity: " + granularity)
+	}
+	if last < 1 {
+		return models.NewBadRequestError("last must be greater than 0")
+	}
+	return nil
+}
+
+var _ models.StatsStore = &StatsStoreDAO{}
+
+// StatsStoreDAO computes deployment statistics. The config determines which of the
+// statistics apply to the deployment.
+type StatsStoreDAO struct {
+	db  *bun.DB
+	cfg *config.Config
+}
+
+func NewStatsStoreDAO(db *bun.DB, cfg *config.Config) *StatsStoreDAO {
+	return &StatsStoreDAO{
+		db:  db,
+		cfg: cfg,
+	}
+}
+
+func (dao *StatsStoreDAO) GetDeploymentStats(ctx context.Context) (*models.DeploymentStats, error) {
+	stats := &models.DeploymentStats{}
+
+	sessionCount, err := dao.db.NewSelect().Model((*SessionSchema)(nil)).Count(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count sessions: %w", err)
+	}
+	stats.SessionCount = int64(sessionCount)
+
+	err = dao.db.NewSelect().
+		Model((*MessageStoreSchema)(nil)).
+		ColumnExpr("count(*)").
+		ColumnExpr("coalesce(sum(m.token_count), 0)").
+		Scan(ctx, &stats.MessageCount, &stats.TotalTokens)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count messages: %w", err)
+	}
+
+	if dao.cfg.Extractors.Messages.Embeddings.Enabled && dao.cfg.VectorStore.Type == "" {
+		pending, err := dao.countPendingEmbeddings(ctx)
+		if err != nil {
+			return nil, err
+		}
+		stats.EmbeddingsPending = &pending
+	}
+
+	if dao.cfg.Extractors.Messages.Summarizer.Enabled {
+		stale, err := dao.countStaleSummaries(ctx)
+		if err != nil {
+			return nil, err
+		}
+		stats.SummariesStale = &stale
+	}
+
+	return stats, nil
+}
+
+// countPendingEmbeddings counts the messages without an embedding, or whose embedding is
+// stale as their content was updated
+func (dao *StatsStoreDAO) countPendingEmbeddings(ctx context.Context) (int64, error) {
+	count, err := dao.db.NewSelect().
+		Model((*MessageStoreSchema)(nil)).
+		Join("LEFT JOIN message_embedding AS me").
+		JoinOn("me.message_uuid = m.uuid").
+		JoinOn("me.is_embedded").
+		Where("me.uuid IS NULL").
+		Count(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count pending embeddings: %w", err)
+	}
+
+	return int64(count), nil
+}
+
+// countStaleSummaries counts the sessions with more messages after the summary point of their
+// latest summary than the configured message window. Message windows overridden in session
+// metadata are ignored.
+func (dao *StatsStoreDAO) countStaleSummaries(ctx context.Context) (int64, error) {
+	latestSummaries := dao.db.NewSelect().
+		Model((*SummaryStoreSchema)(nil)).
+		DistinctOn("su.session_id").
+		ColumnExpr("su.session_id").
+		ColumnExpr("pm.id AS point_id").
+		Join("JOIN message AS pm").
+		JoinOn("pm.uuid = su.summary_point_uuid").
+		OrderExpr("su.session_id, su.created_at DESC")
+
+	staleSessions := dao.db.NewSelect().
+		Model((*MessageStoreSchema)(nil)).
+		Column("m.session_id").
+		Join("LEFT JOIN (?) AS ls", latestSummaries).
+		JoinOn("ls.session_id = m.session_id").
+		Where("m.id > coalesce(ls.point_id, 0)").
+		Group("m.session_id").
+		Having("count(*) > ?", dao.cfg.Memory.MessageWindow)
+
+	var count int64
+	err := dao.db.NewSelect().
+		TableExpr("(?) AS stale", staleSessions).
+		ColumnExpr("count(*)").
+		Scan(ctx, &count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count stale summaries: %w", err)
+	}
+
+	return count, nil
+}
+
+func (dao *StatsStoreDAO) GetIngestionRate(
+	ctx context.Context,
+	granularity string,
+	last int,
+) (*models.IngestionRate, error) {
+	sessions, err := GetSessionCreationRate(ctx, dao.db, granularity, last)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := GetMessageCreationRate(ctx, dao.db, granularity, last)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.IngestionRate{Sessions: sessions, Messages: messages}, nil
+}
+
+// checkSessionQuota returns a SessionQuotaExceededError if ctx carries a session quota that its
+// tenant has reached. It holds a lock on the tenant's quota until tx ends, so that sessions
+// created concurrently in other transactions can't exceed the quota.
+func checkSessionQuota(ctx context.Context, tx bun.Tx) error {
+	quota, ok := models.SessionQuotaFromContext(ctx)
+	if !ok || quota.Quota <= 0 {
+		return nil
+	}
+
+	if err := acquireAdvisoryXactLock(ctx, tx, "session_quota:"+quota.TenantID); err != nil {
+		return err
+	}
+	count, err := GetActiveSessionCount(ctx, tx, quota.TenantID)
+	if err != nil {
+		return err
+	}
+	if count >= quota.Quota {
+		return models.NewSessionQuotaExceededError(quota)
+	}
+	return nil
+}
+
+// GetActiveSessionCount returns the number of sessions belonging to a tenant that have not been
+// deleted. A tenant's sessions are those of the project with the tenant's ID.
+func GetActiveSessionCount(ctx context.Context, db bun.IDB, tenantID string) (int64, error) {
+	count, err := db.NewSelect().
+		Model((*SessionSchema)(nil)).
+		Where("project_id = ?", tenantID).
+		Count(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get active session count: %w", err)
+	}
+
+	return int64(count), nil
+}
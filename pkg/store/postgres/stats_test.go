@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSessionCreationRate(t *testing.T) {
+	dao := NewSessionDAO(testDB)
+	createTestSessions(t, dao, 3)
+
+	t.Run("returns buckets for valid granularity", func(t *testing.T) {
+		buckets, err := GetSessionCreationRate(testCtx, testDB, "day", 2)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, buckets)
+		assert.LessOrEqual(t, len(buckets), 2)
+		// the current day's bucket contains at least the sessions created above
+		assert.GreaterOrEqual(t, buckets[0].Count, int64(3))
+		for i := 1; i < len(buckets); i++ {
+			assert.True(t, buckets[i-1].BucketStart.After(buckets[i].BucketStart))
+		}
+	})
+
+	t.Run("rejects invalid granularity", func(t *testing.T) {
+		_, err := GetSessionCreationRate(testCtx, testDB, "hour'); DROP TABLE session; --", 1)
+		assert.ErrorIs(t, err, models.ErrBadRequest)
+	})
+
+	t.Run("rejects non-positive last", func(t *testing.T) {
+		_, err := GetSessionCreationRate(testCtx, testDB, "day", 0)
+		assert.ErrorIs(t, err, models.ErrBadRequest)
+	})
+}
+
+func TestStatsStoreDAO_GetDeploymentStats(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Memory.MessageWindow = 2
+	cfg.Extractors.Messages.Embeddings.Enabled = true
+	cfg.Extractors.Messages.Summarizer.Enabled = true
+	dao := NewStatsStoreDAO(testDB, cfg)
+
+	before, err := dao.GetDeploymentStats(testCtx)
+	require.NoError(t, err)
+	require.NotNil(t, before.EmbeddingsPending)
+	require.NotNil(t, before.SummariesStale)
+
+	sessionID := createSession(t)
+	msgs, err := putMessages(testCtx, testDB, sessionID, []models.Message{
+		{Role: "user", Content: "Hello", TokenCount: 2},
+		{Role: "assistant", Content: "Hi there!", TokenCount: 4},
+		{Role: "user", Content: "How are you?", TokenCount: 5},
+	})
+	require.NoError(t, err)
+
+	after, err := dao.GetDeploymentStats(testCtx)
+	require.NoError(t, err)
+	assert.Equal(t, before.SessionCount+1, after.SessionCount)
+	assert.Equal(t, before.MessageCount+3, after.MessageCount)
+	assert.Equal(t, before.TotalTokens+11, after.TotalTokens)
+	assert.Equal(t, *before.EmbeddingsPending+3, *after.EmbeddingsPending)
+	assert.Equal(t, *before.SummariesStale+1, *after.SummariesStale)
+
+	// the session's summary is up to date once its messages are summarized
+	_, err = putSummary(testCtx, testDB, sessionID, &models.Summary{
+		Content:          "a greeting",
+		SummaryPointUUID: msgs[2].UUID,
+	})
+	require.NoError(t, err)
+	summarized, err := dao.GetDeploymentStats(testCtx)
+	require.NoError(t, err)
+	assert.Equal(t, *before.SummariesStale, *summarized.SummariesStale)
+
+	t.Run("omits statistics of disabled extractors", func(t *testing.T) {
+		stats, err := NewStatsStoreDAO(testDB, &config.Config{}).GetDeploymentStats(testCtx)
+		require.NoError(t, err)
+		assert.Nil(t, stats.EmbeddingsPending)
+		assert.Nil(t, stats.SummariesStale)
+	})
+}
+
+func TestStatsStoreDAO_GetIngestionRate(t *testing.T) {
+	dao := NewStatsStoreDAO(testDB, &config.Config{})
+	sessionID := createSession(t)
+	_, err := putMessages(testCtx, testDB, sessionID, []models.Message{
+		{Role: "user", Content: "Hello", TokenCount: 2},
+	})
+	require.NoError(t, err)
+
+	rate, err := dao.GetIngestionRate(testCtx, "day", 1)
+	require.NoError(t, err)
+	require.Len(t, rate.Sessions, 1)
+	require.Len(t, rate.Messages, 1)
+	assert.GreaterOrEqual(t, rate.Sessions[0].Count, int64(1))
+	assert.GreaterOrEqual(t, rate.Messages[0].Count, int64(1))
+	assert.GreaterOrEqual(t, rate.Messages[0].Tokens, int64(2))
+
+	_, err = dao.GetIngestionRate(testCtx, "week", 1)
+	assert.ErrorIs(t, err, models.ErrBadRequest)
+}
@@ -2,7 +2,9 @@ package postgres
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"errors"
 	"fmt"
 
@@ -15,8 +17,24 @@ import (
 	"github.com/uptrace/bun"
 )
 
-// putSummary stores a new summary for a session. The recentMessageID is the UUID of the most recent
-// message in the session when the summary was created.
+// summaryNamespaceUUID is the namespace used to derive deterministic summary UUIDs.
+var summaryNamespaceUUID = uuid.MustParse("6f1e7c4a-3b8d-4f52-9a0e-2d7c5b1e8f34")
+
+// generateSummaryUUID returns a deterministic UUID for a summary derived from the session ID
+// and a hash of the summary content. Storing the same summary twice results in the same UUID.
+func generateSummaryUUID(sessionID, content string) uuid.UUID {
+	contentHash := sha256.Sum256([]byte(content))
+	return uuid.NewSHA1(
+		summaryNamespaceUUID,
+		[]byte(sessionID+hex.EncodeToString(contentHash[:])),
+	)
+}
+
+// putSummary stores a new summary for a session. If the summary has no UUID, a deterministic
+// UUID is generated from the session ID and content. Storing a summary with the same content
+// again doesn't add a summary: the existing summary's summary point is moved to the new
+// summary's, so that the session's summary point advances, and the existing summary is
+// returned.
 func putSummary(
 	ctx context.Context,
 	db *bun.DB,
@@ -34,12 +52,99 @@ func putSummary(
 	}
 
 	pgSummary.SessionID = sessionID
+	if pgSummary.UUID == uuid.Nil {
+		pgSummary.UUID = generateSummaryUUID(sessionID, summary.Content)
+	}
+
+	// the existing summary is returned if there's a conflict
+	_, err = db.NewInsert().
+		Model(&pgSummary).
+		On("CONFLICT (uuid) DO UPDATE").
+		Set("summary_point_uuid = EXCLUDED.summary_point_uuid").
+		Returning("*").
+		Exec(ctx)
+	if err != nil {
+		return nil, store.NewStorageError("failed to Create summary", err)
+	}
+
+	retSummary := models.Summary{}
+	err = copier.Copy(&retSummary, &pgSummary)
+	if err != nil {
+		return nil, store.NewStorageError("failed to copy summary", err)
+	}
+
+	return &retSummary, nil
+}
+
+// replaceSummaries stores a new summary for a session and hard deletes the session's existing
+// summaries, their embeddings and the session's abstract, which it replaces. Summaries are hard deleted as the new summary
+// may share a SummaryPoint with one of them.
+func replaceSummaries(
+	ctx context.Context,
+	db *bun.DB,
+	sessionID string,
+	summary *models.Summary,
+) (*models.Summary, error) {
+	if sessionID == "" {
+		return nil, store.NewStorageError("sessionID cannot be empty", nil)
+	}
+
+	pgSummary := SummaryStoreSchema{}
+	err := copier.Copy(&pgSummary, summary)
+	if err != nil {
+		return nil, store.NewStorageError("failed to copy summary", err)
+	}
+	pgSummary.SessionID = sessionID
+	if pgSummary.UUID == uuid.Nil {
+		pgSummary.UUID = generateSummaryUUID(sessionID, summary.Content)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, store.NewStorageError("failed to start transaction", err)
+	}
+	defer rollbackOnError(tx)
 
-	_, err = db.NewInsert().Model(&pgSummary).Exec(ctx)
+	_, err = tx.NewDelete().
+		Model((*SummaryVectorStoreSchema)(nil)).
+		Where("session_id = ?", sessionID).
+		WhereAllWithDeleted().
+		ForceDelete().
+		Exec(ctx)
+	if err != nil {
+		return nil, store.NewStorageError("failed to delete summary embeddings", err)
+	}
+
+	_, err = tx.NewDelete().
+		Model((*SummaryAbstractSchema)(nil)).
+		Where("session_id = ?", sessionID).
+		WhereAllWithDeleted().
+		ForceDelete().
+		Exec(ctx)
+	if err != nil {
+		return nil, store.NewStorageError("failed to delete summary abstract", err)
+	}
+
+	_, err = tx.NewDelete().
+		Model((*SummaryStoreSchema)(nil)).
+		Where("session_id = ?", sessionID).
+		WhereAllWithDeleted().
+		ForceDelete().
+		Exec(ctx)
+	if err != nil {
+		return nil, store.NewStorageError("failed to delete summaries", err)
+	}
+
+	_, err = tx.NewInsert().Model(&pgSummary).Returning("*").Exec(ctx)
 	if err != nil {
 		return nil, store.NewStorageError("failed to Create summary", err)
 	}
 
+	err = tx.Commit()
+	if err != nil {
+		return nil, store.NewStorageError("failed to commit transaction", err)
+	}
+
 	retSummary := models.Summary{}
 	err = copier.Copy(&retSummary, &pgSummary)
 	if err != nil {
@@ -224,14 +329,14 @@ func getSummaryList(ctx context.Context,
 	}
 
 	summariesDB := make([]SummaryStoreSchema, 0)
-	err := db.NewSelect().
+	count, err := db.NewSelect().
 		Model(&summariesDB).
 		Where("session_id = ?", sessionID).
 		Where("deleted_at IS NULL").
 		Order("created_at ASC").
 		Offset((currentPage - 1) * pageSize).
 		Limit(pageSize).
-		Scan(ctx)
+		ScanAndCount(ctx)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -252,8 +357,9 @@ func getSummaryList(ctx context.Context,
 	}
 
 	respSummary := models.SummaryListResponse{
-		Summaries: summaries,
-		RowCount:  len(summaries),
+		Summaries:  summaries,
+		TotalCount: count,
+		RowCount:   len(summaries),
 	}
 
 	return &respSummary, nil
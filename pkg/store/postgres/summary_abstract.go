@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/store"
+	"github.com/uptrace/bun"
+)
+
+// getSummaryAbstract returns the abstract of a session's summaries, or nil if there isn't one
+func getSummaryAbstract(
+	ctx context.Context,
+	db *bun.DB,
+	sessionID string,
+) (*models.SummaryAbstract, error) {
+	abstract := SummaryAbstractSchema{}
+	err := db.NewSelect().
+		Model(&abstract).
+		Where("session_id = ?", sessionID).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, store.NewStorageError("failed to get summary abstract", err)
+	}
+
+	return &models.SummaryAbstract{
+		UUID:            abstract.UUID,
+		CreatedAt:       abstract.CreatedAt,
+		UpdatedAt:       abstract.UpdatedAt,
+		Content:         abstract.Content,
+		LastSummaryUUID: abstract.LastSummaryUUID,
+		TokenCount:      abstract.TokenCount,
+	}, nil
+}
+
+// putSummaryAbstract stores the abstract of a session's summaries, replacing any existing
+// abstract
+func putSummaryAbstract(
+	ctx context.Context,
+	db *bun.DB,
+	sessionID string,
+	abstract *models.SummaryAbstract,
+) (*models.SummaryAbstract, error) {
+	if sessionID == "" {
+		return nil, store.NewStorageError("sessionID cannot be empty", nil)
+	}
+
+	pgAbstract := SummaryAbstractSchema{
+		SessionID:       sessionID,
+		Content:         abstract.Content,
+		LastSummaryUUID: abstract.LastSummaryUUID,
+		TokenCount:      abstract.TokenCount,
+	}
+	_, err := db.NewInsert().
+		Model(&pgAbstract).
+		On("CONFLICT (session_id) DO UPDATE").
+		Set("content = EXCLUDED.content").
+		Set("last_summary_uuid = EXCLUDED.last_summary_uuid").
+		Set("token_count = EXCLUDED.token_count").
+		Set("updated_at = current_timestamp").
+		Set("deleted_at = NULL").
+		Returning("*").
+		Exec(ctx)
+	if err != nil {
+		return nil, store.NewStorageError("failed to put summary abstract", err)
+	}
+
+	return &models.SummaryAbstract{
+		UUID:            pgAbstract.UUID,
+		CreatedAt:       pgAbstract.CreatedAt,
+		UpdatedAt:       pgAbstract.UpdatedAt,
+		Content:         pgAbstract.Content,
+		LastSummaryUUID: pgAbstract.LastSummaryUUID,
+		TokenCount:      pgAbstract.TokenCount,
+	}, nil
+}
+
+// getRecentSummaries returns a session's limit most recent summaries, oldest first
+func getRecentSummaries(
+	ctx context.Context,
+	db *bun.DB,
+	sessionID string,
+	limit int,
+) ([]models.Summary, error) {
+	var summariesDB []SummaryStoreSchema
+	err := db.NewSelect().
+		Model(&summariesDB).
+		Where("session_id = ?", sessionID).
+		Order("created_at DESC").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, store.NewStorageError("failed to get recent summaries", err)
+	}
+
+	summaries := make([]models.Summary, len(summariesDB))
+	for i, summary := range summariesDB {
+		summaries[len(summariesDB)-1-i] = models.Summary{
+			UUID:             summary.UUID,
+			CreatedAt:        summary.CreatedAt,
+			Content:          summary.Content,
+			SummaryPointUUID: summary.SummaryPointUUID,
+			Metadata:         summary.Metadata,
+			TokenCount:       summary.TokenCount,
+		}
+	}
+
+	return summaries, nil
+}
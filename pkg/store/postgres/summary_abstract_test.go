@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+)
+
+func TestSummaryAbstract(t *testing.T) {
+	sessionID := createSession(t)
+
+	abstract, err := getSummaryAbstract(testCtx, testDB, sessionID)
+	require.NoError(t, err)
+	assert.Nil(t, abstract)
+
+	resultMessages, err := putMessages(testCtx, testDB, sessionID, []models.Message{
+		{Role: "user", Content: "Hello"},
+		{Role: "assistant", Content: "Hi there!"},
+	})
+	require.NoError(t, err)
+	var summaries []*models.Summary
+	for i, content := range []string{"First summary", "Second summary"} {
+		summary, err := putSummary(testCtx, testDB, sessionID, &models.Summary{
+			Content:          content,
+			SummaryPointUUID: resultMessages[i].UUID,
+		})
+		require.NoError(t, err)
+		summaries = append(summaries, summary)
+	}
+
+	first, err := putSummaryAbstract(testCtx, testDB, sessionID, &models.SummaryAbstract{
+		Content:         "First abstract",
+		LastSummaryUUID: summaries[0].UUID,
+		TokenCount:      2,
+	})
+	require.NoError(t, err)
+
+	// putting an abstract replaces the session's existing abstract
+	second, err := putSummaryAbstract(testCtx, testDB, sessionID, &models.SummaryAbstract{
+		Content:         "Second abstract",
+		LastSummaryUUID: summaries[1].UUID,
+		TokenCount:      3,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, first.UUID, second.UUID)
+
+	abstract, err = getSummaryAbstract(testCtx, testDB, sessionID)
+	require.NoError(t, err)
+	require.NotNil(t, abstract)
+	assert.Equal(t, "Second abstract", abstract.Content)
+	assert.Equal(t, summaries[1].UUID, abstract.LastSummaryUUID)
+	assert.Equal(t, 3, abstract.TokenCount)
+
+	recent, err := getRecentSummaries(testCtx, testDB, sessionID, 1)
+	require.NoError(t, err)
+	require.Len(t, recent, 1)
+	assert.Equal(t, summaries[1].UUID, recent[0].UUID)
+
+	_, err = putSummaryAbstract(testCtx, testDB, "", &models.SummaryAbstract{})
+	assert.ErrorContains(t, err, "sessionID cannot be empty")
+}
+
+func TestGetMemory_Hierarchical(t *testing.T) {
+	summarizerConfig := &appState.Config.Extractors.Messages.Summarizer
+	summarizerConfig.Hierarchical = config.HierarchicalSummaryConfig{
+		Enabled:         true,
+		WindowSummaries: 2,
+	}
+	defer func() { summarizerConfig.Hierarchical = config.HierarchicalSummaryConfig{} }()
+
+	memoryStore, err := NewPostgresMemoryStore(appState, testDB)
+	require.NoError(t, err)
+
+	sessionID := createSession(t)
+	resultMessages, err := putMessages(testCtx, testDB, sessionID, []models.Message{
+		{Role: "user", Content: "Hello"},
+		{Role: "assistant", Content: "Hi there!"},
+		{Role: "user", Content: "How are you?"},
+		{Role: "assistant", Content: "Good, thanks."},
+	})
+	require.NoError(t, err)
+	var summaries []*models.Summary
+	for i, content := range []string{"First summary", "Second summary", "Third summary"} {
+		summary, err := putSummary(testCtx, testDB, sessionID, &models.Summary{
+			Content:          content,
+			SummaryPointUUID: resultMessages[i].UUID,
+		})
+		require.NoError(t, err)
+		summaries = append(summaries, summary)
+	}
+	_, err = putSummaryAbstract(testCtx, testDB, sessionID, &models.SummaryAbstract{
+		Content:         "Abstract",
+		LastSummaryUUID: summaries[0].UUID,
+	})
+	require.NoError(t, err)
+
+	memory, err := memoryStore.GetMemory(testCtx, appState, sessionID, 0, nil)
+	require.NoError(t, err)
+	require.NotNil(t, memory.Abstract)
+	assert.Equal(t, "Abstract", memory.Abstract.Content)
+	require.Len(t, memory.WindowSummaries, 2)
+	assert.Equal(t, summaries[1].UUID, memory.WindowSummaries[0].UUID)
+	assert.Equal(t, summaries[2].UUID, memory.WindowSummaries[1].UUID)
+	assert.Equal(t, summaries[2].UUID, memory.Summary.UUID)
+	require.Len(t, memory.Messages, 1)
+	assert.Equal(t, resultMessages[3].UUID, memory.Messages[0].UUID)
+}
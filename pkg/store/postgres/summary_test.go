@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/getzep/zep/pkg/models"
@@ -95,6 +96,126 @@ func TestPutSummary(t *testing.T) {
 	}
 }
 
+func TestPutSummaryIsIdempotent(t *testing.T) {
+	sessionID := createSession(t)
+
+	resultMessages, err := putMessages(testCtx, testDB, sessionID, []models.Message{
+		{Role: "user", Content: "Hello"},
+		{Role: "assistant", Content: "Hi there!"},
+	})
+	assert.NoError(t, err, "putMessages should not return an error")
+
+	content := "Test content"
+	assert.Equal(
+		t,
+		generateSummaryUUID(sessionID, content),
+		generateSummaryUUID(sessionID, content),
+	)
+	assert.NotEqual(
+		t,
+		generateSummaryUUID(sessionID, content),
+		generateSummaryUUID(sessionID, content+"!"),
+	)
+
+	summary := models.Summary{
+		Content:          content,
+		SummaryPointUUID: resultMessages[0].UUID,
+	}
+	first, err := putSummary(testCtx, testDB, sessionID, &summary)
+	assert.NoError(t, err)
+	assert.Equal(t, generateSummaryUUID(sessionID, content), first.UUID)
+
+	second, err := putSummary(testCtx, testDB, sessionID, &summary)
+	assert.NoError(t, err)
+	assert.Equal(t, first.UUID, second.UUID)
+	assert.Equal(t, first.CreatedAt.UTC(), second.CreatedAt.UTC())
+
+	// a summary with the same content and a newer summary point advances the summary point
+	newer, err := putSummary(testCtx, testDB, sessionID, &models.Summary{
+		Content:          content,
+		SummaryPointUUID: resultMessages[1].UUID,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, first.UUID, newer.UUID)
+	assert.Equal(t, resultMessages[1].UUID, newer.SummaryPointUUID)
+	latest, err := getSummary(testCtx, testDB, sessionID)
+	assert.NoError(t, err)
+	assert.Equal(t, resultMessages[1].UUID, latest.SummaryPointUUID)
+
+	count, err := testDB.NewSelect().
+		Model((*SummaryStoreSchema)(nil)).
+		Where("session_id = ?", sessionID).
+		Count(testCtx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	// an explicit UUID is used as-is
+	explicitUUID := uuid.New()
+	explicit, err := putSummary(testCtx, testDB, sessionID, &models.Summary{
+		UUID:             explicitUUID,
+		Content:          content,
+		SummaryPointUUID: resultMessages[1].UUID,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, explicitUUID, explicit.UUID)
+}
+
+func TestReplaceSummaries(t *testing.T) {
+	sessionID := createSession(t)
+
+	resultMessages, err := putMessages(testCtx, testDB, sessionID, []models.Message{
+		{Role: "user", Content: "Hello"},
+		{Role: "assistant", Content: "Hi there!"},
+		{Role: "user", Content: "How are you?"},
+	})
+	assert.NoError(t, err, "putMessages should not return an error")
+
+	for i, content := range []string{"First summary", "Second summary"} {
+		summary, err := putSummary(testCtx, testDB, sessionID, &models.Summary{
+			Content:          content,
+			SummaryPointUUID: resultMessages[i].UUID,
+		})
+		assert.NoError(t, err)
+
+		err = putSummaryEmbedding(testCtx, testDB, sessionID, &models.TextData{
+			Embedding: make(
+				[]float32,
+				appState.Config.Extractors.Messages.Summarizer.Embeddings.Dimensions,
+			),
+			TextUUID: summary.UUID,
+		})
+		assert.NoError(t, err)
+	}
+
+	// the new summary shares a SummaryPoint with the second summary
+	replacement, err := replaceSummaries(testCtx, testDB, sessionID, &models.Summary{
+		Content:          "Regenerated summary",
+		SummaryPointUUID: resultMessages[1].UUID,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, generateSummaryUUID(sessionID, "Regenerated summary"), replacement.UUID)
+
+	summary, err := getSummary(testCtx, testDB, sessionID)
+	assert.NoError(t, err)
+	assert.Equal(t, replacement.UUID, summary.UUID)
+	assert.Equal(t, "Regenerated summary", summary.Content)
+
+	count, err := testDB.NewSelect().
+		Model((*SummaryStoreSchema)(nil)).
+		Where("session_id = ?", sessionID).
+		WhereAllWithDeleted().
+		Count(testCtx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	embeddings, err := getSummaryEmbeddings(testCtx, testDB, sessionID)
+	assert.NoError(t, err)
+	assert.Empty(t, embeddings)
+
+	_, err = replaceSummaries(testCtx, testDB, "", &models.Summary{Content: "Summary"})
+	assert.ErrorContains(t, err, "sessionID cannot be empty")
+}
+
 func TestGetSummary(t *testing.T) {
 	sessionID, err := testutils.GenerateRandomSessionID(16)
 	assert.NoError(t, err, "GenerateRandomSessionID should not return an error")
@@ -330,7 +451,7 @@ func TestGetSummaryList(t *testing.T) {
 	// Add test summaries
 	for i := 0; i < 9; i++ {
 		summary := models.Summary{
-			Content: "Test content",
+			Content: fmt.Sprintf("Test content %d", i),
 			Metadata: map[string]interface{}{
 				"key": "value",
 			},
@@ -347,6 +468,7 @@ func TestGetSummaryList(t *testing.T) {
 		pageNumber    int
 		pageSize      int
 		expectedCount int
+		expectedTotal int
 	}{
 		{
 			name:          "Existing session",
@@ -354,6 +476,7 @@ func TestGetSummaryList(t *testing.T) {
 			pageNumber:    1,
 			pageSize:      5,
 			expectedCount: 5,
+			expectedTotal: 9,
 		},
 		{
 			name:          "Existing session page 2",
@@ -361,6 +484,7 @@ func TestGetSummaryList(t *testing.T) {
 			pageNumber:    2,
 			pageSize:      5,
 			expectedCount: 4,
+			expectedTotal: 9,
 		},
 		{
 			name:          "Non-existent session",
@@ -384,6 +508,7 @@ func TestGetSummaryList(t *testing.T) {
 
 			// Check the number of summaries returned
 			assert.Equal(t, tt.expectedCount, len(summaries.Summaries))
+			assert.Equal(t, tt.expectedTotal, summaries.TotalCount)
 		})
 	}
 }
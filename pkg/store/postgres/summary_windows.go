@@ -0,0 +1,190 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/store"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// defaultSummaryWindowConcurrency is the number of message windows fetched concurrently if
+// no concurrency is given
+const defaultSummaryWindowConcurrency = 4
+
+// summaryLink is a summary in a session's summary chain, along with the message ID of its
+// SummaryPoint.
+type summaryLink struct {
+	summary        models.Summary
+	summaryPointID int64
+}
+
+// GetAllSummaryWindows returns each of a session's summaries along with the messages it
+// covers, in chronological order. Summaries whose SummaryPoint message no longer exists are
+// omitted, as the messages they cover can't be determined. At most concurrency message windows
+// are fetched at a time, or defaultSummaryWindowConcurrency if concurrency is 0 or less.
+func GetAllSummaryWindows(
+	ctx context.Context,
+	db *bun.DB,
+	sessionID string,
+	concurrency int,
+) ([]models.SummaryWindow, error) {
+	if concurrency <= 0 {
+		concurrency = defaultSummaryWindowConcurrency
+	}
+
+	chain, err := getSummaryChain(ctx, db, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	windows := make([]models.SummaryWindow, len(chain))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+
+	for i := range chain {
+		var afterID int64
+		if i > 0 {
+			afterID = chain[i-1].summaryPointID
+		}
+		windows[i].Summary = chain[i].summary
+
+		wg.Add(1)
+		go func(i int, afterID int64) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			messages, err := getMessagesBetween(
+				ctx,
+				db,
+				sessionID,
+				afterID,
+				chain[i].summaryPointID,
+			)
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+				return
+			}
+			windows[i].Messages = messages
+		}(i, afterID)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return windows, nil
+}
+
+// getSummaryChain returns a session's summaries ordered by their SummaryPoint.
+func getSummaryChain(ctx context.Context, db *bun.DB, sessionID string) ([]summaryLink, error) {
+	if sessionID == "" {
+		return nil, store.NewStorageError("sessionID cannot be empty", nil)
+	}
+
+	var summaries []SummaryStoreSchema
+	err := db.NewSelect().
+		Model(&summaries).
+		Where("session_id = ?", sessionID).
+		Order("created_at ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, store.NewStorageError("failed to get summaries", err)
+	}
+	if len(summaries) == 0 {
+		return nil, nil
+	}
+
+	pointUUIDs := make([]uuid.UUID, len(summaries))
+	for i := range summaries {
+		pointUUIDs[i] = summaries[i].SummaryPointUUID
+	}
+	var points []MessageStoreSchema
+	err = db.NewSelect().
+		Model(&points).
+		Column("uuid", "id").
+		Where("session_id = ?", sessionID).
+		Where("uuid IN (?)", bun.In(pointUUIDs)).
+		Scan(ctx)
+	if err != nil {
+		return nil, store.NewStorageError("failed to get summary points", err)
+	}
+	pointIDs := make(map[uuid.UUID]int64, len(points))
+	for _, p := range points {
+		pointIDs[p.UUID] = p.ID
+	}
+
+	chain := make([]summaryLink, 0, len(summaries))
+	for _, s := range summaries {
+		id, ok := pointIDs[s.SummaryPointUUID]
+		if !ok {
+			continue
+		}
+		chain = append(chain, summaryLink{
+			summary: models.Summary{
+				UUID:             s.UUID,
+				CreatedAt:        s.CreatedAt,
+				Content:          s.Content,
+				SummaryPointUUID: s.SummaryPointUUID,
+				Metadata:         s.Metadata,
+				TokenCount:       s.TokenCount,
+			},
+			summaryPointID: id,
+		})
+	}
+	sort.SliceStable(chain, func(i, j int) bool {
+		return chain[i].summaryPointID < chain[j].summaryPointID
+	})
+
+	return chain, nil
+}
+
+// getMessagesBetween returns a session's messages with IDs greater than afterID and up to
+// and including throughID, ordered by ID.
+func getMessagesBetween(
+	ctx context.Context,
+	db *bun.DB,
+	sessionID string,
+	afterID int64,
+	throughID int64,
+) ([]models.Message, error) {
+	var messages []MessageStoreSchema
+	err := db.NewSelect().
+		Model(&messages).
+		Where("session_id = ?", sessionID).
+		Where("id > ?", afterID).
+		Where("id <= ?", throughID).
+		Order("id ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get messages between %d and %d: %w", afterID, throughID, err)
+	}
+
+	messageList := make([]models.Message, len(messages))
+	for i, msg := range messages {
+		messageList[i] = models.Message{
+			UUID:       msg.UUID,
+			CreatedAt:  msg.CreatedAt,
+			UpdatedAt:  msg.UpdatedAt,
+			Role:       msg.Role,
+			Content:    msg.Content,
+			TokenCount: msg.TokenCount,
+			Metadata:   msg.Metadata,
+			IsSystem:   msg.IsSystem,
+		}
+	}
+
+	return messageList, nil
+}
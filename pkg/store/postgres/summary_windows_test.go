@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAllSummaryWindows(t *testing.T) {
+	sessionID := createSession(t)
+
+	messages := make([]models.Message, 9)
+	for i := range messages {
+		messages[i] = models.Message{
+			Role:    testutils.TestMessages[i].Role,
+			Content: testutils.TestMessages[i].Content,
+		}
+	}
+	msgs, err := putMessages(testCtx, testDB, sessionID, messages)
+	require.NoError(t, err)
+
+	// summaries covering messages [0, 3], (3, 5] and (5, 8]
+	for i, point := range []int{3, 5, 8} {
+		_, err := putSummary(testCtx, testDB, sessionID, &models.Summary{
+			Content:          fmt.Sprintf("summary %d", i),
+			SummaryPointUUID: msgs[point].UUID,
+		})
+		require.NoError(t, err)
+	}
+
+	for _, concurrency := range []int{0, 1, 3} {
+		windows, err := GetAllSummaryWindows(testCtx, testDB, sessionID, concurrency)
+		require.NoError(t, err)
+		require.Len(t, windows, 3)
+
+		expected := [][]int{{0, 1, 2, 3}, {4, 5}, {6, 7, 8}}
+		for i, window := range windows {
+			assert.Equal(t, fmt.Sprintf("summary %d", i), window.Summary.Content)
+			require.Len(t, window.Messages, len(expected[i]))
+			for j, idx := range expected[i] {
+				assert.Equal(t, msgs[idx].UUID, window.Messages[j].UUID)
+			}
+		}
+	}
+
+	t.Run("session without summaries", func(t *testing.T) {
+		windows, err := GetAllSummaryWindows(testCtx, testDB, createSession(t), 0)
+		require.NoError(t, err)
+		assert.Empty(t, windows)
+	})
+}
@@ -29,6 +29,11 @@ func CleanDB(t *testing.T, db *bun.DB) {
 		IfExists().
 		Exec(context.Background())
 	require.NoError(t, err)
+	_, err = db.NewDropTable().
+		Model((*messageUUIDSchema)(nil)).
+		IfExists().
+		Exec(context.Background())
+	require.NoError(t, err)
 	_, err = db.NewDropTable().
 		Model(&MessageVectorStoreSchema{}).
 		IfExists().
@@ -0,0 +1,33 @@
+package postgres
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const OtelStoreTracerName = "store"
+
+var tracer = otel.Tracer(OtelStoreTracerName)
+
+// startSpan starts a span of a memory store operation on a session. The queries of the
+// operation are traced as its children by bunotel.
+func startSpan(ctx context.Context, operation, sessionID string) (context.Context, trace.Span) {
+	return tracer.Start(
+		ctx,
+		"store."+operation,
+		trace.WithAttributes(attribute.String("zep.session_id", sessionID)),
+	)
+}
+
+// endSpan records err, if any, on span and ends it
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/driver/pgdriver"
+)
+
+const (
+	// txMaxAttempts is the number of times a transaction is run if it fails to serialize
+	txMaxAttempts = 3
+	// txRetryBackoff is the wait before the first retry of a transaction. Each further retry
+	// waits twice as long as the one before.
+	txRetryBackoff = 20 * time.Millisecond
+)
+
+// runInTxWithRetry runs fn in a serializable transaction, and runs it again in a new
+// transaction if the transaction fails with a serialization failure or deadlock. These are
+// expected of concurrent writes to a session, such as concurrent upserts of the same
+// messages, or concurrent creates of sessions counted against the same quota. fn must not
+// have side effects outside the transaction, as it may be run more than once.
+func runInTxWithRetry(
+	ctx context.Context,
+	db bun.IDB,
+	fn func(ctx context.Context, tx bun.Tx) error,
+) error {
+	backoff := txRetryBackoff
+	for attempt := 1; ; attempt++ {
+		err := db.RunInTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable}, fn)
+		if err == nil || attempt == txMaxAttempts || !isRetryableTxError(err) {
+			return err
+		}
+
+		log.Debugf("retrying transaction after attempt %d: %v", attempt, err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+// isRetryableTxError returns whether err is a serialization failure or deadlock, after which
+// a transaction may succeed if it's run again
+func isRetryableTxError(err error) bool {
+	var pgErr pgdriver.Error
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	switch pgErr.Field('C') {
+	case "40001", "40P01":
+		return true
+	default:
+		return false
+	}
+}
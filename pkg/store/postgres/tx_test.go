@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+func TestRunInTxWithRetry(t *testing.T) {
+	raise := func(ctx context.Context, tx bun.Tx, sqlState string) error {
+		_, err := tx.ExecContext(
+			ctx,
+			"DO $$ BEGIN RAISE EXCEPTION 'failed' USING ERRCODE = '"+sqlState+"'; END $$",
+		)
+		return err
+	}
+
+	t.Run("retries serialization failures", func(t *testing.T) {
+		var attempts int
+		err := runInTxWithRetry(testCtx, testDB, func(ctx context.Context, tx bun.Tx) error {
+			attempts++
+			if attempts == 1 {
+				return raise(ctx, tx, "40001")
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, attempts)
+	})
+
+	t.Run("retries concurrent updates", func(t *testing.T) {
+		sessionID := createSession(t)
+		var attempts int
+		err := runInTxWithRetry(testCtx, testDB, func(ctx context.Context, tx bun.Tx) error {
+			attempts++
+			var session SessionSchema
+			err := tx.NewSelect().
+				Model(&session).
+				Where("session_id = ?", sessionID).
+				Scan(ctx)
+			if err != nil {
+				return err
+			}
+			if attempts == 1 {
+				// the session is updated after the transaction's snapshot is taken
+				_, err := testDB.NewUpdate().
+					Model((*SessionSchema)(nil)).
+					Set("metadata = ?", `{"writer": "concurrent"}`).
+					Where("session_id = ?", sessionID).
+					Exec(ctx)
+				if err != nil {
+					return err
+				}
+			}
+			_, err = tx.NewUpdate().
+				Model((*SessionSchema)(nil)).
+				Set("metadata = ?", `{"writer": "retried"}`).
+				Where("session_id = ?", sessionID).
+				Exec(ctx)
+			return err
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, attempts)
+
+		session, err := NewSessionDAO(testDB).Get(testCtx, sessionID)
+		require.NoError(t, err)
+		assert.Equal(t, "retried", session.Metadata["writer"])
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		var attempts int
+		err := runInTxWithRetry(testCtx, testDB, func(ctx context.Context, tx bun.Tx) error {
+			attempts++
+			return raise(ctx, tx, "40P01")
+		})
+		assert.True(t, isRetryableTxError(err))
+		assert.Equal(t, txMaxAttempts, attempts)
+	})
+
+	t.Run("doesn't retry other errors", func(t *testing.T) {
+		var attempts int
+		err := runInTxWithRetry(testCtx, testDB, func(ctx context.Context, tx bun.Tx) error {
+			attempts++
+			return raise(ctx, tx, "23505")
+		})
+		assert.Error(t, err)
+		assert.False(t, isRetryableTxError(err))
+		assert.Equal(t, 1, attempts)
+	})
+}
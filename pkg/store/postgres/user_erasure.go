@@ -0,0 +1,210 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/uptrace/bun"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/store"
+)
+
+// EraseUserData erases a user's data for a right-to-erasure request. Each of the user's
+// sessions, including deleted sessions, is securely deleted as by SecureDeleteSession, along
+// with the dead letters of its tasks, in a single transaction. The user's facts, and the
+// entities no longer mentioned in any session, are deleted, and the user is then hard deleted.
+// The session embeddings held by a VectorIndex are deleted once the transaction is committed.
+// Archived sessions are rehydrated first, so that their records are counted, and their
+// archives deleted.
+func (pms *PostgresMemoryStore) EraseUserData(
+	ctx context.Context,
+	userID string,
+) (*models.UserDataErasureReport, error) {
+	if err := checkUserProject(ctx, pms.Client, userID); err != nil {
+		return nil, err
+	}
+
+	var archiveKeys []string
+	if pms.ArchiveStore != nil {
+		var err error
+		archiveKeys, err = sessionArchiveKeys(ctx, pms.Client, "user_id = ?", userID)
+		if err != nil {
+			return nil, store.NewStorageError("failed to get session archives", err)
+		}
+		var sessionIDs []string
+		err = pms.Client.NewSelect().
+			Model((*SessionSchema)(nil)).
+			Column("session_id").
+			Where("user_id = ?", userID).
+			Where("archived_at IS NOT NULL").
+			Scan(ctx, &sessionIDs)
+		if err != nil {
+			return nil, store.NewStorageError("failed to get archived sessions", err)
+		}
+		for _, sessionID := range sessionIDs {
+			if err := pms.rehydrateSession(ctx, sessionID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	tx, err := pms.Client.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, store.NewStorageError("failed to begin transaction", err)
+	}
+	defer rollbackOnError(tx)
+
+	exists, err := tx.NewSelect().
+		Model((*UserSchema)(nil)).
+		WhereAllWithDeleted().
+		Where("user_id = ?", userID).
+		Exists(ctx)
+	if err != nil {
+		return nil, store.NewStorageError("failed to get user", err)
+	}
+	if !exists {
+		return nil, models.NewNotFoundError("user " + userID)
+	}
+
+	var sessionIDs []string
+	err = tx.NewSelect().
+		Model((*SessionSchema)(nil)).
+		Column("session_id").
+		WhereAllWithDeleted().
+		Where("user_id = ?", userID).
+		Order("id ASC").
+		Scan(ctx, &sessionIDs)
+	if err != nil {
+		return nil, store.NewStorageError("failed to get user sessions", err)
+	}
+
+	report := &models.UserDataErasureReport{
+		UserID:   userID,
+		Sessions: make([]models.SessionErasureReport, 0, len(sessionIDs)),
+	}
+	for _, sessionID := range sessionIDs {
+		sessionReport, err := countSessionRecords(ctx, tx, sessionID)
+		if err != nil {
+			return nil, store.NewStorageError("failed to count session records", err)
+		}
+		if err := secureDeleteSession(ctx, tx, sessionID); err != nil {
+			return nil, store.NewStorageError("failed to delete session "+sessionID, err)
+		}
+		report.Sessions = append(report.Sessions, *sessionReport)
+	}
+
+	if len(sessionIDs) > 0 {
+		r, err := tx.NewDelete().
+			Model((*DeadLetterSchema)(nil)).
+			Where("metadata->>'session_id' IN (?)", bun.In(sessionIDs)).
+			Exec(ctx)
+		if err != nil {
+			return nil, store.NewStorageError("failed to delete dead letters", err)
+		}
+		deadLetters, err := r.RowsAffected()
+		if err != nil {
+			return nil, store.NewStorageError("failed to get rows affected", err)
+		}
+		report.DeadLetters = int(deadLetters)
+	}
+
+	// facts are overwritten, as message content is, so that they don't survive in dead tuples
+	_, err = tx.NewUpdate().
+		Model((*FactSchema)(nil)).
+		Set("subject = md5(random()::text)").
+		Set("predicate = md5(random()::text)").
+		Set("object = md5(random()::text)").
+		Where("user_id = ?", userID).
+		Exec(ctx)
+	if err != nil {
+		return nil, store.NewStorageError("failed to overwrite facts", err)
+	}
+	r, err := tx.NewDelete().
+		Model((*FactSchema)(nil)).
+		Where("user_id = ?", userID).
+		Exec(ctx)
+	if err != nil {
+		return nil, store.NewStorageError("failed to delete facts", err)
+	}
+	facts, err := r.RowsAffected()
+	if err != nil {
+		return nil, store.NewStorageError("failed to get rows affected", err)
+	}
+	report.Facts = int(facts)
+
+	// the mentions of the user's sessions were deleted with them
+	report.Entities, err = deleteUnmentionedEntities(ctx, tx)
+	if err != nil {
+		return nil, store.NewStorageError("failed to delete entities", err)
+	}
+
+	_, err = tx.NewDelete().
+		Model((*UserSchema)(nil)).
+		WhereAllWithDeleted().
+		Where("user_id = ?", userID).
+		ForceDelete().
+		Exec(ctx)
+	if err != nil {
+		return nil, store.NewStorageError("failed to delete user", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, store.NewStorageError("failed to commit transaction", err)
+	}
+	report.ErasedAt = time.Now()
+
+	vacuumSecureDeleteTables(ctx, pms.Client, "user "+userID)
+
+	for _, key := range archiveKeys {
+		if err := pms.ArchiveStore.DeleteObject(ctx, key); err != nil {
+			return nil, store.NewStorageError("failed to delete session archive "+key, err)
+		}
+	}
+
+	if pms.VectorIndex != nil {
+		for _, sessionID := range sessionIDs {
+			if err := pms.VectorIndex.DeleteSessionEmbeddings(ctx, sessionID); err != nil {
+				return nil, store.NewStorageError(
+					"failed to delete vector index embeddings of session "+sessionID,
+					err,
+				)
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// countSessionRecords counts a session's records, including deleted records
+func countSessionRecords(
+	ctx context.Context,
+	tx bun.Tx,
+	sessionID string,
+) (*models.SessionErasureReport, error) {
+	report := &models.SessionErasureReport{SessionID: sessionID}
+	counts := []struct {
+		model interface{}
+		count *int
+	}{
+		{(*MessageStoreSchema)(nil), &report.Messages},
+		{(*MessageVectorStoreSchema)(nil), &report.MessageEmbeddings},
+		{(*SummaryStoreSchema)(nil), &report.Summaries},
+		{(*SummaryVectorStoreSchema)(nil), &report.SummaryEmbeddings},
+		{(*SummaryAbstractSchema)(nil), &report.SummaryAbstracts},
+	}
+	for _, c := range counts {
+		count, err := tx.NewSelect().
+			Model(c.model).
+			WhereAllWithDeleted().
+			Where("session_id = ?", sessionID).
+			Count(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count %T: %w", c.model, err)
+		}
+		*c.count = count
+	}
+	return report, nil
+}
@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEraseUserData(t *testing.T) {
+	userID := testutils.GenerateRandomString(16)
+	userStore := NewUserStoreDAO(testDB)
+	_, err := userStore.Create(testCtx, &models.CreateUserRequest{UserID: userID})
+	require.NoError(t, err)
+
+	sessionIDs := make([]string, 2)
+	sessionDAO := NewSessionDAO(testDB)
+	for i := range sessionIDs {
+		sessionIDs[i] = testutils.GenerateRandomString(16)
+		_, err := sessionDAO.Create(testCtx, &models.CreateSessionRequest{
+			SessionID: sessionIDs[i],
+			UserID:    &userID,
+		})
+		require.NoError(t, err)
+	}
+
+	msgs, err := putMessages(testCtx, testDB, sessionIDs[0], []models.Message{
+		{Role: "user", Content: "my address is 1 Main St"},
+		{Role: "assistant", Content: "noted"},
+	})
+	require.NoError(t, err)
+	_, err = putSummary(testCtx, testDB, sessionIDs[0], &models.Summary{
+		Content:          "the user lives at 1 Main St",
+		SummaryPointUUID: msgs[1].UUID,
+	})
+	require.NoError(t, err)
+
+	err = appState.MemoryStore.PutFacts(testCtx, userID, []models.Fact{
+		{Subject: "user", Predicate: "lives at", Object: "1 Main St", SessionID: sessionIDs[0]},
+	})
+	require.NoError(t, err)
+
+	entityName := testutils.GenerateRandomString(16)
+	err = appState.MemoryStore.PutEntityMentions(testCtx, sessionIDs[0], map[uuid.UUID][]models.Entity{
+		msgs[0].UUID: {{Name: entityName, Label: "PERSON"}},
+	})
+	require.NoError(t, err)
+
+	// deleted sessions are erased too
+	require.NoError(t, sessionDAO.Delete(testCtx, sessionIDs[1], false))
+
+	report, err := appState.MemoryStore.EraseUserData(testCtx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, userID, report.UserID)
+	assert.NotZero(t, report.ErasedAt)
+	require.Len(t, report.Sessions, 2)
+	assert.Equal(t, sessionIDs[0], report.Sessions[0].SessionID)
+	assert.Equal(t, 2, report.Sessions[0].Messages)
+	assert.Equal(t, 1, report.Sessions[0].Summaries)
+	assert.Equal(t, sessionIDs[1], report.Sessions[1].SessionID)
+	assert.Equal(t, 1, report.Facts)
+	assert.GreaterOrEqual(t, report.Entities, 1)
+
+	exists, err := testDB.NewSelect().
+		Model((*EntitySchema)(nil)).
+		Where("normalized_name = ?", normalizeEntityName(entityName)).
+		Exists(testCtx)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	for _, sessionID := range sessionIDs {
+		for _, schema := range messageTableList {
+			count, err := testDB.NewSelect().
+				Model(schema).
+				WhereAllWithDeleted().
+				Where("session_id = ?", sessionID).
+				Count(testCtx)
+			require.NoError(t, err)
+			assert.Zero(t, count, "%T", schema)
+		}
+	}
+
+	_, err = userStore.Get(testCtx, userID)
+	assert.ErrorIs(t, err, models.ErrNotFound)
+
+	t.Run("non-existent user returns not found", func(t *testing.T) {
+		_, err := appState.MemoryStore.EraseUserData(testCtx, "nonexistent")
+		assert.ErrorIs(t, err, models.ErrNotFound)
+	})
+}
@@ -24,7 +24,7 @@ func NewUserStoreDAO(db *bun.DB) *UserStoreDAO {
 	}
 }
 
-// Create creates a new user.
+// Create creates a new user in the project ctx is scoped to.
 func (dao *UserStoreDAO) Create(
 	ctx context.Context,
 	user *models.CreateUserRequest,
@@ -32,12 +32,14 @@ func (dao *UserStoreDAO) Create(
 	if user.UserID == "" {
 		return nil, models.NewBadRequestError("UserID cannot be empty")
 	}
+	projectID, _ := models.ProjectIDFromContext(ctx)
 	userDB := &UserSchema{
 		UserID:    user.UserID,
 		Email:     user.Email,
 		FirstName: user.FirstName,
 		LastName:  user.LastName,
 		Metadata:  user.Metadata,
+		ProjectID: projectID,
 	}
 	_, err := dao.db.NewInsert().Model(userDB).Returning("*").Exec(ctx)
 	if err != nil {
@@ -67,7 +69,11 @@ func (dao *UserStoreDAO) Create(
 // Get gets a user by UserID.
 func (dao *UserStoreDAO) Get(ctx context.Context, userID string) (*models.User, error) {
 	user := new(UserSchema)
-	err := dao.db.NewSelect().Model(user).Where("user_id = ?", userID).Scan(ctx)
+	err := dao.db.NewSelect().
+		Model(user).
+		Where("user_id = ?", userID).
+		ApplyQueryBuilder(whereProject(ctx)).
+		Scan(ctx)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, models.NewNotFoundError("user " + userID)
@@ -137,6 +143,7 @@ func (dao *UserStoreDAO) updateUser(
 		Column("email", "first_name", "last_name", "metadata", "updated_at").
 		OmitZero().
 		Where("user_id = ?", user.UserID).
+		ApplyQueryBuilder(whereProject(ctx)).
 		Exec(ctx)
 	if err != nil {
 		return nil, err
@@ -180,6 +187,10 @@ func (dao *UserStoreDAO) Delete(ctx context.Context, userID string) error {
 	}
 	defer rollbackOnError(tx)
 
+	if err := checkUserProject(ctx, dao.db, userID); err != nil {
+		return err
+	}
+
 	// Delete all related sessions
 	sessions, err := dao.GetSessions(ctx, userID)
 	if err != nil {
@@ -188,7 +199,7 @@ func (dao *UserStoreDAO) Delete(ctx context.Context, userID string) error {
 
 	sessionStore := NewSessionDAO(dao.db)
 	for s := range sessions {
-		err := sessionStore.Delete(ctx, sessions[s].SessionID)
+		err := sessionStore.Delete(ctx, sessions[s].SessionID, false)
 		if err != nil {
 			return err
 		}
@@ -226,6 +237,7 @@ func (dao *UserStoreDAO) ListAll(
 	err := dao.db.NewSelect().
 		Model(&usersDB).
 		Where("id > ?", cursor).
+		ApplyQueryBuilder(whereProject(ctx)).
 		OrderExpr("id ASC").
 		Limit(limit).
 		Scan(ctx)
@@ -263,6 +275,7 @@ func (dao *UserStoreDAO) ListAllOrdered(
 		defer wg.Done()
 		err := dao.db.NewSelect().
 			Model(&users).
+			ApplyQueryBuilder(whereProject(ctx)).
 			Order(fmt.Sprintf("%s %s", orderBy, getAscDesc(asc))).
 			Limit(pageSize).
 			Offset((pageNumber - 1) * pageSize).
@@ -281,6 +294,7 @@ func (dao *UserStoreDAO) ListAllOrdered(
 		var err error
 		totalCount, err = dao.db.NewSelect().
 			Model((*UserSchema)(nil)).
+			ApplyQueryBuilder(whereProject(ctx)).
 			Count(ctx)
 
 		mu.Lock()
@@ -318,6 +332,7 @@ func (dao *UserStoreDAO) GetSessions(
 		Model(&sessionsDB).
 		Join("JOIN users u ON u.user_id = s.user_id").
 		Where("u.user_id = ?", userID).
+		ApplyQueryBuilder(whereProject(ctx)).
 		Scan(ctx)
 	if err != nil {
 		return nil, err
@@ -164,7 +164,7 @@ func TestUserStoreDAO(t *testing.T) {
 
 		// Test that messages and summaries are deleted
 		for _, sessionID := range testSessions {
-			respMessages, err := getMessages(testCtx, testDB, sessionID, 999, nil, 999)
+			respMessages, err := getMessages(testCtx, testDB, sessionID, 999, nil, 999, nil)
 			assert.NoError(t, err, "getMessages should not return an error")
 			assert.Nil(t, respMessages, "getMessages should return nil")
 
@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"math"
@@ -20,8 +21,75 @@ const EmbeddingColName = "embedding"
 // recommend creating the index after a representative sample of data is loaded. This is a guesstimate.
 const MinRowsForIndex = 10000
 
-// IndexMutexMap stores a mutex for each collection.
-var IndexMutexMap = make(map[string]*sync.Mutex)
+// indexMutexes stores a mutex for each collection, held while the collection's index is built.
+var (
+	indexMutexes   = make(map[string]*sync.Mutex)
+	indexMutexesMu sync.Mutex
+)
+
+// indexMutex returns the mutex of a collection, creating it if it does not exist.
+func indexMutex(collectionName string) *sync.Mutex {
+	indexMutexesMu.Lock()
+	defer indexMutexesMu.Unlock()
+
+	mu, ok := indexMutexes[collectionName]
+	if !ok {
+		mu = &sync.Mutex{}
+		indexMutexes[collectionName] = mu
+	}
+	return mu
+}
+
+// lockIndex locks a collection's mutex for an index build, returning a bad request error if
+// an index is already being built on the collection.
+func lockIndex(collectionName string) (*sync.Mutex, error) {
+	mu := indexMutex(collectionName)
+	if !mu.TryLock() {
+		return nil, models.NewBadRequestError(
+			"an index is already being built on collection " + collectionName,
+		)
+	}
+	return mu, nil
+}
+
+// isIndexBuilding returns true while an index is being built on a collection.
+func isIndexBuilding(collectionName string) bool {
+	mu := indexMutex(collectionName)
+	if !mu.TryLock() {
+		return true
+	}
+	mu.Unlock()
+	return false
+}
+
+// ivfflatIndexName returns the name of the ivfflat index on a collection's table.
+func ivfflatIndexName(tableName string) string {
+	return fmt.Sprintf("%s_%s_idx", tableName, EmbeddingColName)
+}
+
+// hnswIndexName returns the name of the hnsw index on a collection's table.
+func hnswIndexName(tableName string) string {
+	return tableName + "_" + EmbeddingColName + "_hnsw_idx"
+}
+
+// getIndexState returns whether an index exists and, if it does, whether it is valid. An
+// index is invalid if its build failed or was interrupted.
+func getIndexState(ctx context.Context, db bun.IDB, idx string) (exists, valid bool, err error) {
+	err = db.NewSelect().
+		TableExpr("pg_class AS c").
+		Join("JOIN pg_index AS i ON i.indexrelid = c.oid").
+		ColumnExpr("i.indisvalid").
+		Where("c.relname = ?", idx).
+		Where("c.relkind = 'i'").
+		Scan(ctx, &valid)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, false, nil
+		}
+		return false, false, fmt.Errorf("error getting index %s: %w", idx, err)
+	}
+	return true, valid, nil
+}
 
 type VectorColIndex struct {
 	appState   *models.AppState
@@ -38,8 +106,10 @@ func (vci *VectorColIndex) CountRows(ctx context.Context) error {
 		return fmt.Errorf("failed to get bun.DB client")
 	}
 
+	// documents without embeddings are not indexed
 	count, err := client.NewSelect().
 		ModelTableExpr("?", bun.Ident(vci.Collection.TableName)).
+		Where("embedding IS NOT NULL").
 		Count(ctx)
 	if err != nil {
 		return fmt.Errorf("error counting rows: %w", err)
@@ -78,14 +148,9 @@ func (vci *VectorColIndex) CalculateProbes() error {
 	return nil
 }
 
+// CreateIndex drops and recreates the ivfflat index on the collection's table in the
+// background. An error is returned if an index is already being built on the collection.
 func (vci *VectorColIndex) CreateIndex(_ context.Context, force bool) error {
-	// Check if a mutex already exists for this collection. If not, create one.
-	if _, ok := IndexMutexMap[vci.Collection.Name]; !ok {
-		IndexMutexMap[vci.Collection.Name] = &sync.Mutex{}
-	}
-	// Lock the mutex for this collection.
-	IndexMutexMap[vci.Collection.Name].Lock()
-
 	if vci.Collection.DistanceFunction != "cosine" {
 		return fmt.Errorf("only cosine distance function is currently supported")
 	}
@@ -100,11 +165,17 @@ func (vci *VectorColIndex) CreateIndex(_ context.Context, force bool) error {
 		return fmt.Errorf("failed to get bun.DB db")
 	}
 
-	indexName := fmt.Sprintf("%s_%s_idx", vci.Collection.TableName, vci.ColName)
+	// Lock the mutex for this collection until the index is built.
+	mu, err := lockIndex(vci.Collection.Name)
+	if err != nil {
+		return err
+	}
+
+	indexName := ivfflatIndexName(vci.Collection.TableName)
 
 	// run index creation in a goroutine with IndexTimeout
 	go func() {
-		defer IndexMutexMap[vci.Collection.Name].Unlock()
+		defer mu.Unlock()
 		// Create a new context with a timeout
 		ctx, cancel := context.WithTimeout(context.Background(), IndexTimeout)
 		defer cancel()
@@ -123,9 +194,11 @@ func (vci *VectorColIndex) CreateIndex(_ context.Context, force bool) error {
 
 		// currently only supports cosine distance ops
 		log.Infof("Starting index creation on %s", vci.Collection.Name)
-		_, err = db.ExecContext(
+		err = execWithoutStatementTimeout(
 			ctx,
-			"CREATE INDEX CONCURRENTLY ON ? USING ivfflat (embedding vector_cosine_ops) WITH (lists = ?)",
+			db,
+			"CREATE INDEX CONCURRENTLY ? ON ? USING ivfflat (embedding vector_cosine_ops) WITH (lists = ?)",
+			bun.Ident(indexName),
 			bun.Ident(vci.Collection.TableName),
 			vci.ListCount,
 		)
@@ -135,21 +208,20 @@ func (vci *VectorColIndex) CreateIndex(_ context.Context, force bool) error {
 		}
 
 		// Set Collection's IsIndexed flag to true
-		collection, err := vci.appState.DocumentStore.GetCollection(ctx, vci.Collection.Name)
-		if err != nil {
-			log.Error("error getting collection: ", err)
-			return
-		}
-		collection.IsIndexed = true
-		collection.ProbeCount = vci.ProbeCount
-		collection.ListCount = vci.ListCount
-		err = vci.appState.DocumentStore.UpdateCollection(ctx, collection)
+		_, err = db.NewUpdate().
+			TableExpr("document_collection").
+			Set("is_indexed = ?", true).
+			Set("list_count = ?", vci.ListCount).
+			Set("probe_count = ?", vci.ProbeCount).
+			Set("updated_at = ?", time.Now()).
+			Where("table_name = ?", vci.Collection.TableName).
+			Exec(ctx)
 		if err != nil {
 			log.Error("error updating collection: ", err)
 			return
 		}
 
-		log.Infof("Index creation on %s completed successfully", collection.Name)
+		log.Infof("Index creation on %s completed successfully", vci.Collection.Name)
 	}()
 
 	return nil
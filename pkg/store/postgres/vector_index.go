@@ -0,0 +1,208 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/store"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// searchMemoryVectorIndex searches a session's messages using an external vector index.
+// Candidate message UUIDs and scores come from the index. The messages themselves, and any
+// metadata and date filters, are resolved against Postgres, so filters are applied after
+// the nearest neighbours have been selected. A VectorFilter is passed through to indexes
+// that support native filtering, and is applied while selecting the nearest neighbours.
+// Indexes that support hybrid search rank candidates by both the query text and embedding.
+func searchMemoryVectorIndex(
+	ctx context.Context,
+	appState *models.AppState,
+	db *bun.DB,
+	index models.MessageVectorIndex,
+	sessionID string,
+	query *models.MemorySearchPayload,
+	limit int,
+) ([]models.MemorySearchResult, error) {
+	if query == nil || appState == nil {
+		return nil, store.NewStorageError("nil query or appState received", nil)
+	}
+
+	if isEmptyMemorySearch(query) {
+		return nil, errors.New("empty query")
+	}
+
+	if err := validateMemorySearchPayload(query); err != nil {
+		return nil, err
+	}
+
+	if limit == 0 {
+		limit = DefaultMemorySearchLimit
+	}
+
+	dbQuery := db.NewSelect().TableExpr("message AS m").
+		ColumnExpr("m.uuid AS message__uuid").
+		ColumnExpr("m.created_at AS message__created_at").
+		ColumnExpr("m.role AS message__role").
+		ColumnExpr("m.content AS message__content").
+		ColumnExpr("m.metadata AS message__metadata").
+		ColumnExpr("m.token_count AS message__token_count").
+		Where("m.session_id = ?", sessionID).
+		Where("m.deleted_at IS NULL")
+	dbQuery = applyMemoryDateFilter(dbQuery, query, "m")
+
+	if len(query.Metadata) > 0 {
+		var err error
+		dbQuery, err = applyMemoryMetadataFilter(dbQuery, query.Metadata, "m")
+		if err != nil {
+			return nil, store.NewStorageError("error applying metadata filter", err)
+		}
+	}
+
+	// Without query text there is nothing to rank by, so this is a metadata search over
+	// the relational store alone.
+	if query.Text == "" {
+		dbQuery = dbQuery.Order("m.created_at DESC").Limit(limit)
+		results, err := executeMessagesSearchScan(ctx, dbQuery)
+		if err != nil {
+			return nil, store.NewStorageError("memory searchMemory failed", err)
+		}
+		return results, nil
+	}
+
+	queryEmbedding, err := embedMemoryQuery(ctx, appState, "message", query.Text)
+	if err != nil {
+		return nil, store.NewStorageError("error embedding query", err)
+	}
+
+	indexLimit := limit
+	if query.SearchType == models.SearchTypeMMR {
+		if query.MMRLambda == 0 {
+			query.MMRLambda = DefaultMMRLambda
+		}
+		indexLimit = limit * DefaultMMRMultiplier
+		if indexLimit < 10 {
+			indexLimit = 10
+		}
+	}
+
+	var indexResults []models.VectorIndexResult
+	if len(query.VectorFilter) > 0 {
+		filteredIndex, ok := index.(models.FilteredMessageVectorIndex)
+		if !ok {
+			return nil, models.NewBadRequestError(
+				"vector_filter is not supported by the configured vector store",
+			)
+		}
+		indexResults, err = filteredIndex.SearchMessageEmbeddingsFiltered(
+			ctx,
+			sessionID,
+			queryEmbedding,
+			indexLimit,
+			query.VectorFilter,
+		)
+	} else if hybridIndex, ok := index.(models.HybridMessageVectorIndex); ok {
+		indexResults, err = hybridIndex.SearchMessagesHybrid(
+			ctx,
+			sessionID,
+			query.Text,
+			queryEmbedding,
+			indexLimit,
+		)
+	} else {
+		indexResults, err = index.SearchMessageEmbeddings(
+			ctx,
+			sessionID,
+			queryEmbedding,
+			indexLimit,
+		)
+	}
+	if err != nil {
+		return nil, store.NewStorageError("vector index search failed", err)
+	}
+	if len(indexResults) == 0 {
+		return []models.MemorySearchResult{}, nil
+	}
+
+	uuids := make([]uuid.UUID, len(indexResults))
+	indexResultMap := make(map[uuid.UUID]models.VectorIndexResult, len(indexResults))
+	for i, r := range indexResults {
+		uuids[i] = r.UUID
+		indexResultMap[r.UUID] = r
+	}
+
+	dbQuery = dbQuery.Where("m.uuid IN (?)", bun.In(uuids))
+	results, err := executeMessagesSearchScan(ctx, dbQuery)
+	if err != nil {
+		return nil, store.NewStorageError("memory searchMemory failed", err)
+	}
+
+	for i := range results {
+		r := indexResultMap[results[i].Message.UUID]
+		results[i].Dist = r.Score
+		if query.SearchType == models.SearchTypeMMR {
+			results[i].Embedding = r.Embedding
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Dist > results[j].Dist
+	})
+
+	if query.SearchType == models.SearchTypeMMR {
+		if len(results) == 0 {
+			return results, nil
+		}
+		return rerankMMR(results, queryEmbedding, query.MMRLambda, limit)
+	}
+
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results, nil
+}
+
+// getMessageEmbeddingsFromIndex returns a session's embeddings from an external vector
+// index, populating the text of each embedding from the session's messages. Embeddings for
+// messages that have been deleted are omitted.
+func getMessageEmbeddingsFromIndex(
+	ctx context.Context,
+	db *bun.DB,
+	index models.MessageVectorIndex,
+	sessionID string,
+) ([]models.TextData, error) {
+	embeddings, err := index.GetMessageEmbeddings(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if len(embeddings) == 0 {
+		return []models.TextData{}, nil
+	}
+
+	uuids := make([]uuid.UUID, len(embeddings))
+	for i, e := range embeddings {
+		uuids[i] = e.TextUUID
+	}
+	messages, err := getMessagesByUUID(ctx, db, sessionID, uuids)
+	if err != nil {
+		return nil, err
+	}
+	content := make(map[uuid.UUID]string, len(messages))
+	for _, m := range messages {
+		content[m.UUID] = m.Content
+	}
+
+	results := make([]models.TextData, 0, len(embeddings))
+	for _, e := range embeddings {
+		text, ok := content[e.TextUUID]
+		if !ok {
+			continue
+		}
+		e.Text = text
+		results = append(results, e)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,122 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticVectorIndex returns the same results for every search, and records the message
+// embeddings deleted from it.
+type staticVectorIndex struct {
+	results []models.VectorIndexResult
+	deleted []uuid.UUID
+}
+
+func (s *staticVectorIndex) PutMessageEmbeddings(
+	_ context.Context,
+	_ string,
+	_ []models.TextData,
+) error {
+	return nil
+}
+
+func (s *staticVectorIndex) GetMessageEmbeddings(
+	_ context.Context,
+	_ string,
+) ([]models.TextData, error) {
+	embeddings := make([]models.TextData, len(s.results))
+	for i, r := range s.results {
+		embeddings[i] = models.TextData{TextUUID: r.UUID, Embedding: r.Embedding}
+	}
+	return embeddings, nil
+}
+
+func (s *staticVectorIndex) SearchMessageEmbeddings(
+	_ context.Context,
+	_ string,
+	_ []float32,
+	limit int,
+) ([]models.VectorIndexResult, error) {
+	if len(s.results) > limit {
+		return s.results[:limit], nil
+	}
+	return s.results, nil
+}
+
+func (s *staticVectorIndex) DeleteSessionEmbeddings(_ context.Context, _ string) error {
+	return nil
+}
+
+func (s *staticVectorIndex) DeleteMessageEmbedding(
+	_ context.Context,
+	_ string,
+	messageUUID uuid.UUID,
+) error {
+	s.deleted = append(s.deleted, messageUUID)
+	return nil
+}
+
+func TestSearchMemoryVectorIndex(t *testing.T) {
+	sessionID := createSession(t)
+
+	messages, err := putMessages(testCtx, testDB, sessionID, []models.Message{
+		{Role: "human", Content: "first", Metadata: map[string]interface{}{"foo": "bar"}},
+		{Role: "ai", Content: "second"},
+		{Role: "human", Content: "third"},
+	})
+	require.NoError(t, err)
+
+	index := &staticVectorIndex{results: []models.VectorIndexResult{
+		{UUID: messages[2].UUID, Score: 0.9, Embedding: []float32{1, 0}},
+		{UUID: messages[0].UUID, Score: 0.5, Embedding: []float32{0, 1}},
+	}}
+
+	t.Run("orders messages by index score", func(t *testing.T) {
+		results, err := searchMemoryVectorIndex(
+			testCtx,
+			appState,
+			testDB,
+			index,
+			sessionID,
+			&models.MemorySearchPayload{Text: "search"},
+			10,
+		)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, "third", results[0].Message.Content)
+		assert.Equal(t, 0.9, results[0].Dist)
+		assert.Equal(t, "first", results[1].Message.Content)
+	})
+
+	t.Run("applies metadata filter to index results", func(t *testing.T) {
+		results, err := searchMemoryVectorIndex(
+			testCtx,
+			appState,
+			testDB,
+			index,
+			sessionID,
+			&models.MemorySearchPayload{
+				Text: "search",
+				Metadata: map[string]interface{}{
+					"where": map[string]interface{}{"jsonpath": `$.foo ? (@ == "bar")`},
+				},
+			},
+			10,
+		)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "first", results[0].Message.Content)
+	})
+
+	t.Run("returns embeddings with message text", func(t *testing.T) {
+		embeddings, err := getMessageEmbeddingsFromIndex(testCtx, testDB, index, sessionID)
+		require.NoError(t, err)
+		require.Len(t, embeddings, 2)
+		assert.Equal(t, "third", embeddings[0].Text)
+	})
+}
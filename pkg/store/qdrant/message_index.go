@@ -0,0 +1,347 @@
+package qdrant
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/llms"
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/store"
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultCollection = "zep_message_embeddings"
+	requestTimeout    = 30 * time.Second
+	maxRetryAttempts  = 3
+	scrollPageSize    = 256
+	sessionIDField    = "session_id"
+)
+
+var ErrCollectionDimensionMismatch = errors.New("qdrant collection vector size mismatch")
+
+var _ models.MessageVectorIndex = (*MessageIndex)(nil)
+
+// MessageIndex is a MessageVectorIndex backed by a Qdrant collection. Points are keyed by
+// message UUID and carry the session ID as a payload field.
+type MessageIndex struct {
+	baseURL    string
+	apiKey     string
+	collection string
+	client     *http.Client
+}
+
+// NewMessageIndex returns a MessageIndex for the configured collection, creating the
+// collection and its session ID payload index if they don't exist.
+func NewMessageIndex(
+	ctx context.Context,
+	cfg *config.QdrantConfig,
+	dimensions int,
+) (*MessageIndex, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("vector_store.qdrant.url must be set")
+	}
+	if _, err := url.ParseRequestURI(cfg.URL); err != nil {
+		return nil, fmt.Errorf("invalid vector_store.qdrant.url: %w", err)
+	}
+
+	collection := cfg.Collection
+	if collection == "" {
+		collection = DefaultCollection
+	}
+
+	idx := &MessageIndex{
+		baseURL:    strings.TrimSuffix(cfg.URL, "/"),
+		apiKey:     cfg.APIKey,
+		collection: collection,
+		client:     llms.NewRetryableHTTPClient(maxRetryAttempts, requestTimeout),
+	}
+
+	if err := idx.ensureCollection(ctx, dimensions); err != nil {
+		return nil, err
+	}
+
+	return idx, nil
+}
+
+type point struct {
+	ID      uuid.UUID              `json:"id"`
+	Vector  []float32              `json:"vector,omitempty"`
+	Payload map[string]interface{} `json:"payload,omitempty"`
+	Score   float64                `json:"score,omitempty"`
+}
+
+type matchCondition struct {
+	Key   string `json:"key"`
+	Match struct {
+		Value string `json:"value"`
+	} `json:"match"`
+}
+
+type filter struct {
+	Must []matchCondition `json:"must"`
+}
+
+func sessionFilter(sessionID string) *filter {
+	c := matchCondition{Key: sessionIDField}
+	c.Match.Value = sessionID
+	return &filter{Must: []matchCondition{c}}
+}
+
+func (idx *MessageIndex) PutMessageEmbeddings(
+	ctx context.Context,
+	sessionID string,
+	embeddings []models.TextData,
+) error {
+	if len(embeddings) == 0 {
+		return store.NewStorageError("no embeddings received", nil)
+	}
+
+	points := make([]point, len(embeddings))
+	for i, e := range embeddings {
+		points[i] = point{
+			ID:      e.TextUUID,
+			Vector:  e.Embedding,
+			Payload: map[string]interface{}{sessionIDField: sessionID},
+		}
+	}
+
+	err := idx.do(
+		ctx,
+		http.MethodPut,
+		"/points?wait=true",
+		map[string]interface{}{"points": points},
+		nil,
+	)
+	if err != nil {
+		return store.NewStorageError("failed to upsert qdrant points", err)
+	}
+
+	return nil
+}
+
+func (idx *MessageIndex) GetMessageEmbeddings(
+	ctx context.Context,
+	sessionID string,
+) ([]models.TextData, error) {
+	var embeddings []models.TextData
+	var offset *uuid.UUID
+	for {
+		req := map[string]interface{}{
+			"filter":       sessionFilter(sessionID),
+			"limit":        scrollPageSize,
+			"with_vector":  true,
+			"with_payload": false,
+		}
+		if offset != nil {
+			req["offset"] = offset
+		}
+
+		var resp struct {
+			Result struct {
+				Points         []point    `json:"points"`
+				NextPageOffset *uuid.UUID `json:"next_page_offset"`
+			} `json:"result"`
+		}
+		if err := idx.do(ctx, http.MethodPost, "/points/scroll", req, &resp); err != nil {
+			return nil, store.NewStorageError("failed to scroll qdrant points", err)
+		}
+
+		for _, p := range resp.Result.Points {
+			embeddings = append(embeddings, models.TextData{
+				TextUUID:  p.ID,
+				Embedding: p.Vector,
+			})
+		}
+
+		if resp.Result.NextPageOffset == nil {
+			break
+		}
+		offset = resp.Result.NextPageOffset
+	}
+
+	return embeddings, nil
+}
+
+func (idx *MessageIndex) SearchMessageEmbeddings(
+	ctx context.Context,
+	sessionID string,
+	embedding []float32,
+	limit int,
+) ([]models.VectorIndexResult, error) {
+	req := map[string]interface{}{
+		"vector":      embedding,
+		"filter":      sessionFilter(sessionID),
+		"limit":       limit,
+		"with_vector": true,
+	}
+
+	var resp struct {
+		Result []point `json:"result"`
+	}
+	if err := idx.do(ctx, http.MethodPost, "/points/search", req, &resp); err != nil {
+		return nil, store.NewStorageError("failed to search qdrant points", err)
+	}
+
+	results := make([]models.VectorIndexResult, len(resp.Result))
+	for i, p := range resp.Result {
+		results[i] = models.VectorIndexResult{
+			UUID:      p.ID,
+			Score:     p.Score,
+			Embedding: p.Vector,
+		}
+	}
+
+	return results, nil
+}
+
+func (idx *MessageIndex) DeleteSessionEmbeddings(ctx context.Context, sessionID string) error {
+	err := idx.do(
+		ctx,
+		http.MethodPost,
+		"/points/delete?wait=true",
+		map[string]interface{}{"filter": sessionFilter(sessionID)},
+		nil,
+	)
+	if err != nil {
+		return store.NewStorageError("failed to delete qdrant points", err)
+	}
+
+	return nil
+}
+
+// DeleteMessageEmbedding deletes a message's point. Points are keyed by message UUID.
+func (idx *MessageIndex) DeleteMessageEmbedding(
+	ctx context.Context,
+	_ string,
+	messageUUID uuid.UUID,
+) error {
+	err := idx.do(
+		ctx,
+		http.MethodPost,
+		"/points/delete?wait=true",
+		map[string]interface{}{"points": []uuid.UUID{messageUUID}},
+		nil,
+	)
+	if err != nil {
+		return store.NewStorageError("failed to delete qdrant point", err)
+	}
+
+	return nil
+}
+
+// ensureCollection creates the collection if it doesn't exist, or validates the vector size
+// of an existing collection. Dot product matches pgvector's inner product search.
+func (idx *MessageIndex) ensureCollection(ctx context.Context, dimensions int) error {
+	var resp struct {
+		Result struct {
+			Config struct {
+				Params struct {
+					Vectors struct {
+						Size int `json:"size"`
+					} `json:"vectors"`
+				} `json:"params"`
+			} `json:"config"`
+		} `json:"result"`
+	}
+	err := idx.do(ctx, http.MethodGet, "", nil, &resp)
+	if err == nil {
+		size := resp.Result.Config.Params.Vectors.Size
+		if size != dimensions {
+			return fmt.Errorf(
+				"%w: collection %s has size %d, embedding model has %d dimensions",
+				ErrCollectionDimensionMismatch,
+				idx.collection,
+				size,
+				dimensions,
+			)
+		}
+		return nil
+	}
+	if !errors.Is(err, models.ErrNotFound) {
+		return fmt.Errorf("failed to get qdrant collection: %w", err)
+	}
+
+	err = idx.do(ctx, http.MethodPut, "", map[string]interface{}{
+		"vectors": map[string]interface{}{
+			"size":     dimensions,
+			"distance": "Dot",
+		},
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create qdrant collection: %w", err)
+	}
+
+	err = idx.do(ctx, http.MethodPut, "/index?wait=true", map[string]interface{}{
+		"field_name":   sessionIDField,
+		"field_schema": "keyword",
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create qdrant payload index: %w", err)
+	}
+
+	return nil
+}
+
+// do sends a request to the collection's endpoint at path, decoding the response into
+// result if it is not nil. A 404 response returns a models.ErrNotFound.
+func (idx *MessageIndex) do(
+	ctx context.Context,
+	method string,
+	path string,
+	body interface{},
+	result interface{},
+) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reqBody = bytes.NewReader(b)
+	}
+
+	endpoint := idx.baseURL + "/collections/" + url.PathEscape(idx.collection) + path
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if idx.apiKey != "" {
+		req.Header.Set("api-key", idx.apiKey)
+	}
+
+	resp, err := idx.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return models.NewNotFoundError("qdrant collection " + idx.collection)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("qdrant returned status %d: %s", resp.StatusCode, respBody)
+	}
+
+	if result != nil {
+		if err := json.Unmarshal(respBody, result); err != nil {
+			return fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+	}
+
+	return nil
+}
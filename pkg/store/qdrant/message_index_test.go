@@ -0,0 +1,191 @@
+package qdrant
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeQdrant struct {
+	collectionSize int
+	requests       []string
+	bodies         map[string]map[string]interface{}
+	apiKeys        []string
+	searchResponse interface{}
+}
+
+func newFakeQdrant(t *testing.T, f *fakeQdrant) *httptest.Server {
+	f.bodies = make(map[string]map[string]interface{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Method + " " + r.URL.Path
+		f.requests = append(f.requests, key)
+		f.apiKeys = append(f.apiKeys, r.Header.Get("api-key"))
+
+		if r.ContentLength > 0 {
+			var body map[string]interface{}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			f.bodies[key] = body
+		}
+
+		switch key {
+		case "GET /collections/test":
+			if f.collectionSize == 0 {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = fmt.Fprintf(
+				w,
+				`{"result":{"config":{"params":{"vectors":{"size":%d}}}}}`,
+				f.collectionSize,
+			)
+		case "POST /collections/test/points/search":
+			assert.NoError(t, json.NewEncoder(w).Encode(f.searchResponse))
+		default:
+			_, _ = w.Write([]byte(`{"result":true,"status":"ok"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNewMessageIndex(t *testing.T) {
+	t.Run("creates missing collection", func(t *testing.T) {
+		f := &fakeQdrant{}
+		server := newFakeQdrant(t, f)
+
+		_, err := NewMessageIndex(context.Background(), &config.QdrantConfig{
+			URL:        server.URL,
+			APIKey:     "secret",
+			Collection: "test",
+		}, 3)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{
+			"GET /collections/test",
+			"PUT /collections/test",
+			"PUT /collections/test/index",
+		}, f.requests)
+		vectors := f.bodies["PUT /collections/test"]["vectors"].(map[string]interface{})
+		assert.Equal(t, float64(3), vectors["size"])
+		assert.Equal(t, "Dot", vectors["distance"])
+		for _, k := range f.apiKeys {
+			assert.Equal(t, "secret", k)
+		}
+	})
+
+	t.Run("accepts existing collection", func(t *testing.T) {
+		f := &fakeQdrant{collectionSize: 3}
+		server := newFakeQdrant(t, f)
+
+		_, err := NewMessageIndex(context.Background(), &config.QdrantConfig{
+			URL:        server.URL,
+			Collection: "test",
+		}, 3)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"GET /collections/test"}, f.requests)
+	})
+
+	t.Run("rejects dimension mismatch", func(t *testing.T) {
+		f := &fakeQdrant{collectionSize: 4}
+		server := newFakeQdrant(t, f)
+
+		_, err := NewMessageIndex(context.Background(), &config.QdrantConfig{
+			URL:        server.URL,
+			Collection: "test",
+		}, 3)
+		assert.ErrorIs(t, err, ErrCollectionDimensionMismatch)
+	})
+
+	t.Run("requires url", func(t *testing.T) {
+		_, err := NewMessageIndex(context.Background(), &config.QdrantConfig{}, 3)
+		assert.Error(t, err)
+	})
+}
+
+func TestMessageIndexSearch(t *testing.T) {
+	messageUUID := uuid.New()
+	f := &fakeQdrant{
+		collectionSize: 3,
+		searchResponse: map[string]interface{}{
+			"result": []map[string]interface{}{
+				{"id": messageUUID, "score": 0.75, "vector": []float32{1, 0, 0}},
+			},
+		},
+	}
+	server := newFakeQdrant(t, f)
+
+	idx, err := NewMessageIndex(context.Background(), &config.QdrantConfig{
+		URL:        server.URL,
+		Collection: "test",
+	}, 3)
+	require.NoError(t, err)
+
+	results, err := idx.SearchMessageEmbeddings(
+		context.Background(),
+		"session",
+		[]float32{1, 0, 0},
+		5,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []models.VectorIndexResult{
+		{UUID: messageUUID, Score: 0.75, Embedding: []float32{1, 0, 0}},
+	}, results)
+
+	body := f.bodies["POST /collections/test/points/search"]
+	assert.Equal(t, float64(5), body["limit"])
+	must := body["filter"].(map[string]interface{})["must"].([]interface{})
+	condition := must[0].(map[string]interface{})
+	assert.Equal(t, sessionIDField, condition["key"])
+	assert.Equal(t, "session", condition["match"].(map[string]interface{})["value"])
+}
+
+func TestMessageIndexPutMessageEmbeddings(t *testing.T) {
+	f := &fakeQdrant{collectionSize: 3}
+	server := newFakeQdrant(t, f)
+
+	idx, err := NewMessageIndex(context.Background(), &config.QdrantConfig{
+		URL:        server.URL,
+		Collection: "test",
+	}, 3)
+	require.NoError(t, err)
+
+	err = idx.PutMessageEmbeddings(context.Background(), "session", []models.TextData{
+		{TextUUID: uuid.New(), Embedding: []float32{1, 0, 0}},
+	})
+	require.NoError(t, err)
+
+	points := f.bodies["PUT /collections/test/points"]["points"].([]interface{})
+	assert.Len(t, points, 1)
+	payload := points[0].(map[string]interface{})["payload"].(map[string]interface{})
+	assert.Equal(t, "session", payload[sessionIDField])
+
+	err = idx.PutMessageEmbeddings(context.Background(), "session", nil)
+	assert.Error(t, err)
+}
+
+func TestMessageIndexDeleteMessageEmbedding(t *testing.T) {
+	f := &fakeQdrant{collectionSize: 3}
+	server := newFakeQdrant(t, f)
+
+	idx, err := NewMessageIndex(context.Background(), &config.QdrantConfig{
+		URL:        server.URL,
+		Collection: "test",
+	}, 3)
+	require.NoError(t, err)
+
+	messageUUID := uuid.New()
+	err = idx.DeleteMessageEmbedding(context.Background(), "session", messageUUID)
+	require.NoError(t, err)
+
+	body := f.bodies["POST /collections/test/points/delete"]
+	assert.Equal(t, []interface{}{messageUUID.String()}, body["points"])
+}
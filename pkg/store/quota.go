@@ -0,0 +1,19 @@
+package store
+
+import "github.com/getzep/zep/pkg/models"
+
+var _ models.QuotaStore = (*StaticQuotaStore)(nil)
+
+// StaticQuotaStore is a QuotaStore that applies the same session quota to all tenants.
+type StaticQuotaStore struct {
+	sessionQuota int64
+}
+
+// NewStaticQuotaStore returns a StaticQuotaStore with the given session quota.
+func NewStaticQuotaStore(sessionQuota int64) *StaticQuotaStore {
+	return &StaticQuotaStore{sessionQuota: sessionQuota}
+}
+
+func (s *StaticQuotaStore) GetSessionQuota(_ string) (int64, error) {
+	return s.sessionQuota, nil
+}
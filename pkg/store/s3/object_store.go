@@ -0,0 +1,153 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/llms"
+	"github.com/getzep/zep/pkg/models"
+)
+
+const (
+	requestTimeout   = 60 * time.Second
+	maxRetryAttempts = 3
+	service          = "s3"
+)
+
+var _ models.ObjectStore = (*ObjectStore)(nil)
+
+// ObjectStore is an ObjectStore backed by a bucket of Amazon S3 or an S3-compatible store.
+// Requests are signed with AWS Signature Version 4.
+type ObjectStore struct {
+	endpoint string
+	region   string
+	bucket   string
+	prefix   string
+	creds    llms.AWSCredentials
+	client   *http.Client
+}
+
+// NewObjectStore returns an ObjectStore for the configured bucket
+func NewObjectStore(cfg *config.S3Config) (*ObjectStore, error) {
+	if cfg.Bucket == "" {
+		return nil, errors.New("s3 bucket must be set")
+	}
+	if cfg.Region == "" {
+		return nil, errors.New("s3 region must be set")
+	}
+
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", cfg.Region)
+	}
+	if _, err := url.ParseRequestURI(endpoint); err != nil {
+		return nil, fmt.Errorf("invalid s3 endpoint: %w", err)
+	}
+
+	return &ObjectStore{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		region:   cfg.Region,
+		bucket:   cfg.Bucket,
+		prefix:   cfg.Prefix,
+		creds: llms.AWSCredentials{
+			AccessKeyID:     cfg.AccessKeyID,
+			SecretAccessKey: cfg.SecretAccessKey,
+		},
+		client: llms.NewRetryableHTTPClient(maxRetryAttempts, requestTimeout),
+	}, nil
+}
+
+func (s *ObjectStore) PutObject(ctx context.Context, key string, data []byte) error {
+	_, err := s.do(ctx, http.MethodPut, key, data)
+	if err != nil {
+		return fmt.Errorf("failed to put s3 object %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *ObjectStore) GetObject(ctx context.Context, key string) ([]byte, error) {
+	data, err := s.do(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get s3 object %s: %w", key, err)
+	}
+	return data, nil
+}
+
+func (s *ObjectStore) DeleteObject(ctx context.Context, key string) error {
+	// S3 returns 204 No Content whether or not the object exists
+	_, err := s.do(ctx, http.MethodDelete, key, nil)
+	if err != nil && !errors.Is(err, models.ErrNotFound) {
+		return fmt.Errorf("failed to delete s3 object %s: %w", key, err)
+	}
+	return nil
+}
+
+// do sends a request for the object at key, returning the response body
+func (s *ObjectStore) do(
+	ctx context.Context,
+	method string,
+	key string,
+	body []byte,
+) ([]byte, error) {
+	u, err := url.Parse(s.endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint: %w", err)
+	}
+	objectPath := "/" + s.bucket + "/" + s.prefix + key
+	rawPath := strings.TrimSuffix(u.EscapedPath(), "/") + escapePath(objectPath)
+	u.Path = strings.TrimSuffix(u.Path, "/") + objectPath
+	u.RawPath = rawPath
+
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+	llms.SignAWSRequestV4(req, body, s.creds, s.region, service, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, models.NewNotFoundError("s3 object " + key)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("s3 returned status %d: %s", resp.StatusCode, respBody)
+	}
+
+	return respBody, nil
+}
+
+// escapePath encodes each segment of an object path as Signature Version 4 requires, leaving
+// the slashes separating them
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = llms.AWSURIEncode(segment)
+	}
+	return strings.Join(segments, "/")
+}
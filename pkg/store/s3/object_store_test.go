@@ -0,0 +1,79 @@
+package s3
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+)
+
+func TestObjectStore(t *testing.T) {
+	objects := make(map[string][]byte)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.True(
+			t,
+			strings.HasPrefix(
+				r.Header.Get("Authorization"),
+				"AWS4-HMAC-SHA256 Credential=key-id/",
+			),
+		)
+		assert.NotEmpty(t, r.Header.Get("X-Amz-Content-Sha256"))
+
+		key := r.URL.EscapedPath()
+		switch r.Method {
+		case http.MethodPut:
+			body, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			objects[key] = body
+		case http.MethodGet:
+			data, ok := objects[key]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write(data)
+		case http.MethodDelete:
+			delete(objects, key)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	objectStore, err := NewObjectStore(&config.S3Config{
+		Endpoint:        server.URL,
+		Region:          "us-east-1",
+		Bucket:          "bucket",
+		Prefix:          "zep/",
+		AccessKeyID:     "key-id",
+		SecretAccessKey: "secret",
+	})
+	require.NoError(t, err)
+
+	const key = "session:1/1.jsonl.gz"
+	require.NoError(t, objectStore.PutObject(context.Background(), key, []byte("data")))
+	assert.Contains(t, objects, "/bucket/zep/session%3A1/1.jsonl.gz")
+
+	data, err := objectStore.GetObject(context.Background(), key)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), data)
+
+	require.NoError(t, objectStore.DeleteObject(context.Background(), key))
+	_, err = objectStore.GetObject(context.Background(), key)
+	assert.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestNewObjectStore_InvalidConfig(t *testing.T) {
+	_, err := NewObjectStore(&config.S3Config{Region: "us-east-1"})
+	assert.ErrorContains(t, err, "bucket must be set")
+
+	_, err = NewObjectStore(&config.S3Config{Bucket: "bucket"})
+	assert.ErrorContains(t, err, "region must be set")
+}
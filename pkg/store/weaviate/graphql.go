@@ -0,0 +1,64 @@
+package weaviate
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var enumValueRegex = regexp.MustCompile(`^[A-Za-z]+$`)
+
+// graphQLValue encodes a JSON-shaped filter as a GraphQL input value. Object keys are written
+// unquoted, and the values of operator keys are written as enum values. Keys and enum values
+// are validated so that the filter cannot alter the surrounding query.
+func graphQLValue(key string, v interface{}) (string, error) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			if !propertyNameRegex.MatchString(k) {
+				return "", fmt.Errorf("invalid filter key: %q", k)
+			}
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		fields := make([]string, len(keys))
+		for i, k := range keys {
+			value, err := graphQLValue(k, v[k])
+			if err != nil {
+				return "", err
+			}
+			fields[i] = k + ": " + value
+		}
+		return "{" + strings.Join(fields, ", ") + "}", nil
+	case []interface{}:
+		values := make([]string, len(v))
+		for i, e := range v {
+			value, err := graphQLValue(key, e)
+			if err != nil {
+				return "", err
+			}
+			values[i] = value
+		}
+		return "[" + strings.Join(values, ", ") + "]", nil
+	case string:
+		if key == "operator" {
+			if !enumValueRegex.MatchString(v) {
+				return "", fmt.Errorf("invalid filter operator: %q", v)
+			}
+			return v, nil
+		}
+	case float64, float32, int, int64, bool, nil:
+	default:
+		return "", fmt.Errorf("unsupported filter value type %T", v)
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
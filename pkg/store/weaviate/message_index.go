@@ -0,0 +1,423 @@
+package weaviate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/llms"
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/store"
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultClass           = "ZepMessage"
+	requestTimeout         = 30 * time.Second
+	maxRetryAttempts       = 3
+	embeddingsPageSize     = 256
+	sessionIDProperty      = "session_id"
+	contentProperty        = "content"
+	metadataPropertyPrefix = "metadata_"
+)
+
+var (
+	classNameRegex    = regexp.MustCompile(`^[A-Z][_0-9A-Za-z]*$`)
+	propertyNameRegex = regexp.MustCompile(`^[_A-Za-z][_0-9A-Za-z]*$`)
+)
+
+var _ models.FilteredMessageVectorIndex = (*MessageIndex)(nil)
+
+// MessageIndex is a MessageVectorIndex backed by a Weaviate class. Objects are keyed by
+// message UUID and carry the session ID, the message content, and the message's top-level
+// scalar metadata as metadata_<key> properties, so that native where filters may be
+// passed through to searches. The metadata properties are added by Weaviate's auto-schema.
+type MessageIndex struct {
+	baseURL string
+	apiKey  string
+	class   string
+	client  *http.Client
+}
+
+// NewMessageIndex returns a MessageIndex for the configured class, creating the class if it
+// doesn't exist.
+func NewMessageIndex(ctx context.Context, cfg *config.WeaviateConfig) (*MessageIndex, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("vector_store.weaviate.url must be set")
+	}
+	if _, err := url.ParseRequestURI(cfg.URL); err != nil {
+		return nil, fmt.Errorf("invalid vector_store.weaviate.url: %w", err)
+	}
+
+	class := cfg.Class
+	if class == "" {
+		class = DefaultClass
+	}
+	if !classNameRegex.MatchString(class) {
+		return nil, fmt.Errorf("invalid vector_store.weaviate.class: %s", class)
+	}
+
+	idx := &MessageIndex{
+		baseURL: strings.TrimSuffix(cfg.URL, "/"),
+		apiKey:  cfg.APIKey,
+		class:   class,
+		client:  llms.NewRetryableHTTPClient(maxRetryAttempts, requestTimeout),
+	}
+
+	if err := idx.ensureClass(ctx); err != nil {
+		return nil, err
+	}
+
+	return idx, nil
+}
+
+type object struct {
+	Class      string                 `json:"class"`
+	ID         uuid.UUID              `json:"id"`
+	Vector     []float32              `json:"vector"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+func (idx *MessageIndex) PutMessageEmbeddings(
+	ctx context.Context,
+	sessionID string,
+	embeddings []models.TextData,
+) error {
+	if len(embeddings) == 0 {
+		return store.NewStorageError("no embeddings received", nil)
+	}
+
+	objects := make([]object, len(embeddings))
+	for i, e := range embeddings {
+		properties := map[string]interface{}{
+			sessionIDProperty: sessionID,
+			contentProperty:   e.Text,
+		}
+		for k, v := range e.Metadata {
+			if !propertyNameRegex.MatchString(k) {
+				continue
+			}
+			switch v.(type) {
+			case string, float64, float32, int, int64, bool:
+				properties[metadataPropertyPrefix+k] = v
+			}
+		}
+		objects[i] = object{
+			Class:      idx.class,
+			ID:         e.TextUUID,
+			Vector:     e.Embedding,
+			Properties: properties,
+		}
+	}
+
+	var resp []struct {
+		ID     uuid.UUID `json:"id"`
+		Result struct {
+			Errors *struct {
+				Error []struct {
+					Message string `json:"message"`
+				} `json:"error"`
+			} `json:"errors"`
+		} `json:"result"`
+	}
+	err := idx.do(
+		ctx,
+		http.MethodPost,
+		"/v1/batch/objects",
+		map[string]interface{}{"objects": objects},
+		&resp,
+	)
+	if err != nil {
+		return store.NewStorageError("failed to put weaviate objects", err)
+	}
+	for _, r := range resp {
+		if r.Result.Errors != nil && len(r.Result.Errors.Error) > 0 {
+			return store.NewStorageError(
+				fmt.Sprintf(
+					"failed to put weaviate object %s: %s",
+					r.ID,
+					r.Result.Errors.Error[0].Message,
+				),
+				nil,
+			)
+		}
+	}
+
+	return nil
+}
+
+type additional struct {
+	ID       uuid.UUID `json:"id"`
+	Distance float64   `json:"distance"`
+	Vector   []float32 `json:"vector"`
+}
+
+func (idx *MessageIndex) GetMessageEmbeddings(
+	ctx context.Context,
+	sessionID string,
+) ([]models.TextData, error) {
+	where, err := graphQLValue("", sessionFilter(sessionID))
+	if err != nil {
+		return nil, store.NewStorageError("failed to build weaviate filter", err)
+	}
+
+	var embeddings []models.TextData
+	for offset := 0; ; offset += embeddingsPageSize {
+		query := fmt.Sprintf(
+			"{ Get { %s(where: %s, limit: %d, offset: %d) { _additional { id vector } } } }",
+			idx.class,
+			where,
+			embeddingsPageSize,
+			offset,
+		)
+		objects, err := idx.get(ctx, query)
+		if err != nil {
+			return nil, store.NewStorageError("failed to get weaviate objects", err)
+		}
+
+		for _, o := range objects {
+			embeddings = append(embeddings, models.TextData{
+				TextUUID:  o.ID,
+				Embedding: o.Vector,
+			})
+		}
+
+		if len(objects) < embeddingsPageSize {
+			break
+		}
+	}
+
+	return embeddings, nil
+}
+
+func (idx *MessageIndex) SearchMessageEmbeddings(
+	ctx context.Context,
+	sessionID string,
+	embedding []float32,
+	limit int,
+) ([]models.VectorIndexResult, error) {
+	return idx.SearchMessageEmbeddingsFiltered(ctx, sessionID, embedding, limit, nil)
+}
+
+// SearchMessageEmbeddingsFiltered searches using a Weaviate where filter, in the same shape
+// as the filters accepted by Weaviate's REST API, e.g.
+// {"path": ["metadata_foo"], "operator": "Equal", "valueText": "bar"}.
+func (idx *MessageIndex) SearchMessageEmbeddingsFiltered(
+	ctx context.Context,
+	sessionID string,
+	embedding []float32,
+	limit int,
+	filter map[string]interface{},
+) ([]models.VectorIndexResult, error) {
+	whereFilter := sessionFilter(sessionID)
+	if len(filter) > 0 {
+		whereFilter = map[string]interface{}{
+			"operator": "And",
+			"operands": []interface{}{whereFilter, filter},
+		}
+	}
+	where, err := graphQLValue("", whereFilter)
+	if err != nil {
+		return nil, models.NewBadRequestError("invalid vector_filter: " + err.Error())
+	}
+
+	vector, err := json.Marshal(embedding)
+	if err != nil {
+		return nil, store.NewStorageError("failed to marshal query vector", err)
+	}
+
+	query := fmt.Sprintf(
+		"{ Get { %s(nearVector: {vector: %s}, where: %s, limit: %d) "+
+			"{ _additional { id distance vector } } } }",
+		idx.class,
+		vector,
+		where,
+		limit,
+	)
+	objects, err := idx.get(ctx, query)
+	if err != nil {
+		return nil, store.NewStorageError("failed to search weaviate objects", err)
+	}
+
+	results := make([]models.VectorIndexResult, len(objects))
+	for i, o := range objects {
+		results[i] = models.VectorIndexResult{
+			UUID: o.ID,
+			// the dot distance is the negative dot product
+			Score:     -o.Distance,
+			Embedding: o.Vector,
+		}
+	}
+
+	return results, nil
+}
+
+func (idx *MessageIndex) DeleteSessionEmbeddings(ctx context.Context, sessionID string) error {
+	err := idx.do(ctx, http.MethodDelete, "/v1/batch/objects", map[string]interface{}{
+		"match": map[string]interface{}{
+			"class": idx.class,
+			"where": sessionFilter(sessionID),
+		},
+	}, nil)
+	if err != nil {
+		return store.NewStorageError("failed to delete weaviate objects", err)
+	}
+
+	return nil
+}
+
+// DeleteMessageEmbedding deletes a message's object. Objects are keyed by message UUID.
+func (idx *MessageIndex) DeleteMessageEmbedding(
+	ctx context.Context,
+	_ string,
+	messageUUID uuid.UUID,
+) error {
+	path := "/v1/objects/" + url.PathEscape(idx.class) + "/" + messageUUID.String()
+	err := idx.do(ctx, http.MethodDelete, path, nil, nil)
+	if err != nil && !errors.Is(err, models.ErrNotFound) {
+		return store.NewStorageError("failed to delete weaviate object", err)
+	}
+
+	return nil
+}
+
+// ensureClass creates the class if it doesn't exist. Vectors are supplied by Zep, and dot
+// product distance matches pgvector's inner product search.
+func (idx *MessageIndex) ensureClass(ctx context.Context) error {
+	err := idx.do(ctx, http.MethodGet, "/v1/schema/"+url.PathEscape(idx.class), nil, nil)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, models.ErrNotFound) {
+		return fmt.Errorf("failed to get weaviate class: %w", err)
+	}
+
+	err = idx.do(ctx, http.MethodPost, "/v1/schema", map[string]interface{}{
+		"class":      idx.class,
+		"vectorizer": "none",
+		"vectorIndexConfig": map[string]interface{}{
+			"distance": "dot",
+		},
+		"properties": []map[string]interface{}{
+			{
+				"name":         sessionIDProperty,
+				"dataType":     []string{"text"},
+				"tokenization": "field",
+			},
+			{
+				"name":     contentProperty,
+				"dataType": []string{"text"},
+			},
+		},
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create weaviate class: %w", err)
+	}
+
+	return nil
+}
+
+func sessionFilter(sessionID string) map[string]interface{} {
+	return map[string]interface{}{
+		"path":      []interface{}{sessionIDProperty},
+		"operator":  "Equal",
+		"valueText": sessionID,
+	}
+}
+
+// get runs a GraphQL Get query, returning the _additional fields of the class's objects.
+func (idx *MessageIndex) get(ctx context.Context, query string) ([]additional, error) {
+	var resp struct {
+		Data struct {
+			Get map[string][]struct {
+				Additional additional `json:"_additional"`
+			} `json:"Get"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	err := idx.do(
+		ctx,
+		http.MethodPost,
+		"/v1/graphql",
+		map[string]interface{}{"query": query},
+		&resp,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Errors) > 0 {
+		return nil, fmt.Errorf("weaviate query failed: %s", resp.Errors[0].Message)
+	}
+
+	objects := resp.Data.Get[idx.class]
+	results := make([]additional, len(objects))
+	for i, o := range objects {
+		results[i] = o.Additional
+	}
+
+	return results, nil
+}
+
+// do sends a request to the Weaviate endpoint at path, decoding the response into result if
+// it is not nil. A 404 response returns a models.ErrNotFound.
+func (idx *MessageIndex) do(
+	ctx context.Context,
+	method string,
+	path string,
+	body interface{},
+	result interface{},
+) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reqBody = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, idx.baseURL+path, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if idx.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+idx.apiKey)
+	}
+
+	resp, err := idx.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return models.NewNotFoundError("weaviate " + path)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("weaviate returned status %d: %s", resp.StatusCode, respBody)
+	}
+
+	if result != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, result); err != nil {
+			return fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,218 @@
+package weaviate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeWeaviate struct {
+	classExists bool
+	requests    []string
+	bodies      map[string]map[string]interface{}
+	getResponse []map[string]interface{}
+}
+
+func newFakeWeaviate(t *testing.T, f *fakeWeaviate) *httptest.Server {
+	f.bodies = make(map[string]map[string]interface{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Method + " " + r.URL.Path
+		f.requests = append(f.requests, key)
+
+		if r.ContentLength > 0 {
+			var body map[string]interface{}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			f.bodies[key] = body
+		}
+
+		switch key {
+		case "GET /v1/schema/Test":
+			if !f.classExists {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte(`{"class":"Test"}`))
+		case "POST /v1/graphql":
+			assert.NoError(t, json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"Get": map[string]interface{}{"Test": f.getResponse},
+				},
+			}))
+		case "POST /v1/batch/objects":
+			_, _ = w.Write([]byte(`[{"result":{}}]`))
+		default:
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNewMessageIndex(t *testing.T) {
+	t.Run("creates missing class", func(t *testing.T) {
+		f := &fakeWeaviate{}
+		server := newFakeWeaviate(t, f)
+
+		_, err := NewMessageIndex(context.Background(), &config.WeaviateConfig{
+			URL:   server.URL,
+			Class: "Test",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"GET /v1/schema/Test", "POST /v1/schema"}, f.requests)
+		assert.Equal(t, "none", f.bodies["POST /v1/schema"]["vectorizer"])
+	})
+
+	t.Run("accepts existing class", func(t *testing.T) {
+		f := &fakeWeaviate{classExists: true}
+		server := newFakeWeaviate(t, f)
+
+		_, err := NewMessageIndex(context.Background(), &config.WeaviateConfig{
+			URL:   server.URL,
+			Class: "Test",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"GET /v1/schema/Test"}, f.requests)
+	})
+
+	t.Run("rejects invalid class", func(t *testing.T) {
+		_, err := NewMessageIndex(context.Background(), &config.WeaviateConfig{
+			URL:   "http://localhost:8080",
+			Class: "test) { id } }",
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestMessageIndexSearchMessageEmbeddingsFiltered(t *testing.T) {
+	messageUUID := uuid.New()
+	f := &fakeWeaviate{
+		classExists: true,
+		getResponse: []map[string]interface{}{
+			{"_additional": map[string]interface{}{
+				"id":       messageUUID,
+				"distance": -0.75,
+				"vector":   []float32{1, 0},
+			}},
+		},
+	}
+	server := newFakeWeaviate(t, f)
+
+	idx, err := NewMessageIndex(context.Background(), &config.WeaviateConfig{
+		URL:   server.URL,
+		Class: "Test",
+	})
+	require.NoError(t, err)
+
+	results, err := idx.SearchMessageEmbeddingsFiltered(
+		context.Background(),
+		"session",
+		[]float32{1, 0},
+		5,
+		map[string]interface{}{
+			"path":      []interface{}{"metadata_foo"},
+			"operator":  "Equal",
+			"valueText": "bar",
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []models.VectorIndexResult{
+		{UUID: messageUUID, Score: 0.75, Embedding: []float32{1, 0}},
+	}, results)
+
+	query := f.bodies["POST /v1/graphql"]["query"].(string)
+	assert.Contains(t, query, "nearVector: {vector: [1,0]}")
+	assert.Contains(t, query, `{operator: Equal, path: ["session_id"], valueText: "session"}`)
+	assert.Contains(t, query, `{operator: Equal, path: ["metadata_foo"], valueText: "bar"}`)
+	assert.Contains(t, query, "limit: 5")
+
+	_, err = idx.SearchMessageEmbeddingsFiltered(
+		context.Background(),
+		"session",
+		[]float32{1, 0},
+		5,
+		map[string]interface{}{"operator": "Equal) { id } }"},
+	)
+	assert.ErrorIs(t, err, models.ErrBadRequest)
+}
+
+func TestMessageIndexPutMessageEmbeddings(t *testing.T) {
+	f := &fakeWeaviate{classExists: true}
+	server := newFakeWeaviate(t, f)
+
+	idx, err := NewMessageIndex(context.Background(), &config.WeaviateConfig{
+		URL:   server.URL,
+		Class: "Test",
+	})
+	require.NoError(t, err)
+
+	err = idx.PutMessageEmbeddings(context.Background(), "session", []models.TextData{
+		{
+			TextUUID:  uuid.New(),
+			Text:      "content",
+			Embedding: []float32{1, 0},
+			Metadata: map[string]interface{}{
+				"foo":     "bar",
+				"system":  map[string]interface{}{"intent": "greeting"},
+				"bad key": "skipped",
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	objects := f.bodies["POST /v1/batch/objects"]["objects"].([]interface{})
+	properties := objects[0].(map[string]interface{})["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"session_id":   "session",
+		"content":      "content",
+		"metadata_foo": "bar",
+	}, properties)
+}
+
+func TestGraphQLValue(t *testing.T) {
+	value, err := graphQLValue("", map[string]interface{}{
+		"operator": "And",
+		"operands": []interface{}{
+			map[string]interface{}{
+				"path":         []interface{}{"metadata_count"},
+				"operator":     "GreaterThan",
+				"valueInt":     float64(2),
+				"valueText":    `quote " and } brace`,
+				"valueBoolean": true,
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		`{operands: [{operator: GreaterThan, path: ["metadata_count"], valueBoolean: true, `+
+			`valueInt: 2, valueText: "quote \" and } brace"}], operator: And}`,
+		value,
+	)
+
+	_, err = graphQLValue("", map[string]interface{}{"bad key": "value"})
+	assert.Error(t, err)
+}
+
+func TestMessageIndexDeleteMessageEmbedding(t *testing.T) {
+	f := &fakeWeaviate{classExists: true}
+	server := newFakeWeaviate(t, f)
+
+	idx, err := NewMessageIndex(context.Background(), &config.WeaviateConfig{
+		URL:   server.URL,
+		Class: "Test",
+	})
+	require.NoError(t, err)
+
+	messageUUID := uuid.New()
+	err = idx.DeleteMessageEmbedding(context.Background(), "session", messageUUID)
+	require.NoError(t, err)
+	assert.Contains(t, f.requests, "DELETE /v1/objects/Test/"+messageUUID.String())
+}
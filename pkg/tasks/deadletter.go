@@ -0,0 +1,80 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+
+	"github.com/getzep/zep/pkg/models"
+)
+
+const deadLetterTimeout = 10 * time.Second
+
+// DeadLetterMiddleware stores messages whose handler fails, after the retries of inner
+// middleware, in deadLetters, and acks them. If a message can't be stored, the handler's
+// error is returned so that the message is redelivered rather than lost.
+func DeadLetterMiddleware(deadLetters models.DeadLetterStore) message.HandlerMiddleware {
+	return func(h message.HandlerFunc) message.HandlerFunc {
+		return func(msg *message.Message) ([]*message.Message, error) {
+			produced, err := h(msg)
+			if err == nil {
+				return produced, nil
+			}
+
+			metadata := make(map[string]string, len(msg.Metadata))
+			for k, v := range msg.Metadata {
+				metadata[k] = v
+			}
+			deadLetter := &models.DeadLetter{
+				MessageUUID: msg.UUID,
+				Topic:       models.TaskTopic(message.SubscribeTopicFromCtx(msg.Context())),
+				Handler:     message.HandlerNameFromCtx(msg.Context()),
+				Metadata:    metadata,
+				Payload:     []byte(msg.Payload),
+				Error:       err.Error(),
+			}
+
+			// the message's context may already be done
+			ctx, cancel := context.WithTimeout(context.Background(), deadLetterTimeout)
+			defer cancel()
+			if putErr := deadLetters.PutDeadLetter(ctx, deadLetter); putErr != nil {
+				return produced, fmt.Errorf(
+					"failed to store dead letter: %v (handler error: %w)",
+					putErr,
+					err,
+				)
+			}
+
+			log.Warnf(
+				"task message %s on topic %s failed and was stored as dead letter %s: %v",
+				msg.UUID,
+				deadLetter.Topic,
+				deadLetter.UUID,
+				err,
+			)
+
+			return produced, nil
+		}
+	}
+}
+
+// RetryDeadLetter republishes a dead letter to its topic and deletes it
+func RetryDeadLetter(
+	ctx context.Context,
+	appState *models.AppState,
+	deadLetter *models.DeadLetter,
+) error {
+	err := appState.TaskPublisher.Publish(
+		ctx,
+		deadLetter.Topic,
+		deadLetter.Metadata,
+		deadLetter.Payload,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to republish dead letter: %w", err)
+	}
+
+	return appState.DeadLetterStore.DeleteDeadLetter(ctx, deadLetter.UUID)
+}
@@ -0,0 +1,75 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/pkg/models"
+)
+
+type fakeDeadLetterStore struct {
+	models.DeadLetterStore
+	deadLetters []*models.DeadLetter
+	err         error
+}
+
+func (s *fakeDeadLetterStore) PutDeadLetter(_ context.Context, d *models.DeadLetter) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.deadLetters = append(s.deadLetters, d)
+	return nil
+}
+
+func TestDeadLetterMiddleware(t *testing.T) {
+	handlerErr := errors.New("extractor failed")
+	failingHandler := func(msg *message.Message) ([]*message.Message, error) {
+		return nil, handlerErr
+	}
+
+	newMsg := func() *message.Message {
+		msg := message.NewMessage(uuid.NewString(), []byte(`[{"uuid":"1"}]`))
+		msg.Metadata.Set("session_id", "session")
+		return msg
+	}
+
+	t.Run("stores failed messages", func(t *testing.T) {
+		deadLetters := &fakeDeadLetterStore{}
+		msg := newMsg()
+
+		_, err := DeadLetterMiddleware(deadLetters)(failingHandler)(msg)
+		require.NoError(t, err)
+		require.Len(t, deadLetters.deadLetters, 1)
+		assert.Equal(t, &models.DeadLetter{
+			MessageUUID: msg.UUID,
+			Metadata:    map[string]string{"session_id": "session"},
+			Payload:     []byte(`[{"uuid":"1"}]`),
+			Error:       "extractor failed",
+		}, deadLetters.deadLetters[0])
+	})
+
+	t.Run("returns the handler error if the store fails", func(t *testing.T) {
+		deadLetters := &fakeDeadLetterStore{err: errors.New("db down")}
+
+		_, err := DeadLetterMiddleware(deadLetters)(failingHandler)(newMsg())
+		assert.ErrorIs(t, err, handlerErr)
+		assert.ErrorContains(t, err, "db down")
+	})
+
+	t.Run("passes through successful messages", func(t *testing.T) {
+		deadLetters := &fakeDeadLetterStore{}
+		handler := func(msg *message.Message) ([]*message.Message, error) {
+			return nil, nil
+		}
+
+		_, err := DeadLetterMiddleware(deadLetters)(handler)(newMsg())
+		assert.NoError(t, err)
+		assert.Empty(t, deadLetters.deadLetters)
+	})
+}
@@ -92,7 +92,20 @@ func (dt *DocumentEmbedderTask) Process(
 		texts[i] = r.Content
 	}
 
-	model, err := llms.GetEmbeddingModel(dt.appState, docType)
+	collection, err := dt.appState.DocumentStore.GetCollection(ctx, collectionName)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			log.Warnf(
+				"DocumentEmbedderTask GetCollection not found. Was the collection deleted? %v",
+				err,
+			)
+			// Don't error out
+			return nil
+		}
+		return fmt.Errorf("DocumentEmbedderTask get collection failed: %w", err)
+	}
+
+	model, err := llms.GetCollectionEmbeddingModel(dt.appState, &collection)
 	if err != nil {
 		return fmt.Errorf("DocumentEmbedderTask get embedding model failed: %w", err)
 	}
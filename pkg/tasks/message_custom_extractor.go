@@ -0,0 +1,258 @@
+package tasks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/google/uuid"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+)
+
+const (
+	DefaultCustomExtractorTimeout    = 10
+	DefaultCustomExtractorMaxRetries = 3
+)
+
+const (
+	CustomExtractorSignatureHeader = "X-Zep-Signature"
+	CustomExtractorTimestampHeader = "X-Zep-Timestamp"
+)
+
+var _ models.Task = &MessageCustomExtractorTask{}
+
+func NewMessageCustomExtractorTask(appState *models.AppState) *MessageCustomExtractorTask {
+	return &MessageCustomExtractorTask{
+		BaseTask{
+			appState: appState,
+		},
+	}
+}
+
+// MessageCustomExtractorTask sends new messages to each configured custom extractor, and
+// merges the metadata they return into the messages
+type MessageCustomExtractorTask struct {
+	BaseTask
+}
+
+func (ct *MessageCustomExtractorTask) Execute(
+	ctx context.Context,
+	msg *message.Message,
+) error {
+	ctx, done := context.WithTimeout(ctx, TaskTimeout*time.Second)
+	defer done()
+
+	sessionID := msg.Metadata.Get("session_id")
+	if sessionID == "" {
+		return errors.New("MessageCustomExtractorTask session_id is empty")
+	}
+
+	log.Debugf("MessageCustomExtractorTask called for session %s", sessionID)
+
+	messages, err := messageTaskPayloadToMessages(ctx, ct.appState, msg)
+	if err != nil {
+		return fmt.Errorf(
+			"MessageCustomExtractorTask messageTaskPayloadToMessages failed: %w",
+			err,
+		)
+	}
+
+	if len(messages) == 0 {
+		return fmt.Errorf(
+			"MessageCustomExtractorTask messageTaskPayloadToMessages returned no messages",
+		)
+	}
+
+	// A failed extractor is logged rather than failing the task, as requests are already
+	// retried, and retrying the task would call the extractors that succeeded again
+	for i := range ct.appState.Config.Extractors.Messages.Custom {
+		extractor := &ct.appState.Config.Extractors.Messages.Custom[i]
+		if err := ct.runExtractor(ctx, extractor, sessionID, messages); err != nil {
+			log.Errorf("MessageCustomExtractorTask extractor %s failed: %v", extractor.Name, err)
+		}
+	}
+
+	msg.Ack()
+
+	return nil
+}
+
+func (ct *MessageCustomExtractorTask) runExtractor(
+	ctx context.Context,
+	extractor *config.CustomExtractorConfig,
+	sessionID string,
+	messages []models.Message,
+) error {
+	results, err := callCustomExtractor(ctx, extractor, sessionID, messages)
+	if err != nil {
+		return err
+	}
+
+	// only the metadata of messages that were sent is merged
+	sent := make(map[uuid.UUID]bool, len(messages))
+	for _, m := range messages {
+		sent[m.UUID] = true
+	}
+	var metadataMessages []models.Message
+	for _, r := range results {
+		if !sent[r.UUID] || len(r.Metadata) == 0 {
+			continue
+		}
+		metadataMessages = append(metadataMessages, models.Message{
+			UUID:     r.UUID,
+			Metadata: r.Metadata,
+		})
+	}
+	if len(metadataMessages) == 0 {
+		return nil
+	}
+
+	// Custom extractors are unprivileged, so can't write to the system metadata
+	err = ct.appState.MemoryStore.PutMessageMetadata(
+		ctx,
+		ct.appState,
+		sessionID,
+		metadataMessages,
+		false,
+	)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			log.Warnf(
+				"MessageCustomExtractorTask PutMessageMetadata not found. " +
+					"Were the records deleted?",
+			)
+			return nil
+		}
+		return fmt.Errorf("failed to put message metadata: %w", err)
+	}
+
+	publishExtractorResult(
+		ct.appState,
+		sessionID,
+		models.MessageCustomTopic,
+		metadataMessages,
+		nil,
+	)
+
+	return nil
+}
+
+// callCustomExtractor POSTs messages to a custom extractor. If the extractor has a secret,
+// the request is signed and the response's signature is verified.
+func callCustomExtractor(
+	ctx context.Context,
+	extractor *config.CustomExtractorConfig,
+	sessionID string,
+	messages []models.Message,
+) ([]models.CustomExtractorResult, error) {
+	body, err := json.Marshal(models.CustomExtractorRequest{
+		Extractor: extractor.Name,
+		SessionID: sessionID,
+		Messages:  messages,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	timeout := extractor.Timeout
+	if timeout <= 0 {
+		timeout = DefaultCustomExtractorTimeout
+	}
+	maxRetries := extractor.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = DefaultCustomExtractorMaxRetries
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		extractor.URL,
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	if extractor.Secret != "" {
+		req.Header.Set(CustomExtractorTimestampHeader, timestamp)
+		req.Header.Set(
+			CustomExtractorSignatureHeader,
+			SignCustomExtractorPayload(extractor.Secret, timestamp, body),
+		)
+	}
+
+	client := NewRetryableHTTPClient(maxRetries, time.Duration(timeout)*time.Second)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, respBody)
+	}
+
+	if extractor.Secret != "" {
+		expected := SignCustomExtractorPayload(extractor.Secret, timestamp, respBody)
+		signature := resp.Header.Get(CustomExtractorSignatureHeader)
+		if !hmac.Equal([]byte(signature), []byte(expected)) {
+			return nil, errors.New("response signature is missing or invalid")
+		}
+	}
+
+	var response models.CustomExtractorResponse
+	if err := json.Unmarshal(respBody, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return response.Messages, nil
+}
+
+// ValidateCustomExtractors checks that custom extractors have unique names and valid URLs
+func ValidateCustomExtractors(extractors []config.CustomExtractorConfig) error {
+	names := make(map[string]bool, len(extractors))
+	for _, e := range extractors {
+		if e.Name == "" {
+			return errors.New("custom extractors must have a name")
+		}
+		if names[e.Name] {
+			return fmt.Errorf("duplicate custom extractor name: %s", e.Name)
+		}
+		names[e.Name] = true
+
+		u, err := url.Parse(e.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("custom extractor %s has an invalid url: %q", e.Name, e.URL)
+		}
+	}
+	return nil
+}
+
+// SignCustomExtractorPayload returns the signature of a custom extractor request or response
+// body: "sha256=" followed by the hex HMAC-SHA256 of the timestamp, a ".", and the body
+func SignCustomExtractorPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
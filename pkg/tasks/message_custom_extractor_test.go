@@ -0,0 +1,107 @@
+package tasks
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+)
+
+func TestCallCustomExtractor(t *testing.T) {
+	const secret = "test-secret"
+	messages := []models.Message{
+		{UUID: uuid.New(), Role: "user", Content: "Hello"},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		timestamp := r.Header.Get(CustomExtractorTimestampHeader)
+		assert.NotEmpty(t, timestamp)
+		assert.Equal(
+			t,
+			SignCustomExtractorPayload(secret, timestamp, body),
+			r.Header.Get(CustomExtractorSignatureHeader),
+		)
+
+		var request models.CustomExtractorRequest
+		require.NoError(t, json.Unmarshal(body, &request))
+		assert.Equal(t, "topics", request.Extractor)
+		assert.Equal(t, "session", request.SessionID)
+		require.Len(t, request.Messages, 1)
+		assert.Equal(t, "Hello", request.Messages[0].Content)
+
+		respBody, err := json.Marshal(models.CustomExtractorResponse{
+			Messages: []models.CustomExtractorResult{{
+				UUID:     request.Messages[0].UUID,
+				Metadata: map[string]interface{}{"topic": "greeting"},
+			}},
+		})
+		require.NoError(t, err)
+		w.Header().Set(
+			CustomExtractorSignatureHeader,
+			SignCustomExtractorPayload(secret, timestamp, respBody),
+		)
+		_, _ = w.Write(respBody)
+	}))
+	defer server.Close()
+
+	extractor := &config.CustomExtractorConfig{Name: "topics", URL: server.URL, Secret: secret}
+	results, err := callCustomExtractor(testCtx, extractor, "session", messages)
+	require.NoError(t, err)
+	assert.Equal(t, []models.CustomExtractorResult{{
+		UUID:     messages[0].UUID,
+		Metadata: map[string]interface{}{"topic": "greeting"},
+	}}, results)
+}
+
+func TestCallCustomExtractor_InvalidResponseSignature(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(CustomExtractorSignatureHeader, "sha256=forged")
+		_, _ = w.Write([]byte(`{"messages":[]}`))
+	}))
+	defer server.Close()
+
+	extractor := &config.CustomExtractorConfig{Name: "topics", URL: server.URL, Secret: "s"}
+	_, err := callCustomExtractor(testCtx, extractor, "session", []models.Message{
+		{UUID: uuid.New(), Content: "Hello"},
+	})
+	assert.ErrorContains(t, err, "response signature is missing or invalid")
+}
+
+func TestValidateCustomExtractors(t *testing.T) {
+	valid := config.CustomExtractorConfig{Name: "topics", URL: "https://example.com/extract"}
+	assert.NoError(t, ValidateCustomExtractors([]config.CustomExtractorConfig{valid}))
+
+	tests := []struct {
+		name       string
+		extractors []config.CustomExtractorConfig
+		err        string
+	}{
+		{"no name", []config.CustomExtractorConfig{{URL: valid.URL}}, "must have a name"},
+		{
+			"duplicate name",
+			[]config.CustomExtractorConfig{valid, valid},
+			"duplicate custom extractor name",
+		},
+		{
+			"invalid url",
+			[]config.CustomExtractorConfig{{Name: "topics", URL: "example.com"}},
+			"invalid url",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, ValidateCustomExtractors(tt.extractors), tt.err)
+		})
+	}
+}
@@ -8,8 +8,10 @@ import (
 	"time"
 
 	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/getzep/zep/internal"
 	"github.com/getzep/zep/pkg/llms"
 	"github.com/getzep/zep/pkg/models"
+	"github.com/sirupsen/logrus"
 )
 
 var _ models.Task = &MessageEmbedderTask{}
@@ -37,7 +39,10 @@ func (t *MessageEmbedderTask) Execute(
 	if sessionID == "" {
 		return fmt.Errorf("MessageEmbedderTask session_id is empty")
 	}
-	log.Debugf("MessageEmbedderTask called for session %s", sessionID)
+	internal.LoggerFromContext(ctx).WithFields(logrus.Fields{
+		"task":       "MessageEmbedderTask",
+		"session_id": sessionID,
+	}).Debug("called")
 
 	messages, err := messageTaskPayloadToMessages(ctx, t.appState, msg)
 	if err != nil {
@@ -80,7 +85,9 @@ func (t *MessageEmbedderTask) Process(
 	for i, r := range msgs {
 		embeddingRecords[i] = models.TextData{
 			TextUUID:  r.UUID,
+			Text:      r.Content,
 			Embedding: embeddings[i],
+			Metadata:  r.Metadata,
 		}
 	}
 	err = t.appState.MemoryStore.PutMessageEmbeddings(
@@ -91,10 +98,10 @@ func (t *MessageEmbedderTask) Process(
 	)
 	if err != nil {
 		if errors.Is(err, models.ErrNotFound) {
-			log.Warnf(
-				"MessageEmbedderTask PutMessageEmbeddings not found. Were the records deleted? %v",
-				err,
-			)
+			internal.LoggerFromContext(ctx).WithFields(logrus.Fields{
+				"task":       "MessageEmbedderTask",
+				"session_id": sessionID,
+			}).WithError(err).Warn("PutMessageEmbeddings not found. Were the records deleted?")
 			// Don't error out
 			return nil
 		}
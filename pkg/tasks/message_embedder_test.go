@@ -35,7 +35,7 @@ func TestEmbeddingExtractor_Extract_OpenAI(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Get messages that are missing embeddings using appState.MemoryStore.GetMessageEmbeddings
-	memories, err := store.GetMemory(testCtx, appState, sessionID, 0)
+	memories, err := store.GetMemory(testCtx, appState, sessionID, 0, nil)
 	assert.NoError(t, err)
 	assert.True(t, len(memories.Messages) == len(testMessages))
 
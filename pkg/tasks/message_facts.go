@@ -0,0 +1,183 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	llms2 "github.com/tmc/langchaingo/llms"
+
+	"github.com/getzep/zep/internal"
+	"github.com/getzep/zep/pkg/llms"
+	"github.com/getzep/zep/pkg/models"
+)
+
+const (
+	factsMaxTokens = 1024
+	// factsPromptLimit is the number of the user's most recently updated facts the llm is
+	// given, so that it can update them rather than repeat them
+	factsPromptLimit = 100
+)
+
+var _ models.Task = &MessageFactsTask{}
+
+func NewMessageFactsTask(appState *models.AppState) *MessageFactsTask {
+	return &MessageFactsTask{
+		BaseTask{
+			appState: appState,
+		},
+	}
+}
+
+// MessageFactsTask extracts stable facts about a session's user from new messages, and
+// stores them with the user's facts. Sessions without a user are skipped.
+type MessageFactsTask struct {
+	BaseTask
+}
+
+func (ft *MessageFactsTask) Execute(
+	ctx context.Context,
+	msg *message.Message,
+) error {
+	ctx, done := context.WithTimeout(ctx, TaskTimeout*time.Second)
+	defer done()
+
+	sessionID := msg.Metadata.Get("session_id")
+	if sessionID == "" {
+		return errors.New("MessageFactsTask session_id is empty")
+	}
+
+	log.Debugf("MessageFactsTask called for session %s", sessionID)
+
+	session, err := ft.appState.MemoryStore.GetSession(ctx, ft.appState, sessionID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			log.Warnf("MessageFactsTask session %s not found. Was it deleted?", sessionID)
+			msg.Ack()
+			return nil
+		}
+		return fmt.Errorf("MessageFactsTask failed to get session: %w", err)
+	}
+	if session.UserID == nil || *session.UserID == "" {
+		msg.Ack()
+		return nil
+	}
+	userID := *session.UserID
+
+	messages, err := messageTaskPayloadToMessages(ctx, ft.appState, msg)
+	if err != nil {
+		return fmt.Errorf("MessageFactsTask messageTaskPayloadToMessages failed: %w", err)
+	}
+
+	if len(messages) == 0 {
+		return fmt.Errorf("MessageFactsTask messageTaskPayloadToMessages returned no messages")
+	}
+
+	known, err := ft.appState.MemoryStore.GetFacts(ctx, userID, factsPromptLimit)
+	if err != nil {
+		return fmt.Errorf("MessageFactsTask failed to get facts: %w", err)
+	}
+
+	facts, err := extractFacts(
+		ctx,
+		llms.GetExtractorLLMClient(ft.appState, llms.FactsExtractor),
+		known,
+		messages,
+	)
+	if err != nil {
+		return fmt.Errorf("MessageFactsTask: %w", err)
+	}
+
+	for i := range facts {
+		facts[i].SessionID = sessionID
+	}
+
+	err = ft.appState.MemoryStore.PutFacts(ctx, userID, facts)
+	if err != nil {
+		return fmt.Errorf("MessageFactsTask failed to put facts: %w", err)
+	}
+
+	msg.Ack()
+
+	return nil
+}
+
+// extractFacts prompts llm for the new and changed facts stated in messages, given the
+// user's known facts
+func extractFacts(
+	ctx context.Context,
+	llm models.ZepLLM,
+	known []models.Fact,
+	messages []models.Message,
+) ([]models.Fact, error) {
+	data := FactsPromptTemplateData{
+		Facts:    known,
+		Messages: make([]FactsPromptMessage, len(messages)),
+	}
+	for i := range messages {
+		data.Messages[i] = FactsPromptMessage{
+			Number:  i + 1,
+			Role:    messages[i].Role,
+			Content: messages[i].Content,
+		}
+	}
+
+	prompt, err := internal.ParsePrompt(factsPromptTemplate, data)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := llm.Call(ctx, prompt, llms2.WithMaxTokens(factsMaxTokens))
+	if err != nil {
+		return nil, err
+	}
+
+	return parseFacts(response, messages)
+}
+
+// factTriple is a fact as it's returned by the llm. Message is the number of the message the
+// fact is stated in.
+type factTriple struct {
+	Subject   string `json:"subject"`
+	Predicate string `json:"predicate"`
+	Object    string `json:"object"`
+	Message   int    `json:"message"`
+}
+
+// parseFacts parses the JSON array of facts in an llm response, ignoring any text around it.
+// Facts are attributed to the message they cite, if it's one of messages.
+func parseFacts(response string, messages []models.Message) ([]models.Fact, error) {
+	start := strings.Index(response, "[")
+	end := strings.LastIndex(response, "]")
+	if start == -1 || end < start {
+		return nil, fmt.Errorf("failed to find facts in response: %s", response)
+	}
+
+	var triples []factTriple
+	if err := json.Unmarshal([]byte(response[start:end+1]), &triples); err != nil {
+		return nil, fmt.Errorf("failed to parse facts: %w", err)
+	}
+
+	facts := make([]models.Fact, 0, len(triples))
+	for _, t := range triples {
+		fact := models.Fact{
+			Subject:   strings.TrimSpace(t.Subject),
+			Predicate: strings.TrimSpace(t.Predicate),
+			Object:    strings.TrimSpace(t.Object),
+		}
+		if fact.Subject == "" || fact.Predicate == "" || fact.Object == "" {
+			continue
+		}
+		if t.Message >= 1 && t.Message <= len(messages) {
+			messageUUID := messages[t.Message-1].UUID
+			fact.MessageUUID = &messageUUID
+		}
+		facts = append(facts, fact)
+	}
+
+	return facts, nil
+}
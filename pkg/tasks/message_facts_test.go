@@ -0,0 +1,108 @@
+package tasks
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+)
+
+type fakeLLM struct {
+	response string
+	prompt   string
+}
+
+func (f *fakeLLM) Call(_ context.Context, prompt string, _ ...llms.CallOption) (string, error) {
+	f.prompt = prompt
+	return f.response, nil
+}
+
+func (f *fakeLLM) EmbedTexts(context.Context, []string) ([][]float32, error) {
+	return nil, nil
+}
+
+func (f *fakeLLM) GetTokenCount(string) (int, error) {
+	return 0, nil
+}
+
+func (f *fakeLLM) Init(context.Context, *config.Config) error {
+	return nil
+}
+
+func TestParseFacts(t *testing.T) {
+	messages := []models.Message{{UUID: uuid.New()}, {UUID: uuid.New()}}
+
+	tests := []struct {
+		name     string
+		response string
+		expected []models.Fact
+		wantErr  bool
+	}{
+		{
+			"facts",
+			`[{"subject": "user", "predicate": "lives in", "object": "Berlin", "message": 2}]`,
+			[]models.Fact{
+				{
+					Subject:     "user",
+					Predicate:   "lives in",
+					Object:      "Berlin",
+					MessageUUID: &messages[1].UUID,
+				},
+			},
+			false,
+		},
+		{
+			"surrounding text and invalid message",
+			"Here are the facts:\n```json\n" +
+				`[{"subject": " user ", "predicate": "has children", "object": "two", "message": 5}]` +
+				"\n```",
+			[]models.Fact{{Subject: "user", Predicate: "has children", Object: "two"}},
+			false,
+		},
+		{
+			"incomplete facts are dropped",
+			`[{"subject": "user", "predicate": "likes", "object": ""}]`,
+			[]models.Fact{},
+			false,
+		},
+		{"no facts", "[]", []models.Fact{}, false},
+		{"unparseable", "The user didn't state any facts.", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			facts, err := parseFacts(tt.response, messages)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, facts)
+		})
+	}
+}
+
+func TestExtractFacts(t *testing.T) {
+	messages := []models.Message{
+		{UUID: uuid.New(), Role: "user", Content: "We moved to Berlin last month."},
+	}
+	known := []models.Fact{{Subject: "user", Predicate: "lives in", Object: "Munich"}}
+	llm := &fakeLLM{
+		response: `[{"subject": "user", "predicate": "lives in", "object": "Berlin", "message": 1}]`,
+	}
+
+	facts, err := extractFacts(context.Background(), llm, known, messages)
+	require.NoError(t, err)
+
+	assert.Contains(t, llm.prompt, "user | lives in | Munich\n")
+	assert.Contains(t, llm.prompt, "[1] user: We moved to Berlin last month.\n")
+	require.Len(t, facts, 1)
+	assert.Equal(t, "Berlin", facts[0].Object)
+	assert.Equal(t, &messages[0].UUID, facts[0].MessageUUID)
+}
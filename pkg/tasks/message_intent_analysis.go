@@ -2,6 +2,7 @@ package tasks
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"regexp"
@@ -10,9 +11,10 @@ import (
 	"time"
 
 	"github.com/ThreeDotsLabs/watermill/message"
-	"github.com/tmc/langchaingo/llms"
+	llms2 "github.com/tmc/langchaingo/llms"
 
 	"github.com/getzep/zep/internal"
+	"github.com/getzep/zep/pkg/llms"
 	"github.com/getzep/zep/pkg/models"
 )
 
@@ -107,34 +109,12 @@ func (mt *MessageIntentTask) processMessage(
 	sessionID string,
 	errs chan error,
 ) {
-	// Populate the template with the message
-	data := IntentPromptTemplateData{
-		Input: message.Content,
-	}
-
-	// Create a prompt with the Message input that needs to be classified
-	prompt, err := internal.ParsePrompt(intentPromptTemplate, data)
+	intentContent, err := mt.extractIntent(ctx, appState, message.Content)
 	if err != nil {
 		errs <- fmt.Errorf("MessageIntentTask: %w", err)
 		return
 	}
 
-	// Send the populated prompt to the language model
-	intentContent, err := appState.LLMClient.Call(
-		ctx,
-		prompt,
-		llms.WithMaxTokens(intentMaxTokens),
-	)
-	if err != nil {
-		errs <- fmt.Errorf("MessageIntentTask: %w", err)
-		return
-	}
-
-	// Get the intent from the response
-	intentContent = IntentStringRegex.ReplaceAllStringFunc(intentContent, func(s string) string {
-		return ""
-	})
-
 	// if we don't have an intent, just return
 	if intentContent == "" {
 		return
@@ -165,5 +145,62 @@ func (mt *MessageIntentTask) processMessage(
 			return
 		}
 		errs <- fmt.Errorf("MessageIntentTask failed to put message metadata: %w", err)
+		return
 	}
+
+	publishExtractorResult(appState, sessionID, models.MessageIntentTopic, intentResponse, nil)
+}
+
+// extractIntent prompts the intent extractor's llm for the intent of content. Intents are
+// cached by the normalized content, if the extractor result cache is enabled.
+func (mt *MessageIntentTask) extractIntent(
+	ctx context.Context,
+	appState *models.AppState,
+	content string,
+) (string, error) {
+	llmCfg := llms.ExtractorConfig(appState.Config, llms.IntentExtractor).LLM
+	key := resultCacheKey(
+		llms.IntentExtractor,
+		llmCfg.Service+":"+llmCfg.Model,
+		normalizeUtterance(content),
+	)
+	if cached, ok := getCachedResults(ctx, appState, []string{key})[key]; ok {
+		var intentContent string
+		if err := json.Unmarshal(cached, &intentContent); err == nil {
+			return intentContent, nil
+		}
+	}
+
+	// Populate the template with the message
+	data := IntentPromptTemplateData{
+		Input: content,
+	}
+
+	// Create a prompt with the Message input that needs to be classified
+	prompt, err := internal.ParsePrompt(intentPromptTemplate, data)
+	if err != nil {
+		return "", err
+	}
+
+	// Send the populated prompt to the language model
+	llmClient := llms.GetExtractorLLMClient(appState, llms.IntentExtractor)
+	intentContent, err := llmClient.Call(
+		ctx,
+		prompt,
+		llms2.WithMaxTokens(intentMaxTokens),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	// Get the intent from the response
+	intentContent = IntentStringRegex.ReplaceAllStringFunc(intentContent, func(s string) string {
+		return ""
+	})
+
+	if result, err := json.Marshal(intentContent); err == nil {
+		putCachedResults(ctx, appState, llms.IntentExtractor, map[string]json.RawMessage{key: result})
+	}
+
+	return intentContent, nil
 }
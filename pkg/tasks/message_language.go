@@ -0,0 +1,103 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+
+	"github.com/getzep/zep/pkg/langdetect"
+	"github.com/getzep/zep/pkg/models"
+)
+
+var _ models.Task = &MessageLanguageTask{}
+
+func NewMessageLanguageTask(appState *models.AppState) *MessageLanguageTask {
+	return &MessageLanguageTask{
+		BaseTask{
+			appState: appState,
+		},
+	}
+}
+
+// MessageLanguageTask records the detected language of messages in their system metadata.
+// Detection runs in-process rather than calling an llm.
+type MessageLanguageTask struct {
+	BaseTask
+}
+
+func (lt *MessageLanguageTask) Execute(
+	ctx context.Context,
+	msg *message.Message,
+) error {
+	ctx, done := context.WithTimeout(ctx, TaskTimeout*time.Second)
+	defer done()
+
+	sessionID := msg.Metadata.Get("session_id")
+	if sessionID == "" {
+		return errors.New("MessageLanguageTask session_id is empty")
+	}
+
+	log.Debugf("MessageLanguageTask called for session %s", sessionID)
+
+	messages, err := messageTaskPayloadToMessages(ctx, lt.appState, msg)
+	if err != nil {
+		return fmt.Errorf("MessageLanguageTask messageTaskPayloadToMessages failed: %w", err)
+	}
+
+	languageMessages := detectMessageLanguages(messages)
+	if len(languageMessages) == 0 {
+		msg.Ack()
+		return nil
+	}
+
+	err = lt.appState.MemoryStore.PutMessageMetadata(
+		ctx,
+		lt.appState,
+		sessionID,
+		languageMessages,
+		true,
+	)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			log.Warnf("MessageLanguageTask PutMessageMetadata not found. Were the records deleted?")
+			// Don't error out
+			msg.Ack()
+			return nil
+		}
+		return fmt.Errorf("MessageLanguageTask failed to put message metadata: %w", err)
+	}
+
+	publishExtractorResult(
+		lt.appState,
+		sessionID,
+		models.MessageLanguageTopic,
+		languageMessages,
+		nil,
+	)
+
+	msg.Ack()
+
+	return nil
+}
+
+// detectMessageLanguages returns the metadata updates recording the language of messages.
+// Messages whose language can't be detected are skipped.
+func detectMessageLanguages(messages []models.Message) []models.Message {
+	var languageMessages []models.Message
+	for _, m := range messages {
+		language := langdetect.Detect(m.Content)
+		if language == langdetect.Unknown {
+			continue
+		}
+		languageMessages = append(languageMessages, models.Message{
+			UUID: m.UUID,
+			Metadata: map[string]interface{}{
+				"system": map[string]interface{}{"language": language},
+			},
+		})
+	}
+	return languageMessages
+}
@@ -0,0 +1,35 @@
+package tasks
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/pkg/models"
+)
+
+func TestDetectMessageLanguages(t *testing.T) {
+	messages := []models.Message{
+		{UUID: uuid.New(), Content: "What is the capital of France?"},
+		{UUID: uuid.New(), Content: "42"},
+		{UUID: uuid.New(), Content: "¿Dónde está la estación de tren? Quiero ir con mi hermano."},
+	}
+
+	result := detectMessageLanguages(messages)
+	require.Len(t, result, 2)
+
+	assert.Equal(t, messages[0].UUID, result[0].UUID)
+	assert.Equal(
+		t,
+		map[string]interface{}{"system": map[string]interface{}{"language": "en"}},
+		result[0].Metadata,
+	)
+	assert.Equal(t, messages[2].UUID, result[1].UUID)
+	assert.Equal(
+		t,
+		map[string]interface{}{"system": map[string]interface{}{"language": "es"}},
+		result[1].Metadata,
+	)
+}
@@ -0,0 +1,104 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+
+	"github.com/getzep/zep/pkg/llms"
+	"github.com/getzep/zep/pkg/models"
+)
+
+var _ models.Task = &MessageModerationTask{}
+
+func NewMessageModerationTask(appState *models.AppState) *MessageModerationTask {
+	return &MessageModerationTask{
+		BaseTask{
+			appState: appState,
+		},
+	}
+}
+
+// MessageModerationTask records the moderation of messages in their system metadata. It
+// isn't run if messages are moderated as they're stored, which they are if a reject
+// threshold is configured.
+type MessageModerationTask struct {
+	BaseTask
+}
+
+func (mt *MessageModerationTask) Execute(
+	ctx context.Context,
+	msg *message.Message,
+) error {
+	ctx, done := context.WithTimeout(ctx, TaskTimeout*time.Second)
+	defer done()
+
+	sessionID := msg.Metadata.Get("session_id")
+	if sessionID == "" {
+		return errors.New("MessageModerationTask session_id is empty")
+	}
+
+	log.Debugf("MessageModerationTask called for session %s", sessionID)
+
+	messages, err := messageTaskPayloadToMessages(ctx, mt.appState, msg)
+	if err != nil {
+		return fmt.Errorf("MessageModerationTask messageTaskPayloadToMessages failed: %w", err)
+	}
+
+	if len(messages) == 0 {
+		return fmt.Errorf("MessageModerationTask messageTaskPayloadToMessages returned no messages")
+	}
+
+	texts := make([]string, len(messages))
+	for i, m := range messages {
+		texts[i] = m.Content
+	}
+	moderations, err := llms.ModerateTexts(ctx, mt.appState, texts)
+	if err != nil {
+		return fmt.Errorf("MessageModerationTask moderation failed: %w", err)
+	}
+
+	moderationMessages := make([]models.Message, len(messages))
+	for i, m := range messages {
+		moderationMessages[i] = models.Message{
+			UUID: m.UUID,
+			Metadata: map[string]interface{}{
+				"system": map[string]interface{}{"moderation": moderations[i]},
+			},
+		}
+	}
+
+	err = mt.appState.MemoryStore.PutMessageMetadata(
+		ctx,
+		mt.appState,
+		sessionID,
+		moderationMessages,
+		true,
+	)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			log.Warnf(
+				"MessageModerationTask PutMessageMetadata not found. Were the records deleted?",
+			)
+			// Don't error out
+			msg.Ack()
+			return nil
+		}
+		return fmt.Errorf("MessageModerationTask failed to put message metadata: %w", err)
+	}
+
+	publishExtractorResult(
+		mt.appState,
+		sessionID,
+		models.MessageModerationTopic,
+		moderationMessages,
+		nil,
+	)
+
+	msg.Ack()
+
+	return nil
+}
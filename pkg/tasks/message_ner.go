@@ -62,11 +62,14 @@ func (n *MessageNERTask) Execute(
 	}
 
 	nerMessages := make([]models.Message, len(nerResponse.Texts))
+	mentions := make(map[uuid.UUID][]models.Entity, len(nerResponse.Texts))
 	for i, r := range nerResponse.Texts {
 		msgUUID, err := uuid.Parse(r.UUID)
 		if err != nil {
 			return fmt.Errorf("MessageNERTask failed to parse message UUID: %w", err)
 		}
+		// messages without entities are included, to clear the mentions of updated messages
+		mentions[msgUUID] = r.Entities
 		entityList := extractEntities(r.Entities)
 
 		if len(entityList) == 0 {
@@ -92,6 +95,14 @@ func (n *MessageNERTask) Execute(
 		return fmt.Errorf("MessageNERTask failed to put message metadata: %w", err)
 	}
 
+	err = n.appState.MemoryStore.PutEntityMentions(ctx, sessionID, mentions)
+	if err != nil {
+		return fmt.Errorf("MessageNERTask failed to put entity mentions: %w", err)
+	}
+
+	publishExtractorResult(n.appState, sessionID, models.MessageNerTopic, nerMessages, nil)
+	notifyEntitiesExtracted(ctx, n.appState, sessionID, nerMessages, nil)
+
 	msg.Ack()
 
 	return nil
@@ -0,0 +1,305 @@
+package tasks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/google/uuid"
+	llms2 "github.com/tmc/langchaingo/llms"
+
+	"github.com/getzep/zep/internal"
+	"github.com/getzep/zep/pkg/llms"
+	"github.com/getzep/zep/pkg/models"
+)
+
+const sentimentMaxTokens = 64
+
+// SentimentServiceLocal selects the NLP server's sentiment model rather than the llm
+const SentimentServiceLocal = "local"
+
+var (
+	sentimentLabelRegex    = regexp.MustCompile(`(?i)sentiment\W*(positive|negative|neutral)`)
+	sentimentPolarityRegex = regexp.MustCompile(`(?i)polarity\s*[:=]?\s*([-+]?\d*\.?\d+)`)
+)
+
+var _ models.Task = &MessageSentimentTask{}
+
+func NewMessageSentimentTask(appState *models.AppState) *MessageSentimentTask {
+	return &MessageSentimentTask{
+		BaseTask{
+			appState: appState,
+		},
+	}
+}
+
+type MessageSentimentTask struct {
+	BaseTask
+}
+
+func (st *MessageSentimentTask) Execute(
+	ctx context.Context,
+	msg *message.Message,
+) error {
+	ctx, done := context.WithTimeout(ctx, TaskTimeout*time.Second)
+	defer done()
+
+	sessionID := msg.Metadata.Get("session_id")
+	if sessionID == "" {
+		return errors.New("MessageSentimentTask session_id is empty")
+	}
+
+	log.Debugf("MessageSentimentTask called for session %s", sessionID)
+
+	messages, err := messageTaskPayloadToMessages(ctx, st.appState, msg)
+	if err != nil {
+		return fmt.Errorf(
+			"MessageSentimentTask messageTaskPayloadToMessages failed: %w",
+			err,
+		)
+	}
+
+	if len(messages) == 0 {
+		return fmt.Errorf(
+			"MessageSentimentTask messageTaskPayloadToMessages returned no messages",
+		)
+	}
+
+	var sentiments map[uuid.UUID]models.Sentiment
+	if st.appState.Config.Extractors.Messages.Sentiment.Service == SentimentServiceLocal {
+		sentiments, err = callSentimentService(ctx, st.appState, messages)
+	} else {
+		sentiments, err = st.classifyWithLLM(ctx, messages)
+	}
+	if err != nil {
+		return fmt.Errorf("MessageSentimentTask: %w", err)
+	}
+
+	sentimentMessages := make([]models.Message, 0, len(sentiments))
+	for _, m := range messages {
+		sentiment, ok := sentiments[m.UUID]
+		if !ok {
+			continue
+		}
+		sentimentMessages = append(sentimentMessages, models.Message{
+			UUID: m.UUID,
+			Metadata: map[string]interface{}{
+				"system": map[string]interface{}{"sentiment": sentiment},
+			},
+		})
+	}
+
+	if len(sentimentMessages) == 0 {
+		msg.Ack()
+		return nil
+	}
+
+	err = st.appState.MemoryStore.PutMessageMetadata(
+		ctx,
+		st.appState,
+		sessionID,
+		sentimentMessages,
+		true,
+	)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			log.Warnf(
+				"MessageSentimentTask PutMessageMetadata not found. Were the records deleted?",
+			)
+			// Don't error out
+			msg.Ack()
+			return nil
+		}
+		return fmt.Errorf("MessageSentimentTask failed to put message metadata: %w", err)
+	}
+
+	publishExtractorResult(
+		st.appState,
+		sessionID,
+		models.MessageSentimentTopic,
+		sentimentMessages,
+		nil,
+	)
+
+	msg.Ack()
+
+	return nil
+}
+
+// classifyWithLLM prompts the sentiment extractor's llm for the sentiment of each message.
+// Messages for which the llm's response can't be parsed are left out of the result.
+func (st *MessageSentimentTask) classifyWithLLM(
+	ctx context.Context,
+	messages []models.Message,
+) (map[uuid.UUID]models.Sentiment, error) {
+	llmClient := llms.GetExtractorLLMClient(st.appState, llms.SentimentExtractor)
+
+	sentiments := make(map[uuid.UUID]models.Sentiment, len(messages))
+	errs := make(chan error, len(messages))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, m := range messages {
+		wg.Add(1)
+		go func(message models.Message) {
+			defer wg.Done()
+
+			prompt, err := internal.ParsePrompt(
+				sentimentPromptTemplate,
+				SentimentPromptTemplateData{Input: message.Content},
+			)
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			response, err := llmClient.Call(
+				ctx,
+				prompt,
+				llms2.WithMaxTokens(sentimentMaxTokens),
+			)
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			sentiment, ok := parseSentiment(response)
+			if !ok {
+				log.Warnf("MessageSentimentTask could not parse llm response: %s", response)
+				return
+			}
+
+			mu.Lock()
+			sentiments[message.UUID] = sentiment
+			mu.Unlock()
+		}(m)
+	}
+
+	wg.Wait()
+	close(errs)
+
+	var errStrings []string
+	for err := range errs {
+		errStrings = append(errStrings, err.Error())
+	}
+	if len(errStrings) > 0 {
+		return nil, errors.New(strings.Join(errStrings, "; "))
+	}
+
+	return sentiments, nil
+}
+
+// parseSentiment parses a response in the format "Sentiment: <label>, Polarity: <polarity>".
+// A missing polarity is treated as 0, and polarities are clamped to [-1, 1].
+func parseSentiment(response string) (models.Sentiment, bool) {
+	labelMatch := sentimentLabelRegex.FindStringSubmatch(response)
+	if labelMatch == nil {
+		return models.Sentiment{}, false
+	}
+	sentiment := models.Sentiment{
+		Label: models.SentimentLabel(strings.ToLower(labelMatch[1])),
+	}
+
+	if polarityMatch := sentimentPolarityRegex.FindStringSubmatch(response); polarityMatch != nil {
+		polarity, err := strconv.ParseFloat(polarityMatch[1], 64)
+		if err == nil {
+			sentiment.Polarity = clampPolarity(polarity)
+		}
+	}
+
+	return sentiment, true
+}
+
+func clampPolarity(polarity float64) float64 {
+	switch {
+	case polarity < -1:
+		return -1
+	case polarity > 1:
+		return 1
+	default:
+		return polarity
+	}
+}
+
+func callSentimentService(
+	ctx context.Context,
+	appState *models.AppState,
+	messages []models.Message,
+) (map[uuid.UUID]models.Sentiment, error) {
+	url := appState.Config.NLP.ServerURL + "/sentiment"
+
+	request := make([]models.SentimentRequestRecord, len(messages))
+	for i, m := range messages {
+		request[i] = models.SentimentRequestRecord{
+			UUID:     m.UUID.String(),
+			Text:     m.Content,
+			Language: "en",
+		}
+	}
+
+	jsonBody, err := json.Marshal(models.SentimentRequest{Texts: request})
+	if err != nil {
+		return nil, err
+	}
+
+	client := NewRetryableHTTPClient(NerRetryMax, NerTimeout)
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		url,
+		bytes.NewBuffer(jsonBody),
+	)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(
+			"error making POST request: %d - %s",
+			resp.StatusCode,
+			resp.Status,
+		)
+	}
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var response models.SentimentResponse
+	if err := json.Unmarshal(bodyBytes, &response); err != nil {
+		return nil, err
+	}
+
+	sentiments := make(map[uuid.UUID]models.Sentiment, len(response.Texts))
+	for _, r := range response.Texts {
+		msgUUID, err := uuid.Parse(r.UUID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse message UUID: %w", err)
+		}
+		sentiments[msgUUID] = models.Sentiment{
+			Label:    r.Label,
+			Polarity: clampPolarity(r.Polarity),
+		}
+	}
+
+	return sentiments, nil
+}
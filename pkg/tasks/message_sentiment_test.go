@@ -0,0 +1,97 @@
+package tasks
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/pkg/models"
+)
+
+func TestParseSentiment(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		expected models.Sentiment
+		ok       bool
+	}{
+		{
+			"positive",
+			"Sentiment: positive, Polarity: 0.8",
+			models.Sentiment{Label: models.SentimentPositive, Polarity: 0.8},
+			true,
+		},
+		{
+			"negative",
+			"Sentiment: Negative\nPolarity: -0.65",
+			models.Sentiment{Label: models.SentimentNegative, Polarity: -0.65},
+			true,
+		},
+		{
+			"clamped polarity",
+			"Sentiment: positive, Polarity: 3",
+			models.Sentiment{Label: models.SentimentPositive, Polarity: 1},
+			true,
+		},
+		{
+			"missing polarity",
+			"Sentiment: neutral",
+			models.Sentiment{Label: models.SentimentNeutral},
+			true,
+		},
+		{"unparseable", "I cannot determine that.", models.Sentiment{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sentiment, ok := parseSentiment(tt.response)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, sentiment)
+		})
+	}
+}
+
+func TestCallSentimentService(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/sentiment", r.URL.Path)
+
+		var request models.SentimentRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+		response := models.SentimentResponse{}
+		for _, text := range request.Texts {
+			response.Texts = append(response.Texts, models.SentimentResponseRecord{
+				UUID:     text.UUID,
+				Label:    models.SentimentNegative,
+				Polarity: -0.5,
+			})
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(response))
+	}))
+	defer server.Close()
+
+	cfg := *appState.Config
+	cfg.NLP.ServerURL = server.URL
+	testAppState := &models.AppState{Config: &cfg}
+
+	messages := []models.Message{
+		{UUID: uuid.New(), Content: "This is terrible."},
+		{UUID: uuid.New(), Content: "I'm not happy with it."},
+	}
+	sentiments, err := callSentimentService(testCtx, testAppState, messages)
+	require.NoError(t, err)
+
+	assert.Len(t, sentiments, len(messages))
+	for _, m := range messages {
+		assert.Equal(
+			t,
+			models.Sentiment{Label: models.SentimentNegative, Polarity: -0.5},
+			sentiments[m.UUID],
+		)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/sirupsen/logrus"
 	llms2 "github.com/tmc/langchaingo/llms"
 
 	"github.com/getzep/zep/internal"
@@ -18,6 +19,11 @@ import (
 const MaxTokensFallback = 2048
 const SummaryMaxOutputTokens = 1024
 
+// SummarizerPromptMetadataKey is the session metadata key of a summarizer prompt that overrides
+// the configured prompts for the session. Like them, it must include the {{.PrevSummary}} and
+// {{.MessagesJoined}} template variables.
+const SummarizerPromptMetadataKey = "summarizer_prompt"
+
 var _ models.Task = &MessageSummaryTask{}
 
 // MessageSummaryTask gets a list of messages created since the last SummaryPoint,
@@ -54,19 +60,46 @@ func (t *MessageSummaryTask) Execute(
 		return errors.New("SummaryTask session_id is empty")
 	}
 
-	log.Debugf("SummaryTask called for session %s", sessionID)
+	taskLog := internal.LoggerFromContext(ctx).WithFields(logrus.Fields{
+		"task":       "SummaryTask",
+		"session_id": sessionID,
+	})
+	taskLog.Debug("called")
 
-	messageWindow := t.appState.Config.Memory.MessageWindow
+	session, err := t.appState.MemoryStore.GetSession(ctx, t.appState, sessionID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			taskLog.Warn("GetSession not found. Were the records deleted?")
+			// Don't error out
+			msg.Ack()
+			return nil
+		}
+		return fmt.Errorf("SummaryTask get session failed: %w", err)
+	}
+
+	messageWindow, err := models.SessionMessageWindow(
+		session,
+		t.appState.Config.Memory.MessageWindow,
+	)
+	if err != nil {
+		return fmt.Errorf("SummaryTask %w", err)
+	}
 	if messageWindow == 0 {
 		return errors.New("SummaryTask message window is 0")
 	}
 
+	sessionPrompt, err := sessionSummarizerPrompt(session)
+	if err != nil {
+		return fmt.Errorf("SummaryTask %w", err)
+	}
+
 	// if no summary exists yet, we'll get all messages up to the message window
 	messagesSummary, err := t.appState.MemoryStore.GetMemory(
 		ctx,
 		t.appState,
 		sessionID,
 		0,
+		nil,
 	)
 	if err != nil {
 		return fmt.Errorf("SummaryTask get memory failed: %w", err)
@@ -74,16 +107,23 @@ func (t *MessageSummaryTask) Execute(
 
 	messages := messagesSummary.Messages
 	if messages == nil {
-		log.Warningf("SummaryTask GetMemory returned no messages for session %s", sessionID)
+		taskLog.Warn("GetMemory returned no messages")
 		return nil
 	}
 	// If we're still under the message window, we don't need to summarize.
-	if len(messages) < t.appState.Config.Memory.MessageWindow {
+	if len(messages) < messageWindow {
 		return nil
 	}
 
+	// hierarchical summarization summarizes each window on its own
+	hierarchical := t.appState.Config.Extractors.Messages.Summarizer.Hierarchical.Enabled
+	prevSummary := messagesSummary.Summary
+	if hierarchical {
+		prevSummary = nil
+	}
+
 	newSummary, err := t.summarize(
-		ctx, messages, messagesSummary.Summary, 0,
+		ctx, messages, prevSummary, 0, messageWindow, sessionPrompt,
 	)
 	if err != nil {
 		return fmt.Errorf("SummaryTask summarize failed %w", err)
@@ -97,7 +137,7 @@ func (t *MessageSummaryTask) Execute(
 	)
 	if err != nil {
 		if errors.Is(err, models.ErrNotFound) {
-			log.Warnf("MessageSummaryTask PutSummary not found. Were the records deleted?")
+			taskLog.Warn("PutSummary not found. Were the records deleted?")
 			// Don't error out
 			msg.Ack()
 			return nil
@@ -105,26 +145,105 @@ func (t *MessageSummaryTask) Execute(
 		return fmt.Errorf("SummaryTask put summary failed: %w", err)
 	}
 
-	log.Debugf("SummaryTask completed for session %s", sessionID)
+	if hierarchical {
+		// a failure is logged rather than returned, as the summary has been stored. summaries
+		// not added to the abstract are added when the next summary is
+		if err := t.updateSummaryAbstract(ctx, sessionID); err != nil {
+			taskLog.WithError(err).Error("failed to update abstract")
+		}
+	}
+
+	taskLog.WithField("summary_uuid", newSummary.UUID).Debug("completed")
 
 	msg.Ack()
 
 	return nil
 }
 
+// RegenerateSummary summarizes a session's messages afresh, ignoring its existing summaries,
+// and replaces them with the new summary. messages are all of the session's messages, oldest
+// first. As with MessageSummaryTask, the newest half of the message window isn't summarized.
+func RegenerateSummary(
+	ctx context.Context,
+	appState *models.AppState,
+	sessionID string,
+	messages []models.Message,
+) error {
+	session, err := appState.MemoryStore.GetSession(ctx, appState, sessionID)
+	if err != nil {
+		return fmt.Errorf("RegenerateSummary get session failed: %w", err)
+	}
+
+	messageWindow, err := models.SessionMessageWindow(session, appState.Config.Memory.MessageWindow)
+	if err != nil {
+		return err
+	}
+	if messageWindow == 0 {
+		return errors.New("RegenerateSummary message window is 0")
+	}
+	if len(messages) <= messageWindow/2 {
+		return models.NewBadRequestError(fmt.Sprintf(
+			"session %s has %d messages. more than %d are needed to create a summary",
+			sessionID,
+			len(messages),
+			messageWindow/2,
+		))
+	}
+
+	sessionPrompt, err := sessionSummarizerPrompt(session)
+	if err != nil {
+		return err
+	}
+
+	t := NewMessageSummaryTask(appState)
+	newSummary, err := t.summarize(ctx, messages, nil, 0, messageWindow, sessionPrompt)
+	if err != nil {
+		return fmt.Errorf("RegenerateSummary summarize failed: %w", err)
+	}
+
+	err = appState.MemoryStore.ReplaceSummaries(ctx, appState, sessionID, newSummary)
+	if err != nil {
+		return fmt.Errorf("RegenerateSummary replace summaries failed: %w", err)
+	}
+
+	return nil
+}
+
 func (t *MessageSummaryTask) HandleError(err error) {
 	log.Errorf("SummaryExtractor failed: %v", err)
 }
 
+// sessionSummarizerPrompt returns the summarizer prompt set in a session's metadata, or an empty
+// string if there isn't one
+func sessionSummarizerPrompt(session *models.Session) (string, error) {
+	prompt, ok := session.Metadata[SummarizerPromptMetadataKey]
+	if !ok || prompt == nil {
+		return "", nil
+	}
+	promptStr, ok := prompt.(string)
+	if !ok {
+		return "", fmt.Errorf(
+			"session %s metadata %s must be a string",
+			session.SessionID,
+			SummarizerPromptMetadataKey,
+		)
+	}
+
+	return promptStr, nil
+}
+
 // summarize takes a slice of messages and a summary and returns a slice of messages that,
 // if larger than the window size, results in the messages slice being halved. If the slice of messages is larger than
 // the window size, the summary is updated to reflect the oldest messages that are removed. Expects messages to be in
-// chronological order, with the oldest first.
+// chronological order, with the oldest first. messageWindow is the session's message window.
+// sessionPrompt, if set, overrides the configured summarizer prompts.
 func (t *MessageSummaryTask) summarize(
 	ctx context.Context,
 	messages []models.Message,
 	summary *models.Summary,
 	promptTokens int,
+	messageWindow int,
+	sessionPrompt string,
 ) (*models.Summary, error) {
 	var currentSummaryContent string
 	if summary != nil {
@@ -132,12 +251,14 @@ func (t *MessageSummaryTask) summarize(
 	}
 
 	// New messages reduced to Half the MessageWindow to minimize the need to summarize new messages in the future.
-	newMessageCount := t.appState.Config.Memory.MessageWindow / 2
+	newMessageCount := messageWindow / 2
 
 	// Oldest messages that are over the newMessageCount
 	messagesToSummarize := messages[:len(messages)-newMessageCount]
 
-	modelName, err := llms.GetLLMModelName(t.appState.Config)
+	modelName, err := llms.GetLLMModelName(
+		llms.ExtractorConfig(t.appState.Config, llms.SummarizerExtractor),
+	)
 	if err != nil {
 		return &models.Summary{}, err
 	}
@@ -161,6 +282,7 @@ func (t *MessageSummaryTask) summarize(
 		messagesToSummarize,
 		summarizerMaxInputTokens,
 		currentSummaryContent,
+		sessionPrompt,
 	)
 	if err != nil {
 		return &models.Summary{}, err
@@ -183,6 +305,7 @@ func (t *MessageSummaryTask) processOverLimitMessages(
 	messages []models.Message,
 	summarizerMaxInputTokens int,
 	summary string,
+	sessionPrompt string,
 ) (*models.Summary, error) {
 	var tempMessageText []string //nolint:prealloc
 	var newSummary string
@@ -196,6 +319,7 @@ func (t *MessageSummaryTask) processOverLimitMessages(
 	}
 
 	newSummaryPointUUID := messages[len(messages)-1].UUID
+	llmClient := llms.GetExtractorLLMClient(t.appState, llms.SummarizerExtractor)
 
 	processSummary := func() error {
 		newSummary, newSummaryTokens, err = t.incrementalSummarizer(
@@ -203,6 +327,7 @@ func (t *MessageSummaryTask) processOverLimitMessages(
 			summary,
 			tempMessageText,
 			SummaryMaxOutputTokens,
+			sessionPrompt,
 		)
 		if err != nil {
 			return err
@@ -214,7 +339,7 @@ func (t *MessageSummaryTask) processOverLimitMessages(
 
 	for _, m := range messages {
 		messageText := fmt.Sprintf("%s: %s", m.Role, m.Content)
-		messageTokens, err := t.appState.LLMClient.GetTokenCount(messageText)
+		messageTokens, err := llmClient.GetTokenCount(messageText)
 		if err != nil {
 			return nil, err
 		}
@@ -269,6 +394,7 @@ func (t *MessageSummaryTask) incrementalSummarizer(
 	currentSummary string,
 	messages []string,
 	summaryMaxTokens int,
+	sessionPrompt string,
 ) (string, int, error) {
 	if len(messages) < 1 {
 		return "", 0, errors.New("no messages provided")
@@ -285,12 +411,13 @@ func (t *MessageSummaryTask) incrementalSummarizer(
 		MessagesJoined: messagesJoined,
 	}
 
-	progressivePrompt, err := t.generateProgressiveSummarizerPrompt(promptData)
+	progressivePrompt, err := t.generateProgressiveSummarizerPrompt(sessionPrompt, promptData)
 	if err != nil {
 		return "", 0, err
 	}
 
-	summary, err := t.appState.LLMClient.Call(
+	llmClient := llms.GetExtractorLLMClient(t.appState, llms.SummarizerExtractor)
+	summary, err := llmClient.Call(
 		ctx,
 		progressivePrompt,
 		llms2.WithMaxTokens(summaryMaxTokens),
@@ -301,7 +428,7 @@ func (t *MessageSummaryTask) incrementalSummarizer(
 
 	summary = strings.TrimSpace(summary)
 
-	tokensUsed, err := t.appState.LLMClient.GetTokenCount(summary)
+	tokensUsed, err := llmClient.GetTokenCount(summary)
 	if err != nil {
 		return "", 0, err
 	}
@@ -309,15 +436,37 @@ func (t *MessageSummaryTask) incrementalSummarizer(
 	return summary, tokensUsed, nil
 }
 
+// generateProgressiveSummarizerPrompt renders the summarizer prompt. sessionPrompt, if set,
+// is used in place of the configured or default prompt for the summarizer's llm service.
 func (t *MessageSummaryTask) generateProgressiveSummarizerPrompt(
+	sessionPrompt string,
 	promptData SummaryPromptTemplateData,
 ) (string, error) {
+	if sessionPrompt != "" {
+		if err := t.validateSummarizerPrompt(sessionPrompt); err != nil {
+			return "", err
+		}
+		return internal.ParsePrompt(sessionPrompt, promptData)
+	}
+
 	customSummaryPromptTemplateAnthropic := t.appState.Config.CustomPrompts.SummarizerPrompts.Anthropic
 	customSummaryPromptTemplateOpenAI := t.appState.Config.CustomPrompts.SummarizerPrompts.OpenAI
 
+	llmCfg := llms.ExtractorConfig(t.appState.Config, llms.SummarizerExtractor).LLM
+	service := llmCfg.Service
+	// Claude models served by Bedrock use the Anthropic prompt
+	if service == "bedrock" {
+		service = "openai"
+		if strings.HasPrefix(llmCfg.Model, llms.BedrockProviderAnthropic+".") {
+			service = "anthropic"
+		}
+	}
+
 	var summaryPromptTemplate string
-	switch t.appState.Config.LLM.Service {
-	case "openai":
+	switch service {
+	// local models served by Ollama and Gemini use the OpenAI prompt, which has no
+	// Human/Assistant turns
+	case "openai", "ollama", "vertexai":
 		if customSummaryPromptTemplateOpenAI != "" {
 			summaryPromptTemplate = customSummaryPromptTemplateOpenAI
 		} else {
@@ -330,7 +479,7 @@ func (t *MessageSummaryTask) generateProgressiveSummarizerPrompt(
 			summaryPromptTemplate = defaultSummaryPromptTemplateAnthropic
 		}
 	default:
-		return "", fmt.Errorf("unknown LLM service: %s", t.appState.Config.LLM.Service)
+		return "", fmt.Errorf("unknown LLM service: %s", llmCfg.Service)
 	}
 
 	err := t.validateSummarizerPrompt(summaryPromptTemplate)
@@ -55,9 +55,12 @@ func runTestSummarize(t *testing.T, llmClient models.ZepLLM) {
 	}
 
 	task := NewMessageSummaryTask(appState)
+	messageWindow := appState.Config.Memory.MessageWindow
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			newSummary, err := task.summarize(testCtx, tt.messages, tt.summary, 0)
+			newSummary, err := task.summarize(
+				testCtx, tt.messages, tt.summary, 0, messageWindow, "",
+			)
 			assert.NoError(t, err)
 
 			assert.Equal(t, newSummaryPointUUID, newSummary.SummaryPointUUID)
@@ -121,8 +124,10 @@ func TestGenerateProgressiveSummarizerPrompt(t *testing.T) {
 	testCases := []struct {
 		name                  string
 		service               string
+		summarizerService     string
 		customPromptOpenAI    string
 		customPromptAnthropic string
+		sessionPrompt         string
 		expectedPrompt        string
 		defaultPrompt         bool
 	}{
@@ -140,6 +145,13 @@ func TestGenerateProgressiveSummarizerPrompt(t *testing.T) {
 			customPromptAnthropic: "custom anthropic prompt {{.PrevSummary}} {{.MessagesJoined}}",
 			expectedPrompt:        "custom anthropic prompt previous summary joined messages",
 		},
+		{
+			name:                  "Session prompt overrides custom prompt",
+			service:               "anthropic",
+			customPromptAnthropic: "custom anthropic prompt {{.PrevSummary}} {{.MessagesJoined}}",
+			sessionPrompt:         "support ticket prompt {{.PrevSummary}} {{.MessagesJoined}}",
+			expectedPrompt:        "support ticket prompt previous summary joined messages",
+		},
 		{
 			name:                  "OpenAI without custom prompt",
 			service:               "openai",
@@ -156,6 +168,23 @@ func TestGenerateProgressiveSummarizerPrompt(t *testing.T) {
 			expectedPrompt:        defaultSummaryPromptTemplateAnthropic,
 			defaultPrompt:         true,
 		},
+		{
+			name:                  "Vertex AI without custom prompt",
+			service:               "vertexai",
+			customPromptOpenAI:    "",
+			customPromptAnthropic: "",
+			expectedPrompt:        defaultSummaryPromptTemplateOpenAI,
+			defaultPrompt:         true,
+		},
+		{
+			name:                  "Summarizer llm overrides service",
+			service:               "openai",
+			summarizerService:     "anthropic",
+			customPromptOpenAI:    "",
+			customPromptAnthropic: "",
+			expectedPrompt:        defaultSummaryPromptTemplateAnthropic,
+			defaultPrompt:         true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -165,6 +194,13 @@ func TestGenerateProgressiveSummarizerPrompt(t *testing.T) {
 					LLM: config.LLM{
 						Service: tc.service,
 					},
+					Extractors: config.ExtractorsConfig{
+						Messages: config.MessageExtractorsConfig{
+							Summarizer: config.SummarizerConfig{
+								LLM: config.ExtractorLLMConfig{Service: tc.summarizerService},
+							},
+						},
+					},
 					CustomPrompts: config.CustomPromptsConfig{
 						SummarizerPrompts: config.ExtractorPromptsConfig{
 							OpenAI:    tc.customPromptOpenAI,
@@ -180,7 +216,7 @@ func TestGenerateProgressiveSummarizerPrompt(t *testing.T) {
 
 			task := NewMessageSummaryTask(appState)
 
-			prompt, err := task.generateProgressiveSummarizerPrompt(promptData)
+			prompt, err := task.generateProgressiveSummarizerPrompt(tc.sessionPrompt, promptData)
 			assert.NoError(t, err)
 			if !tc.defaultPrompt {
 				assert.Equal(t, tc.expectedPrompt, prompt)
@@ -191,3 +227,28 @@ func TestGenerateProgressiveSummarizerPrompt(t *testing.T) {
 		})
 	}
 }
+
+func TestRegenerateSummary_TooFewMessages(t *testing.T) {
+	appState := &models.AppState{
+		Config: &config.Config{Memory: config.MemoryConfig{MessageWindow: 4}},
+	}
+
+	err := RegenerateSummary(testCtx, appState, "session", []models.Message{
+		{Role: "user", Content: "Hello"},
+		{Role: "assistant", Content: "Hi there!"},
+	})
+	assert.ErrorIs(t, err, models.ErrBadRequest)
+	assert.ErrorContains(t, err, "more than 2 are needed")
+}
+
+func TestGenerateProgressiveSummarizerPrompt_InvalidSessionPrompt(t *testing.T) {
+	task := NewMessageSummaryTask(&models.AppState{
+		Config: &config.Config{LLM: config.LLM{Service: "openai"}},
+	})
+
+	_, err := task.generateProgressiveSummarizerPrompt(
+		"summarize {{.MessagesJoined}}",
+		SummaryPromptTemplateData{MessagesJoined: "joined messages"},
+	)
+	assert.ErrorContains(t, err, "wrong summary prompt format")
+}
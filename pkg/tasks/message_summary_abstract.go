@@ -0,0 +1,168 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	llms2 "github.com/tmc/langchaingo/llms"
+
+	"github.com/getzep/zep/internal"
+	"github.com/getzep/zep/pkg/llms"
+	"github.com/getzep/zep/pkg/models"
+)
+
+// summaryListPageSize is the number of summaries fetched at a time when updating an abstract
+const summaryListPageSize = 100
+
+// updateSummaryAbstract adds the session's window summaries that are older than the most
+// recent WindowSummaries, and not yet in the session's abstract, to the abstract. Summaries
+// are added one at a time, oldest first.
+func (t *MessageSummaryTask) updateSummaryAbstract(ctx context.Context, sessionID string) error {
+	windowSummaries := t.appState.Config.Extractors.Messages.Summarizer.Hierarchical.WindowSummaries
+	if windowSummaries <= 0 {
+		windowSummaries = models.DefaultWindowSummaries
+	}
+
+	abstract, err := t.appState.MemoryStore.GetSummaryAbstract(ctx, t.appState, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to get summary abstract: %w", err)
+	}
+
+	summaries, err := getAllSummaries(ctx, t.appState, sessionID)
+	if err != nil {
+		return err
+	}
+
+	toAdd, rebuild := summariesToAbstract(summaries, abstract, windowSummaries)
+	if len(toAdd) == 0 {
+		return nil
+	}
+
+	var content string
+	if abstract != nil && !rebuild {
+		content = abstract.Content
+	}
+	var tokenCount int
+	for _, s := range toAdd {
+		content, tokenCount, err = t.abstractSummarizer(ctx, content, s.Content)
+		if err != nil {
+			return fmt.Errorf("failed to summarize summary %s: %w", s.UUID, err)
+		}
+	}
+
+	newAbstract := &models.SummaryAbstract{
+		Content:         content,
+		LastSummaryUUID: toAdd[len(toAdd)-1].UUID,
+		TokenCount:      tokenCount,
+	}
+	err = t.appState.MemoryStore.PutSummaryAbstract(ctx, t.appState, sessionID, newAbstract)
+	if err != nil {
+		return fmt.Errorf("failed to put summary abstract: %w", err)
+	}
+
+	return nil
+}
+
+// summariesToAbstract returns the summaries, oldest first, that are older than the most
+// recent windowSummaries and not yet in abstract. If abstract's last summary no longer
+// exists, all summaries older than the most recent windowSummaries are returned, and
+// rebuild is true.
+func summariesToAbstract(
+	summaries []models.Summary,
+	abstract *models.SummaryAbstract,
+	windowSummaries int,
+) (toAdd []models.Summary, rebuild bool) {
+	if len(summaries) <= windowSummaries {
+		return nil, false
+	}
+	older := summaries[:len(summaries)-windowSummaries]
+	if abstract == nil {
+		return older, false
+	}
+
+	for i, s := range summaries {
+		if s.UUID != abstract.LastSummaryUUID {
+			continue
+		}
+		if i+1 >= len(older) {
+			return nil, false
+		}
+		return older[i+1:], false
+	}
+
+	log.Warnf(
+		"summary %s of the abstract no longer exists. rebuilding the abstract",
+		abstract.LastSummaryUUID,
+	)
+	return older, true
+}
+
+// getAllSummaries pages through all summaries for a session, oldest first
+func getAllSummaries(
+	ctx context.Context,
+	appState *models.AppState,
+	sessionID string,
+) ([]models.Summary, error) {
+	var summaries []models.Summary
+	for page := 1; ; page++ {
+		summaryList, err := appState.MemoryStore.GetSummaryList(
+			ctx,
+			appState,
+			sessionID,
+			page,
+			summaryListPageSize,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get summaries: %w", err)
+		}
+		if summaryList == nil {
+			break
+		}
+		summaries = append(summaries, summaryList.Summaries...)
+		if summaryList.RowCount < summaryListPageSize {
+			break
+		}
+	}
+
+	return summaries, nil
+}
+
+// abstractSummarizer calls the LLM to add a summary to an abstract, which can be an empty
+// string. Returns the new abstract and the number of tokens in it.
+func (t *MessageSummaryTask) abstractSummarizer(
+	ctx context.Context,
+	abstract string,
+	summary string,
+) (string, int, error) {
+	promptData := SummaryAbstractPromptTemplateData{
+		PrevAbstract: abstract,
+		Summary:      summary,
+	}
+	prompt, err := internal.ParsePrompt(summaryAbstractPromptTemplate, promptData)
+	if err != nil {
+		return "", 0, err
+	}
+
+	llmClient := llms.GetExtractorLLMClient(t.appState, llms.SummarizerExtractor)
+	newAbstract, err := llmClient.Call(
+		ctx,
+		prompt,
+		llms2.WithMaxTokens(SummaryMaxOutputTokens),
+	)
+	if err != nil {
+		return "", 0, err
+	}
+
+	newAbstract = strings.TrimSpace(newAbstract)
+	if newAbstract == "" {
+		return "", 0, fmt.Errorf("no abstract found after summarization")
+	}
+
+	tokenCount, err := llmClient.GetTokenCount(newAbstract)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return newAbstract, tokenCount, nil
+}
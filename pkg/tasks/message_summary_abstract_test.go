@@ -0,0 +1,66 @@
+package tasks
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/getzep/zep/pkg/models"
+)
+
+func TestSummariesToAbstract(t *testing.T) {
+	summaries := make([]models.Summary, 5)
+	for i := range summaries {
+		summaries[i] = models.Summary{UUID: uuid.New()}
+	}
+
+	tests := []struct {
+		name            string
+		summaries       []models.Summary
+		abstract        *models.SummaryAbstract
+		windowSummaries int
+		want            []models.Summary
+		wantRebuild     bool
+	}{
+		{
+			name:            "within the window",
+			summaries:       summaries[:3],
+			windowSummaries: 3,
+		},
+		{
+			name:            "no abstract",
+			summaries:       summaries,
+			windowSummaries: 3,
+			want:            summaries[:2],
+		},
+		{
+			name:            "abstract covers older summaries",
+			summaries:       summaries,
+			abstract:        &models.SummaryAbstract{LastSummaryUUID: summaries[0].UUID},
+			windowSummaries: 3,
+			want:            summaries[1:2],
+		},
+		{
+			name:            "abstract is up to date",
+			summaries:       summaries,
+			abstract:        &models.SummaryAbstract{LastSummaryUUID: summaries[1].UUID},
+			windowSummaries: 3,
+		},
+		{
+			name:            "abstract summary no longer exists",
+			summaries:       summaries,
+			abstract:        &models.SummaryAbstract{LastSummaryUUID: uuid.New()},
+			windowSummaries: 3,
+			want:            summaries[:2],
+			wantRebuild:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toAdd, rebuild := summariesToAbstract(tt.summaries, tt.abstract, tt.windowSummaries)
+			assert.Equal(t, tt.want, toAdd)
+			assert.Equal(t, tt.wantRebuild, rebuild)
+		})
+	}
+}
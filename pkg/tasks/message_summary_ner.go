@@ -76,6 +76,15 @@ func (n *MessageSummaryNERTask) Execute(
 		return fmt.Errorf("MessageSummaryNERTask failed to put summary metadata: %w", err)
 	}
 
+	publishExtractorResult(
+		n.appState,
+		sessionID,
+		models.MessageSummaryNERTopic,
+		nil,
+		summaryMetadataUpdate,
+	)
+	notifyEntitiesExtracted(ctx, n.appState, sessionID, nil, summaryMetadataUpdate)
+
 	msg.Ack()
 
 	return nil
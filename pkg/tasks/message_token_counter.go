@@ -70,6 +70,14 @@ func (mt *MessageTokenCountTask) Execute(
 		return fmt.Errorf("TokenCountExtractor update messages failed:  %w", err)
 	}
 
+	publishExtractorResult(
+		mt.appState,
+		sessionID,
+		models.MessageTokenCountTopic,
+		countResult,
+		nil,
+	)
+
 	msg.Ack()
 
 	return nil
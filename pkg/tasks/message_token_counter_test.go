@@ -34,7 +34,7 @@ func runTestTokenCountExtractor(
 	)
 	assert.NoError(t, err)
 
-	memories, err := store.GetMemory(testCtx, appState, sessionID, 0)
+	memories, err := store.GetMemory(testCtx, appState, sessionID, 0, nil)
 	assert.NoError(t, err)
 
 	messages := memories.Messages
@@ -54,7 +54,7 @@ func runTestTokenCountExtractor(
 	err = tokenCountExtractor.Execute(testCtx, m)
 	assert.NoError(t, err)
 
-	memory, err := appState.MemoryStore.GetMemory(testCtx, appState, sessionID, 0)
+	memory, err := appState.MemoryStore.GetMemory(testCtx, appState, sessionID, 0, nil)
 	assert.NoError(t, err)
 	assert.Equal(t, len(memory.Messages), len(messages))
 
@@ -62,3 +62,56 @@ func messageTaskPayloadToMessages(
 
 	return messages, err
 }
+
+// publishExtractorResult publishes the messages or summary enriched by an extractor to the
+// session's stream. Messages without a UUID, which the extractor skipped, are left out.
+func publishExtractorResult(
+	appState *models.AppState,
+	sessionID string,
+	extractor models.TaskTopic,
+	messages []models.Message,
+	summary *models.Summary,
+) {
+	var enriched []models.Message
+	for _, m := range messages {
+		if m.UUID != uuid.Nil {
+			enriched = append(enriched, m)
+		}
+	}
+	if len(enriched) == 0 && summary == nil {
+		return
+	}
+	appState.PublishSessionEvent(&models.SessionEvent{
+		Type:      models.SessionEventExtractor,
+		SessionID: sessionID,
+		Extractor: extractor,
+		Messages:  enriched,
+		Summary:   summary,
+	})
+}
+
+// notifyEntitiesExtracted sends the messages or summary whose entities were extracted to the
+// webhooks. Messages without a UUID, which had no entities, are left out.
+func notifyEntitiesExtracted(
+	ctx context.Context,
+	appState *models.AppState,
+	sessionID string,
+	messages []models.Message,
+	summary *models.Summary,
+) {
+	var extracted []models.Message
+	for _, m := range messages {
+		if m.UUID != uuid.Nil {
+			extracted = append(extracted, m)
+		}
+	}
+	if len(extracted) == 0 && summary == nil {
+		return
+	}
+	appState.NotifyWebhooks(ctx, &models.WebhookEvent{
+		Type:      models.WebhookEventEntitiesExtracted,
+		SessionID: sessionID,
+		Messages:  extracted,
+		Summary:   summary,
+	})
+}
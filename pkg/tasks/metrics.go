@@ -0,0 +1,86 @@
+package tasks
+
+import (
+	"context"
+	"time"
+
+	wsql "github.com/ThreeDotsLabs/watermill-sql/v2/pkg/sql"
+	"github.com/ThreeDotsLabs/watermill/message"
+
+	"github.com/getzep/zep/pkg/metrics"
+)
+
+// queueDepthTimeout bounds the queries of the queue depths collected by a metrics scrape
+const queueDepthTimeout = 5 * time.Second
+
+var (
+	taskRuns = metrics.NewCounterVec(
+		"zep_task_runs_total",
+		"Task runs by topic and status, ok or error. Each retry is a run.",
+		"topic", "status",
+	)
+	taskDuration = metrics.NewHistogramVec(
+		"zep_task_duration_seconds",
+		"Latency of task runs by topic",
+		metrics.DefaultBuckets,
+		"topic",
+	)
+)
+
+// MetricsMiddleware records the count, status and latency of each run of a task handler
+func MetricsMiddleware(h message.HandlerFunc) message.HandlerFunc {
+	return func(msg *message.Message) ([]*message.Message, error) {
+		topic := message.SubscribeTopicFromCtx(msg.Context())
+		start := time.Now()
+		produced, err := h(msg)
+		taskDuration.Observe(time.Since(start).Seconds(), topic)
+		status := "ok"
+		if err != nil {
+			status = "error"
+		}
+		taskRuns.Inc(topic, status)
+		return produced, err
+	}
+}
+
+// registerQueueDepthMetric registers the zep_task_queue_depth gauge of the router's topics
+func (tr *TaskRouter) registerQueueDepthMetric() {
+	metrics.NewGaugeFunc(
+		"zep_task_queue_depth",
+		"Messages published to a task topic and not yet acked by its handler",
+		[]string{"topic"},
+		tr.queueDepths,
+	)
+}
+
+// queueDepths returns the number of messages of each topic that haven't been acked. The
+// depth of a topic is the difference of its last published and last acked offsets. Topics
+// whose depth can't be queried, such as those whose tables don't exist yet, are skipped.
+func (tr *TaskRouter) queueDepths() []metrics.GaugeSample {
+	ctx, cancel := context.WithTimeout(context.Background(), queueDepthTimeout)
+	defer cancel()
+
+	tr.mu.Lock()
+	topics := append([]string(nil), tr.topics...)
+	tr.mu.Unlock()
+
+	samples := make([]metrics.GaugeSample, 0, len(topics))
+	for _, topic := range topics {
+		// the table names are quoted identifiers of the topics
+		query := `SELECT GREATEST(0,
+			COALESCE(pg_sequence_last_value(pg_get_serial_sequence($1, 'offset')), 0) -
+			COALESCE((SELECT MAX(offset_acked) FROM ` +
+			wsql.DefaultPostgreSQLOffsetsAdapter{}.MessagesOffsetsTable(topic) + `), 0))`
+		var depth int64
+		err := tr.db.QueryRowContext(ctx, query, SQLSchema{}.MessagesTable(topic)).Scan(&depth)
+		if err != nil {
+			log.Debugf("failed to get queue depth of topic %s: %v", topic, err)
+			continue
+		}
+		samples = append(samples, metrics.GaugeSample{
+			LabelValues: []string{topic},
+			Value:       float64(depth),
+		})
+	}
+	return samples
+}
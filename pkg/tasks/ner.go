@@ -16,10 +16,86 @@ import (
 const NerRetryMax = 3
 const NerTimeout = 10 * time.Second
 
+// entitiesExtractor names the NER extractor's results in the extractor result cache
+const entitiesExtractor = "entities"
+
+// callNERTask extracts the entities of texts. If the extractor result cache is enabled, only
+// texts whose entities aren't cached are sent to the NLP server. Texts are cached by their
+// exact content, as entities are matched by their position in the text.
 func callNERTask(
 	ctx context.Context,
 	appState *models.AppState,
 	texts []models.TextData,
+) (models.EntityResponse, error) {
+	if appState.ExtractorResultCache == nil {
+		return requestEntities(ctx, appState, texts)
+	}
+
+	keys := make([]string, len(texts))
+	for i, t := range texts {
+		keys[i] = resultCacheKey(entitiesExtractor, "", t.Text)
+	}
+
+	results := make(map[string]json.RawMessage, len(texts))
+	for key, result := range getCachedResults(ctx, appState, keys) {
+		results[key] = result
+	}
+
+	var missing []models.TextData
+	keysByUUID := make(map[string]string)
+	for i, t := range texts {
+		if _, ok := results[keys[i]]; !ok {
+			missing = append(missing, t)
+			keysByUUID[t.TextUUID.String()] = keys[i]
+		}
+	}
+
+	if len(missing) > 0 {
+		response, err := requestEntities(ctx, appState, missing)
+		if err != nil {
+			return models.EntityResponse{}, err
+		}
+
+		extracted := make(map[string]json.RawMessage, len(response.Texts))
+		for _, r := range response.Texts {
+			key, ok := keysByUUID[r.UUID]
+			if !ok {
+				continue
+			}
+			result, err := json.Marshal(r.Entities)
+			if err != nil {
+				return models.EntityResponse{}, err
+			}
+			extracted[key] = result
+			results[key] = result
+		}
+		putCachedResults(ctx, appState, entitiesExtractor, extracted)
+	}
+
+	response := models.EntityResponse{Texts: make([]models.EntityResponseRecord, 0, len(texts))}
+	for i, t := range texts {
+		result, ok := results[keys[i]]
+		if !ok {
+			continue
+		}
+		var entities []models.Entity
+		if err := json.Unmarshal(result, &entities); err != nil {
+			return models.EntityResponse{}, err
+		}
+		response.Texts = append(response.Texts, models.EntityResponseRecord{
+			UUID:     t.TextUUID.String(),
+			Entities: entities,
+		})
+	}
+
+	return response, nil
+}
+
+// requestEntities requests the entities of texts from the NLP server
+func requestEntities(
+	ctx context.Context,
+	appState *models.AppState,
+	texts []models.TextData,
 ) (models.EntityResponse, error) {
 	url := appState.Config.NLP.ServerURL + "/entities"
 
@@ -0,0 +1,191 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+)
+
+// pipelineCompletedKey is the message metadata key recording the stages that completed. A
+// message retried by the router only runs the stages that haven't.
+const pipelineCompletedKey = "pipeline_completed"
+
+type pipelineStage struct {
+	topic     models.TaskTopic
+	dependsOn []models.TaskTopic
+	task      models.Task
+}
+
+// inPipelineTopics returns the topics of the stages listed in the pipeline, including
+// disabled stages
+func inPipelineTopics(cfg *config.Config) map[models.TaskTopic]bool {
+	topics := make(map[models.TaskTopic]bool, len(cfg.Extractors.Messages.Pipeline))
+	for _, stage := range cfg.Extractors.Messages.Pipeline {
+		topics[models.TaskTopic(stage.Name)] = true
+	}
+	return topics
+}
+
+// newPipelineStages returns the enabled stages of the configured pipeline, in order. A
+// stage's dependencies on disabled stages are dropped. An error is returned if a stage
+// isn't a message task, is listed twice, or depends on a stage that isn't listed before it.
+func newPipelineStages(appState *models.AppState) ([]pipelineStage, error) {
+	tasks := make(map[models.TaskTopic]messageTask)
+	for _, t := range messageTasks(appState) {
+		tasks[t.topic] = t
+	}
+
+	listed := make(map[models.TaskTopic]bool)
+	enabled := make(map[models.TaskTopic]bool)
+	var stages []pipelineStage
+	for _, stageCfg := range appState.Config.Extractors.Messages.Pipeline {
+		topic := models.TaskTopic(stageCfg.Name)
+		t, ok := tasks[topic]
+		if !ok {
+			return nil, fmt.Errorf("unknown pipeline stage: %s", stageCfg.Name)
+		}
+		if listed[topic] {
+			return nil, fmt.Errorf("pipeline stage %s is listed more than once", stageCfg.Name)
+		}
+
+		var dependsOn []models.TaskTopic
+		for _, dep := range stageCfg.DependsOn {
+			depTopic := models.TaskTopic(dep)
+			if !listed[depTopic] {
+				return nil, fmt.Errorf(
+					"pipeline stage %s depends on %s, which must be listed before it",
+					stageCfg.Name,
+					dep,
+				)
+			}
+			if enabled[depTopic] {
+				dependsOn = append(dependsOn, depTopic)
+			}
+		}
+		listed[topic] = true
+
+		if !t.enabled || (stageCfg.Enabled != nil && !*stageCfg.Enabled) {
+			continue
+		}
+		enabled[topic] = true
+		stages = append(stages, pipelineStage{
+			topic:     topic,
+			dependsOn: dependsOn,
+			task:      t.newTask(),
+		})
+	}
+
+	return stages, nil
+}
+
+var _ models.Task = &MessagePipelineTask{}
+
+func NewMessagePipelineTask(
+	appState *models.AppState,
+	stages []pipelineStage,
+) *MessagePipelineTask {
+	return &MessagePipelineTask{
+		BaseTask: BaseTask{appState: appState},
+		stages:   stages,
+	}
+}
+
+// MessagePipelineTask runs the stages of the message pipeline in order. A stage whose
+// dependencies failed is skipped, and the task fails if any stage fails.
+type MessagePipelineTask struct {
+	BaseTask
+	stages []pipelineStage
+}
+
+func (pt *MessagePipelineTask) Execute(
+	ctx context.Context,
+	msg *message.Message,
+) error {
+	// Stages apply their own timeouts
+	completed := make(map[models.TaskTopic]bool)
+	if c := msg.Metadata.Get(pipelineCompletedKey); c != "" {
+		for _, topic := range strings.Split(c, ",") {
+			completed[models.TaskTopic(topic)] = true
+		}
+	}
+
+	failed := make(map[models.TaskTopic]bool)
+	var errStrings []string
+	for _, stage := range pt.stages {
+		if completed[stage.topic] {
+			continue
+		}
+		if dep, ok := firstFailed(stage.dependsOn, failed); ok {
+			log.Warnf("MessagePipelineTask skipping %s as %s failed", stage.topic, dep)
+			failed[stage.topic] = true
+			continue
+		}
+
+		// each stage acks its own copy of the message
+		stageMsg := message.NewMessage(msg.UUID, msg.Payload)
+		for k, v := range msg.Metadata {
+			stageMsg.Metadata.Set(k, v)
+		}
+		stageCtx, span := tracer.Start(ctx, "pipeline."+string(stage.topic))
+		stageMsg.SetContext(stageCtx)
+
+		err := stage.task.Execute(stageCtx, stageMsg)
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+		if err != nil {
+			stage.task.HandleError(err)
+			failed[stage.topic] = true
+			errStrings = append(errStrings, fmt.Sprintf("%s: %v", stage.topic, err))
+			continue
+		}
+
+		completed[stage.topic] = true
+		msg.Metadata.Set(pipelineCompletedKey, joinTopics(pt.stages, completed))
+	}
+
+	if len(errStrings) > 0 {
+		return fmt.Errorf(
+			"MessagePipelineTask stages failed: %w",
+			errors.New(strings.Join(errStrings, "; ")),
+		)
+	}
+
+	msg.Ack()
+
+	return nil
+}
+
+func (pt *MessagePipelineTask) HandleError(err error) {
+	log.Errorf("MessagePipelineTask failed: %v", err)
+}
+
+func firstFailed(
+	topics []models.TaskTopic,
+	failed map[models.TaskTopic]bool,
+) (models.TaskTopic, bool) {
+	for _, topic := range topics {
+		if failed[topic] {
+			return topic, true
+		}
+	}
+	return "", false
+}
+
+// joinTopics joins the topics of the stages in set, in the order of stages
+func joinTopics(stages []pipelineStage, set map[models.TaskTopic]bool) string {
+	var topics []string
+	for _, stage := range stages {
+		if set[stage.topic] {
+			topics = append(topics, string(stage.topic))
+		}
+	}
+	return strings.Join(topics, ",")
+}
@@ -0,0 +1,178 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+)
+
+type recordingTask struct {
+	BaseTask
+	topic models.TaskTopic
+	calls *[]models.TaskTopic
+	err   error
+}
+
+func (rt *recordingTask) Execute(_ context.Context, msg *message.Message) error {
+	*rt.calls = append(*rt.calls, rt.topic)
+	if rt.err != nil {
+		return rt.err
+	}
+	msg.Ack()
+	return nil
+}
+
+func (rt *recordingTask) HandleError(error) {}
+
+func TestMessagePipelineTask(t *testing.T) {
+	var calls []models.TaskTopic
+	newStage := func(topic models.TaskTopic, err error, deps ...models.TaskTopic) pipelineStage {
+		return pipelineStage{
+			topic:     topic,
+			dependsOn: deps,
+			task:      &recordingTask{topic: topic, calls: &calls, err: err},
+		}
+	}
+	nerFails := newStage(models.MessageNerTopic, errors.New("ner failed"))
+	stages := []pipelineStage{
+		newStage(models.MessageLanguageTopic, nil),
+		nerFails,
+		newStage(models.MessageEmbedderTopic, nil, models.MessageLanguageTopic),
+		newStage(models.MessageIntentTopic, nil, models.MessageNerTopic),
+	}
+	task := NewMessagePipelineTask(appState, stages)
+
+	msg := message.NewMessage("1", []byte("[]"))
+	err := task.Execute(testCtx, msg)
+	assert.ErrorContains(t, err, "message_ner: ner failed")
+
+	// stages run in order, and intent is skipped as ner failed
+	assert.Equal(t, []models.TaskTopic{
+		models.MessageLanguageTopic,
+		models.MessageNerTopic,
+		models.MessageEmbedderTopic,
+	}, calls)
+	assert.Equal(t, "message_language,message_embedder", msg.Metadata.Get(pipelineCompletedKey))
+
+	// a retry only runs the stages that didn't complete
+	calls = nil
+	nerFails.task.(*recordingTask).err = nil
+	require.NoError(t, task.Execute(testCtx, msg))
+	assert.Equal(t, []models.TaskTopic{models.MessageNerTopic, models.MessageIntentTopic}, calls)
+}
+
+func TestNewPipelineStages(t *testing.T) {
+	disabled := false
+	cfg := *appState.Config
+	cfg.Extractors.Messages.Language.Enabled = true
+	cfg.Extractors.Messages.Entities.Enabled = true
+	cfg.Extractors.Messages.Embeddings.Enabled = true
+	cfg.Extractors.Messages.Pipeline = []config.PipelineStageConfig{
+		{Name: "message_language"},
+		{Name: "message_ner", Enabled: &disabled},
+		{Name: "message_embedder", DependsOn: []string{"message_language", "message_ner"}},
+	}
+	testAppState := &models.AppState{Config: &cfg}
+
+	stages, err := newPipelineStages(testAppState)
+	require.NoError(t, err)
+	require.Len(t, stages, 2)
+	assert.Equal(t, models.MessageLanguageTopic, stages[0].topic)
+	assert.Equal(t, models.MessageEmbedderTopic, stages[1].topic)
+	// the dependency on the disabled ner stage is dropped
+	assert.Equal(t, []models.TaskTopic{models.MessageLanguageTopic}, stages[1].dependsOn)
+
+	// stages in the pipeline aren't published to their own topics
+	assert.Equal(t, []models.TaskTopic{
+		models.MessageSummarizerTopic,
+		models.MessageIntentTopic,
+		models.MessageTokenCountTopic,
+		models.MessageSentimentTopic,
+		models.MessageModerationTopic,
+		models.MessageCustomTopic,
+		models.MessagePipelineTopic,
+	}, messageTopicsToPublish(&cfg))
+}
+
+func TestNewPipelineStages_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		pipeline []config.PipelineStageConfig
+		err      string
+	}{
+		{
+			"unknown stage",
+			[]config.PipelineStageConfig{{Name: "message_translator"}},
+			"unknown pipeline stage",
+		},
+		{
+			"duplicate stage",
+			[]config.PipelineStageConfig{{Name: "message_ner"}, {Name: "message_ner"}},
+			"listed more than once",
+		},
+		{
+			"dependency listed after",
+			[]config.PipelineStageConfig{
+				{Name: "message_embedder", DependsOn: []string{"message_ner"}},
+				{Name: "message_ner"},
+			},
+			"must be listed before it",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := *appState.Config
+			cfg.Extractors.Messages.Pipeline = tt.pipeline
+			_, err := newPipelineStages(&models.AppState{Config: &cfg})
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+type recordingPublisher struct {
+	message.Publisher
+	topics   []string
+	messages []*message.Message
+}
+
+func (p *recordingPublisher) Publish(topic string, messages ...*message.Message) error {
+	for _, m := range messages {
+		p.topics = append(p.topics, topic)
+		p.messages = append(p.messages, m)
+	}
+	return nil
+}
+
+func TestPublishSummarize(t *testing.T) {
+	publisher := &recordingPublisher{}
+	taskPublisher := &TaskPublisher{publisher: publisher}
+	require.NoError(t, taskPublisher.PublishSummarize(testCtx, "session"))
+	assert.Equal(t, []string{string(models.MessageSummarizerTopic)}, publisher.topics)
+	assert.Equal(t, "session", publisher.messages[0].Metadata.Get("session_id"))
+
+	// a summarizer in the pipeline is run without the pipeline's other stages
+	publisher = &recordingPublisher{}
+	taskPublisher = &TaskPublisher{
+		publisher: publisher,
+		pipelineStages: []models.TaskTopic{
+			models.MessageLanguageTopic,
+			models.MessageSummarizerTopic,
+			models.MessageEmbedderTopic,
+		},
+	}
+	require.NoError(t, taskPublisher.PublishSummarize(testCtx, "session"))
+	assert.Equal(t, []string{string(models.MessagePipelineTopic)}, publisher.topics)
+	assert.Equal(
+		t,
+		"message_language,message_embedder",
+		publisher.messages[0].Metadata.Get(pipelineCompletedKey),
+	)
+}
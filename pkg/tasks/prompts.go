@@ -1,5 +1,7 @@
 package tasks
 
+import "github.com/getzep/zep/pkg/models"
+
 const intentPromptTemplate = `
 Identify the intent of the subject's statement or question below.
 
@@ -76,3 +78,80 @@ type SummaryPromptTemplateData struct {
 	PrevSummary    string
 	MessagesJoined string
 }
+
+const sentimentPromptTemplate = `
+Classify the sentiment of the subject's statement or question below as positive, negative or
+neutral, and rate its polarity from -1.0, most negative, to 1.0, most positive.
+
+Respond only in the format "Sentiment: <sentiment>, Polarity: <polarity>".
+
+EXAMPLE
+Human: I love how fast these shoes arrived!
+Assistant: Sentiment: positive, Polarity: 0.8
+
+Human: {{.Input}}
+`
+
+type SentimentPromptTemplateData struct {
+	Input string
+}
+
+const summaryAbstractPromptTemplate = `
+Progressively condense the summaries of a conversation into a compact abstract of the whole
+conversation. You are given the current abstract and the summary of the next part of the
+conversation. Return a new abstract that adds the essential facts, decisions and open questions
+from the summary to the current abstract, favouring lasting information over details. Keep the
+abstract brief. If the current abstract is empty, return an abstract of the summary.
+
+Current abstract:
+{{.PrevAbstract}}
+
+Summary of the next part of the conversation:
+{{.Summary}}
+
+Respond with the new abstract only, without preamble.
+`
+
+type SummaryAbstractPromptTemplateData struct {
+	PrevAbstract string
+	Summary      string
+}
+
+const factsPromptTemplate = `
+Extract stable facts about the user from the numbered messages of a conversation below, such as
+where they live, their family, their work, and their lasting preferences. Ignore passing
+remarks, questions, and anything the assistant says that the user hasn't confirmed.
+
+State each fact as a subject, predicate and object. The subject is "user" for facts about the
+user, or the person or thing the fact is about, such as "user's daughter". Use short, general
+predicates, such as "lives in", "works as" or "has children", so that a changed fact reuses
+the predicate of the fact it replaces. If a message changes one of the known facts, return the
+fact with its new object.
+
+Respond only with a JSON array of objects with the keys "subject", "predicate", "object" and
+"message", the number of the message the fact is stated in. Respond with [] if there are no
+new or changed facts.
+
+EXAMPLE
+Known facts:
+user | lives in | Munich
+Messages:
+[1] user: We finally moved to Berlin last month, the kids love it.
+Assistant: [{"subject": "user", "predicate": "lives in", "object": "Berlin", "message": 1}, {"subject": "user", "predicate": "has children", "object": "yes", "message": 1}]
+
+Known facts:
+{{range .Facts}}{{.Subject}} | {{.Predicate}} | {{.Object}}
+{{end}}Messages:
+{{range .Messages}}[{{.Number}}] {{.Role}}: {{.Content}}
+{{end}}`
+
+type FactsPromptTemplateData struct {
+	Facts    []models.Fact
+	Messages []FactsPromptMessage
+}
+
+type FactsPromptMessage struct {
+	Number  int
+	Role    string
+	Content string
+}
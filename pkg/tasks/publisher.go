@@ -1,33 +1,80 @@
 package tasks
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/ThreeDotsLabs/watermill"
 	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/internal"
 	"github.com/getzep/zep/pkg/models"
 	wla "github.com/ma-hartma/watermill-logrus-adapter"
 )
 
+// MessageTopics are the topics of the tasks run on new messages
+var MessageTopics = []models.TaskTopic{
+	models.MessageSummarizerTopic,
+	models.MessageEmbedderTopic,
+	models.MessageNerTopic,
+	models.MessageIntentTopic,
+	models.MessageTokenCountTopic,
+	models.MessageSentimentTopic,
+	models.MessageLanguageTopic,
+	models.MessageModerationTopic,
+	models.MessageCustomTopic,
+	models.MessageFactsTopic,
+}
+
 type TaskPublisher struct {
-	publisher message.Publisher
+	publisher     message.Publisher
+	messageTopics []models.TaskTopic
+	// pipelineStages are the topics of the stages of the message pipeline, if configured
+	pipelineStages []models.TaskTopic
 }
 
-func NewTaskPublisher(db *sql.DB) *TaskPublisher {
+// NewTaskPublisher returns a TaskPublisher publishing to the queue in db. New messages are
+// published to the message pipeline, if cfg configures one, and the topics of the message
+// tasks that aren't part of it.
+func NewTaskPublisher(db *sql.DB, cfg *config.Config) *TaskPublisher {
 	var wlog = wla.NewLogrusLogger(log)
 	publisher, err := NewSQLQueuePublisher(db, wlog)
 	if err != nil {
 		log.Fatalf("Failed to create task publisher: %v", err)
 	}
+	var pipelineStages []models.TaskTopic
+	for _, stage := range cfg.Extractors.Messages.Pipeline {
+		pipelineStages = append(pipelineStages, models.TaskTopic(stage.Name))
+	}
 	return &TaskPublisher{
-		publisher: publisher,
+		publisher:      publisher,
+		messageTopics:  messageTopicsToPublish(cfg),
+		pipelineStages: pipelineStages,
+	}
+}
+
+func messageTopicsToPublish(cfg *config.Config) []models.TaskTopic {
+	if len(cfg.Extractors.Messages.Pipeline) == 0 {
+		return MessageTopics
 	}
+
+	inPipeline := inPipelineTopics(cfg)
+	var topics []models.TaskTopic
+	for _, topic := range MessageTopics {
+		if !inPipeline[topic] {
+			topics = append(topics, topic)
+		}
+	}
+	return append(topics, models.MessagePipelineTopic)
 }
 
 // Publish publishes a message to the given topic. Payload must be a struct that can be marshalled to JSON.
+// The trace context and request ID of ctx are propagated in the message's metadata.
 func (t *TaskPublisher) Publish(
+	ctx context.Context,
 	taskType models.TaskTopic,
 	metadata map[string]string,
 	payload any,
@@ -39,7 +86,11 @@ func (t *TaskPublisher) Publish(
 	}
 	log.Debugf("Publishing message: %s", p)
 	m := message.NewMessage(watermill.NewUUID(), p)
-	m.Metadata = metadata
+	m.Metadata = injectTraceContext(ctx, metadata)
+	if requestID := internal.RequestIDFromContext(ctx); requestID != "" {
+		m.Metadata.Set(internal.RequestIDField, requestID)
+	}
+	m.SetContext(ctx)
 
 	err = t.publisher.Publish(string(taskType), m)
 	if err != nil {
@@ -53,19 +104,12 @@ func (t *TaskPublisher) Publish(
 
 // PublishMessage publishes a slice of Messages to all Message topics.
 func (t *TaskPublisher) PublishMessage(
+	ctx context.Context,
 	metadata map[string]string,
 	payload []models.MessageTask,
 ) error {
-	var messageTopics = []models.TaskTopic{
-		models.MessageSummarizerTopic,
-		models.MessageEmbedderTopic,
-		models.MessageNerTopic,
-		models.MessageIntentTopic,
-		models.MessageTokenCountTopic,
-	}
-
-	for _, topic := range messageTopics {
-		err := t.Publish(topic, metadata, payload)
+	for _, topic := range t.messageTopics {
+		err := t.Publish(ctx, topic, metadata, payload)
 		if err != nil {
 			return fmt.Errorf("failed to publish message: %w", err)
 		}
@@ -74,6 +118,29 @@ func (t *TaskPublisher) PublishMessage(
 	return nil
 }
 
+// PublishSummarize publishes a task summarizing a session, such as a session whose summaries
+// were removed. If the summarizer is a stage of the message pipeline, the pipeline is
+// published with its other stages marked completed.
+func (t *TaskPublisher) PublishSummarize(ctx context.Context, sessionID string) error {
+	metadata := map[string]string{"session_id": sessionID}
+
+	var otherStages []string
+	inPipeline := false
+	for _, stage := range t.pipelineStages {
+		if stage == models.MessageSummarizerTopic {
+			inPipeline = true
+			continue
+		}
+		otherStages = append(otherStages, string(stage))
+	}
+	if !inPipeline {
+		return t.Publish(ctx, models.MessageSummarizerTopic, metadata, []models.MessageTask{})
+	}
+
+	metadata[pipelineCompletedKey] = strings.Join(otherStages, ",")
+	return t.Publish(ctx, models.MessagePipelineTopic, metadata, []models.MessageTask{})
+}
+
 func (t *TaskPublisher) Close() error {
 	err := t.publisher.Close()
 	if err != nil {
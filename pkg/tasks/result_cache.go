@@ -0,0 +1,61 @@
+package tasks
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"github.com/getzep/zep/pkg/models"
+)
+
+// resultCacheKey returns the extractor result cache key of input: the hex SHA-256 hash of the
+// extractor, the model it's extracted with, and the input
+func resultCacheKey(extractor, model, input string) string {
+	hash := sha256.Sum256([]byte(extractor + "\x00" + model + "\x00" + input))
+	return hex.EncodeToString(hash[:])
+}
+
+// normalizeUtterance lowercases text and collapses its whitespace, so that utterances such
+// as "Hi there" and "hi  there " share a cache key
+func normalizeUtterance(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+// getCachedResults returns the cached extractor results of keys. No results are returned if
+// the cache is disabled or fails, in which case the inputs are extracted as usual.
+func getCachedResults(
+	ctx context.Context,
+	appState *models.AppState,
+	keys []string,
+) map[string]json.RawMessage {
+	if appState.ExtractorResultCache == nil {
+		return nil
+	}
+
+	results, err := appState.ExtractorResultCache.GetResults(ctx, keys)
+	if err != nil {
+		log.Warnf("error getting cached extractor results: %v", err)
+		return nil
+	}
+
+	return results
+}
+
+// putCachedResults caches extractor results, if the cache is enabled. Results that can't be
+// cached are logged and otherwise ignored.
+func putCachedResults(
+	ctx context.Context,
+	appState *models.AppState,
+	extractor string,
+	results map[string]json.RawMessage,
+) {
+	if appState.ExtractorResultCache == nil || len(results) == 0 {
+		return
+	}
+
+	if err := appState.ExtractorResultCache.PutResults(ctx, extractor, results); err != nil {
+		log.Warnf("error caching %s extractor results: %v", extractor, err)
+	}
+}
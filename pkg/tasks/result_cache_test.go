@@ -0,0 +1,101 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/pkg/models"
+	"github.com/getzep/zep/pkg/testutils"
+)
+
+// mapResultCache is an in-memory ExtractorResultCache
+type mapResultCache struct {
+	results map[string]json.RawMessage
+}
+
+func (c *mapResultCache) GetResults(
+	_ context.Context,
+	keys []string,
+) (map[string]json.RawMessage, error) {
+	results := make(map[string]json.RawMessage)
+	for _, key := range keys {
+		if result, ok := c.results[key]; ok {
+			results[key] = result
+		}
+	}
+	return results, nil
+}
+
+func (c *mapResultCache) PutResults(
+	_ context.Context,
+	_ string,
+	results map[string]json.RawMessage,
+) error {
+	for key, result := range results {
+		c.results[key] = result
+	}
+	return nil
+}
+
+func (c *mapResultCache) PurgeResults(_ context.Context, _ time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestNormalizeUtterance(t *testing.T) {
+	assert.Equal(t, "hi there", normalizeUtterance("  Hi \n there "))
+	assert.Equal(
+		t,
+		resultCacheKey("intent", "", normalizeUtterance("Hi there")),
+		resultCacheKey("intent", "", normalizeUtterance("hi  there")),
+	)
+}
+
+func TestCallNERTaskCachesEntities(t *testing.T) {
+	var requested atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request models.EntityRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		requested.Add(int32(len(request.Texts)))
+
+		response := models.EntityResponse{}
+		for _, text := range request.Texts {
+			response.Texts = append(response.Texts, models.EntityResponseRecord{
+				UUID:     text.UUID,
+				Entities: []models.Entity{{Name: text.Text, Label: "ORG"}},
+			})
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(response))
+	}))
+	defer server.Close()
+
+	cfg := testutils.NewTestConfig()
+	cfg.NLP.ServerURL = server.URL
+	testAppState := &models.AppState{
+		Config:               cfg,
+		ExtractorResultCache: &mapResultCache{results: make(map[string]json.RawMessage)},
+	}
+
+	response, err := callNERTask(testCtx, testAppState, createMessages([]string{"Google"}))
+	require.NoError(t, err)
+	require.Len(t, response.Texts, 1)
+
+	textData := createMessages([]string{"Google", "Apple"})
+	response, err = callNERTask(testCtx, testAppState, textData)
+	require.NoError(t, err)
+	require.Len(t, response.Texts, 2)
+	for i, r := range response.Texts {
+		validateUUID(t, r.UUID, textData[i].TextUUID)
+		assert.Equal(t, textData[i].Text, r.Entities[0].Name)
+	}
+
+	// Google's entities are cached
+	assert.Equal(t, int32(2), requested.Load())
+}
@@ -11,8 +11,10 @@ import (
 	"github.com/ThreeDotsLabs/watermill"
 	"github.com/ThreeDotsLabs/watermill/message"
 	"github.com/ThreeDotsLabs/watermill/message/router/middleware"
+	"github.com/getzep/zep/internal"
 	"github.com/getzep/zep/pkg/models"
 	wla "github.com/ma-hartma/watermill-logrus-adapter"
+	"github.com/sirupsen/logrus"
 )
 
 // TODO: Add these to config
@@ -31,6 +33,10 @@ type TaskRouter struct {
 	db          *sql.DB
 	logger      watermill.LoggerAdapter
 	Subscribers map[string]message.Subscriber
+
+	mu sync.Mutex
+	// topics are the topics of the router's tasks
+	topics []string
 }
 
 // NewTaskRouter creates a new TaskRouter. Note that db should not be a bun.DB instance
@@ -45,17 +51,26 @@ func NewTaskRouter(appState *models.AppState, db *sql.DB) (*TaskRouter, error) {
 		return nil, err
 	}
 
-	// Set up a poison queue
-	publisher, err := NewSQLQueuePublisher(db, wlog)
-	if err != nil {
-		return nil, err
-	}
-	poisonQueueHandler, err := middleware.PoisonQueue(publisher, "poison_queue")
-	if err != nil {
-		return nil, err
+	// Store messages that failed after retries as dead letters, falling back to a poison
+	// queue if there's no dead letter store
+	var deadLetterHandler message.HandlerMiddleware
+	if appState.DeadLetterStore != nil {
+		deadLetterHandler = DeadLetterMiddleware(appState.DeadLetterStore)
+	} else {
+		publisher, err := NewSQLQueuePublisher(db, wlog)
+		if err != nil {
+			return nil, err
+		}
+		deadLetterHandler, err = middleware.PoisonQueue(publisher, "poison_queue")
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	router.AddMiddleware(
+		// Continue the trace of the request that published the task
+		TraceContextMiddleware,
+
 		// Watermill opentelemetry middleware
 		wotel.Trace(),
 
@@ -66,8 +81,8 @@ func NewTaskRouter(appState *models.AppState, db *sql.DB) (*TaskRouter, error) {
 		// In this case, it passes them as errors to the Retry middleware.
 		middleware.Recoverer,
 
-		// PoisonQueue will publish messages that failed to process after MaxRetries to the poison queue.
-		poisonQueueHandler,
+		// Messages that failed to process after MaxRetries are stored as dead letters.
+		deadLetterHandler,
 
 		// The handler function is retried if it returns an error.
 		// After MaxRetries, the message is Nacked and it's up to the PubSub to resend it.
@@ -79,6 +94,9 @@ func NewTaskRouter(appState *models.AppState, db *sql.DB) (*TaskRouter, error) {
 			RandomizationFactor: 0.5,
 			Logger:              wlog,
 		}.Middleware,
+
+		// Metrics records each run of a handler, including retries.
+		MetricsMiddleware,
 	)
 
 	return &TaskRouter{
@@ -100,6 +118,9 @@ func (tr *TaskRouter) AddTask(
 	if err != nil {
 		log.Fatalf("Failed to create subscriber for task %s: %v", taskType, err)
 	}
+	tr.mu.Lock()
+	tr.topics = append(tr.topics, string(taskType))
+	tr.mu.Unlock()
 	tr.AddNoPublisherHandler(
 		name,
 		string(taskType),
@@ -128,15 +149,30 @@ func (tr *TaskRouter) Close() (err error) {
 
 // TaskHandler returns a message handler function for the given task.
 // Handlers are NoPublishHandlerFuncs i.e. do not publish messages.
+// The ID of the request that published the task, if any, is added to the message's context
+// so that the task's logs can be correlated with the request's.
 func TaskHandler(task models.Task) message.NoPublishHandlerFunc {
 	return func(msg *message.Message) error {
-		log.Debugf("Handling task: %s", msg.UUID)
-		err := task.Execute(msg.Context(), msg)
+		ctx := msg.Context()
+		if requestID := msg.Metadata.Get(internal.RequestIDField); requestID != "" {
+			ctx = internal.WithRequestID(ctx, requestID)
+			msg.SetContext(ctx)
+		}
+		taskLog := internal.LoggerFromContext(ctx).WithFields(logrus.Fields{
+			"topic":        message.SubscribeTopicFromCtx(ctx),
+			"message_uuid": msg.UUID,
+			"session_id":   msg.Metadata.Get("session_id"),
+		})
+
+		taskLog.Debug("handling task")
+		start := time.Now()
+		err := task.Execute(ctx, msg)
 		if err != nil {
+			taskLog.WithError(err).Debug("task failed")
 			task.HandleError(err)
 			return err
 		}
-		log.Debugf("Handled task: %s", msg.UUID)
+		taskLog.WithField("duration", time.Since(start).String()).Debug("handled task")
 		return nil
 	}
 }
@@ -150,8 +186,9 @@ func RunTaskRouter(ctx context.Context, appState *models.AppState, db *sql.DB) {
 			log.Fatalf("failed to create task router: %v", err)
 		}
 
-		publisher := NewTaskPublisher(db)
+		publisher := NewTaskPublisher(db, appState.Config)
 		Initialize(ctx, appState, router)
+		router.registerQueueDepthMetric()
 
 		appState.TaskRouter = router
 		appState.TaskPublisher = publisher
@@ -25,6 +25,73 @@ func (b *BaseTask) HandleError(err error) {
 	log.Errorf("Task HandleError error: %s", err)
 }
 
+// messageTask is a task run on new messages. Message tasks are either subscribed to their own
+// topic, or run as a stage of the message pipeline.
+type messageTask struct {
+	topic   models.TaskTopic
+	enabled bool
+	newTask func() models.Task
+}
+
+// messageTasks returns the tasks run on new messages, in the order of MessageTopics
+func messageTasks(appState *models.AppState) []messageTask {
+	// messages are moderated as they're stored if they may be rejected
+	moderation := appState.Config.Extractors.Messages.Moderation
+
+	return []messageTask{
+		{
+			models.MessageSummarizerTopic,
+			appState.Config.Extractors.Messages.Summarizer.Enabled,
+			func() models.Task { return NewMessageSummaryTask(appState) },
+		},
+		{
+			models.MessageEmbedderTopic,
+			appState.Config.Extractors.Messages.Embeddings.Enabled,
+			func() models.Task { return NewMessageEmbedderTask(appState) },
+		},
+		{
+			models.MessageNerTopic,
+			appState.Config.Extractors.Messages.Entities.Enabled,
+			func() models.Task { return NewMessageNERTask(appState) },
+		},
+		{
+			models.MessageIntentTopic,
+			appState.Config.Extractors.Messages.Intent.Enabled,
+			func() models.Task { return NewMessageIntentTask(appState) },
+		},
+		{
+			models.MessageTokenCountTopic,
+			true, // Always enabled
+			func() models.Task { return NewMessageTokenCountTask(appState) },
+		},
+		{
+			models.MessageSentimentTopic,
+			appState.Config.Extractors.Messages.Sentiment.Enabled,
+			func() models.Task { return NewMessageSentimentTask(appState) },
+		},
+		{
+			models.MessageLanguageTopic,
+			appState.Config.Extractors.Messages.Language.Enabled,
+			func() models.Task { return NewMessageLanguageTask(appState) },
+		},
+		{
+			models.MessageModerationTopic,
+			moderation.Enabled && moderation.RejectThreshold <= 0,
+			func() models.Task { return NewMessageModerationTask(appState) },
+		},
+		{
+			models.MessageCustomTopic,
+			len(appState.Config.Extractors.Messages.Custom) > 0,
+			func() models.Task { return NewMessageCustomExtractorTask(appState) },
+		},
+		{
+			models.MessageFactsTopic,
+			appState.Config.Extractors.Messages.Facts.Enabled,
+			func() models.Task { return NewMessageFactsTask(appState) },
+		},
+	}
+}
+
 func Initialize(ctx context.Context, appState *models.AppState, router models.TaskRouter) {
 	log.Info("Initializing tasks")
 
@@ -36,44 +103,23 @@ func Initialize(ctx context.Context, appState *models.AppState, router models.Ta
 		}
 	}
 
-	addTask(
-		ctx,
-		string(models.MessageSummarizerTopic),
-		models.MessageSummarizerTopic,
-		appState.Config.Extractors.Messages.Summarizer.Enabled,
-		func() models.Task { return NewMessageSummaryTask(appState) },
-	)
-
-	addTask(
-		ctx,
-		string(models.MessageEmbedderTopic),
-		models.MessageEmbedderTopic,
-		appState.Config.Extractors.Messages.Embeddings.Enabled,
-		func() models.Task { return NewMessageEmbedderTask(appState) },
-	)
-
-	addTask(
-		ctx,
-		string(models.MessageNerTopic),
-		models.MessageNerTopic,
-		appState.Config.Extractors.Messages.Entities.Enabled,
-		func() models.Task { return NewMessageNERTask(appState) },
-	)
+	pipelineStages, err := newPipelineStages(appState)
+	if err != nil {
+		log.Fatalf("invalid extractor pipeline: %v", err)
+	}
 
-	addTask(
-		ctx,
-		string(models.MessageIntentTopic),
-		models.MessageIntentTopic,
-		appState.Config.Extractors.Messages.Intent.Enabled,
-		func() models.Task { return NewMessageIntentTask(appState) },
-	)
+	// Tasks run by the pipeline aren't subscribed to their own topics
+	inPipeline := inPipelineTopics(appState.Config)
+	for _, t := range messageTasks(appState) {
+		addTask(ctx, string(t.topic), t.topic, t.enabled && !inPipeline[t.topic], t.newTask)
+	}
 
 	addTask(
 		ctx,
-		string(models.MessageTokenCountTopic),
-		models.MessageTokenCountTopic,
-		true, // Always enabled
-		func() models.Task { return NewMessageTokenCountTask(appState) },
+		string(models.MessagePipelineTopic),
+		models.MessagePipelineTopic,
+		len(pipelineStages) > 0,
+		func() models.Task { return NewMessagePipelineTask(appState, pipelineStages) },
 	)
 
 	addTask(
@@ -100,4 +146,11 @@ func Initialize(ctx context.Context, appState *models.AppState, router models.Ta
 		func() models.Task { return NewMessageSummaryNERTask(appState) },
 	)
 
+	addTask(
+		ctx,
+		string(models.WebhookTopic),
+		models.WebhookTopic,
+		len(appState.Config.Webhooks) > 0,
+		func() models.Task { return NewWebhookTask(appState) },
+	)
 }
@@ -0,0 +1,38 @@
+package tasks
+
+import (
+	"context"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+const OtelTaskTracerName = "tasks"
+
+var tracer = otel.Tracer(OtelTaskTracerName)
+
+// injectTraceContext returns a copy of metadata carrying the trace context of ctx, so that
+// the task continues the trace of the request that published it
+func injectTraceContext(ctx context.Context, metadata map[string]string) map[string]string {
+	carrier := make(propagation.MapCarrier, len(metadata))
+	for k, v := range metadata {
+		carrier[k] = v
+	}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	return carrier
+}
+
+// TraceContextMiddleware extracts the trace context propagated in a task's metadata into
+// the context of its message. It must run before the tracing middleware, so that the
+// handler's span is a child of the publisher's.
+func TraceContextMiddleware(h message.HandlerFunc) message.HandlerFunc {
+	return func(msg *message.Message) ([]*message.Message, error) {
+		ctx := otel.GetTextMapPropagator().Extract(
+			msg.Context(),
+			propagation.MapCarrier(msg.Metadata),
+		)
+		msg.SetContext(ctx)
+		return h(msg)
+	}
+}
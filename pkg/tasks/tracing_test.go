@@ -0,0 +1,51 @@
+package tasks
+
+import (
+	"testing"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/getzep/zep/pkg/models"
+)
+
+func TestTraceContextPropagation(t *testing.T) {
+	propagator := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(propagator)
+
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{2},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(testCtx, spanContext)
+
+	publisher := &recordingPublisher{}
+	taskPublisher := &TaskPublisher{publisher: publisher}
+	metadata := map[string]string{"session_id": "session"}
+	require.NoError(t, taskPublisher.Publish(ctx, models.MessageEmbedderTopic, metadata, nil))
+
+	// the caller's metadata isn't modified
+	assert.Len(t, metadata, 1)
+	published := publisher.messages[0]
+	assert.Equal(t, "session", published.Metadata.Get("session_id"))
+	assert.NotEmpty(t, published.Metadata.Get("traceparent"))
+
+	// the handler continues the publisher's trace
+	received := message.NewMessage(published.UUID, published.Payload)
+	received.Metadata = published.Metadata
+	handler := TraceContextMiddleware(func(msg *message.Message) ([]*message.Message, error) {
+		remote := trace.SpanContextFromContext(msg.Context())
+		assert.Equal(t, spanContext.TraceID(), remote.TraceID())
+		assert.Equal(t, spanContext.SpanID(), remote.SpanID())
+		assert.True(t, remote.IsRemote())
+		return nil, nil
+	})
+	_, err := handler(received)
+	require.NoError(t, err)
+}
@@ -0,0 +1,217 @@
+package tasks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/google/uuid"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+)
+
+const (
+	DefaultWebhookTimeout    = 10
+	DefaultWebhookMaxRetries = 3
+)
+
+const (
+	WebhookEventHeader    = "X-Zep-Event"
+	WebhookDeliveryHeader = "X-Zep-Delivery"
+)
+
+var _ models.WebhookNotifier = &WebhookNotifier{}
+
+// WebhookNotifier queues the delivery of memory events to the configured webhooks. Each
+// delivery to a webhook is a task, so it's retried and dead lettered like other tasks.
+type WebhookNotifier struct {
+	appState *models.AppState
+}
+
+// NewWebhookNotifier returns a WebhookNotifier of the webhooks configured in appState. It
+// publishes with appState's TaskPublisher, which is set once the task router runs.
+func NewWebhookNotifier(appState *models.AppState) *WebhookNotifier {
+	return &WebhookNotifier{appState: appState}
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, event *models.WebhookEvent) {
+	if err := n.NotifySync(ctx, event); err != nil {
+		log.Error(err)
+	}
+}
+
+func (n *WebhookNotifier) NotifySync(ctx context.Context, event *models.WebhookEvent) error {
+	if event.UUID == uuid.Nil {
+		event.UUID = uuid.New()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC()
+	}
+
+	var errs []error
+	for i := range n.appState.Config.Webhooks {
+		webhook := &n.appState.Config.Webhooks[i]
+		if !webhookSubscribed(webhook, event.Type) {
+			continue
+		}
+		if n.appState.TaskPublisher == nil {
+			return fmt.Errorf(
+				"failed to publish %s webhook event: the task publisher isn't running",
+				event.Type,
+			)
+		}
+
+		err := n.appState.TaskPublisher.Publish(
+			ctx,
+			models.WebhookTopic,
+			map[string]string{
+				"session_id":  event.SessionID,
+				"webhook_url": webhook.URL,
+				"event_uuid":  event.UUID.String(),
+				"event_type":  string(event.Type),
+			},
+			event,
+		)
+		if err != nil {
+			errs = append(errs, fmt.Errorf(
+				"failed to publish %s webhook event to %s: %w",
+				event.Type,
+				webhook.URL,
+				err,
+			))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// webhookSubscribed returns whether a webhook is sent events of eventType. Webhooks without
+// events are sent all events.
+func webhookSubscribed(webhook *config.WebhookConfig, eventType models.WebhookEventType) bool {
+	return len(webhook.Events) == 0 || slices.Contains(webhook.Events, string(eventType))
+}
+
+var _ models.Task = &WebhookTask{}
+
+func NewWebhookTask(appState *models.AppState) *WebhookTask {
+	return &WebhookTask{
+		BaseTask{
+			appState: appState,
+		},
+	}
+}
+
+// WebhookTask delivers a memory event to a webhook
+type WebhookTask struct {
+	BaseTask
+}
+
+func (wt *WebhookTask) Execute(
+	ctx context.Context,
+	msg *message.Message,
+) error {
+	ctx, done := context.WithTimeout(ctx, TaskTimeout*time.Second)
+	defer done()
+
+	webhookURL := msg.Metadata.Get("webhook_url")
+	webhookIndex := slices.IndexFunc(
+		wt.appState.Config.Webhooks,
+		func(w config.WebhookConfig) bool { return w.URL == webhookURL },
+	)
+	// the webhook may have been removed from the config since the event was published
+	if webhookIndex < 0 {
+		log.Warnf("WebhookTask dropped event for unknown webhook %s", webhookURL)
+		msg.Ack()
+		return nil
+	}
+
+	err := deliverWebhookEvent(
+		ctx,
+		&wt.appState.Config.Webhooks[webhookIndex],
+		msg.Metadata.Get("event_type"),
+		msg.Metadata.Get("event_uuid"),
+		msg.Payload,
+	)
+	if err != nil {
+		return fmt.Errorf("WebhookTask failed to deliver event to %s: %w", webhookURL, err)
+	}
+
+	msg.Ack()
+
+	return nil
+}
+
+// deliverWebhookEvent POSTs an event to a webhook. If the webhook has a secret, the request
+// is signed as custom extractor requests are.
+func deliverWebhookEvent(
+	ctx context.Context,
+	webhook *config.WebhookConfig,
+	eventType string,
+	eventUUID string,
+	body []byte,
+) error {
+	timeout := webhook.Timeout
+	if timeout <= 0 {
+		timeout = DefaultWebhookTimeout
+	}
+	maxRetries := webhook.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = DefaultWebhookMaxRetries
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, eventType)
+	req.Header.Set(WebhookDeliveryHeader, eventUUID)
+
+	if webhook.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(CustomExtractorTimestampHeader, timestamp)
+		req.Header.Set(
+			CustomExtractorSignatureHeader,
+			SignCustomExtractorPayload(webhook.Secret, timestamp, body),
+		)
+	}
+
+	client := NewRetryableHTTPClient(maxRetries, time.Duration(timeout)*time.Second)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, respBody)
+	}
+
+	return nil
+}
+
+// ValidateWebhooks checks that webhooks have valid URLs and subscribe to known events
+func ValidateWebhooks(webhooks []config.WebhookConfig) error {
+	for _, w := range webhooks {
+		u, err := url.Parse(w.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("webhook has an invalid url: %q", w.URL)
+		}
+		for _, event := range w.Events {
+			if !slices.Contains(models.WebhookEventTypes, models.WebhookEventType(event)) {
+				return errors.New("webhook " + w.URL + " has an unknown event: " + event)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package tasks
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/config"
+	"github.com/getzep/zep/pkg/models"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	publisher := &recordingPublisher{}
+	appState := &models.AppState{
+		TaskPublisher: &TaskPublisher{publisher: publisher},
+		Config: &config.Config{
+			Webhooks: []config.WebhookConfig{
+				{URL: "http://all.example.com"},
+				{URL: "http://summaries.example.com", Events: []string{"summary.created"}},
+			},
+		},
+	}
+
+	NewWebhookNotifier(appState).Notify(testCtx, &models.WebhookEvent{
+		Type:      models.WebhookEventSessionDeleted,
+		SessionID: "session",
+	})
+
+	// only the webhook subscribed to all events is sent the event
+	require.Len(t, publisher.messages, 1)
+	assert.Equal(t, string(models.WebhookTopic), publisher.topics[0])
+	published := publisher.messages[0]
+	assert.Equal(t, "http://all.example.com", published.Metadata.Get("webhook_url"))
+	assert.Equal(t, "session.deleted", published.Metadata.Get("event_type"))
+
+	var event models.WebhookEvent
+	require.NoError(t, json.Unmarshal(published.Payload, &event))
+	assert.NotEqual(t, uuid.Nil, event.UUID)
+	assert.Equal(t, event.UUID.String(), published.Metadata.Get("event_uuid"))
+	assert.Equal(t, "session", event.SessionID)
+	assert.False(t, event.CreatedAt.IsZero())
+}
+
+func TestWebhookNotifier_NotifySync(t *testing.T) {
+	appState := &models.AppState{
+		Config: &config.Config{
+			Webhooks: []config.WebhookConfig{{URL: "http://all.example.com"}},
+		},
+	}
+
+	// the event can't be queued until the task publisher runs
+	err := NewWebhookNotifier(appState).NotifySync(testCtx, &models.WebhookEvent{
+		Type:      models.WebhookEventSessionDeleted,
+		SessionID: "session",
+	})
+	assert.ErrorContains(t, err, "task publisher isn't running")
+
+	publisher := &recordingPublisher{}
+	appState.TaskPublisher = &TaskPublisher{publisher: publisher}
+	err = NewWebhookNotifier(appState).NotifySync(testCtx, &models.WebhookEvent{
+		Type:      models.WebhookEventSessionDeleted,
+		SessionID: "session",
+	})
+	require.NoError(t, err)
+	assert.Len(t, publisher.messages, 1)
+}
+
+func TestWebhookTask(t *testing.T) {
+	const secret = "test-secret"
+	var requests int
+	var headers http.Header
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		headers = r.Header
+		var err error
+		body, err = io.ReadAll(r.Body)
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	appState := &models.AppState{
+		Config: &config.Config{
+			Webhooks: []config.WebhookConfig{{URL: server.URL, Secret: secret}},
+		},
+	}
+	task := NewWebhookTask(appState)
+
+	payload := []byte(`{"type":"session.created","session_id":"session"}`)
+	msg := message.NewMessage(uuid.NewString(), payload)
+	msg.Metadata.Set("webhook_url", server.URL)
+	msg.Metadata.Set("event_type", "session.created")
+	msg.Metadata.Set("event_uuid", "event")
+	require.NoError(t, task.Execute(testCtx, msg))
+
+	assert.Equal(t, 1, requests)
+	assert.Equal(t, payload, body)
+	assert.Equal(t, "session.created", headers.Get(WebhookEventHeader))
+	assert.Equal(t, "event", headers.Get(WebhookDeliveryHeader))
+	timestamp := headers.Get(CustomExtractorTimestampHeader)
+	assert.NotEmpty(t, timestamp)
+	assert.Equal(
+		t,
+		SignCustomExtractorPayload(secret, timestamp, payload),
+		headers.Get(CustomExtractorSignatureHeader),
+	)
+
+	// events of webhooks removed from the config are dropped
+	msg = message.NewMessage(uuid.NewString(), payload)
+	msg.Metadata.Set("webhook_url", "http://removed.example.com")
+	require.NoError(t, task.Execute(testCtx, msg))
+	assert.Equal(t, 1, requests)
+}
+
+func TestDeliverWebhookEvent_Failure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	webhook := &config.WebhookConfig{URL: server.URL}
+	err := deliverWebhookEvent(testCtx, webhook, "session.created", "event", []byte(`{}`))
+	assert.ErrorContains(t, err, "status 400")
+}
+
+func TestValidateWebhooks(t *testing.T) {
+	assert.NoError(t, ValidateWebhooks([]config.WebhookConfig{
+		{URL: "https://example.com/events", Events: []string{"session.created"}},
+	}))
+	assert.Error(t, ValidateWebhooks([]config.WebhookConfig{{URL: "example.com"}}))
+	assert.Error(t, ValidateWebhooks([]config.WebhookConfig{
+		{URL: "https://example.com/events", Events: []string{"session.updated"}},
+	}))
+}
@@ -0,0 +1,234 @@
+// Package webfetch fetches web pages for ingestion, respecting robots.txt and refusing pages
+// that are too large or on private networks.
+package webfetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/html/charset"
+
+	"github.com/getzep/zep/config"
+)
+
+const (
+	DefaultMaxSize   = 10 << 20 // 10MB
+	DefaultTimeout   = 30 * time.Second
+	DefaultUserAgent = "zep"
+	// maxRobotsSize is the size of robots.txt files that's read, as RFC 9309 allows
+	// crawlers to ignore rules past the first 500KiB
+	maxRobotsSize = 500 << 10
+	maxRedirects  = 10
+)
+
+var (
+	// ErrDisallowed is returned for URLs the site's robots.txt disallows fetching
+	ErrDisallowed = errors.New("fetching the URL is disallowed by robots.txt")
+	// ErrTooLarge is returned for pages larger than the maximum size
+	ErrTooLarge = errors.New("page is too large")
+	// ErrPrivateAddress is returned for URLs on loopback, private and link-local addresses
+	// unless private networks are allowed
+	ErrPrivateAddress = errors.New("URLs on private networks can't be fetched")
+	// ErrInvalidURL is returned for URLs that aren't absolute http or https URLs
+	ErrInvalidURL = errors.New("URL must be an absolute http or https URL")
+)
+
+// Page is a fetched web page
+type Page struct {
+	// URL is the URL of the page, after any redirects
+	URL string
+	// ContentType is the media type of the page, without its parameters
+	ContentType string
+	// Body is the page's body. Text is converted to UTF-8 from its declared charset.
+	Body []byte
+}
+
+// Fetcher fetches web pages
+type Fetcher struct {
+	client    *http.Client
+	maxSize   int64
+	userAgent string
+}
+
+// NewFetcher returns a Fetcher configured by cfg
+func NewFetcher(cfg *config.URLIngestionConfig) *Fetcher {
+	f := &Fetcher{
+		maxSize:   cfg.MaxSize,
+		userAgent: cfg.UserAgent,
+	}
+	if f.maxSize <= 0 {
+		f.maxSize = DefaultMaxSize
+	}
+	if f.userAgent == "" {
+		f.userAgent = DefaultUserAgent
+	}
+	timeout := DefaultTimeout
+	if cfg.Timeout > 0 {
+		timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	if !cfg.AllowPrivateNetworks {
+		dialer.Control = DenyPrivateNetworks
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+
+	f.client = &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return errors.New("stopped after too many redirects")
+			}
+			// the page redirected to must be allowed by its own site's robots.txt. robots.txt
+			// files may redirect to others.
+			if req.URL.Path == "/robots.txt" {
+				return nil
+			}
+			return f.checkRobots(req.Context(), req.URL)
+		},
+	}
+
+	return f
+}
+
+// DenyPrivateNetworks is a net.Dialer Control function that refuses connections to loopback,
+// private and link-local addresses with ErrPrivateAddress. Addresses are checked after
+// they're resolved, so that hostnames resolving to private addresses are refused too.
+func DenyPrivateNetworks(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || isPrivateIP(ip) {
+		return ErrPrivateAddress
+	}
+	return nil
+}
+
+func isPrivateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast()
+}
+
+// Fetch fetches the page at rawURL if the site's robots.txt allows it
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*Page, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, ErrInvalidURL
+	}
+	if err := f.checkRobots(ctx, u); err != nil {
+		return nil, err
+	}
+
+	resp, err := f.get(ctx, u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: %s", u, resp.Status)
+	}
+	if resp.ContentLength > f.maxSize {
+		return nil, ErrTooLarge
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	var body io.Reader = resp.Body
+	if strings.HasPrefix(mediaType, "text/") || mediaType == "application/xhtml+xml" {
+		body, err = charset.NewReader(body, contentType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", u, err)
+		}
+	}
+
+	data, err := readAtMost(body, f.maxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Page{URL: resp.Request.URL.String(), ContentType: mediaType, Body: data}, nil
+}
+
+// checkRobots returns ErrDisallowed if the robots.txt of u's site disallows fetching u. Sites
+// without a robots.txt allow every URL, while robots.txt files that can't be fetched
+// disallow every URL, as RFC 9309 requires.
+func (f *Fetcher) checkRobots(ctx context.Context, u *url.URL) error {
+	robotsURL := url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/robots.txt"}
+	resp, err := f.get(ctx, robotsURL.String())
+	if err != nil {
+		return fmt.Errorf("failed to fetch robots.txt: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return nil
+	default:
+		return fmt.Errorf("failed to fetch robots.txt: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRobotsSize))
+	if err != nil {
+		return fmt.Errorf("failed to read robots.txt: %w", err)
+	}
+
+	productToken, _, _ := strings.Cut(f.userAgent, "/")
+	productToken, _, _ = strings.Cut(productToken, " ")
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	if !parseRobots(data, productToken).allowed(path) {
+		return ErrDisallowed
+	}
+
+	return nil
+}
+
+func (f *Fetcher) get(ctx context.Context, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", f.userAgent)
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		// errors returned by CheckRedirect and the dialer are wrapped by the client
+		for _, target := range []error{ErrDisallowed, ErrPrivateAddress} {
+			if errors.Is(err, target) {
+				return nil, target
+			}
+		}
+		return nil, err
+	}
+	return resp, nil
+}
+
+// readAtMost reads r, returning ErrTooLarge if it's larger than maxSize bytes
+func readAtMost(r io.Reader, maxSize int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, ErrTooLarge
+	}
+	return data, nil
+}
@@ -0,0 +1,96 @@
+package webfetch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getzep/zep/config"
+)
+
+func newTestServer(t *testing.T, robotsStatus int) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		if robotsStatus != http.StatusOK {
+			w.WriteHeader(robotsStatus)
+			return
+		}
+		_, _ = w.Write([]byte("User-agent: *\nDisallow: /private\n"))
+	})
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "testbot/1.0", r.UserAgent())
+		w.Header().Set("Content-Type", "text/html; charset=iso-8859-1")
+		// café in Latin-1
+		_, _ = w.Write([]byte("<p>caf\xe9</p>"))
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/private/page", http.StatusFound)
+	})
+	mux.HandleFunc("/private/page", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("private"))
+	})
+	mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(strings.Repeat("a", 2048)))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetch(t *testing.T) {
+	server := newTestServer(t, http.StatusOK)
+	fetcher := NewFetcher(&config.URLIngestionConfig{
+		MaxSize:              1024,
+		UserAgent:            "testbot/1.0",
+		AllowPrivateNetworks: true,
+	})
+	ctx := context.Background()
+
+	page, err := fetcher.Fetch(ctx, server.URL+"/page")
+	require.NoError(t, err)
+	assert.Equal(t, server.URL+"/page", page.URL)
+	assert.Equal(t, "text/html", page.ContentType)
+	assert.Equal(t, "<p>café</p>", string(page.Body))
+
+	_, err = fetcher.Fetch(ctx, server.URL+"/private/page")
+	assert.ErrorIs(t, err, ErrDisallowed)
+
+	_, err = fetcher.Fetch(ctx, server.URL+"/redirect")
+	assert.ErrorIs(t, err, ErrDisallowed)
+
+	_, err = fetcher.Fetch(ctx, server.URL+"/large")
+	assert.ErrorIs(t, err, ErrTooLarge)
+
+	_, err = fetcher.Fetch(ctx, "file:///etc/passwd")
+	assert.ErrorIs(t, err, ErrInvalidURL)
+}
+
+func TestFetch_RobotsUnavailable(t *testing.T) {
+	fetcher := NewFetcher(&config.URLIngestionConfig{AllowPrivateNetworks: true})
+	ctx := context.Background()
+
+	// sites without a robots.txt allow every URL
+	server := newTestServer(t, http.StatusNotFound)
+	_, err := fetcher.Fetch(ctx, server.URL+"/private/page")
+	assert.NoError(t, err)
+
+	// robots.txt files that fail to be fetched disallow every URL
+	server = newTestServer(t, http.StatusServiceUnavailable)
+	_, err = fetcher.Fetch(ctx, server.URL+"/page")
+	assert.Error(t, err)
+}
+
+func TestFetch_PrivateNetworks(t *testing.T) {
+	server := newTestServer(t, http.StatusOK)
+	fetcher := NewFetcher(&config.URLIngestionConfig{})
+
+	_, err := fetcher.Fetch(context.Background(), server.URL+"/page")
+	assert.ErrorIs(t, err, ErrPrivateAddress)
+}
@@ -0,0 +1,112 @@
+package webfetch
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+// robotsRule allows or disallows the paths matching its pattern
+type robotsRule struct {
+	pattern *regexp.Regexp
+	// length is the length of the rule's path, the most specific of the rules matching a
+	// path being the longest
+	length int
+	allow  bool
+}
+
+// robots are the rules of a robots.txt file that apply to a user agent
+type robots struct {
+	rules []robotsRule
+}
+
+// parseRobots parses the rules of a robots.txt file, as specified by RFC 9309, that apply
+// to the user agent with the given product token. The rules of every group naming the
+// product token apply, or if there are none, the rules of the groups for all user agents.
+func parseRobots(data []byte, productToken string) *robots {
+	productToken = strings.ToLower(productToken)
+
+	var (
+		matched, wildcard []robotsRule
+		agents            []string
+		inAgents, named   bool
+	)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// consecutive user-agent lines start a group that applies to each of them
+			if !inAgents {
+				agents = agents[:0]
+			}
+			agents = append(agents, strings.ToLower(value))
+			inAgents = true
+			named = named || strings.ToLower(value) == productToken
+		case "allow", "disallow":
+			inAgents = false
+			// an empty disallow rule allows every path
+			if value == "" {
+				continue
+			}
+			rule := robotsRule{
+				pattern: robotsPattern(value),
+				length:  len(value),
+				allow:   key == "allow",
+			}
+			for _, agent := range agents {
+				switch agent {
+				case productToken:
+					matched = append(matched, rule)
+				case "*":
+					wildcard = append(wildcard, rule)
+				}
+			}
+		default:
+			inAgents = false
+		}
+	}
+
+	// a group naming the product token applies instead of the groups for all user agents,
+	// even if it has no rules
+	if !named {
+		return &robots{rules: wildcard}
+	}
+	return &robots{rules: matched}
+}
+
+// robotsPattern returns a regular expression matching the paths a rule's path matches: the
+// paths it's a prefix of, where * matches any characters and a trailing $ the end of the path
+func robotsPattern(path string) *regexp.Regexp {
+	anchored := strings.HasSuffix(path, "$")
+	path = strings.TrimSuffix(path, "$")
+
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(path), `\*`, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
+
+// allowed returns whether a path, including its query, may be fetched. The longest rule
+// matching the path applies, and allow rules win ties. Paths no rule matches are allowed.
+func (r *robots) allowed(path string) bool {
+	allow, length := true, -1
+	for _, rule := range r.rules {
+		if !rule.pattern.MatchString(path) {
+			continue
+		}
+		if rule.length > length || (rule.length == length && rule.allow) {
+			allow, length = rule.allow, rule.length
+		}
+	}
+	return allow
+}
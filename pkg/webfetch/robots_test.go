@@ -0,0 +1,48 @@
+package webfetch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRobotsAllowed(t *testing.T) {
+	robotsTxt := []byte(`
+# comments are ignored
+User-agent: *
+Disallow: /private
+Allow: /private/public
+Disallow: /*.json$
+
+User-agent: zep
+User-agent: otherbot
+Disallow: /no-zep
+Allow: /no-zep/but-this
+
+User-agent: quietbot
+`)
+
+	tests := []struct {
+		name     string
+		agent    string
+		path     string
+		expected bool
+	}{
+		{"unmatched path", "anybot", "/docs", true},
+		{"disallowed prefix", "anybot", "/private/page", false},
+		{"longer allow wins", "anybot", "/private/public/page", true},
+		{"wildcard and end anchor", "anybot", "/data/file.json", false},
+		{"end anchor", "anybot", "/data/file.json?x=1", true},
+		{"named group replaces the wildcard group", "zep", "/private/page", true},
+		{"named group", "ZEP", "/no-zep/page", false},
+		{"named group allow", "zep", "/no-zep/but-this", true},
+		{"group with several agents", "otherbot", "/no-zep", false},
+		{"named group without rules", "quietbot", "/private", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseRobots(robotsTxt, tt.agent).allowed(tt.path))
+		})
+	}
+}